@@ -68,4 +68,6 @@ export interface FeatureToggles {
   redshiftAsyncQueryDataSupport?: boolean;
   athenaAsyncQueryDataSupport?: boolean;
   increaseInMemDatabaseQueryCache?: boolean;
+  nestedFolders?: boolean;
+  scheduledDashboardSnapshots?: boolean;
 }
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /admin/logging/levels admin adminGetLogLevels
+//
+// Fetch the log levels.
+//
+// Returns the default log level and the levels of the loggers with a level of their own, including the changes made with the API since Grafana started.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminGetLogLevelsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminGetLogLevels(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, log.Levels())
+}
+
+// swagger:route PUT /admin/logging/levels admin adminSetLogLevel
+//
+// Change a log level.
+//
+// Changes the level of a logger, or the default level if the logger is empty, without restarting Grafana. The change is lost on restart.
+// An empty level removes the level of the logger, which then logs with the default level.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminSetLogLevel(c *models.ReqContext) response.Response {
+	cmd := SetLogLevelCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd.Logger = strings.TrimSpace(cmd.Logger)
+	cmd.Level = strings.ToLower(strings.TrimSpace(cmd.Level))
+
+	if cmd.Level == "" {
+		if cmd.Logger == "" {
+			return response.Error(http.StatusBadRequest, "The default log level can not be removed", nil)
+		}
+		log.ResetLevel(cmd.Logger)
+		c.Logger.Info("Log level removed", "name", cmd.Logger)
+		return response.Success("Log level removed")
+	}
+
+	if err := log.SetLevel(cmd.Logger, cmd.Level); err != nil {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Invalid log level, expected one of %s", strings.Join(log.LevelNames(), ", ")), err)
+	}
+	c.Logger.Info("Log level changed", "name", cmd.Logger, "level", cmd.Level)
+	return response.Success("Log level changed")
+}
+
+// SetLogLevelCommand changes the level of a logger.
+type SetLogLevelCommand struct {
+	// The name of the logger, for example `tsdb.prometheus`. Empty for the default level.
+	Logger string `json:"logger"`
+	// The level, one of trace, debug, info, warn, error and critical. Empty to remove the level of the logger.
+	Level string `json:"level"`
+}
+
+// swagger:parameters adminSetLogLevel
+type AdminSetLogLevelParams struct {
+	// in:body
+	// required:true
+	Body SetLogLevelCommand `json:"body"`
+}
+
+// swagger:response adminGetLogLevelsResponse
+type GetLogLevelsResponse struct {
+	// in:body
+	Body log.LoggerLevels `json:"body"`
+}
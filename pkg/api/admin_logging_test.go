@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestAPI_AdminLogLevels(t *testing.T) {
+	hs := &HTTPServer{}
+	t.Cleanup(func() {
+		log.ResetLevel("tsdb.test")
+	})
+
+	newReqContext := func(t *testing.T, method string, body string) *models.ReqContext {
+		t.Helper()
+		httpReq, err := http.NewRequest(method, "/api/admin/logging/levels", strings.NewReader(body))
+		require.NoError(t, err)
+		httpReq.Header.Add("Content-Type", "application/json")
+		return &models.ReqContext{SignedInUser: &user.SignedInUser{}, Context: &web.Context{Req: httpReq}, Logger: log.New("test")}
+	}
+
+	getLevels := func(t *testing.T) log.LoggerLevels {
+		t.Helper()
+		resp := hs.AdminGetLogLevels(newReqContext(t, http.MethodGet, ""))
+		require.Equal(t, http.StatusOK, resp.Status())
+		levels := log.LoggerLevels{}
+		require.NoError(t, json.Unmarshal(resp.Body(), &levels))
+		return levels
+	}
+
+	t.Run("should change the level of a logger", func(t *testing.T) {
+		resp := hs.AdminSetLogLevel(newReqContext(t, http.MethodPut, `{"logger":"tsdb.test","level":"DEBUG"}`))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, "debug", getLevels(t).Loggers["tsdb.test"])
+	})
+
+	t.Run("should reject an unknown level", func(t *testing.T) {
+		resp := hs.AdminSetLogLevel(newReqContext(t, http.MethodPut, `{"logger":"tsdb.test","level":"verbose"}`))
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		require.Equal(t, "debug", getLevels(t).Loggers["tsdb.test"])
+	})
+
+	t.Run("should reject removing the default level", func(t *testing.T) {
+		resp := hs.AdminSetLogLevel(newReqContext(t, http.MethodPut, `{"logger":"","level":""}`))
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should remove the level of a logger", func(t *testing.T) {
+		resp := hs.AdminSetLogLevel(newReqContext(t, http.MethodPut, `{"logger":"tsdb.test","level":""}`))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.NotContains(t, getLevels(t).Loggers, "tsdb.test")
+	})
+}
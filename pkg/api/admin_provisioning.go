@@ -3,9 +3,14 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
@@ -107,3 +112,90 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *models.ReqContext) resp
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:route GET /admin/provisioning/git admin_provisioning adminProvisioningGetGitSyncStatus
+//
+// Get the git sync status.
+//
+// Returns the state of the last sync of the git repository: the synced commit, the dashboards pushed to the branch and the dashboards which differ from the repository.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:git`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningGetGitSyncStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (hs *HTTPServer) AdminProvisioningGetGitSyncStatus(c *models.ReqContext) response.Response {
+	status, err := hs.ProvisioningService.GetGitSyncStatus()
+	if err != nil {
+		if errors.Is(err, gitsync.ErrDisabled) {
+			return response.Error(http.StatusNotFound, "Git sync is disabled", err)
+		}
+		return response.Error(http.StatusInternalServerError, "", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:route POST /admin/provisioning/git/sync admin_provisioning adminProvisioningSyncGit
+//
+// Sync the git repository.
+//
+// Pulls the git repository and provisions its dashboards and alerting resources. The dashboards edited in Grafana are pushed to the branch first when `push_changes` is enabled. It won’t return until the sync is done.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:git`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningSyncGit(c *models.ReqContext) response.Response {
+	err := hs.ProvisioningService.SyncGitRepository(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, gitsync.ErrDisabled) {
+			return response.Error(http.StatusNotFound, "Git sync is disabled", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to sync git repository", err)
+	}
+	return response.Success("Git repository synced")
+}
+
+// gitSyncWebhookMaxBodySize limits the payloads read by the webhook, which is not authenticated until the
+// signature of the payload is checked.
+const gitSyncWebhookMaxBodySize = 1 << 20
+
+// GitSyncWebhook triggers a sync of the git repository. The requests are authenticated by the signature of
+// the payload, or the secret token of GitLab, using the webhook_secret of the git sync.
+func (hs *HTTPServer) GitSyncWebhook(c *models.ReqContext) response.Response {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Resp, c.Req.Body, gitSyncWebhookMaxBodySize))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Failed to read the webhook payload, it must be smaller than %s", util.ByteCountSI(gitSyncWebhookMaxBodySize)), err)
+	}
+
+	triggered, err := hs.ProvisioningService.HandleGitSyncWebhook(c.Req.Header, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, gitsync.ErrDisabled), errors.Is(err, gitsync.ErrWebhookDisabled):
+			return response.Error(http.StatusNotFound, "Git sync webhook is disabled", err)
+		case errors.Is(err, gitsync.ErrInvalidSignature):
+			return response.Error(http.StatusUnauthorized, "Invalid webhook signature", err)
+		}
+		return response.Error(http.StatusBadRequest, "Invalid webhook payload", err)
+	}
+	if !triggered {
+		return response.Success("Ignored push to another branch")
+	}
+	return response.Success("Git sync triggered")
+}
+
+// swagger:response adminProvisioningGetGitSyncStatusResponse
+type AdminProvisioningGetGitSyncStatusResponse struct {
+	// in:body
+	Body gitsync.Status `json:"body"`
+}
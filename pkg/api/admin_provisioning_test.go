@@ -4,12 +4,15 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type reloadProvisioningTestCase struct {
@@ -162,6 +165,33 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 			url:          "/api/admin/provisioning/alerting/reload",
 			exit:         true,
 		},
+		{
+			desc:         "should work for git sync with specific scope",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"Git repository synced"}`,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersGit,
+				},
+			},
+			url: "/api/admin/provisioning/git/sync",
+			checkCall: func(mock provisioning.ProvisioningServiceMock) {
+				assert.Len(t, mock.Calls.SyncGitRepository, 1)
+			},
+		},
+		{
+			desc:         "should fail for git sync with wrong scope",
+			expectedCode: http.StatusForbidden,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersDashboards,
+				},
+			},
+			url:  "/api/admin/provisioning/git/sync",
+			exit: true,
+		},
 	}
 
 	cfg := setting.NewCfg()
@@ -195,3 +225,85 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_GitSyncWebhook(t *testing.T) {
+	tests := []struct {
+		desc         string
+		err          error
+		triggered    bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			desc:         "should trigger a sync",
+			triggered:    true,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"Git sync triggered"}`,
+		},
+		{
+			desc:         "should ignore the pushes to other branches",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"Ignored push to another branch"}`,
+		},
+		{
+			desc:         "should fail with an invalid signature",
+			err:          gitsync.ErrInvalidSignature,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			desc:         "should fail when the webhook is disabled",
+			err:          gitsync.ErrWebhookDisabled,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	cfg := setting.NewCfg()
+	url := "/api/provisioning/git/webhook"
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sc, hs := setupAccessControlScenarioContext(t, cfg, url, nil)
+
+			provisioningMock := provisioning.NewProvisioningServiceMock(context.Background())
+			provisioningMock.HandleGitSyncWebhookFunc = func(header http.Header, body []byte) (bool, error) {
+				assert.Equal(t, "sha256=abc", header.Get("X-Hub-Signature-256"))
+				assert.Equal(t, `{"ref":"refs/heads/main"}`, string(body))
+				return test.triggered, test.err
+			}
+			hs.ProvisioningService = provisioningMock
+
+			sc.resp = httptest.NewRecorder()
+			var err error
+			sc.req, err = http.NewRequest(http.MethodPost, url, strings.NewReader(`{"ref":"refs/heads/main"}`))
+			require.NoError(t, err)
+			sc.req.Header.Set("X-Hub-Signature-256", "sha256=abc")
+
+			sc.exec()
+
+			assert.Equal(t, test.expectedCode, sc.resp.Code)
+			assert.Len(t, provisioningMock.Calls.HandleGitSyncWebhook, 1)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, sc.resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPI_GitSyncWebhook_PayloadTooLarge(t *testing.T) {
+	cfg := setting.NewCfg()
+	url := "/api/provisioning/git/webhook"
+	sc, hs := setupAccessControlScenarioContext(t, cfg, url, nil)
+
+	provisioningMock := provisioning.NewProvisioningServiceMock(context.Background())
+	hs.ProvisioningService = provisioningMock
+
+	sc.resp = httptest.NewRecorder()
+	var err error
+	sc.req, err = http.NewRequest(http.MethodPost, url, strings.NewReader(strings.Repeat(" ", gitSyncWebhookMaxBodySize+1)))
+	require.NoError(t, err)
+
+	sc.exec()
+
+	assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+	assert.Empty(t, provisioningMock.Calls.HandleGitSyncWebhook)
+}
@@ -307,7 +307,19 @@ func (hs *HTTPServer) AdminLogoutUser(c *models.ReqContext) response.Response {
 		return response.Error(400, "You cannot logout yourself", nil)
 	}
 
-	return hs.logoutUserFromAllDevicesInternal(c.Req.Context(), userID)
+	tokens, err := hs.AuthTokenService.GetUserTokens(c.Req.Context(), userID)
+	if err != nil {
+		return response.Error(500, "Failed to get user auth tokens", err)
+	}
+
+	resp := hs.logoutUserFromAllDevicesInternal(c.Req.Context(), userID)
+	if resp.Status() == http.StatusOK {
+		for _, token := range tokens {
+			hs.publishSessionRevoked(c, token)
+		}
+	}
+
+	return resp
 }
 
 // swagger:route GET /admin/users/{user_id}/auth-tokens admin_users adminGetUserAuthTokens
@@ -10,7 +10,10 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/login/loginservice"
@@ -266,6 +269,8 @@ func adminLogoutUserScenario(t *testing.T, desc string, url string, routePattern
 		hs := HTTPServer{
 			AuthTokenService: auth.NewFakeUserAuthTokenService(),
 			userService:      userService,
+			bus:              bus.ProvideBus(tracing.InitializeTracerForTest()),
+			log:              log.New("test"),
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -293,6 +298,8 @@ func adminRevokeUserAuthTokenScenario(t *testing.T, desc string, url string, rou
 		hs := HTTPServer{
 			AuthTokenService: fakeAuthTokenService,
 			userService:      userService,
+			bus:              bus.ProvideBus(tracing.InitializeTracerForTest()),
+			log:              log.New("test"),
 		}
 
 		sc := setupScenarioContext(t, url)
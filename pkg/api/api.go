@@ -321,6 +321,8 @@ func (hs *HTTPServer) registerRoutes() {
 			orgsRoute.Delete("/users/:userId", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgUsersRemove, userIDScope)), routing.Wrap(hs.RemoveOrgUser))
 			orgsRoute.Get("/quotas", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgsQuotasRead)), routing.Wrap(hs.GetOrgQuotas))
 			orgsRoute.Put("/quotas/:target", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgsQuotasWrite)), routing.Wrap(hs.UpdateOrgQuota))
+			orgsRoute.Get("/sessions", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionUsersAuthTokenList, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.GetOrgSessions))
+			orgsRoute.Delete("/sessions/:sessionId", authorizeInOrg(reqGrafanaAdmin, ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionUsersAuthTokenUpdate, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.RevokeOrgSession))
 		})
 
 		// orgs (admin routes)
@@ -408,6 +410,8 @@ func (hs *HTTPServer) registerRoutes() {
 				folderUidRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.GetFolderByUID))
 				folderUidRoute.Put("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersWrite, uidScope)), routing.Wrap(hs.UpdateFolder))
 				folderUidRoute.Delete("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersDelete, uidScope)), routing.Wrap(hs.DeleteFolder))
+				folderUidRoute.Get("/parents", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersRead, uidScope)), routing.Wrap(hs.GetFolderParents))
+				folderUidRoute.Post("/move", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersWrite, uidScope)), routing.Wrap(hs.MoveFolder))
 
 				folderUidRoute.Group("/permissions", func(folderPermissionRoute routing.RouteRegister) {
 					folderPermissionRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionFoldersPermissionsRead, uidScope)), routing.Wrap(hs.GetFolderPermissionList))
@@ -468,6 +472,18 @@ func (hs *HTTPServer) registerRoutes() {
 			dashboardRoute.Get("/", routing.Wrap(hs.SearchDashboardSnapshots))
 		})
 
+		if hs.Features.IsEnabled(featuremgmt.FlagScheduledDashboardSnapshots) {
+			apiRoute.Group("/dashboard/snapshot-schedules", func(scheduleRoute routing.RouteRegister) {
+				scheduleRoute.Get("/", routing.Wrap(hs.SearchSnapshotSchedules))
+				scheduleRoute.Post("/", routing.Wrap(hs.CreateSnapshotSchedule))
+				scheduleRoute.Get("/:uid", routing.Wrap(hs.GetSnapshotSchedule))
+				scheduleRoute.Put("/:uid", routing.Wrap(hs.UpdateSnapshotSchedule))
+				scheduleRoute.Delete("/:uid", routing.Wrap(hs.DeleteSnapshotSchedule))
+				scheduleRoute.Get("/:uid/snapshots", routing.Wrap(hs.GetSnapshotScheduleHistory))
+				scheduleRoute.Post("/:uid/run", routing.Wrap(hs.RunSnapshotSchedule))
+			}, reqEditorRole)
+		}
+
 		// Playlist
 		apiRoute.Group("/playlists", func(playlistRoute routing.RouteRegister) {
 			playlistRoute.Get("/", routing.Wrap(hs.SearchPlaylists))
@@ -573,6 +589,10 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/comments", func(commentRoute routing.RouteRegister) {
 			commentRoute.Post("/get", routing.Wrap(hs.commentsGet))
 			commentRoute.Post("/create", routing.Wrap(hs.commentsCreate))
+			commentRoute.Post("/update", routing.Wrap(hs.commentsUpdate))
+			commentRoute.Post("/history", routing.Wrap(hs.commentsHistory))
+			commentRoute.Post("/react", routing.Wrap(hs.commentsReact))
+			commentRoute.Post("/unreact", routing.Wrap(hs.commentsUnreact))
 		})
 	}, reqSignedIn)
 
@@ -584,6 +604,8 @@ func (hs *HTTPServer) registerRoutes() {
 		}
 		adminRoute.Get("/stats", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetStats))
 		adminRoute.Post("/pause-all-alerts", reqGrafanaAdmin, routing.Wrap(hs.PauseAllAlerts(setting.AlertingEnabled)))
+		adminRoute.Get("/logging/levels", reqGrafanaAdmin, routing.Wrap(hs.AdminGetLogLevels))
+		adminRoute.Put("/logging/levels", reqGrafanaAdmin, routing.Wrap(hs.AdminSetLogLevel))
 
 		if hs.ThumbService != nil && hs.Features.IsEnabled(featuremgmt.FlagDashboardPreviewsAdmin) {
 			adminRoute.Post("/crawler/start", reqGrafanaAdmin, routing.Wrap(hs.ThumbService.StartCrawler))
@@ -595,6 +617,7 @@ func (hs *HTTPServer) registerRoutes() {
 			adminRoute.Get("/export", reqGrafanaAdmin, routing.Wrap(hs.ExportService.HandleGetStatus))
 			adminRoute.Post("/export", reqGrafanaAdmin, routing.Wrap(hs.ExportService.HandleRequestExport))
 			adminRoute.Post("/export/stop", reqGrafanaAdmin, routing.Wrap(hs.ExportService.HandleRequestStop))
+			adminRoute.Post("/export/import", reqGrafanaAdmin, routing.Wrap(hs.ExportService.HandleRequestImport))
 			adminRoute.Get("/export/options", reqGrafanaAdmin, routing.Wrap(hs.ExportService.HandleGetOptions))
 		}
 
@@ -611,6 +634,8 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/provisioning/datasources/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources)), routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersNotifications)), routing.Wrap(hs.AdminProvisioningReloadNotifications))
 		adminRoute.Post("/provisioning/alerting/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAlertRules)), routing.Wrap(hs.AdminProvisioningReloadAlerting))
+		adminRoute.Get("/provisioning/git", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersGit)), routing.Wrap(hs.AdminProvisioningGetGitSyncStatus))
+		adminRoute.Post("/provisioning/git/sync", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersGit)), routing.Wrap(hs.AdminProvisioningSyncGit))
 
 		adminRoute.Post("/ldap/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPConfigReload)), routing.Wrap(hs.ReloadLDAPCfg))
 		adminRoute.Post("/ldap/sync/:id", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(hs.PostSyncUserWithLDAP))
@@ -651,4 +676,7 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/api/snapshots/:key", routing.Wrap(hs.GetDashboardSnapshot))
 	r.Get("/api/snapshots-delete/:deleteKey", reqSnapshotPublicModeOrSignedIn, routing.Wrap(hs.DeleteDashboardSnapshotByDeleteKey))
 	r.Delete("/api/snapshots/:key", reqEditorRole, routing.Wrap(hs.DeleteDashboardSnapshot))
+
+	// Git sync webhook, authenticated by the payload signature
+	r.Post("/api/provisioning/git/webhook", routing.Wrap(hs.GitSyncWebhook))
 }
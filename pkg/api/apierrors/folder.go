@@ -19,7 +19,10 @@ func ToFolderErrorResponse(err error) response.Response {
 		errors.Is(err, dashboards.ErrDashboardTypeMismatch) ||
 		errors.Is(err, dashboards.ErrDashboardInvalidUid) ||
 		errors.Is(err, dashboards.ErrDashboardUidTooLong) ||
-		errors.Is(err, dashboards.ErrFolderContainsAlertRules) {
+		errors.Is(err, dashboards.ErrFolderContainsAlertRules) ||
+		errors.Is(err, dashboards.ErrFolderTooDeep) ||
+		errors.Is(err, dashboards.ErrFolderCircularReference) ||
+		errors.Is(err, dashboards.ErrFolderTitleInvalid) {
 		return response.Error(400, err.Error(), nil)
 	}
 
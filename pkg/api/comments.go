@@ -19,10 +19,7 @@ func (hs *HTTPServer) commentsGet(c *models.ReqContext) response.Response {
 	}
 	items, err := hs.commentsService.Get(c.Req.Context(), c.OrgID, c.SignedInUser, cmd)
 	if err != nil {
-		if errors.Is(err, comments.ErrPermissionDenied) {
-			return response.Error(http.StatusForbidden, "permission denied", err)
-		}
-		return response.Error(http.StatusInternalServerError, "internal error", err)
+		return commentsErrorResponse(err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
 		"comments": items,
@@ -39,12 +36,77 @@ func (hs *HTTPServer) commentsCreate(c *models.ReqContext) response.Response {
 	}
 	comment, err := hs.commentsService.Create(c.Req.Context(), c.OrgID, c.SignedInUser, cmd)
 	if err != nil {
-		if errors.Is(err, comments.ErrPermissionDenied) {
-			return response.Error(http.StatusForbidden, "permission denied", err)
-		}
-		return response.Error(http.StatusInternalServerError, "internal error", err)
+		return commentsErrorResponse(err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
 		"comment": comment,
 	})
 }
+
+func (hs *HTTPServer) commentsUpdate(c *models.ReqContext) response.Response {
+	cmd := comments.UpdateCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	comment, err := hs.commentsService.Update(c.Req.Context(), c.OrgID, c.SignedInUser, cmd)
+	if err != nil {
+		return commentsErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"comment": comment,
+	})
+}
+
+func (hs *HTTPServer) commentsHistory(c *models.ReqContext) response.Response {
+	cmd := comments.HistoryCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	items, err := hs.commentsService.History(c.Req.Context(), c.OrgID, c.SignedInUser, cmd)
+	if err != nil {
+		return commentsErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"history": items,
+	})
+}
+
+func (hs *HTTPServer) commentsReact(c *models.ReqContext) response.Response {
+	cmd := comments.ReactCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	comment, err := hs.commentsService.React(c.Req.Context(), c.OrgID, c.SignedInUser, cmd)
+	if err != nil {
+		return commentsErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"comment": comment,
+	})
+}
+
+func (hs *HTTPServer) commentsUnreact(c *models.ReqContext) response.Response {
+	cmd := comments.ReactCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	comment, err := hs.commentsService.Unreact(c.Req.Context(), c.OrgID, c.SignedInUser, cmd)
+	if err != nil {
+		return commentsErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"comment": comment,
+	})
+}
+
+func commentsErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, comments.ErrPermissionDenied):
+		return response.Error(http.StatusForbidden, "permission denied", err)
+	case errors.Is(err, comments.ErrCommentNotFound):
+		return response.Error(http.StatusNotFound, "comment not found", err)
+	case errors.Is(err, comments.ErrInvalidComment):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "internal error", err)
+}
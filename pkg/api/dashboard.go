@@ -832,7 +832,7 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *models.ReqContext) response.Resp
 		return response.Error(500, "Unable to compute diff", err)
 	}
 
-	if options.DiffType == dashdiffs.DiffDelta {
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffSemantic {
 		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
 	}
 
@@ -858,6 +858,9 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *models.ReqContext) response.Resp
 //
 // Restore a dashboard to a given dashboard version using UID.
 //
+// If panels or variables are provided, only those are restored from the given version
+// and saved as a new version of the current dashboard.
+//
 // Responses:
 // 200: postDashboardResponse
 // 401: unauthorisedError
@@ -902,18 +905,48 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext) response.Res
 	}
 
 	saveCmd := models.SaveDashboardCommand{}
-	saveCmd.RestoredFrom = version.Version
 	saveCmd.OrgId = c.OrgID
 	saveCmd.UserId = c.UserID
-	saveCmd.Dashboard = version.Data
+	saveCmd.FolderId = dash.FolderId
+	if len(apiCmd.Panels) > 0 || len(apiCmd.Variables) > 0 {
+		restored, err := dashdiffs.RestoreFromVersion(dash.Data, version.Data, dashdiffs.RestoreOptions{
+			PanelIDs:  apiCmd.Panels,
+			Variables: apiCmd.Variables,
+		})
+		if err != nil {
+			if errors.Is(err, dashdiffs.ErrPanelNotFound) || errors.Is(err, dashdiffs.ErrVariableNotFound) {
+				return response.Error(http.StatusBadRequest, err.Error(), err)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to restore dashboard version", err)
+		}
+		saveCmd.Dashboard = restored
+		saveCmd.Message = fmt.Sprintf("Restored %s from version %d", describeRestoredElements(apiCmd), version.Version)
+	} else {
+		saveCmd.RestoredFrom = version.Version
+		saveCmd.Dashboard = version.Data
+		saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
+	}
 	saveCmd.Dashboard.Set("version", dash.Version)
 	saveCmd.Dashboard.Set("uid", dash.Uid)
-	saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
-	saveCmd.FolderId = dash.FolderId
 
 	return hs.postDashboard(c, saveCmd)
 }
 
+func describeRestoredElements(cmd dtos.RestoreDashboardVersionCommand) string {
+	var parts []string
+	if n := len(cmd.Panels); n == 1 {
+		parts = append(parts, "1 panel")
+	} else if n > 1 {
+		parts = append(parts, fmt.Sprintf("%d panels", n))
+	}
+	if n := len(cmd.Variables); n == 1 {
+		parts = append(parts, "1 variable")
+	} else if n > 1 {
+		parts = append(parts, fmt.Sprintf("%d variables", n))
+	}
+	return strings.Join(parts, " and ")
+}
+
 // swagger:route GET /dashboards/tags dashboards getDashboardTags
 //
 // Get all dashboards tags of an organisation.
@@ -1056,7 +1089,8 @@ type CalcDashboardDiffParams struct {
 		// Description:
 		// * `basic`
 		// * `json`
-		// Enum: basic,json
+		// * `semantic` - the added, removed and modified panels, variables and settings
+		// Enum: basic,json,semantic
 		DiffType string `json:"diffType" binding:"Required"`
 	}
 }
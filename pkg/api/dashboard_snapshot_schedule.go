@@ -0,0 +1,327 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /dashboard/snapshot-schedules snapshots searchSnapshotSchedules
+//
+// List snapshot schedules.
+//
+// Only the schedules of the dashboards the user can view are returned.
+//
+// Responses:
+// 200: searchSnapshotSchedulesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) SearchSnapshotSchedules(c *models.ReqContext) response.Response {
+	query := &dashboardsnapshots.GetSnapshotSchedulesQuery{
+		OrgId:        c.OrgID,
+		DashboardUid: c.Query("dashboardUid"),
+	}
+	if err := hs.snapshotScheduleService.SearchSnapshotSchedules(c.Req.Context(), query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search snapshot schedules", err)
+	}
+
+	canView := make(map[string]bool)
+	schedules := make([]*dashboardsnapshots.SnapshotSchedule, 0, len(query.Result))
+	for _, schedule := range query.Result {
+		allowed, ok := canView[schedule.DashboardUid]
+		if !ok {
+			var err error
+			allowed, err = hs.canAccessSnapshotSchedule(c, schedule.DashboardUid, false)
+			if err != nil {
+				return response.Error(http.StatusInternalServerError, "Error while checking permissions for snapshot schedule", err)
+			}
+			canView[schedule.DashboardUid] = allowed
+		}
+		if allowed {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	return response.JSON(http.StatusOK, schedules)
+}
+
+// swagger:route POST /dashboard/snapshot-schedules snapshots createSnapshotSchedule
+//
+// Create a snapshot schedule.
+//
+// Takes snapshots of a dashboard according to a cron expression. Snapshots beyond the retention count
+// or older than the retention age are removed after each run.
+//
+// Responses:
+// 200: snapshotScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CreateSnapshotSchedule(c *models.ReqContext) response.Response {
+	cmd := dashboardsnapshots.CreateSnapshotScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	canEdit, err := hs.canAccessSnapshotSchedule(c, cmd.DashboardUid, true)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while checking permissions for dashboard", err)
+	}
+	if !canEdit {
+		return response.Error(http.StatusForbidden, "Access denied to this dashboard", nil)
+	}
+
+	cmd.OrgId = c.OrgID
+	cmd.UserId = c.UserID
+	if err := hs.snapshotScheduleService.CreateSnapshotSchedule(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create snapshot schedule", err)
+	}
+
+	return response.JSON(http.StatusOK, cmd.Result)
+}
+
+// swagger:route GET /dashboard/snapshot-schedules/{uid} snapshots getSnapshotSchedule
+//
+// Get a snapshot schedule by uid.
+//
+// Responses:
+// 200: snapshotScheduleResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetSnapshotSchedule(c *models.ReqContext) response.Response {
+	schedule, errResponse := hs.getSnapshotSchedule(c, false)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route PUT /dashboard/snapshot-schedules/{uid} snapshots updateSnapshotSchedule
+//
+// Update a snapshot schedule.
+//
+// Only the owner of the schedule, whose permissions are used to take the snapshots, or an org admin can update it.
+//
+// Responses:
+// 200: snapshotScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateSnapshotSchedule(c *models.ReqContext) response.Response {
+	cmd := dashboardsnapshots.UpdateSnapshotScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	schedule, errResponse := hs.getSnapshotSchedule(c, true)
+	if errResponse != nil {
+		return errResponse
+	}
+	if !canManageSnapshotSchedule(c, schedule) {
+		return response.Error(http.StatusForbidden, "Only the owner of the snapshot schedule or an admin can update it", nil)
+	}
+
+	cmd.Uid = schedule.Uid
+	cmd.OrgId = c.OrgID
+	if err := hs.snapshotScheduleService.UpdateSnapshotSchedule(c.Req.Context(), &cmd); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update snapshot schedule", err)
+	}
+
+	return response.JSON(http.StatusOK, cmd.Result)
+}
+
+// swagger:route DELETE /dashboard/snapshot-schedules/{uid} snapshots deleteSnapshotSchedule
+//
+// Delete a snapshot schedule.
+//
+// The snapshots taken by the schedule are kept.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteSnapshotSchedule(c *models.ReqContext) response.Response {
+	schedule, errResponse := hs.getSnapshotSchedule(c, true)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	cmd := &dashboardsnapshots.DeleteSnapshotScheduleCommand{Uid: schedule.Uid, OrgId: c.OrgID}
+	if err := hs.snapshotScheduleService.DeleteSnapshotSchedule(c.Req.Context(), cmd); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete snapshot schedule", err)
+	}
+
+	return response.Success("Snapshot schedule deleted")
+}
+
+// swagger:route GET /dashboard/snapshot-schedules/{uid}/snapshots snapshots getSnapshotScheduleHistory
+//
+// List the snapshots taken by a schedule, most recent first.
+//
+// Responses:
+// 200: searchDashboardSnapshotsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetSnapshotScheduleHistory(c *models.ReqContext) response.Response {
+	schedule, errResponse := hs.getSnapshotSchedule(c, false)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	query := &dashboardsnapshots.GetScheduleSnapshotsQuery{ScheduleId: schedule.Id, OrgId: c.OrgID}
+	if err := hs.snapshotScheduleService.GetScheduleSnapshots(c.Req.Context(), query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get snapshot schedule history", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
+
+// swagger:route POST /dashboard/snapshot-schedules/{uid}/run snapshots runSnapshotSchedule
+//
+// Take a snapshot right away.
+//
+// Does not change when the schedule runs next. The retention policy of the schedule is applied.
+// Only the owner of the schedule or an org admin can run it.
+//
+// Responses:
+// 200: createDashboardSnapshotResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RunSnapshotSchedule(c *models.ReqContext) response.Response {
+	schedule, errResponse := hs.getSnapshotSchedule(c, true)
+	if errResponse != nil {
+		return errResponse
+	}
+	if !canManageSnapshotSchedule(c, schedule) {
+		return response.Error(http.StatusForbidden, "Only the owner of the snapshot schedule or an admin can run it", nil)
+	}
+
+	cmd := &dashboardsnapshots.RunSnapshotScheduleCommand{Uid: schedule.Uid, OrgId: c.OrgID}
+	err := hs.snapshotScheduleService.RunSnapshotSchedule(c.Req.Context(), cmd)
+	if cmd.Result == nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to take snapshot", err)
+	}
+	if err != nil {
+		hs.log.Warn("Scheduled snapshot taken with errors", "uid", schedule.Uid, "error", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"key":       cmd.Result.Key,
+		"deleteKey": cmd.Result.DeleteKey,
+		"url":       setting.ToAbsUrl("dashboard/snapshot/" + cmd.Result.Key),
+		"deleteUrl": setting.ToAbsUrl("api/snapshots-delete/" + cmd.Result.DeleteKey),
+		"id":        cmd.Result.Id,
+	})
+}
+
+// getSnapshotSchedule returns the schedule from the uid parameter, provided that the user
+// can view, or edit if requested, the dashboard it takes snapshots of.
+func (hs *HTTPServer) getSnapshotSchedule(c *models.ReqContext, edit bool) (*dashboardsnapshots.SnapshotSchedule, response.Response) {
+	query := &dashboardsnapshots.GetSnapshotScheduleQuery{Uid: web.Params(c.Req)[":uid"], OrgId: c.OrgID}
+	if err := hs.snapshotScheduleService.GetSnapshotSchedule(c.Req.Context(), query); err != nil {
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "Failed to get snapshot schedule", err)
+	}
+
+	allowed, err := hs.canAccessSnapshotSchedule(c, query.Result.DashboardUid, edit)
+	if err != nil {
+		return nil, response.Error(http.StatusInternalServerError, "Error while checking permissions for snapshot schedule", err)
+	}
+	if !allowed {
+		return nil, response.Error(http.StatusForbidden, "Access denied to this snapshot schedule", nil)
+	}
+
+	return query.Result, nil
+}
+
+// canAccessSnapshotSchedule checks the permissions of the user on the dashboard of a schedule.
+// The schedules of deleted dashboards are accessible so that they can be removed.
+func (hs *HTTPServer) canAccessSnapshotSchedule(c *models.ReqContext, dashboardUID string, edit bool) (bool, error) {
+	query := &models.GetDashboardQuery{Uid: dashboardUID, OrgId: c.OrgID}
+	if err := hs.DashboardService.GetDashboard(c.Req.Context(), query); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	g := guardian.New(c.Req.Context(), query.Result.Id, c.OrgID, c.SignedInUser)
+	if edit {
+		return g.CanEdit()
+	}
+	return g.CanView()
+}
+
+// swagger:parameters searchSnapshotSchedules
+type SearchSnapshotSchedulesParams struct {
+	// Only return the schedules of this dashboard
+	// in:query
+	DashboardUID string `json:"dashboardUid"`
+}
+
+// swagger:parameters createSnapshotSchedule
+type CreateSnapshotScheduleParams struct {
+	// in:body
+	// required:true
+	Body dashboardsnapshots.CreateSnapshotScheduleCommand `json:"body"`
+}
+
+// swagger:parameters updateSnapshotSchedule
+type UpdateSnapshotScheduleParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body dashboardsnapshots.UpdateSnapshotScheduleCommand `json:"body"`
+}
+
+// swagger:parameters getSnapshotSchedule deleteSnapshotSchedule getSnapshotScheduleHistory runSnapshotSchedule
+type SnapshotScheduleUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response searchSnapshotSchedulesResponse
+type SearchSnapshotSchedulesResponse struct {
+	// in:body
+	Body []*dashboardsnapshots.SnapshotSchedule `json:"body"`
+}
+
+// swagger:response snapshotScheduleResponse
+type SnapshotScheduleResponse struct {
+	// in:body
+	Body *dashboardsnapshots.SnapshotSchedule `json:"body"`
+}
+
+// canManageSnapshotSchedule checks that the user owns the schedule or is an org admin. The snapshots are taken with
+// the permissions of the owner, so other users must not change or run the schedule.
+func canManageSnapshotSchedule(c *models.ReqContext, schedule *dashboardsnapshots.SnapshotSchedule) bool {
+	return schedule.UserId == c.UserID || c.SignedInUser.HasRole(org.RoleAdmin)
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type fakeSnapshotScheduleService struct {
+	dashboardsnapshots.ScheduleService
+	schedules []*dashboardsnapshots.SnapshotSchedule
+	created   *dashboardsnapshots.CreateSnapshotScheduleCommand
+}
+
+func (f *fakeSnapshotScheduleService) SearchSnapshotSchedules(_ context.Context, query *dashboardsnapshots.GetSnapshotSchedulesQuery) error {
+	query.Result = f.schedules
+	return nil
+}
+
+func (f *fakeSnapshotScheduleService) CreateSnapshotSchedule(_ context.Context, cmd *dashboardsnapshots.CreateSnapshotScheduleCommand) error {
+	f.created = cmd
+	cmd.Result = &dashboardsnapshots.SnapshotSchedule{Uid: "new", DashboardUid: cmd.DashboardUid, OrgId: cmd.OrgId}
+	return nil
+}
+
+func (f *fakeSnapshotScheduleService) GetSnapshotSchedule(_ context.Context, query *dashboardsnapshots.GetSnapshotScheduleQuery) error {
+	for _, schedule := range f.schedules {
+		if schedule.Uid == query.Uid {
+			query.Result = schedule
+			return nil
+		}
+	}
+	return dashboardsnapshots.ErrScheduleNotFound
+}
+
+func (f *fakeSnapshotScheduleService) UpdateSnapshotSchedule(_ context.Context, cmd *dashboardsnapshots.UpdateSnapshotScheduleCommand) error {
+	cmd.Result = &dashboardsnapshots.SnapshotSchedule{Uid: cmd.Uid, OrgId: cmd.OrgId}
+	return nil
+}
+
+func TestSnapshotScheduleAPIEndpoint(t *testing.T) {
+	origNewGuardian := guardian.New
+	t.Cleanup(func() { guardian.New = origNewGuardian })
+	// Users can view dashboard 1 and edit dashboard 2
+	guardian.New = func(_ context.Context, dashID int64, _ int64, _ *user.SignedInUser) guardian.DashboardGuardian {
+		return &guardian.FakeDashboardGuardian{CanViewValue: true, CanEditValue: dashID == 2}
+	}
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		switch q.Uid {
+		case "view":
+			q.Result = &models.Dashboard{Id: 1, Uid: q.Uid}
+		case "edit":
+			q.Result = &models.Dashboard{Id: 2, Uid: q.Uid}
+		}
+	}).Return(func(_ context.Context, q *models.GetDashboardQuery) error {
+		if q.Result == nil {
+			return dashboards.ErrDashboardNotFound
+		}
+		return nil
+	})
+
+	scheduleService := &fakeSnapshotScheduleService{}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Features = featuremgmt.WithFeatures(featuremgmt.FlagScheduledDashboardSnapshots)
+		hs.DashboardService = dashboardService
+		hs.snapshotScheduleService = scheduleService
+		hs.QuotaService = quotatest.NewQuotaServiceFake()
+	})
+	editor := &user.SignedInUser{UserID: 10, OrgID: 1, OrgRole: org.RoleEditor}
+
+	t.Run("Should only allow editors", func(t *testing.T) {
+		req := server.NewGetRequest("/api/dashboard/snapshot-schedules")
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 10, OrgID: 1, OrgRole: org.RoleViewer})
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Should list the schedules of the dashboards the user can view", func(t *testing.T) {
+		guardian.New = func(_ context.Context, dashID int64, _ int64, _ *user.SignedInUser) guardian.DashboardGuardian {
+			return &guardian.FakeDashboardGuardian{CanViewValue: dashID == 1}
+		}
+		t.Cleanup(func() {
+			guardian.New = func(_ context.Context, dashID int64, _ int64, _ *user.SignedInUser) guardian.DashboardGuardian {
+				return &guardian.FakeDashboardGuardian{CanViewValue: true, CanEditValue: dashID == 2}
+			}
+		})
+		scheduleService.schedules = []*dashboardsnapshots.SnapshotSchedule{
+			{Uid: "a", DashboardUid: "view"},
+			{Uid: "b", DashboardUid: "edit"},
+			{Uid: "c", DashboardUid: "deleted"},
+		}
+
+		req := server.NewGetRequest("/api/dashboard/snapshot-schedules")
+		webtest.RequestWithSignedInUser(req, editor)
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var schedules []*dashboardsnapshots.SnapshotSchedule
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&schedules))
+		require.Len(t, schedules, 2)
+		assert.Equal(t, "a", schedules[0].Uid)
+		assert.Equal(t, "c", schedules[1].Uid)
+	})
+
+	t.Run("Should not create a schedule for a dashboard the user cannot edit", func(t *testing.T) {
+		req := server.NewPostRequest("/api/dashboard/snapshot-schedules", strings.NewReader(`{"dashboardUid": "view", "schedule": "@daily"}`))
+		req.Header.Set("Content-Type", "application/json")
+		webtest.RequestWithSignedInUser(req, editor)
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Should create a schedule", func(t *testing.T) {
+		req := server.NewPostRequest("/api/dashboard/snapshot-schedules", strings.NewReader(`{"dashboardUid": "edit", "schedule": "@daily", "retentionCount": 30}`))
+		req.Header.Set("Content-Type", "application/json")
+		webtest.RequestWithSignedInUser(req, editor)
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		require.NotNil(t, scheduleService.created)
+		assert.Equal(t, int64(10), scheduleService.created.UserId)
+		assert.Equal(t, int64(1), scheduleService.created.OrgId)
+		assert.Equal(t, int64(30), scheduleService.created.RetentionCount)
+	})
+
+	t.Run("Should only let the owner or an admin update a schedule", func(t *testing.T) {
+		scheduleService.schedules = []*dashboardsnapshots.SnapshotSchedule{
+			{Uid: "owned", DashboardUid: "edit", UserId: 10},
+			{Uid: "other", DashboardUid: "edit", UserId: 20},
+		}
+
+		for _, tc := range []struct {
+			uid      string
+			user     *user.SignedInUser
+			expected int
+		}{
+			{uid: "owned", user: editor, expected: http.StatusOK},
+			{uid: "other", user: editor, expected: http.StatusForbidden},
+			{uid: "other", user: &user.SignedInUser{UserID: 30, OrgID: 1, OrgRole: org.RoleAdmin}, expected: http.StatusOK},
+		} {
+			req := server.NewRequest(http.MethodPut, "/api/dashboard/snapshot-schedules/"+tc.uid, strings.NewReader(`{"schedule": "@daily"}`))
+			req.Header.Set("Content-Type", "application/json")
+			webtest.RequestWithSignedInUser(req, tc.user)
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tc.expected, res.StatusCode, tc.uid)
+		}
+
+		req := server.NewPostRequest("/api/dashboard/snapshot-schedules/other/run", nil)
+		webtest.RequestWithSignedInUser(req, editor)
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
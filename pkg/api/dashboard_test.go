@@ -861,6 +861,59 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}, mockSQLStore)
 	})
 
+	t.Run("Given selected panels being restored should only restore those panels", func(t *testing.T) {
+		fakeDash := models.NewDashboard("Child dash")
+		fakeDash.Id = 2
+		fakeDash.Version = 3
+		fakeDash.Data.Set("panels", []interface{}{
+			map[string]interface{}{"id": 1, "title": "Current panel 1"},
+			map[string]interface{}{"id": 2, "title": "Current panel 2"},
+		})
+
+		oldData := simplejson.NewFromAny(map[string]interface{}{
+			"title": "Old title",
+			"panels": []interface{}{
+				map[string]interface{}{"id": 1, "title": "Old panel 1"},
+				map[string]interface{}{"id": 2, "title": "Old panel 2"},
+			},
+		})
+
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+			q := args.Get(1).(*models.GetDashboardQuery)
+			q.Result = fakeDash
+		}).Return(nil)
+		var savedDTO *dashboards.SaveDashboardDTO
+		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
+			savedDTO = args.Get(1).(*dashboards.SaveDashboardDTO)
+		}).Return(&models.Dashboard{Id: 2, Uid: "uid", Title: "Dash", Slug: "dash", Version: 4}, nil)
+
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersions = []*dashver.DashboardVersion{
+			{
+				DashboardID: 2,
+				Version:     1,
+				Data:        oldData,
+			}}
+
+		cmd := dtos.RestoreDashboardVersionCommand{
+			Version: 1,
+			Panels:  []int64{2},
+		}
+		mockSQLStore := mockstore.NewSQLStoreMock()
+		restoreDashboardVersionScenario(t, "When calling POST on", "/api/dashboards/id/2/restore",
+			"/api/dashboards/id/:dashboardId/restore", dashboardService, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+				callRestoreDashboardVersion(sc)
+				assert.Equal(t, 200, sc.resp.Code)
+				require.NotNil(t, savedDTO)
+				data := savedDTO.Dashboard.Data
+				assert.Equal(t, "Child dash", data.Get("title").MustString())
+				assert.Equal(t, "Current panel 1", data.Get("panels").GetIndex(0).Get("title").MustString())
+				assert.Equal(t, "Old panel 2", data.Get("panels").GetIndex(1).Get("title").MustString())
+				assert.Equal(t, "Restored 1 panel from version 1", savedDTO.Message)
+			}, mockSQLStore)
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		mockSQLStore := mockstore.NewSQLStoreMock()
 		dashboardStore := dashboards.NewFakeDashboardStore(t)
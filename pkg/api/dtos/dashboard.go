@@ -36,6 +36,10 @@ type DashboardMeta struct {
 	PublicDashboardAccessToken string                `json:"publicDashboardAccessToken"`
 	PublicDashboardUID         string                `json:"publicDashboardUid"`
 	PublicDashboardEnabled     bool                  `json:"publicDashboardEnabled"`
+
+	PublicDashboardTimeSelectionEnabled bool   `json:"publicDashboardTimeSelectionEnabled,omitempty"`
+	PublicDashboardMaxTimeRange         string `json:"publicDashboardMaxTimeRange,omitempty"`
+	PublicDashboardAnnotationsEnabled   bool   `json:"publicDashboardAnnotationsEnabled,omitempty"`
 }
 type AnnotationPermission struct {
 	Dashboard    AnnotationActions `json:"dashboard"`
@@ -76,4 +80,9 @@ type CalculateDiffTarget struct {
 
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
+	// Panels are the ids of the panels to restore. When panels or variables are set,
+	// only those are restored from the version into the current dashboard.
+	Panels []int64 `json:"panels"`
+	// Variables are the names of the template variables to restore.
+	Variables []string `json:"variables"`
 }
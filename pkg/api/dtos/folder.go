@@ -9,6 +9,7 @@ import (
 type Folder struct {
 	Id            int64                  `json:"id"`
 	Uid           string                 `json:"uid"`
+	ParentUid     string                 `json:"parentUid,omitempty"`
 	Title         string                 `json:"title"`
 	Url           string                 `json:"url"`
 	HasACL        bool                   `json:"hasAcl" xorm:"has_acl"`
@@ -27,6 +28,7 @@ type Folder struct {
 type FolderSearchHit struct {
 	Id            int64                  `json:"id"`
 	Uid           string                 `json:"uid"`
+	ParentUid     string                 `json:"parentUid,omitempty"`
 	Title         string                 `json:"title"`
 	AccessControl accesscontrol.Metadata `json:"accessControl,omitempty"`
 }
@@ -26,6 +26,7 @@ type PluginSetting struct {
 	Signature     plugins.SignatureStatus `json:"signature"`
 	SignatureType plugins.SignatureType   `json:"signatureType"`
 	SignatureOrg  string                  `json:"signatureOrg"`
+	ProcessStatus *plugins.ProcessStatus  `json:"processStatus,omitempty"`
 }
 
 type PluginListItem struct {
@@ -45,6 +46,7 @@ type PluginListItem struct {
 	SignatureType plugins.SignatureType   `json:"signatureType"`
 	SignatureOrg  string                  `json:"signatureOrg"`
 	AccessControl accesscontrol.Metadata  `json:"accessControl,omitempty"`
+	ProcessStatus *plugins.ProcessStatus  `json:"processStatus,omitempty"`
 }
 
 type PluginList []PluginListItem
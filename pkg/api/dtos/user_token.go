@@ -14,3 +14,12 @@ type UserToken struct {
 	CreatedAt              time.Time `json:"createdAt"`
 	SeenAt                 time.Time `json:"seenAt"`
 }
+
+// UserSession is a user auth token listed in the organization sessions API.
+type UserSession struct {
+	UserToken
+	UserId    int64  `json:"userId"`
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	UserAgent string `json:"userAgent"`
+}
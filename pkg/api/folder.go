@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/util"
@@ -22,6 +23,7 @@ import (
 // Get all folders.
 //
 // Returns all folders that the authenticated user has permission to view.
+// If nested folders are enabled and `parentUid` is set, only the folders directly nested in that folder are returned.
 //
 // Responses:
 // 200: getFoldersResponse
@@ -29,7 +31,13 @@ import (
 // 403: forbiddenError
 // 500: internalServerError
 func (hs *HTTPServer) GetFolders(c *models.ReqContext) response.Response {
-	folders, err := hs.folderService.GetFolders(c.Req.Context(), c.SignedInUser, c.OrgID, c.QueryInt64("limit"), c.QueryInt64("page"))
+	var folders []*models.Folder
+	var err error
+	if parentUID := c.Query("parentUid"); parentUID != "" && hs.Features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		folders, err = hs.folderService.GetChildFolders(c.Req.Context(), c.SignedInUser, c.OrgID, parentUID)
+	} else {
+		folders, err = hs.folderService.GetFolders(c.Req.Context(), c.SignedInUser, c.OrgID, c.QueryInt64("limit"), c.QueryInt64("page"))
+	}
 
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
@@ -40,9 +48,10 @@ func (hs *HTTPServer) GetFolders(c *models.ReqContext) response.Response {
 	for _, f := range folders {
 		uids[f.Uid] = true
 		result = append(result, dtos.FolderSearchHit{
-			Id:    f.Id,
-			Uid:   f.Uid,
-			Title: f.Title,
+			Id:        f.Id,
+			Uid:       f.Uid,
+			Title:     f.Title,
+			ParentUid: f.ParentUid,
 		})
 	}
 
@@ -118,7 +127,13 @@ func (hs *HTTPServer) CreateFolder(c *models.ReqContext) response.Response {
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
-	folder, err := hs.folderService.CreateFolder(c.Req.Context(), c.SignedInUser, c.OrgID, cmd.Title, cmd.Uid)
+	var folder *models.Folder
+	var err error
+	if cmd.ParentUid != "" {
+		folder, err = hs.folderService.CreateNestedFolder(c.Req.Context(), c.SignedInUser, c.OrgID, cmd.Title, cmd.Uid, cmd.ParentUid)
+	} else {
+		folder, err = hs.folderService.CreateFolder(c.Req.Context(), c.SignedInUser, c.OrgID, cmd.Title, cmd.Uid)
+	}
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
 	}
@@ -152,6 +167,65 @@ func (hs *HTTPServer) UpdateFolder(c *models.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, hs.toFolderDto(c, g, cmd.Result))
 }
 
+// swagger:route POST /folders/{folder_uid}/move folders moveFolder
+//
+// Move folder.
+//
+// Moves a folder, along with everything it contains, inside another folder or to the root level if `parentUid` is empty.
+// Requires the nestedFolders feature toggle.
+//
+// Responses:
+// 200: folderResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) MoveFolder(c *models.ReqContext) response.Response {
+	cmd := models.MoveFolderCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	err := hs.folderService.MoveFolder(c.Req.Context(), c.SignedInUser, c.OrgID, web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+	g := guardian.New(c.Req.Context(), cmd.Result.Id, c.OrgID, c.SignedInUser)
+	return response.JSON(http.StatusOK, hs.toFolderDto(c, g, cmd.Result))
+}
+
+// swagger:route GET /folders/{folder_uid}/parents folders getFolderParents
+//
+// Get folder parents.
+//
+// Returns the folders containing the folder identified by uid, starting with the root level folder.
+//
+// Responses:
+// 200: getFoldersResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderParents(c *models.ReqContext) response.Response {
+	parents, err := hs.folderService.GetFolderParents(c.Req.Context(), c.SignedInUser, c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	result := make([]dtos.FolderSearchHit, 0, len(parents))
+	for _, f := range parents {
+		result = append(result, dtos.FolderSearchHit{
+			Id:        f.Id,
+			Uid:       f.Uid,
+			Title:     f.Title,
+			ParentUid: f.ParentUid,
+		})
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 // swagger:route DELETE /folders/{folder_uid} folders deleteFolder
 //
 // Delete folder.
@@ -166,7 +240,7 @@ func (hs *HTTPServer) UpdateFolder(c *models.ReqContext) response.Response {
 // 404: notFoundError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
-	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	err := hs.deleteLibraryElementsInFolderTree(c, web.Params(c.Req)[":uid"])
 	if err != nil {
 		if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
 			return response.Error(403, "Folder could not be deleted because it contains library elements in use", err)
@@ -187,6 +261,23 @@ func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { //
 	})
 }
 
+// deleteLibraryElementsInFolderTree deletes the library elements of a folder and, if nested folders are enabled,
+// of all the folders nested in it, since those are deleted along with their parent.
+func (hs *HTTPServer) deleteLibraryElementsInFolderTree(c *models.ReqContext, uid string) error {
+	if hs.Features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		children, err := hs.folderService.GetChildFolders(c.Req.Context(), c.SignedInUser, c.OrgID, uid)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := hs.deleteLibraryElementsInFolderTree(c, child.Uid); err != nil {
+				return err
+			}
+		}
+	}
+	return hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, uid)
+}
+
 func (hs *HTTPServer) toFolderDto(c *models.ReqContext, g guardian.DashboardGuardian, folder *models.Folder) dtos.Folder {
 	canEdit, _ := g.CanEdit()
 	canSave, _ := g.CanSave()
@@ -205,6 +296,7 @@ func (hs *HTTPServer) toFolderDto(c *models.ReqContext, g guardian.DashboardGuar
 	return dtos.Folder{
 		Id:            folder.Id,
 		Uid:           folder.Uid,
+		ParentUid:     folder.ParentUid,
 		Title:         folder.Title,
 		Url:           folder.Url,
 		HasACL:        folder.HasACL,
@@ -233,6 +325,11 @@ type GetFoldersParams struct {
 	// required:false
 	// default:1
 	Page int64 `json:"page"`
+	// Only return the folders directly nested in the folder with this uid.
+	// Requires the nestedFolders feature toggle.
+	// in:query
+	// required:false
+	ParentUID string `json:"parentUid"`
 }
 
 // swagger:parameters getFolderByUID
@@ -270,6 +367,23 @@ type CreateFolderParams struct {
 	Body models.CreateFolderCommand `json:"body"`
 }
 
+// swagger:parameters moveFolder
+type MoveFolderParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:body
+	// required:true
+	Body models.MoveFolderCommand `json:"body"`
+}
+
+// swagger:parameters getFolderParents
+type GetFolderParentsParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+}
+
 // swagger:parameters deleteFolder
 type DeleteFolderParams struct {
 	// in:path
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
@@ -229,6 +230,11 @@ func handleCallResourceError(err error, reqCtx *models.ReqContext) {
 		return
 	}
 
+	if errors.Is(err, plugins.ErrPluginRequestTimeout) {
+		reqCtx.JsonApiErr(504, "Plugin request timed out", err)
+		return
+	}
+
 	reqCtx.JsonApiErr(500, "Failed to call resource", err)
 }
 
@@ -127,6 +127,7 @@ func (hs *HTTPServer) GetPluginList(c *models.ReqContext) response.Response {
 			SignatureType: pluginDef.SignatureType,
 			SignatureOrg:  pluginDef.SignatureOrg,
 			AccessControl: pluginsMetadata[pluginDef.ID],
+			ProcessStatus: pluginDef.ProcessStatus,
 		}
 
 		update, exists := hs.pluginsUpdateChecker.HasUpdate(c.Req.Context(), pluginDef.ID)
@@ -184,6 +185,7 @@ func (hs *HTTPServer) GetPluginSettingByID(c *models.ReqContext) response.Respon
 		SignatureType:    plugin.SignatureType,
 		SignatureOrg:     plugin.SignatureOrg,
 		SecureJsonFields: map[string]bool{},
+		ProcessStatus:    plugin.ProcessStatus,
 	}
 
 	if plugin.IsApp() {
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -98,49 +100,73 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 			isActive = true
 		}
 
-		parser := uaparser.NewFromSaved()
-		client := parser.Parse(token.UserAgent)
+		result = append(result, userTokenDTO(token, isActive))
+	}
 
-		osVersion := ""
-		if client.Os.Major != "" {
-			osVersion = client.Os.Major
+	return response.JSON(http.StatusOK, result)
+}
 
-			if client.Os.Minor != "" {
-				osVersion = osVersion + "." + client.Os.Minor
-			}
-		}
+func userTokenDTO(token *models.UserToken, isActive bool) *dtos.UserToken {
+	parser := uaparser.NewFromSaved()
+	client := parser.Parse(token.UserAgent)
 
-		browserVersion := ""
-		if client.UserAgent.Major != "" {
-			browserVersion = client.UserAgent.Major
+	osVersion := ""
+	if client.Os.Major != "" {
+		osVersion = client.Os.Major
 
-			if client.UserAgent.Minor != "" {
-				browserVersion = browserVersion + "." + client.UserAgent.Minor
-			}
+		if client.Os.Minor != "" {
+			osVersion = osVersion + "." + client.Os.Minor
 		}
+	}
 
-		createdAt := time.Unix(token.CreatedAt, 0)
-		seenAt := time.Unix(token.SeenAt, 0)
+	browserVersion := ""
+	if client.UserAgent.Major != "" {
+		browserVersion = client.UserAgent.Major
 
-		if token.SeenAt == 0 {
-			seenAt = createdAt
+		if client.UserAgent.Minor != "" {
+			browserVersion = browserVersion + "." + client.UserAgent.Minor
 		}
+	}
 
-		result = append(result, &dtos.UserToken{
-			Id:                     token.Id,
-			IsActive:               isActive,
-			ClientIp:               token.ClientIp,
-			Device:                 client.Device.ToString(),
-			OperatingSystem:        client.Os.Family,
-			OperatingSystemVersion: osVersion,
-			Browser:                client.UserAgent.Family,
-			BrowserVersion:         browserVersion,
-			CreatedAt:              createdAt,
-			SeenAt:                 seenAt,
-		})
+	createdAt := time.Unix(token.CreatedAt, 0)
+	seenAt := time.Unix(token.SeenAt, 0)
+
+	if token.SeenAt == 0 {
+		seenAt = createdAt
 	}
 
-	return response.JSON(http.StatusOK, result)
+	return &dtos.UserToken{
+		Id:                     token.Id,
+		IsActive:               isActive,
+		ClientIp:               token.ClientIp,
+		Device:                 client.Device.ToString(),
+		OperatingSystem:        client.Os.Family,
+		OperatingSystemVersion: osVersion,
+		Browser:                client.UserAgent.Family,
+		BrowserVersion:         browserVersion,
+		CreatedAt:              createdAt,
+		SeenAt:                 seenAt,
+	}
+}
+
+// publishSessionRevoked records that the session of a user was revoked by an administrator.
+func (hs *HTTPServer) publishSessionRevoked(c *models.ReqContext, token *models.UserToken) {
+	if token.UserId == c.UserID {
+		return
+	}
+
+	hs.log.Info("user auth token revoked by admin", "tokenId", token.Id, "userId", token.UserId, "clientIP", token.ClientIp, "userAgent", token.UserAgent, "revokedBy", c.UserID)
+	if err := hs.bus.Publish(c.Req.Context(), &events.UserSessionRevoked{
+		Timestamp: time.Now(),
+		TokenID:   token.Id,
+		UserID:    token.UserId,
+		ClientIP:  token.ClientIp,
+		UserAgent: token.UserAgent,
+		Reason:    events.UserSessionRevokedReasonAdmin,
+		RevokedBy: c.UserID,
+	}); err != nil {
+		hs.log.Error("failed to publish user session revoked event", "tokenId", token.Id, "error", err)
+	}
 }
 
 func (hs *HTTPServer) revokeUserAuthTokenInternal(c *models.ReqContext, userID int64, cmd models.RevokeAuthTokenCmd) response.Response {
@@ -173,6 +199,118 @@ func (hs *HTTPServer) revokeUserAuthTokenInternal(c *models.ReqContext, userID i
 		return response.Error(500, "Failed to revoke user auth token", err)
 	}
 
+	hs.publishSessionRevoked(c, token)
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "User auth token revoked",
+	})
+}
+
+// swagger:route GET /orgs/{org_id}/sessions orgs getOrgSessions
+//
+// Get the active sessions of all users in the organization.
+//
+// Returns the auth tokens (devices) of every member of the organization, including client IP and user agent.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.authtoken:read` and scope `global.users:*`.
+//
+// Responses:
+// 200: getOrgSessionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetOrgSessions(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	tokens, err := hs.AuthTokenService.GetOrgUserTokens(c.Req.Context(), orgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get organization sessions", err)
+	}
+
+	users := map[int64]*user.User{}
+	result := []*dtos.UserSession{}
+	for _, token := range tokens {
+		usr, ok := users[token.UserId]
+		if !ok {
+			usr, err = hs.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: token.UserId})
+			if err != nil {
+				if errors.Is(err, user.ErrUserNotFound) {
+					continue
+				}
+				return response.Error(http.StatusInternalServerError, "Failed to get user", err)
+			}
+			users[token.UserId] = usr
+		}
+
+		isActive := c.UserToken != nil && c.UserToken.Id == token.Id
+		result = append(result, &dtos.UserSession{
+			UserToken: *userTokenDTO(token, isActive),
+			UserId:    token.UserId,
+			Login:     usr.Login,
+			Email:     usr.Email,
+			UserAgent: token.UserAgent,
+		})
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /orgs/{org_id}/sessions/{session_id} orgs revokeOrgSession
+//
+// Revoke a session of a user in the organization.
+//
+// The user of the revoked auth token (device) will be required to authenticate again upon next activity.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.authtoken:write` and scope `global.users:*`.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RevokeOrgSession(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	sessionID, err := strconv.ParseInt(web.Params(c.Req)[":sessionId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "sessionId is invalid", err)
+	}
+
+	tokens, err := hs.AuthTokenService.GetOrgUserTokens(c.Req.Context(), orgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get organization sessions", err)
+	}
+
+	var token *models.UserToken
+	for _, t := range tokens {
+		if t.Id == sessionID {
+			token = t
+			break
+		}
+	}
+	if token == nil {
+		return response.Error(http.StatusNotFound, "User auth token not found", nil)
+	}
+
+	if c.UserToken != nil && c.UserToken.Id == token.Id {
+		return response.Error(http.StatusBadRequest, "Cannot revoke active user auth token", nil)
+	}
+
+	if err := hs.AuthTokenService.RevokeToken(c.Req.Context(), token, false); err != nil {
+		if errors.Is(err, models.ErrUserTokenNotFound) {
+			return response.Error(http.StatusNotFound, "User auth token not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to revoke user auth token", err)
+	}
+
+	hs.publishSessionRevoked(c, token)
+
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "User auth token revoked",
 	})
@@ -190,3 +328,26 @@ type GetUserAuthTokensResponse struct {
 	// in:body
 	Body []*models.UserToken `json:"body"`
 }
+
+// swagger:parameters getOrgSessions
+type GetOrgSessionsParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:parameters revokeOrgSession
+type RevokeOrgSessionParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+	// in:path
+	// required:true
+	SessionID int64 `json:"session_id"`
+}
+
+// swagger:response getOrgSessionsResponse
+type GetOrgSessionsResponse struct {
+	// in:body
+	Body []*dtos.UserSession `json:"body"`
+}
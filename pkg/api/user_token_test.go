@@ -10,11 +10,16 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func TestUserTokenAPIEndpoint(t *testing.T) {
@@ -143,6 +148,100 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 			assert.Equal(t, "11.0", resultTwo.Get("osVersion").MustString())
 		}, mockUser)
 	})
+
+	t.Run("When getting the sessions of an organization", func(t *testing.T) {
+		mockUser := &usertest.FakeUserService{
+			ExpectedUser: &user.User{ID: 200, Login: "user200", Email: "user200@example.org"},
+		}
+		orgSessionsScenario(t, "Should return the sessions with user details", func(sc *scenarioContext, _ *[]*events.UserSessionRevoked) {
+			sc.userAuthTokenService.GetOrgUserTokensProvider = func(ctx context.Context, orgId int64) ([]*models.UserToken, error) {
+				assert.Equal(t, int64(2), orgId)
+				return []*models.UserToken{
+					{Id: 3, UserId: 200, ClientIp: "127.0.0.1", UserAgent: "curl/7.79.1", CreatedAt: time.Now().Unix()},
+				}, nil
+			}
+			sc.fakeReqWithParams("GET", "/api/orgs/2/sessions", map[string]string{}).exec()
+
+			assert.Equal(t, 200, sc.resp.Code)
+			result := sc.ToJSON()
+			assert.Len(t, result.MustArray(), 1)
+			session := result.GetIndex(0)
+			assert.Equal(t, int64(3), session.Get("id").MustInt64())
+			assert.Equal(t, int64(200), session.Get("userId").MustInt64())
+			assert.Equal(t, "user200", session.Get("login").MustString())
+			assert.Equal(t, "127.0.0.1", session.Get("clientIp").MustString())
+			assert.Equal(t, "curl/7.79.1", session.Get("userAgent").MustString())
+		}, mockUser)
+	})
+
+	t.Run("When revoking a session of an organization", func(t *testing.T) {
+		mockUser := usertest.NewUserServiceFake()
+		orgSessionsScenario(t, "Should revoke the session and publish an event", func(sc *scenarioContext, revoked *[]*events.UserSessionRevoked) {
+			sc.userAuthTokenService.GetOrgUserTokensProvider = func(ctx context.Context, orgId int64) ([]*models.UserToken, error) {
+				return []*models.UserToken{{Id: 3, UserId: 200}}, nil
+			}
+			var revokedToken *models.UserToken
+			sc.userAuthTokenService.RevokeTokenProvider = func(ctx context.Context, token *models.UserToken, soft bool) error {
+				revokedToken = token
+				return nil
+			}
+			sc.fakeReqWithParams("DELETE", "/api/orgs/2/sessions/3", map[string]string{}).exec()
+
+			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, int64(3), revokedToken.Id)
+			assert.Len(t, *revoked, 1)
+			assert.Equal(t, int64(200), (*revoked)[0].UserID)
+			assert.Equal(t, int64(testUserID), (*revoked)[0].RevokedBy)
+			assert.Equal(t, events.UserSessionRevokedReasonAdmin, (*revoked)[0].Reason)
+		}, mockUser)
+
+		orgSessionsScenario(t, "Should return not found for a session outside of the organization", func(sc *scenarioContext, revoked *[]*events.UserSessionRevoked) {
+			sc.userAuthTokenService.GetOrgUserTokensProvider = func(ctx context.Context, orgId int64) ([]*models.UserToken, error) {
+				return []*models.UserToken{{Id: 3, UserId: 200}}, nil
+			}
+			sc.fakeReqWithParams("DELETE", "/api/orgs/2/sessions/4", map[string]string{}).exec()
+
+			assert.Equal(t, 404, sc.resp.Code)
+			assert.Empty(t, *revoked)
+		}, mockUser)
+	})
+}
+
+func orgSessionsScenario(t *testing.T, desc string, fn func(sc *scenarioContext, revoked *[]*events.UserSessionRevoked), userService user.Service) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := auth.NewFakeUserAuthTokenService()
+		eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+
+		var revoked []*events.UserSessionRevoked
+		eventBus.AddEventListener(func(_ context.Context, e *events.UserSessionRevoked) error {
+			revoked = append(revoked, e)
+			return nil
+		})
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+			userService:      userService,
+			bus:              eventBus,
+			log:              log.New("test"),
+		}
+
+		sc := setupScenarioContext(t, "/api/orgs/2/sessions")
+		sc.userAuthTokenService = fakeAuthTokenService
+		handler := func(h func(c *models.ReqContext) response.Response) web.Handler {
+			return routing.Wrap(func(c *models.ReqContext) response.Response {
+				sc.context = c
+				sc.context.UserID = testUserID
+				sc.context.OrgID = testOrgID
+				sc.context.OrgRole = org.RoleAdmin
+
+				return h(c)
+			})
+		}
+		sc.m.Get("/api/orgs/:orgId/sessions", handler(hs.GetOrgSessions))
+		sc.m.Delete("/api/orgs/:orgId/sessions/:sessionId", handler(hs.RevokeOrgSession))
+
+		fn(sc, &revoked)
+	})
 }
 
 func revokeUserAuthTokenScenario(t *testing.T, desc string, url string, routePattern string, cmd models.RevokeAuthTokenCmd,
@@ -230,6 +329,8 @@ func revokeUserAuthTokenInternalScenario(t *testing.T, desc string, cmd models.R
 		hs := HTTPServer{
 			AuthTokenService: fakeAuthTokenService,
 			userService:      userService,
+			bus:              bus.ProvideBus(tracing.InitializeTracerForTest()),
+			log:              log.New("test"),
 		}
 
 		sc := setupScenarioContext(t, "/")
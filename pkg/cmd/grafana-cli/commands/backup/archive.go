@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// archiveVersion is the version of the format of the archives. It must be incremented when
+	// the format changes in a way older versions of Grafana cannot restore.
+	archiveVersion = 1
+	manifestFile   = "manifest.json"
+)
+
+// maxArchiveFileSize limits the size of the files read from an archive.
+const maxArchiveFileSize = 1 << 30
+
+type manifest struct {
+	Version        int            `json:"version"`
+	GrafanaVersion string         `json:"grafanaVersion"`
+	Created        time.Time      `json:"created"`
+	Rows           map[string]int `json:"rows"`
+	// KeyCheck is a known value encrypted with the key of the archive, to verify the key on
+	// restore, as decrypting with another key does not fail.
+	KeyCheck []byte `json:"keyCheck"`
+}
+
+// row is a row of a table, by column name.
+type row map[string]interface{}
+
+// archive is a backup of an instance. It is stored as a gzipped tarball containing the manifest
+// and a JSON file with the rows of each table.
+type archive struct {
+	manifest manifest
+	tables   map[string][]row
+}
+
+func writeArchive(w io.Writer, a *archive) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	writeFile := func(name string, v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(b)),
+			ModTime: a.manifest.Created,
+		}); err != nil {
+			return err
+		}
+		_, err = tw.Write(b)
+		return err
+	}
+
+	if err := writeFile(manifestFile, a.manifest); err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := writeFile(t.name+".json", a.tables[t.name]); err != nil {
+			return fmt.Errorf("failed to write rows of %s: %w", t.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func readArchive(r io.Reader) (*archive, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	tr := tar.NewReader(gr)
+
+	a := &archive{tables: map[string][]row{}}
+	var hasManifest bool
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		b, err := io.ReadAll(io.LimitReader(tr, maxArchiveFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", header.Name, err)
+		}
+		// Numbers are kept as is, so that IDs are not rounded.
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		decoder.UseNumber()
+
+		if header.Name == manifestFile {
+			if err := decoder.Decode(&a.manifest); err != nil {
+				return nil, fmt.Errorf("failed to parse manifest of archive: %w", err)
+			}
+			if a.manifest.Version != archiveVersion {
+				return nil, fmt.Errorf("archive version %d is not supported by this version of Grafana, which supports version %d", a.manifest.Version, archiveVersion)
+			}
+			hasManifest = true
+			continue
+		}
+
+		var rows []row
+		if err := decoder.Decode(&rows); err != nil {
+			return nil, fmt.Errorf("failed to parse %s from archive: %w", header.Name, err)
+		}
+		a.tables[strings.TrimSuffix(header.Name, ".json")] = rows
+	}
+
+	if !hasManifest {
+		return nil, errors.New("archive has no manifest")
+	}
+	return a, nil
+}
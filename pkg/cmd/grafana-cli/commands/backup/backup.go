@@ -0,0 +1,189 @@
+// Package backup implements the backup and restore of the persistent entities of Grafana.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/runner"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// keyCheck is encrypted with the key of an archive to verify the key on restore.
+var keyCheck = []byte("grafana-backup")
+
+// service backs up and restores the entities of the database of an instance. The secrets of the
+// entities are decrypted with the secrets service of the instance, and encrypted with the key of
+// the archive, so that the archive can be restored into an instance with another secret key.
+type service struct {
+	store             *sqlstore.SQLStore
+	secretsService    secrets.Service
+	encryptionService encryption.Internal
+	archiveKey        string
+	grafanaVersion    string
+}
+
+func newService(runner runner.Runner, archiveKey string) *service {
+	if archiveKey == "" {
+		archiveKey = runner.Cfg.SecretKey
+	}
+	return &service{
+		store:             runner.SQLStore,
+		secretsService:    runner.SecretsService,
+		encryptionService: runner.EncryptionService,
+		archiveKey:        archiveKey,
+		grafanaVersion:    runner.Cfg.BuildVersion,
+	}
+}
+
+// Backup writes the entities of the instance to the archive at the path given as argument.
+func Backup(c utils.CommandLine, runner runner.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("path of the archive is required")
+	}
+
+	s := newService(runner, c.String("encryption-key"))
+	a, err := s.backup(context.Background())
+	if err != nil {
+		return err
+	}
+
+	// We can ignore the gosec G304 warning since the path is given by the administrator.
+	// nolint:gosec
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := writeArchive(f, a); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	for _, t := range tables {
+		logger.Infof("%s Backed up %d rows of %s\n", color.GreenString("✔"), a.manifest.Rows[t.name], t.name)
+	}
+	logger.Infof("\nBackup written to %s\n", path)
+	return nil
+}
+
+func (s *service) backup(ctx context.Context) (*archive, error) {
+	a := &archive{
+		manifest: manifest{
+			Version:        archiveVersion,
+			GrafanaVersion: s.grafanaVersion,
+			Created:        time.Now().UTC(),
+			Rows:           map[string]int{},
+		},
+		tables: map[string][]row{},
+	}
+
+	var err error
+	if a.manifest.KeyCheck, err = s.encryptionService.Encrypt(ctx, keyCheck, s.archiveKey); err != nil {
+		return nil, err
+	}
+
+	err = s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, t := range tables {
+			query := "SELECT * FROM " + s.store.Quote(t.name)
+			if t.where != "" {
+				query += " WHERE " + t.where
+			}
+			query += " ORDER BY " + t.orderBy
+
+			results, err := sess.QueryInterface(query)
+			if err != nil {
+				return fmt.Errorf("failed to read rows of %s: %w", t.name, err)
+			}
+
+			rows := make([]row, 0, len(results))
+			for _, result := range results {
+				r := row{}
+				for col, v := range result {
+					// Drivers return text columns as bytes.
+					if b, ok := v.([]byte); ok {
+						v = string(b)
+					}
+					r[col] = v
+				}
+				rows = append(rows, r)
+			}
+			a.tables[t.name] = rows
+			a.manifest.Rows[t.name] = len(rows)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The secrets service must not be used within a session, as it may use the database.
+	for _, t := range tables {
+		if err := s.transformSecrets(a, t, s.exportSecret(ctx)); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// exportSecret decrypts a secret of the instance and encrypts it with the key of the archive.
+func (s *service) exportSecret(ctx context.Context) func([]byte) ([]byte, error) {
+	return func(encrypted []byte) ([]byte, error) {
+		decrypted, err := s.secretsService.Decrypt(ctx, encrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+		}
+		return s.encryptionService.Encrypt(ctx, decrypted, s.archiveKey)
+	}
+}
+
+// checkArchiveKey verifies that the secrets of the archive were encrypted with the key of the service.
+func (s *service) checkArchiveKey(ctx context.Context, m manifest) error {
+	decrypted, err := s.encryptionService.Decrypt(ctx, m.KeyCheck, s.archiveKey)
+	if err != nil || !bytes.Equal(decrypted, keyCheck) {
+		return errors.New("failed to decrypt the secrets of the archive, check that the encryption key is the one used for the backup")
+	}
+	return nil
+}
+
+// importSecret decrypts a secret of the archive and encrypts it with the secrets service of the instance.
+func (s *service) importSecret(ctx context.Context) func([]byte) ([]byte, error) {
+	return func(encrypted []byte) ([]byte, error) {
+		decrypted, err := s.encryptionService.Decrypt(ctx, encrypted, s.archiveKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret of archive: %w", err)
+		}
+		return s.secretsService.Encrypt(ctx, decrypted, secrets.WithoutScope())
+	}
+}
+
+func (s *service) transformSecrets(a *archive, t table, fn func([]byte) ([]byte, error)) error {
+	for col, format := range t.secrets {
+		for _, r := range a.tables[t.name] {
+			value, ok := r[col].(string)
+			if !ok {
+				continue
+			}
+			transformed, err := transformSecrets(format, value, fn)
+			if err != nil {
+				return fmt.Errorf("failed to re-encrypt %s of %s %v: %w", col, t.name, r["id"], err)
+			}
+			r[col] = transformed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	encryptionservice "github.com/grafana/grafana/pkg/services/encryption/service"
+	"github.com/grafana/grafana/pkg/services/org"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	store := sqlstore.InitTestDB(t)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(store))
+	s := &service{
+		store:             store,
+		secretsService:    secretsService,
+		encryptionService: encryptionservice.SetupTestService(t),
+		archiveKey:        "archive-key",
+		grafanaVersion:    "9.2.0",
+	}
+
+	seed := seedInstance(t, store, secretsService)
+
+	a, err := s.backup(ctx)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeArchive(&buf, a))
+	archived := buf.Bytes()
+	restored := func() *archive {
+		a, err := readArchive(bytes.NewReader(archived))
+		require.NoError(t, err)
+		return a
+	}
+
+	t.Run("archive should contain the entities with re-encrypted secrets", func(t *testing.T) {
+		a := restored()
+		require.Equal(t, archiveVersion, a.manifest.Version)
+		require.Equal(t, "9.2.0", a.manifest.GrafanaVersion)
+		require.Equal(t, 3, a.manifest.Rows["dashboard"])
+		require.Len(t, a.tables["data_source"], 1)
+
+		var secureJSONData map[string][]byte
+		require.NoError(t, json.Unmarshal([]byte(a.tables["data_source"][0]["secure_json_data"].(string)), &secureJSONData))
+		decrypted, err := s.encryptionService.Decrypt(ctx, secureJSONData["password"], "archive-key")
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(decrypted))
+	})
+
+	t.Run("restore should fail with another encryption key", func(t *testing.T) {
+		other := *s
+		other.archiveKey = "another-key"
+		_, err := other.restore(ctx, restored(), ConflictSkip)
+		require.ErrorContains(t, err, "check that the encryption key is the one used for the backup")
+	})
+
+	t.Run("restore should recreate deleted entities with their references", func(t *testing.T) {
+		deleteOrg(t, store, seed.orgID)
+
+		stats, err := s.restore(ctx, restored(), ConflictSkip)
+		require.NoError(t, err)
+		require.Equal(t, 1, stats["org"].created)
+		require.Equal(t, 3, stats["dashboard"].created)
+		require.Equal(t, 1, stats["team_member"].created)
+
+		orgID := queryInt(t, store, `SELECT id FROM org WHERE name = ?`, "Backup Org")
+		require.NotEqual(t, seed.orgID, orgID)
+		userID := queryInt(t, store, `SELECT id FROM `+store.Quote("user")+` WHERE login = ?`, "editor")
+		folderID := queryInt(t, store, `SELECT id FROM dashboard WHERE org_id = ? AND uid = ?`, orgID, "folder")
+		dashboardID := queryInt(t, store, `SELECT id FROM dashboard WHERE org_id = ? AND uid = ?`, orgID, "dashboard")
+		parentID := queryInt(t, store, `SELECT id FROM dashboard WHERE org_id = ? AND uid = ?`, orgID, "parent")
+		teamID := queryInt(t, store, `SELECT id FROM team WHERE org_id = ?`, orgID)
+
+		require.Equal(t, orgID, queryInt(t, store, `SELECT org_id FROM `+store.Quote("user")+` WHERE id = ?`, userID))
+		require.Equal(t, folderID, queryInt(t, store, `SELECT folder_id FROM dashboard WHERE id = ?`, dashboardID))
+		require.Equal(t, parentID, queryInt(t, store, `SELECT folder_id FROM dashboard WHERE id = ?`, folderID))
+		require.Equal(t, teamID, queryInt(t, store, `SELECT team_id FROM dashboard_acl WHERE org_id = ? AND dashboard_id = ?`, orgID, folderID))
+		require.Equal(t, userID, queryInt(t, store, `SELECT created_by FROM dashboard WHERE id = ?`, dashboardID))
+		require.Equal(t, userID, queryInt(t, store, `SELECT user_id FROM team_member WHERE org_id = ?`, orgID))
+		require.Equal(t, dashboardID, queryInt(t, store, `SELECT home_dashboard_id FROM preferences WHERE user_id = ?`, userID))
+		require.Equal(t, dashboardID, queryInt(t, store, `SELECT connection_id FROM library_element_connection WHERE element_id = ?`,
+			queryInt(t, store, `SELECT id FROM library_element WHERE org_id = ?`, orgID)))
+
+		ds := &datasources.DataSource{}
+		has, err := store.NewSession(ctx).Where("org_id = ? AND uid = ?", orgID, "prometheus").Get(ds)
+		require.NoError(t, err)
+		require.True(t, has)
+		decrypted, err := secretsService.DecryptJsonData(ctx, ds.SecureJsonData)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"password": "secret"}, decrypted)
+
+		kv := kvstore.NewSQLSecretsKVStore(store, secretsService, log.New("test"))
+		value, exists, err := kv.Get(ctx, orgID, "Prometheus", kvstore.DataSourceSecretType)
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, `{"password":"secret"}`, value)
+
+		var amConfig string
+		_, err = store.NewSession(ctx).SQL(`SELECT alertmanager_configuration FROM alert_configuration WHERE org_id = ?`, orgID).Get(&amConfig)
+		require.NoError(t, err)
+		_, err = transformSecrets(secretAlertmanagerConfig, amConfig, func(b []byte) ([]byte, error) {
+			decrypted, err := secretsService.Decrypt(ctx, b)
+			require.NoError(t, err)
+			require.Equal(t, "webhook-password", string(decrypted))
+			return b, nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("restore should skip existing entities", func(t *testing.T) {
+		stats, err := s.restore(ctx, restored(), ConflictSkip)
+		require.NoError(t, err)
+		for _, t2 := range tables {
+			require.Zero(t, stats[t2.name].created, t2.name)
+		}
+		require.Equal(t, 3, stats["dashboard"].skipped)
+	})
+
+	t.Run("restore should fail on existing entities", func(t *testing.T) {
+		_, err := s.restore(ctx, restored(), ConflictFail)
+		require.ErrorContains(t, err, "conflicts with")
+	})
+
+	t.Run("restore should overwrite existing entities", func(t *testing.T) {
+		_, err := store.NewSession(ctx).Exec(`UPDATE dashboard SET title = ? WHERE uid = ?`, "Renamed", "dashboard")
+		require.NoError(t, err)
+
+		stats, err := s.restore(ctx, restored(), ConflictOverwrite)
+		require.NoError(t, err)
+		require.Equal(t, 3, stats["dashboard"].overwritten)
+
+		var title string
+		_, err = store.NewSession(ctx).SQL(`SELECT title FROM dashboard WHERE uid = ?`, "dashboard").Get(&title)
+		require.NoError(t, err)
+		require.Equal(t, "Dashboard", title)
+	})
+}
+
+func TestReadArchive_Version(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeArchive(&buf, &archive{manifest: manifest{Version: archiveVersion + 1}}))
+	_, err := readArchive(&buf)
+	require.ErrorContains(t, err, fmt.Sprintf("archive version %d is not supported", archiveVersion+1))
+}
+
+func TestTransformSecrets(t *testing.T) {
+	reverse := func(b []byte) ([]byte, error) {
+		r := make([]byte, len(b))
+		for i := range b {
+			r[len(b)-1-i] = b[i]
+		}
+		return r, nil
+	}
+
+	t.Run("JSON map", func(t *testing.T) {
+		out, err := transformSecrets(secretJSONMap, `{"password":"YWJj"}`, reverse)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"password":"Y2Jh"}`, out)
+	})
+
+	t.Run("raw base64", func(t *testing.T) {
+		out, err := transformSecrets(secretRawBase64, base64.RawStdEncoding.EncodeToString([]byte("abcd")), reverse)
+		require.NoError(t, err)
+		require.Equal(t, base64.RawStdEncoding.EncodeToString([]byte("dcba")), out)
+	})
+
+	t.Run("Alertmanager configuration keeps unknown fields", func(t *testing.T) {
+		out, err := transformSecrets(secretAlertmanagerConfig, `{
+			"template_files": {"a": "b"},
+			"alertmanager_config": {
+				"route": {"receiver": "webhook", "group_wait": 30},
+				"receivers": [{
+					"name": "webhook",
+					"grafana_managed_receiver_configs": [{"uid": "abc", "type": "webhook", "secureSettings": {"password": "YWJj"}}]
+				}]
+			}
+		}`, reverse)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"template_files": {"a": "b"},
+			"alertmanager_config": {
+				"route": {"receiver": "webhook", "group_wait": 30},
+				"receivers": [{
+					"name": "webhook",
+					"grafana_managed_receiver_configs": [{"uid": "abc", "type": "webhook", "secureSettings": {"password": "Y2Jh"}}]
+				}]
+			}
+		}`, out)
+	})
+}
+
+type seededInstance struct {
+	orgID int64
+}
+
+// seedInstance creates an organization with an entity of each kind backed up.
+func seedInstance(t *testing.T, store *sqlstore.SQLStore, secretsService secrets.Service) seededInstance {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	secureJSONData, err := secretsService.EncryptJsonData(ctx, map[string]string{"password": "secret"}, secrets.WithoutScope())
+	require.NoError(t, err)
+	webhookPassword, err := secretsService.Encrypt(ctx, []byte("webhook-password"), secrets.WithoutScope())
+	require.NoError(t, err)
+	kv := kvstore.NewSQLSecretsKVStore(store, secretsService, log.New("test"))
+
+	var orgID int64
+	err = store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		o := &org.Org{Name: "Backup Org", Created: now, Updated: now}
+		if _, err := sess.Insert(o); err != nil {
+			return err
+		}
+		orgID = o.ID
+
+		u := &user.User{Login: "editor", Email: "editor@example.com", Password: "hash", Salt: "salt", OrgID: orgID, Created: now, Updated: now, LastSeenAt: now}
+		if _, err := sess.Insert(u); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&org.OrgUser{OrgID: orgID, UserID: u.ID, Role: org.RoleEditor, Created: now, Updated: now}); err != nil {
+			return err
+		}
+
+		team := &models.Team{OrgId: orgID, Name: "sre", Created: now, Updated: now}
+		if _, err := sess.Insert(team); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&models.TeamMember{OrgId: orgID, TeamId: team.Id, UserId: u.ID, Created: now, Updated: now}); err != nil {
+			return err
+		}
+
+		folder := &models.Dashboard{OrgId: orgID, Uid: "folder", Slug: "folder", Title: "Folder", IsFolder: true, Data: simplejson.New(), Created: now, Updated: now}
+		if _, err := sess.Insert(folder); err != nil {
+			return err
+		}
+		// the parent of the nested folder has a higher ID than the folder
+		parent := &models.Dashboard{OrgId: orgID, Uid: "parent", Slug: "parent", Title: "Parent", IsFolder: true, Data: simplejson.New(), Created: now, Updated: now}
+		if _, err := sess.Insert(parent); err != nil {
+			return err
+		}
+		if _, err := sess.Exec(`UPDATE dashboard SET folder_id = ? WHERE id = ?`, parent.Id, folder.Id); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&models.DashboardACL{OrgID: orgID, DashboardID: folder.Id, TeamID: team.Id, Permission: models.PERMISSION_EDIT, Created: now, Updated: now}); err != nil {
+			return err
+		}
+
+		dashboard := &models.Dashboard{OrgId: orgID, Uid: "dashboard", Slug: "dashboard", Title: "Dashboard", FolderId: folder.Id, CreatedBy: u.ID, UpdatedBy: u.ID, Data: simplejson.New(), Created: now, Updated: now}
+		if _, err := sess.Insert(dashboard); err != nil {
+			return err
+		}
+
+		if _, err := sess.Insert(&pref.Preference{OrgID: orgID, UserID: u.ID, HomeDashboardID: dashboard.Id, Created: now, Updated: now}); err != nil {
+			return err
+		}
+
+		if _, err := sess.Insert(&datasources.DataSource{OrgId: orgID, Uid: "prometheus", Name: "Prometheus", Type: datasources.DS_PROMETHEUS, Access: datasources.DS_ACCESS_PROXY, SecureJsonData: secureJSONData, Created: now, Updated: now}); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec(`INSERT INTO library_element (org_id, folder_id, uid, name, kind, type, description, model, created, created_by, updated, updated_by, version)
+			VALUES (?, ?, ?, ?, 1, 'graph', '', '{}', ?, ?, ?, ?, 1)`, orgID, folder.Id, "panel", "Panel", now, u.ID, now, u.ID); err != nil {
+			return err
+		}
+		var elementID int64
+		if _, err := sess.SQL(`SELECT id FROM library_element WHERE org_id = ?`, orgID).Get(&elementID); err != nil {
+			return err
+		}
+		if _, err := sess.Exec(`INSERT INTO library_element_connection (element_id, kind, connection_id, created, created_by) VALUES (?, 1, ?, ?, ?)`,
+			elementID, dashboard.Id, now, u.ID); err != nil {
+			return err
+		}
+
+		amConfig := fmt.Sprintf(`{"alertmanager_config":{"route":{"receiver":"webhook"},"receivers":[{"name":"webhook","grafana_managed_receiver_configs":[{"uid":"abc","type":"webhook","settings":{},"secureSettings":{"password":%q}}]}]}}`,
+			base64.StdEncoding.EncodeToString(webhookPassword))
+		_, err := sess.Exec(`INSERT INTO alert_configuration (alertmanager_configuration, configuration_version, created_at, `+store.Quote("default")+`, org_id, configuration_hash)
+			VALUES (?, 'v1', ?, ?, ?, 'hash')`, amConfig, now.Unix(), false, orgID)
+		return err
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, kv.Set(ctx, orgID, "Prometheus", kvstore.DataSourceSecretType, `{"password":"secret"}`))
+
+	return seededInstance{orgID: orgID}
+}
+
+// deleteOrg deletes the organization and all its entities.
+func deleteOrg(t *testing.T, store *sqlstore.SQLStore, orgID int64) {
+	t.Helper()
+	err := store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		deletes := []string{
+			`DELETE FROM library_element_connection WHERE element_id IN (SELECT id FROM library_element WHERE org_id = ?)`,
+			`DELETE FROM ` + store.Quote("user") + ` WHERE org_id = ?`,
+		}
+		for _, table := range []string{"org_user", "team", "team_member", "dashboard", "dashboard_acl", "data_source", "secrets", "library_element", "preferences", "alert_configuration"} {
+			deletes = append(deletes, `DELETE FROM `+table+` WHERE org_id = ?`)
+		}
+		deletes = append(deletes, `DELETE FROM org WHERE id = ?`)
+		for _, d := range deletes {
+			if _, err := sess.Exec(d, orgID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func queryInt(t *testing.T, store *sqlstore.SQLStore, query string, args ...interface{}) int64 {
+	t.Helper()
+	var v int64
+	has, err := store.NewSession(context.Background()).SQL(query, args...).Get(&v)
+	require.NoError(t, err)
+	require.True(t, has, query)
+	return v
+}
@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// columnKind is the kind of values of a column, whatever the type of the column is in each database.
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindTime
+	kindBytes
+)
+
+// kindOf returns the kind of a column from its type in the database. Booleans are integers
+// in SQLite and MySQL, and have their own type in PostgreSQL.
+func kindOf(databaseType string) columnKind {
+	t := strings.ToUpper(databaseType)
+	switch {
+	case strings.Contains(t, "BOOL"):
+		return kindBool
+	case strings.Contains(t, "INT"):
+		return kindInt
+	case strings.Contains(t, "DATE"), strings.Contains(t, "TIME"):
+		return kindTime
+	case strings.Contains(t, "BLOB"), strings.Contains(t, "BYTEA"), strings.Contains(t, "BINARY"):
+		return kindBytes
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOAT"), strings.Contains(t, "DOUBLE"),
+		strings.Contains(t, "NUMERIC"), strings.Contains(t, "DECIMAL"):
+		return kindFloat
+	}
+	return kindString
+}
+
+var timeFormats = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05"}
+
+// convertValue converts a value read from an archive to the kind of the column it is restored into.
+func convertValue(v interface{}, kind columnKind) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch kind {
+	case kindInt:
+		switch n := v.(type) {
+		case int64:
+			return n, nil
+		case int:
+			return int64(n), nil
+		case bool:
+			if n {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case json.Number:
+			if i, err := n.Int64(); err == nil {
+				return i, nil
+			}
+			f, err := n.Float64()
+			return int64(f), err
+		}
+	case kindFloat:
+		if n, ok := v.(json.Number); ok {
+			return n.Float64()
+		}
+	case kindBool:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case json.Number:
+			return b.String() != "0", nil
+		}
+	case kindTime:
+		if s, ok := v.(string); ok {
+			for _, format := range timeFormats {
+				if t, err := time.Parse(format, s); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("unsupported time %q", s)
+		}
+		if n, ok := v.(json.Number); ok {
+			// Some timestamps are stored as Unix seconds in integer columns.
+			return n.Int64()
+		}
+	case kindBytes:
+		if s, ok := v.(string); ok {
+			return []byte(s), nil
+		}
+	case kindString:
+		switch s := v.(type) {
+		case string:
+			return s, nil
+		case json.Number:
+			return s.String(), nil
+		case bool:
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected value %v of type %T", v, v)
+}
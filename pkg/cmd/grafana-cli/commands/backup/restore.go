@@ -0,0 +1,360 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/runner"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ConflictStrategy is how a row of the archive is restored when the instance has a row with the same key.
+type ConflictStrategy string
+
+const (
+	// ConflictSkip keeps the row of the instance.
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictOverwrite replaces the row of the instance with the row of the archive.
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	// ConflictFail aborts the restore.
+	ConflictFail ConflictStrategy = "fail"
+)
+
+type restoreStats struct {
+	created     int
+	overwritten int
+	skipped     int
+}
+
+// Restore restores the entities of the archive at the path given as argument into the instance.
+func Restore(c utils.CommandLine, runner runner.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("path of the archive is required")
+	}
+
+	strategy := ConflictStrategy(c.String("conflict"))
+	if strategy == "" {
+		strategy = ConflictSkip
+	}
+	switch strategy {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return fmt.Errorf("unknown conflict strategy %q, expected one of skip, overwrite or fail", strategy)
+	}
+
+	// We can ignore the gosec G304 warning since the path is given by the administrator.
+	// nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Warnf("Failed to close archive: %v\n", err)
+		}
+	}()
+
+	a, err := readArchive(f)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Restoring backup of Grafana %s created at %s\n\n", a.manifest.GrafanaVersion, a.manifest.Created.Format(time.RFC3339))
+
+	s := newService(runner, c.String("encryption-key"))
+	stats, err := s.restore(context.Background(), a, strategy)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tables {
+		st := stats[t.name]
+		logger.Infof("%s Restored %s: %d created, %d overwritten, %d skipped\n", color.GreenString("✔"), t.name, st.created, st.overwritten, st.skipped)
+	}
+	return nil
+}
+
+// restore restores the rows of the archive in a single transaction. The rows get new IDs, and the
+// references between them are updated accordingly.
+func (s *service) restore(ctx context.Context, a *archive, strategy ConflictStrategy) (map[string]*restoreStats, error) {
+	if err := s.checkArchiveKey(ctx, a.manifest); err != nil {
+		return nil, err
+	}
+
+	kinds := map[string]map[string]columnKind{}
+	for _, t := range tables {
+		if err := s.transformSecrets(a, t, s.importSecret(ctx)); err != nil {
+			return nil, err
+		}
+		k, err := s.columnKinds(ctx, t.name)
+		if err != nil {
+			return nil, err
+		}
+		kinds[t.name] = k
+	}
+
+	stats := map[string]*restoreStats{}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		// ids maps the IDs of the rows of the archive to the IDs of the restored rows, by table.
+		ids := map[string]map[int64]int64{}
+		for _, t := range tables {
+			ids[t.name] = map[int64]int64{}
+			stats[t.name] = &restoreStats{}
+			for _, r := range restoreOrder(t, a.tables[t.name]) {
+				if err := s.restoreRow(sess, t, r, kinds[t.name], ids, strategy, stats[t.name]); err != nil {
+					return fmt.Errorf("failed to restore %s %v: %w", t.name, r["id"], err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// restoreOrder orders the rows so that the rows referencing rows of the same table, such as nested
+// folders, come after the rows they reference, whatever the order of their IDs.
+func restoreOrder(t table, rows []row) []row {
+	selfRefs := make([]string, 0)
+	for col, ref := range t.refs {
+		if ref.table == t.name {
+			selfRefs = append(selfRefs, col)
+		}
+	}
+	if len(selfRefs) == 0 {
+		return rows
+	}
+
+	archived := make(map[int64]bool, len(rows))
+	for _, r := range rows {
+		if id, ok := toInt64(r["id"]); ok {
+			archived[id] = true
+		}
+	}
+
+	ordered := make([]row, 0, len(rows))
+	restored := make(map[int64]bool, len(rows))
+	pending := rows
+	for len(pending) > 0 {
+		next := make([]row, 0)
+		for _, r := range pending {
+			ready := true
+			for _, col := range selfRefs {
+				// references to rows missing from the archive are reported by restoreRow
+				if id, _ := toInt64(r[col]); id > 0 && archived[id] && !restored[id] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				next = append(next, r)
+				continue
+			}
+			ordered = append(ordered, r)
+			if id, ok := toInt64(r["id"]); ok {
+				restored[id] = true
+			}
+		}
+		// rows referencing each other cannot be ordered, restoreRow reports their references
+		if len(next) == len(pending) {
+			return append(ordered, next...)
+		}
+		pending = next
+	}
+	return ordered
+}
+
+func (s *service) restoreRow(sess *sqlstore.DBSession, t table, r row, kinds map[string]columnKind, ids map[string]map[int64]int64, strategy ConflictStrategy, stats *restoreStats) error {
+	oldID, ok := toInt64(r["id"])
+	if !ok {
+		return fmt.Errorf("row has no ID")
+	}
+
+	values := row{}
+	for col, v := range r {
+		kind, exists := kinds[col]
+		if col == "id" || !exists {
+			continue
+		}
+
+		if ref, isRef := t.refs[col]; isRef {
+			id, _ := toInt64(v)
+			if id > 0 {
+				newID, found := ids[ref.table][id]
+				switch {
+				case found:
+					v = newID
+				case ref.optional:
+					v = 0
+				default:
+					return fmt.Errorf("%s references %s %d, which is not in the archive", col, ref.table, id)
+				}
+			}
+		}
+
+		converted, err := convertValue(v, kind)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %w", col, err)
+		}
+		values[col] = converted
+	}
+
+	existingID, exists, err := s.findRow(sess, t, values, t.conflictFilter)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		switch strategy {
+		case ConflictFail:
+			return fmt.Errorf("conflicts with %s %d with the same %s", t.name, existingID, strings.Join(t.key, ", "))
+		case ConflictSkip:
+			stats.skipped++
+		case ConflictOverwrite:
+			if err := s.updateRow(sess, t, existingID, values); err != nil {
+				return err
+			}
+			stats.overwritten++
+		}
+		ids[t.name][oldID] = existingID
+		return nil
+	}
+
+	if err := s.insertRow(sess, t, values); err != nil {
+		return err
+	}
+	newID, exists, err := s.findRow(sess, t, values, nil)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("restored row not found")
+	}
+	ids[t.name][oldID] = newID
+	stats.created++
+	return nil
+}
+
+// findRow returns the ID of the newest row of the instance with the same key as the given values.
+func (s *service) findRow(sess *sqlstore.DBSession, t table, values row, filter map[string]interface{}) (int64, bool, error) {
+	conds := make([]string, 0, len(t.key)+len(filter))
+	args := []interface{}{}
+	for _, col := range t.key {
+		if values[col] == nil {
+			conds = append(conds, s.store.Quote(col)+" IS NULL")
+			continue
+		}
+		conds = append(conds, s.store.Quote(col)+" = ?")
+		args = append(args, values[col])
+	}
+	for _, col := range sortedKeys(filter) {
+		conds = append(conds, s.store.Quote(col)+" = ?")
+		args = append(args, filter[col])
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s DESC", s.store.Quote("id"), s.store.Quote(t.name), strings.Join(conds, " AND "), s.store.Quote("id"))
+	results, err := sess.QueryInterface(append([]interface{}{query}, args...)...)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(results) == 0 {
+		return 0, false, nil
+	}
+	id, ok := toInt64(results[0]["id"])
+	return id, ok, nil
+}
+
+func (s *service) insertRow(sess *sqlstore.DBSession, t table, values row) error {
+	cols := sortedKeys(values)
+	quoted := make([]string, 0, len(cols))
+	args := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		quoted = append(quoted, s.store.Quote(col))
+		args = append(args, values[col])
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", s.store.Quote(t.name), strings.Join(quoted, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", "))
+	_, err := sess.Exec(append([]interface{}{query}, args...)...)
+	return err
+}
+
+func (s *service) updateRow(sess *sqlstore.DBSession, t table, id int64, values row) error {
+	cols := sortedKeys(values)
+	sets := make([]string, 0, len(cols))
+	args := make([]interface{}, 0, len(cols)+1)
+	for _, col := range cols {
+		sets = append(sets, s.store.Quote(col)+" = ?")
+		args = append(args, values[col])
+	}
+	args = append(args, id)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?", s.store.Quote(t.name), strings.Join(sets, ", "), s.store.Quote("id"))
+	_, err := sess.Exec(append([]interface{}{query}, args...)...)
+	return err
+}
+
+// columnKinds returns the kind of the columns of the table in the database of the instance, so
+// that the values of the archive can be converted to them, whatever database they come from.
+func (s *service) columnKinds(ctx context.Context, name string) (map[string]columnKind, error) {
+	kinds := map[string]columnKind{}
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rows, err := sess.DB().QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", s.store.Quote(name)))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				logger.Warnf("Failed to close rows: %v\n", err)
+			}
+		}()
+
+		types, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+		for _, ct := range types {
+			kinds[ct.Name()] = kindOf(ct.DatabaseTypeName())
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+	}
+	return kinds, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := json.Number(n).Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
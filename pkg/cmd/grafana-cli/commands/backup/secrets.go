@@ -0,0 +1,98 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// secretFormat is the format of a column holding encrypted secrets.
+type secretFormat int
+
+const (
+	// secretJSONMap is a JSON object of base64 encoded secrets, like the secure JSON data of data sources.
+	secretJSONMap secretFormat = iota
+	// secretRawBase64 is a secret encoded in base64 without padding, like the values of the secrets key-value store.
+	secretRawBase64
+	// secretAlertmanagerConfig is an Alertmanager configuration, with the base64 encoded secure settings of its receivers.
+	secretAlertmanagerConfig
+)
+
+// transformSecrets applies fn to each encrypted secret of the value of a column in the given format.
+func transformSecrets(format secretFormat, value string, fn func([]byte) ([]byte, error)) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	switch format {
+	case secretJSONMap:
+		var secrets map[string][]byte
+		if err := json.Unmarshal([]byte(value), &secrets); err != nil {
+			return "", err
+		}
+		for k, v := range secrets {
+			transformed, err := fn(v)
+			if err != nil {
+				return "", fmt.Errorf("secret %s: %w", k, err)
+			}
+			secrets[k] = transformed
+		}
+		b, err := json.Marshal(secrets)
+		return string(b), err
+	case secretRawBase64:
+		return transformBase64(base64.RawStdEncoding, value, fn)
+	case secretAlertmanagerConfig:
+		return transformAlertmanagerConfig(value, fn)
+	}
+	return "", fmt.Errorf("unknown secret format %d", format)
+}
+
+func transformBase64(encoding *base64.Encoding, value string, fn func([]byte) ([]byte, error)) (string, error) {
+	decoded, err := encoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	transformed, err := fn(decoded)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(transformed), nil
+}
+
+// transformAlertmanagerConfig applies fn to the secure settings of the Grafana managed receivers
+// of the configuration. The configuration is handled as a generic JSON document, so that fields
+// unknown to this version of Grafana are kept as is.
+func transformAlertmanagerConfig(value string, fn func([]byte) ([]byte, error)) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.UseNumber()
+	var cfg map[string]interface{}
+	if err := decoder.Decode(&cfg); err != nil {
+		return "", err
+	}
+
+	amConfig, _ := cfg["alertmanager_config"].(map[string]interface{})
+	receivers, _ := amConfig["receivers"].([]interface{})
+	for _, r := range receivers {
+		receiver, _ := r.(map[string]interface{})
+		integrations, _ := receiver["grafana_managed_receiver_configs"].([]interface{})
+		for _, i := range integrations {
+			integration, _ := i.(map[string]interface{})
+			secureSettings, _ := integration["secureSettings"].(map[string]interface{})
+			for k, v := range secureSettings {
+				s, ok := v.(string)
+				if !ok {
+					continue
+				}
+				transformed, err := transformBase64(base64.StdEncoding, s, fn)
+				if err != nil {
+					return "", fmt.Errorf("secure setting %s of receiver %v: %w", k, receiver["name"], err)
+				}
+				secureSettings[k] = transformed
+			}
+		}
+	}
+
+	b, err := json.Marshal(cfg)
+	return string(b), err
+}
@@ -0,0 +1,178 @@
+package backup
+
+// table describes how the rows of a database table are backed up and restored.
+type table struct {
+	name string
+	// where restricts the rows that are backed up.
+	where string
+	// orderBy orders the rows of the archive. Rows referencing rows of the same table are restored
+	// after them whatever their order, see restoreOrder.
+	orderBy string
+	// key are the columns identifying a row across instances. A row of the archive conflicts with
+	// the row of the instance that has the same key.
+	key []string
+	// conflictFilter restricts the rows of the instance that a row of the archive can conflict with.
+	conflictFilter map[string]interface{}
+	// refs are the columns referencing the ID of a row of another table. The IDs of the rows are
+	// not kept on restore, so the references are updated with the IDs of the restored rows.
+	refs map[string]ref
+	// secrets are the columns holding values encrypted by the secrets service.
+	secrets map[string]secretFormat
+}
+
+type ref struct {
+	table string
+	// optional references may point to rows that are not in the archive, such as users that
+	// have since been deleted. They are reset to 0 when the row is not restored.
+	optional bool
+}
+
+// tables are the tables of the persistent entities of Grafana, in the order they are restored.
+var tables = []table{
+	{
+		name:    "org",
+		orderBy: "id",
+		key:     []string{"name"},
+	},
+	{
+		name:    "user",
+		orderBy: "id",
+		key:     []string{"login"},
+		refs: map[string]ref{
+			"org_id": {table: "org"},
+		},
+	},
+	{
+		name:    "org_user",
+		orderBy: "id",
+		key:     []string{"org_id", "user_id"},
+		refs: map[string]ref{
+			"org_id":  {table: "org"},
+			"user_id": {table: "user"},
+		},
+	},
+	{
+		name:    "team",
+		orderBy: "id",
+		key:     []string{"org_id", "name"},
+		refs: map[string]ref{
+			"org_id": {table: "org"},
+		},
+	},
+	{
+		name:    "team_member",
+		orderBy: "id",
+		key:     []string{"org_id", "team_id", "user_id"},
+		refs: map[string]ref{
+			"org_id":  {table: "org"},
+			"team_id": {table: "team"},
+			"user_id": {table: "user"},
+		},
+	},
+	{
+		// Folders are dashboards, and are restored before the dashboards they contain.
+		name:    "dashboard",
+		orderBy: "is_folder DESC, id",
+		key:     []string{"org_id", "uid"},
+		refs: map[string]ref{
+			"org_id":     {table: "org"},
+			"folder_id":  {table: "dashboard"},
+			"created_by": {table: "user", optional: true},
+			"updated_by": {table: "user", optional: true},
+		},
+	},
+	{
+		// The permissions of the default roles on all dashboards use -1 as dashboard and organization,
+		// they are created by the migrations of every instance.
+		name:    "dashboard_acl",
+		where:   "dashboard_id > 0",
+		orderBy: "id",
+		key:     []string{"org_id", "dashboard_id", "user_id", "team_id", "role"},
+		refs: map[string]ref{
+			"org_id":       {table: "org"},
+			"dashboard_id": {table: "dashboard"},
+			"user_id":      {table: "user"},
+			"team_id":      {table: "team"},
+		},
+	},
+	{
+		name:    "data_source",
+		orderBy: "id",
+		key:     []string{"org_id", "uid"},
+		refs: map[string]ref{
+			"org_id": {table: "org"},
+		},
+		secrets: map[string]secretFormat{
+			"secure_json_data": secretJSONMap,
+		},
+	},
+	{
+		// The secrets of data sources are also stored in the secrets key-value store.
+		name:    "secrets",
+		where:   "type = 'datasource'",
+		orderBy: "id",
+		key:     []string{"org_id", "namespace", "type"},
+		refs: map[string]ref{
+			"org_id": {table: "org"},
+		},
+		secrets: map[string]secretFormat{
+			"value": secretRawBase64,
+		},
+	},
+	{
+		name:    "library_element",
+		orderBy: "id",
+		key:     []string{"org_id", "uid"},
+		refs: map[string]ref{
+			"org_id":     {table: "org"},
+			"folder_id":  {table: "dashboard"},
+			"created_by": {table: "user", optional: true},
+			"updated_by": {table: "user", optional: true},
+		},
+	},
+	{
+		name:    "library_element_connection",
+		orderBy: "id",
+		key:     []string{"element_id", "kind", "connection_id"},
+		refs: map[string]ref{
+			"element_id":    {table: "library_element"},
+			"connection_id": {table: "dashboard"},
+			"created_by":    {table: "user", optional: true},
+		},
+	},
+	{
+		name:    "preferences",
+		orderBy: "id",
+		key:     []string{"org_id", "user_id", "team_id"},
+		refs: map[string]ref{
+			"org_id":            {table: "org"},
+			"user_id":           {table: "user"},
+			"team_id":           {table: "team"},
+			"home_dashboard_id": {table: "dashboard", optional: true},
+		},
+	},
+	{
+		// Alert rules reference their folder by UID, which is kept on restore.
+		name:    "alert_rule",
+		orderBy: "id",
+		key:     []string{"org_id", "uid"},
+		refs: map[string]ref{
+			"org_id": {table: "org"},
+		},
+	},
+	{
+		// Only the current Alertmanager configuration of each organization is backed up. It
+		// conflicts with the configuration of the organization unless that is the default one.
+		name:           "alert_configuration",
+		where:          "id IN (SELECT MAX(id) FROM alert_configuration GROUP BY org_id)",
+		orderBy:        "id",
+		key:            []string{"org_id"},
+		conflictFilter: map[string]interface{}{"default": false},
+		refs: map[string]ref{
+			"org_id": {table: "org"},
+		},
+		secrets: map[string]secretFormat{
+			"alertmanager_configuration": secretAlertmanagerConfig,
+		},
+	},
+}
@@ -38,6 +38,12 @@ func RunCLI(version string) int {
 				Value:   "https://grafana.com/api/plugins",
 				EnvVars: []string{"GF_PLUGIN_REPO"},
 			},
+			&cli.StringFlag{
+				Name:    "repoMirror",
+				Usage:   "Path or URL to a plugin repository mirror to use instead of the plugin repository",
+				Value:   "",
+				EnvVars: []string{"GF_PLUGIN_REPO_MIRROR"},
+			},
 			&cli.StringFlag{
 				Name:    "pluginUrl",
 				Usage:   "Full url to the plugin zip file instead of downloading the plugin from grafana.com/api",
@@ -52,11 +52,20 @@ func (cmd Command) installCommand(c utils.CommandLine) error {
 	return installPlugin(context.Background(), pluginID, version, c)
 }
 
-// installPlugin downloads the plugin code as a zip file from the Grafana.com API
+// pluginRepository returns the plugin repository mirror if one is configured, and the
+// Grafana.com API otherwise.
+func pluginRepository(c utils.CommandLine) repo.Service {
+	skipTLSVerify := c.Bool("insecure")
+	if mirror := c.PluginRepoMirror(); mirror != "" {
+		return repo.NewMirror(skipTLSVerify, mirror, services.Logger)
+	}
+	return repo.New(skipTLSVerify, c.PluginRepoURL(), services.Logger)
+}
+
+// installPlugin downloads the plugin code as a zip file from the plugin repository
 // and then extracts the zip into the plugin's directory.
 func installPlugin(ctx context.Context, pluginID, version string, c utils.CommandLine) error {
-	skipTLSVerify := c.Bool("insecure")
-	repository := repo.New(skipTLSVerify, c.PluginRepoURL(), services.Logger)
+	repository := pluginRepository(c)
 
 	compatOpts := repo.NewCompatOpts(services.GrafanaVersion, runtime.GOOS, runtime.GOARCH)
 
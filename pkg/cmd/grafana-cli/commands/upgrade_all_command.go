@@ -2,49 +2,63 @@ package commands
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"runtime"
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/plugins/repo"
 	"github.com/hashicorp/go-version"
 )
 
-func shouldUpgrade(installed string, remote *models.Plugin) bool {
+func shouldUpgrade(installed string, remote *repo.PluginDownloadOptions) bool {
 	installedVersion, err := version.NewVersion(installed)
 	if err != nil {
 		return false
 	}
 
-	latest := latestSupportedVersion(remote)
-	latestVersion, err := version.NewVersion(latest.Version)
+	latestVersion, err := version.NewVersion(remote.Version)
 	if err != nil {
 		return false
 	}
 	return installedVersion.LessThan(latestVersion)
 }
 
+// isUnavailable returns true if the error means that the repository has no version of the
+// plugin that can be installed, for instance because the plugin is private.
+func isUnavailable(err error) bool {
+	var notFound repo.ErrPluginNotFound
+	var unsupported repo.ErrVersionUnsupported
+	var respErr repo.Response4xxError
+	return errors.As(err, &notFound) || errors.As(err, &unsupported) ||
+		(errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound)
+}
+
 func (cmd Command) upgradeAllCommand(c utils.CommandLine) error {
+	ctx := context.Background()
 	pluginsDir := c.PluginDirectory()
 
 	localPlugins := services.GetLocalPlugins(pluginsDir)
 
-	remotePlugins, err := cmd.Client.ListAllPlugins(c.String("repo"))
-	if err != nil {
-		return err
-	}
+	repository := pluginRepository(c)
+	compatOpts := repo.NewCompatOpts(services.GrafanaVersion, runtime.GOOS, runtime.GOARCH)
 
 	pluginsToUpgrade := make([]models.InstalledPlugin, 0)
 
 	for _, localPlugin := range localPlugins {
-		for _, p := range remotePlugins.Plugins {
-			remotePlugin := p
-			if localPlugin.ID != remotePlugin.ID {
+		dlOpts, err := repository.GetPluginDownloadOptions(ctx, localPlugin.ID, "", compatOpts)
+		if err != nil {
+			if isUnavailable(err) {
+				logger.Debugf("Skipping %s: %s\n", localPlugin.ID, err)
 				continue
 			}
-			if shouldUpgrade(localPlugin.Info.Version, &remotePlugin) {
-				pluginsToUpgrade = append(pluginsToUpgrade, localPlugin)
-			}
+			return err
+		}
+		if shouldUpgrade(localPlugin.Info.Version, dlOpts) {
+			pluginsToUpgrade = append(pluginsToUpgrade, localPlugin)
 		}
 	}
 
@@ -56,7 +70,7 @@ func (cmd Command) upgradeAllCommand(c utils.CommandLine) error {
 			return err
 		}
 
-		err = installPlugin(context.Background(), p.ID, "", c)
+		err = installPlugin(ctx, p.ID, "", c)
 		if err != nil {
 			return err
 		}
@@ -4,46 +4,42 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
+	"github.com/grafana/grafana/pkg/plugins/repo"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestVersionComparison(t *testing.T) {
 	t.Run("Validate that version is outdated", func(t *testing.T) {
-		versions := []models.Version{
-			{Version: "1.1.1"},
-			{Version: "2.0.0"},
-		}
+		latest := &repo.PluginDownloadOptions{Version: "2.0.0"}
 
-		upgradeablePlugins := map[string]models.Plugin{
-			"0.0.0": {Versions: versions},
-			"1.0.0": {Versions: versions},
-		}
+		upgradeablePlugins := []string{"0.0.0", "1.0.0"}
 
-		for k, v := range upgradeablePlugins {
-			val := v
-			t.Run(fmt.Sprintf("for %s should be true", k), func(t *testing.T) {
-				assert.True(t, shouldUpgrade(k, &val))
+		for _, k := range upgradeablePlugins {
+			installed := k
+			t.Run(fmt.Sprintf("for %s should be true", installed), func(t *testing.T) {
+				assert.True(t, shouldUpgrade(installed, latest))
 			})
 		}
 	})
 
 	t.Run("Validate that version is ok", func(t *testing.T) {
-		versions := []models.Version{
-			{Version: "1.1.1"},
-			{Version: "2.0.0"},
-		}
+		latest := &repo.PluginDownloadOptions{Version: "2.0.0"}
 
-		shouldNotUpgrade := map[string]models.Plugin{
-			"2.0.0": {Versions: versions},
-			"6.0.0": {Versions: versions},
-		}
+		shouldNotUpgrade := []string{"2.0.0", "6.0.0"}
 
-		for k, v := range shouldNotUpgrade {
-			val := v
-			t.Run(fmt.Sprintf("for %s should be false", k), func(t *testing.T) {
-				assert.False(t, shouldUpgrade(k, &val))
+		for _, k := range shouldNotUpgrade {
+			installed := k
+			t.Run(fmt.Sprintf("for %s should be false", installed), func(t *testing.T) {
+				assert.False(t, shouldUpgrade(installed, latest))
 			})
 		}
 	})
 }
+
+func TestIsUnavailable(t *testing.T) {
+	assert.True(t, isUnavailable(repo.ErrPluginNotFound{PluginID: "test"}))
+	assert.True(t, isUnavailable(repo.ErrVersionUnsupported{PluginID: "test"}))
+	assert.True(t, isUnavailable(fmt.Errorf("wrapped: %w", repo.Response4xxError{StatusCode: 404})))
+	assert.False(t, isUnavailable(repo.Response4xxError{StatusCode: 403}))
+	assert.False(t, isUnavailable(fmt.Errorf("connection refused")))
+}
@@ -3,14 +3,17 @@ package commands
 import (
 	"context"
 	"fmt"
+	"runtime"
 
 	"github.com/fatih/color"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/plugins/repo"
 )
 
 func (cmd Command) upgradeCommand(c utils.CommandLine) error {
+	ctx := context.Background()
 	pluginsDir := c.PluginDirectory()
 	pluginName := c.Args().First()
 
@@ -20,17 +23,18 @@ func (cmd Command) upgradeCommand(c utils.CommandLine) error {
 		return err
 	}
 
-	plugin, err2 := cmd.Client.GetPlugin(pluginName, c.PluginRepoURL())
-	if err2 != nil {
-		return err2
+	compatOpts := repo.NewCompatOpts(services.GrafanaVersion, runtime.GOOS, runtime.GOARCH)
+	dlOpts, err := pluginRepository(c).GetPluginDownloadOptions(ctx, pluginName, "", compatOpts)
+	if err != nil {
+		return err
 	}
 
-	if shouldUpgrade(localPlugin.Info.Version, &plugin) {
+	if shouldUpgrade(localPlugin.Info.Version, dlOpts) {
 		if err := services.RemoveInstalledPlugin(pluginsDir, pluginName); err != nil {
 			return fmt.Errorf("failed to remove plugin '%s': %w", pluginName, err)
 		}
 
-		return installPlugin(context.Background(), pluginName, "", c)
+		return installPlugin(ctx, pluginName, "", c)
 	}
 
 	logger.Infof("%s %s is up to date \n", color.GreenString("✔"), pluginName)
@@ -0,0 +1,305 @@
+package dashdiffs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	// ErrPanelNotFound occurs when a panel to restore exists in neither dashboard version.
+	ErrPanelNotFound = errors.New("dashdiff: panel not found")
+	// ErrVariableNotFound occurs when a variable to restore exists in neither dashboard version.
+	ErrVariableNotFound = errors.New("dashdiff: variable not found")
+)
+
+type SemanticChangeType string
+
+const (
+	SemanticChangeAdded    SemanticChangeType = "added"
+	SemanticChangeRemoved  SemanticChangeType = "removed"
+	SemanticChangeModified SemanticChangeType = "modified"
+)
+
+// SemanticChange describes how a single panel, variable or setting differs between two dashboard versions.
+type SemanticChange struct {
+	Type SemanticChangeType `json:"type"`
+	// Key identifies the changed element: the panel id, the variable name or the setting name.
+	Key string `json:"key"`
+	// Title is the panel title, taken from the new version unless the panel was removed.
+	Title string `json:"title,omitempty"`
+	// Fields lists the modified properties of a panel or variable.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// SemanticDiff groups the changes between two dashboard versions by the kind of element they apply to.
+type SemanticDiff struct {
+	Panels    []SemanticChange `json:"panels"`
+	Variables []SemanticChange `json:"variables"`
+	Settings  []SemanticChange `json:"settings"`
+}
+
+// settingsIgnoredKeys are the dashboard properties that are either compared
+// separately or always change between versions.
+var settingsIgnoredKeys = map[string]bool{
+	"id":         true,
+	"uid":        true,
+	"version":    true,
+	"panels":     true,
+	"templating": true,
+}
+
+// CalculateSemanticDiff reports the panels, variables and dashboard settings that
+// were added, removed or modified between two dashboard versions.
+func CalculateSemanticDiff(baseData, newData *simplejson.Json) (*SemanticDiff, error) {
+	result := &SemanticDiff{
+		Panels:    []SemanticChange{},
+		Variables: []SemanticChange{},
+		Settings:  []SemanticChange{},
+	}
+
+	basePanels, baseOrder := indexPanels(baseData)
+	newPanels, newOrder := indexPanels(newData)
+	for _, id := range newOrder {
+		newPanel := newPanels[id]
+		basePanel, ok := basePanels[id]
+		if !ok {
+			result.Panels = append(result.Panels, SemanticChange{Type: SemanticChangeAdded, Key: id, Title: panelTitle(newPanel)})
+			continue
+		}
+		// Panels nested in a collapsed row are compared on their own.
+		if fields := changedFields(basePanel, newPanel, "panels"); len(fields) > 0 {
+			result.Panels = append(result.Panels, SemanticChange{Type: SemanticChangeModified, Key: id, Title: panelTitle(newPanel), Fields: fields})
+		}
+	}
+	for _, id := range baseOrder {
+		if _, ok := newPanels[id]; !ok {
+			result.Panels = append(result.Panels, SemanticChange{Type: SemanticChangeRemoved, Key: id, Title: panelTitle(basePanels[id])})
+		}
+	}
+
+	baseVariables, baseNames := indexVariables(baseData)
+	newVariables, newNames := indexVariables(newData)
+	for _, name := range newNames {
+		baseVariable, ok := baseVariables[name]
+		if !ok {
+			result.Variables = append(result.Variables, SemanticChange{Type: SemanticChangeAdded, Key: name})
+			continue
+		}
+		if fields := changedFields(baseVariable, newVariables[name], "current"); len(fields) > 0 {
+			result.Variables = append(result.Variables, SemanticChange{Type: SemanticChangeModified, Key: name, Fields: fields})
+		}
+	}
+	for _, name := range baseNames {
+		if _, ok := newVariables[name]; !ok {
+			result.Variables = append(result.Variables, SemanticChange{Type: SemanticChangeRemoved, Key: name})
+		}
+	}
+
+	baseSettings, err := baseData.Map()
+	if err != nil {
+		return nil, err
+	}
+	newSettings, err := newData.Map()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range sortedKeys(newSettings) {
+		if settingsIgnoredKeys[key] {
+			continue
+		}
+		baseValue, ok := baseSettings[key]
+		if !ok {
+			result.Settings = append(result.Settings, SemanticChange{Type: SemanticChangeAdded, Key: key})
+		} else if !reflect.DeepEqual(baseValue, newSettings[key]) {
+			result.Settings = append(result.Settings, SemanticChange{Type: SemanticChangeModified, Key: key})
+		}
+	}
+	for _, key := range sortedKeys(baseSettings) {
+		if _, ok := newSettings[key]; !ok && !settingsIgnoredKeys[key] {
+			result.Settings = append(result.Settings, SemanticChange{Type: SemanticChangeRemoved, Key: key})
+		}
+	}
+
+	return result, nil
+}
+
+// RestoreOptions selects the panels and variables to take from an older dashboard version.
+type RestoreOptions struct {
+	PanelIDs  []int64
+	Variables []string
+}
+
+// RestoreFromVersion returns a copy of the current dashboard in which the selected panels and
+// variables are replaced by their definition in the old version. Selected elements that only
+// exist in the old version are added back, those that only exist in the current version are removed.
+func RestoreFromVersion(current, old *simplejson.Json, options RestoreOptions) (*simplejson.Json, error) {
+	encoded, err := current.Encode()
+	if err != nil {
+		return nil, err
+	}
+	result, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	oldPanels, _ := indexPanels(old)
+	for _, id := range options.PanelIDs {
+		key := fmt.Sprint(id)
+		oldPanel, inOld := oldPanels[key]
+		panels, replaced := replacePanel(result.Get("panels").MustArray(), key, oldPanel)
+		switch {
+		case replaced:
+		case inOld:
+			panels = append(panels, oldPanel)
+		default:
+			return nil, fmt.Errorf("%w: %d", ErrPanelNotFound, id)
+		}
+		result.Set("panels", panels)
+	}
+
+	oldVariables, _ := indexVariables(old)
+	for _, name := range options.Variables {
+		oldVariable, inOld := oldVariables[name]
+		variables, replaced := replaceVariable(result.GetPath("templating", "list").MustArray(), name, oldVariable)
+		switch {
+		case replaced:
+		case inOld:
+			variables = append(variables, oldVariable)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrVariableNotFound, name)
+		}
+		result.SetPath([]string{"templating", "list"}, variables)
+	}
+
+	return result, nil
+}
+
+// indexPanels returns the panels of a dashboard by id, including the panels of collapsed rows,
+// along with the ids in dashboard order.
+func indexPanels(dash *simplejson.Json) (map[string]map[string]interface{}, []string) {
+	panels := make(map[string]map[string]interface{})
+	var order []string
+	var visit func(list []interface{})
+	visit = func(list []interface{}) {
+		for _, item := range list {
+			panel, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if id, ok := panel["id"]; ok {
+				key := fmt.Sprint(id)
+				if _, exists := panels[key]; !exists {
+					order = append(order, key)
+				}
+				panels[key] = panel
+			}
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				visit(nested)
+			}
+		}
+	}
+	visit(dash.Get("panels").MustArray())
+	return panels, order
+}
+
+// replacePanel replaces the panel with the given id, looking into collapsed rows as well.
+// If replacement is nil the panel is removed.
+func replacePanel(list []interface{}, key string, replacement map[string]interface{}) ([]interface{}, bool) {
+	for i, item := range list {
+		panel, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := panel["id"]; ok && fmt.Sprint(id) == key {
+			if replacement == nil {
+				return append(list[:i:i], list[i+1:]...), true
+			}
+			list[i] = replacement
+			return list, true
+		}
+		if nested, ok := panel["panels"].([]interface{}); ok {
+			if updated, replaced := replacePanel(nested, key, replacement); replaced {
+				panel["panels"] = updated
+				return list, true
+			}
+		}
+	}
+	return list, false
+}
+
+func indexVariables(dash *simplejson.Json) (map[string]map[string]interface{}, []string) {
+	variables := make(map[string]map[string]interface{})
+	var names []string
+	for _, item := range dash.GetPath("templating", "list").MustArray() {
+		variable, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := variable["name"].(string)
+		if !ok {
+			continue
+		}
+		if _, exists := variables[name]; !exists {
+			names = append(names, name)
+		}
+		variables[name] = variable
+	}
+	return variables, names
+}
+
+// replaceVariable replaces the variable with the given name. If replacement is nil the variable is removed.
+func replaceVariable(list []interface{}, name string, replacement map[string]interface{}) ([]interface{}, bool) {
+	for i, item := range list {
+		variable, ok := item.(map[string]interface{})
+		if !ok || variable["name"] != name {
+			continue
+		}
+		if replacement == nil {
+			return append(list[:i:i], list[i+1:]...), true
+		}
+		list[i] = replacement
+		return list, true
+	}
+	return list, false
+}
+
+func panelTitle(panel map[string]interface{}) string {
+	title, _ := panel["title"].(string)
+	return title
+}
+
+// changedFields returns the sorted names of the properties that differ between two objects,
+// leaving out the ignored ones.
+func changedFields(base, new map[string]interface{}, ignored ...string) []string {
+	skip := make(map[string]bool, len(ignored))
+	for _, key := range ignored {
+		skip[key] = true
+	}
+
+	var fields []string
+	for key, value := range new {
+		if baseValue, ok := base[key]; !skip[key] && (!ok || !reflect.DeepEqual(baseValue, value)) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range base {
+		if _, ok := new[key]; !ok && !skip[key] {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,131 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	semanticBaseJSON = `{
+		"id": 1,
+		"version": 1,
+		"title": "Dashboard",
+		"refresh": "5s",
+		"panels": [
+			{"id": 1, "title": "Unchanged", "type": "graph"},
+			{"id": 2, "title": "Modified", "type": "graph", "targets": [{"expr": "up"}]},
+			{"id": 3, "title": "Removed", "type": "stat"},
+			{"id": 4, "title": "Row", "type": "row", "collapsed": true, "panels": [
+				{"id": 5, "title": "Nested", "type": "graph"}
+			]}
+		],
+		"templating": {"list": [
+			{"name": "env", "query": "prod,dev", "current": {"text": "prod"}},
+			{"name": "removed", "query": "a,b"}
+		]}
+	}`
+
+	semanticNewJSON = `{
+		"id": 1,
+		"version": 2,
+		"title": "Renamed dashboard",
+		"tags": ["new"],
+		"panels": [
+			{"id": 1, "title": "Unchanged", "type": "graph"},
+			{"id": 2, "title": "Modified", "type": "timeseries", "targets": [{"expr": "down"}]},
+			{"id": 4, "title": "Row", "type": "row", "collapsed": true, "panels": [
+				{"id": 5, "title": "Nested and changed", "type": "graph"}
+			]},
+			{"id": 6, "title": "Added", "type": "text"}
+		],
+		"templating": {"list": [
+			{"name": "env", "query": "prod,dev", "current": {"text": "dev"}},
+			{"name": "added", "query": "c"}
+		]}
+	}`
+)
+
+func TestCalculateSemanticDiff(t *testing.T) {
+	baseData, err := simplejson.NewJson([]byte(semanticBaseJSON))
+	require.NoError(t, err)
+	newData, err := simplejson.NewJson([]byte(semanticNewJSON))
+	require.NoError(t, err)
+
+	result, err := CalculateSemanticDiff(baseData, newData)
+	require.NoError(t, err)
+
+	require.Equal(t, []SemanticChange{
+		{Type: SemanticChangeModified, Key: "2", Title: "Modified", Fields: []string{"targets", "type"}},
+		{Type: SemanticChangeModified, Key: "5", Title: "Nested and changed", Fields: []string{"title"}},
+		{Type: SemanticChangeAdded, Key: "6", Title: "Added"},
+		{Type: SemanticChangeRemoved, Key: "3", Title: "Removed"},
+	}, result.Panels)
+
+	// Changing the current value of a variable is not a change of its definition.
+	require.Equal(t, []SemanticChange{
+		{Type: SemanticChangeAdded, Key: "added"},
+		{Type: SemanticChangeRemoved, Key: "removed"},
+	}, result.Variables)
+
+	require.Equal(t, []SemanticChange{
+		{Type: SemanticChangeAdded, Key: "tags"},
+		{Type: SemanticChangeModified, Key: "title"},
+		{Type: SemanticChangeRemoved, Key: "refresh"},
+	}, result.Settings)
+}
+
+func TestRestoreFromVersion(t *testing.T) {
+	load := func(t *testing.T) (*simplejson.Json, *simplejson.Json) {
+		old, err := simplejson.NewJson([]byte(semanticBaseJSON))
+		require.NoError(t, err)
+		current, err := simplejson.NewJson([]byte(semanticNewJSON))
+		require.NoError(t, err)
+		return current, old
+	}
+
+	t.Run("should only restore the selected panels and variables", func(t *testing.T) {
+		current, old := load(t)
+
+		restored, err := RestoreFromVersion(current, old, RestoreOptions{PanelIDs: []int64{2, 5}, Variables: []string{"removed"}})
+		require.NoError(t, err)
+
+		result, err := CalculateSemanticDiff(old, restored)
+		require.NoError(t, err)
+		require.Equal(t, []SemanticChange{
+			{Type: SemanticChangeAdded, Key: "6", Title: "Added"},
+			{Type: SemanticChangeRemoved, Key: "3", Title: "Removed"},
+		}, result.Panels)
+		require.Equal(t, []SemanticChange{{Type: SemanticChangeAdded, Key: "added"}}, result.Variables)
+		require.Equal(t, "Renamed dashboard", restored.Get("title").MustString())
+
+		// The current dashboard is left untouched.
+		require.Equal(t, "timeseries", current.Get("panels").GetIndex(1).Get("type").MustString())
+	})
+
+	t.Run("should add back removed panels and remove added ones", func(t *testing.T) {
+		current, old := load(t)
+
+		restored, err := RestoreFromVersion(current, old, RestoreOptions{PanelIDs: []int64{3, 6}, Variables: []string{"added"}})
+		require.NoError(t, err)
+
+		result, err := CalculateSemanticDiff(old, restored)
+		require.NoError(t, err)
+		for _, change := range result.Panels {
+			require.Equal(t, SemanticChangeModified, change.Type)
+		}
+		require.Equal(t, []SemanticChange{{Type: SemanticChangeRemoved, Key: "removed"}}, result.Variables)
+	})
+
+	t.Run("should fail if a selected panel does not exist in either version", func(t *testing.T) {
+		current, old := load(t)
+
+		_, err := RestoreFromVersion(current, old, RestoreOptions{PanelIDs: []int64{42}})
+		require.ErrorIs(t, err, ErrPanelNotFound)
+
+		_, err = RestoreFromVersion(current, old, RestoreOptions{Variables: []string{"unknown"}})
+		require.ErrorIs(t, err, ErrVariableNotFound)
+	})
+}
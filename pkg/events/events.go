@@ -78,3 +78,22 @@ type FolderTitleUpdated struct {
 	UID       string    `json:"uid"`
 	OrgID     int64     `json:"org_id"`
 }
+
+const (
+	UserSessionRevokedReasonAdmin                 = "admin"
+	UserSessionRevokedReasonMaxConcurrentSessions = "max_concurrent_sessions"
+	UserSessionRevokedReasonDeviceMismatch        = "device_mismatch"
+)
+
+// UserSessionRevoked is published when a user session is revoked by someone
+// other than the user, i.e. when the user is forcibly logged out.
+type UserSessionRevoked struct {
+	Timestamp time.Time `json:"timestamp"`
+	TokenID   int64     `json:"token_id"`
+	UserID    int64     `json:"user_id"`
+	ClientIP  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	Reason    string    `json:"reason"`
+	// RevokedBy is the ID of the user who revoked the session, 0 if revoked by Grafana.
+	RevokedBy int64 `json:"revoked_by"`
+}
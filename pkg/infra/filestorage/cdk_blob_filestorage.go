@@ -178,6 +178,9 @@ func precedingFolders(path string) []string {
 
 func (c cdkBlobStorage) CreateFolder(ctx context.Context, path string) error {
 	c.log.Info("Creating folder", "path", path)
+	if path == "" {
+		return nil // the root of the bucket always exists
+	}
 
 	precedingFolders := precedingFolders(path)
 	folderToOriginalCasing := make(map[string]string)
@@ -294,8 +297,10 @@ func (c cdkBlobStorage) list(ctx context.Context, folderPath string, paging *Pag
 	recursive := options.Recursive
 	pageSize := paging.First
 
+	// the keys are in lower case, the cursor is the original path
+	after := strings.ToLower(paging.After)
 	foundCursor := true
-	if paging.After != "" {
+	if after != "" {
 		foundCursor = false
 	}
 
@@ -329,7 +334,7 @@ func (c cdkBlobStorage) list(ctx context.Context, folderPath string, paging *Pag
 		}
 
 		if !foundCursor {
-			res := strings.Compare(strings.TrimSuffix(lowerPath, Delimiter), paging.After)
+			res := strings.Compare(strings.TrimSuffix(lowerPath, Delimiter), after)
 			if res < 0 {
 				continue
 			} else if res == 0 {
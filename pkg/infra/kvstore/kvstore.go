@@ -2,20 +2,60 @@ package kvstore
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
 	// Wildcard to query all organizations
 	AllOrganizations = -1
+
+	// defaultWatchInterval is how often the database store polls the watched items.
+	defaultWatchInterval = 5 * time.Second
 )
 
+// ErrVersionMismatch is returned by CompareAndSwap when the item was changed since its version was read.
+var ErrVersionMismatch = errors.New("the item was changed by another writer")
+
+// ProvideService returns a key/value store backed by the Grafana database.
 func ProvideService(sqlStore sqlstore.Store) KVStore {
 	return &kvStoreSQL{
-		sqlStore: sqlStore,
-		log:      log.New("infra.kvstore.sql"),
+		sqlStore:      sqlStore,
+		log:           log.New("infra.kvstore.sql"),
+		watchInterval: defaultWatchInterval,
+	}
+}
+
+// ProvideStore returns the key/value store of the backend of the [kvstore] settings.
+func ProvideStore(cfg *setting.Cfg, sqlStore sqlstore.Store) (KVStore, error) {
+	switch cfg.KVStoreBackend {
+	case "", "database":
+		watchInterval := cfg.KVStoreWatchInterval
+		if watchInterval <= 0 {
+			watchInterval = defaultWatchInterval
+		}
+		return &kvStoreSQL{
+			sqlStore:      sqlStore,
+			log:           log.New("infra.kvstore.sql"),
+			watchInterval: watchInterval,
+		}, nil
+	case "redis":
+		if cfg.RemoteCacheOptions == nil || cfg.RemoteCacheOptions.Name != "redis" {
+			return nil, errors.New("the redis key/value store uses the redis settings of the remote cache, [remote_cache] type must be redis")
+		}
+		client, err := remotecache.NewRedisClient(cfg.RemoteCacheOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redis client for the key/value store: %w", err)
+		}
+		return &kvStoreRedis{client: client, log: log.New("infra.kvstore.redis")}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key/value store backend %q, expected database or redis", cfg.KVStoreBackend)
 	}
 }
 
@@ -26,6 +66,20 @@ type KVStore interface {
 	Del(ctx context.Context, orgId int64, namespace string, key string) error
 	Keys(ctx context.Context, orgId int64, namespace string, keyPrefix string) ([]Key, error)
 	GetAll(ctx context.Context, orgId int64, namespace string) (map[int64]map[string]string, error)
+	// SetWithTTL sets an item that expires after ttl, after which it is no longer returned. A ttl of
+	// zero never expires, like Set.
+	SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, ttl time.Duration) error
+	// GetVersioned returns the value of an item with its version, to change it with CompareAndSwap.
+	GetVersioned(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error)
+	// CompareAndSwap sets an item only if its version is still version, or if it doesn't exist when
+	// version is zero, and returns its new version. It returns ErrVersionMismatch otherwise.
+	CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, version int64, value string, ttl time.Duration) (int64, error)
+	// Watch returns the changes of the items with the key prefix, including the changes made by the
+	// other Grafana instances, until the context is done. To watch all organizations the constant
+	// AllOrganizations can be passed as orgId.
+	Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan Event, error)
+	// DeleteExpired deletes the items that have expired, for the backends that don't expire them.
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
 // WithNamespace returns a kvstore wrapper with fixed orgId and namespace.
@@ -64,3 +118,23 @@ func (kv *NamespacedKVStore) Keys(ctx context.Context, keyPrefix string) ([]Key,
 func (kv *NamespacedKVStore) GetAll(ctx context.Context) (map[int64]map[string]string, error) {
 	return kv.kvStore.GetAll(ctx, kv.orgId, kv.namespace)
 }
+
+// SetWithTTL sets an item that expires after ttl.
+func (kv *NamespacedKVStore) SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return kv.kvStore.SetWithTTL(ctx, kv.orgId, kv.namespace, key, value, ttl)
+}
+
+// GetVersioned returns the value of an item with its version.
+func (kv *NamespacedKVStore) GetVersioned(ctx context.Context, key string) (string, int64, bool, error) {
+	return kv.kvStore.GetVersioned(ctx, kv.orgId, kv.namespace, key)
+}
+
+// CompareAndSwap sets an item only if its version is still version.
+func (kv *NamespacedKVStore) CompareAndSwap(ctx context.Context, key string, version int64, value string, ttl time.Duration) (int64, error) {
+	return kv.kvStore.CompareAndSwap(ctx, kv.orgId, kv.namespace, key, version, value, ttl)
+}
+
+// Watch returns the changes of the items with the key prefix.
+func (kv *NamespacedKVStore) Watch(ctx context.Context, keyPrefix string) (<-chan Event, error) {
+	return kv.kvStore.Watch(ctx, kv.orgId, kv.namespace, keyPrefix)
+}
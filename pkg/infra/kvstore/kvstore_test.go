@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -17,8 +19,9 @@ func createTestableKVStore(t *testing.T) KVStore {
 	sqlStore := sqlstore.InitTestDB(t)
 
 	kv := &kvStoreSQL{
-		sqlStore: sqlStore,
-		log:      log.New("infra.kvstore.sql"),
+		sqlStore:      sqlStore,
+		log:           log.New("infra.kvstore.sql"),
+		watchInterval: 10 * time.Millisecond,
 	}
 
 	return kv
@@ -286,3 +289,188 @@ func TestGetItems(t *testing.T) {
 		}
 	})
 }
+
+func TestIntegrationKVStoreTTL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := createTestableKVStore(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	require.NoError(t, kv.SetWithTTL(ctx, 1, "ttl", "short", "value", time.Minute))
+	require.NoError(t, kv.SetWithTTL(ctx, 1, "ttl", "long", "value", time.Hour))
+	require.NoError(t, kv.Set(ctx, 1, "ttl", "forever", "value"))
+
+	_, ok, err := kv.Get(ctx, 1, "ttl", "short")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+
+	t.Run("expired items are not returned", func(t *testing.T) {
+		_, ok, err := kv.Get(ctx, 1, "ttl", "short")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		keys, err := kv.Keys(ctx, 1, "ttl", "")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []Key{
+			{OrgId: 1, Namespace: "ttl", Key: "long"},
+			{OrgId: 1, Namespace: "ttl", Key: "forever"},
+		}, keys)
+
+		items, err := kv.GetAll(ctx, 1, "ttl")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"long": "value", "forever": "value"}, items[1])
+	})
+
+	t.Run("an expired item can be set again", func(t *testing.T) {
+		require.NoError(t, kv.SetWithTTL(ctx, 1, "ttl", "renewed", "old", time.Minute))
+		now = now.Add(2 * time.Minute)
+		require.NoError(t, kv.Set(ctx, 1, "ttl", "renewed", "new"))
+
+		value, ok, err := kv.Get(ctx, 1, "ttl", "renewed")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "new", value)
+	})
+
+	t.Run("delete expired items", func(t *testing.T) {
+		deleted, err := kv.DeleteExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		now = now.Add(2 * time.Hour)
+		deleted, err = kv.DeleteExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		keys, err := kv.Keys(ctx, 1, "ttl", "")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []Key{
+			{OrgId: 1, Namespace: "ttl", Key: "forever"},
+			{OrgId: 1, Namespace: "ttl", Key: "renewed"},
+		}, keys)
+	})
+}
+
+func TestIntegrationKVStoreCompareAndSwap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := createTestableKVStore(t)
+	ctx := context.Background()
+
+	version, err := kv.CompareAndSwap(ctx, 1, "cas", "key", 0, "first", 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), version)
+
+	t.Run("creating an existing item fails", func(t *testing.T) {
+		_, err := kv.CompareAndSwap(ctx, 1, "cas", "key", 0, "other", 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+	})
+
+	t.Run("swap with the current version", func(t *testing.T) {
+		version, err := kv.CompareAndSwap(ctx, 1, "cas", "key", 1, "second", 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), version)
+
+		value, version, ok, err := kv.GetVersioned(ctx, 1, "cas", "key")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "second", value)
+		require.Equal(t, int64(2), version)
+	})
+
+	t.Run("swap with a stale version fails", func(t *testing.T) {
+		_, err := kv.CompareAndSwap(ctx, 1, "cas", "key", 1, "stale", 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+
+		value, _, err := kv.Get(ctx, 1, "cas", "key")
+		require.NoError(t, err)
+		require.Equal(t, "second", value)
+	})
+
+	t.Run("set increments the version", func(t *testing.T) {
+		require.NoError(t, kv.Set(ctx, 1, "cas", "key", "third"))
+
+		_, version, _, err := kv.GetVersioned(ctx, 1, "cas", "key")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), version)
+	})
+}
+
+func TestIntegrationKVStoreWatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := createTestableKVStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, kv.Set(ctx, 1, "watch", "prefix-existing", "value"))
+
+	events, err := kv.Watch(ctx, 1, "watch", "prefix-")
+	require.NoError(t, err)
+
+	next := func() Event {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return Event{}
+		}
+	}
+
+	require.NoError(t, kv.Set(ctx, 1, "watch", "other", "ignored"))
+	require.NoError(t, kv.Set(ctx, 2, "watch", "prefix-key", "ignored"))
+	require.NoError(t, kv.Set(ctx, 1, "watch", "prefix-key", "value"))
+	require.Equal(t, Event{Type: EventPut, OrgId: 1, Namespace: "watch", Key: "prefix-key", Value: "value", Version: 1}, next())
+
+	require.NoError(t, kv.Del(ctx, 1, "watch", "prefix-existing"))
+	require.Equal(t, Event{Type: EventDelete, OrgId: 1, Namespace: "watch", Key: "prefix-existing"}, next())
+
+	// the recreated item has the same version as the deleted one
+	require.NoError(t, kv.Del(ctx, 1, "watch", "prefix-key"))
+	require.NoError(t, kv.Set(ctx, 1, "watch", "prefix-key", "recreated"))
+	require.Equal(t, Event{Type: EventDelete, OrgId: 1, Namespace: "watch", Key: "prefix-key"}, next())
+	require.Equal(t, Event{Type: EventPut, OrgId: 1, Namespace: "watch", Key: "prefix-key", Value: "recreated", Version: 1}, next())
+}
+
+func TestRedisItemKey(t *testing.T) {
+	require.NotEqual(t, redisItemKey(1, "a:b", "c"), redisItemKey(1, "a", "b:c"))
+	require.NotEqual(t, redisItemKey(1, "a%3Ab", "c"), redisItemKey(1, "a:b", "c"))
+	require.Equal(t, "grafana:kv:1:a%3Ab:c%25", redisItemKey(1, "a:b", "c%"))
+}
+
+func TestProvideStore(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+
+	t.Run("database is the default backend", func(t *testing.T) {
+		kv, err := ProvideStore(setting.NewCfg(), sqlStore)
+		require.NoError(t, err)
+		require.IsType(t, &kvStoreSQL{}, kv)
+		require.Equal(t, defaultWatchInterval, kv.(*kvStoreSQL).watchInterval)
+	})
+
+	t.Run("redis needs the redis remote cache", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.KVStoreBackend = "redis"
+		cfg.RemoteCacheOptions = &setting.RemoteCacheOptions{Name: "database"}
+		_, err := ProvideStore(cfg, sqlStore)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown backend", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.KVStoreBackend = "etcd"
+		_, err := ProvideStore(cfg, sqlStore)
+		require.Error(t, err)
+	})
+}
@@ -11,11 +11,19 @@ type Item struct {
 	Namespace *string
 	Key       *string
 	Value     string
+	// Version is incremented each time the item is set.
+	Version int64
+	// Expires is when the item expires in Unix milliseconds, or 0 if it never expires.
+	Expires int64
 
 	Created time.Time
 	Updated time.Time
 }
 
+func (i *Item) expired(now time.Time) bool {
+	return i.Expires > 0 && i.Expires <= now.UnixMilli()
+}
+
 func (i *Item) TableName() string {
 	return "kv_store"
 }
@@ -29,3 +37,24 @@ type Key struct {
 func (i *Key) TableName() string {
 	return "kv_store"
 }
+
+// EventType is the type of a change of an item.
+type EventType string
+
+const (
+	// EventPut is an item that was set.
+	EventPut EventType = "put"
+	// EventDelete is an item that was deleted or has expired.
+	EventDelete EventType = "delete"
+)
+
+// Event is a change of an item of the store, returned by Watch.
+type Event struct {
+	Type      EventType `json:"type"`
+	OrgId     int64     `json:"orgId"`
+	Namespace string    `json:"namespace"`
+	Key       string    `json:"key"`
+	// Value is the value of the item set, empty for a deleted item.
+	Value   string `json:"value,omitempty"`
+	Version int64  `json:"version,omitempty"`
+}
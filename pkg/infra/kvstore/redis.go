@@ -0,0 +1,312 @@
+package kvstore
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	redisKeyPrefix = "grafana:kv:"
+	// redisEventsChannel is where the changes of the items are published for Watch.
+	redisEventsChannel = "grafana:kv:events"
+)
+
+var (
+	// setScript sets the value of an item, increments its version, and returns the version. The
+	// item expires after ARGV[3] milliseconds, or never if it is 0.
+	setScript = redis.NewScript(`
+local version = redis.call("HINCRBY", KEYS[1], "version", 1)
+redis.call("HSET", KEYS[1], "value", ARGV[1], "org", ARGV[4], "namespace", ARGV[5], "key", ARGV[6])
+if version == 1 then
+	redis.call("HSET", KEYS[1], "created", ARGV[2])
+end
+redis.call("HSET", KEYS[1], "updated", ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+else
+	redis.call("PERSIST", KEYS[1])
+end
+return version
+`)
+
+	// compareAndSwapScript sets the value of an item like setScript if its version is still ARGV[7],
+	// 0 for an item that doesn't exist, and returns the new version, or 0 if the version changed.
+	compareAndSwapScript = redis.NewScript(`
+local current = tonumber(redis.call("HGET", KEYS[1], "version") or "0")
+if current ~= tonumber(ARGV[7]) then
+	return 0
+end
+local version = redis.call("HINCRBY", KEYS[1], "version", 1)
+redis.call("HSET", KEYS[1], "value", ARGV[1], "org", ARGV[4], "namespace", ARGV[5], "key", ARGV[6])
+if version == 1 then
+	redis.call("HSET", KEYS[1], "created", ARGV[2])
+end
+redis.call("HSET", KEYS[1], "updated", ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+else
+	redis.call("PERSIST", KEYS[1])
+end
+return version
+`)
+)
+
+var _ KVStore = (*kvStoreRedis)(nil)
+
+// kvStoreRedis provides a key/value store backed by Redis. The items are hashes that expire with
+// their TTL, and the changes are published to a channel for the watchers of all the instances.
+// Redis does not publish the items that expire, so they are not returned by Watch.
+type kvStoreRedis struct {
+	log    log.Logger
+	client *redis.Client
+}
+
+// Get an item from the store
+func (kv *kvStoreRedis) Get(ctx context.Context, orgId int64, namespace string, key string) (string, bool, error) {
+	value, _, ok, err := kv.GetVersioned(ctx, orgId, namespace, key)
+	return value, ok, err
+}
+
+// GetVersioned gets an item from the store with its version
+func (kv *kvStoreRedis) GetVersioned(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error) {
+	values, err := kv.client.HMGet(ctx, redisItemKey(orgId, namespace, key), "value", "version").Result()
+	if err != nil {
+		return "", 0, false, err
+	}
+	value, ok := values[0].(string)
+	if !ok {
+		return "", 0, false, nil
+	}
+	version, err := strconv.ParseInt(toString(values[1]), 10, 64)
+	if err != nil {
+		return "", 0, false, err
+	}
+	return value, version, true, nil
+}
+
+// Set an item in the store
+func (kv *kvStoreRedis) Set(ctx context.Context, orgId int64, namespace string, key string, value string) error {
+	return kv.SetWithTTL(ctx, orgId, namespace, key, value, 0)
+}
+
+// SetWithTTL sets an item in the store that expires after ttl, or never if ttl is zero
+func (kv *kvStoreRedis) SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, ttl time.Duration) error {
+	version, err := setScript.Run(ctx, kv.client, []string{redisItemKey(orgId, namespace, key)}, kv.itemArgs(orgId, namespace, key, value, ttl)...).Int64()
+	if err != nil {
+		return err
+	}
+	kv.publish(ctx, Event{Type: EventPut, OrgId: orgId, Namespace: namespace, Key: key, Value: value, Version: version})
+	return nil
+}
+
+// CompareAndSwap sets an item in the store if its version has not changed
+func (kv *kvStoreRedis) CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, version int64, value string, ttl time.Duration) (int64, error) {
+	args := append(kv.itemArgs(orgId, namespace, key, value, ttl), version)
+	newVersion, err := compareAndSwapScript.Run(ctx, kv.client, []string{redisItemKey(orgId, namespace, key)}, args...).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if newVersion == 0 {
+		return 0, ErrVersionMismatch
+	}
+	kv.publish(ctx, Event{Type: EventPut, OrgId: orgId, Namespace: namespace, Key: key, Value: value, Version: newVersion})
+	return newVersion, nil
+}
+
+func (kv *kvStoreRedis) itemArgs(orgId int64, namespace string, key string, value string, ttl time.Duration) []interface{} {
+	return []interface{}{value, timeNow().Format(time.RFC3339), ttl.Milliseconds(), orgId, namespace, key}
+}
+
+// Del deletes an item from the store.
+func (kv *kvStoreRedis) Del(ctx context.Context, orgId int64, namespace string, key string) error {
+	deleted, err := kv.client.Del(ctx, redisItemKey(orgId, namespace, key)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		kv.publish(ctx, Event{Type: EventDelete, OrgId: orgId, Namespace: namespace, Key: key})
+	}
+	return nil
+}
+
+// Keys get all keys for a given namespace and keyPrefix. To query for all
+// organizations the constant 'kvstore.AllOrganizations' can be passed as orgId.
+func (kv *kvStoreRedis) Keys(ctx context.Context, orgId int64, namespace string, keyPrefix string) ([]Key, error) {
+	items, err := kv.scan(ctx, orgId, namespace, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]Key, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, Key{OrgId: *item.OrgId, Namespace: *item.Namespace, Key: *item.Key})
+	}
+	return keys, nil
+}
+
+// GetAll get all items a given namespace and org. To query for all
+// organizations the constant 'kvstore.AllOrganizations' can be passed as orgId.
+// The map result is like map[orgId]map[key]value
+func (kv *kvStoreRedis) GetAll(ctx context.Context, orgId int64, namespace string) (map[int64]map[string]string, error) {
+	items, err := kv.scan(ctx, orgId, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	result := map[int64]map[string]string{}
+	for _, item := range items {
+		if _, ok := result[*item.OrgId]; !ok {
+			result[*item.OrgId] = map[string]string{}
+		}
+		result[*item.OrgId][*item.Key] = item.Value
+	}
+	return result, nil
+}
+
+// scan returns the items with the key prefix. The org, namespace and key of the items are read from
+// their fields, as the keys of Redis escape them.
+func (kv *kvStoreRedis) scan(ctx context.Context, orgId int64, namespace string, keyPrefix string) ([]Item, error) {
+	org := "*"
+	if orgId != AllOrganizations {
+		org = strconv.FormatInt(orgId, 10)
+	}
+	pattern := redisKeyPrefix + org + ":" + escapeRedisPattern(escapeRedisKeySegment(namespace)) + ":" + escapeRedisPattern(escapeRedisKeySegment(keyPrefix)) + "*"
+
+	var items []Item
+	iter := kv.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		fields, err := kv.client.HGetAll(ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, err
+		}
+		item, ok := redisItem(fields)
+		if !ok || *item.Namespace != namespace || !strings.HasPrefix(*item.Key, keyPrefix) ||
+			(orgId != AllOrganizations && *item.OrgId != orgId) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, iter.Err()
+}
+
+// DeleteExpired does nothing, as Redis deletes the items that expire.
+func (kv *kvStoreRedis) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// Watch subscribes to the changes published by the Grafana instances.
+func (kv *kvStoreRedis) Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan Event, error) {
+	pubsub := kv.client.Subscribe(ctx, redisEventsChannel)
+	// wait for the subscription, so that the changes made after Watch returns are received
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer func() {
+			_ = pubsub.Close()
+		}()
+
+		messages := pubsub.Channel()
+		for {
+			var msg *redis.Message
+			select {
+			case <-ctx.Done():
+				return
+			case msg = <-messages:
+			}
+			if msg == nil {
+				return
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				kv.log.Warn("invalid kvstore event", "payload", msg.Payload, "err", err)
+				continue
+			}
+			if event.Namespace != namespace || !strings.HasPrefix(event.Key, keyPrefix) ||
+				(orgId != AllOrganizations && event.OrgId != orgId) {
+				continue
+			}
+			if !sendEvent(ctx, events, event) {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// publish sends a change to the watchers. The change is already made, so a failure is only logged.
+func (kv *kvStoreRedis) publish(ctx context.Context, event Event) {
+	payload, err := json.Marshal(event)
+	if err == nil {
+		err = kv.client.Publish(ctx, redisEventsChannel, payload).Err()
+	}
+	if err != nil {
+		kv.log.Warn("failed to publish kvstore event", "orgId", event.OrgId, "namespace", event.Namespace, "key", event.Key, "err", err)
+	}
+}
+
+// redisItemKey returns the Redis key of an item. The separator is escaped in the namespace and the
+// key, so that items like ("a:b", "c") and ("a", "b:c") have different keys.
+func redisItemKey(orgId int64, namespace string, key string) string {
+	return redisKeyPrefix + strconv.FormatInt(orgId, 10) + ":" + escapeRedisKeySegment(namespace) + ":" + escapeRedisKeySegment(key)
+}
+
+var redisKeySegmentReplacer = strings.NewReplacer("%", "%25", ":", "%3A")
+
+// escapeRedisKeySegment escapes the separator of the Redis keys. The escaping keeps the prefixes
+// of the segments, which SCAN relies on.
+func escapeRedisKeySegment(s string) string {
+	return redisKeySegmentReplacer.Replace(s)
+}
+
+func redisItem(fields map[string]string) (Item, bool) {
+	orgId, err := strconv.ParseInt(fields["org"], 10, 64)
+	if err != nil {
+		return Item{}, false
+	}
+	version, err := strconv.ParseInt(fields["version"], 10, 64)
+	if err != nil {
+		return Item{}, false
+	}
+	namespace, key := fields["namespace"], fields["key"]
+	item := Item{
+		OrgId:     &orgId,
+		Namespace: &namespace,
+		Key:       &key,
+		Value:     fields["value"],
+		Version:   version,
+	}
+	item.Created, _ = time.Parse(time.RFC3339, fields["created"])
+	item.Updated, _ = time.Parse(time.RFC3339, fields["updated"])
+	return item, true
+}
+
+// escapeRedisPattern escapes the special characters of the patterns of SCAN.
+func escapeRedisPattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]^\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func toString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+//go:build redis
+// +build redis
+
+package kvstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRedisKVStore(t *testing.T) {
+	client, err := remotecache.NewRedisClient(&setting.RemoteCacheOptions{Name: "redis", ConnStr: "addr=localhost:6379"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	kv := &kvStoreRedis{client: client, log: log.New("infra.kvstore.redis")}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	namespace := "test-" + time.Now().Format(time.RFC3339Nano)
+
+	events, err := kv.Watch(ctx, 1, namespace, "prefix-")
+	require.NoError(t, err)
+	next := func() Event {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return Event{}
+		}
+	}
+
+	t.Run("set and get", func(t *testing.T) {
+		require.NoError(t, kv.Set(ctx, 1, namespace, "prefix-key", "value"))
+		require.Equal(t, Event{Type: EventPut, OrgId: 1, Namespace: namespace, Key: "prefix-key", Value: "value", Version: 1}, next())
+
+		value, version, ok, err := kv.GetVersioned(ctx, 1, namespace, "prefix-key")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "value", value)
+		require.Equal(t, int64(1), version)
+
+		keys, err := kv.Keys(ctx, AllOrganizations, namespace, "prefix-")
+		require.NoError(t, err)
+		require.Equal(t, []Key{{OrgId: 1, Namespace: namespace, Key: "prefix-key"}}, keys)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		_, err := kv.CompareAndSwap(ctx, 1, namespace, "prefix-key", 0, "other", 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+
+		version, err := kv.CompareAndSwap(ctx, 1, namespace, "prefix-key", 1, "swapped", 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), version)
+		require.Equal(t, Event{Type: EventPut, OrgId: 1, Namespace: namespace, Key: "prefix-key", Value: "swapped", Version: 2}, next())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		require.NoError(t, kv.SetWithTTL(ctx, 1, namespace, "expiring", "value", 50*time.Millisecond))
+		time.Sleep(100 * time.Millisecond)
+
+		_, ok, err := kv.Get(ctx, 1, namespace, "expiring")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("namespaces and keys with separators", func(t *testing.T) {
+		require.NoError(t, kv.Set(ctx, 1, namespace+":a", "b", "first"))
+		require.NoError(t, kv.Set(ctx, 1, namespace, "a:b", "second"))
+
+		value, ok, err := kv.Get(ctx, 1, namespace+":a", "b")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "first", value)
+
+		require.NoError(t, kv.Del(ctx, 1, namespace+":a", "b"))
+		value, ok, err = kv.Get(ctx, 1, namespace, "a:b")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "second", value)
+		require.NoError(t, kv.Del(ctx, 1, namespace, "a:b"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, kv.Del(ctx, 1, namespace, "prefix-key"))
+		require.Equal(t, Event{Type: EventDelete, OrgId: 1, Namespace: namespace, Key: "prefix-key"}, next())
+
+		_, ok, err := kv.Get(ctx, 1, namespace, "prefix-key")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
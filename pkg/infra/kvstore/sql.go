@@ -9,14 +9,24 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
+var timeNow = time.Now
+
 // kvStoreSQL provides a key/value store backed by the Grafana database
 type kvStoreSQL struct {
 	log      log.Logger
 	sqlStore sqlstore.Store
+	// watchInterval is how often the items are polled for the changes of the watched keys.
+	watchInterval time.Duration
 }
 
 // Get an item from the store
 func (kv *kvStoreSQL) Get(ctx context.Context, orgId int64, namespace string, key string) (string, bool, error) {
+	value, _, itemFound, err := kv.GetVersioned(ctx, orgId, namespace, key)
+	return value, itemFound, err
+}
+
+// GetVersioned gets an item from the store with its version
+func (kv *kvStoreSQL) GetVersioned(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error) {
 	item := Item{
 		OrgId:     &orgId,
 		Namespace: &namespace,
@@ -30,7 +40,7 @@ func (kv *kvStoreSQL) Get(ctx context.Context, orgId int64, namespace string, ke
 			kv.log.Debug("error getting kvstore value", "orgId", orgId, "namespace", namespace, "key", key, "err", err)
 			return err
 		}
-		if !has {
+		if !has || item.expired(timeNow()) {
 			kv.log.Debug("kvstore value not found", "orgId", orgId, "namespace", namespace, "key", key)
 			return nil
 		}
@@ -39,11 +49,19 @@ func (kv *kvStoreSQL) Get(ctx context.Context, orgId int64, namespace string, ke
 		return nil
 	})
 
-	return item.Value, itemFound, err
+	if !itemFound {
+		return "", 0, false, err
+	}
+	return item.Value, item.Version, itemFound, err
 }
 
 // Set an item in the store
 func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, key string, value string) error {
+	return kv.SetWithTTL(ctx, orgId, namespace, key, value, 0)
+}
+
+// SetWithTTL sets an item in the store that expires after ttl, or never if ttl is zero
+func (kv *kvStoreSQL) SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, ttl time.Duration) error {
 	return kv.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		item := Item{
 			OrgId:     &orgId,
@@ -57,16 +75,19 @@ func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, ke
 			return err
 		}
 
-		if has && item.Value == value {
+		now := timeNow()
+		expires := expiresAt(now, ttl)
+		if has && item.Value == value && item.Expires == 0 && expires == 0 {
 			kv.log.Debug("kvstore value not changed", "orgId", orgId, "namespace", namespace, "key", key, "value", value)
 			return nil
 		}
 
 		item.Value = value
-		item.Updated = time.Now()
+		item.Updated = now
+		item.Expires = expires
 
 		if has {
-			_, err = dbSession.Exec("UPDATE kv_store SET value = ?, updated = ? WHERE id = ?", item.Value, item.Updated, item.Id)
+			_, err = dbSession.Exec("UPDATE kv_store SET value = ?, updated = ?, expires = ?, version = version + 1 WHERE id = ?", item.Value, item.Updated, item.Expires, item.Id)
 			if err != nil {
 				kv.log.Debug("error updating kvstore value", "orgId", orgId, "namespace", namespace, "key", key, "value", value, "err", err)
 			} else {
@@ -76,6 +97,7 @@ func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, ke
 		}
 
 		item.Created = item.Updated
+		item.Version = 1
 		_, err = dbSession.Insert(&item)
 		if err != nil {
 			kv.log.Debug("error inserting kvstore value", "orgId", orgId, "namespace", namespace, "key", key, "value", value, "err", err)
@@ -86,6 +108,74 @@ func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, ke
 	})
 }
 
+// CompareAndSwap sets an item in the store if its version has not changed
+func (kv *kvStoreSQL) CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, version int64, value string, ttl time.Duration) (int64, error) {
+	var newVersion int64
+	err := kv.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		item := Item{
+			OrgId:     &orgId,
+			Namespace: &namespace,
+			Key:       &key,
+		}
+
+		has, err := dbSession.Get(&item)
+		if err != nil {
+			return err
+		}
+
+		now := timeNow()
+		exists := has && !item.expired(now)
+		if (version == 0 && exists) || (version != 0 && (!exists || item.Version != version)) {
+			kv.log.Debug("kvstore value version mismatch", "orgId", orgId, "namespace", namespace, "key", key, "version", version, "currentVersion", item.Version)
+			return ErrVersionMismatch
+		}
+
+		if has {
+			// the version in the condition makes the update fail if another writer changed the item
+			// since it was read, for the databases that don't lock the row
+			res, err := dbSession.Exec("UPDATE kv_store SET value = ?, updated = ?, expires = ?, version = ? WHERE id = ? AND version = ?",
+				value, now, expiresAt(now, ttl), item.Version+1, item.Id, item.Version)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected != 1 {
+				return ErrVersionMismatch
+			}
+			newVersion = item.Version + 1
+			return nil
+		}
+
+		item.Value = value
+		item.Version = 1
+		item.Expires = expiresAt(now, ttl)
+		item.Created = now
+		item.Updated = now
+		if _, err := dbSession.Insert(&item); err != nil {
+			// another writer inserted the item first
+			if kv.sqlStore.GetDialect().IsUniqueConstraintViolation(err) {
+				return ErrVersionMismatch
+			}
+			return err
+		}
+		newVersion = 1
+		return nil
+	})
+
+	return newVersion, err
+}
+
+// expiresAt returns the expiry in Unix milliseconds of an item set at now, or 0 if ttl is zero.
+func expiresAt(now time.Time, ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return now.Add(ttl).UnixMilli()
+}
+
 // Del deletes an item from the store.
 func (kv *kvStoreSQL) Del(ctx context.Context, orgId int64, namespace string, key string) error {
 	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
@@ -101,7 +191,8 @@ func (kv *kvStoreSQL) Del(ctx context.Context, orgId int64, namespace string, ke
 func (kv *kvStoreSQL) Keys(ctx context.Context, orgId int64, namespace string, keyPrefix string) ([]Key, error) {
 	var keys []Key
 	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		query := dbSession.Where("namespace = ?", namespace).And(fmt.Sprintf("%s LIKE ?", kv.sqlStore.Quote("key")), keyPrefix+"%")
+		query := dbSession.Where("namespace = ?", namespace).And(fmt.Sprintf("%s LIKE ?", kv.sqlStore.Quote("key")), keyPrefix+"%").
+			And("(expires = 0 OR expires > ?)", timeNow().UnixMilli())
 		if orgId != AllOrganizations {
 			query.And("org_id = ?", orgId)
 		}
@@ -116,7 +207,7 @@ func (kv *kvStoreSQL) Keys(ctx context.Context, orgId int64, namespace string, k
 func (kv *kvStoreSQL) GetAll(ctx context.Context, orgId int64, namespace string) (map[int64]map[string]string, error) {
 	var results []Item
 	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		query := dbSession.Where("namespace = ?", namespace)
+		query := dbSession.Where("namespace = ?", namespace).And("(expires = 0 OR expires > ?)", timeNow().UnixMilli())
 		if orgId != AllOrganizations {
 			query.And("org_id = ?", orgId)
 		}
@@ -134,3 +225,123 @@ func (kv *kvStoreSQL) GetAll(ctx context.Context, orgId int64, namespace string)
 
 	return items, err
 }
+
+// DeleteExpired deletes the items that have expired from the database.
+func (kv *kvStoreSQL) DeleteExpired(ctx context.Context) (int64, error) {
+	var affected int64
+	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		res, err := dbSession.Exec("DELETE FROM kv_store WHERE expires > 0 AND expires <= ?", timeNow().UnixMilli())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
+// Watch polls the items with the key prefix at the watch interval, and returns the items that were
+// set, deleted or have expired since the previous poll. As the items are polled from the database,
+// the changes made by the other Grafana instances are returned too, but a value set and changed
+// again within the interval only returns the last value. An item deleted and set again within the
+// interval is a new row, and returns a delete followed by the new value, even if its version is the same.
+func (kv *kvStoreSQL) Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan Event, error) {
+	versions, err := kv.watchedVersions(ctx, orgId, namespace, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(kv.watchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			items, err := kv.watchedItems(ctx, orgId, namespace, keyPrefix)
+			if err != nil {
+				if ctx.Err() == nil {
+					kv.log.Warn("failed to poll the watched kvstore items", "orgId", orgId, "namespace", namespace, "keyPrefix", keyPrefix, "err", err)
+				}
+				continue
+			}
+
+			current := make(map[Key]watchedVersion, len(items))
+			for _, item := range items {
+				k := Key{OrgId: *item.OrgId, Namespace: *item.Namespace, Key: *item.Key}
+				current[k] = watchedVersion{id: item.Id, version: item.Version}
+				previous, ok := versions[k]
+				if ok && previous == current[k] {
+					continue
+				}
+				if ok && previous.id != item.Id {
+					if !sendEvent(ctx, events, Event{Type: EventDelete, OrgId: k.OrgId, Namespace: k.Namespace, Key: k.Key}) {
+						return
+					}
+				}
+				if !sendEvent(ctx, events, Event{Type: EventPut, OrgId: k.OrgId, Namespace: k.Namespace, Key: k.Key, Value: item.Value, Version: item.Version}) {
+					return
+				}
+			}
+			for k := range versions {
+				if _, ok := current[k]; ok {
+					continue
+				}
+				if !sendEvent(ctx, events, Event{Type: EventDelete, OrgId: k.OrgId, Namespace: k.Namespace, Key: k.Key}) {
+					return
+				}
+			}
+			versions = current
+		}
+	}()
+
+	return events, nil
+}
+
+// watchedVersion identifies a change of a watched item. The versions of an item start again at 1
+// when it is deleted and set again, but it is then another row.
+type watchedVersion struct {
+	id      int64
+	version int64
+}
+
+func (kv *kvStoreSQL) watchedVersions(ctx context.Context, orgId int64, namespace string, keyPrefix string) (map[Key]watchedVersion, error) {
+	items, err := kv.watchedItems(ctx, orgId, namespace, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[Key]watchedVersion, len(items))
+	for _, item := range items {
+		versions[Key{OrgId: *item.OrgId, Namespace: *item.Namespace, Key: *item.Key}] = watchedVersion{id: item.Id, version: item.Version}
+	}
+	return versions, nil
+}
+
+func (kv *kvStoreSQL) watchedItems(ctx context.Context, orgId int64, namespace string, keyPrefix string) ([]Item, error) {
+	var items []Item
+	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		query := dbSession.Where("namespace = ?", namespace).And(fmt.Sprintf("%s LIKE ?", kv.sqlStore.Quote("key")), keyPrefix+"%").
+			And("(expires = 0 OR expires > ?)", timeNow().UnixMilli())
+		if orgId != AllOrganizations {
+			query.And("org_id = ?", orgId)
+		}
+		return query.Find(&items)
+	})
+	return items, err
+}
+
+func sendEvent(ctx context.Context, events chan<- Event, event Event) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
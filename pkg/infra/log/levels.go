@@ -0,0 +1,91 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-kit/log/level"
+)
+
+// LoggerLevels are the levels of the loggers.
+type LoggerLevels struct {
+	// Default is the level of the loggers without a level of their own.
+	Default string `json:"default"`
+	// Loggers are the levels of the loggers with a level of their own, by logger name.
+	Loggers map[string]string `json:"loggers"`
+}
+
+// Levels returns the current levels of the loggers.
+func Levels() LoggerLevels {
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+
+	levels := LoggerLevels{Default: root.levels.Default, Loggers: make(map[string]string, len(root.levels.Loggers))}
+	for name, levelName := range root.levels.Loggers {
+		levels.Loggers[name] = levelName
+	}
+	return levels
+}
+
+// LevelNames returns the names of the log levels, from the most to the least verbose.
+func LevelNames() []string {
+	names := make([]string, 0, len(logLevels))
+	for name := range logLevels {
+		names = append(names, name)
+	}
+	rank := map[string]int{"trace": 0, "debug": 1, "info": 2, "warn": 3, "error": 4, "critical": 5}
+	sort.Slice(names, func(i, j int) bool { return rank[names[i]] < rank[names[j]] })
+	return names
+}
+
+// SetLevel changes the level of a logger while Grafana is running, for all the log modes. An empty
+// logger name changes the default level, which also replaces the levels of the modes, but not the
+// level of the loggers added with AddLogger. The change is lost on restart.
+func SetLevel(loggerName string, levelName string) error {
+	option, ok := logLevels[levelName]
+	if !ok {
+		return fmt.Errorf("unknown log level %q", levelName)
+	}
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	for i := range root.logFilters {
+		if loggerName == "" {
+			if !root.logFilters[i].fixedLevel {
+				root.logFilters[i].maxLevel = option
+			}
+			continue
+		}
+		if root.logFilters[i].filters == nil {
+			root.logFilters[i].filters = map[string]level.Option{}
+		}
+		root.logFilters[i].filters[loggerName] = option
+	}
+
+	if loggerName == "" {
+		root.levels.Default = levelName
+	} else {
+		root.levels.Loggers[loggerName] = levelName
+	}
+
+	if len(root.logFilters) > 0 {
+		root.setLoggers(root.logFilters)
+	}
+	return nil
+}
+
+// ResetLevel removes the level of a logger, which then logs with the default level.
+func ResetLevel(loggerName string) {
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	for i := range root.logFilters {
+		delete(root.logFilters[i].filters, loggerName)
+	}
+	delete(root.levels.Loggers, loggerName)
+
+	if len(root.logFilters) > 0 {
+		root.setLoggers(root.logFilters)
+	}
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"testing"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLevel(t *testing.T) {
+	newLoggerScenario(t, "Changing the levels should apply to the existing and new loggers", func(t *testing.T, ctx *scenarioContext) {
+		loggedArgs := [][]interface{}{}
+		handler := gokitlog.LoggerFunc(func(i ...interface{}) error {
+			loggedArgs = append(loggedArgs, i)
+			return nil
+		})
+		root.initialize([]logWithFilters{{val: handler, maxLevel: level.AllowInfo(), filters: map[string]level.Option{}}})
+
+		one := New("one")
+		one.Debug("dropped")
+		require.Len(t, loggedArgs, 0)
+
+		require.NoError(t, SetLevel("one", "debug"))
+		one.Debug("logged")
+		New("two").Debug("dropped")
+		require.Len(t, loggedArgs, 1)
+
+		require.NoError(t, SetLevel("", "error"))
+		New("two").Info("dropped")
+		one.Debug("logged")
+		require.Len(t, loggedArgs, 2)
+
+		require.Equal(t, LoggerLevels{Default: "error", Loggers: map[string]string{"one": "debug"}}, Levels())
+
+		ResetLevel("one")
+		one.Debug("dropped")
+		one.Info("dropped")
+		require.Len(t, loggedArgs, 2)
+		require.Equal(t, LoggerLevels{Default: "error", Loggers: map[string]string{}}, Levels())
+	})
+
+	newLoggerScenario(t, "Changing the default level should keep the level of the added loggers", func(t *testing.T, ctx *scenarioContext) {
+		modeArgs := [][]interface{}{}
+		root.initialize([]logWithFilters{{val: gokitlog.LoggerFunc(func(i ...interface{}) error {
+			modeArgs = append(modeArgs, i)
+			return nil
+		}), maxLevel: level.AllowInfo(), filters: map[string]level.Option{}}})
+		addedArgs := [][]interface{}{}
+		require.NoError(t, AddLogger(gokitlog.LoggerFunc(func(i ...interface{}) error {
+			addedArgs = append(addedArgs, i)
+			return nil
+		}), "warn"))
+
+		require.NoError(t, SetLevel("", "debug"))
+		one := New("one")
+		one.Debug("logged by the mode only")
+		one.Warn("logged by both")
+		require.Len(t, modeArgs, 2)
+		require.Len(t, addedArgs, 1)
+
+		require.NoError(t, SetLevel("one", "debug"))
+		one.Debug("logged by both")
+		require.Len(t, addedArgs, 2)
+	})
+
+	newLoggerScenario(t, "Changing to an unknown level should fail", func(t *testing.T, ctx *scenarioContext) {
+		require.Error(t, SetLevel("one", "verbose"))
+		require.Equal(t, LoggerLevels{Default: "info", Loggers: map[string]string{}}, Levels())
+	})
+
+	t.Run("Level names should be ordered by verbosity", func(t *testing.T) {
+		require.Equal(t, []string{"trace", "debug", "info", "warn", "error", "critical"}, LevelNames())
+	})
+}
@@ -58,13 +58,19 @@ type logManager struct {
 	*ConcreteLogger
 	loggersByName map[string]*ConcreteLogger
 	logFilters    []logWithFilters
-	mutex         sync.RWMutex
+	// levels are the names of the levels of the loggers, as configured or changed with SetLevel.
+	levels LoggerLevels
+	// samplings are the sampling settings of the loggers, by logger name.
+	samplings map[string]sampling
+	mutex     sync.RWMutex
 }
 
 func newManager(logger gokitlog.Logger) *logManager {
 	return &logManager{
 		ConcreteLogger: newConcreteLogger(logger),
 		loggersByName:  map[string]*ConcreteLogger{},
+		levels:         LoggerLevels{Default: "info", Loggers: map[string]string{}},
+		samplings:      map[string]sampling{},
 	}
 }
 
@@ -72,6 +78,11 @@ func (lm *logManager) initialize(loggers []logWithFilters) {
 	lm.mutex.Lock()
 	defer lm.mutex.Unlock()
 
+	lm.setLoggers(loggers)
+}
+
+// setLoggers swaps the loggers of the manager and of the named loggers. The caller must hold the mutex.
+func (lm *logManager) setLoggers(loggers []logWithFilters) {
 	defaultLoggers := make([]gokitlog.Logger, len(loggers))
 	for index, logger := range loggers {
 		defaultLoggers[index] = level.NewFilter(logger.val, logger.maxLevel)
@@ -90,7 +101,7 @@ func (lm *logManager) initialize(loggers []logWithFilters) {
 		ctxLoggers := make([]gokitlog.Logger, len(loggers))
 
 		for index, logger := range loggers {
-			ctxLogger := gokitlog.With(logger.val, lm.loggersByName[name].ctx...)
+			ctxLogger := lm.sample(name, gokitlog.With(logger.val, lm.loggersByName[name].ctx...))
 			if filterLevel, exists := logger.filters[name]; !exists {
 				ctxLoggers[index] = level.NewFilter(ctxLogger, logger.maxLevel)
 			} else {
@@ -129,9 +140,9 @@ func (lm *logManager) New(ctx ...interface{}) *ConcreteLogger {
 	for _, logWithFilter := range lm.logFilters {
 		filterLevel, ok := logWithFilter.filters[loggerName]
 		if ok {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, filterLevel)
+			logWithFilter.val = level.NewFilter(lm.sample(loggerName, logWithFilter.val), filterLevel)
 		} else {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, logWithFilter.maxLevel)
+			logWithFilter.val = level.NewFilter(lm.sample(loggerName, logWithFilter.val), logWithFilter.maxLevel)
 		}
 
 		compositeLogger.loggers = append(compositeLogger.loggers, logWithFilter.val)
@@ -201,6 +212,10 @@ func (cl *ConcreteLogger) FromContext(ctx context.Context) Logger {
 		}
 	}
 
+	if attrs, ok := ctx.Value(contextualAttributesKey{}).([]interface{}); ok {
+		args = append(args, attrs...)
+	}
+
 	if len(args) > 0 {
 		return cl.New(args...)
 	}
@@ -261,6 +276,20 @@ func WithSuffix(ctxLogger *ConcreteLogger, ctx ...interface{}) *ConcreteLogger {
 	return with(ctxLogger, gokitlog.WithSuffix, ctx)
 }
 
+type contextualAttributesKey struct{}
+
+// WithContextualAttributes returns a context with key/value pairs that are added to the lines of
+// the loggers returned by Logger.FromContext, in addition to the attributes already in the context.
+// Use the same keys as the request logger for the same attributes: userId, orgId, uname, pluginId
+// and datasourceUid.
+func WithContextualAttributes(ctx context.Context, attrs []interface{}) context.Context {
+	existing, _ := ctx.Value(contextualAttributesKey{}).([]interface{})
+	combined := make([]interface{}, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, contextualAttributesKey{}, combined)
+}
+
 // ContextualLogProviderFunc contextual log provider function definition.
 type ContextualLogProviderFunc func(ctx context.Context) ([]interface{}, bool)
 
@@ -301,23 +330,34 @@ func getLogLevelFromString(levelName string) level.Option {
 func getFilters(filterStrArray []string) map[string]level.Option {
 	filterMap := make(map[string]level.Option)
 
-	for i := 0; i < len(filterStrArray); i++ {
-		filterStr := strings.TrimSpace(filterStrArray[i])
+	for name, levelName := range parseLoggerSettings(filterStrArray) {
+		filterMap[name] = getLogLevelFromString(levelName)
+	}
+
+	return filterMap
+}
+
+// parseLoggerSettings parses settings composed with logger name and value, like filters.
+func parseLoggerSettings(settingStrArray []string) map[string]string {
+	settings := make(map[string]string)
+
+	for i := 0; i < len(settingStrArray); i++ {
+		settingStr := strings.TrimSpace(settingStrArray[i])
 
-		if strings.HasPrefix(filterStr, ";") || strings.HasPrefix(filterStr, "#") {
-			if len(filterStr) == 1 {
+		if strings.HasPrefix(settingStr, ";") || strings.HasPrefix(settingStr, "#") {
+			if len(settingStr) == 1 {
 				i++
 			}
 			continue
 		}
 
-		parts := strings.Split(filterStr, ":")
+		parts := strings.Split(settingStr, ":")
 		if len(parts) > 1 {
-			filterMap[parts[0]] = getLogLevelFromString(parts[1])
+			settings[parts[0]] = strings.ToLower(parts[1])
 		}
 	}
 
-	return filterMap
+	return settings
 }
 
 func Stack(skip int) string {
@@ -367,6 +407,30 @@ func getLogFormat(format string) Formatedlogger {
 	}
 }
 
+// AddLogger adds a logger that receives the lines of all the loggers, in addition to the log modes,
+// at levelName or above and with the levels of the loggers that have a level of their own. Unlike
+// the levels of the log modes, levelName is kept when the default level is changed with SetLevel.
+func AddLogger(logger gokitlog.Logger, levelName string) error {
+	maxLevel, ok := logLevels[levelName]
+	if !ok {
+		return fmt.Errorf("unknown log level %q", levelName)
+	}
+
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+
+	filters := make(map[string]level.Option, len(root.levels.Loggers))
+	for name, filterLevelName := range root.levels.Loggers {
+		filters[name] = getLogLevelFromString(filterLevelName)
+	}
+
+	loggers := make([]logWithFilters, 0, len(root.logFilters)+1)
+	loggers = append(loggers, root.logFilters...)
+	loggers = append(loggers, logWithFilters{val: logger, filters: filters, maxLevel: maxLevel, fixedLevel: true})
+	root.setLoggers(loggers)
+	return nil
+}
+
 // this is for file logger only
 func Close() error {
 	var err error
@@ -395,6 +459,8 @@ type logWithFilters struct {
 	val      gokitlog.Logger
 	filters  map[string]level.Option
 	maxLevel level.Option
+	// fixedLevel keeps maxLevel when the default level is changed.
+	fixedLevel bool
 }
 
 func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
@@ -408,8 +474,14 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 	}
 
 	defaultLevelName, _ := getLogLevelFromConfig("log", "info", cfg)
+	defaultFilterNames := parseLoggerSettings(util.SplitString(cfg.Section("log").Key("filters").String()))
 	defaultFilters := getFilters(util.SplitString(cfg.Section("log").Key("filters").String()))
 
+	samplings, err := getSamplings(cfg.Section("log"))
+	if err != nil {
+		return err
+	}
+
 	var configLoggers []logWithFilters
 	for _, mode := range modes {
 		mode = strings.TrimSpace(mode)
@@ -474,6 +546,10 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 		configLoggers = append(configLoggers, handler)
 	}
 	if len(configLoggers) > 0 {
+		root.mutex.Lock()
+		root.levels = LoggerLevels{Default: defaultLevelName, Loggers: defaultFilterNames}
+		root.samplings = samplings
+		root.mutex.Unlock()
 		root.initialize(configLoggers)
 	}
 
@@ -333,3 +333,41 @@ func newLoggerScenario(t *testing.T, desc string, fn func(t *testing.T, ctx *sce
 		fn(t, ctx)
 	})
 }
+
+func TestWithContextualAttributes(t *testing.T) {
+	newLoggerScenario(t, "Loggers from context should log the contextual attributes", func(t *testing.T, sCtx *scenarioContext) {
+		ctx := WithContextualAttributes(context.Background(), []interface{}{"userId", int64(1), "orgId", int64(2)})
+		ctx = WithContextualAttributes(ctx, []interface{}{"pluginId", "loki"})
+
+		New("test").FromContext(ctx).Info("hello")
+		require.Len(t, sCtx.loggedArgs, 1)
+
+		// skip the attributes of the contextual log providers registered by other tests
+		args := sCtx.loggedArgs[0]
+		for len(args) > 0 && args[0] != "userId" {
+			args = args[1:]
+		}
+		require.GreaterOrEqual(t, len(args), 6)
+		require.Equal(t, []interface{}{"userId", int64(1), "orgId", int64(2), "pluginId", "loki"}, args[:6])
+	})
+}
+
+func TestAddLogger(t *testing.T) {
+	newLoggerScenario(t, "Added loggers should receive the lines at their level and with the levels of the loggers", func(t *testing.T, ctx *scenarioContext) {
+		root.initialize([]logWithFilters{{val: gokitlog.NewNopLogger(), maxLevel: level.AllowInfo()}})
+		require.NoError(t, SetLevel("verbose", "debug"))
+
+		addedArgs := [][]interface{}{}
+		require.NoError(t, AddLogger(gokitlog.LoggerFunc(func(i ...interface{}) error {
+			addedArgs = append(addedArgs, i)
+			return nil
+		}), "warn"))
+
+		New("test").Info("dropped")
+		New("test").Warn("logged")
+		New("verbose").Debug("logged")
+		require.Len(t, addedArgs, 2)
+
+		require.Error(t, AddLogger(gokitlog.NewNopLogger(), "verbose"))
+	})
+}
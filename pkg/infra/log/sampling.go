@@ -0,0 +1,94 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"golang.org/x/time/rate"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// sampling reduces the volume of the lines of a logger below the error level.
+type sampling struct {
+	// every keeps one line out of every lines.
+	every uint64
+	// perSecond is the maximum number of lines per second.
+	perSecond int
+}
+
+// getSamplings reads the sampling and rate_limits settings of a log section, which are composed
+// with logger name and number like filters.
+func getSamplings(sec *ini.Section) (map[string]sampling, error) {
+	samplings := map[string]sampling{}
+
+	for name, value := range parseLoggerSettings(util.SplitString(sec.Key("sampling").String())) {
+		every, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || every == 0 {
+			return nil, fmt.Errorf("invalid sampling %q of logger %s, expected a positive number", value, name)
+		}
+		s := samplings[name]
+		s.every = every
+		samplings[name] = s
+	}
+
+	for name, value := range parseLoggerSettings(util.SplitString(sec.Key("rate_limits").String())) {
+		perSecond, err := strconv.Atoi(value)
+		if err != nil || perSecond <= 0 {
+			return nil, fmt.Errorf("invalid rate limit %q of logger %s, expected a positive number", value, name)
+		}
+		s := samplings[name]
+		s.perSecond = perSecond
+		samplings[name] = s
+	}
+
+	return samplings, nil
+}
+
+// sample wraps the logger of a named logger with its sampling, if any. The caller must hold the mutex.
+func (lm *logManager) sample(name string, logger gokitlog.Logger) gokitlog.Logger {
+	s, ok := lm.samplings[name]
+	if !ok {
+		return logger
+	}
+
+	sampled := &sampledLogger{next: logger, every: s.every}
+	if s.perSecond > 0 {
+		sampled.limiter = rate.NewLimiter(rate.Limit(s.perSecond), s.perSecond)
+	}
+	return sampled
+}
+
+// sampledLogger drops lines below the error level, to keep one line out of every lines and at
+// most the lines allowed by the limiter.
+type sampledLogger struct {
+	next    gokitlog.Logger
+	every   uint64
+	count   uint64
+	limiter *rate.Limiter
+}
+
+func (l *sampledLogger) Log(keyvals ...interface{}) error {
+	if !isErrorLevel(keyvals) {
+		if l.every > 1 && (atomic.AddUint64(&l.count, 1)-1)%l.every != 0 {
+			return nil
+		}
+		if l.limiter != nil && !l.limiter.Allow() {
+			return nil
+		}
+	}
+	return l.next.Log(keyvals...)
+}
+
+func isErrorLevel(keyvals []interface{}) bool {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == level.Key() {
+			return keyvals[i+1] == level.ErrorValue()
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"testing"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestGetSamplings(t *testing.T) {
+	t.Run("Should read sampling and rate limits by logger", func(t *testing.T) {
+		cfg := ini.Empty()
+		sec := cfg.Section("log")
+		sec.Key("sampling").SetValue("data-proxy-log:10 tsdb.loki:2")
+		sec.Key("rate_limits").SetValue("data-proxy-log:100")
+
+		samplings, err := getSamplings(sec)
+		require.NoError(t, err)
+		require.Equal(t, map[string]sampling{
+			"data-proxy-log": {every: 10, perSecond: 100},
+			"tsdb.loki":      {every: 2},
+		}, samplings)
+	})
+
+	t.Run("Should fail on an invalid number", func(t *testing.T) {
+		cfg := ini.Empty()
+		cfg.Section("log").Key("rate_limits").SetValue("data-proxy-log:many")
+
+		_, err := getSamplings(cfg.Section("log"))
+		require.Error(t, err)
+	})
+}
+
+func TestSampledLogger(t *testing.T) {
+	newLoggerScenario(t, "Sampled loggers should drop lines below the error level", func(t *testing.T, ctx *scenarioContext) {
+		loggedArgs := [][]interface{}{}
+		handler := gokitlog.LoggerFunc(func(i ...interface{}) error {
+			loggedArgs = append(loggedArgs, i)
+			return nil
+		})
+		root.samplings = map[string]sampling{
+			"sampled": {every: 3},
+			"limited": {perSecond: 2},
+		}
+		root.initialize([]logWithFilters{{val: handler, maxLevel: level.AllowInfo()}})
+
+		sampled := New("sampled")
+		for i := 0; i < 9; i++ {
+			sampled.Info("hello")
+		}
+		require.Len(t, loggedArgs, 3)
+
+		limited := New("limited")
+		for i := 0; i < 5; i++ {
+			limited.Info("hello")
+		}
+		require.Len(t, loggedArgs, 5)
+
+		sampled.Error("failed")
+		limited.Error("failed")
+		require.Len(t, loggedArgs, 7)
+
+		New("other").Info("hello")
+		require.Len(t, loggedArgs, 8)
+	})
+}
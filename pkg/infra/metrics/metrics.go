@@ -104,6 +104,9 @@ var (
 
 	// MPublicDashboardRequestCount is a metric counter for public dashboards requests
 	MPublicDashboardRequestCount prometheus.Counter
+
+	// MPublicDashboardRateLimitedRequestCount is a metric counter for public dashboards requests rejected by the rate limits
+	MPublicDashboardRateLimitedRequestCount prometheus.Counter
 )
 
 // Timers
@@ -419,6 +422,12 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MPublicDashboardRateLimitedRequestCount = metricutil.NewCounterStartingAtZero(prometheus.CounterOpts{
+		Name:      "public_dashboard_rate_limited_request_count",
+		Help:      "counter for public dashboards requests rejected by the rate limits",
+		Namespace: ExporterName,
+	})
+
 	MStatTotalDashboards = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_dashboard",
 		Help:      "total amount of dashboards",
@@ -664,5 +673,6 @@ func initMetricVars() {
 		StatsTotalDataKeys,
 		MStatTotalPublicDashboards,
 		MPublicDashboardRequestCount,
+		MPublicDashboardRateLimitedRequestCount,
 	)
 }
@@ -76,12 +76,22 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 	return options, nil
 }
 
-func newRedisStorage(opts *setting.RemoteCacheOptions) (*redisStorage, error) {
+// NewRedisClient returns a client of the Redis server of the remote cache options, so that other
+// services can share the Redis settings of the remote cache.
+func NewRedisClient(opts *setting.RemoteCacheOptions) (*redis.Client, error) {
 	opt, err := parseRedisConnStr(opts.ConnStr)
 	if err != nil {
 		return nil, err
 	}
-	return &redisStorage{c: redis.NewClient(opt)}, nil
+	return redis.NewClient(opt), nil
+}
+
+func newRedisStorage(opts *setting.RemoteCacheOptions) (*redisStorage, error) {
+	c, err := NewRedisClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &redisStorage{c: c}, nil
 }
 
 // Set sets value to given key in session.
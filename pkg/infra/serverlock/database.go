@@ -0,0 +1,209 @@
+package serverlock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// databaseBackend keeps the locks in the server_lock table of the Grafana database.
+type databaseBackend struct {
+	SQLStore *sqlstore.SQLStore
+	tracer   tracing.Tracer
+	log      log.Logger
+}
+
+func (db *databaseBackend) name() string {
+	return "database"
+}
+
+// acquireInterval acquires the lock if it was not acquired within maxInterval. The fencing token
+// is the version of the row of the lock.
+func (db *databaseBackend) acquireInterval(ctx context.Context, actionName string, maxInterval time.Duration) (int64, bool, error) {
+	// gets or creates a lockable row
+	rowLock, err := db.getOrCreate(ctx, actionName)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// avoid execution if last lock happened less than `maxInterval` ago
+	if db.isLockWithinInterval(rowLock, maxInterval) {
+		return 0, false, nil
+	}
+
+	// try to get lock based on rowLock version
+	acquired, err := db.acquireLock(ctx, rowLock)
+	if err != nil || !acquired {
+		return 0, false, err
+	}
+	return rowLock.Version + 1, true, nil
+}
+
+func (db *databaseBackend) acquireLock(ctx context.Context, serverLock *serverLock) (bool, error) {
+	ctx, span := db.tracer.Start(ctx, "ServerLockService.acquireLock")
+	defer span.End()
+	var result bool
+
+	err := db.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		newVersion := serverLock.Version + 1
+		sql := `UPDATE server_lock SET
+			version = ?,
+			last_execution = ?
+		WHERE
+			id = ? AND version = ?`
+
+		res, err := dbSession.Exec(sql, newVersion, time.Now().Unix(), serverLock.Id, serverLock.Version)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		result = affected == 1
+
+		return err
+	})
+
+	return result, err
+}
+
+func (db *databaseBackend) getOrCreate(ctx context.Context, actionName string) (*serverLock, error) {
+	ctx, span := db.tracer.Start(ctx, "ServerLockService.getOrCreate")
+	defer span.End()
+
+	var result *serverLock
+
+	err := db.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		lockRows := []*serverLock{}
+		err := dbSession.Where("operation_uid = ?", actionName).Find(&lockRows)
+		if err != nil {
+			return err
+		}
+
+		if len(lockRows) > 0 {
+			result = lockRows[0]
+			return nil
+		}
+
+		lockRow := &serverLock{
+			OperationUID:  actionName,
+			LastExecution: 0,
+		}
+
+		_, err = dbSession.Insert(lockRow)
+		if err != nil {
+			return err
+		}
+
+		result = lockRow
+		return nil
+	})
+
+	return result, err
+}
+
+// acquireLease will check if the lock is already on the database, if it is, will check with maxInterval if it is
+// timeouted. Returns nil error if the lock was acquired correctly, with the ID of the row of the lock as fencing token.
+// As the rows get increasing IDs, a lock that has timeouted is replaced by a new row.
+func (db *databaseBackend) acquireLease(ctx context.Context, actionName string, maxInterval time.Duration) (int64, error) {
+	ctx, span := db.tracer.Start(ctx, "ServerLockService.acquireLease")
+	defer span.End()
+
+	var token int64
+	// getting the lock - as the action name has a Unique constraint, this will fail if the lock is already on the database
+	err := db.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		// we need to find if the lock is in the database
+		lockRows := []*serverLock{}
+		err := dbSession.Where("operation_uid = ?", actionName).Find(&lockRows)
+		if err != nil {
+			return err
+		}
+
+		ctxLogger := db.log.FromContext(ctx)
+
+		if len(lockRows) > 0 {
+			if db.isLockWithinInterval(lockRows[0], maxInterval) {
+				return fmt.Errorf("%w: %s", errLockHeld, actionName)
+			}
+			// lock has timeouted, so we replace it
+			if _, err := dbSession.Exec("DELETE FROM server_lock WHERE id = ?", lockRows[0].Id); err != nil {
+				return err
+			}
+		}
+
+		lockRow := &serverLock{
+			OperationUID:  actionName,
+			LastExecution: time.Now().Unix(),
+		}
+
+		affected, err := dbSession.Insert(lockRow)
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			// this means that there was no error but there is something not working correctly
+			ctxLogger.Error("Expected rows affected to be 1 if there was no error", "actionName", actionName, "rowsAffected", affected)
+		}
+		token = lockRow.Id
+		return nil
+	})
+	return token, err
+}
+
+// renewLease updates the last execution of the lock, unless it has been replaced by another server.
+func (db *databaseBackend) renewLease(ctx context.Context, actionName string, token int64, _ time.Duration) error {
+	return db.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		// the version is incremented so that the row changes even within the same second
+		sql := `UPDATE server_lock SET last_execution = ?, version = version + 1 WHERE operation_uid = ? AND id = ?`
+
+		res, err := dbSession.Exec(sql, time.Now().Unix(), actionName, token)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return errLeaseLost
+		}
+		return nil
+	})
+}
+
+// releaseLease will delete the row at the database. This is only intended to be used within the scope of LockExecuteAndRelease
+// method, but not as to manually release a Lock
+func (db *databaseBackend) releaseLease(ctx context.Context, actionName string, token int64) error {
+	ctx, span := db.tracer.Start(ctx, "ServerLockService.releaseLease")
+	defer span.End()
+
+	err := db.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sql := `DELETE FROM server_lock WHERE operation_uid = ? AND id = ?`
+
+		res, err := dbSession.Exec(sql, actionName, token)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if affected != 1 {
+			db.log.FromContext(ctx).Debug("Error releasing lock", "actionName", actionName, "rowsAffected", affected)
+		}
+		return err
+	})
+
+	return err
+}
+
+func (db *databaseBackend) isLockWithinInterval(lock *serverLock, maxInterval time.Duration) bool {
+	if lock.LastExecution != 0 {
+		lastExecutionTime := time.Unix(lock.LastExecution, 0)
+		if time.Since(lastExecutionTime) < maxInterval {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package serverlock
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultAcquired = "acquired"
+	resultSkipped  = "skipped"
+	resultError    = "error"
+)
+
+var (
+	lockAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "serverlock",
+		Name:      "attempts_total",
+		Help:      "The total amount of attempts to acquire a server lock, by result: acquired, skipped when another server has the lock, or error",
+	}, []string{"backend", "action", "result"})
+
+	lockExecutionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Subsystem: "serverlock",
+		Name:      "execution_duration_seconds",
+		Help:      "Duration of the functions executed while holding a server lock",
+		Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 300, 900, 3600},
+	}, []string{"backend", "action"})
+
+	lockLeasesLost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "serverlock",
+		Name:      "leases_lost_total",
+		Help:      "The total amount of server locks which could not be renewed while executing a function",
+	}, []string{"backend", "action"})
+)
@@ -0,0 +1,105 @@
+package serverlock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const redisKeyPrefix = "grafana:serverlock:"
+
+var (
+	// acquireIntervalScript sets the interval key of an action until it expires, and returns a new
+	// fencing token, or 0 if the key is already set.
+	acquireIntervalScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], "1", "NX", "PX", ARGV[1]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+	// acquireLeaseScript sets the lease key of an action to a new fencing token until it expires,
+	// and returns the token, or 0 if the key is already set.
+	acquireLeaseScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("SET", KEYS[1], token, "PX", ARGV[1])
+return token
+`)
+
+	// renewLeaseScript extends the lease key of an action if it is still set to the given token.
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+	// releaseLeaseScript deletes the lease key of an action if it is still set to the given token.
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+)
+
+// redisBackend keeps the locks in Redis, with keys that expire. Unlike the database backend, it
+// does not contend with the other queries of the database and handles short intervals.
+type redisBackend struct {
+	client *redis.Client
+}
+
+func (r *redisBackend) name() string {
+	return "redis"
+}
+
+func (r *redisBackend) acquireInterval(ctx context.Context, actionName string, maxInterval time.Duration) (int64, bool, error) {
+	token, err := acquireIntervalScript.Run(ctx, r.client, []string{r.key(actionName, "interval"), r.key(actionName, "fencing")}, milliseconds(maxInterval)).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+	return token, token > 0, nil
+}
+
+func (r *redisBackend) acquireLease(ctx context.Context, actionName string, maxInterval time.Duration) (int64, error) {
+	token, err := acquireLeaseScript.Run(ctx, r.client, []string{r.key(actionName, "lease"), r.key(actionName, "fencing")}, milliseconds(maxInterval)).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if token == 0 {
+		return 0, fmt.Errorf("%w: %s", errLockHeld, actionName)
+	}
+	return token, nil
+}
+
+func (r *redisBackend) renewLease(ctx context.Context, actionName string, token int64, maxInterval time.Duration) error {
+	renewed, err := renewLeaseScript.Run(ctx, r.client, []string{r.key(actionName, "lease")}, token, milliseconds(maxInterval)).Int64()
+	if err != nil {
+		return err
+	}
+	if renewed == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+func (r *redisBackend) releaseLease(ctx context.Context, actionName string, token int64) error {
+	return releaseLeaseScript.Run(ctx, r.client, []string{r.key(actionName, "lease")}, token).Err()
+}
+
+func (r *redisBackend) key(actionName, kind string) string {
+	return redisKeyPrefix + actionName + ":" + kind
+}
+
+// milliseconds returns the duration in milliseconds for the expiry of a key, which must be positive.
+func milliseconds(d time.Duration) int64 {
+	if ms := d.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
@@ -0,0 +1,71 @@
+//go:build redis
+// +build redis
+
+package serverlock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRedisBackend(t *testing.T) {
+	client, err := remotecache.NewRedisClient(&setting.RemoteCacheOptions{Name: "redis", ConnStr: "addr=localhost:6379"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	b := &redisBackend{client: client}
+	ctx := context.Background()
+	actionName := "test-operation-" + time.Now().Format(time.RFC3339Nano)
+
+	t.Run("interval", func(t *testing.T) {
+		token, acquired, err := b.acquireInterval(ctx, actionName, 50*time.Millisecond)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		_, acquired, err = b.acquireInterval(ctx, actionName, 50*time.Millisecond)
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		time.Sleep(100 * time.Millisecond)
+		token2, acquired, err := b.acquireInterval(ctx, actionName, 50*time.Millisecond)
+		require.NoError(t, err)
+		require.True(t, acquired)
+		require.Greater(t, token2, token)
+	})
+
+	t.Run("lease", func(t *testing.T) {
+		token, err := b.acquireLease(ctx, actionName, 100*time.Millisecond)
+		require.NoError(t, err)
+
+		_, err = b.acquireLease(ctx, actionName, 100*time.Millisecond)
+		require.ErrorIs(t, err, errLockHeld)
+
+		// the lease is kept as long as it is renewed
+		for i := 0; i < 3; i++ {
+			time.Sleep(50 * time.Millisecond)
+			require.NoError(t, b.renewLease(ctx, actionName, token, 100*time.Millisecond))
+		}
+
+		// until it expires and another server acquires it
+		time.Sleep(150 * time.Millisecond)
+		token2, err := b.acquireLease(ctx, actionName, time.Minute)
+		require.NoError(t, err)
+		require.Greater(t, token2, token)
+		require.ErrorIs(t, b.renewLease(ctx, actionName, token, time.Minute), errLeaseLost)
+
+		// releasing with the old token keeps the lease of the other server
+		require.NoError(t, b.releaseLease(ctx, actionName, token))
+		_, err = b.acquireLease(ctx, actionName, time.Minute)
+		require.ErrorIs(t, err, errLockHeld)
+
+		require.NoError(t, b.releaseLease(ctx, actionName, token2))
+		_, err = b.acquireLease(ctx, actionName, time.Minute)
+		require.NoError(t, err)
+	})
+}
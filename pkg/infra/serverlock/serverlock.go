@@ -3,29 +3,90 @@ package serverlock
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 	"go.opentelemetry.io/otel/attribute"
 )
 
-func ProvideService(sqlStore *sqlstore.SQLStore, tracer tracing.Tracer) *ServerLockService {
-	return &ServerLockService{
-		SQLStore: sqlStore,
-		tracer:   tracer,
-		log:      log.New("infra.lockservice"),
+var (
+	// errLockHeld is returned when acquiring a lock held by another server.
+	errLockHeld = errors.New("there is already a lock for this actionName")
+	// errLeaseLost is returned when renewing a lock that has timeouted and was acquired by another server.
+	errLeaseLost = errors.New("the lock was acquired by another server")
+)
+
+// backend keeps the locks shared by the servers in HA mode.
+type backend interface {
+	name() string
+	// acquireInterval acquires the lock of the action unless it was acquired within maxInterval.
+	// It returns the fencing token of the lock, and whether it was acquired.
+	acquireInterval(ctx context.Context, actionName string, maxInterval time.Duration) (int64, bool, error)
+	// acquireLease acquires the lock of the action until it is released, or until it timeouts after
+	// maxInterval. It returns the fencing token of the lock, or an error if the lock is held.
+	acquireLease(ctx context.Context, actionName string, maxInterval time.Duration) (int64, error)
+	// renewLease extends the lock of the action by maxInterval. It returns errLeaseLost if the lock
+	// with the given token has been acquired by another server.
+	renewLease(ctx context.Context, actionName string, token int64, maxInterval time.Duration) error
+	// releaseLease releases the lock of the action, if it still has the given token.
+	releaseLease(ctx context.Context, actionName string, token int64) error
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, tracer tracing.Tracer) (*ServerLockService, error) {
+	logger := log.New("infra.lockservice")
+
+	var b backend
+	switch cfg.ServerLockBackend {
+	case "", "database":
+		b = &databaseBackend{SQLStore: sqlStore, tracer: tracer, log: logger}
+	case "redis":
+		if cfg.RemoteCacheOptions == nil || cfg.RemoteCacheOptions.Name != "redis" {
+			return nil, errors.New("the redis server lock backend uses the redis settings of the remote cache, [remote_cache] type must be redis")
+		}
+		client, err := remotecache.NewRedisClient(cfg.RemoteCacheOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redis client for server locks: %w", err)
+		}
+		b = &redisBackend{client: client}
+	default:
+		return nil, fmt.Errorf("unsupported server lock backend %q, expected database or redis", cfg.ServerLockBackend)
 	}
+
+	return &ServerLockService{
+		backend: b,
+		tracer:  tracer,
+		log:     logger,
+	}, nil
 }
 
 // ServerLockService allows servers in HA mode to claim a lock and execute a function if the server was granted the lock
 // It exposes 2 services LockAndExecute and LockExecuteAndRelease, which are intended to be used independently, don't mix
 // them up (ie, use the same actionName for both of them).
+//
+// The function executed gets a context with the fencing token of the lock, see FencingToken.
 type ServerLockService struct {
-	SQLStore *sqlstore.SQLStore
-	tracer   tracing.Tracer
-	log      log.Logger
+	backend backend
+	tracer  tracing.Tracer
+	log     log.Logger
+	// renewInterval is how often the lock of LockExecuteAndRelease is renewed. Defaults to a
+	// third of maxInterval, so that a renewal can fail without losing the lock.
+	renewInterval time.Duration
+}
+
+type fencingTokenKey struct{}
+
+// FencingToken returns the fencing token of the lock held while executing a function of the
+// ServerLockService. The tokens of the locks of an action increase each time the lock is acquired,
+// so that the systems written to by the function can reject the writes of a server whose lock has
+// timeouted and was acquired by another server since.
+func FencingToken(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(fencingTokenKey{}).(int64)
+	return token, ok
 }
 
 // LockAndExecute try to create a lock for this server and only executes the
@@ -40,27 +101,18 @@ func (sl *ServerLockService) LockAndExecute(ctx context.Context, actionName stri
 	ctxLogger := sl.log.FromContext(ctx)
 	ctxLogger.Debug("Start LockAndExecute", "actionName", actionName)
 
-	// gets or creates a lockable row
-	rowLock, err := sl.getOrCreate(ctx, actionName)
-	if err != nil {
-		span.RecordError(err)
-		return err
-	}
-
-	// avoid execution if last lock happened less than `maxInterval` ago
-	if sl.isLockWithinInterval(rowLock, maxInterval) {
-		return nil
-	}
-
-	// try to get lock based on rowLock version
-	acquiredLock, err := sl.acquireLock(ctx, rowLock)
+	token, acquiredLock, err := sl.backend.acquireInterval(ctx, actionName, maxInterval)
 	if err != nil {
+		lockAttempts.WithLabelValues(sl.backend.name(), actionName, resultError).Inc()
 		span.RecordError(err)
 		return err
 	}
 
 	if acquiredLock {
-		sl.executeFunc(ctx, actionName, fn)
+		lockAttempts.WithLabelValues(sl.backend.name(), actionName, resultAcquired).Inc()
+		sl.executeFunc(ctx, actionName, token, fn)
+	} else {
+		lockAttempts.WithLabelValues(sl.backend.name(), actionName, resultSkipped).Inc()
 	}
 
 	ctxLogger.Debug("LockAndExecute finished", "actionName", actionName, "acquiredLock", acquiredLock, "duration", time.Since(start))
@@ -68,73 +120,11 @@ func (sl *ServerLockService) LockAndExecute(ctx context.Context, actionName stri
 	return nil
 }
 
-func (sl *ServerLockService) acquireLock(ctx context.Context, serverLock *serverLock) (bool, error) {
-	ctx, span := sl.tracer.Start(ctx, "ServerLockService.acquireLock")
-	defer span.End()
-	var result bool
-
-	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		newVersion := serverLock.Version + 1
-		sql := `UPDATE server_lock SET
-			version = ?,
-			last_execution = ?
-		WHERE
-			id = ? AND version = ?`
-
-		res, err := dbSession.Exec(sql, newVersion, time.Now().Unix(), serverLock.Id, serverLock.Version)
-		if err != nil {
-			return err
-		}
-
-		affected, err := res.RowsAffected()
-		result = affected == 1
-
-		return err
-	})
-
-	return result, err
-}
-
-func (sl *ServerLockService) getOrCreate(ctx context.Context, actionName string) (*serverLock, error) {
-	ctx, span := sl.tracer.Start(ctx, "ServerLockService.getOrCreate")
-	defer span.End()
-
-	var result *serverLock
-
-	err := sl.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		lockRows := []*serverLock{}
-		err := dbSession.Where("operation_uid = ?", actionName).Find(&lockRows)
-		if err != nil {
-			return err
-		}
-
-		if len(lockRows) > 0 {
-			result = lockRows[0]
-			return nil
-		}
-
-		lockRow := &serverLock{
-			OperationUID:  actionName,
-			LastExecution: 0,
-		}
-
-		_, err = dbSession.Insert(lockRow)
-		if err != nil {
-			return err
-		}
-
-		result = lockRow
-		return nil
-	})
-
-	return result, err
-}
-
 // LockExecuteAndRelease Creates the lock, executes the func, and then release the locks. The locking mechanism is
-// based on the UNIQUE constraint of the actionName in the database (column  operation_uid), so a new process can not insert
-// a new operation if already exists one. The parameter 'maxInterval' is a timeout safeguard, if the LastExecution in the
-// database is older than maxInterval, we will assume the lock as timeouted. The 'maxInterval' parameter should be so long
-// that is impossible for 2 processes to run at the same time.
+// based on the UNIQUE constraint of the actionName in the database (column  operation_uid), or on a key in Redis, so a
+// new process can not acquire the lock if another one holds it. The parameter 'maxInterval' is a timeout safeguard: the
+// lock is renewed while the func executes, and if it has not been renewed for maxInterval, we will assume the lock as
+// timeouted. The context of the func is canceled if the lock could not be renewed and was acquired by another process.
 func (sl *ServerLockService) LockExecuteAndRelease(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error {
 	start := time.Now()
 	ctx, span := sl.tracer.Start(ctx, "ServerLockService.LockExecuteAndRelease")
@@ -144,16 +134,31 @@ func (sl *ServerLockService) LockExecuteAndRelease(ctx context.Context, actionNa
 	ctxLogger := sl.log.FromContext(ctx)
 	ctxLogger.Debug("Start LockExecuteAndRelease", "actionName", actionName)
 
-	err := sl.acquireForRelease(ctx, actionName, maxInterval)
+	token, err := sl.backend.acquireLease(ctx, actionName, maxInterval)
 	// could not get the lock, returning
 	if err != nil {
+		result := resultError
+		if errors.Is(err, errLockHeld) {
+			result = resultSkipped
+		}
+		lockAttempts.WithLabelValues(sl.backend.name(), actionName, result).Inc()
 		span.RecordError(err)
 		return err
 	}
+	lockAttempts.WithLabelValues(sl.backend.name(), actionName, resultAcquired).Inc()
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+	renewDone := make(chan struct{})
+	go func() {
+		defer close(renewDone)
+		sl.renewLease(leaseCtx, cancel, actionName, token, maxInterval)
+	}()
 
-	sl.executeFunc(ctx, actionName, fn)
+	sl.executeFunc(leaseCtx, actionName, token, fn)
+	cancel()
+	<-renewDone
 
-	err = sl.releaseLock(ctx, actionName)
+	err = sl.backend.releaseLease(ctx, actionName, token)
 	if err != nil {
 		span.RecordError(err)
 		ctxLogger.Error("Failed to release the lock", "error", err)
@@ -164,104 +169,49 @@ func (sl *ServerLockService) LockExecuteAndRelease(ctx context.Context, actionNa
 	return nil
 }
 
-// acquireForRelease will check if the lock is already on the database, if it is, will check with maxInterval if it is
-// timeouted. Returns nil error if the lock was acquired correctly
-func (sl *ServerLockService) acquireForRelease(ctx context.Context, actionName string, maxInterval time.Duration) error {
-	ctx, span := sl.tracer.Start(ctx, "ServerLockService.acquireForRelease")
-	defer span.End()
-
-	// getting the lock - as the action name has a Unique constraint, this will fail if the lock is already on the database
-	err := sl.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		// we need to find if the lock is in the database
-		lockRows := []*serverLock{}
-		err := dbSession.Where("operation_uid = ?", actionName).Find(&lockRows)
-		if err != nil {
-			return err
-		}
-
-		ctxLogger := sl.log.FromContext(ctx)
-
-		if len(lockRows) > 0 {
-			result := lockRows[0]
-			if sl.isLockWithinInterval(result, maxInterval) {
-				return errors.New("there is already a lock for this actionName: " + actionName)
-			} else {
-				// lock has timeouted, so we update the timestamp
-				result.LastExecution = time.Now().Unix()
-				cond := &serverLock{OperationUID: actionName}
-				affected, err := dbSession.Update(result, cond)
-				if err != nil {
-					return err
-				}
-				if affected != 1 {
-					ctxLogger.Error("Expected rows affected to be 1 if there was no error", "actionName", actionName, "rowsAffected", affected)
-				}
-				return nil
-			}
-		} else {
-			// lock not found, creating it
-			lockRow := &serverLock{
-				OperationUID:  actionName,
-				LastExecution: time.Now().Unix(),
-			}
-
-			affected, err := dbSession.Insert(lockRow)
-			if err != nil {
-				return err
-			}
-
-			if affected != 1 {
-				// this means that there was no error but there is something not working correctly
-				ctxLogger.Error("Expected rows affected to be 1 if there was no error", "actionName", actionName, "rowsAffected", affected)
-			}
-		}
-		return nil
-	})
-	return err
-}
-
-// releaseLock will delete the row at the database. This is only intended to be used within the scope of LockExecuteAndRelease
-// method, but not as to manually release a Lock
-func (sl *ServerLockService) releaseLock(ctx context.Context, actionName string) error {
-	ctx, span := sl.tracer.Start(ctx, "ServerLockService.releaseLock")
-	defer span.End()
-
-	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		sql := `DELETE FROM server_lock WHERE operation_uid=? `
-
-		res, err := dbSession.Exec(sql, actionName)
-		if err != nil {
-			return err
-		}
-		affected, err := res.RowsAffected()
-		if affected != 1 {
-			sl.log.FromContext(ctx).Debug("Error releasing lock", "actionName", actionName, "rowsAffected", affected)
+// renewLease renews the lock until the context is done. If the lock was acquired by another server,
+// the context is canceled.
+func (sl *ServerLockService) renewLease(ctx context.Context, cancel context.CancelFunc, actionName string, token int64, maxInterval time.Duration) {
+	interval := sl.renewInterval
+	if interval == 0 {
+		interval = maxInterval / 3
+	}
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		return err
-	})
-
-	return err
-}
 
-func (sl *ServerLockService) isLockWithinInterval(lock *serverLock, maxInterval time.Duration) bool {
-	if lock.LastExecution != 0 {
-		lastExecutionTime := time.Unix(lock.LastExecution, 0)
-		if time.Since(lastExecutionTime) < maxInterval {
-			return true
+		err := sl.backend.renewLease(ctx, actionName, token, maxInterval)
+		switch {
+		case errors.Is(err, errLeaseLost):
+			lockLeasesLost.WithLabelValues(sl.backend.name(), actionName).Inc()
+			sl.log.FromContext(ctx).Error("Lost the lock while executing, canceling", "actionName", actionName, "fencingToken", token)
+			cancel()
+			return
+		case err != nil && ctx.Err() == nil:
+			sl.log.FromContext(ctx).Warn("Failed to renew the lock", "actionName", actionName, "error", err)
 		}
 	}
-	return false
 }
 
-func (sl ServerLockService) executeFunc(ctx context.Context, actionName string, fn func(ctx context.Context)) {
+func (sl *ServerLockService) executeFunc(ctx context.Context, actionName string, token int64, fn func(ctx context.Context)) {
 	start := time.Now()
 	ctx, span := sl.tracer.Start(ctx, "ServerLockService.executeFunc")
 	defer span.End()
 
 	ctxLogger := sl.log.FromContext(ctx)
-	ctxLogger.Debug("Start execution", "actionName", actionName)
+	ctxLogger.Debug("Start execution", "actionName", actionName, "fencingToken", token)
 
-	fn(ctx)
+	fn(context.WithValue(ctx, fencingTokenKey{}, token))
 
+	lockExecutionDuration.WithLabelValues(sl.backend.name(), actionName).Observe(time.Since(start).Seconds())
 	ctxLogger.Debug("Execution finished", "actionName", actionName, "duration", time.Since(start))
 }
@@ -2,31 +2,45 @@ package serverlock
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
-func createTestableServerLock(t *testing.T) *ServerLockService {
+func createTestableDatabaseBackend(t *testing.T) *databaseBackend {
 	t.Helper()
 
 	store := sqlstore.InitTestDB(t)
 
-	return &ServerLockService{
+	return &databaseBackend{
 		SQLStore: store,
 		tracer:   tracing.InitializeTracerForTest(),
 		log:      log.New("test-logger"),
 	}
 }
 
+func createTestableServerLock(t *testing.T) *ServerLockService {
+	t.Helper()
+
+	return &ServerLockService{
+		backend: createTestableDatabaseBackend(t),
+		tracer:  tracing.InitializeTracerForTest(),
+		log:     log.New("test-logger"),
+	}
+}
+
 func TestServerLock(t *testing.T) {
-	sl := createTestableServerLock(t)
+	sl := createTestableDatabaseBackend(t)
 	operationUID := "test-operation"
 
 	first, err := sl.getOrCreate(context.Background(), operationUID)
@@ -64,40 +78,41 @@ func TestLockAndRelease(t *testing.T) {
 	operationUID := "test-operation-release"
 
 	t.Run("create lock and then release it", func(t *testing.T) {
-		sl := createTestableServerLock(t)
+		sl := createTestableDatabaseBackend(t)
 		duration := time.Hour * 5
 
-		err := sl.acquireForRelease(context.Background(), operationUID, duration)
+		token, err := sl.acquireLease(context.Background(), operationUID, duration)
 		require.NoError(t, err)
 
-		err = sl.releaseLock(context.Background(), operationUID)
+		err = sl.releaseLease(context.Background(), operationUID, token)
 		require.NoError(t, err)
 
-		// and now we can acquire it again
-		err2 := sl.acquireForRelease(context.Background(), operationUID, duration)
+		// and now we can acquire it again, with a greater fencing token
+		token2, err2 := sl.acquireLease(context.Background(), operationUID, duration)
 		require.NoError(t, err2)
+		require.Greater(t, token2, token)
 
-		err = sl.releaseLock(context.Background(), operationUID)
+		err = sl.releaseLease(context.Background(), operationUID, token2)
 		require.NoError(t, err)
 	})
 
 	t.Run("try to acquire a lock which is already locked, get error", func(t *testing.T) {
-		sl := createTestableServerLock(t)
+		sl := createTestableDatabaseBackend(t)
 		duration := time.Hour * 5
 
-		err := sl.acquireForRelease(context.Background(), operationUID, duration)
+		token, err := sl.acquireLease(context.Background(), operationUID, duration)
 		require.NoError(t, err)
 
-		err2 := sl.acquireForRelease(context.Background(), operationUID, duration)
+		_, err2 := sl.acquireLease(context.Background(), operationUID, duration)
 		require.Error(t, err2, "We should expect an error when trying to get the second lock")
 		require.Equal(t, "there is already a lock for this actionName: "+operationUID, err2.Error())
 
-		err3 := sl.releaseLock(context.Background(), operationUID)
+		err3 := sl.releaseLease(context.Background(), operationUID, token)
 		require.NoError(t, err3)
 	})
 
 	t.Run("lock already exists but is timeouted", func(t *testing.T) {
-		sl := createTestableServerLock(t)
+		sl := createTestableDatabaseBackend(t)
 		pastLastExec := time.Now().Add(-time.Hour).Unix()
 		lock := serverLock{
 			OperationUID:  operationUID,
@@ -114,7 +129,7 @@ func TestLockAndRelease(t *testing.T) {
 		require.NoError(t, err)
 		duration := time.Minute * 5
 
-		err = sl.acquireForRelease(context.Background(), operationUID, duration)
+		token, err := sl.acquireLease(context.Background(), operationUID, duration)
 		require.NoError(t, err)
 
 		//validate that the lock LastExecution was updated (at least different from the original)
@@ -128,7 +143,153 @@ func TestLockAndRelease(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err3 := sl.releaseLock(context.Background(), operationUID)
+		// the lock can be renewed, until another server acquires it after it timeouted
+		require.NoError(t, sl.renewLease(context.Background(), operationUID, token, duration))
+		err = sl.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("UPDATE server_lock SET last_execution = ? WHERE operation_uid = ?", pastLastExec, operationUID)
+			return err
+		})
+		require.NoError(t, err)
+		token2, err := sl.acquireLease(context.Background(), operationUID, duration)
+		require.NoError(t, err)
+		require.Greater(t, token2, token)
+		require.ErrorIs(t, sl.renewLease(context.Background(), operationUID, token, duration), errLeaseLost)
+
+		// releasing the timeouted lock does not release the lock of the other server
+		require.NoError(t, sl.releaseLease(context.Background(), operationUID, token))
+		_, err = sl.acquireLease(context.Background(), operationUID, duration)
+		require.ErrorIs(t, err, errLockHeld)
+
+		err3 := sl.releaseLease(context.Background(), operationUID, token2)
 		require.NoError(t, err3)
 	})
 }
+
+// fakeBackend is a backend which holds the lease of a single action.
+type fakeBackend struct {
+	mu       sync.Mutex
+	token    int64
+	held     bool
+	renewals int
+	lost     bool
+}
+
+func (f *fakeBackend) name() string { return "fake" }
+
+func (f *fakeBackend) acquireInterval(_ context.Context, _ string, _ time.Duration) (int64, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token++
+	return f.token, true, nil
+}
+
+func (f *fakeBackend) acquireLease(_ context.Context, actionName string, _ time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.held {
+		return 0, fmt.Errorf("%w: %s", errLockHeld, actionName)
+	}
+	f.held = true
+	f.token++
+	return f.token, nil
+}
+
+func (f *fakeBackend) renewLease(_ context.Context, _ string, token int64, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.renewals++
+	if f.lost || token != f.token {
+		return errLeaseLost
+	}
+	return nil
+}
+
+func (f *fakeBackend) releaseLease(_ context.Context, _ string, token int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if token == f.token {
+		f.held = false
+	}
+	return nil
+}
+
+func TestServerLockService_FencingTokens(t *testing.T) {
+	b := &fakeBackend{}
+	sl := &ServerLockService{backend: b, tracer: tracing.InitializeTracerForTest(), log: log.New("test-logger")}
+
+	var tokens []int64
+	fn := func(ctx context.Context) {
+		token, ok := FencingToken(ctx)
+		require.True(t, ok)
+		tokens = append(tokens, token)
+	}
+	require.NoError(t, sl.LockAndExecute(context.Background(), "test-operation", time.Hour, fn))
+	require.NoError(t, sl.LockExecuteAndRelease(context.Background(), "test-operation", time.Hour, fn))
+	assert.Equal(t, []int64{1, 2}, tokens)
+
+	_, ok := FencingToken(context.Background())
+	assert.False(t, ok)
+}
+
+func TestServerLockService_LeaseRenewal(t *testing.T) {
+	t.Run("lock is renewed while executing", func(t *testing.T) {
+		b := &fakeBackend{}
+		sl := &ServerLockService{backend: b, tracer: tracing.InitializeTracerForTest(), log: log.New("test-logger"), renewInterval: time.Millisecond}
+
+		err := sl.LockExecuteAndRelease(context.Background(), "test-operation", time.Hour, func(ctx context.Context) {
+			require.Eventually(t, func() bool {
+				b.mu.Lock()
+				defer b.mu.Unlock()
+				return b.renewals >= 3
+			}, time.Second, time.Millisecond)
+			require.NoError(t, ctx.Err())
+		})
+		require.NoError(t, err)
+		assert.False(t, b.held, "lock should be released")
+	})
+
+	t.Run("execution is canceled when the lock is lost", func(t *testing.T) {
+		b := &fakeBackend{lost: true}
+		sl := &ServerLockService{backend: b, tracer: tracing.InitializeTracerForTest(), log: log.New("test-logger"), renewInterval: time.Millisecond}
+		before := testutil.ToFloat64(lockLeasesLost.WithLabelValues("fake", "test-operation"))
+
+		err := sl.LockExecuteAndRelease(context.Background(), "test-operation", time.Hour, func(ctx context.Context) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+				t.Error("context should be canceled when the lock is lost")
+			}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, before+1, testutil.ToFloat64(lockLeasesLost.WithLabelValues("fake", "test-operation")))
+	})
+
+	t.Run("lock held by another server", func(t *testing.T) {
+		b := &fakeBackend{held: true}
+		sl := &ServerLockService{backend: b, tracer: tracing.InitializeTracerForTest(), log: log.New("test-logger")}
+		before := testutil.ToFloat64(lockAttempts.WithLabelValues("fake", "test-operation", resultSkipped))
+
+		executed := false
+		err := sl.LockExecuteAndRelease(context.Background(), "test-operation", time.Hour, func(context.Context) { executed = true })
+		require.ErrorIs(t, err, errLockHeld)
+		assert.False(t, executed)
+		assert.Equal(t, before+1, testutil.ToFloat64(lockAttempts.WithLabelValues("fake", "test-operation", resultSkipped)))
+	})
+}
+
+func TestProvideService(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.ServerLockBackend = "redis"
+	cfg.RemoteCacheOptions = &setting.RemoteCacheOptions{Name: "database"}
+	_, err := ProvideService(cfg, nil, tracing.InitializeTracerForTest())
+	require.Error(t, err)
+
+	cfg.RemoteCacheOptions = &setting.RemoteCacheOptions{Name: "redis", ConnStr: "addr=localhost:6379"}
+	sl, err := ProvideService(cfg, nil, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	assert.Equal(t, "redis", sl.backend.name())
+
+	cfg.ServerLockBackend = "etcd"
+	_, err = ProvideService(cfg, nil, tracing.InitializeTracerForTest())
+	require.Error(t, err)
+}
@@ -59,6 +59,11 @@ type Opentelemetry struct {
 	tracerProvider tracerProvider
 	tracer         trace.Tracer
 
+	export          otlpExportSettings
+	exportClient    otlpClient
+	metricsExporter *metricsExporter
+	logsExporter    *logsExporter
+
 	Cfg *setting.Cfg
 }
 
@@ -102,6 +107,10 @@ func (ots *Opentelemetry) parseSettingsOpentelemetry() error {
 		return err
 	}
 
+	if err := ots.parseSettingsOTLPExport(); err != nil {
+		return err
+	}
+
 	section, err = ots.Cfg.Raw.GetSection("tracing.opentelemetry.jaeger")
 	if err != nil {
 		return err
@@ -257,6 +266,7 @@ func (ots *Opentelemetry) Run(ctx context.Context) error {
 			ots.log.Error("OpenTelemetry log returning error", err)
 		}
 	}))
+	ots.runOTLPExport(ctx)
 	<-ctx.Done()
 
 	ots.log.Info("Closing tracing")
@@ -280,7 +290,7 @@ func (ots *Opentelemetry) Start(ctx context.Context, spanName string, opts ...tr
 	}
 
 	if traceID := span.SpanContext().TraceID(); traceID.IsValid() {
-		ctx = context.WithValue(ctx, traceKey{}, traceValue{traceID.String(), span.SpanContext().IsSampled()})
+		ctx = context.WithValue(ctx, traceKey{}, traceValue{traceID.String(), span.SpanContext().IsSampled(), span.SpanContext().SpanID().String()})
 	}
 
 	return ctx, opentelemetrySpan
@@ -0,0 +1,296 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	otlpProtocolGRPC = "grpc"
+	otlpProtocolHTTP = "http"
+
+	// orgCountMetric is the gauge of the usage stats with the number of orgs, exported as a resource attribute.
+	orgCountMetric = "grafana_stat_total_orgs"
+)
+
+// otlpExportSettings are the settings of the export of the metrics and logs to an OTLP endpoint,
+// alongside the traces.
+type otlpExportSettings struct {
+	address         string
+	protocol        string
+	metricsEnabled  bool
+	metricsInterval time.Duration
+	logsEnabled     bool
+	logsLevel       string
+	logsInterval    time.Duration
+}
+
+func (ots *Opentelemetry) parseSettingsOTLPExport() error {
+	section := ots.Cfg.Raw.Section("tracing.opentelemetry")
+
+	ots.export = otlpExportSettings{
+		address:         section.Key("otlp_address").MustString(""),
+		protocol:        strings.ToLower(section.Key("otlp_protocol").MustString(otlpProtocolGRPC)),
+		metricsEnabled:  section.Key("otlp_metrics_enabled").MustBool(false),
+		metricsInterval: section.Key("otlp_metrics_interval").MustDuration(time.Minute),
+		logsEnabled:     section.Key("otlp_logs_enabled").MustBool(false),
+		logsLevel:       strings.ToLower(section.Key("otlp_logs_level").MustString("info")),
+		logsInterval:    section.Key("otlp_logs_interval").MustDuration(5 * time.Second),
+	}
+
+	if !ots.export.metricsEnabled && !ots.export.logsEnabled {
+		return nil
+	}
+	if ots.export.address == "" {
+		ots.export.address = ots.Cfg.Raw.Section("tracing.opentelemetry.otlp").Key("address").MustString("")
+	}
+	if ots.export.address == "" {
+		return errors.New("the export of metrics and logs with OTLP needs an address, set [tracing.opentelemetry] otlp_address")
+	}
+	if ots.export.protocol != otlpProtocolGRPC && ots.export.protocol != otlpProtocolHTTP {
+		return fmt.Errorf("unsupported OTLP protocol %q, expected grpc or http", ots.export.protocol)
+	}
+	if ots.export.metricsInterval <= 0 || ots.export.logsInterval <= 0 {
+		return errors.New("the intervals of the export of metrics and logs with OTLP must be positive")
+	}
+	return nil
+}
+
+// initOTLPExport creates the exporters of the metrics and the logs. The logs exporter receives the
+// lines of all the loggers from now on, and both send to the collector once Run is called.
+func (ots *Opentelemetry) initOTLPExport() error {
+	if !ots.export.metricsEnabled && !ots.export.logsEnabled {
+		return nil
+	}
+
+	client, err := newOTLPClient(ots.export.protocol, ots.export.address)
+	if err != nil {
+		return err
+	}
+	ots.exportClient = client
+
+	resource := &otlpResource{customAttribs: ots.customAttribs}
+
+	if ots.export.metricsEnabled {
+		ots.metricsExporter = &metricsExporter{
+			client:   client,
+			gatherer: prometheus.DefaultGatherer,
+			resource: resource,
+			start:    time.Now(),
+		}
+	}
+
+	if ots.export.logsEnabled {
+		ots.logsExporter = &logsExporter{
+			client:   client,
+			resource: resource,
+			maxLines: maxBufferedLogLines,
+		}
+		if err := log.AddLogger(ots.logsExporter, ots.export.logsLevel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runOTLPExport sends the metrics and the logs at their intervals until the context is done, and
+// then sends them a last time.
+func (ots *Opentelemetry) runOTLPExport(ctx context.Context) {
+	if ots.exportClient == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	if ots.metricsExporter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ots.exportPeriodically(ctx, ots.export.metricsInterval, "metrics", ots.metricsExporter.export)
+		}()
+	}
+	if ots.logsExporter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ots.exportPeriodically(ctx, ots.export.logsInterval, "logs", ots.logsExporter.export)
+		}()
+	}
+	wg.Wait()
+
+	if err := ots.exportClient.close(); err != nil {
+		ots.log.Warn("Failed to close the OTLP export client", "error", err)
+	}
+}
+
+func (ots *Opentelemetry) exportPeriodically(ctx context.Context, interval time.Duration, kind string, export func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := export(ctx); err != nil && ctx.Err() == nil {
+				ots.log.Warn("Failed to export with OTLP", "kind", kind, "error", err)
+			}
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := export(shutdownCtx); err != nil {
+				ots.log.Warn("Failed to export with OTLP on shutdown", "kind", kind, "error", err)
+			}
+			return
+		}
+	}
+}
+
+// otlpResource describes the Grafana server in the resource of the exported metrics and logs.
+type otlpResource struct {
+	customAttribs []attribute.KeyValue
+	mutex         sync.Mutex
+	orgCount      int64
+	hasOrgCount   bool
+}
+
+// setOrgCount records the number of orgs, read from the usage stats metrics.
+func (r *otlpResource) setOrgCount(count int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.orgCount = count
+	r.hasOrgCount = true
+}
+
+func (r *otlpResource) proto() *resourcepb.Resource {
+	attrs := []*commonpb.KeyValue{
+		stringKeyValue("service.name", "grafana"),
+		stringKeyValue("service.version", setting.BuildVersion),
+		stringKeyValue("service.instance.id", setting.InstanceName),
+	}
+
+	r.mutex.Lock()
+	if r.hasOrgCount {
+		attrs = append(attrs, &commonpb.KeyValue{Key: "grafana.org.count", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: r.orgCount}}})
+	}
+	r.mutex.Unlock()
+
+	for _, attr := range r.customAttribs {
+		attrs = append(attrs, stringKeyValue(string(attr.Key), attr.Value.Emit()))
+	}
+	return &resourcepb.Resource{Attributes: attrs}
+}
+
+func stringKeyValue(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+// otlpClient sends metrics and logs to an OTLP endpoint.
+type otlpClient interface {
+	exportMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error
+	exportLogs(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error
+	close() error
+}
+
+func newOTLPClient(protocol, address string) (otlpClient, error) {
+	if protocol == otlpProtocolHTTP {
+		if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+			address = "http://" + address
+		}
+		return &otlpHTTPClient{url: strings.TrimSuffix(address, "/"), client: &http.Client{Timeout: 30 * time.Second}}, nil
+	}
+
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP gRPC client: %w", err)
+	}
+	return &otlpGRPCClient{
+		conn:    conn,
+		metrics: colmetricspb.NewMetricsServiceClient(conn),
+		logs:    collogspb.NewLogsServiceClient(conn),
+	}, nil
+}
+
+type otlpGRPCClient struct {
+	conn    *grpc.ClientConn
+	metrics colmetricspb.MetricsServiceClient
+	logs    collogspb.LogsServiceClient
+}
+
+func (c *otlpGRPCClient) exportMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error {
+	_, err := c.metrics.Export(ctx, req)
+	return err
+}
+
+func (c *otlpGRPCClient) exportLogs(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error {
+	_, err := c.logs.Export(ctx, req)
+	return err
+}
+
+func (c *otlpGRPCClient) close() error {
+	return c.conn.Close()
+}
+
+// otlpHTTPClient sends the requests in protobuf to the /v1/metrics and /v1/logs paths of the endpoint.
+type otlpHTTPClient struct {
+	url    string
+	client *http.Client
+}
+
+func (c *otlpHTTPClient) exportMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error {
+	return c.post(ctx, "/v1/metrics", req)
+}
+
+func (c *otlpHTTPClient) exportLogs(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error {
+	return c.post(ctx, "/v1/logs", req)
+}
+
+func (c *otlpHTTPClient) post(ctx context.Context, path string, msg proto.Message) error {
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("OTLP endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (c *otlpHTTPClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
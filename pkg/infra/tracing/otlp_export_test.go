@@ -0,0 +1,203 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestOpentelemetry_ParseSettingsOTLPExport(t *testing.T) {
+	cfg := setting.NewCfg()
+	otel := &Opentelemetry{Cfg: cfg}
+
+	otelsect := cfg.Raw.Section("tracing.opentelemetry")
+	otlpsect := cfg.Raw.Section("tracing.opentelemetry.otlp")
+
+	require.NoError(t, otel.parseSettingsOTLPExport())
+	assert.False(t, otel.export.metricsEnabled)
+	assert.False(t, otel.export.logsEnabled)
+
+	otelsect.Key("otlp_metrics_enabled").SetValue("true")
+	require.Error(t, otel.parseSettingsOTLPExport(), "an address is required")
+
+	otlpsect.Key("address").SetValue("somehost:4317")
+	require.NoError(t, otel.parseSettingsOTLPExport())
+	assert.Equal(t, "somehost:4317", otel.export.address)
+	assert.Equal(t, otlpProtocolGRPC, otel.export.protocol)
+	assert.Equal(t, time.Minute, otel.export.metricsInterval)
+
+	otelsect.Key("otlp_address").SetValue("http://collector:4318")
+	otelsect.Key("otlp_protocol").SetValue("HTTP")
+	otelsect.Key("otlp_logs_enabled").SetValue("true")
+	otelsect.Key("otlp_logs_level").SetValue("warn")
+	require.NoError(t, otel.parseSettingsOTLPExport())
+	assert.Equal(t, "http://collector:4318", otel.export.address)
+	assert.Equal(t, otlpProtocolHTTP, otel.export.protocol)
+	assert.True(t, otel.export.logsEnabled)
+	assert.Equal(t, "warn", otel.export.logsLevel)
+
+	otelsect.Key("otlp_protocol").SetValue("thrift")
+	require.Error(t, otel.parseSettingsOTLPExport())
+}
+
+func TestConvertMetricFamily(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "requests"}, []string{"status"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "active"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "duration_seconds", Buckets: []float64{1, 5}})
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{Name: "size_bytes", Objectives: map[float64]float64{0.5: 0.05}})
+	registry.MustRegister(counter, gauge, histogram, summary)
+
+	counter.WithLabelValues("200").Add(3)
+	gauge.Set(7)
+	for _, v := range []float64{0.5, 2, 3, 10} {
+		histogram.Observe(v)
+	}
+	summary.Observe(4)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	start := time.Unix(100, 0)
+	now := time.Unix(200, 0)
+	metrics := map[string]*metricspb.Metric{}
+	for _, family := range families {
+		metric := convertMetricFamily(family, start, now)
+		require.NotNil(t, metric)
+		metrics[metric.Name] = metric
+	}
+
+	sum := metrics["requests_total"].GetSum()
+	require.NotNil(t, sum)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, sum.AggregationTemporality)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, 3.0, sum.DataPoints[0].GetAsDouble())
+	assert.Equal(t, uint64(start.UnixNano()), sum.DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, []*commonpb.KeyValue{stringKeyValue("status", "200")}, sum.DataPoints[0].Attributes)
+	assert.Equal(t, "requests", metrics["requests_total"].Description)
+
+	assert.Equal(t, 7.0, metrics["active"].GetGauge().DataPoints[0].GetAsDouble())
+
+	hist := metrics["duration_seconds"].GetHistogram().DataPoints[0]
+	assert.Equal(t, uint64(4), hist.Count)
+	assert.Equal(t, 15.5, hist.GetSum())
+	assert.Equal(t, []float64{1, 5}, hist.ExplicitBounds)
+	assert.Equal(t, []uint64{1, 2, 1}, hist.BucketCounts)
+
+	sumPoint := metrics["size_bytes"].GetSummary().DataPoints[0]
+	assert.Equal(t, uint64(1), sumPoint.Count)
+	assert.Equal(t, 4.0, sumPoint.Sum)
+	require.Len(t, sumPoint.QuantileValues, 1)
+	assert.Equal(t, 0.5, sumPoint.QuantileValues[0].Quantile)
+}
+
+func TestConvertLogLine(t *testing.T) {
+	now := time.Unix(100, 0)
+	record := convertLogLine(now, []interface{}{
+		"logger", "context",
+		"traceID", "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanID", "00f067aa0ba902b7",
+		"userId", int64(1),
+		"t", "2022-09-01T00:00:00Z",
+		"level", "error",
+		"msg", "Request failed",
+		"error", errors.New("boom"),
+	})
+
+	assert.Equal(t, uint64(now.UnixNano()), record.TimeUnixNano)
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, record.SeverityNumber)
+	assert.Equal(t, "error", record.SeverityText)
+	assert.Equal(t, "Request failed", record.Body.GetStringValue())
+	assert.Len(t, record.TraceId, 16)
+	assert.Len(t, record.SpanId, 8)
+
+	attrs := map[string]*commonpb.AnyValue{}
+	for _, attr := range record.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	assert.Len(t, attrs, 5)
+	assert.Equal(t, "context", attrs["logger"].GetStringValue())
+	assert.Equal(t, int64(1), attrs["userId"].GetIntValue())
+	assert.Equal(t, "boom", attrs["error"].GetStringValue())
+}
+
+func TestOTLPHTTPExport(t *testing.T) {
+	requests := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests[r.URL.Path] = body
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := newOTLPClient(otlpProtocolHTTP, server.URL)
+	require.NoError(t, err)
+	resource := &otlpResource{}
+
+	t.Run("should send the metrics with the org count in the resource", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		orgs := prometheus.NewGauge(prometheus.GaugeOpts{Name: orgCountMetric})
+		registry.MustRegister(orgs)
+		orgs.Set(3)
+
+		exporter := &metricsExporter{client: client, gatherer: registry, resource: resource, start: time.Now()}
+		require.NoError(t, exporter.export(context.Background()))
+
+		req := &colmetricspb.ExportMetricsServiceRequest{}
+		require.NoError(t, proto.Unmarshal(requests["/v1/metrics"], req))
+		require.Len(t, req.ResourceMetrics, 1)
+		assert.Contains(t, req.ResourceMetrics[0].Resource.Attributes, stringKeyValue("service.name", "grafana"))
+		assert.Contains(t, req.ResourceMetrics[0].Resource.Attributes, &commonpb.KeyValue{Key: "grafana.org.count", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 3}}})
+		assert.Equal(t, orgCountMetric, req.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Name)
+	})
+
+	t.Run("should send the buffered logs and report the dropped lines", func(t *testing.T) {
+		exporter := &logsExporter{client: client, resource: resource, maxLines: 2}
+		for i := 0; i < 3; i++ {
+			require.NoError(t, exporter.Log("level", "info", "msg", "hello"))
+		}
+		require.NoError(t, exporter.export(context.Background()))
+
+		req := &collogspb.ExportLogsServiceRequest{}
+		require.NoError(t, proto.Unmarshal(requests["/v1/logs"], req))
+		records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+		require.Len(t, records, 3)
+		assert.Equal(t, "hello", records[0].Body.GetStringValue())
+		assert.Contains(t, records[2].Body.GetStringValue(), "Dropped 1 log lines")
+
+		delete(requests, "/v1/logs")
+		require.NoError(t, exporter.export(context.Background()))
+		assert.NotContains(t, requests, "/v1/logs", "nothing to send")
+	})
+
+	t.Run("should fail on an error status", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(failing.Close)
+
+		client, err := newOTLPClient(otlpProtocolHTTP, failing.URL)
+		require.NoError(t, err)
+		exporter := &logsExporter{client: client, resource: resource, maxLines: 2}
+		require.NoError(t, exporter.Log("msg", "hello"))
+		require.ErrorContains(t, exporter.export(context.Background()), "status 503")
+	})
+}
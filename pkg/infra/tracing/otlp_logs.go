@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// maxBufferedLogLines is the number of lines kept between two exports, the lines above are dropped.
+const maxBufferedLogLines = 10000
+
+// logsExporter is a logger that buffers the lines of the Grafana loggers, and sends them with OTLP
+// when exported. It never blocks the loggers: the lines are dropped when the buffer is full.
+type logsExporter struct {
+	client   otlpClient
+	resource *otlpResource
+	maxLines int
+
+	mutex   sync.Mutex
+	records []*logspb.LogRecord
+	dropped int
+}
+
+func (e *logsExporter) Log(keyvals ...interface{}) error {
+	record := convertLogLine(time.Now(), keyvals)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.records) >= e.maxLines {
+		e.dropped++
+		return nil
+	}
+	e.records = append(e.records, record)
+	return nil
+}
+
+func (e *logsExporter) export(ctx context.Context) error {
+	e.mutex.Lock()
+	records, dropped := e.records, e.dropped
+	e.records, e.dropped = nil, 0
+	e.mutex.Unlock()
+
+	if dropped > 0 {
+		records = append(records, &logspb.LogRecord{
+			TimeUnixNano:   uint64(time.Now().UnixNano()),
+			SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+			SeverityText:   "warn",
+			Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprintf("Dropped %d log lines, the OTLP export is too slow", dropped)}},
+			Attributes:     []*commonpb.KeyValue{stringKeyValue("logger", "tracing")},
+		})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	return e.client.exportLogs(ctx, &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: e.resource.proto(),
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope:      &commonpb.InstrumentationScope{Name: "grafana"},
+				LogRecords: records,
+			}},
+		}},
+	})
+}
+
+// convertLogLine converts the key/value pairs of a log line to an OTLP log record. The message is
+// the body, the level is the severity, the trace and span IDs of the request link the record to the
+// trace, and the other pairs are attributes. The time of the line is when it was logged.
+func convertLogLine(t time.Time, keyvals []interface{}) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		TimeUnixNano: uint64(t.UnixNano()),
+		Attributes:   make([]*commonpb.KeyValue, 0, len(keyvals)/2),
+	}
+
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		value := keyvals[i+1]
+
+		switch key {
+		case "t":
+			// the time of the record
+		case level.Key():
+			record.SeverityText = fmt.Sprint(value)
+			record.SeverityNumber = severityNumber(record.SeverityText)
+		case "msg":
+			record.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(value)}}
+		case "traceID":
+			if id, err := hex.DecodeString(fmt.Sprint(value)); err == nil && len(id) == 16 {
+				record.TraceId = id
+			}
+			record.Attributes = append(record.Attributes, attributeKeyValue(key, value))
+		case "spanID":
+			if id, err := hex.DecodeString(fmt.Sprint(value)); err == nil && len(id) == 8 {
+				record.SpanId = id
+			}
+			record.Attributes = append(record.Attributes, attributeKeyValue(key, value))
+		default:
+			record.Attributes = append(record.Attributes, attributeKeyValue(key, value))
+		}
+	}
+
+	return record
+}
+
+func severityNumber(levelName string) logspb.SeverityNumber {
+	switch levelName {
+	case "debug":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case "info":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case "warn":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case "error":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+	}
+}
+
+func attributeKeyValue(key string, value interface{}) *commonpb.KeyValue {
+	var v *commonpb.AnyValue
+	switch val := value.(type) {
+	case string:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: val}}
+	case bool:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: val}}
+	case int:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(val)}}
+	case int64:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: val}}
+	case float64:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: val}}
+	case error:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: val.Error()}}
+	default:
+		v = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(val)}}
+	}
+	return &commonpb.KeyValue{Key: key, Value: v}
+}
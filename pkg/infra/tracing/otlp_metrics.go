@@ -0,0 +1,169 @@
+package tracing
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+// metricsExporter sends the metrics of a Prometheus registry with OTLP, as cumulative values since
+// Grafana started, so that they don't have to be scraped.
+type metricsExporter struct {
+	client   otlpClient
+	gatherer prometheus.Gatherer
+	resource *otlpResource
+	start    time.Time
+}
+
+func (e *metricsExporter) export(ctx context.Context) error {
+	families, err := e.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return err
+	}
+	// Gather returns the metrics it could collect along with the errors, which are not worth
+	// dropping all the metrics for.
+
+	now := time.Now()
+	metrics := make([]*metricspb.Metric, 0, len(families))
+	for _, family := range families {
+		if family.GetName() == orgCountMetric && len(family.GetMetric()) == 1 {
+			e.resource.setOrgCount(int64(family.GetMetric()[0].GetGauge().GetValue()))
+		}
+		if metric := convertMetricFamily(family, e.start, now); metric != nil {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return e.client.exportMetrics(ctx, &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: e.resource.proto(),
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: "grafana"},
+				Metrics: metrics,
+			}},
+		}},
+	})
+}
+
+// convertMetricFamily converts a Prometheus metric family to an OTLP metric: counters to monotonic
+// sums, gauges and untyped metrics to gauges, and histograms and summaries to their OTLP equivalent.
+func convertMetricFamily(family *dto.MetricFamily, start, now time.Time) *metricspb.Metric {
+	if len(family.GetMetric()) == 0 {
+		return nil
+	}
+
+	startNano := uint64(start.UnixNano())
+	nowNano := uint64(now.UnixNano())
+
+	metric := &metricspb.Metric{
+		Name:        family.GetName(),
+		Description: family.GetHelp(),
+	}
+
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		points := make([]*metricspb.NumberDataPoint, 0, len(family.GetMetric()))
+		for _, m := range family.GetMetric() {
+			points = append(points, numberDataPoint(m, m.GetCounter().GetValue(), startNano, nowNano))
+		}
+		metric.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			DataPoints:             points,
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}}
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		points := make([]*metricspb.NumberDataPoint, 0, len(family.GetMetric()))
+		for _, m := range family.GetMetric() {
+			value := m.GetGauge().GetValue()
+			if family.GetType() == dto.MetricType_UNTYPED {
+				value = m.GetUntyped().GetValue()
+			}
+			points = append(points, numberDataPoint(m, value, 0, nowNano))
+		}
+		metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}
+	case dto.MetricType_HISTOGRAM:
+		points := make([]*metricspb.HistogramDataPoint, 0, len(family.GetMetric()))
+		for _, m := range family.GetMetric() {
+			points = append(points, histogramDataPoint(m, startNano, nowNano))
+		}
+		metric.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			DataPoints:             points,
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}}
+	case dto.MetricType_SUMMARY:
+		points := make([]*metricspb.SummaryDataPoint, 0, len(family.GetMetric()))
+		for _, m := range family.GetMetric() {
+			summary := m.GetSummary()
+			point := &metricspb.SummaryDataPoint{
+				Attributes:        labelAttributes(m),
+				StartTimeUnixNano: startNano,
+				TimeUnixNano:      nowNano,
+				Count:             summary.GetSampleCount(),
+				Sum:               summary.GetSampleSum(),
+			}
+			for _, q := range summary.GetQuantile() {
+				point.QuantileValues = append(point.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+					Quantile: q.GetQuantile(),
+					Value:    q.GetValue(),
+				})
+			}
+			points = append(points, point)
+		}
+		metric.Data = &metricspb.Metric_Summary{Summary: &metricspb.Summary{DataPoints: points}}
+	default:
+		return nil
+	}
+
+	return metric
+}
+
+func numberDataPoint(m *dto.Metric, value float64, startNano, nowNano uint64) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{
+		Attributes:        labelAttributes(m),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      nowNano,
+		Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+	}
+}
+
+// histogramDataPoint converts the cumulative buckets of Prometheus to the bucket counts of OTLP,
+// where the last bucket counts the observations above the last bound.
+func histogramDataPoint(m *dto.Metric, startNano, nowNano uint64) *metricspb.HistogramDataPoint {
+	histogram := m.GetHistogram()
+	sum := histogram.GetSampleSum()
+
+	point := &metricspb.HistogramDataPoint{
+		Attributes:        labelAttributes(m),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      nowNano,
+		Count:             histogram.GetSampleCount(),
+		Sum:               &sum,
+	}
+
+	var previous uint64
+	for _, bucket := range histogram.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			continue
+		}
+		point.ExplicitBounds = append(point.ExplicitBounds, bucket.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, bucket.GetCumulativeCount()-previous)
+		previous = bucket.GetCumulativeCount()
+	}
+	point.BucketCounts = append(point.BucketCounts, histogram.GetSampleCount()-previous)
+
+	return point
+}
+
+func labelAttributes(m *dto.Metric) []*commonpb.KeyValue {
+	attrs := make([]*commonpb.KeyValue, 0, len(m.GetLabel()))
+	for _, label := range m.GetLabel() {
+		attrs = append(attrs, stringKeyValue(label.GetName(), label.GetValue()))
+	}
+	return attrs
+}
@@ -34,8 +34,8 @@ func ProvideService(cfg *setting.Cfg) (Tracer, error) {
 	}
 
 	log.RegisterContextualLogProvider(func(ctx context.Context) ([]interface{}, bool) {
-		if traceID := TraceIDFromContext(ctx, false); traceID != "" {
-			return []interface{}{"traceID", traceID}, true
+		if trace, ok := ctx.Value(traceKey{}).(traceValue); ok && trace.ID != "" {
+			return []interface{}{"traceID", trace.ID, "spanID", trace.SpanID}, true
 		}
 
 		return nil, false
@@ -45,7 +45,10 @@ func ProvideService(cfg *setting.Cfg) (Tracer, error) {
 		return ts, ts.initJaegerGlobalTracer()
 	}
 
-	return ots, ots.initOpentelemetryTracer()
+	if err := ots.initOpentelemetryTracer(); err != nil {
+		return ots, err
+	}
+	return ots, ots.initOTLPExport()
 }
 
 func parseSettings(cfg *setting.Cfg) (*Opentracing, *Opentelemetry, error) {
@@ -74,6 +77,7 @@ type traceKey struct{}
 type traceValue struct {
 	ID        string
 	IsSampled bool
+	SpanID    string
 }
 
 func TraceIDFromContext(c context.Context, requireSampled bool) string {
@@ -206,7 +210,7 @@ func (ts *Opentracing) Start(ctx context.Context, spanName string, opts ...trace
 	span, ctx := opentracing.StartSpanFromContext(ctx, spanName)
 	opentracingSpan := OpentracingSpan{span: span}
 	if sctx, ok := span.Context().(jaeger.SpanContext); ok {
-		ctx = context.WithValue(ctx, traceKey{}, traceValue{sctx.TraceID().String(), sctx.IsSampled()})
+		ctx = context.WithValue(ctx, traceKey{}, traceValue{sctx.TraceID().String(), sctx.IsSampled(), sctx.SpanID().String()})
 	}
 	return ctx, opentracingSpan
 }
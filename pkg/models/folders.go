@@ -13,6 +13,8 @@ type Folder struct {
 	Title   string
 	Url     string
 	Version int
+	// ParentUid is the UID of the parent folder, empty for folders at the root level
+	ParentUid string
 
 	Created time.Time
 	Updated time.Time
@@ -73,8 +75,16 @@ func (cmd *UpdateFolderCommand) UpdateDashboardModel(dashFolder *Dashboard, orgI
 //
 
 type CreateFolderCommand struct {
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Uid       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUid string `json:"parentUid"`
+
+	Result *Folder `json:"-"`
+}
+
+// MoveFolderCommand moves a folder under another folder, or to the root level if ParentUid is empty.
+type MoveFolderCommand struct {
+	ParentUid string `json:"parentUid"`
 
 	Result *Folder `json:"-"`
 }
@@ -36,8 +36,10 @@ type TokenExpiredError struct {
 func (e *TokenExpiredError) Error() string { return "user token expired" }
 
 type TokenRevokedError struct {
-	UserID                int64
-	TokenID               int64
+	UserID  int64
+	TokenID int64
+	// MaxConcurrentSessions is only set when the token was revoked because the
+	// user reached the maximum number of concurrent sessions.
 	MaxConcurrentSessions int64
 }
 
@@ -57,7 +59,11 @@ type UserToken struct {
 	CreatedAt     int64
 	UpdatedAt     int64
 	RevokedAt     int64
+	RevokeReason  string
 	UnhashedToken string
+
+	MaxInactiveLifetime int64
+	MaxLifetime         int64
 }
 
 type RevokeAuthTokenCmd struct {
@@ -69,11 +75,13 @@ type UserTokenService interface {
 	CreateToken(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*UserToken, error)
 	LookupToken(ctx context.Context, unhashedToken string) (*UserToken, error)
 	TryRotateToken(ctx context.Context, token *UserToken, clientIP net.IP, userAgent string) (bool, error)
+	CheckTokenDevice(ctx context.Context, token *UserToken, userAgent string) error
 	RevokeToken(ctx context.Context, token *UserToken, soft bool) error
 	RevokeAllUserTokens(ctx context.Context, userId int64) error
 	GetUserToken(ctx context.Context, userId, userTokenId int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userId int64) ([]*UserToken, error)
 	GetUserRevokedTokens(ctx context.Context, userId int64) ([]*UserToken, error)
+	GetOrgUserTokens(ctx context.Context, orgId int64) ([]*UserToken, error)
 }
 
 type ActiveTokenService interface {
@@ -23,3 +23,9 @@ type Plugin interface {
 	backend.CallResourceHandler
 	backend.StreamHandler
 }
+
+// ProcessInfo is implemented by plugins that run in their own process.
+type ProcessInfo interface {
+	// Pid returns the ID of the plugin process, and false if the process is not running.
+	Pid() (int, bool)
+}
@@ -19,6 +19,14 @@ type Cfg struct {
 	PluginSettings       setting.PluginSettings
 	PluginsAllowUnsigned []string
 
+	// PluginsRepositoryMirror is the directory or URL of a plugin repository mirror used instead of grafana.com
+	PluginsRepositoryMirror string
+
+	// PluginLimits are the resource limits of backend plugins, by plugin ID
+	PluginLimits map[string]PluginLimits
+	// PluginsMemoryLimitCgroup is the cgroup v2 directory under which memory limited plugins are placed
+	PluginsMemoryLimitCgroup string
+
 	EnterpriseLicensePath string
 
 	// AWS Plugin Auth
@@ -52,16 +60,21 @@ func NewCfg(settingProvider setting.Provider, grafanaCfg *setting.Cfg) *Cfg {
 		allowedUnsigned = strings.Split(settingProvider.KeyValue("plugins", "allow_loading_unsigned_plugins").Value(), ",")
 	}
 
+	pluginSettings := extractPluginSettings(settingProvider)
+
 	return &Cfg{
-		log:                     logger,
-		PluginsPath:             grafanaCfg.PluginsPath,
-		BuildVersion:            grafanaCfg.BuildVersion,
-		DevMode:                 settingProvider.KeyValue("", "app_mode").MustBool(grafanaCfg.Env == setting.Dev),
-		EnterpriseLicensePath:   settingProvider.KeyValue("enterprise", "license_path").MustString(grafanaCfg.EnterpriseLicensePath),
-		PluginSettings:          extractPluginSettings(settingProvider),
-		PluginsAllowUnsigned:    allowedUnsigned,
-		AWSAllowedAuthProviders: allowedAuth,
-		AWSAssumeRoleEnabled:    aws.KeyValue("assume_role_enabled").MustBool(grafanaCfg.AWSAssumeRoleEnabled),
+		log:                      logger,
+		PluginsPath:              grafanaCfg.PluginsPath,
+		BuildVersion:             grafanaCfg.BuildVersion,
+		DevMode:                  settingProvider.KeyValue("", "app_mode").MustBool(grafanaCfg.Env == setting.Dev),
+		EnterpriseLicensePath:    settingProvider.KeyValue("enterprise", "license_path").MustString(grafanaCfg.EnterpriseLicensePath),
+		PluginSettings:           pluginSettings,
+		PluginLimits:             extractPluginLimits(pluginSettings, logger),
+		PluginsAllowUnsigned:     allowedUnsigned,
+		PluginsRepositoryMirror:  plugins.KeyValue("repository_mirror").Value(),
+		PluginsMemoryLimitCgroup: plugins.KeyValue("memory_limit_cgroup").Value(),
+		AWSAllowedAuthProviders:  allowedAuth,
+		AWSAssumeRoleEnabled:     aws.KeyValue("assume_role_enabled").MustBool(grafanaCfg.AWSAssumeRoleEnabled),
 		Azure: &azsettings.AzureSettings{
 			Cloud:                   azure.KeyValue("cloud").MustString(grafanaCfg.Azure.Cloud),
 			ManagedIdentityEnabled:  azure.KeyValue("managed_identity_enabled").MustBool(grafanaCfg.Azure.ManagedIdentityEnabled),
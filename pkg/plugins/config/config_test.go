@@ -2,9 +2,11 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"gopkg.in/ini.v1"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/stretchr/testify/require"
@@ -36,3 +38,33 @@ func TestPluginSettings(t *testing.T) {
 	require.Equal(t, ps["secret-plugin"]["secret_key"], "secret")
 	require.Equal(t, ps["secret-plugin"]["normal_key"], "not a secret")
 }
+
+func TestPluginLimits(t *testing.T) {
+	ps := setting.PluginSettings{
+		"test-datasource": {
+			"query_data_timeout":    "30s",
+			"call_resource_timeout": "1m",
+			"memory_limit_mb":       "512",
+		},
+		"invalid-datasource": {
+			"query_data_timeout": "soon",
+			"memory_limit_mb":    "-1",
+		},
+		"unlimited-datasource": {
+			"foo": "bar",
+		},
+	}
+
+	limits := extractPluginLimits(ps, log.NewNopLogger())
+	require.Equal(t, map[string]PluginLimits{
+		"test-datasource": {
+			QueryDataTimeout:    30 * time.Second,
+			CallResourceTimeout: time.Minute,
+			MemoryLimit:         512 * 1024 * 1024,
+		},
+	}, limits)
+
+	cfg := &Cfg{PluginLimits: limits}
+	require.Equal(t, 30*time.Second, cfg.Limits("test-datasource").QueryDataTimeout)
+	require.Equal(t, PluginLimits{}, cfg.Limits("unlimited-datasource"))
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Settings of the [plugin.<id>] sections that limit the resources of a backend plugin. They are
+// consumed by Grafana and are not passed to the plugin.
+const (
+	QueryDataTimeoutSetting    = "query_data_timeout"
+	CallResourceTimeoutSetting = "call_resource_timeout"
+	MemoryLimitSetting         = "memory_limit_mb"
+)
+
+// PluginLimits are the resource limits of a backend plugin. A zero value means no limit.
+type PluginLimits struct {
+	QueryDataTimeout    time.Duration
+	CallResourceTimeout time.Duration
+	// MemoryLimit is the maximum memory of the plugin process in bytes.
+	MemoryLimit uint64
+}
+
+// IsLimitSetting returns whether the key of a [plugin.<id>] section is a resource limit.
+func IsLimitSetting(key string) bool {
+	switch key {
+	case QueryDataTimeoutSetting, CallResourceTimeoutSetting, MemoryLimitSetting:
+		return true
+	}
+	return false
+}
+
+// Limits returns the resource limits of the plugin with the given ID.
+func (cfg *Cfg) Limits(pluginID string) PluginLimits {
+	if cfg == nil {
+		return PluginLimits{}
+	}
+	return cfg.PluginLimits[pluginID]
+}
+
+func extractPluginLimits(ps setting.PluginSettings, logger log.Logger) map[string]PluginLimits {
+	limits := map[string]PluginLimits{}
+	for pluginID, settings := range ps {
+		var l PluginLimits
+		l.QueryDataTimeout = parseLimitDuration(settings, QueryDataTimeoutSetting, pluginID, logger)
+		l.CallResourceTimeout = parseLimitDuration(settings, CallResourceTimeoutSetting, pluginID, logger)
+		if v, exists := settings[MemoryLimitSetting]; exists && v != "" {
+			mb, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				logger.Warn("Invalid plugin memory limit, ignoring it", "pluginID", pluginID, "value", v, "err", err)
+			} else {
+				l.MemoryLimit = mb * 1024 * 1024
+			}
+		}
+		if l != (PluginLimits{}) {
+			limits[pluginID] = l
+		}
+	}
+	return limits
+}
+
+func parseLimitDuration(settings map[string]string, key, pluginID string, logger log.Logger) time.Duration {
+	v, exists := settings[key]
+	if !exists || v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		logger.Warn("Invalid plugin timeout, ignoring it", "pluginID", pluginID, "setting", key, "value", v, "err", err)
+		return 0
+	}
+	return d
+}
@@ -13,4 +13,6 @@ var (
 	ErrMethodNotImplemented = errutil.NewBase(errutil.StatusNotImplemented, "plugin.notImplemented")
 	// ErrPluginDownstreamError error returned when a plugin method is not implemented.
 	ErrPluginDownstreamError = errutil.NewBase(errutil.StatusInternal, "plugin.downstreamError", errutil.WithPublicMessage("An error occurred within the plugin"))
+	// ErrPluginRequestTimeout error returned when a plugin request exceeds the timeout configured for the plugin.
+	ErrPluginRequestTimeout = errutil.NewBase(errutil.StatusTimeout, "plugin.requestTimeout", errutil.WithPublicMessage("The plugin did not respond in time"))
 )
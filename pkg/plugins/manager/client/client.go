@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/instrumentation"
+	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/manager/registry"
 )
 
@@ -17,27 +20,38 @@ var _ plugins.Client = (*Service)(nil)
 
 type Service struct {
 	pluginRegistry registry.Service
+	cfg            *config.Cfg
 }
 
-func ProvideService(pluginRegistry registry.Service) *Service {
+func ProvideService(pluginRegistry registry.Service, cfg *config.Cfg) *Service {
 	return &Service{
 		pluginRegistry: pluginRegistry,
+		cfg:            cfg,
 	}
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	ctx = withLogAttributes(ctx, req.PluginContext)
 	plugin, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, plugins.ErrPluginNotRegistered.Errorf("%w", backendplugin.ErrPluginNotRegistered)
 	}
 
+	timeout := s.cfg.Limits(req.PluginContext.PluginID).QueryDataTimeout
+	reqCtx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
 	var resp *backend.QueryDataResponse
 	err := instrumentation.InstrumentQueryDataRequest(req.PluginContext.PluginID, func() (innerErr error) {
-		resp, innerErr = plugin.QueryData(ctx, req)
+		resp, innerErr = plugin.QueryData(reqCtx, req)
 		return
 	})
 
 	if err != nil {
+		if timedOut(ctx, reqCtx) {
+			return nil, plugins.ErrPluginRequestTimeout.Errorf("query data timed out after %s: %w", timeout, err)
+		}
+
 		if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
 			return nil, plugins.ErrMethodNotImplemented.Errorf("%w", backendplugin.ErrMethodNotImplemented)
 		}
@@ -62,18 +76,27 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 }
 
 func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	ctx = withLogAttributes(ctx, req.PluginContext)
 	p, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return backendplugin.ErrPluginNotRegistered
 	}
+
+	timeout := s.cfg.Limits(req.PluginContext.PluginID).CallResourceTimeout
+	reqCtx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
 	err := instrumentation.InstrumentCallResourceRequest(p.PluginID(), func() error {
-		if err := p.CallResource(ctx, req, sender); err != nil {
+		if err := p.CallResource(reqCtx, req, sender); err != nil {
 			return err
 		}
 		return nil
 	})
 
 	if err != nil {
+		if timedOut(ctx, reqCtx) {
+			return plugins.ErrPluginRequestTimeout.Errorf("call resource timed out after %s: %w", timeout, err)
+		}
 		return err
 	}
 
@@ -81,6 +104,7 @@ func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceReq
 }
 
 func (s *Service) CollectMetrics(ctx context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+	ctx = withLogAttributes(ctx, req.PluginContext)
 	p, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
@@ -99,6 +123,7 @@ func (s *Service) CollectMetrics(ctx context.Context, req *backend.CollectMetric
 }
 
 func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	ctx = withLogAttributes(ctx, req.PluginContext)
 	p, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
@@ -126,6 +151,7 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 }
 
 func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	ctx = withLogAttributes(ctx, req.PluginContext)
 	plugin, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
@@ -135,6 +161,7 @@ func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStr
 }
 
 func (s *Service) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	ctx = withLogAttributes(ctx, req.PluginContext)
 	plugin, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
@@ -144,6 +171,7 @@ func (s *Service) PublishStream(ctx context.Context, req *backend.PublishStreamR
 }
 
 func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	ctx = withLogAttributes(ctx, req.PluginContext)
 	plugin, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return backendplugin.ErrPluginNotRegistered
@@ -165,3 +193,27 @@ func (s *Service) plugin(ctx context.Context, pluginID string) (*plugins.Plugin,
 
 	return p, true
 }
+
+// withLogAttributes returns a context with the plugin and data source of the request, to log them
+// with the loggers of the context.
+func withLogAttributes(ctx context.Context, pCtx backend.PluginContext) context.Context {
+	attrs := []interface{}{"pluginId", pCtx.PluginID}
+	if pCtx.DataSourceInstanceSettings != nil {
+		attrs = append(attrs, "datasourceUid", pCtx.DataSourceInstanceSettings.UID)
+	}
+	return log.WithContextualAttributes(ctx, attrs)
+}
+
+// withTimeout returns a context that is cancelled after timeout, unless timeout is zero.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timedOut returns whether the request was cancelled by the timeout of the plugin, rather than by
+// the cancellation or the deadline of the parent context.
+func timedOut(parent, reqCtx context.Context) bool {
+	return parent.Err() == nil && errors.Is(reqCtx.Err(), context.DeadlineExceeded)
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/manager/fakes"
 	"github.com/stretchr/testify/require"
 )
@@ -16,7 +18,7 @@ import (
 func TestQueryData(t *testing.T) {
 	t.Run("Empty registry should return not registered error", func(t *testing.T) {
 		registry := fakes.NewFakePluginRegistry()
-		client := ProvideService(registry)
+		client := ProvideService(registry, &config.Cfg{})
 		_, err := client.QueryData(context.Background(), &backend.QueryDataRequest{})
 		require.Error(t, err)
 		require.ErrorIs(t, err, plugins.ErrPluginNotRegistered)
@@ -57,7 +59,7 @@ func TestQueryData(t *testing.T) {
 				err := registry.Add(context.Background(), p)
 				require.NoError(t, err)
 
-				client := ProvideService(registry)
+				client := ProvideService(registry, &config.Cfg{})
 				_, err = client.QueryData(context.Background(), &backend.QueryDataRequest{
 					PluginContext: backend.PluginContext{
 						PluginID: "grafana",
@@ -70,8 +72,59 @@ func TestQueryData(t *testing.T) {
 	})
 }
 
+func TestTimeouts(t *testing.T) {
+	registry := fakes.NewFakePluginRegistry()
+	p := &plugins.Plugin{
+		JSONData: plugins.JSONData{
+			ID: "slow-datasource",
+		},
+	}
+	wait := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	p.RegisterClient(&fakePluginBackend{
+		qdr: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			return nil, wait(ctx)
+		},
+		crr: func(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+			return wait(ctx)
+		},
+	})
+	require.NoError(t, registry.Add(context.Background(), p))
+
+	client := ProvideService(registry, &config.Cfg{
+		PluginLimits: map[string]config.PluginLimits{
+			"slow-datasource": {
+				QueryDataTimeout:    10 * time.Millisecond,
+				CallResourceTimeout: 10 * time.Millisecond,
+			},
+		},
+	})
+	pCtx := backend.PluginContext{PluginID: "slow-datasource"}
+
+	t.Run("Query data exceeding the timeout of the plugin should return timeout error", func(t *testing.T) {
+		_, err := client.QueryData(context.Background(), &backend.QueryDataRequest{PluginContext: pCtx})
+		require.ErrorIs(t, err, plugins.ErrPluginRequestTimeout)
+	})
+
+	t.Run("Call resource exceeding the timeout of the plugin should return timeout error", func(t *testing.T) {
+		err := client.CallResource(context.Background(), &backend.CallResourceRequest{PluginContext: pCtx}, nil)
+		require.ErrorIs(t, err, plugins.ErrPluginRequestTimeout)
+	})
+
+	t.Run("Cancelled request should not return timeout error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := client.QueryData(ctx, &backend.QueryDataRequest{PluginContext: pCtx})
+		require.ErrorIs(t, err, plugins.ErrPluginDownstreamError)
+		require.NotErrorIs(t, err, plugins.ErrPluginRequestTimeout)
+	})
+}
+
 type fakePluginBackend struct {
 	qdr backend.QueryDataHandlerFunc
+	crr backend.CallResourceHandlerFunc
 
 	backendplugin.Plugin
 }
@@ -83,6 +136,13 @@ func (f *fakePluginBackend) QueryData(ctx context.Context, req *backend.QueryDat
 	return backend.NewQueryDataResponse(), nil
 }
 
+func (f *fakePluginBackend) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	if f.crr != nil {
+		return f.crr(ctx, req, sender)
+	}
+	return nil
+}
+
 func (f *fakePluginBackend) IsDecommissioned() bool {
 	return false
 }
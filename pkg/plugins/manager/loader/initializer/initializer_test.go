@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/config"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestInitializer_Initialize(t *testing.T) {
@@ -194,7 +195,18 @@ func Test_getPluginLogoUrl(t *testing.T) {
 }
 
 func Test_getPluginSettings(t *testing.T) {
-
+	cfg := &config.Cfg{PluginSettings: setting.PluginSettings{
+		"test-datasource": {
+			"path":               "/var/lib/plugins",
+			"id":                 "test-datasource",
+			"foo":                "bar",
+			"query_data_timeout": "30s",
+			"memory_limit_mb":    "512",
+		},
+	}}
+
+	ps := getPluginSettings("test-datasource", cfg)
+	assert.Equal(t, pluginSettings{"foo": "bar"}, ps)
 }
 
 func Test_pluginSettings_ToEnv(t *testing.T) {
@@ -52,7 +52,7 @@ type Loader struct {
 
 func ProvideService(cfg *config.Cfg, license models.Licensing, authorizer plugins.PluginLoaderAuthorizer,
 	pluginRegistry registry.Service, backendProvider plugins.BackendFactoryProvider) *Loader {
-	return New(cfg, license, authorizer, pluginRegistry, backendProvider, process.NewManager(pluginRegistry),
+	return New(cfg, license, authorizer, pluginRegistry, backendProvider, process.NewManager(pluginRegistry, cfg),
 		storage.FileSystem(logger.NewLogger("loader.fs"), cfg.PluginsPath))
 }
 
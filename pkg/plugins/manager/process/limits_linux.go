@@ -0,0 +1,49 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// cgroupNameRegex matches the plugin IDs that can be used in the name of a cgroup, which must not contain path separators.
+var cgroupNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// limitMemory limits the memory of the plugin process with the given PID to limit bytes.
+//
+// The process is moved to a plugin-<id> cgroup v2 created under cgroupRoot, and the memory.max of
+// that cgroup is set to the limit, so the kernel kills the process when it uses more memory. The
+// memory of a plugin cannot be limited without a cgroup root.
+func limitMemory(cgroupRoot, pluginID string, pid int, limit uint64) error {
+	if cgroupRoot == "" {
+		return errors.New("memory limits require the memory_limit_cgroup setting")
+	}
+	if !cgroupNameRegex.MatchString(pluginID) {
+		return fmt.Errorf("plugin ID %q cannot be used as a cgroup name", pluginID)
+	}
+
+	dir := filepath.Join(cgroupRoot, "plugin-"+pluginID)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	if err := writeCgroupFile(dir, "memory.max", strconv.FormatUint(limit, 10)); err != nil {
+		return err
+	}
+	return writeCgroupFile(dir, "cgroup.procs", strconv.Itoa(pid))
+}
+
+func writeCgroupFile(dir, name, value string) error {
+	// We can ignore the gosec G304 warning since the cgroup root is set by the administrator
+	// and the plugin ID is validated.
+	// nolint:gosec
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0600); err != nil {
+		return fmt.Errorf("failed to write %s of cgroup: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitMemory(t *testing.T) {
+	t.Run("Requires a cgroup root", func(t *testing.T) {
+		require.Error(t, limitMemory("", "test-datasource", 1, 1024))
+	})
+
+	t.Run("Rejects plugin IDs that are not valid cgroup names", func(t *testing.T) {
+		root := t.TempDir()
+		for _, pluginID := range []string{"", "../test-datasource", "test/datasource", ".."} {
+			require.Error(t, limitMemory(root, pluginID, 1, 1024), pluginID)
+		}
+		entries, err := os.ReadDir(root)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("Moves the process to the cgroup of the plugin", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, limitMemory(root, "test-datasource", 42, 1024))
+
+		memoryMax, err := os.ReadFile(filepath.Join(root, "plugin-test-datasource", "memory.max"))
+		require.NoError(t, err)
+		require.Equal(t, "1024", string(memoryMax))
+		procs, err := os.ReadFile(filepath.Join(root, "plugin-test-datasource", "cgroup.procs"))
+		require.NoError(t, err)
+		require.Equal(t, "42", string(procs))
+	})
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import "errors"
+
+// limitMemory is not supported outside of Linux.
+func limitMemory(_, _ string, _ int, _ uint64) error {
+	return errors.New("memory limits are only supported on Linux")
+}
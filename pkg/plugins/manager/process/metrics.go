@@ -0,0 +1,20 @@
+package process
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	pluginRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "plugin_restarts_total",
+		Help:      "The total amount of restarts of backend plugin processes after they exited",
+	}, []string{"plugin_id"})
+
+	pluginCrashLoop = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "plugin_crash_loop",
+		Help:      "Whether the process of a backend plugin is crash looping (1) or not (0)",
+	}, []string{"plugin_id"})
+)
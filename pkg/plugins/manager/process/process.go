@@ -2,33 +2,62 @@ package process
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/manager/registry"
 )
 
 var _ Service = (*Manager)(nil)
 
+const (
+	defaultCheckInterval      = time.Second
+	defaultInitialBackoff     = time.Second
+	defaultMaxBackoff         = 5 * time.Minute
+	defaultStableAfter        = 10 * time.Minute
+	defaultCrashLoopWindow    = 10 * time.Minute
+	defaultCrashLoopThreshold = 5
+)
+
 type Manager struct {
 	pluginRegistry registry.Service
+	cfg            *config.Cfg
+
+	// checkInterval is how often the supervisor checks whether a plugin process exited.
+	checkInterval time.Duration
+	// initialBackoff is the delay before restarting a plugin process the first time it exits. The
+	// delay doubles every time the process exits again, up to maxBackoff, and is reset once the
+	// process has been running for stableAfter.
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	stableAfter    time.Duration
+	// A plugin process is in a crash loop when it exited crashLoopThreshold times within crashLoopWindow.
+	crashLoopWindow    time.Duration
+	crashLoopThreshold int
 
 	mu  sync.Mutex
 	log log.Logger
 }
 
-func ProvideService(pluginRegistry registry.Service) *Manager {
-	return NewManager(pluginRegistry)
+func ProvideService(cfg *config.Cfg, pluginRegistry registry.Service) *Manager {
+	return NewManager(pluginRegistry, cfg)
 }
 
-func NewManager(pluginRegistry registry.Service) *Manager {
+func NewManager(pluginRegistry registry.Service, cfg *config.Cfg) *Manager {
 	return &Manager{
-		pluginRegistry: pluginRegistry,
-		log:            log.New("plugin.process.manager"),
+		pluginRegistry:     pluginRegistry,
+		cfg:                cfg,
+		checkInterval:      defaultCheckInterval,
+		initialBackoff:     defaultInitialBackoff,
+		maxBackoff:         defaultMaxBackoff,
+		stableAfter:        defaultStableAfter,
+		crashLoopWindow:    defaultCrashLoopWindow,
+		crashLoopThreshold: defaultCrashLoopThreshold,
+		log:                log.New("plugin.process.manager"),
 	}
 }
 
@@ -51,7 +80,7 @@ func (m *Manager) Start(ctx context.Context, pluginID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if err := startPluginAndRestartKilledProcesses(ctx, p); err != nil {
+	if err := m.startPluginAndSuperviseProcess(ctx, p); err != nil {
 		return err
 	}
 
@@ -96,8 +125,8 @@ func (m *Manager) shutdown(ctx context.Context) {
 	wg.Wait()
 }
 
-func startPluginAndRestartKilledProcesses(ctx context.Context, p *plugins.Plugin) error {
-	if err := p.Start(ctx); err != nil {
+func (m *Manager) startPluginAndSuperviseProcess(ctx context.Context, p *plugins.Plugin) error {
+	if err := m.startProcess(ctx, p); err != nil {
 		return err
 	}
 
@@ -105,41 +134,143 @@ func startPluginAndRestartKilledProcesses(ctx context.Context, p *plugins.Plugin
 		return nil
 	}
 
-	go func(ctx context.Context, p *plugins.Plugin) {
-		if err := restartKilledProcess(ctx, p); err != nil {
-			p.Logger().Error("Attempt to restart killed plugin process failed", "error", err)
-		}
-	}(ctx, p)
+	p.SetProcessStatus(plugins.ProcessStatus{State: plugins.ProcessRunning})
+	pluginCrashLoop.WithLabelValues(p.ID).Set(0)
+	go m.supervise(ctx, p)
+
+	return nil
+}
+
+// startProcess starts the plugin process and applies the resource limits of the plugin to it.
+func (m *Manager) startProcess(ctx context.Context, p *plugins.Plugin) error {
+	if err := p.Start(ctx); err != nil {
+		return err
+	}
 
+	memoryLimit := m.cfg.Limits(p.ID).MemoryLimit
+	if memoryLimit == 0 || p.IsCorePlugin() {
+		return nil
+	}
+	pid, ok := p.Pid()
+	if !ok {
+		p.Logger().Warn("Cannot limit the memory of plugin, process ID is unknown")
+		return nil
+	}
+	if err := limitMemory(m.cfg.PluginsMemoryLimitCgroup, p.ID, pid, memoryLimit); err != nil {
+		p.Logger().Warn("Failed to limit the memory of plugin", "limit", memoryLimit, "error", err)
+	}
 	return nil
 }
 
-func restartKilledProcess(ctx context.Context, p *plugins.Plugin) error {
-	ticker := time.NewTicker(time.Second * 1)
+// supervise restarts the plugin process when it exits, waiting longer every time the process
+// exits again, until the plugin is decommissioned or the context is done.
+func (m *Manager) supervise(ctx context.Context, p *plugins.Plugin) {
+	ticker := time.NewTicker(m.checkInterval)
+	defer ticker.Stop()
+
+	var (
+		backoff     = m.initialBackoff
+		startedAt   = time.Now()
+		restarts    int
+		exits       []time.Time
+		nextRestart time.Time
+	)
+
+	setStatus := func(state plugins.ProcessState) {
+		status := plugins.ProcessStatus{State: state, Restarts: restarts}
+		if len(exits) > 0 {
+			lastExit := exits[len(exits)-1]
+			status.LastExit = &lastExit
+		}
+		if !nextRestart.IsZero() {
+			next := nextRestart
+			status.NextRestart = &next
+		}
+		p.SetProcessStatus(status)
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			if err := ctx.Err(); err != nil && !errors.Is(err, context.Canceled) {
-				return err
-			}
-			return nil
-		case <-ticker.C:
+			return
+		case now := <-ticker.C:
 			if p.IsDecommissioned() {
 				p.Logger().Debug("Plugin decommissioned")
-				return nil
+				pluginCrashLoop.WithLabelValues(p.ID).Set(0)
+				return
+			}
+
+			if nextRestart.IsZero() {
+				if !p.Exited() {
+					if now.Sub(startedAt) >= m.stableAfter && backoff != m.initialBackoff {
+						p.Logger().Debug("Plugin process is stable, resetting restart backoff")
+						backoff = m.initialBackoff
+					}
+					if len(exits) > 0 && now.Sub(exits[len(exits)-1]) >= m.crashLoopWindow {
+						exits = nil
+						pluginCrashLoop.WithLabelValues(p.ID).Set(0)
+						setStatus(plugins.ProcessRunning)
+					}
+					continue
+				}
+
+				exits = append(recentExits(exits, now, m.crashLoopWindow), now)
+				state := plugins.ProcessRestarting
+				if len(exits) >= m.crashLoopThreshold {
+					state = plugins.ProcessCrashLoop
+					backoff = m.maxBackoff
+					pluginCrashLoop.WithLabelValues(p.ID).Set(1)
+				}
+				nextRestart = now.Add(backoff)
+				setStatus(state)
+				if state == plugins.ProcessCrashLoop {
+					p.Logger().Error("Plugin process is crash looping", "exits", len(exits), "window", m.crashLoopWindow, "nextRestart", nextRestart)
+				} else {
+					p.Logger().Warn("Plugin process exited", "restartIn", backoff)
+				}
+				continue
 			}
 
-			if !p.Exited() {
+			if now.Before(nextRestart) {
 				continue
 			}
 
 			p.Logger().Debug("Restarting plugin")
-			if err := p.Start(ctx); err != nil {
-				p.Logger().Error("Failed to restart plugin", "error", err)
+			restarts++
+			pluginRestarts.WithLabelValues(p.ID).Inc()
+			backoff = nextBackoff(backoff, m.maxBackoff)
+			if err := m.startProcess(ctx, p); err != nil {
+				p.Logger().Error("Failed to restart plugin", "error", err, "restartIn", backoff)
+				nextRestart = now.Add(backoff)
+				setStatus(p.ProcessStatus().State)
 				continue
 			}
+			startedAt = now
+			nextRestart = time.Time{}
+			if len(exits) >= m.crashLoopThreshold {
+				setStatus(plugins.ProcessCrashLoop)
+			} else {
+				setStatus(plugins.ProcessRunning)
+			}
 			p.Logger().Debug("Plugin restarted")
 		}
 	}
 }
+
+// recentExits returns the exits that happened within the window before now.
+func recentExits(exits []time.Time, now time.Time, window time.Duration) []time.Time {
+	for i, exit := range exits {
+		if now.Sub(exit) < window {
+			return exits[i:]
+		}
+	}
+	return nil
+}
+
+func nextBackoff(backoff, max time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
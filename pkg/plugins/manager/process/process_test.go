@@ -4,16 +4,19 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
 func TestProcessManager_Start(t *testing.T) {
 	t.Run("Plugin not found in registry", func(t *testing.T) {
-		m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{}))
+		m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{}), &config.Cfg{})
 		err := m.Start(context.Background(), "non-existing-datasource")
 		require.ErrorIs(t, err, backendplugin.ErrPluginNotRegistered)
 	})
@@ -64,7 +67,7 @@ func TestProcessManager_Start(t *testing.T) {
 
 				m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{
 					p.ID: p,
-				}))
+				}), &config.Cfg{})
 
 				err := m.Start(context.Background(), p.ID)
 				require.NoError(t, err)
@@ -82,7 +85,7 @@ func TestProcessManager_Start(t *testing.T) {
 
 func TestProcessManager_Stop(t *testing.T) {
 	t.Run("Plugin not found in registry", func(t *testing.T) {
-		m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{}))
+		m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{}), &config.Cfg{})
 		err := m.Stop(context.Background(), "non-existing-datasource")
 		require.ErrorIs(t, err, backendplugin.ErrPluginNotRegistered)
 	})
@@ -98,7 +101,7 @@ func TestProcessManager_Stop(t *testing.T) {
 
 		m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{
 			pluginID: p,
-		}))
+		}), &config.Cfg{})
 		err := m.Stop(context.Background(), pluginID)
 		require.NoError(t, err)
 
@@ -117,7 +120,7 @@ func TestProcessManager_ManagedBackendPluginLifecycle(t *testing.T) {
 
 	m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{
 		p.ID: p,
-	}))
+	}), &config.Cfg{})
 
 	err := m.Start(context.Background(), p.ID)
 	require.NoError(t, err)
@@ -161,6 +164,90 @@ func TestProcessManager_ManagedBackendPluginLifecycle(t *testing.T) {
 	})
 }
 
+func TestProcessManager_Supervision(t *testing.T) {
+	bp := newFakeBackendPlugin(true)
+	p := createPlugin(t, bp, func(plugin *plugins.Plugin) {
+		plugin.ID = "crashing-datasource"
+		plugin.Backend = true
+	})
+
+	m := NewManager(newFakePluginRegistry(map[string]*plugins.Plugin{
+		p.ID: p,
+	}), &config.Cfg{})
+	m.checkInterval = 5 * time.Millisecond
+	m.initialBackoff = 20 * time.Millisecond
+	m.maxBackoff = time.Hour
+	m.crashLoopThreshold = 3
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, m.Start(ctx, p.ID))
+	require.Equal(t, plugins.ProcessRunning, p.ProcessStatus().State)
+
+	t.Run("Exited process is restarted after a backoff", func(t *testing.T) {
+		restarts := testutil.ToFloat64(pluginRestarts.WithLabelValues(p.ID))
+		bp.kill()
+		require.Eventually(t, func() bool {
+			return p.ProcessStatus().State == plugins.ProcessRestarting
+		}, time.Second, time.Millisecond)
+		status := p.ProcessStatus()
+		require.NotNil(t, status.LastExit)
+		require.NotNil(t, status.NextRestart)
+		require.Equal(t, 20*time.Millisecond, status.NextRestart.Sub(*status.LastExit))
+
+		require.Eventually(t, func() bool {
+			return p.ProcessStatus().State == plugins.ProcessRunning
+		}, time.Second, time.Millisecond)
+		require.Equal(t, 1, p.ProcessStatus().Restarts)
+		require.Equal(t, 2, bp.starts())
+		require.Equal(t, restarts+1, testutil.ToFloat64(pluginRestarts.WithLabelValues(p.ID)))
+	})
+
+	t.Run("Backoff doubles every time the process exits", func(t *testing.T) {
+		bp.kill()
+		require.Eventually(t, func() bool {
+			return p.ProcessStatus().State == plugins.ProcessRestarting
+		}, time.Second, time.Millisecond)
+		status := p.ProcessStatus()
+		require.Equal(t, 40*time.Millisecond, status.NextRestart.Sub(*status.LastExit))
+		require.Eventually(t, func() bool {
+			return p.ProcessStatus().State == plugins.ProcessRunning
+		}, time.Second, time.Millisecond)
+		require.Equal(t, 3, bp.starts())
+	})
+
+	t.Run("Process that keeps exiting is in crash loop", func(t *testing.T) {
+		bp.kill()
+		require.Eventually(t, func() bool {
+			return p.ProcessStatus().State == plugins.ProcessCrashLoop
+		}, time.Second, time.Millisecond)
+		status := p.ProcessStatus()
+		require.Equal(t, time.Hour, status.NextRestart.Sub(*status.LastExit))
+		require.Equal(t, float64(1), testutil.ToFloat64(pluginCrashLoop.WithLabelValues(p.ID)))
+		require.Equal(t, 3, bp.starts())
+	})
+
+	t.Run("Decommissioned plugin is not restarted", func(t *testing.T) {
+		require.NoError(t, m.Stop(ctx, p.ID))
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(pluginCrashLoop.WithLabelValues(p.ID)) == 0
+		}, time.Second, time.Millisecond)
+		require.Equal(t, 3, bp.starts())
+	})
+}
+
+func TestNextBackoff(t *testing.T) {
+	require.Equal(t, 2*time.Second, nextBackoff(time.Second, time.Minute))
+	require.Equal(t, time.Minute, nextBackoff(45*time.Second, time.Minute))
+}
+
+func TestRecentExits(t *testing.T) {
+	now := time.Now()
+	exits := []time.Time{now.Add(-20 * time.Minute), now.Add(-5 * time.Minute), now.Add(-time.Minute)}
+	require.Equal(t, exits[1:], recentExits(exits, now, 10*time.Minute))
+	require.Empty(t, recentExits(exits, now, time.Second))
+}
+
 type fakePluginRegistry struct {
 	store map[string]*plugins.Plugin
 }
@@ -254,6 +341,12 @@ func (p *fakeBackendPlugin) Exited() bool {
 	return !p.running
 }
 
+func (p *fakeBackendPlugin) starts() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.startCount
+}
+
 func (p *fakeBackendPlugin) kill() {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
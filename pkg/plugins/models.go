@@ -3,6 +3,7 @@ package plugins
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/org"
 )
@@ -192,6 +193,27 @@ func (s SignatureType) IsValid() bool {
 
 type PluginFiles map[string]struct{}
 
+// ProcessState is the state of the process of a managed backend plugin.
+type ProcessState string
+
+const (
+	// ProcessRunning is the state of a plugin process that is running.
+	ProcessRunning ProcessState = "running"
+	// ProcessRestarting is the state of a plugin process that exited and is waiting to be restarted.
+	ProcessRestarting ProcessState = "restarting"
+	// ProcessCrashLoop is the state of a plugin process that keeps exiting shortly after being
+	// started. It is still restarted, but with the maximum backoff.
+	ProcessCrashLoop ProcessState = "crashLoop"
+)
+
+// ProcessStatus describes the supervision of the process of a managed backend plugin.
+type ProcessStatus struct {
+	State       ProcessState `json:"state"`
+	Restarts    int          `json:"restarts"`
+	LastExit    *time.Time   `json:"lastExit,omitempty"`
+	NextRestart *time.Time   `json:"nextRestart,omitempty"`
+}
+
 type Signature struct {
 	Status     SignatureStatus
 	Type       SignatureType
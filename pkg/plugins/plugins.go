@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -41,6 +42,8 @@ type Plugin struct {
 	SecretsManager secretsmanagerplugin.SecretsManagerPlugin
 	client         backendplugin.Plugin
 	log            log.Logger
+
+	processStatus atomic.Value
 }
 
 type PluginDTO struct {
@@ -65,6 +68,9 @@ type PluginDTO struct {
 	Module  string
 	BaseURL string
 
+	// ProcessStatus is the status of the process of a managed backend plugin, if it has been started
+	ProcessStatus *ProcessStatus
+
 	// temporary
 	backend.StreamHandler
 }
@@ -248,6 +254,28 @@ func (p *Plugin) Exited() bool {
 	return false
 }
 
+// Pid returns the process ID of the plugin, if it runs in its own process.
+func (p *Plugin) Pid() (int, bool) {
+	if pi, ok := p.client.(backendplugin.ProcessInfo); ok {
+		return pi.Pid()
+	}
+	return 0, false
+}
+
+// ProcessStatus returns the status of the process of the plugin, or nil if the plugin process
+// is not supervised.
+func (p *Plugin) ProcessStatus() *ProcessStatus {
+	status, ok := p.processStatus.Load().(ProcessStatus)
+	if !ok {
+		return nil
+	}
+	return &status
+}
+
+func (p *Plugin) SetProcessStatus(status ProcessStatus) {
+	p.processStatus.Store(status)
+}
+
 func (p *Plugin) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	pluginClient, ok := p.Client()
 	if !ok {
@@ -340,6 +368,7 @@ func (p *Plugin) ToDTO() PluginDTO {
 		SignatureError:  p.SignatureError,
 		Module:          p.Module,
 		BaseURL:         p.BaseURL,
+		ProcessStatus:   p.ProcessStatus(),
 		StreamHandler:   c,
 	}
 }
@@ -82,10 +82,14 @@ func (c *Client) downloadFile(tmpFile *os.File, pluginURL, checksum string, comp
 				c.log.Warn("Failed to close file", "err", err)
 			}
 		}()
-		_, err = io.Copy(tmpFile, f)
+		h := sha256.New()
+		_, err = io.Copy(tmpFile, io.TeeReader(f, h))
 		if err != nil {
 			return fmt.Errorf("%v: %w", "Failed to copy plugin archive", err)
 		}
+		if len(checksum) > 0 && checksum != fmt.Sprintf("%x", h.Sum(nil)) {
+			return fmt.Errorf("expected SHA256 checksum does not match the plugin archive %s", pluginURL)
+		}
 		return nil
 	}
 
@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/grafana/grafana/pkg/plugins/logger"
+)
+
+const mirrorIndexFile = "index.json"
+
+// Mirror is a plugin repository served from a local directory or a static HTTP server, for
+// installations that cannot reach grafana.com.
+//
+// The mirror contains an index.json file, with the same format as the plugin repository of
+// grafana.com, and the archive of each plugin version stored as <id>/<id>-<version>.<arch>.zip,
+// where <arch> is a key of the arch metadata of the version in the index, such as linux-amd64
+// or any. The SHA256 checksums of the index are verified when the archives are downloaded.
+type Mirror struct {
+	client   *Client
+	location string
+
+	log logger.Logger
+}
+
+func NewMirror(skipTLSVerify bool, location string, logger logger.Logger) *Mirror {
+	return &Mirror{
+		client:   newClient(skipTLSVerify, logger),
+		location: strings.TrimPrefix(location, "file://"),
+		log:      logger,
+	}
+}
+
+// GetPluginArchive fetches the requested plugin archive from the mirror
+func (m *Mirror) GetPluginArchive(ctx context.Context, pluginID, version string, compatOpts CompatOpts) (*PluginArchive, error) {
+	dlOpts, err := m.GetPluginDownloadOptions(ctx, pluginID, version, compatOpts)
+	if err != nil {
+		return nil, err
+	}
+	if !m.isRemote() {
+		if _, err := os.Stat(dlOpts.PluginZipURL); err != nil {
+			return nil, fmt.Errorf("archive of %s v%s is missing from mirror: %w", pluginID, dlOpts.Version, err)
+		}
+	}
+
+	return m.client.download(ctx, dlOpts.PluginZipURL, dlOpts.Checksum, compatOpts)
+}
+
+// GetPluginArchiveByURL fetches the requested plugin archive from the provided `pluginZipURL`
+func (m *Mirror) GetPluginArchiveByURL(ctx context.Context, pluginZipURL string, compatOpts CompatOpts) (*PluginArchive, error) {
+	return m.client.download(ctx, pluginZipURL, "", compatOpts)
+}
+
+// GetPluginDownloadOptions returns the options for downloading the requested plugin (with optional `version`) from the mirror
+func (m *Mirror) GetPluginDownloadOptions(_ context.Context, pluginID, version string, compatOpts CompatOpts) (*PluginDownloadOptions, error) {
+	index, err := m.index(compatOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var plugin *Plugin
+	for i := range index.Plugins {
+		if index.Plugins[i].ID == pluginID {
+			plugin = &index.Plugins[i]
+			break
+		}
+	}
+	if plugin == nil {
+		return nil, ErrPluginNotFound{PluginID: pluginID}
+	}
+	sortVersions(plugin)
+
+	v, err := selectVersion(plugin, version, compatOpts, m.log)
+	if err != nil {
+		return nil, err
+	}
+
+	arch := "any"
+	if _, exists := v.Arch[compatOpts.OSAndArch()]; exists {
+		arch = compatOpts.OSAndArch()
+	}
+
+	archive, err := m.resolve(pluginID, fmt.Sprintf("%s-%s.%s.zip", pluginID, v.Version, arch))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PluginDownloadOptions{
+		Version:      v.Version,
+		Checksum:     v.Arch[arch].SHA256,
+		PluginZipURL: archive,
+	}, nil
+}
+
+func (m *Mirror) index(compatOpts CompatOpts) (PluginRepo, error) {
+	m.log.Debugf("Fetching plugin index from mirror %s", m.location)
+
+	var body []byte
+	if m.isRemote() {
+		u, err := url.Parse(m.location)
+		if err != nil {
+			return PluginRepo{}, err
+		}
+		u.Path = path.Join(u.Path, mirrorIndexFile)
+		if body, err = m.client.sendReq(u, compatOpts); err != nil {
+			return PluginRepo{}, err
+		}
+	} else {
+		var err error
+		// We can ignore the gosec G304 warning since the mirror is set by the administrator.
+		// nolint:gosec
+		if body, err = os.ReadFile(filepath.Join(m.location, mirrorIndexFile)); err != nil {
+			return PluginRepo{}, fmt.Errorf("failed to read plugin index of mirror: %w", err)
+		}
+	}
+
+	var index PluginRepo
+	if err := json.Unmarshal(body, &index); err != nil {
+		return PluginRepo{}, fmt.Errorf("failed to parse plugin index of mirror: %w", err)
+	}
+	return index, nil
+}
+
+// resolve returns the URL or the path of a file in the mirror.
+func (m *Mirror) resolve(elem ...string) (string, error) {
+	if !m.isRemote() {
+		return filepath.Join(append([]string{m.location}, elem...)...), nil
+	}
+	u, err := url.Parse(m.location)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(append([]string{u.Path}, elem...)...)
+	return u.String(), nil
+}
+
+func (m *Mirror) isRemote() bool {
+	return strings.HasPrefix(m.location, "http://") || strings.HasPrefix(m.location, "https://")
+}
+
+// sortVersions sorts the versions of the plugin so that the newest version is first, as
+// selectVersion expects. The index of a mirror is usually written by hand, so the order of the
+// versions in the index cannot be trusted.
+func sortVersions(plugin *Plugin) {
+	sort.SliceStable(plugin.Versions, func(i, j int) bool {
+		vi, err := semver.NewVersion(plugin.Versions[i].Version)
+		if err != nil {
+			return false
+		}
+		vj, err := semver.NewVersion(plugin.Versions[j].Version)
+		if err != nil {
+			return false
+		}
+		return vi.GreaterThan(vj)
+	})
+}
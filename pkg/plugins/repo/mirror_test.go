@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirror(t *testing.T) {
+	dir := t.TempDir()
+	index := PluginRepo{Plugins: []Plugin{{
+		ID: "test-panel",
+		Versions: []Version{
+			{Version: "1.0.0", Arch: map[string]ArchMeta{"any": {SHA256: writePluginArchive(t, dir, "test-panel", "1.0.0", "any")}}},
+			{Version: "2.0.0", GrafanaDependency: ">=9.0.0", Arch: map[string]ArchMeta{"any": {SHA256: writePluginArchive(t, dir, "test-panel", "2.0.0", "any")}}},
+			{Version: "1.1.0", Arch: map[string]ArchMeta{
+				"linux-amd64":  {SHA256: writePluginArchive(t, dir, "test-panel", "1.1.0", "linux-amd64")},
+				"darwin-arm64": {SHA256: "invalid"},
+			}},
+		},
+	}}}
+	data, err := json.Marshal(index)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, mirrorIndexFile), data, 0600))
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(server.Close)
+
+	for name, location := range map[string]string{"directory": dir, "HTTP server": server.URL + "/"} {
+		mirror := NewMirror(false, location, &fakeLogger{})
+
+		t.Run(fmt.Sprintf("%s: should install the latest compatible version", name), func(t *testing.T) {
+			opts := NewCompatOpts("8.5.0", "linux", "amd64")
+			dlOpts, err := mirror.GetPluginDownloadOptions(context.Background(), "test-panel", "", opts)
+			require.NoError(t, err)
+			require.Equal(t, "1.1.0", dlOpts.Version)
+
+			archive, err := mirror.GetPluginArchive(context.Background(), "test-panel", "", opts)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = archive.File.Close() })
+			require.Equal(t, "test-panel/plugin.json", archive.File.File[0].Name)
+		})
+
+		t.Run(fmt.Sprintf("%s: should install the requested version", name), func(t *testing.T) {
+			archive, err := mirror.GetPluginArchive(context.Background(), "test-panel", "2.0.0", NewCompatOpts("9.1.0", "windows", "amd64"))
+			require.NoError(t, err)
+			require.NoError(t, archive.File.Close())
+		})
+
+		t.Run(fmt.Sprintf("%s: should fail if the checksum does not match", name), func(t *testing.T) {
+			_, err := mirror.GetPluginArchive(context.Background(), "test-panel", "1.1.0", NewCompatOpts("8.5.0", "darwin", "arm64"))
+			require.Error(t, err)
+		})
+
+		t.Run(fmt.Sprintf("%s: should fail if the version is not supported", name), func(t *testing.T) {
+			_, err := mirror.GetPluginDownloadOptions(context.Background(), "test-panel", "2.0.0", NewCompatOpts("8.5.0", "linux", "amd64"))
+			require.ErrorAs(t, err, &ErrVersionUnsupported{})
+		})
+
+		t.Run(fmt.Sprintf("%s: should fail if the plugin is not in the mirror", name), func(t *testing.T) {
+			_, err := mirror.GetPluginDownloadOptions(context.Background(), "other-panel", "", NewCompatOpts("8.5.0", "linux", "amd64"))
+			require.ErrorAs(t, err, &ErrPluginNotFound{})
+		})
+	}
+}
+
+// writePluginArchive writes the archive of a plugin version to the mirror and returns its checksum.
+func writePluginArchive(t *testing.T, dir, pluginID, version, arch string) string {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, pluginID), 0750))
+	path := filepath.Join(dir, pluginID, fmt.Sprintf("%s-%s.%s.zip", pluginID, version, arch))
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	pluginJSON, err := w.Create(pluginID + "/plugin.json")
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(pluginJSON, `{"id": %q, "type": "panel", "info": {"version": %q}}`, pluginID, version)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
@@ -22,10 +22,11 @@ type Plugin struct {
 }
 
 type Version struct {
-	Commit  string              `json:"commit"`
-	URL     string              `json:"repoURL"`
-	Version string              `json:"version"`
-	Arch    map[string]ArchMeta `json:"arch"`
+	Commit            string              `json:"commit"`
+	URL               string              `json:"repoURL"`
+	Version           string              `json:"version"`
+	Arch              map[string]ArchMeta `json:"arch"`
+	GrafanaDependency string              `json:"grafanaDependency,omitempty"`
 }
 
 type ArchMeta struct {
@@ -72,3 +73,11 @@ type ErrVersionNotFound struct {
 func (e ErrVersionNotFound) Error() string {
 	return fmt.Sprintf("%s v%s either does not exist or is not supported on your system (%s)", e.PluginID, e.RequestedVersion, e.SystemInfo)
 }
+
+type ErrPluginNotFound struct {
+	PluginID string
+}
+
+func (e ErrPluginNotFound) Error() string {
+	return fmt.Sprintf("%s does not exist in the plugin repository", e.PluginID)
+}
@@ -8,6 +8,9 @@ import (
 	"path"
 	"strings"
 
+	"github.com/Masterminds/semver"
+
+	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/logger"
 )
 
@@ -18,7 +21,10 @@ type Manager struct {
 	log logger.Logger
 }
 
-func ProvideService() *Manager {
+func ProvideService(cfg *config.Cfg) Service {
+	if cfg.PluginsRepositoryMirror != "" {
+		return NewMirror(false, cfg.PluginsRepositoryMirror, logger.NewLogger("plugin.repository"))
+	}
 	defaultBaseURL := "https://grafana.com/api/plugins"
 	return New(false, defaultBaseURL, logger.NewLogger("plugin.repository"))
 }
@@ -106,6 +112,10 @@ func (m *Manager) pluginMetadata(pluginID string, compatOpts CompatOpts) (Plugin
 // returns error if supplied version exists but is not supported.
 // NOTE: It expects plugin.Versions to be sorted so the newest version is first.
 func (m *Manager) selectVersion(plugin *Plugin, version string, compatOpts CompatOpts) (*Version, error) {
+	return selectVersion(plugin, version, compatOpts, m.log)
+}
+
+func selectVersion(plugin *Plugin, version string, compatOpts CompatOpts, log logger.Logger) (*Version, error) {
 	version = normalizeVersion(version)
 
 	var ver Version
@@ -129,7 +139,7 @@ func (m *Manager) selectVersion(plugin *Plugin, version string, compatOpts Compa
 	}
 
 	if len(ver.Version) == 0 {
-		m.log.Debugf("Requested plugin version %s v%s not found but potential fallback version '%s' was found",
+		log.Debugf("Requested plugin version %s v%s not found but potential fallback version '%s' was found",
 			plugin.ID, version, latestForArch.Version)
 		return nil, ErrVersionNotFound{
 			PluginID:         plugin.ID,
@@ -138,8 +148,8 @@ func (m *Manager) selectVersion(plugin *Plugin, version string, compatOpts Compa
 		}
 	}
 
-	if !supportsCurrentArch(&ver, compatOpts) {
-		m.log.Debugf("Requested plugin version %s v%s is not supported on your system but potential fallback version '%s' was found",
+	if !isCompatible(&ver, compatOpts) {
+		log.Debugf("Requested plugin version %s v%s is not supported on your system but potential fallback version '%s' was found",
 			plugin.ID, version, latestForArch.Version)
 		return nil, ErrVersionUnsupported{
 			PluginID:         plugin.ID,
@@ -163,10 +173,35 @@ func supportsCurrentArch(version *Version, compatOpts CompatOpts) bool {
 	return false
 }
 
+// supportsGrafanaVersion returns false if the version declares a Grafana dependency that the
+// Grafana version does not satisfy. Pre-releases of Grafana are considered as the release.
+func supportsGrafanaVersion(version *Version, compatOpts CompatOpts) bool {
+	if version.GrafanaDependency == "" || compatOpts.GrafanaVersion == "" {
+		return true
+	}
+	constraint, err := semver.NewConstraint(version.GrafanaDependency)
+	if err != nil {
+		return true
+	}
+	grafanaVersion, err := semver.NewVersion(compatOpts.GrafanaVersion)
+	if err != nil {
+		return true
+	}
+	release, err := grafanaVersion.SetPrerelease("")
+	if err != nil {
+		return true
+	}
+	return constraint.Check(&release)
+}
+
+func isCompatible(version *Version, compatOpts CompatOpts) bool {
+	return supportsCurrentArch(version, compatOpts) && supportsGrafanaVersion(version, compatOpts)
+}
+
 func latestSupportedVersion(plugin *Plugin, compatOpts CompatOpts) *Version {
 	for _, v := range plugin.Versions {
 		ver := v
-		if supportsCurrentArch(&ver, compatOpts) {
+		if isCompatible(&ver, compatOpts) {
 			return &ver
 		}
 	}
@@ -50,6 +50,30 @@ func TestSelectVersion(t *testing.T) {
 	})
 }
 
+func TestSelectVersion_GrafanaDependency(t *testing.T) {
+	i := &Manager{log: &fakeLogger{}}
+	plugin := createPlugin(versionArg{version: "2.0.0"}, versionArg{version: "1.0.0"})
+	plugin.Versions[0].GrafanaDependency = ">=9.0.0"
+	plugin.Versions[1].GrafanaDependency = ">=8.0.0"
+
+	t.Run("Should return latest version supported by the Grafana version", func(t *testing.T) {
+		ver, err := i.selectVersion(plugin, "", CompatOpts{GrafanaVersion: "8.5.0"})
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", ver.Version)
+	})
+
+	t.Run("Should treat a pre-release of Grafana as the release", func(t *testing.T) {
+		ver, err := i.selectVersion(plugin, "", CompatOpts{GrafanaVersion: "9.0.0-pre"})
+		require.NoError(t, err)
+		require.Equal(t, "2.0.0", ver.Version)
+	})
+
+	t.Run("Should return error when requested version does not support the Grafana version", func(t *testing.T) {
+		_, err := i.selectVersion(plugin, "2.0.0", CompatOpts{GrafanaVersion: "8.5.0"})
+		require.ErrorAs(t, err, &ErrVersionUnsupported{})
+	})
+}
+
 type versionArg struct {
 	version string
 	arch    []string
@@ -66,6 +66,7 @@ import (
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/service"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapstore "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	dashsnapscheduler "github.com/grafana/grafana/pkg/services/dashboardsnapshots/scheduler"
 	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashverimpl"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
@@ -176,7 +177,7 @@ var wireBasicSet = wire.NewSet(
 	routing.ProvideRegister,
 	wire.Bind(new(routing.RouteRegister), new(*routing.RouteRegisterImpl)),
 	hooks.ProvideService,
-	kvstore.ProvideService,
+	kvstore.ProvideStore,
 	localcache.ProvideService,
 	updatechecker.ProvideGrafanaService,
 	updatechecker.ProvidePluginsService,
@@ -186,7 +187,6 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(registry.Service), new(*registry.InMemory)),
 	pluginsCfg.ProvideConfig,
 	repo.ProvideService,
-	wire.Bind(new(repo.Service), new(*repo.Manager)),
 	manager.ProvideInstaller,
 	wire.Bind(new(plugins.Installer), new(*manager.PluginInstaller)),
 	client.ProvideService,
@@ -282,6 +282,9 @@ var wireBasicSet = wire.NewSet(
 	dashsnapstore.ProvideStore,
 	wire.Bind(new(dashboardsnapshots.Service), new(*dashsnapsvc.ServiceImpl)),
 	dashsnapsvc.ProvideService,
+	wire.Bind(new(dashboardsnapshots.ScheduleStore), new(*dashsnapstore.DashboardSnapshotStore)),
+	wire.Bind(new(dashboardsnapshots.ScheduleService), new(*dashsnapscheduler.Scheduler)),
+	dashsnapscheduler.ProvideService,
 	datasourceservice.ProvideService,
 	wire.Bind(new(datasources.DataSourceService), new(*datasourceservice.Service)),
 	pluginSettings.ProvideService,
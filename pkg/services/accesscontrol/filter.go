@@ -18,6 +18,8 @@ var sqlIDAcceptList = map[string]struct{}{
 	"\"user\".\"id\"":  {}, // For Postgres
 	"`user`.`id`":      {}, // For MySQL and SQLite
 	"dashboard.uid":    {},
+
+	"query_history.datasource_uid": {},
 }
 
 var (
@@ -10,8 +10,10 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -98,9 +100,19 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User,
 		AuthTokenSeen: false,
 	}
 
-	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		_, err = dbSession.Insert(&userAuthToken)
-		return err
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		lifetime, err := s.roleLifetime(dbSession, user)
+		if err != nil {
+			return err
+		}
+		userAuthToken.MaxInactiveLifetime = int64(lifetime.MaxInactiveLifetime / time.Second)
+		userAuthToken.MaxLifetime = int64(lifetime.MaxLifetime / time.Second)
+
+		if _, err = dbSession.Insert(&userAuthToken); err != nil {
+			return err
+		}
+
+		return s.revokeExcessTokens(ctx, dbSession, user.ID)
 	})
 
 	if err != nil {
@@ -142,14 +154,18 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 	ctxLogger := s.log.FromContext(ctx)
 
 	if model.RevokedAt > 0 {
-		ctxLogger.Debug("user token has been revoked", "user ID", model.UserId, "token ID", model.Id)
-		return nil, &models.TokenRevokedError{
+		ctxLogger.Debug("user token has been revoked", "user ID", model.UserId, "token ID", model.Id, "reason", model.RevokeReason)
+		revokedErr := &models.TokenRevokedError{
 			UserID:  model.UserId,
 			TokenID: model.Id,
 		}
+		if model.RevokeReason == events.UserSessionRevokedReasonMaxConcurrentSessions {
+			revokedErr.MaxConcurrentSessions = int64(s.Cfg.LoginMaxConcurrentSessions)
+		}
+		return nil, revokedErr
 	}
 
-	if model.CreatedAt <= s.createdAfterParam() || model.RotatedAt <= s.rotatedAfterParam() {
+	if s.isExpired(&model) {
 		ctxLogger.Debug("user token has expired", "user ID", model.UserId, "token ID", model.Id)
 		return nil, &models.TokenExpiredError{
 			UserID:  model.UserId,
@@ -302,6 +318,41 @@ func (s *UserAuthTokenService) TryRotateToken(ctx context.Context, token *models
 	return false, nil
 }
 
+// CheckTokenDevice enforces login_session_device_binding: a session is bound to the
+// user agent it was created with, and is soft revoked when it is used from another one.
+func (s *UserAuthTokenService) CheckTokenDevice(ctx context.Context, token *models.UserToken, userAgent string) error {
+	if !s.Cfg.LoginSessionDeviceBinding || token == nil || token.UserAgent == userAgent {
+		return nil
+	}
+
+	now := getTime()
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, err := dbSession.Exec("UPDATE user_auth_token SET revoked_at = ?, revoke_reason = ? WHERE id = ? AND revoked_at = 0",
+			now.Unix(), events.UserSessionRevokedReasonDeviceMismatch, token.Id); err != nil {
+			return err
+		}
+
+		dbSession.PublishAfterCommit(&events.UserSessionRevoked{
+			Timestamp: now,
+			TokenID:   token.Id,
+			UserID:    token.UserId,
+			ClientIP:  token.ClientIp,
+			UserAgent: userAgent,
+			Reason:    events.UserSessionRevokedReasonDeviceMismatch,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.FromContext(ctx).Info("user auth token revoked, used from another device", "tokenId", token.Id, "userId", token.UserId, "userAgent", userAgent, "boundUserAgent", token.UserAgent)
+	return &models.TokenRevokedError{
+		UserID:  token.UserId,
+		TokenID: token.Id,
+	}
+}
+
 func (s *UserAuthTokenService) RevokeToken(ctx context.Context, token *models.UserToken, soft bool) error {
 	if token == nil {
 		return models.ErrUserTokenNotFound
@@ -415,16 +466,16 @@ func (s *UserAuthTokenService) GetUserTokens(ctx context.Context, userId int64)
 	result := []*models.UserToken{}
 	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		var tokens []*userAuthToken
-		err := dbSession.Where("user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0",
-			userId,
-			s.createdAfterParam(),
-			s.rotatedAfterParam()).
-			Find(&tokens)
+		err := dbSession.Where("user_id = ? AND revoked_at = 0", userId).Find(&tokens)
 		if err != nil {
 			return err
 		}
 
 		for _, token := range tokens {
+			if s.isExpired(token) {
+				continue
+			}
+
 			var userToken models.UserToken
 			if err := token.toUserToken(&userToken); err != nil {
 				return err
@@ -461,12 +512,135 @@ func (s *UserAuthTokenService) GetUserRevokedTokens(ctx context.Context, userId
 	return result, err
 }
 
-func (s *UserAuthTokenService) createdAfterParam() int64 {
-	return getTime().Add(-s.Cfg.LoginMaxLifetime).Unix()
+// GetOrgUserTokens returns the active tokens of all users that are members of the given organization.
+func (s *UserAuthTokenService) GetOrgUserTokens(ctx context.Context, orgId int64) ([]*models.UserToken, error) {
+	result := []*models.UserToken{}
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var tokens []*userAuthToken
+		err := dbSession.Table("user_auth_token").
+			Select("user_auth_token.*").
+			Join("INNER", "org_user", "org_user.user_id = user_auth_token.user_id").
+			Where("org_user.org_id = ? AND user_auth_token.revoked_at = 0", orgId).
+			Asc("user_auth_token.user_id").
+			Asc("user_auth_token.id").
+			Find(&tokens)
+		if err != nil {
+			return err
+		}
+
+		for _, token := range tokens {
+			if s.isExpired(token) {
+				continue
+			}
+
+			var userToken models.UserToken
+			if err := token.toUserToken(&userToken); err != nil {
+				return err
+			}
+			result = append(result, &userToken)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// roleLifetime returns the session lifetime overrides configured for the most
+// privileged role of the user. Grafana server admins use the GrafanaAdmin
+// override when one is configured, and their organization role otherwise.
+func (s *UserAuthTokenService) roleLifetime(dbSession *sqlstore.DBSession, usr *user.User) (setting.LoginLifetime, error) {
+	if len(s.Cfg.LoginRoleLifetimes) == 0 {
+		return setting.LoginLifetime{}, nil
+	}
+
+	if usr.IsAdmin {
+		if lifetime, ok := s.Cfg.LoginRoleLifetimes["GrafanaAdmin"]; ok {
+			return lifetime, nil
+		}
+	}
+
+	var roles []string
+	if err := dbSession.Table("org_user").Where("user_id = ?", usr.ID).Cols("role").Find(&roles); err != nil {
+		return setting.LoginLifetime{}, err
+	}
+
+	var highest org.RoleType
+	for _, r := range roles {
+		role := org.RoleType(r)
+		if !role.IsValid() {
+			continue
+		}
+		if highest == "" || role.Includes(highest) {
+			highest = role
+		}
+	}
+
+	return s.Cfg.LoginRoleLifetimes[string(highest)], nil
 }
 
-func (s *UserAuthTokenService) rotatedAfterParam() int64 {
-	return getTime().Add(-s.Cfg.LoginMaxInactiveLifetime).Unix()
+// revokeExcessTokens soft revokes the oldest active tokens of the user when the
+// number of concurrent sessions exceeds login_maximum_concurrent_sessions.
+func (s *UserAuthTokenService) revokeExcessTokens(ctx context.Context, dbSession *sqlstore.DBSession, userId int64) error {
+	limit := s.Cfg.LoginMaxConcurrentSessions
+	if limit <= 0 {
+		return nil
+	}
+
+	var tokens []*userAuthToken
+	err := dbSession.Where("user_id = ? AND revoked_at = 0", userId).Desc("created_at").Desc("id").Find(&tokens)
+	if err != nil {
+		return err
+	}
+
+	active := make([]*userAuthToken, 0, len(tokens))
+	for _, token := range tokens {
+		if !s.isExpired(token) {
+			active = append(active, token)
+		}
+	}
+
+	if len(active) <= limit {
+		return nil
+	}
+
+	now := getTime()
+	ctxLogger := s.log.FromContext(ctx)
+	for _, token := range active[limit:] {
+		if _, err := dbSession.Exec("UPDATE user_auth_token SET revoked_at = ?, revoke_reason = ? WHERE id = ?",
+			now.Unix(), events.UserSessionRevokedReasonMaxConcurrentSessions, token.Id); err != nil {
+			return err
+		}
+
+		ctxLogger.Info("user auth token revoked, maximum concurrent sessions reached", "tokenId", token.Id, "userId", token.UserId, "clientIP", token.ClientIp, "userAgent", token.UserAgent, "maxConcurrentSessions", limit)
+		dbSession.PublishAfterCommit(&events.UserSessionRevoked{
+			Timestamp: now,
+			TokenID:   token.Id,
+			UserID:    token.UserId,
+			ClientIP:  token.ClientIp,
+			UserAgent: token.UserAgent,
+			Reason:    events.UserSessionRevokedReasonMaxConcurrentSessions,
+		})
+	}
+
+	return nil
+}
+
+// isExpired checks the token against its role based lifetimes, falling back to
+// the configured login_maximum_lifetime_duration and login_maximum_inactive_lifetime_duration.
+func (s *UserAuthTokenService) isExpired(token *userAuthToken) bool {
+	maxLifetime := s.Cfg.LoginMaxLifetime
+	if token.MaxLifetime > 0 {
+		maxLifetime = time.Duration(token.MaxLifetime) * time.Second
+	}
+
+	maxInactiveLifetime := s.Cfg.LoginMaxInactiveLifetime
+	if token.MaxInactiveLifetime > 0 {
+		maxInactiveLifetime = time.Duration(token.MaxInactiveLifetime) * time.Second
+	}
+
+	now := getTime()
+	return token.CreatedAt <= now.Add(-maxLifetime).Unix() || token.RotatedAt <= now.Add(-maxInactiveLifetime).Unix()
 }
 
 func hashToken(token string) string {
@@ -9,10 +9,12 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/user"
 
@@ -529,6 +531,157 @@ func TestUserAuthToken(t *testing.T) {
 	})
 }
 
+func TestUserAuthTokenSessionPolicies(t *testing.T) {
+	now := time.Date(2018, 12, 13, 13, 45, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	defer func() { getTime = time.Now }()
+
+	t.Run("should revoke the oldest tokens when the concurrent sessions limit is reached", func(t *testing.T) {
+		ctx := createTestContext(t)
+		ctx.tokenService.Cfg.LoginMaxConcurrentSessions = 2
+		usr := &user.User{ID: int64(10)}
+
+		var revoked []*events.UserSessionRevoked
+		ctx.sqlstore.Bus().AddEventListener(func(_ context.Context, e *events.UserSessionRevoked) error {
+			revoked = append(revoked, e)
+			return nil
+		})
+
+		tokens := make([]*models.UserToken, 0, 3)
+		for i := 0; i < 3; i++ {
+			getTime = func() time.Time { return now.Add(time.Duration(i) * time.Minute) }
+			token, err := ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP("192.168.10.11"), "some user agent")
+			require.NoError(t, err)
+			tokens = append(tokens, token)
+		}
+
+		_, err := ctx.tokenService.LookupToken(context.Background(), tokens[0].UnhashedToken)
+		var revokedErr *models.TokenRevokedError
+		require.ErrorAs(t, err, &revokedErr)
+		require.Equal(t, int64(2), revokedErr.MaxConcurrentSessions)
+
+		for _, token := range tokens[1:] {
+			_, err := ctx.tokenService.LookupToken(context.Background(), token.UnhashedToken)
+			require.NoError(t, err)
+		}
+
+		active, err := ctx.tokenService.GetUserTokens(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+
+		require.Len(t, revoked, 1)
+		require.Equal(t, tokens[0].Id, revoked[0].TokenID)
+		require.Equal(t, events.UserSessionRevokedReasonMaxConcurrentSessions, revoked[0].Reason)
+	})
+
+	t.Run("should not report the concurrent sessions limit for other revoked tokens", func(t *testing.T) {
+		getTime = func() time.Time { return now }
+		ctx := createTestContext(t)
+		ctx.tokenService.Cfg.LoginMaxConcurrentSessions = 2
+
+		token, err := ctx.tokenService.CreateToken(context.Background(), &user.User{ID: int64(11)}, net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+		require.NoError(t, ctx.tokenService.RevokeToken(context.Background(), token, true))
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), token.UnhashedToken)
+		var revokedErr *models.TokenRevokedError
+		require.ErrorAs(t, err, &revokedErr)
+		require.Zero(t, revokedErr.MaxConcurrentSessions)
+	})
+
+	t.Run("should revoke the token when it is used from another device", func(t *testing.T) {
+		getTime = func() time.Time { return now }
+		ctx := createTestContext(t)
+		usr := &user.User{ID: int64(12)}
+
+		var revoked []*events.UserSessionRevoked
+		ctx.sqlstore.Bus().AddEventListener(func(_ context.Context, e *events.UserSessionRevoked) error {
+			revoked = append(revoked, e)
+			return nil
+		})
+
+		token, err := ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+
+		// device binding is disabled by default
+		require.NoError(t, ctx.tokenService.CheckTokenDevice(context.Background(), token, "other user agent"))
+
+		ctx.tokenService.Cfg.LoginSessionDeviceBinding = true
+		require.NoError(t, ctx.tokenService.CheckTokenDevice(context.Background(), token, "some user agent"))
+
+		err = ctx.tokenService.CheckTokenDevice(context.Background(), token, "other user agent")
+		var revokedErr *models.TokenRevokedError
+		require.ErrorAs(t, err, &revokedErr)
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), token.UnhashedToken)
+		require.ErrorAs(t, err, &revokedErr)
+		require.Zero(t, revokedErr.MaxConcurrentSessions)
+
+		model, err := ctx.getAuthTokenByID(token.Id)
+		require.NoError(t, err)
+		require.Equal(t, events.UserSessionRevokedReasonDeviceMismatch, model.RevokeReason)
+
+		require.Len(t, revoked, 1)
+		require.Equal(t, events.UserSessionRevokedReasonDeviceMismatch, revoked[0].Reason)
+		require.Equal(t, "other user agent", revoked[0].UserAgent)
+	})
+
+	t.Run("should apply the lifetimes of the highest role of the user", func(t *testing.T) {
+		getTime = func() time.Time { return now }
+		ctx := createTestContext(t)
+		ctx.tokenService.Cfg.LoginRoleLifetimes = map[string]setting.LoginLifetime{
+			"Admin": {MaxLifetime: time.Hour, MaxInactiveLifetime: 30 * time.Minute},
+		}
+
+		admin := &user.User{ID: int64(20)}
+		viewer := &user.User{ID: int64(21)}
+		ctx.addOrgUser(t, 1, admin.ID, org.RoleViewer)
+		ctx.addOrgUser(t, 2, admin.ID, org.RoleAdmin)
+		ctx.addOrgUser(t, 1, viewer.ID, org.RoleViewer)
+
+		adminToken, err := ctx.tokenService.CreateToken(context.Background(), admin, net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+		require.Equal(t, int64(3600), adminToken.MaxLifetime)
+		require.Equal(t, int64(1800), adminToken.MaxInactiveLifetime)
+
+		viewerToken, err := ctx.tokenService.CreateToken(context.Background(), viewer, net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+		require.Zero(t, viewerToken.MaxLifetime)
+
+		getTime = func() time.Time { return now.Add(30 * time.Minute) }
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), adminToken.UnhashedToken)
+		var expiredErr *models.TokenExpiredError
+		require.ErrorAs(t, err, &expiredErr)
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), viewerToken.UnhashedToken)
+		require.NoError(t, err)
+
+		affected, err := ctx.tokenService.deleteExpiredTokens(context.Background(), ctx.tokenService.Cfg.LoginMaxInactiveLifetime, ctx.tokenService.Cfg.LoginMaxLifetime)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), affected)
+	})
+
+	t.Run("should list the active tokens of the organization users", func(t *testing.T) {
+		getTime = func() time.Time { return now }
+		ctx := createTestContext(t)
+		ctx.addOrgUser(t, 1, 30, org.RoleViewer)
+		ctx.addOrgUser(t, 2, 31, org.RoleViewer)
+
+		token, err := ctx.tokenService.CreateToken(context.Background(), &user.User{ID: 30}, net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+		_, err = ctx.tokenService.CreateToken(context.Background(), &user.User{ID: 31}, net.ParseIP("192.168.10.12"), "other user agent")
+		require.NoError(t, err)
+
+		tokens, err := ctx.tokenService.GetOrgUserTokens(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.Equal(t, token.Id, tokens[0].Id)
+		require.Equal(t, "192.168.10.11", tokens[0].ClientIp)
+		require.Equal(t, "some user agent", tokens[0].UserAgent)
+	})
+}
+
 func createTestContext(t *testing.T) *testContext {
 	t.Helper()
 	maxInactiveDurationVal, _ := time.ParseDuration("168h")
@@ -565,6 +718,18 @@ type testContext struct {
 	activeTokenService *ActiveAuthTokenService
 }
 
+func (c *testContext) addOrgUser(t *testing.T, orgID, userID int64, role org.RoleType) {
+	t.Helper()
+	_, err := c.sqlstore.NewSession(context.Background()).Insert(&models.OrgUser{
+		OrgId:   orgID,
+		UserId:  userID,
+		Role:    role,
+		Created: getTime(),
+		Updated: getTime(),
+	})
+	require.NoError(t, err)
+}
+
 func (c *testContext) getAuthTokenByID(id int64) (*userAuthToken, error) {
 	sess := c.sqlstore.NewSession(context.Background())
 	var t userAuthToken
@@ -19,7 +19,13 @@ type userAuthToken struct {
 	CreatedAt     int64
 	UpdatedAt     int64
 	RevokedAt     int64
-	UnhashedToken string `xorm:"-"`
+	// RevokeReason is one of the events.UserSessionRevokedReason values for soft revoked tokens.
+	RevokeReason string
+	// MaxInactiveLifetime and MaxLifetime are role based overrides, in seconds,
+	// of the configured session lifetimes. Zero means the global setting applies.
+	MaxInactiveLifetime int64
+	MaxLifetime         int64
+	UnhashedToken       string `xorm:"-"`
 }
 
 func userAuthTokenFromUserToken(ut *models.UserToken) (*userAuthToken, error) {
@@ -45,6 +51,9 @@ func (uat *userAuthToken) fromUserToken(ut *models.UserToken) error {
 	uat.CreatedAt = ut.CreatedAt
 	uat.UpdatedAt = ut.UpdatedAt
 	uat.RevokedAt = ut.RevokedAt
+	uat.RevokeReason = ut.RevokeReason
+	uat.MaxInactiveLifetime = ut.MaxInactiveLifetime
+	uat.MaxLifetime = ut.MaxLifetime
 	uat.UnhashedToken = ut.UnhashedToken
 
 	return nil
@@ -67,6 +76,9 @@ func (uat *userAuthToken) toUserToken(ut *models.UserToken) error {
 	ut.CreatedAt = uat.CreatedAt
 	ut.UpdatedAt = uat.UpdatedAt
 	ut.RevokedAt = uat.RevokedAt
+	ut.RevokeReason = uat.RevokeReason
+	ut.MaxInactiveLifetime = uat.MaxInactiveLifetime
+	ut.MaxLifetime = uat.MaxLifetime
 	ut.UnhashedToken = uat.UnhashedToken
 
 	return nil
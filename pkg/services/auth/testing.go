@@ -12,12 +12,14 @@ type FakeUserAuthTokenService struct {
 	CreateTokenProvider          func(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*models.UserToken, error)
 	TryRotateTokenProvider       func(ctx context.Context, token *models.UserToken, clientIP net.IP, userAgent string) (bool, error)
 	LookupTokenProvider          func(ctx context.Context, unhashedToken string) (*models.UserToken, error)
+	CheckTokenDeviceProvider     func(ctx context.Context, token *models.UserToken, userAgent string) error
 	RevokeTokenProvider          func(ctx context.Context, token *models.UserToken, soft bool) error
 	RevokeAllUserTokensProvider  func(ctx context.Context, userId int64) error
 	ActiveAuthTokenCount         func(ctx context.Context) (int64, error)
 	GetUserTokenProvider         func(ctx context.Context, userId, userTokenId int64) (*models.UserToken, error)
 	GetUserTokensProvider        func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	GetUserRevokedTokensProvider func(ctx context.Context, userId int64) ([]*models.UserToken, error)
+	GetOrgUserTokensProvider     func(ctx context.Context, orgId int64) ([]*models.UserToken, error)
 	BatchRevokedTokenProvider    func(ctx context.Context, userIds []int64) error
 }
 
@@ -38,6 +40,9 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 				UnhashedToken: "",
 			}, nil
 		},
+		CheckTokenDeviceProvider: func(ctx context.Context, token *models.UserToken, userAgent string) error {
+			return nil
+		},
 		RevokeTokenProvider: func(ctx context.Context, token *models.UserToken, soft bool) error {
 			return nil
 		},
@@ -56,6 +61,9 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 		GetUserTokensProvider: func(ctx context.Context, userId int64) ([]*models.UserToken, error) {
 			return nil, nil
 		},
+		GetOrgUserTokensProvider: func(ctx context.Context, orgId int64) ([]*models.UserToken, error) {
+			return nil, nil
+		},
 	}
 }
 
@@ -73,6 +81,13 @@ func (s *FakeUserAuthTokenService) LookupToken(ctx context.Context, unhashedToke
 	return s.LookupTokenProvider(context.Background(), unhashedToken)
 }
 
+func (s *FakeUserAuthTokenService) CheckTokenDevice(ctx context.Context, token *models.UserToken, userAgent string) error {
+	if s.CheckTokenDeviceProvider == nil {
+		return nil
+	}
+	return s.CheckTokenDeviceProvider(context.Background(), token, userAgent)
+}
+
 func (s *FakeUserAuthTokenService) TryRotateToken(ctx context.Context, token *models.UserToken, clientIP net.IP,
 	userAgent string) (bool, error) {
 	return s.TryRotateTokenProvider(context.Background(), token, clientIP, userAgent)
@@ -102,6 +117,10 @@ func (s *FakeUserAuthTokenService) GetUserRevokedTokens(ctx context.Context, use
 	return s.GetUserRevokedTokensProvider(context.Background(), userId)
 }
 
+func (s *FakeUserAuthTokenService) GetOrgUserTokens(ctx context.Context, orgId int64) ([]*models.UserToken, error) {
+	return s.GetOrgUserTokensProvider(context.Background(), orgId)
+}
+
 func (s *FakeUserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.BatchRevokedTokenProvider(ctx, userIds)
 }
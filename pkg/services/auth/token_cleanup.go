@@ -47,8 +47,14 @@ func (s *UserAuthTokenService) deleteExpiredTokens(ctx context.Context, maxInact
 
 	var affected int64
 	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		sql := `DELETE from user_auth_token WHERE created_at <= ? OR rotated_at <= ?`
-		res, err := dbSession.Exec(sql, createdBefore.Unix(), rotatedBefore.Unix())
+		// tokens with role based lifetimes are compared against their own lifetimes (in seconds)
+		sql := `DELETE from user_auth_token WHERE
+			(COALESCE(max_lifetime, 0) = 0 AND created_at <= ?) OR
+			(max_lifetime > 0 AND created_at + max_lifetime <= ?) OR
+			(COALESCE(max_inactive_lifetime, 0) = 0 AND rotated_at <= ?) OR
+			(max_inactive_lifetime > 0 AND rotated_at + max_inactive_lifetime <= ?)`
+		now := getTime().Unix()
+		res, err := dbSession.Exec(sql, createdBefore.Unix(), now, rotatedBefore.Unix(), now)
 		if err != nil {
 			return err
 		}
@@ -12,6 +12,7 @@ import (
 
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -31,7 +32,8 @@ import (
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore *sqlstore.SQLStore, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	loginAttemptService loginattempt.Service, tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner) *CleanUpService {
+	loginAttemptService loginattempt.Service, tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner,
+	kvStore kvstore.KVStore) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -46,6 +48,7 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		tempUserService:           tempUserService,
 		tracer:                    tracer,
 		annotationCleaner:         annotationCleaner,
+		kvStore:                   kvStore,
 	}
 	return s
 }
@@ -64,6 +67,7 @@ type CleanUpService struct {
 	loginAttemptService       loginattempt.Service
 	tempUserService           tempuser.Service
 	annotationCleaner         annotations.Cleaner
+	kvStore                   kvstore.KVStore
 }
 
 type cleanUpJob struct {
@@ -107,6 +111,7 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
 		{"delete stale query history", srv.deleteStaleQueryHistory},
 		{"delete old login attempts", srv.deleteOldLoginAttempts},
+		{"delete expired key/value items", srv.deleteExpiredKVItems},
 	}
 
 	logger := srv.log.FromContext(ctx)
@@ -254,6 +259,15 @@ func (srv *CleanUpService) deleteOldLoginAttemptsWithoutLock(ctx context.Context
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredKVItems(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if deleted, err := srv.kvStore.DeleteExpired(ctx); err != nil {
+		logger.Error("Problem deleting expired key/value items", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired key/value items", "rows affected", deleted)
+	}
+}
+
 func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
@@ -283,23 +297,34 @@ func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 
 func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
-	// Delete query history from 14+ days ago with exception of starred queries
-	maxQueryHistoryLifetime := time.Hour * 24 * 14
-	olderThan := time.Now().Add(-maxQueryHistoryLifetime).Unix()
-	rowsCount, err := srv.QueryHistoryService.DeleteStaleQueriesInQueryHistory(ctx, olderThan)
-	if err != nil {
-		logger.Error("Problem deleting stale query history", "error", err.Error())
-	} else {
-		logger.Debug("Deleted stale query history", "rows affected", rowsCount)
+	// Delete query history from the configured days ago with exception of starred and shared queries
+	if srv.Cfg.QueryHistoryRetentionDays > 0 {
+		maxQueryHistoryLifetime := time.Hour * 24 * time.Duration(srv.Cfg.QueryHistoryRetentionDays)
+		olderThan := time.Now().Add(-maxQueryHistoryLifetime).Unix()
+		rowsCount, err := srv.QueryHistoryService.DeleteStaleQueriesInQueryHistory(ctx, olderThan)
+		if err != nil {
+			logger.Error("Problem deleting stale query history", "error", err.Error())
+		} else {
+			logger.Debug("Deleted stale query history", "rows affected", rowsCount)
+		}
 	}
 
-	// Enforce 200k limit for query_history table
-	queryHistoryLimit := 200000
-	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, queryHistoryLimit, false)
+	// Enforce the retention and row limits of the orgs
+	rowsCount, err := srv.QueryHistoryService.EnforceOrgRetentionInQueryHistory(ctx)
 	if err != nil {
-		logger.Error("Problem with enforcing row limit for query_history", "error", err.Error())
+		logger.Error("Problem with enforcing org retention for query_history", "error", err.Error())
 	} else {
-		logger.Debug("Enforced row limit for query_history", "rows affected", rowsCount)
+		logger.Debug("Enforced org retention for query_history", "rows affected", rowsCount)
+	}
+
+	// Enforce the configured limit for query_history table
+	if srv.Cfg.QueryHistoryRowLimit > 0 {
+		rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, srv.Cfg.QueryHistoryRowLimit, false)
+		if err != nil {
+			logger.Error("Problem with enforcing row limit for query_history", "error", err.Error())
+		} else {
+			logger.Debug("Enforced row limit for query_history", "rows affected", rowsCount)
+		}
 	}
 
 	// Enforce 150k limit for query_history_star table
@@ -4,10 +4,13 @@ type EventType string
 
 const (
 	EventCommentCreated EventType = "commentCreated"
+	// EventCommentUpdated is sent when the content or the reactions of a comment change.
+	EventCommentUpdated EventType = "commentUpdated"
 )
 
 // Event represents comment event structure.
 type Event struct {
 	Event          EventType   `json:"event"`
 	CommentCreated *CommentDto `json:"commentCreated"`
+	CommentUpdated *CommentDto `json:"commentUpdated,omitempty"`
 }
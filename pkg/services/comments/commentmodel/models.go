@@ -69,6 +69,13 @@ type Comment struct {
 	GroupId int64
 	UserId  int64
 	Content string
+	// ParentId is set for the replies, threads have a single level.
+	ParentId int64
+
+	// Anchors of the dashboard comments, the time range is in epoch milliseconds.
+	PanelId  int64
+	TimeFrom int64
+	TimeTo   int64
 
 	Created int64
 	Updated int64
@@ -83,23 +90,71 @@ type CommentUser struct {
 }
 
 type CommentDto struct {
-	Id      int64        `json:"id"`
-	UserId  int64        `json:"userId"`
-	Content string       `json:"content"`
-	Created int64        `json:"created"`
-	User    *CommentUser `json:"user,omitempty"`
+	Id         int64                 `json:"id"`
+	UserId     int64                 `json:"userId"`
+	Content    string                `json:"content"`
+	Created    int64                 `json:"created"`
+	Updated    int64                 `json:"updated"`
+	ParentId   int64                 `json:"parentId,omitempty"`
+	PanelId    int64                 `json:"panelId,omitempty"`
+	TimeFrom   int64                 `json:"timeFrom,omitempty"`
+	TimeTo     int64                 `json:"timeTo,omitempty"`
+	ReplyCount int64                 `json:"replyCount"`
+	Reactions  []*CommentReactionDto `json:"reactions"`
+	User       *CommentUser          `json:"user,omitempty"`
 }
 
 func (i Comment) ToDTO(user *CommentUser) *CommentDto {
 	return &CommentDto{
-		Id:      i.Id,
-		UserId:  i.UserId,
-		Content: i.Content,
-		Created: i.Created,
-		User:    user,
+		Id:        i.Id,
+		UserId:    i.UserId,
+		Content:   i.Content,
+		Created:   i.Created,
+		Updated:   i.Updated,
+		ParentId:  i.ParentId,
+		PanelId:   i.PanelId,
+		TimeFrom:  i.TimeFrom,
+		TimeTo:    i.TimeTo,
+		Reactions: []*CommentReactionDto{},
+		User:      user,
 	}
 }
 
 func (i Comment) TableName() string {
 	return "comment"
 }
+
+// CommentHistory keeps the previous content of an edited comment.
+type CommentHistory struct {
+	Id        int64
+	CommentId int64
+	Content   string
+	// Created is when the content was replaced.
+	Created int64
+}
+
+func (i CommentHistory) TableName() string {
+	return "comment_history"
+}
+
+type CommentHistoryDto struct {
+	Content string `json:"content"`
+	Created int64  `json:"created"`
+}
+
+type CommentReaction struct {
+	Id        int64
+	CommentId int64
+	UserId    int64
+	Reaction  string
+	Created   int64
+}
+
+func (i CommentReaction) TableName() string {
+	return "comment_reaction"
+}
+
+type CommentReactionDto struct {
+	Reaction string  `json:"reaction"`
+	UserIds  []int64 `json:"userIds"`
+}
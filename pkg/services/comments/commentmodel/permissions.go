@@ -14,6 +14,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+const (
+	ActionCommentsRead  = "comments:read"
+	ActionCommentsWrite = "comments:write"
+)
+
 type PermissionChecker struct {
 	sqlStore         *sqlstore.SQLStore
 	features         featuremgmt.FeatureToggles
@@ -26,7 +31,7 @@ func NewPermissionChecker(sqlStore *sqlstore.SQLStore, features featuremgmt.Feat
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService,
 	annotationsRepo annotations.Repository,
 ) *PermissionChecker {
-	return &PermissionChecker{sqlStore: sqlStore, features: features, accessControl: accessControl, annotationsRepo: annotationsRepo}
+	return &PermissionChecker{sqlStore: sqlStore, features: features, accessControl: accessControl, dashboardService: dashboardService, annotationsRepo: annotationsRepo}
 }
 
 func (c *PermissionChecker) getDashboardByUid(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
@@ -57,38 +62,24 @@ func (c *PermissionChecker) CheckReadPermissions(ctx context.Context, orgId int6
 		if err != nil {
 			return false, err
 		}
-		guard := guardian.New(ctx, dash.Id, orgId, signedInUser)
-		if ok, err := guard.CanView(); err != nil || !ok {
-			return false, nil
-		}
+		return c.canAccessDashboard(ctx, orgId, signedInUser, dash, false)
 	case ObjectTypeAnnotation:
 		if !c.features.IsEnabled(featuremgmt.FlagAnnotationComments) {
 			return false, nil
 		}
-		annotationID, err := strconv.ParseInt(objectID, 10, 64)
-		if err != nil {
-			return false, nil
-		}
-		items, err := c.annotationsRepo.Find(ctx, &annotations.ItemQuery{AnnotationId: annotationID, OrgId: orgId, SignedInUser: signedInUser})
-		if err != nil || len(items) != 1 {
-			return false, nil
-		}
-		dashboardID := items[0].DashboardId
-		if dashboardID == 0 {
-			return false, nil
+		if !c.accessControl.IsDisabled() {
+			evaluator := accesscontrol.EvalPermission(accesscontrol.ActionAnnotationsRead, accesscontrol.ScopeAnnotationsTypeDashboard)
+			if canRead, err := c.accessControl.Evaluate(ctx, signedInUser, evaluator); err != nil || !canRead {
+				return canRead, err
+			}
 		}
-		dash, err := c.getDashboardById(ctx, orgId, dashboardID)
-		if err != nil {
+		dash, err := c.getAnnotationDashboard(ctx, orgId, signedInUser, objectID)
+		if err != nil || dash == nil {
 			return false, err
 		}
-		guard := guardian.New(ctx, dash.Id, orgId, signedInUser)
-		if ok, err := guard.CanView(); err != nil || !ok {
-			return false, nil
-		}
-	default:
-		return false, nil
+		return c.canAccessDashboard(ctx, orgId, signedInUser, dash, false)
 	}
-	return true, nil
+	return false, nil
 }
 
 func (c *PermissionChecker) CheckWritePermissions(ctx context.Context, orgId int64, signedInUser *user.SignedInUser, objectType string, objectID string) (bool, error) {
@@ -103,10 +94,7 @@ func (c *PermissionChecker) CheckWritePermissions(ctx context.Context, orgId int
 		if err != nil {
 			return false, err
 		}
-		guard := guardian.New(ctx, dash.Id, orgId, signedInUser)
-		if ok, err := guard.CanEdit(); err != nil || !ok {
-			return false, nil
-		}
+		return c.canAccessDashboard(ctx, orgId, signedInUser, dash, true)
 	case ObjectTypeAnnotation:
 		if !c.features.IsEnabled(featuremgmt.FlagAnnotationComments) {
 			return false, nil
@@ -117,28 +105,57 @@ func (c *PermissionChecker) CheckWritePermissions(ctx context.Context, orgId int
 				return canEdit, err
 			}
 		}
-		annotationID, err := strconv.ParseInt(objectID, 10, 64)
-		if err != nil {
-			return false, nil
-		}
-		items, err := c.annotationsRepo.Find(ctx, &annotations.ItemQuery{AnnotationId: annotationID, OrgId: orgId, SignedInUser: signedInUser})
-		if err != nil || len(items) != 1 {
-			return false, nil
-		}
-		dashboardID := items[0].DashboardId
-		if dashboardID == 0 {
-			return false, nil
-		}
-		dash, err := c.getDashboardById(ctx, orgId, dashboardID)
-		if err != nil {
+		dash, err := c.getAnnotationDashboard(ctx, orgId, signedInUser, objectID)
+		if err != nil || dash == nil {
 			return false, nil
 		}
+		return c.canAccessDashboard(ctx, orgId, signedInUser, dash, true)
+	}
+	return false, nil
+}
+
+// getAnnotationDashboard returns the dashboard of an annotation, or nil for the org annotations.
+func (c *PermissionChecker) getAnnotationDashboard(ctx context.Context, orgId int64, signedInUser *user.SignedInUser, objectID string) (*models.Dashboard, error) {
+	annotationID, err := strconv.ParseInt(objectID, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	items, err := c.annotationsRepo.Find(ctx, &annotations.ItemQuery{AnnotationId: annotationID, OrgId: orgId, SignedInUser: signedInUser})
+	if err != nil || len(items) != 1 {
+		return nil, nil
+	}
+	dashboardID := items[0].DashboardId
+	if dashboardID == 0 {
+		return nil, nil
+	}
+	return c.getDashboardById(ctx, orgId, dashboardID)
+}
+
+// canAccessDashboard evaluates the comment and dashboard permissions, the dashboard guardian is used
+// when access control is disabled.
+func (c *PermissionChecker) canAccessDashboard(ctx context.Context, orgId int64, signedInUser *user.SignedInUser, dash *models.Dashboard, write bool) (bool, error) {
+	if c.accessControl.IsDisabled() {
 		guard := guardian.New(ctx, dash.Id, orgId, signedInUser)
-		if ok, err := guard.CanEdit(); err != nil || !ok {
-			return false, nil
-		}
-	default:
-		return false, nil
+		var ok bool
+		var err error
+		if write {
+			ok, err = guard.CanEdit()
+		} else {
+			ok, err = guard.CanView()
+		}
+		return err == nil && ok, nil
+	}
+
+	scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dash.Uid)
+	evaluator := accesscontrol.EvalAll(
+		accesscontrol.EvalPermission(ActionCommentsRead),
+		accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, scope),
+	)
+	if write {
+		evaluator = accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(ActionCommentsWrite),
+			accesscontrol.EvalPermission(dashboards.ActionDashboardsWrite, scope),
+		)
 	}
-	return true, nil
+	return c.accessControl.Evaluate(ctx, signedInUser, evaluator)
 }
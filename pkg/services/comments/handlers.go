@@ -76,12 +76,36 @@ type GetCmd struct {
 	ObjectID   string `json:"objectId"`
 	Limit      uint   `json:"limit"`
 	BeforeId   int64  `json:"beforeId"`
+	// ParentId returns the replies of a comment.
+	ParentId int64 `json:"parentId"`
+	// PanelId returns the comments anchored to a panel of a dashboard.
+	PanelId int64 `json:"panelId"`
 }
 
 type CreateCmd struct {
 	ObjectType string `json:"objectType"`
 	ObjectID   string `json:"objectId"`
 	Content    string `json:"content"`
+	// ParentId is the comment to reply to.
+	ParentId int64 `json:"parentId"`
+	PanelId  int64 `json:"panelId"`
+	// TimeFrom and TimeTo anchor the comment to a time range, in epoch milliseconds.
+	TimeFrom int64 `json:"timeFrom"`
+	TimeTo   int64 `json:"timeTo"`
+}
+
+type UpdateCmd struct {
+	Id      int64  `json:"id"`
+	Content string `json:"content"`
+}
+
+type HistoryCmd struct {
+	Id int64 `json:"id"`
+}
+
+type ReactCmd struct {
+	Id       int64  `json:"id"`
+	Reaction string `json:"reaction"`
 }
 
 var ErrPermissionDenied = errors.New("permission denied")
@@ -95,33 +119,129 @@ func (s *Service) Create(ctx context.Context, orgID int64, signedInUser *user.Si
 		return nil, ErrPermissionDenied
 	}
 
-	userMap := make(map[int64]*commentmodel.CommentUser, 1)
-	if signedInUser.UserID > 0 {
-		userMap[signedInUser.UserID] = &commentmodel.CommentUser{
-			Id:        signedInUser.UserID,
-			Name:      signedInUser.Name,
-			Login:     signedInUser.Login,
-			Email:     signedInUser.Email,
-			AvatarUrl: dtos.GetGravatarUrl(signedInUser.Email),
-		}
-	}
-
-	m, err := s.storage.Create(ctx, orgID, cmd.ObjectType, cmd.ObjectID, signedInUser.UserID, cmd.Content)
+	m, err := s.storage.Create(ctx, orgID, cmd.ObjectType, cmd.ObjectID, signedInUser.UserID, cmd.Content, CreateOptions{
+		ParentID: cmd.ParentId,
+		PanelID:  cmd.PanelId,
+		TimeFrom: cmd.TimeFrom,
+		TimeTo:   cmd.TimeTo,
+	})
 	if err != nil {
 		return nil, err
 	}
-	mDto := commentToDto(m, userMap)
-	e := commentmodel.Event{
+	mDto := commentToDto(m, signedInUserMap(signedInUser))
+	s.publish(orgID, cmd.ObjectType, cmd.ObjectID, commentmodel.Event{
 		Event:          commentmodel.EventCommentCreated,
 		CommentCreated: mDto,
-	}
-	eventJSON, _ := json.Marshal(e)
-	_ = s.live.Publish(orgID, fmt.Sprintf("grafana/comment/%s/%s", cmd.ObjectType, cmd.ObjectID), eventJSON)
+	})
+	s.notifyMentionsAsync(ctx, orgID, signedInUser, cmd.ObjectType, cmd.ObjectID, m, "")
 	return mDto, nil
 }
 
+// Update changes the content of a comment of the user.
+func (s *Service) Update(ctx context.Context, orgID int64, signedInUser *user.SignedInUser, cmd UpdateCmd) (*commentmodel.CommentDto, error) {
+	comment, group, err := s.storage.GetByID(ctx, orgID, cmd.Id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserId != signedInUser.UserID || signedInUser.UserID == 0 {
+		return nil, ErrPermissionDenied
+	}
+	ok, err := s.permissions.CheckWritePermissions(ctx, orgID, signedInUser, group.ObjectType, group.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	previousContent := comment.Content
+	m, err := s.storage.Update(ctx, orgID, cmd.Id, cmd.Content)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.toDtos(ctx, signedInUser, []*commentmodel.Comment{m})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(orgID, group.ObjectType, group.ObjectId, commentmodel.Event{
+		Event:          commentmodel.EventCommentUpdated,
+		CommentUpdated: result[0],
+	})
+	s.notifyMentionsAsync(ctx, orgID, signedInUser, group.ObjectType, group.ObjectId, m, previousContent)
+	return result[0], nil
+}
+
+// History returns the previous contents of a comment, the most recent first.
+func (s *Service) History(ctx context.Context, orgID int64, signedInUser *user.SignedInUser, cmd HistoryCmd) ([]*commentmodel.CommentHistoryDto, error) {
+	_, group, err := s.storage.GetByID(ctx, orgID, cmd.Id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.permissions.CheckReadPermissions(ctx, orgID, signedInUser, group.ObjectType, group.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	items, err := s.storage.GetHistory(ctx, orgID, cmd.Id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*commentmodel.CommentHistoryDto, 0, len(items))
+	for _, item := range items {
+		result = append(result, &commentmodel.CommentHistoryDto{Content: item.Content, Created: item.Created})
+	}
+	return result, nil
+}
+
+// React adds a reaction of the user to a comment, the users who can read the comments can react.
+func (s *Service) React(ctx context.Context, orgID int64, signedInUser *user.SignedInUser, cmd ReactCmd) (*commentmodel.CommentDto, error) {
+	return s.updateReaction(ctx, orgID, signedInUser, cmd, s.storage.AddReaction)
+}
+
+// Unreact removes a reaction of the user from a comment.
+func (s *Service) Unreact(ctx context.Context, orgID int64, signedInUser *user.SignedInUser, cmd ReactCmd) (*commentmodel.CommentDto, error) {
+	return s.updateReaction(ctx, orgID, signedInUser, cmd, s.storage.RemoveReaction)
+}
+
+func (s *Service) updateReaction(ctx context.Context, orgID int64, signedInUser *user.SignedInUser, cmd ReactCmd,
+	update func(ctx context.Context, orgID int64, id int64, userID int64, reaction string) error) (*commentmodel.CommentDto, error) {
+	if signedInUser.UserID == 0 {
+		return nil, ErrPermissionDenied
+	}
+	_, group, err := s.storage.GetByID(ctx, orgID, cmd.Id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.permissions.CheckReadPermissions(ctx, orgID, signedInUser, group.ObjectType, group.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	if err := update(ctx, orgID, cmd.Id, signedInUser.UserID, cmd.Reaction); err != nil {
+		return nil, err
+	}
+	m, _, err := s.storage.GetByID(ctx, orgID, cmd.Id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.toDtos(ctx, signedInUser, []*commentmodel.Comment{m})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(orgID, group.ObjectType, group.ObjectId, commentmodel.Event{
+		Event:          commentmodel.EventCommentUpdated,
+		CommentUpdated: result[0],
+	})
+	return result[0], nil
+}
+
 func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *user.SignedInUser, cmd GetCmd) ([]*commentmodel.CommentDto, error) {
-	var res *user.SearchUserQueryResult
 	ok, err := s.permissions.CheckReadPermissions(ctx, orgID, signedInUser, cmd.ObjectType, cmd.ObjectID)
 	if err != nil {
 		return nil, err
@@ -133,13 +253,32 @@ func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *user.Signe
 	messages, err := s.storage.Get(ctx, orgID, cmd.ObjectType, cmd.ObjectID, GetFilter{
 		Limit:    cmd.Limit,
 		BeforeID: cmd.BeforeId,
+		ParentID: cmd.ParentId,
+		PanelID:  cmd.PanelId,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	result, err := s.toDtos(ctx, signedInUser, messages)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result, nil
+}
+
+// toDtos adds the users, the reply counts and the reactions to the comments.
+func (s *Service) toDtos(ctx context.Context, signedInUser *user.SignedInUser, messages []*commentmodel.Comment) ([]*commentmodel.CommentDto, error) {
+	var res *user.SearchUserQueryResult
+	var err error
+
 	userIds := make([]int64, 0, len(messages))
+	ids := make([]int64, 0, len(messages))
 	for _, m := range messages {
+		ids = append(ids, m.Id)
 		if m.UserId <= 0 {
 			continue
 		}
@@ -163,9 +302,57 @@ func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *user.Signe
 		userMap[v.ID] = searchUserToCommentUser(v)
 	}
 
+	replyCounts, err := s.storage.GetReplyCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	reactions, err := s.storage.GetReactions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
 	result := commentsToDto(messages, userMap)
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Id < result[j].Id
-	})
+	byID := make(map[int64]*commentmodel.CommentDto, len(result))
+	for _, dto := range result {
+		dto.ReplyCount = replyCounts[dto.Id]
+		byID[dto.Id] = dto
+	}
+	for _, r := range reactions {
+		dto, ok := byID[r.CommentId]
+		if !ok {
+			continue
+		}
+		dto.Reactions = addReaction(dto.Reactions, r)
+	}
 	return result, nil
 }
+
+// addReaction groups the reactions in the order they were first added.
+func addReaction(reactions []*commentmodel.CommentReactionDto, r *commentmodel.CommentReaction) []*commentmodel.CommentReactionDto {
+	for _, reaction := range reactions {
+		if reaction.Reaction == r.Reaction {
+			reaction.UserIds = append(reaction.UserIds, r.UserId)
+			return reactions
+		}
+	}
+	return append(reactions, &commentmodel.CommentReactionDto{Reaction: r.Reaction, UserIds: []int64{r.UserId}})
+}
+
+func signedInUserMap(signedInUser *user.SignedInUser) map[int64]*commentmodel.CommentUser {
+	userMap := make(map[int64]*commentmodel.CommentUser, 1)
+	if signedInUser.UserID > 0 {
+		userMap[signedInUser.UserID] = &commentmodel.CommentUser{
+			Id:        signedInUser.UserID,
+			Name:      signedInUser.Name,
+			Login:     signedInUser.Login,
+			Email:     signedInUser.Email,
+			AvatarUrl: dtos.GetGravatarUrl(signedInUser.Email),
+		}
+	}
+	return userMap
+}
+
+func (s *Service) publish(orgID int64, objectType string, objectID string, e commentmodel.Event) {
+	eventJSON, _ := json.Marshal(e)
+	_ = s.live.Publish(orgID, fmt.Sprintf("grafana/comment/%s/%s", objectType, objectID), eventJSON)
+}
@@ -0,0 +1,164 @@
+package comments
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	mentionEmailTemplate = "comment_mention"
+	// maxMentions limits the logins and team names looked up for a comment.
+	maxMentions = 20
+	// maxMentionRecipients limits the users notified for a comment, the members of the mentioned teams included.
+	maxMentionRecipients = 50
+	// mentionNotificationTimeout limits the time spent notifying the mentions of a comment.
+	mentionNotificationTimeout = time.Minute
+)
+
+// mentionRegexp matches @login and @team, logins can be emails.
+var mentionRegexp = regexp.MustCompile(`(?:^|\s)@([\w.\-+@]+)`)
+
+// parseMentions returns the mentioned logins and team names in the order of the content.
+func parseMentions(content string) []string {
+	names := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, match := range mentionRegexp.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+		if len(names) == maxMentions {
+			break
+		}
+	}
+	return names
+}
+
+// notifyMentionsAsync notifies the mentions of a comment in the background, the request does not wait for the emails.
+func (s *Service) notifyMentionsAsync(ctx context.Context, orgID int64, author *user.SignedInUser, objectType string, objectID string, comment *commentmodel.Comment, previousContent string) {
+	ctx, cancel := context.WithTimeout(util.WithoutCancel(ctx), mentionNotificationTimeout)
+	go func() {
+		defer cancel()
+		s.notifyMentions(ctx, orgID, author, objectType, objectID, comment, previousContent)
+	}()
+}
+
+// notifyMentions emails the users and team members mentioned in a comment who can read it.  When the
+// comment is edited, only the new mentions are notified.  Failures are logged, the comment is saved.
+func (s *Service) notifyMentions(ctx context.Context, orgID int64, author *user.SignedInUser, objectType string, objectID string, comment *commentmodel.Comment, previousContent string) {
+	names := parseMentions(comment.Content)
+	if previousContent != "" {
+		previous := make(map[string]struct{})
+		for _, name := range parseMentions(previousContent) {
+			previous[name] = struct{}{}
+		}
+		newNames := make([]string, 0, len(names))
+		for _, name := range names {
+			if _, ok := previous[name]; !ok {
+				newNames = append(newNames, name)
+			}
+		}
+		names = newNames
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	// one more user is looked up as the author is not notified
+	userIDs, err := s.storage.GetMentionedUserIDs(ctx, orgID, names, maxMentionRecipients+1)
+	if err != nil {
+		s.log.Warn("Failed to find mentioned users", "commentId", comment.Id, "error", err)
+		return
+	}
+
+	authorName := author.Name
+	if authorName == "" {
+		authorName = author.Login
+	}
+	notified := 0
+	for _, userID := range userIDs {
+		if userID == author.UserID {
+			continue
+		}
+		if notified == maxMentionRecipients {
+			s.log.Warn("Too many mentioned users, not notifying the others", "commentId", comment.Id, "limit", maxMentionRecipients)
+			break
+		}
+		notified++
+		if err := s.notifyMention(ctx, orgID, userID, authorName, objectType, objectID, comment); err != nil {
+			s.log.Warn("Failed to notify mentioned user", "commentId", comment.Id, "userId", userID, "error", err)
+		}
+	}
+}
+
+func (s *Service) notifyMention(ctx context.Context, orgID int64, userID int64, authorName string, objectType string, objectID string, comment *commentmodel.Comment) error {
+	mentioned, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: userID, OrgID: orgID})
+	if err != nil {
+		return err
+	}
+	if mentioned.Email == "" || mentioned.IsDisabled {
+		return nil
+	}
+
+	if !s.acService.IsDisabled() {
+		permissions, err := s.acService.GetUserPermissions(ctx, mentioned, accesscontrol.Options{})
+		if err != nil {
+			return err
+		}
+		mentioned.Permissions = map[int64]map[string][]string{orgID: accesscontrol.GroupScopesByAction(permissions)}
+	}
+	ok, err := s.permissions.CheckReadPermissions(ctx, orgID, mentioned, objectType, objectID)
+	if err != nil || !ok {
+		return err
+	}
+
+	name := mentioned.Name
+	if name == "" {
+		name = mentioned.Login
+	}
+	return s.notifications.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
+		To:       []string{mentioned.Email},
+		Template: mentionEmailTemplate,
+		Data: map[string]interface{}{
+			"Name":       name,
+			"AuthorName": authorName,
+			"Content":    comment.Content,
+			"Link":       s.commentLink(objectType, objectID, comment),
+		},
+	})
+}
+
+// commentLink links the dashboard comments to the dashboard, with the panel and the time range of the anchors.
+func (s *Service) commentLink(objectType string, objectID string, comment *commentmodel.Comment) string {
+	if objectType != commentmodel.ObjectTypeDashboard {
+		return ""
+	}
+	params := url.Values{}
+	if comment.PanelId > 0 {
+		params.Set("viewPanel", strconv.FormatInt(comment.PanelId, 10))
+	}
+	if comment.TimeFrom > 0 {
+		params.Set("from", strconv.FormatInt(comment.TimeFrom, 10))
+		params.Set("to", strconv.FormatInt(comment.TimeTo, 10))
+	}
+	link := s.cfg.AppURL + "d/" + url.PathEscape(objectID)
+	if len(params) > 0 {
+		link += "?" + params.Encode()
+	}
+	return link
+}
@@ -0,0 +1,173 @@
+package comments
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestParseMentions(t *testing.T) {
+	testCases := []struct {
+		content  string
+		expected []string
+	}{
+		{content: "no mentions", expected: []string{}},
+		{content: "@alice look at this", expected: []string{"alice"}},
+		{content: "cc @alice, @bob. and @alice again", expected: []string{"alice", "bob"}},
+		{content: "ask @bob.", expected: []string{"bob"}},
+		{content: "mail@example.com is not a mention", expected: []string{}},
+		{content: "@alice@example.com and\n@team-a", expected: []string{"alice@example.com", "team-a"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.content, func(t *testing.T) {
+			require.Equal(t, tc.expected, parseMentions(tc.content))
+		})
+	}
+}
+
+func TestCommentLink(t *testing.T) {
+	s := &Service{cfg: &setting.Cfg{AppURL: "http://localhost:3000/"}}
+
+	link := s.commentLink(commentmodel.ObjectTypeDashboard, "dash", &commentmodel.Comment{})
+	require.Equal(t, "http://localhost:3000/d/dash", link)
+
+	link = s.commentLink(commentmodel.ObjectTypeDashboard, "dash", &commentmodel.Comment{PanelId: 2, TimeFrom: 1000, TimeTo: 2000})
+	require.Equal(t, "http://localhost:3000/d/dash?from=1000&to=2000&viewPanel=2", link)
+
+	link = s.commentLink(commentmodel.ObjectTypeAnnotation, "1", &commentmodel.Comment{})
+	require.Equal(t, "", link)
+}
+
+// fakeUserService returns the signed in users by id.
+type fakeUserService struct {
+	*usertest.FakeUserService
+	users map[int64]*user.SignedInUser
+}
+
+func (f *fakeUserService) GetSignedInUser(_ context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error) {
+	u, ok := f.users[query.UserID]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	// the permissions are set on a copy, like the real service
+	copied := *u
+	return &copied, nil
+}
+
+func TestIntegrationNotifyMentions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	// the users are added to the org with their login
+	sqlStore.Cfg.AutoAssignOrg = true
+	sqlStore.Cfg.AutoAssignOrgRole = string(org.RoleViewer)
+	orgCmd := &models.CreateOrgCommand{Name: "test"}
+	require.NoError(t, sqlStore.CreateOrg(ctx, orgCmd))
+	orgID := orgCmd.Result.Id
+
+	users := make(map[int64]*user.SignedInUser)
+	ids := make(map[string]int64)
+	for _, login := range []string{"alice", "bob", "carol", "dave"} {
+		u, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: login, Email: login + "@example.com", OrgID: orgID})
+		require.NoError(t, err)
+		users[u.ID] = &user.SignedInUser{UserID: u.ID, OrgID: orgID, Login: login, Email: u.Email}
+		ids[login] = u.ID
+	}
+	teamService := teamimpl.ProvideService(sqlStore, sqlStore.Cfg)
+	team, err := teamService.CreateTeam("team-a", "", orgID)
+	require.NoError(t, err)
+	for _, login := range []string{"bob", "dave"} {
+		require.NoError(t, teamService.AddTeamMember(ids[login], orgID, team.Id, false, models.PERMISSION_VIEW))
+	}
+
+	// carol can not read the comments of the dashboard
+	ac := accesscontrolmock.New()
+	ac.GetUserPermissionsFunc = func(_ context.Context, u *user.SignedInUser, _ accesscontrol.Options) ([]accesscontrol.Permission, error) {
+		if u.UserID == ids["carol"] {
+			return []accesscontrol.Permission{}, nil
+		}
+		return []accesscontrol.Permission{
+			{Action: commentmodel.ActionCommentsRead},
+			{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:dash"},
+		}, nil
+	}
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.GetDashboardQuery).Result = &models.Dashboard{Id: 1, Uid: "dash", OrgId: orgID}
+	}).Return(nil)
+
+	var sent []string
+	notificationService := notifications.MockNotificationService()
+	notificationService.EmailHandler = func(_ context.Context, cmd *models.SendEmailCommand) error {
+		require.Equal(t, mentionEmailTemplate, cmd.Template)
+		sent = append(sent, cmd.To...)
+		return nil
+	}
+
+	s := &Service{
+		cfg:           setting.NewCfg(),
+		sqlStore:      sqlStore,
+		storage:       &sqlStorage{sql: sqlStore},
+		permissions:   commentmodel.NewPermissionChecker(sqlStore, featuremgmt.WithFeatures(featuremgmt.FlagDashboardComments), ac, dashboardService, nil),
+		userService:   &fakeUserService{FakeUserService: usertest.NewUserServiceFake(), users: users},
+		acService:     ac,
+		notifications: notificationService,
+		log:           log.NewNopLogger(),
+	}
+	author := users[ids["alice"]]
+
+	comment := &commentmodel.Comment{Id: 1, Content: "@alice @bob @carol @team-a @unknown"}
+	s.notifyMentions(ctx, orgID, author, commentmodel.ObjectTypeDashboard, "dash", comment, "")
+	sort.Strings(sent)
+	require.Equal(t, []string{"bob@example.com", "dave@example.com"}, sent)
+
+	// only the new mentions are notified after an edit
+	sent = nil
+	comment.Content = "@bob @dave"
+	s.notifyMentions(ctx, orgID, author, commentmodel.ObjectTypeDashboard, "dash", comment, "@bob")
+	require.Equal(t, []string{"dave@example.com"}, sent)
+
+	// the mentioned users are kept first when the team members exceed the limit
+	userIDs, err := s.storage.GetMentionedUserIDs(ctx, orgID, []string{"team-a", "carol"}, 2)
+	require.NoError(t, err)
+	require.Equal(t, []int64{ids["carol"], ids["bob"]}, userIDs)
+
+	// the mentions are notified in the background, after the end of the request
+	emails := make(chan string, 1)
+	notificationService.EmailHandler = func(_ context.Context, cmd *models.SendEmailCommand) error {
+		emails <- cmd.To[0]
+		return nil
+	}
+	requestCtx, cancel := context.WithCancel(ctx)
+	s.notifyMentionsAsync(requestCtx, orgID, author, commentmodel.ObjectTypeDashboard, "dash", &commentmodel.Comment{Id: 2, Content: "@bob"}, "")
+	cancel()
+	select {
+	case email := <-emails:
+		require.Equal(t, "bob@example.com", email)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the mentioned user was not notified")
+	}
+}
@@ -0,0 +1,39 @@
+package comments
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// RegisterRoles declares the comment roles. The dashboard and annotation permissions are
+// still required to read or write the comments of an object.
+func RegisterRoles(service accesscontrol.Service) error {
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:comments:reader",
+			DisplayName: "Comments reader",
+			Description: "Read the comments of the dashboards and annotations, and react to them.",
+			Group:       "Comments",
+			Permissions: []accesscontrol.Permission{
+				{Action: commentmodel.ActionCommentsRead},
+			},
+		},
+		Grants: []string{string(org.RoleViewer)},
+	}
+
+	writer := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:comments:writer",
+			DisplayName: "Comments writer",
+			Description: "Read, create, reply to and edit the comments of the dashboards and annotations.",
+			Group:       "Comments",
+			Permissions: accesscontrol.ConcatPermissions(reader.Role.Permissions, []accesscontrol.Permission{
+				{Action: commentmodel.ActionCommentsWrite},
+			}),
+		},
+		Grants: []string{string(org.RoleEditor)},
+	}
+
+	return service.DeclareFixedRoles(reader, writer)
+}
@@ -3,29 +3,35 @@ package comments
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 type Service struct {
-	cfg         *setting.Cfg
-	live        *live.GrafanaLive
-	sqlStore    *sqlstore.SQLStore
-	storage     Storage
-	permissions *commentmodel.PermissionChecker
-	userService user.Service
+	cfg           *setting.Cfg
+	live          *live.GrafanaLive
+	sqlStore      *sqlstore.SQLStore
+	storage       Storage
+	permissions   *commentmodel.PermissionChecker
+	userService   user.Service
+	acService     accesscontrol.Service
+	notifications notifications.EmailSender
+	log           log.Logger
 }
 
 func ProvideService(cfg *setting.Cfg, store *sqlstore.SQLStore, live *live.GrafanaLive,
-	features featuremgmt.FeatureToggles, accessControl accesscontrol.AccessControl,
-	dashboardService dashboards.DashboardService, userService user.Service, annotationsRepo annotations.Repository) *Service {
+	features featuremgmt.FeatureToggles, accessControl accesscontrol.AccessControl, acService accesscontrol.Service,
+	dashboardService dashboards.DashboardService, userService user.Service, annotationsRepo annotations.Repository,
+	notificationService notifications.Service) (*Service, error) {
 	s := &Service{
 		cfg:      cfg,
 		live:     live,
@@ -33,10 +39,16 @@ func ProvideService(cfg *setting.Cfg, store *sqlstore.SQLStore, live *live.Grafa
 		storage: &sqlStorage{
 			sql: store,
 		},
-		permissions: commentmodel.NewPermissionChecker(store, features, accessControl, dashboardService, annotationsRepo),
-		userService: userService,
+		permissions:   commentmodel.NewPermissionChecker(store, features, accessControl, dashboardService, annotationsRepo),
+		userService:   userService,
+		acService:     acService,
+		notifications: notificationService,
+		log:           log.New("comments"),
 	}
-	return s
+	if err := RegisterRoles(acService); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 // Run Service.
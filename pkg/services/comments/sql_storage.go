@@ -2,6 +2,7 @@ package comments
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
@@ -21,7 +22,25 @@ func checkObjectID(objectID string) bool {
 	return objectID != ""
 }
 
-func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string) (*commentmodel.Comment, error) {
+// checkAnchors allows panel and time range anchors on the top level dashboard comments.
+func checkAnchors(objectType string, opts CreateOptions) bool {
+	if opts.PanelID == 0 && opts.TimeFrom == 0 && opts.TimeTo == 0 {
+		return true
+	}
+	if opts.ParentID != 0 || objectType != commentmodel.ObjectTypeDashboard || opts.PanelID < 0 {
+		return false
+	}
+	if opts.TimeFrom != 0 || opts.TimeTo != 0 {
+		return opts.TimeFrom > 0 && opts.TimeFrom <= opts.TimeTo
+	}
+	return true
+}
+
+func checkReaction(reaction string) bool {
+	return reaction != "" && len(reaction) <= 32 && !strings.ContainsAny(reaction, " \t\n")
+}
+
+func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string, opts CreateOptions) (*commentmodel.Comment, error) {
 	if !checkObjectType(objectType) {
 		return nil, errUnknownObjectType
 	}
@@ -31,6 +50,9 @@ func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string,
 	if content == "" {
 		return nil, errEmptyContent
 	}
+	if !checkAnchors(objectType, opts) {
+		return nil, errInvalidAnchor
+	}
 
 	var result *commentmodel.Comment
 
@@ -47,6 +69,18 @@ func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string,
 		nowUnix := time.Now().Unix()
 
 		groupID := group.Id
+		if opts.ParentID != 0 {
+			if !has {
+				return errInvalidParent
+			}
+			parent, err := dbSession.Where("id=? AND group_id=? AND parent_id=0", opts.ParentID, groupID).Exist(&commentmodel.Comment{})
+			if err != nil {
+				return err
+			}
+			if !parent {
+				return errInvalidParent
+			}
+		}
 		if !has {
 			group.OrgId = orgID
 			group.ObjectType = objectType
@@ -61,11 +95,15 @@ func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string,
 			groupID = group.Id
 		}
 		message := commentmodel.Comment{
-			GroupId: groupID,
-			UserId:  userID,
-			Content: content,
-			Created: nowUnix,
-			Updated: nowUnix,
+			GroupId:  groupID,
+			UserId:   userID,
+			Content:  content,
+			ParentId: opts.ParentID,
+			PanelId:  opts.PanelID,
+			TimeFrom: opts.TimeFrom,
+			TimeTo:   opts.TimeTo,
+			Created:  nowUnix,
+			Updated:  nowUnix,
 		}
 		_, err = dbSession.Insert(&message)
 		if err != nil {
@@ -108,10 +146,207 @@ func (s *sqlStorage) Get(ctx context.Context, orgID int64, objectType string, ob
 		if !has {
 			return nil
 		}
-		clause := dbSession.Where("group_id=?", group.Id)
+		clause := dbSession.Where("group_id=? AND parent_id=?", group.Id, filter.ParentID)
+		if filter.ParentID == 0 && filter.PanelID > 0 {
+			clause.Where("panel_id=?", filter.PanelID)
+		}
 		if filter.BeforeID > 0 {
 			clause.Where("id < ?", filter.BeforeID)
 		}
 		return clause.OrderBy("id desc").Limit(limit).Find(&result)
 	})
 }
+
+func getComment(dbSession *sqlstore.DBSession, orgID int64, id int64) (*commentmodel.Comment, *commentmodel.CommentGroup, error) {
+	var comment commentmodel.Comment
+	has, err := dbSession.ID(id).Get(&comment)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !has {
+		return nil, nil, ErrCommentNotFound
+	}
+
+	var group commentmodel.CommentGroup
+	has, err = dbSession.NoAutoCondition().Where("id=? AND org_id=?", comment.GroupId, orgID).Get(&group)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !has {
+		return nil, nil, ErrCommentNotFound
+	}
+	return &comment, &group, nil
+}
+
+func (s *sqlStorage) GetByID(ctx context.Context, orgID int64, id int64) (*commentmodel.Comment, *commentmodel.CommentGroup, error) {
+	var comment *commentmodel.Comment
+	var group *commentmodel.CommentGroup
+	err := s.sql.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var err error
+		comment, group, err = getComment(dbSession, orgID, id)
+		return err
+	})
+	return comment, group, err
+}
+
+func (s *sqlStorage) Update(ctx context.Context, orgID int64, id int64, content string) (*commentmodel.Comment, error) {
+	if content == "" {
+		return nil, errEmptyContent
+	}
+
+	var result *commentmodel.Comment
+
+	return result, s.sql.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		comment, _, err := getComment(dbSession, orgID, id)
+		if err != nil {
+			return err
+		}
+		if comment.Content == content {
+			result = comment
+			return nil
+		}
+
+		nowUnix := time.Now().Unix()
+		_, err = dbSession.Insert(&commentmodel.CommentHistory{
+			CommentId: comment.Id,
+			Content:   comment.Content,
+			Created:   nowUnix,
+		})
+		if err != nil {
+			return err
+		}
+
+		comment.Content = content
+		comment.Updated = nowUnix
+		if _, err := dbSession.ID(comment.Id).Cols("content", "updated").Update(comment); err != nil {
+			return err
+		}
+		result = comment
+		return nil
+	})
+}
+
+func (s *sqlStorage) GetHistory(ctx context.Context, orgID int64, id int64) ([]*commentmodel.CommentHistory, error) {
+	var result []*commentmodel.CommentHistory
+
+	return result, s.sql.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, _, err := getComment(dbSession, orgID, id); err != nil {
+			return err
+		}
+		return dbSession.Where("comment_id=?", id).OrderBy("id desc").Find(&result)
+	})
+}
+
+func (s *sqlStorage) AddReaction(ctx context.Context, orgID int64, id int64, userID int64, reaction string) error {
+	if !checkReaction(reaction) {
+		return errInvalidReaction
+	}
+
+	return s.sql.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, _, err := getComment(dbSession, orgID, id); err != nil {
+			return err
+		}
+		has, err := dbSession.Where("comment_id=? AND user_id=? AND reaction=?", id, userID, reaction).Exist(&commentmodel.CommentReaction{})
+		if err != nil || has {
+			return err
+		}
+		_, err = dbSession.Insert(&commentmodel.CommentReaction{
+			CommentId: id,
+			UserId:    userID,
+			Reaction:  reaction,
+			Created:   time.Now().Unix(),
+		})
+		return err
+	})
+}
+
+func (s *sqlStorage) RemoveReaction(ctx context.Context, orgID int64, id int64, userID int64, reaction string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, _, err := getComment(dbSession, orgID, id); err != nil {
+			return err
+		}
+		_, err := dbSession.Where("comment_id=? AND user_id=? AND reaction=?", id, userID, reaction).Delete(&commentmodel.CommentReaction{})
+		return err
+	})
+}
+
+func (s *sqlStorage) GetReactions(ctx context.Context, ids []int64) ([]*commentmodel.CommentReaction, error) {
+	result := make([]*commentmodel.CommentReaction, 0)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	return result, s.sql.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		return dbSession.In("comment_id", ids).OrderBy("id asc").Find(&result)
+	})
+}
+
+func (s *sqlStorage) GetReplyCounts(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	result := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	type replyCount struct {
+		ParentId int64
+		Count    int64
+	}
+	var counts []replyCount
+	err := s.sql.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		return dbSession.Table("comment").Select("parent_id, COUNT(*) AS count").In("parent_id", ids).GroupBy("parent_id").Find(&counts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		result[c.ParentId] = c.Count
+	}
+	return result, nil
+}
+
+func (s *sqlStorage) GetMentionedUserIDs(ctx context.Context, orgID int64, names []string, limit int) ([]int64, error) {
+	if len(names) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	params := make([]interface{}, 0, len(names)+1)
+	params = append(params, orgID)
+	for _, name := range names {
+		params = append(params, name)
+	}
+	in := "?" + strings.Repeat(",?", len(names)-1)
+
+	var userIDs, memberIDs []int64
+	err := s.sql.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		err := dbSession.SQL(`SELECT u.id FROM `+s.sql.Dialect.Quote("user")+` AS u
+			INNER JOIN org_user AS ou ON ou.user_id = u.id
+			WHERE ou.org_id = ? AND u.login IN (`+in+`)
+			ORDER BY u.id`+s.sql.Dialect.Limit(int64(limit)), params...).Find(&userIDs)
+		if err != nil {
+			return err
+		}
+		// the mentioned users can also be members of the teams
+		return dbSession.SQL(`SELECT DISTINCT tm.user_id FROM team_member AS tm
+			INNER JOIN team AS t ON t.id = tm.team_id
+			WHERE t.org_id = ? AND t.name IN (`+in+`)
+			ORDER BY tm.user_id`+s.sql.Dialect.Limit(int64(limit+len(userIDs))), params...).Find(&memberIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the mentioned users are kept first when the members of the teams exceed the limit
+	seen := make(map[int64]struct{}, len(userIDs)+len(memberIDs))
+	result := make([]int64, 0, len(userIDs)+len(memberIDs))
+	for _, id := range append(userIDs, memberIDs...) {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+		if len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
@@ -29,7 +29,7 @@ func TestSqlStorage(t *testing.T) {
 	numComments := 10
 
 	for i := 0; i < numComments; i++ {
-		comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, "test"+strconv.Itoa(i))
+		comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, "test"+strconv.Itoa(i), CreateOptions{})
 		require.NoError(t, err)
 		require.NotNil(t, comment)
 		require.True(t, comment.Id > 0)
@@ -74,3 +74,123 @@ func TestSqlStorage(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, items, 0)
 }
+
+func TestSqlStorageThreads(t *testing.T) {
+	s := createSqlStorage(t)
+	ctx := context.Background()
+
+	parent, err := s.Create(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", 1, "parent", CreateOptions{PanelID: 2, TimeFrom: 1000, TimeTo: 2000})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), parent.PanelId)
+	_, err = s.Create(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", 1, "other", CreateOptions{})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.Create(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", 2, "reply"+strconv.Itoa(i), CreateOptions{ParentID: parent.Id})
+		require.NoError(t, err)
+	}
+
+	items, err := s.Get(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", GetFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	items, err = s.Get(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", GetFilter{PanelID: 2})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, "parent", items[0].Content)
+
+	items, err = s.Get(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", GetFilter{ParentID: parent.Id, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, "reply2", items[0].Content)
+	require.Equal(t, parent.Id, items[0].ParentId)
+
+	counts, err := s.GetReplyCounts(ctx, []int64{parent.Id, items[0].Id})
+	require.NoError(t, err)
+	require.Equal(t, map[int64]int64{parent.Id: 3}, counts)
+
+	t.Run("replies only reply to top level comments of the object", func(t *testing.T) {
+		_, err := s.Create(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", 1, "nested", CreateOptions{ParentID: items[0].Id})
+		require.ErrorIs(t, err, errInvalidParent)
+		_, err = s.Create(ctx, 1, commentmodel.ObjectTypeDashboard, "other", 1, "other object", CreateOptions{ParentID: parent.Id})
+		require.ErrorIs(t, err, errInvalidParent)
+	})
+
+	t.Run("anchors are validated", func(t *testing.T) {
+		for _, opts := range []CreateOptions{
+			{ParentID: parent.Id, PanelID: 2},
+			{TimeFrom: 2000, TimeTo: 1000},
+			{TimeFrom: 1000},
+			{PanelID: -1},
+		} {
+			_, err := s.Create(ctx, 1, commentmodel.ObjectTypeDashboard, "dash", 1, "anchored", opts)
+			require.ErrorIs(t, err, ErrInvalidComment)
+		}
+		_, err := s.Create(ctx, 1, commentmodel.ObjectTypeAnnotation, "1", 1, "anchored", CreateOptions{PanelID: 2})
+		require.ErrorIs(t, err, errInvalidAnchor)
+	})
+}
+
+func TestSqlStorageUpdate(t *testing.T) {
+	s := createSqlStorage(t)
+	ctx := context.Background()
+
+	comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, "first", CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = s.Update(ctx, 1, comment.Id, "second")
+	require.NoError(t, err)
+	updated, err := s.Update(ctx, 1, comment.Id, "third")
+	require.NoError(t, err)
+	require.Equal(t, "third", updated.Content)
+
+	// same content is not saved in the history
+	_, err = s.Update(ctx, 1, comment.Id, "third")
+	require.NoError(t, err)
+
+	history, err := s.GetHistory(ctx, 1, comment.Id)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, "second", history[0].Content)
+	require.Equal(t, "first", history[1].Content)
+
+	found, group, err := s.GetByID(ctx, 1, comment.Id)
+	require.NoError(t, err)
+	require.Equal(t, "third", found.Content)
+	require.Equal(t, "2", group.ObjectId)
+
+	_, err = s.Update(ctx, 1, comment.Id, "")
+	require.ErrorIs(t, err, errEmptyContent)
+
+	// comments of the other orgs are not found
+	_, err = s.Update(ctx, 2, comment.Id, "other org")
+	require.ErrorIs(t, err, ErrCommentNotFound)
+	_, _, err = s.GetByID(ctx, 2, comment.Id)
+	require.ErrorIs(t, err, ErrCommentNotFound)
+}
+
+func TestSqlStorageReactions(t *testing.T) {
+	s := createSqlStorage(t)
+	ctx := context.Background()
+
+	comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, "comment", CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.AddReaction(ctx, 1, comment.Id, 1, "+1"))
+	require.NoError(t, s.AddReaction(ctx, 1, comment.Id, 1, "+1"))
+	require.NoError(t, s.AddReaction(ctx, 1, comment.Id, 2, "+1"))
+	require.NoError(t, s.AddReaction(ctx, 1, comment.Id, 2, "eyes"))
+	require.ErrorIs(t, s.AddReaction(ctx, 1, comment.Id, 2, "two words"), errInvalidReaction)
+	require.ErrorIs(t, s.AddReaction(ctx, 2, comment.Id, 2, "+1"), ErrCommentNotFound)
+
+	reactions, err := s.GetReactions(ctx, []int64{comment.Id})
+	require.NoError(t, err)
+	require.Len(t, reactions, 3)
+
+	require.NoError(t, s.RemoveReaction(ctx, 1, comment.Id, 2, "+1"))
+	reactions, err = s.GetReactions(ctx, []int64{comment.Id})
+	require.NoError(t, err)
+	require.Len(t, reactions, 2)
+	require.Equal(t, int64(1), reactions[0].UserId)
+	require.Equal(t, "eyes", reactions[1].Reaction)
+}
@@ -3,6 +3,7 @@ package comments
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
 )
@@ -10,15 +11,47 @@ import (
 type GetFilter struct {
 	Limit    uint
 	BeforeID int64
+	// ParentID returns the replies of a comment instead of the top level comments.
+	ParentID int64
+	// PanelID returns the top level comments anchored to a panel.
+	PanelID int64
+}
+
+// CreateOptions are the thread and the anchors of a new comment.
+type CreateOptions struct {
+	ParentID int64
+	PanelID  int64
+	TimeFrom int64
+	TimeTo   int64
 }
 
 var (
-	errUnknownObjectType = errors.New("unknown object type")
-	errEmptyObjectID     = errors.New("empty object id")
-	errEmptyContent      = errors.New("empty comment content")
+	// ErrInvalidComment is wrapped by the errors of the invalid requests.
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrCommentNotFound is returned when the comment does not exist in the org.
+	ErrCommentNotFound = errors.New("comment not found")
+
+	errUnknownObjectType = fmt.Errorf("%w: unknown object type", ErrInvalidComment)
+	errEmptyObjectID     = fmt.Errorf("%w: empty object id", ErrInvalidComment)
+	errEmptyContent      = fmt.Errorf("%w: empty comment content", ErrInvalidComment)
+	errInvalidParent     = fmt.Errorf("%w: replies must reply to a top level comment of the same object", ErrInvalidComment)
+	errInvalidAnchor     = fmt.Errorf("%w: only top level dashboard comments can be anchored, with a valid time range", ErrInvalidComment)
+	errInvalidReaction   = fmt.Errorf("%w: reactions must have between 1 and 32 characters without spaces", ErrInvalidComment)
 )
 
 type Storage interface {
 	Get(ctx context.Context, orgID int64, objectType string, objectID string, filter GetFilter) ([]*commentmodel.Comment, error)
-	Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string) (*commentmodel.Comment, error)
+	Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string, opts CreateOptions) (*commentmodel.Comment, error)
+	// GetByID returns the comment with the group of its object.
+	GetByID(ctx context.Context, orgID int64, id int64) (*commentmodel.Comment, *commentmodel.CommentGroup, error)
+	// Update replaces the content of a comment and keeps the previous content in its history.
+	Update(ctx context.Context, orgID int64, id int64, content string) (*commentmodel.Comment, error)
+	GetHistory(ctx context.Context, orgID int64, id int64) ([]*commentmodel.CommentHistory, error)
+	AddReaction(ctx context.Context, orgID int64, id int64, userID int64, reaction string) error
+	RemoveReaction(ctx context.Context, orgID int64, id int64, userID int64, reaction string) error
+	GetReactions(ctx context.Context, ids []int64) ([]*commentmodel.CommentReaction, error)
+	GetReplyCounts(ctx context.Context, ids []int64) (map[int64]int64, error)
+	// GetMentionedUserIDs returns the users of the org with the logins and the members of the teams with the names,
+	// at most limit users.
+	GetMentionedUserIDs(ctx context.Context, orgID int64, names []string, limit int) ([]int64, error)
 }
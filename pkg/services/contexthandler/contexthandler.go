@@ -156,6 +156,8 @@ func (h *ContextHandler) Middleware(next http.Handler) http.Handler {
 		}
 
 		reqContext.Logger = reqContext.Logger.New("userId", reqContext.UserID, "orgId", reqContext.OrgID, "uname", reqContext.Login)
+		// the loggers of the services called with the context of the request log the same attributes
+		*r = *r.WithContext(log.WithContextualAttributes(r.Context(), []interface{}{"userId", reqContext.UserID, "orgId", reqContext.OrgID, "uname", reqContext.Login}))
 		span.AddEvents(
 			[]string{"uname", "orgId", "userId"},
 			[]tracing.EventValue{
@@ -408,6 +410,9 @@ func (h *ContextHandler) initContextWithToken(reqContext *models.ReqContext, org
 	defer span.End()
 
 	token, err := h.AuthTokenService.LookupToken(ctx, rawToken)
+	if err == nil {
+		err = h.AuthTokenService.CheckTokenDevice(ctx, token, reqContext.Req.UserAgent())
+	}
 	if err != nil {
 		reqContext.Logger.Warn("Failed to look up user based on cookie", "error", err)
 		// Burn the cookie in case of failure
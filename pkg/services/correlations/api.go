@@ -53,6 +53,10 @@ func (s *CorrelationsService) createHandler(c *models.ReqContext) response.Respo
 
 	correlation, err := s.CreateCorrelation(c.Req.Context(), cmd)
 	if err != nil {
+		if errors.Is(err, ErrInvalidCorrelationConfig) {
+			return response.Error(http.StatusBadRequest, "Invalid correlation config", err)
+		}
+
 		if errors.Is(err, ErrSourceDataSourceDoesNotExists) || errors.Is(err, ErrTargetDataSourceDoesNotExists) {
 			return response.Error(http.StatusNotFound, "Data source not found", err)
 		}
@@ -160,7 +164,11 @@ func (s *CorrelationsService) updateHandler(c *models.ReqContext) response.Respo
 	correlation, err := s.UpdateCorrelation(c.Req.Context(), cmd)
 	if err != nil {
 		if errors.Is(err, ErrUpdateCorrelationEmptyParams) {
-			return response.Error(http.StatusBadRequest, "At least one of label, description is required", err)
+			return response.Error(http.StatusBadRequest, "At least one of label, description or config is required", err)
+		}
+
+		if errors.Is(err, ErrInvalidCorrelationConfig) {
+			return response.Error(http.StatusBadRequest, "Invalid correlation config", err)
 		}
 
 		if errors.Is(err, ErrSourceDataSourceDoesNotExists) {
@@ -0,0 +1,170 @@
+package correlations
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-logfmt/logfmt"
+)
+
+var variableRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// FromDB deserializes the config stored as JSON, a correlation without config has an empty one
+func (c *CorrelationConfig) FromDB(data []byte) error {
+	*c = CorrelationConfig{}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, c)
+}
+
+// ToDB serializes the config as JSON, or NULL when the correlation has no config
+func (c *CorrelationConfig) ToDB() ([]byte, error) {
+	if c.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// IsEmpty returns whether the correlation has no config
+func (c CorrelationConfig) IsEmpty() bool {
+	return c.Field == "" && c.Type == "" && len(c.Target) == 0 && len(c.Transformations) == 0
+}
+
+// Validate returns ErrInvalidCorrelationConfig when the config cannot be used to build the target query
+func (c CorrelationConfig) Validate() error {
+	if c.IsEmpty() {
+		return nil
+	}
+
+	if c.Type != ConfigTypeQuery {
+		return fmt.Errorf("%w: unsupported type %q", ErrInvalidCorrelationConfig, c.Type)
+	}
+	if c.Field == "" {
+		return fmt.Errorf("%w: field is required", ErrInvalidCorrelationConfig)
+	}
+	if len(c.Target) == 0 {
+		return fmt.Errorf("%w: target is required", ErrInvalidCorrelationConfig)
+	}
+
+	for _, t := range c.Transformations {
+		switch t.Type {
+		case TransformationTypeRegex:
+			if t.Expression == "" {
+				return fmt.Errorf("%w: regex transformation requires an expression", ErrInvalidCorrelationConfig)
+			}
+			if _, err := regexp.Compile(t.Expression); err != nil {
+				return fmt.Errorf("%w: invalid regex transformation expression: %s", ErrInvalidCorrelationConfig, err)
+			}
+		case TransformationTypeLogfmt:
+		default:
+			return fmt.Errorf("%w: unsupported transformation type %q", ErrInvalidCorrelationConfig, t.Type)
+		}
+	}
+
+	return nil
+}
+
+// BuildTargetQuery returns the query to run on the target data source for a row of the source data:
+// the target of the config where the ${variable} are replaced with the values of the fields of the
+// row and the variables extracted by the transformations.
+func (c CorrelationConfig) BuildTargetQuery(fields map[string]string) (map[string]interface{}, error) {
+	variables := make(map[string]string, len(fields))
+	for name, value := range fields {
+		variables[name] = value
+	}
+
+	for _, t := range c.Transformations {
+		field := t.Field
+		if field == "" {
+			field = c.Field
+		}
+		value, ok := fields[field]
+		if !ok {
+			continue
+		}
+
+		switch t.Type {
+		case TransformationTypeRegex:
+			re, err := regexp.Compile(t.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid regex transformation expression: %s", ErrInvalidCorrelationConfig, err)
+			}
+			match := re.FindStringSubmatch(value)
+			if match == nil {
+				continue
+			}
+			name := t.MapValue
+			if name == "" {
+				name = field
+			}
+			if len(match) > 1 {
+				variables[name] = match[1]
+			} else {
+				variables[name] = match[0]
+			}
+		case TransformationTypeLogfmt:
+			decoder := logfmt.NewDecoder(strings.NewReader(value))
+			for decoder.ScanRecord() {
+				for decoder.ScanKeyval() {
+					variables[string(decoder.Key())] = string(decoder.Value())
+				}
+			}
+			if err := decoder.Err(); err != nil {
+				return nil, fmt.Errorf("failed to parse logfmt field %s: %w", field, err)
+			}
+		}
+	}
+
+	target, err := interpolate(c.Target, variables)
+	if err != nil {
+		return nil, err
+	}
+	return target.(map[string]interface{}), nil
+}
+
+// interpolate returns a copy of the value where the ${variable} of the strings are replaced
+func interpolate(value interface{}, variables map[string]string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		var missing string
+		interpolated := variableRegex.ReplaceAllStringFunc(v, func(match string) string {
+			name := match[2 : len(match)-1]
+			if value, ok := variables[name]; ok {
+				return value
+			}
+			if missing == "" {
+				missing = name
+			}
+			return match
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("%w: %s", ErrCorrelationMissingVariable, missing)
+		}
+		return interpolated, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			interpolated, err := interpolate(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = interpolated
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			interpolated, err := interpolate(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, interpolated)
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
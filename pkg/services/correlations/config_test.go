@@ -0,0 +1,105 @@
+package correlations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationConfigValidate(t *testing.T) {
+	valid := CorrelationConfig{
+		Type:   ConfigTypeQuery,
+		Field:  "message",
+		Target: map[string]interface{}{"query": "${traceId}"},
+		Transformations: []Transformation{
+			{Type: TransformationTypeRegex, Expression: `traceId=(\w+)`},
+			{Type: TransformationTypeLogfmt},
+		},
+	}
+
+	t.Run("accepts an empty config", func(t *testing.T) {
+		require.NoError(t, CorrelationConfig{}.Validate())
+	})
+
+	t.Run("accepts a valid config", func(t *testing.T) {
+		require.NoError(t, valid.Validate())
+	})
+
+	testCases := []struct {
+		name   string
+		modify func(c *CorrelationConfig)
+	}{
+		{name: "unsupported type", modify: func(c *CorrelationConfig) { c.Type = "link" }},
+		{name: "missing field", modify: func(c *CorrelationConfig) { c.Field = "" }},
+		{name: "missing target", modify: func(c *CorrelationConfig) { c.Target = nil }},
+		{name: "unsupported transformation", modify: func(c *CorrelationConfig) { c.Transformations = []Transformation{{Type: "jsonpath"}} }},
+		{name: "regex without expression", modify: func(c *CorrelationConfig) { c.Transformations = []Transformation{{Type: TransformationTypeRegex}} }},
+		{name: "invalid regex", modify: func(c *CorrelationConfig) {
+			c.Transformations = []Transformation{{Type: TransformationTypeRegex, Expression: "traceId=("}}
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run("rejects a config with "+tc.name, func(t *testing.T) {
+			config := valid
+			tc.modify(&config)
+			require.ErrorIs(t, config.Validate(), ErrInvalidCorrelationConfig)
+		})
+	}
+}
+
+func TestCorrelationConfigBuildTargetQuery(t *testing.T) {
+	t.Run("interpolates the fields", func(t *testing.T) {
+		config := CorrelationConfig{
+			Type:   ConfigTypeQuery,
+			Field:  "traceId",
+			Target: map[string]interface{}{"query": "${traceId}", "limit": 20, "tags": []interface{}{"service=${service}"}},
+		}
+
+		target, err := config.BuildTargetQuery(map[string]string{"traceId": "abc", "service": "api"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"query": "abc", "limit": 20, "tags": []interface{}{"service=api"}}, target)
+		// the target of the config is not modified
+		require.Equal(t, "${traceId}", config.Target["query"])
+	})
+
+	t.Run("interpolates the variables extracted with a regex", func(t *testing.T) {
+		config := CorrelationConfig{
+			Type:   ConfigTypeQuery,
+			Field:  "message",
+			Target: map[string]interface{}{"query": "${traceId} ${message}"},
+			Transformations: []Transformation{
+				{Type: TransformationTypeRegex, Expression: `traceId=(\w+)`, MapValue: "traceId"},
+			},
+		}
+
+		target, err := config.BuildTargetQuery(map[string]string{"message": "request traceId=abc123 done"})
+		require.NoError(t, err)
+		require.Equal(t, "abc123 request traceId=abc123 done", target["query"])
+	})
+
+	t.Run("interpolates the variables extracted with logfmt", func(t *testing.T) {
+		config := CorrelationConfig{
+			Type:            ConfigTypeQuery,
+			Field:           "line",
+			Target:          map[string]interface{}{"expr": `{app="${app}"} |= "${msg}"`},
+			Transformations: []Transformation{{Type: TransformationTypeLogfmt}},
+		}
+
+		target, err := config.BuildTargetQuery(map[string]string{"line": `app=api level=error msg="connection refused"`})
+		require.NoError(t, err)
+		require.Equal(t, `{app="api"} |= "connection refused"`, target["expr"])
+	})
+
+	t.Run("returns an error when a variable is missing", func(t *testing.T) {
+		config := CorrelationConfig{
+			Type:            ConfigTypeQuery,
+			Field:           "message",
+			Target:          map[string]interface{}{"query": "${traceId}"},
+			Transformations: []Transformation{{Type: TransformationTypeRegex, Expression: `traceId=(\w+)`, MapValue: "traceId"}},
+		}
+
+		_, err := config.BuildTargetQuery(map[string]string{"message": "no trace"})
+		require.ErrorIs(t, err, ErrCorrelationMissingVariable)
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -16,6 +17,11 @@ func (s CorrelationsService) createCorrelation(ctx context.Context, cmd CreateCo
 		TargetUID:   cmd.TargetUID,
 		Label:       cmd.Label,
 		Description: cmd.Description,
+		Config:      cmd.Config,
+	}
+
+	if err := cmd.Config.Validate(); err != nil {
+		return Correlation{}, err
 	}
 
 	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
@@ -40,12 +46,12 @@ func (s CorrelationsService) createCorrelation(ctx context.Context, cmd CreateCo
 			return ErrTargetDataSourceDoesNotExists
 		}
 
-		_, err = session.Insert(correlation)
+		_, err = session.Insert(&correlation)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return s.insertEntityEvents(session, cmd.OrgId, store.EntityEventTypeCreate, correlation.UID)
 	})
 
 	if err != nil {
@@ -73,7 +79,11 @@ func (s CorrelationsService) deleteCorrelation(ctx context.Context, cmd DeleteCo
 		if deletedCount == 0 {
 			return ErrCorrelationNotFound
 		}
-		return err
+		if err != nil {
+			return err
+		}
+
+		return s.insertEntityEvents(session, cmd.OrgId, store.EntityEventTypeDelete, cmd.UID)
 	})
 }
 
@@ -96,7 +106,7 @@ func (s CorrelationsService) updateCorrelation(ctx context.Context, cmd UpdateCo
 			return ErrSourceDataSourceReadOnly
 		}
 
-		if cmd.Label == nil && cmd.Description == nil {
+		if cmd.Label == nil && cmd.Description == nil && cmd.Config == nil {
 			return ErrUpdateCorrelationEmptyParams
 		}
 		update := Correlation{}
@@ -108,8 +118,17 @@ func (s CorrelationsService) updateCorrelation(ctx context.Context, cmd UpdateCo
 			update.Description = *cmd.Description
 			session.MustCols("description")
 		}
+		if cmd.Config != nil {
+			if err := cmd.Config.Validate(); err != nil {
+				return err
+			}
+			update.Config = *cmd.Config
+		} else {
+			// the config is always updated otherwise, as it is converted with ToDB
+			session.Omit("config")
+		}
 
-		updateCount, err := session.Where("uid = ? AND source_uid = ?", correlation.UID, correlation.SourceUID).Limit(1).Update(update)
+		updateCount, err := session.Where("uid = ? AND source_uid = ?", correlation.UID, correlation.SourceUID).Limit(1).Update(&update)
 		if updateCount == 0 {
 			return ErrCorrelationNotFound
 		}
@@ -121,8 +140,11 @@ func (s CorrelationsService) updateCorrelation(ctx context.Context, cmd UpdateCo
 		if !found {
 			return ErrCorrelationNotFound
 		}
+		if err != nil {
+			return err
+		}
 
-		return err
+		return s.insertEntityEvents(session, cmd.OrgId, store.EntityEventTypeUpdate, correlation.UID)
 	})
 
 	if err != nil {
@@ -210,14 +232,56 @@ func (s CorrelationsService) getCorrelations(ctx context.Context, cmd GetCorrela
 
 func (s CorrelationsService) deleteCorrelationsBySourceUID(ctx context.Context, cmd DeleteCorrelationsBySourceUIDCommand) error {
 	return s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
-		_, err := session.Delete(&Correlation{SourceUID: cmd.SourceUID})
-		return err
+		uids, err := s.findCorrelationUIDs(session, cmd.OrgId, Correlation{SourceUID: cmd.SourceUID})
+		if err != nil {
+			return err
+		}
+
+		if _, err := session.Delete(&Correlation{SourceUID: cmd.SourceUID}); err != nil {
+			return err
+		}
+
+		return s.insertEntityEvents(session, cmd.OrgId, store.EntityEventTypeDelete, uids...)
 	})
 }
 
 func (s CorrelationsService) deleteCorrelationsByTargetUID(ctx context.Context, cmd DeleteCorrelationsByTargetUIDCommand) error {
 	return s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
-		_, err := session.Delete(&Correlation{TargetUID: cmd.TargetUID})
-		return err
+		uids, err := s.findCorrelationUIDs(session, cmd.OrgId, Correlation{TargetUID: cmd.TargetUID})
+		if err != nil {
+			return err
+		}
+
+		if _, err := session.Delete(&Correlation{TargetUID: cmd.TargetUID}); err != nil {
+			return err
+		}
+
+		return s.insertEntityEvents(session, cmd.OrgId, store.EntityEventTypeDelete, uids...)
 	})
 }
+
+// findCorrelationUIDs returns the UIDs of the correlations matching the condition, when their deletion
+// has to be saved as entity events
+func (s CorrelationsService) findCorrelationUIDs(session *sqlstore.DBSession, orgID int64, cond Correlation) ([]string, error) {
+	if orgID == 0 || !store.EntityEventsEnabled(s.SQLStore.Cfg) {
+		return nil, nil
+	}
+
+	var uids []string
+	err := session.Table("correlation").Cols("uid").Find(&uids, &cond)
+	return uids, err
+}
+
+// insertEntityEvents saves the changes of the correlations as entity events for the search index
+func (s CorrelationsService) insertEntityEvents(session *sqlstore.DBSession, orgID int64, eventType store.EntityEventType, uids ...string) error {
+	if orgID == 0 || !store.EntityEventsEnabled(s.SQLStore.Cfg) {
+		return nil
+	}
+
+	for _, uid := range uids {
+		if _, err := session.Insert(store.CreateDatabaseEntityEvent(uid, orgID, store.EntityTypeCorrelation, eventType)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package correlations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/store"
+)
+
+func TestIntegrationCorrelationConfig(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := CorrelationsService{
+		SQLStore: sqlstore.InitTestDB(t),
+		log:      log.New("correlations"),
+		DataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{Id: 1, Uid: "loki", OrgId: 1},
+			{Id: 2, Uid: "tempo", OrgId: 1},
+		}},
+	}
+	ctx := context.Background()
+
+	config := CorrelationConfig{
+		Type:            ConfigTypeQuery,
+		Field:           "message",
+		Target:          map[string]interface{}{"query": "${traceId}"},
+		Transformations: []Transformation{{Type: TransformationTypeRegex, Expression: `traceId=(\w+)`, MapValue: "traceId"}},
+	}
+
+	t.Run("stores the config", func(t *testing.T) {
+		created, err := s.CreateCorrelation(ctx, CreateCorrelationCommand{SourceUID: "loki", TargetUID: "tempo", OrgId: 1, Config: config})
+		require.NoError(t, err)
+
+		correlation, err := s.GetCorrelation(ctx, GetCorrelationQuery{UID: created.UID, SourceUID: "loki", OrgId: 1})
+		require.NoError(t, err)
+		require.Equal(t, config, correlation.Config)
+
+		label := "a label"
+		correlation, err = s.UpdateCorrelation(ctx, UpdateCorrelationCommand{UID: created.UID, SourceUID: "loki", OrgId: 1, Label: &label})
+		require.NoError(t, err)
+		require.Equal(t, label, correlation.Label)
+		require.Equal(t, config, correlation.Config)
+
+		updatedConfig := config
+		updatedConfig.Field = "line"
+		correlation, err = s.UpdateCorrelation(ctx, UpdateCorrelationCommand{UID: created.UID, SourceUID: "loki", OrgId: 1, Config: &updatedConfig})
+		require.NoError(t, err)
+		require.Equal(t, label, correlation.Label)
+		require.Equal(t, updatedConfig, correlation.Config)
+	})
+
+	t.Run("stores a correlation without config", func(t *testing.T) {
+		created, err := s.CreateCorrelation(ctx, CreateCorrelationCommand{SourceUID: "loki", TargetUID: "tempo", OrgId: 1})
+		require.NoError(t, err)
+
+		correlation, err := s.GetCorrelation(ctx, GetCorrelationQuery{UID: created.UID, SourceUID: "loki", OrgId: 1})
+		require.NoError(t, err)
+		require.True(t, correlation.Config.IsEmpty())
+	})
+
+	t.Run("returns an error when the config is invalid", func(t *testing.T) {
+		_, err := s.CreateCorrelation(ctx, CreateCorrelationCommand{SourceUID: "loki", TargetUID: "tempo", OrgId: 1, Config: CorrelationConfig{Type: ConfigTypeQuery}})
+		require.ErrorIs(t, err, ErrInvalidCorrelationConfig)
+
+		invalidConfig := config
+		invalidConfig.Transformations = []Transformation{{Type: "jsonpath"}}
+		correlations, err := s.GetCorrelationsBySourceUID(ctx, GetCorrelationsBySourceUIDQuery{SourceUID: "loki", OrgId: 1})
+		require.NoError(t, err)
+		_, err = s.UpdateCorrelation(ctx, UpdateCorrelationCommand{UID: correlations[0].UID, SourceUID: "loki", OrgId: 1, Config: &invalidConfig})
+		require.ErrorIs(t, err, ErrInvalidCorrelationConfig)
+	})
+}
+
+func TestIntegrationCorrelationEntityEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := sqlstore.InitTestDB(t)
+	sqlStore.Cfg.IsFeatureToggleEnabled = func(flag string) bool { return flag == featuremgmt.FlagPanelTitleSearch }
+	s := CorrelationsService{
+		SQLStore: sqlStore,
+		log:      log.New("correlations"),
+		DataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{Id: 1, Uid: "loki", OrgId: 1},
+			{Id: 2, Uid: "tempo", OrgId: 1},
+		}},
+	}
+	ctx := context.Background()
+
+	getEntityIDs := func(t *testing.T, eventType store.EntityEventType) []string {
+		t.Helper()
+		var events []store.EntityEvent
+		err := sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.Where("event_type = ?", eventType).Asc("id").Find(&events)
+		})
+		require.NoError(t, err)
+		ids := make([]string, 0, len(events))
+		for _, e := range events {
+			ids = append(ids, e.EntityId)
+		}
+		return ids
+	}
+
+	first, err := s.CreateCorrelation(ctx, CreateCorrelationCommand{SourceUID: "loki", TargetUID: "tempo", OrgId: 1})
+	require.NoError(t, err)
+	second, err := s.CreateCorrelation(ctx, CreateCorrelationCommand{SourceUID: "loki", TargetUID: "tempo", OrgId: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"database/1/correlation/" + first.UID, "database/1/correlation/" + second.UID}, getEntityIDs(t, store.EntityEventTypeCreate))
+
+	label := "a label"
+	_, err = s.UpdateCorrelation(ctx, UpdateCorrelationCommand{UID: first.UID, SourceUID: "loki", OrgId: 1, Label: &label})
+	require.NoError(t, err)
+	require.Equal(t, []string{"database/1/correlation/" + first.UID}, getEntityIDs(t, store.EntityEventTypeUpdate))
+
+	err = s.DeleteCorrelation(ctx, DeleteCorrelationCommand{UID: first.UID, SourceUID: "loki", OrgId: 1})
+	require.NoError(t, err)
+	err = s.DeleteCorrelationsBySourceUID(ctx, DeleteCorrelationsBySourceUIDCommand{SourceUID: "loki", OrgId: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"database/1/correlation/" + first.UID, "database/1/correlation/" + second.UID}, getEntityIDs(t, store.EntityEventTypeDelete))
+}
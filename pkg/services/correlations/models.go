@@ -11,8 +11,57 @@ var (
 	ErrCorrelationFailedGenerateUniqueUid = errors.New("failed to generate unique correlation UID")
 	ErrCorrelationNotFound                = errors.New("correlation not found")
 	ErrUpdateCorrelationEmptyParams       = errors.New("not enough parameters to edit correlation")
+	ErrInvalidCorrelationConfig           = errors.New("invalid correlation config")
+	ErrCorrelationMissingVariable         = errors.New("correlation variable is missing")
 )
 
+type CorrelationConfigType string
+
+const (
+	ConfigTypeQuery CorrelationConfigType = "query"
+)
+
+type TransformationType string
+
+const (
+	TransformationTypeRegex  TransformationType = "regex"
+	TransformationTypeLogfmt TransformationType = "logfmt"
+)
+
+// Transformation extracts variables for the target query from a field of the source data
+// swagger:model
+type Transformation struct {
+	// Type of the transformation, regex or logfmt
+	// example: regex
+	Type TransformationType `json:"type"`
+	// Field the variables are extracted from, the field of the correlation if empty
+	// example: message
+	Field string `json:"field,omitempty"`
+	// Regular expression of a regex transformation. The variable is the first capture group, or the whole match
+	// example: traceId=(\w+)
+	Expression string `json:"expression,omitempty"`
+	// Name of the variable extracted by a regex transformation, the name of the field if empty
+	// example: traceId
+	MapValue string `json:"mapValue,omitempty"`
+}
+
+// CorrelationConfig defines the query run on the target data source of a correlation
+// swagger:model
+type CorrelationConfig struct {
+	// Field of the source data the link of the correlation is attached to
+	// example: message
+	Field string `json:"field"`
+	// Type of the correlation, only query is supported
+	// example: query
+	Type CorrelationConfigType `json:"type"`
+	// Query run on the target data source. The ${variable} in its values are replaced with the
+	// fields of the source data and the variables extracted by the transformations.
+	// example: {"expr": "{job=\"app\"} |= \"${traceId}\""}
+	Target map[string]interface{} `json:"target"`
+	// Transformations extracting variables from the fields of the source data
+	Transformations []Transformation `json:"transformations,omitempty"`
+}
+
 // Correlation is the model for correlations definitions
 type Correlation struct {
 	// Unique identifier of the correlation
@@ -30,6 +79,8 @@ type Correlation struct {
 	// Description of the correlation
 	// example: Logs to Traces
 	Description string `json:"description" xorm:"description"`
+	// Correlation Configuration
+	Config CorrelationConfig `json:"config" xorm:"config"`
 }
 
 // CreateCorrelationResponse is the response struct for CreateCorrelationCommand
@@ -56,6 +107,8 @@ type CreateCorrelationCommand struct {
 	// Optional description of the correlation
 	// example: Logs to Traces
 	Description string `json:"description"`
+	// Optional configuration of the query run on the target data source
+	Config CorrelationConfig `json:"config"`
 }
 
 // swagger:model
@@ -92,6 +145,8 @@ type UpdateCorrelationCommand struct {
 	// Optional description of the correlation
 	// example: Logs to Traces
 	Description *string `json:"description"`
+	// Optional configuration of the query run on the target data source, which replaces the current one
+	Config *CorrelationConfig `json:"config"`
 }
 
 // GetCorrelationQuery is the query to retrieve a single correlation
@@ -116,8 +171,10 @@ type GetCorrelationsQuery struct {
 
 type DeleteCorrelationsBySourceUIDCommand struct {
 	SourceUID string
+	OrgId     int64
 }
 
 type DeleteCorrelationsByTargetUIDCommand struct {
 	TargetUID string
+	OrgId     int64
 }
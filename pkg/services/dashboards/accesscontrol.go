@@ -2,6 +2,7 @@ package dashboards
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -50,10 +51,20 @@ func NewFolderNameScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
 			return nil, ac.ErrInvalidScope
 		}
 		folder, err := db.GetFolderByTitle(ctx, orgID, nsName)
+		if errors.Is(err, ErrFolderNotFound) && strings.Contains(nsName, FolderPathSeparator) {
+			// alert rule namespaces of nested folders are the paths of the folders
+			folder, err = FindFolderByPath(nsName, func(parent *models.Folder) ([]*models.Folder, error) {
+				var parentID int64
+				if parent != nil {
+					parentID = parent.Id
+				}
+				return db.GetChildFolders(ctx, orgID, parentID)
+			})
+		}
 		if err != nil {
 			return nil, err
 		}
-		return []string{ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)}, nil
+		return resolveFolderScope(ctx, db, orgID, folder)
 	})
 }
 
@@ -79,7 +90,37 @@ func NewFolderIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
 			return nil, err
 		}
 
-		return []string{ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)}, nil
+		return resolveFolderScope(ctx, db, orgID, folder)
+	})
+}
+
+// NewFolderUIDScopeResolver provides an ScopeAttributeResolver that is able to convert a scope prefixed with "folders:uid:"
+// into uid based scopes for the folder and all its parent folders, so that permissions are inherited by nested folders.
+func NewFolderUIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
+	prefix := ScopeFoldersProvider.GetResourceScopeUID("")
+	return prefix, ac.ScopeAttributeResolverFunc(func(ctx context.Context, orgID int64, scope string) ([]string, error) {
+		if !strings.HasPrefix(scope, prefix) {
+			return nil, ac.ErrInvalidScope
+		}
+
+		uid, err := ac.ParseScopeUID(scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if uid == ac.GeneralFolderUID {
+			return []string{scope}, nil
+		}
+
+		parents, err := db.GetFolderParents(ctx, orgID, uid)
+		if err != nil {
+			if errors.Is(err, ErrFolderNotFound) {
+				return []string{scope}, nil
+			}
+			return nil, err
+		}
+
+		return append([]string{scope}, folderScopes(parents)...), nil
 	})
 }
 
@@ -130,23 +171,45 @@ func NewDashboardUIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver)
 }
 
 func resolveDashboardScope(ctx context.Context, db Store, orgID int64, dashboard *models.Dashboard) ([]string, error) {
-	var folderUID string
 	if dashboard.FolderId < 0 {
 		return []string{ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid)}, nil
 	}
 
+	scopes := []string{ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid)}
 	if dashboard.FolderId == 0 {
-		folderUID = ac.GeneralFolderUID
-	} else {
-		folder, err := db.GetFolderByID(ctx, orgID, dashboard.FolderId)
-		if err != nil {
-			return nil, err
-		}
-		folderUID = folder.Uid
+		return append(scopes, ScopeFoldersProvider.GetResourceScopeUID(ac.GeneralFolderUID)), nil
+	}
+
+	folder, err := db.GetFolderByID(ctx, orgID, dashboard.FolderId)
+	if err != nil {
+		return nil, err
 	}
 
-	return []string{
-		ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid),
-		ScopeFoldersProvider.GetResourceScopeUID(folderUID),
-	}, nil
+	folderScopes, err := resolveFolderScope(ctx, db, orgID, folder)
+	if err != nil {
+		return nil, err
+	}
+	return append(scopes, folderScopes...), nil
+}
+
+// resolveFolderScope returns the uid based scopes of a folder and of its parent folders.
+func resolveFolderScope(ctx context.Context, db Store, orgID int64, folder *models.Folder) ([]string, error) {
+	scopes := []string{ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)}
+	if folder.ParentUid == "" {
+		return scopes, nil
+	}
+
+	parents, err := db.GetFolderParents(ctx, orgID, folder.Uid)
+	if err != nil {
+		return nil, err
+	}
+	return append(scopes, folderScopes(parents)...), nil
+}
+
+func folderScopes(folders []*models.Folder) []string {
+	scopes := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		scopes = append(scopes, ScopeFoldersProvider.GetResourceScopeUID(folder.Uid))
+	}
+	return scopes
 }
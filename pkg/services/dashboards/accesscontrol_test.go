@@ -44,6 +44,27 @@ func TestNewFolderNameScopeResolver(t *testing.T) {
 
 		dashboardStore.AssertCalled(t, "GetFolderByTitle", mock.Anything, orgId, title)
 	})
+	t.Run("resolver should resolve the path of a nested folder", func(t *testing.T) {
+		dashboardStore := &FakeDashboardStore{}
+		_, resolver := NewFolderNameScopeResolver(dashboardStore)
+
+		orgId := rand.Int63()
+		parent := models.NewFolder("Team")
+		parent.Id = rand.Int63()
+		parent.Uid = util.GenerateShortUID()
+		child := models.NewFolder("Service")
+		child.Id = rand.Int63()
+		child.Uid = util.GenerateShortUID()
+		child.ParentUid = parent.Uid
+		dashboardStore.On("GetFolderByTitle", mock.Anything, orgId, "Team/Service").Return(nil, ErrFolderNotFound).Once()
+		dashboardStore.On("GetChildFolders", mock.Anything, orgId, int64(0)).Return([]*models.Folder{parent}, nil).Once()
+		dashboardStore.On("GetChildFolders", mock.Anything, orgId, parent.Id).Return([]*models.Folder{child}, nil).Once()
+		dashboardStore.On("GetFolderParents", mock.Anything, orgId, child.Uid).Return([]*models.Folder{parent}, nil).Once()
+
+		resolvedScopes, err := resolver.Resolve(context.Background(), orgId, "folders:name:Team/Service")
+		require.NoError(t, err)
+		require.Equal(t, []string{"folders:uid:" + child.Uid, "folders:uid:" + parent.Uid}, resolvedScopes)
+	})
 	t.Run("resolver should fail if input scope is not expected", func(t *testing.T) {
 		dashboardStore := &FakeDashboardStore{}
 		_, resolver := NewFolderNameScopeResolver(dashboardStore)
@@ -145,6 +166,49 @@ func TestNewFolderIDScopeResolver(t *testing.T) {
 	})
 }
 
+func TestNewFolderUIDScopeResolver(t *testing.T) {
+	t.Run("prefix should be expected", func(t *testing.T) {
+		prefix, _ := NewFolderUIDScopeResolver(&FakeDashboardStore{})
+		require.Equal(t, "folders:uid:", prefix)
+	})
+
+	t.Run("resolver should add the scopes of the parent folders", func(t *testing.T) {
+		dashboardStore := &FakeDashboardStore{}
+		_, resolver := NewFolderUIDScopeResolver(dashboardStore)
+
+		orgId := rand.Int63()
+		uid := util.GenerateShortUID()
+		root := models.NewFolder("root")
+		root.Uid = util.GenerateShortUID()
+		parent := models.NewFolder("parent")
+		parent.Uid = util.GenerateShortUID()
+		parent.ParentUid = root.Uid
+		dashboardStore.On("GetFolderParents", mock.Anything, orgId, uid).Return([]*models.Folder{root, parent}, nil).Once()
+
+		resolvedScopes, err := resolver.Resolve(context.Background(), orgId, "folders:uid:"+uid)
+		require.NoError(t, err)
+		require.Equal(t, []string{"folders:uid:" + uid, "folders:uid:" + root.Uid, "folders:uid:" + parent.Uid}, resolvedScopes)
+	})
+
+	t.Run("resolver should keep the scope if folder does not exist", func(t *testing.T) {
+		dashboardStore := &FakeDashboardStore{}
+		_, resolver := NewFolderUIDScopeResolver(dashboardStore)
+
+		dashboardStore.On("GetFolderParents", mock.Anything, mock.Anything, mock.Anything).Return(nil, ErrFolderNotFound).Once()
+
+		resolvedScopes, err := resolver.Resolve(context.Background(), rand.Int63(), "folders:uid:unknown")
+		require.NoError(t, err)
+		require.Equal(t, []string{"folders:uid:unknown"}, resolvedScopes)
+	})
+
+	t.Run("resolver should fail if input scope is not expected", func(t *testing.T) {
+		_, resolver := NewFolderUIDScopeResolver(&FakeDashboardStore{})
+
+		_, err := resolver.Resolve(context.Background(), rand.Int63(), "folders:id:123")
+		require.ErrorIs(t, err, ac.ErrInvalidScope)
+	})
+}
+
 func TestNewDashboardIDScopeResolver(t *testing.T) {
 	t.Run("prefix should be expected", func(t *testing.T) {
 		prefix, _ := NewDashboardIDScopeResolver(&FakeDashboardStore{})
@@ -89,4 +89,8 @@ type FolderStore interface {
 	GetFolderByUID(ctx context.Context, orgID int64, uid string) (*models.Folder, error)
 	// GetFolderByID retrieves a folder by its ID
 	GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error)
+	// GetFolderParents retrieves the ancestors of a folder, starting with the root level folder
+	GetFolderParents(ctx context.Context, orgID int64, uid string) ([]*models.Folder, error)
+	// GetChildFolders retrieves the folders directly nested in a folder
+	GetChildFolders(ctx context.Context, orgID int64, parentID int64) ([]*models.Folder, error)
 }
@@ -67,16 +67,18 @@ func (d *DashboardStore) ValidateDashboardBeforeSave(ctx context.Context, dashbo
 	return isParentFolderChanged, nil
 }
 
+// GetFolderByTitle retrieves a folder by its title. Folder titles are only unique among
+// siblings, so root level folders are preferred when nested folders share a title.
 func (d *DashboardStore) GetFolderByTitle(ctx context.Context, orgID int64, title string) (*models.Folder, error) {
 	if title == "" {
 		return nil, dashboards.ErrFolderTitleEmpty
 	}
 
 	// there is a unique constraint on org_id, folder_id, title
-	// there are no nested folders so the parent folder id is always 0
 	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Title: title}
+	var folder *models.Folder
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Asc("folder_id").Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -85,15 +87,20 @@ func (d *DashboardStore) GetFolderByTitle(ctx context.Context, orgID int64, titl
 		}
 		dashboard.SetId(dashboard.Id)
 		dashboard.SetUid(dashboard.Uid)
-		return nil
+		folder, err = d.toFolder(sess, &dashboard)
+		return err
 	})
-	return models.DashboardToFolder(&dashboard), err
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
 }
 
 func (d *DashboardStore) GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error) {
 	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Id: id}
+	var folder *models.Folder
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -102,12 +109,13 @@ func (d *DashboardStore) GetFolderByID(ctx context.Context, orgID int64, id int6
 		}
 		dashboard.SetId(dashboard.Id)
 		dashboard.SetUid(dashboard.Uid)
-		return nil
+		folder, err = d.toFolder(sess, &dashboard)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return models.DashboardToFolder(&dashboard), nil
+	return folder, nil
 }
 
 func (d *DashboardStore) GetFolderByUID(ctx context.Context, orgID int64, uid string) (*models.Folder, error) {
@@ -116,8 +124,9 @@ func (d *DashboardStore) GetFolderByUID(ctx context.Context, orgID int64, uid st
 	}
 
 	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Uid: uid}
+	var folder *models.Folder
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -126,12 +135,101 @@ func (d *DashboardStore) GetFolderByUID(ctx context.Context, orgID int64, uid st
 		}
 		dashboard.SetId(dashboard.Id)
 		dashboard.SetUid(dashboard.Uid)
+		folder, err = d.toFolder(sess, &dashboard)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// GetFolderParents returns the ancestors of a folder, starting with the root level folder.
+func (d *DashboardStore) GetFolderParents(ctx context.Context, orgID int64, uid string) ([]*models.Folder, error) {
+	parents := make([]*models.Folder, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		current := models.Dashboard{OrgId: orgID, Uid: uid}
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&current)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return dashboards.ErrFolderNotFound
+		}
+
+		// the depth is validated when folders are created or moved, the bound only protects against corrupted data
+		for current.FolderId > 0 && len(parents) <= dashboards.MaxNestedFolderDepth {
+			parent := models.Dashboard{OrgId: orgID, Id: current.FolderId}
+			has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&parent)
+			if err != nil {
+				return err
+			}
+			if !has {
+				return dashboards.ErrFolderNotFound
+			}
+			parent.SetId(parent.Id)
+			parent.SetUid(parent.Uid)
+			parents = append([]*models.Folder{models.DashboardToFolder(&parent)}, parents...)
+			current = parent
+		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return models.DashboardToFolder(&dashboard), nil
+
+	for i := 1; i < len(parents); i++ {
+		parents[i].ParentUid = parents[i-1].Uid
+	}
+	return parents, nil
+}
+
+// GetChildFolders returns the folders directly nested in a folder, or the root level folders if parentID is 0.
+func (d *DashboardStore) GetChildFolders(ctx context.Context, orgID int64, parentID int64) ([]*models.Folder, error) {
+	var children []*models.Dashboard
+	var parentUID string
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if parentID > 0 {
+			parent := models.Dashboard{OrgId: orgID, Id: parentID}
+			has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(&parent)
+			if err != nil {
+				return err
+			}
+			if !has {
+				return dashboards.ErrFolderNotFound
+			}
+			parentUID = parent.Uid
+		}
+
+		return sess.Where("org_id = ? AND folder_id = ? AND is_folder = "+d.sqlStore.Dialect.BooleanStr(true), orgID, parentID).
+			Asc("title").Find(&children)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]*models.Folder, 0, len(children))
+	for _, child := range children {
+		child.SetId(child.Id)
+		child.SetUid(child.Uid)
+		folder := models.DashboardToFolder(child)
+		folder.ParentUid = parentUID
+		folders = append(folders, folder)
+	}
+	return folders, nil
+}
+
+// toFolder converts a folder dashboard to a folder, resolving the UID of its parent folder.
+func (d *DashboardStore) toFolder(sess *sqlstore.DBSession, dashboard *models.Dashboard) (*models.Folder, error) {
+	folder := models.DashboardToFolder(dashboard)
+	if dashboard.FolderId > 0 {
+		var parentUID string
+		if _, err := sess.Table(&models.Dashboard{}).Where("id = ? AND org_id = ?", dashboard.FolderId, dashboard.OrgId).Cols("uid").Get(&parentUID); err != nil {
+			return nil, err
+		}
+		folder.ParentUid = parentUID
+	}
+	return folder, nil
 }
 
 func (d *DashboardStore) GetProvisionedDataByDashboardID(ctx context.Context, dashboardID int64) (*models.DashboardProvisioning, error) {
@@ -374,8 +472,15 @@ func getExistingDashboardByIdOrUidForUpdate(sess *sqlstore.DBSession, dash *mode
 func getExistingDashboardByTitleAndFolder(sess *sqlstore.DBSession, dash *models.Dashboard, dialect migrator.Dialect, overwrite,
 	isParentFolderChanged bool) (bool, error) {
 	var existing models.Dashboard
-	exists, err := sess.Where("org_id=? AND slug=? AND (is_folder=? OR folder_id=?)", dash.OrgId, dash.Slug,
-		dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	var exists bool
+	var err error
+	if dash.IsFolder {
+		// folder names are unique among their siblings
+		exists, err = sess.Where("org_id=? AND slug=? AND folder_id=?", dash.OrgId, dash.Slug, dash.FolderId).Get(&existing)
+	} else {
+		exists, err = sess.Where("org_id=? AND slug=? AND ((is_folder=? AND folder_id=0) OR folder_id=?)", dash.OrgId, dash.Slug,
+			dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	}
 	if err != nil {
 		return isParentFolderChanged, fmt.Errorf("SQL query for existing dashboard by org ID or folder ID failed: %w", err)
 	}
@@ -717,13 +822,27 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 	}
 
 	if dashboard.IsFolder {
+		// nested folders are deleted along with their parent
+		var childFolderIDs []int64
+		err := sess.Table("dashboard").Where("org_id = ? AND folder_id = ? AND is_folder = "+d.sqlStore.Dialect.BooleanStr(true), dashboard.OrgId, dashboard.Id).
+			Cols("id").Find(&childFolderIDs)
+		if err != nil {
+			return err
+		}
+		for _, childID := range childFolderIDs {
+			childCmd := &models.DeleteDashboardCommand{OrgId: cmd.OrgId, Id: childID, ForceDeleteFolderRules: cmd.ForceDeleteFolderRules}
+			if err := d.deleteDashboard(childCmd, sess, emitEntityEvent); err != nil {
+				return err
+			}
+		}
+
 		deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
 
 		var dashIds []struct {
 			Id  int64
 			Uid string
 		}
-		err := sess.SQL("SELECT id, uid FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
+		err = sess.SQL("SELECT id, uid FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
 		if err != nil {
 			return err
 		}
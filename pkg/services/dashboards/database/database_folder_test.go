@@ -533,6 +533,96 @@ func TestIntegrationDashboardFolderDataAccess(t *testing.T) {
 	})
 }
 
+func TestIntegrationNestedFolderDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	var orgId int64 = 1
+	setup := func(t *testing.T) (*DashboardStore, *models.Dashboard, *models.Dashboard, *models.Dashboard) {
+		sqlStore := sqlstore.InitTestDB(t)
+		dashboardStore := ProvideDashboardStore(sqlStore, testFeatureToggles, tagimpl.ProvideService(sqlStore))
+		root := insertTestDashboard(t, dashboardStore, "root", orgId, 0, true)
+		child := insertTestDashboard(t, dashboardStore, "child", orgId, root.Id, true)
+		grandchild := insertTestDashboard(t, dashboardStore, "grandchild", orgId, child.Id, true)
+		return dashboardStore, root, child, grandchild
+	}
+
+	t.Run("GetFolderByUID should return the parent uid of a nested folder", func(t *testing.T) {
+		dashboardStore, root, child, _ := setup(t)
+
+		f, err := dashboardStore.GetFolderByUID(context.Background(), orgId, child.Uid)
+		require.NoError(t, err)
+		require.Equal(t, child.Id, f.Id)
+		require.Equal(t, root.Uid, f.ParentUid)
+
+		f, err = dashboardStore.GetFolderByUID(context.Background(), orgId, root.Uid)
+		require.NoError(t, err)
+		require.Empty(t, f.ParentUid)
+	})
+
+	t.Run("GetFolderParents should return the ancestors starting with the root folder", func(t *testing.T) {
+		dashboardStore, root, child, grandchild := setup(t)
+
+		parents, err := dashboardStore.GetFolderParents(context.Background(), orgId, grandchild.Uid)
+		require.NoError(t, err)
+		require.Len(t, parents, 2)
+		require.Equal(t, root.Uid, parents[0].Uid)
+		require.Equal(t, child.Uid, parents[1].Uid)
+		require.Equal(t, root.Uid, parents[1].ParentUid)
+
+		parents, err = dashboardStore.GetFolderParents(context.Background(), orgId, root.Uid)
+		require.NoError(t, err)
+		require.Empty(t, parents)
+
+		_, err = dashboardStore.GetFolderParents(context.Background(), orgId, "unknown")
+		require.ErrorIs(t, err, dashboards.ErrFolderNotFound)
+	})
+
+	t.Run("GetChildFolders should return the direct children of a folder", func(t *testing.T) {
+		dashboardStore, root, child, _ := setup(t)
+		insertTestDashboard(t, dashboardStore, "dashboard in root", orgId, root.Id, false)
+
+		children, err := dashboardStore.GetChildFolders(context.Background(), orgId, root.Id)
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+		require.Equal(t, child.Uid, children[0].Uid)
+		require.Equal(t, root.Uid, children[0].ParentUid)
+
+		children, err = dashboardStore.GetChildFolders(context.Background(), orgId, 0)
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+		require.Equal(t, root.Uid, children[0].Uid)
+	})
+
+	t.Run("Folder names should only be unique among sibling folders", func(t *testing.T) {
+		dashboardStore, root, child, _ := setup(t)
+		insertTestDashboard(t, dashboardStore, "child", orgId, child.Id, true)
+
+		_, err := dashboardStore.ValidateDashboardBeforeSave(context.Background(), &models.Dashboard{
+			OrgId:    orgId,
+			FolderId: root.Id,
+			IsFolder: true,
+			Title:    "child",
+			Slug:     "child",
+			Data:     simplejson.NewFromAny(map[string]interface{}{"title": "child"}),
+		}, false)
+		require.ErrorIs(t, err, dashboards.ErrDashboardWithSameNameInFolderExists)
+	})
+
+	t.Run("Deleting a folder should delete its nested folders and their dashboards", func(t *testing.T) {
+		dashboardStore, root, child, grandchild := setup(t)
+		dash := insertTestDashboard(t, dashboardStore, "nested dashboard", orgId, grandchild.Id, false)
+
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: root.Id, OrgId: orgId})
+		require.NoError(t, err)
+
+		for _, d := range []*models.Dashboard{root, child, grandchild, dash} {
+			_, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Id: d.Id, OrgId: orgId})
+			require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+		}
+	})
+}
+
 func moveDashboard(t *testing.T, dashboardStore *DashboardStore, orgId int64, dashboard *simplejson.Json,
 	newFolderId int64) *models.Dashboard {
 	t.Helper()
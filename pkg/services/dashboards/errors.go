@@ -135,6 +135,9 @@ var (
 	ErrFolderFailedGenerateUniqueUid = errors.New("failed to generate unique folder ID")
 	ErrFolderAccessDenied            = errors.New("access denied to folder")
 	ErrFolderContainsAlertRules      = errors.New("folder contains alert rules")
+	ErrFolderTooDeep                 = errors.New("folder exceeds the maximum nesting depth")
+	ErrFolderCircularReference       = errors.New("folder cannot be moved into itself or one of its descendants")
+	ErrFolderTitleInvalid            = errors.New("the title of a nested folder cannot contain " + FolderPathSeparator)
 )
 
 // DashboardErr represents a dashboard error.
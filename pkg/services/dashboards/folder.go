@@ -2,11 +2,54 @@ package dashboards
 
 import (
 	"context"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// MaxNestedFolderDepth is the maximum number of levels of nested folders, including the root level.
+const MaxNestedFolderDepth = 4
+
+// FolderPathSeparator separates the folder titles in the path of a nested folder, e.g. "Team/Service".
+const FolderPathSeparator = "/"
+
+// FolderPath joins the titles of the parents of a folder, starting with the root level folder, and the title of the folder.
+func FolderPath(parentTitles []string, title string) string {
+	return strings.Join(append(parentTitles, title), FolderPathSeparator)
+}
+
+// FindFolderByPath walks down a path of folder titles, starting with the root level folders. children returns
+// the folders nested in a folder, or the root level folders for nil. It returns ErrFolderNotFound when a title of
+// the path does not match any folder.
+func FindFolderByPath(path string, children func(parent *models.Folder) ([]*models.Folder, error)) (*models.Folder, error) {
+	titles := strings.Split(path, FolderPathSeparator)
+	if len(titles) > MaxNestedFolderDepth {
+		return nil, ErrFolderNotFound
+	}
+
+	var folder *models.Folder
+	for _, title := range titles {
+		folders, err := children(folder)
+		if err != nil {
+			return nil, err
+		}
+
+		var found *models.Folder
+		for _, f := range folders {
+			if f.Title == title {
+				found = f
+				break
+			}
+		}
+		if found == nil {
+			return nil, ErrFolderNotFound
+		}
+		folder = found
+	}
+	return folder, nil
+}
+
 // FolderService is a service for operating on folders.
 //
 //go:generate mockery --name FolderService --structname FakeFolderService --inpackage --filename folder_service_mock.go
@@ -19,4 +62,12 @@ type FolderService interface {
 	UpdateFolder(ctx context.Context, user *user.SignedInUser, orgID int64, existingUid string, cmd *models.UpdateFolderCommand) error
 	DeleteFolder(ctx context.Context, user *user.SignedInUser, orgID int64, uid string, forceDeleteRules bool) (*models.Folder, error)
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, folderID int64, setViewAndEditPermissions bool) error
+	// CreateNestedFolder creates a folder inside the folder identified by parentUID.
+	CreateNestedFolder(ctx context.Context, user *user.SignedInUser, orgID int64, title, uid, parentUID string) (*models.Folder, error)
+	// MoveFolder moves a folder and everything it contains under another folder.
+	MoveFolder(ctx context.Context, user *user.SignedInUser, orgID int64, uid string, cmd *models.MoveFolderCommand) error
+	// GetFolderParents returns the ancestors of a folder, starting with the root level folder.
+	GetFolderParents(ctx context.Context, user *user.SignedInUser, orgID int64, uid string) ([]*models.Folder, error)
+	// GetChildFolders returns the folders directly nested in a folder, or the root level folders if parentUID is empty.
+	GetChildFolders(ctx context.Context, user *user.SignedInUser, orgID int64, parentUID string) ([]*models.Folder, error)
 }
@@ -40,6 +40,29 @@ func (_m *FakeFolderService) CreateFolder(ctx context.Context, usr *user.SignedI
 	return r0, r1
 }
 
+// CreateNestedFolder provides a mock function with given fields: ctx, user, orgID, title, uid, parentUID
+func (_m *FakeFolderService) CreateNestedFolder(ctx context.Context, usr *user.SignedInUser, orgID int64, title string, uid string, parentUID string) (*models.Folder, error) {
+	ret := _m.Called(ctx, usr, orgID, title, uid, parentUID)
+
+	var r0 *models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, int64, string, string, string) *models.Folder); ok {
+		r0 = rf(ctx, usr, orgID, title, uid, parentUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, int64, string, string, string) error); ok {
+		r1 = rf(ctx, usr, orgID, title, uid, parentUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteFolder provides a mock function with given fields: ctx, user, orgID, uid, forceDeleteRules
 func (_m *FakeFolderService) DeleteFolder(ctx context.Context, usr *user.SignedInUser, orgID int64, uid string, forceDeleteRules bool) (*models.Folder, error) {
 	ret := _m.Called(ctx, usr, orgID, uid, forceDeleteRules)
@@ -63,6 +86,29 @@ func (_m *FakeFolderService) DeleteFolder(ctx context.Context, usr *user.SignedI
 	return r0, r1
 }
 
+// GetChildFolders provides a mock function with given fields: ctx, user, orgID, parentUID
+func (_m *FakeFolderService) GetChildFolders(ctx context.Context, usr *user.SignedInUser, orgID int64, parentUID string) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, usr, orgID, parentUID)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, int64, string) []*models.Folder); ok {
+		r0 = rf(ctx, usr, orgID, parentUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, int64, string) error); ok {
+		r1 = rf(ctx, usr, orgID, parentUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFolderByID provides a mock function with given fields: ctx, user, id, orgID
 func (_m *FakeFolderService) GetFolderByID(ctx context.Context, usr *user.SignedInUser, id int64, orgID int64) (*models.Folder, error) {
 	ret := _m.Called(ctx, usr, id, orgID)
@@ -132,6 +178,29 @@ func (_m *FakeFolderService) GetFolderByUID(ctx context.Context, usr *user.Signe
 	return r0, r1
 }
 
+// GetFolderParents provides a mock function with given fields: ctx, user, orgID, uid
+func (_m *FakeFolderService) GetFolderParents(ctx context.Context, usr *user.SignedInUser, orgID int64, uid string) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, usr, orgID, uid)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, int64, string) []*models.Folder); ok {
+		r0 = rf(ctx, usr, orgID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, int64, string) error); ok {
+		r1 = rf(ctx, usr, orgID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFolders provides a mock function with given fields: ctx, user, orgID, limit, page
 func (_m *FakeFolderService) GetFolders(ctx context.Context, usr *user.SignedInUser, orgID int64, limit int64, page int64) ([]*models.Folder, error) {
 	ret := _m.Called(ctx, usr, orgID, limit, page)
@@ -169,6 +238,20 @@ func (_m *FakeFolderService) MakeUserAdmin(ctx context.Context, orgID int64, use
 	return r0
 }
 
+// MoveFolder provides a mock function with given fields: ctx, user, orgID, uid, cmd
+func (_m *FakeFolderService) MoveFolder(ctx context.Context, usr *user.SignedInUser, orgID int64, uid string, cmd *models.MoveFolderCommand) error {
+	ret := _m.Called(ctx, usr, orgID, uid, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, int64, string, *models.MoveFolderCommand) error); ok {
+		r0 = rf(ctx, usr, orgID, uid, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFolder provides a mock function with given fields: ctx, user, orgID, existingUid, cmd
 func (_m *FakeFolderService) UpdateFolder(ctx context.Context, usr *user.SignedInUser, orgID int64, existingUid string, cmd *models.UpdateFolderCommand) error {
 	ret := _m.Called(ctx, usr, orgID, existingUid, cmd)
@@ -16,6 +16,29 @@ type FakeFolderStore struct {
 	mock.Mock
 }
 
+// GetChildFolders provides a mock function with given fields: ctx, orgID, parentID
+func (_m *FakeFolderStore) GetChildFolders(ctx context.Context, orgID int64, parentID int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, parentID)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFolderByID provides a mock function with given fields: ctx, orgID, id
 func (_m *FakeFolderStore) GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error) {
 	ret := _m.Called(ctx, orgID, id)
@@ -85,6 +108,29 @@ func (_m *FakeFolderStore) GetFolderByUID(ctx context.Context, orgID int64, uid
 	return r0, r1
 }
 
+// GetFolderParents provides a mock function with given fields: ctx, orgID, uid
+func (_m *FakeFolderStore) GetFolderParents(ctx context.Context, orgID int64, uid string) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, uid)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewFakeFolderStore creates a new instance of FakeFolderStore. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
 func NewFakeFolderStore(t testing.TB) *FakeFolderStore {
 	mock := &FakeFolderStore{}
@@ -0,0 +1,41 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestFindFolderByPath(t *testing.T) {
+	team := &models.Folder{Id: 1, Uid: "team", Title: "Team"}
+	other := &models.Folder{Id: 2, Uid: "other", Title: "Other"}
+	service := &models.Folder{Id: 3, Uid: "service", Title: "Service", ParentUid: "team"}
+	children := func(parent *models.Folder) ([]*models.Folder, error) {
+		if parent == nil {
+			return []*models.Folder{team, other}, nil
+		}
+		if parent.Uid == "team" {
+			return []*models.Folder{service}, nil
+		}
+		return []*models.Folder{}, nil
+	}
+
+	folder, err := FindFolderByPath("Team/Service", children)
+	require.NoError(t, err)
+	require.Equal(t, service, folder)
+
+	folder, err = FindFolderByPath("Team", children)
+	require.NoError(t, err)
+	require.Equal(t, team, folder)
+
+	_, err = FindFolderByPath("Other/Service", children)
+	require.ErrorIs(t, err, ErrFolderNotFound)
+
+	_, err = FindFolderByPath("a/b/c/d/e", children)
+	require.ErrorIs(t, err, ErrFolderNotFound)
+
+	require.Equal(t, "Team/Service", FolderPath([]string{"Team"}, "Service"))
+	require.Equal(t, "Team", FolderPath(nil, "Team"))
+}
@@ -87,7 +87,7 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		return nil, dashboards.ErrDashboardTitleEmpty
 	}
 
-	if dash.IsFolder && dash.FolderId > 0 {
+	if dash.IsFolder && dash.FolderId > 0 && !dr.features.IsEnabled(featuremgmt.FlagNestedFolders) {
 		return nil, dashboards.ErrDashboardFolderCannotHaveParent
 	}
 
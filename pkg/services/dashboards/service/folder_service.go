@@ -39,6 +39,9 @@ func ProvideFolderService(
 ) *FolderServiceImpl {
 	ac.RegisterScopeAttributeResolver(dashboards.NewFolderNameScopeResolver(dashboardStore))
 	ac.RegisterScopeAttributeResolver(dashboards.NewFolderIDScopeResolver(dashboardStore))
+	if features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		ac.RegisterScopeAttributeResolver(dashboards.NewFolderUIDScopeResolver(dashboardStore))
+	}
 
 	return &FolderServiceImpl{
 		cfg:              cfg,
@@ -135,10 +138,75 @@ func (f *FolderServiceImpl) GetFolderByTitle(ctx context.Context, user *user.Sig
 	return dashFolder, nil
 }
 
+func (f *FolderServiceImpl) GetFolderParents(ctx context.Context, user *user.SignedInUser, orgID int64, uid string) ([]*models.Folder, error) {
+	if _, err := f.GetFolderByUID(ctx, user, orgID, uid); err != nil {
+		return nil, err
+	}
+
+	parents, err := f.dashboardStore.GetFolderParents(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.filterViewableFolders(ctx, user, orgID, parents)
+}
+
+func (f *FolderServiceImpl) GetChildFolders(ctx context.Context, user *user.SignedInUser, orgID int64, parentUID string) ([]*models.Folder, error) {
+	var parentID int64
+	if parentUID != "" {
+		parent, err := f.GetFolderByUID(ctx, user, orgID, parentUID)
+		if err != nil {
+			return nil, err
+		}
+		parentID = parent.Id
+	}
+
+	children, err := f.dashboardStore.GetChildFolders(ctx, orgID, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.filterViewableFolders(ctx, user, orgID, children)
+}
+
+func (f *FolderServiceImpl) filterViewableFolders(ctx context.Context, user *user.SignedInUser, orgID int64, folders []*models.Folder) ([]*models.Folder, error) {
+	result := make([]*models.Folder, 0, len(folders))
+	for _, folder := range folders {
+		g := guardian.New(ctx, folder.Id, orgID, user)
+		canView, err := g.CanView()
+		if err != nil {
+			return nil, toFolderError(err)
+		}
+		if canView {
+			result = append(result, folder)
+		}
+	}
+	return result, nil
+}
+
 func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *user.SignedInUser, orgID int64, title, uid string) (*models.Folder, error) {
+	return f.CreateNestedFolder(ctx, user, orgID, title, uid, "")
+}
+
+func (f *FolderServiceImpl) CreateNestedFolder(ctx context.Context, user *user.SignedInUser, orgID int64, title, uid, parentUID string) (*models.Folder, error) {
+	if err := f.validateTitle(title); err != nil {
+		return nil, err
+	}
+
 	dashFolder := models.NewDashboardFolder(title)
 	dashFolder.OrgId = orgID
 
+	if parentUID != "" {
+		parent, ancestors, err := f.getNestingParent(ctx, user, orgID, parentUID)
+		if err != nil {
+			return nil, err
+		}
+		if len(ancestors)+2 > dashboards.MaxNestedFolderDepth {
+			return nil, dashboards.ErrFolderTooDeep
+		}
+		dashFolder.FolderId = parent.Id
+	}
+
 	trimmedUID := strings.TrimSpace(uid)
 	if trimmedUID == accesscontrol.GeneralFolderUID {
 		return nil, dashboards.ErrFolderInvalidUID
@@ -215,6 +283,11 @@ func (f *FolderServiceImpl) UpdateFolder(ctx context.Context, user *user.SignedI
 	}
 
 	cmd.UpdateDashboardModel(dashFolder, orgID, user.UserID)
+	if dashFolder.Title != currentTitle {
+		if err := f.validateTitle(dashFolder.Title); err != nil {
+			return err
+		}
+	}
 
 	dto := &dashboards.SaveDashboardDTO{
 		Dashboard: dashFolder,
@@ -255,6 +328,150 @@ func (f *FolderServiceImpl) UpdateFolder(ctx context.Context, user *user.SignedI
 	return nil
 }
 
+func (f *FolderServiceImpl) MoveFolder(ctx context.Context, user *user.SignedInUser, orgID int64, uid string, cmd *models.MoveFolderCommand) error {
+	if !f.features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		return dashboards.ErrDashboardFolderCannotHaveParent
+	}
+
+	query := models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	dashFolder, err := f.dashboardStore.GetDashboard(ctx, &query)
+	if err != nil {
+		return toFolderError(err)
+	}
+	if !dashFolder.IsFolder {
+		return dashboards.ErrFolderNotFound
+	}
+	var parentID int64
+	depth := 0
+	if cmd.ParentUid != "" {
+		if err := f.validateTitle(dashFolder.Title); err != nil {
+			return err
+		}
+		if cmd.ParentUid == uid {
+			return dashboards.ErrFolderCircularReference
+		}
+
+		parent, ancestors, err := f.getNestingParent(ctx, user, orgID, cmd.ParentUid)
+		if err != nil {
+			return err
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.Uid == uid {
+				return dashboards.ErrFolderCircularReference
+			}
+		}
+		parentID = parent.Id
+		depth = len(ancestors) + 1
+	} else {
+		g := guardian.New(ctx, 0, orgID, user)
+		if canCreate, err := g.CanCreate(0, true); err != nil || !canCreate {
+			if err != nil {
+				return toFolderError(err)
+			}
+			return dashboards.ErrFolderAccessDenied
+		}
+	}
+
+	height, err := f.getFolderTreeHeight(ctx, orgID, dashFolder.Id, dashboards.MaxNestedFolderDepth-depth)
+	if err != nil {
+		return err
+	}
+	if depth+height > dashboards.MaxNestedFolderDepth {
+		return dashboards.ErrFolderTooDeep
+	}
+
+	dashFolder.FolderId = parentID
+	dto := &dashboards.SaveDashboardDTO{
+		Dashboard: dashFolder,
+		OrgId:     orgID,
+		User:      user,
+	}
+
+	saveDashboardCmd, err := f.dashboardService.BuildSaveDashboardCommand(ctx, dto, false, false)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	dash, err := f.dashboardStore.SaveDashboard(ctx, *saveDashboardCmd)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	folder, err := f.dashboardStore.GetFolderByID(ctx, orgID, dash.Id)
+	if err != nil {
+		return err
+	}
+	cmd.Result = folder
+
+	return nil
+}
+
+// validateTitle rejects the titles containing the separator of the folder paths, which are the namespaces of the
+// alert rules of nested folders.
+func (f *FolderServiceImpl) validateTitle(title string) error {
+	if f.features.IsEnabled(featuremgmt.FlagNestedFolders) && strings.Contains(title, dashboards.FolderPathSeparator) {
+		return dashboards.ErrFolderTitleInvalid
+	}
+	return nil
+}
+
+// getNestingParent returns the folder identified by parentUID along with its ancestors,
+// after checking that nested folders are enabled and that the user can edit the parent folder.
+func (f *FolderServiceImpl) getNestingParent(ctx context.Context, user *user.SignedInUser, orgID int64, parentUID string) (*models.Folder, []*models.Folder, error) {
+	if !f.features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		return nil, nil, dashboards.ErrDashboardFolderCannotHaveParent
+	}
+
+	parent, err := f.dashboardStore.GetFolderByUID(ctx, orgID, parentUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := f.validateTitle(parent.Title); err != nil {
+		return nil, nil, err
+	}
+
+	g := guardian.New(ctx, parent.Id, orgID, user)
+	if canEdit, err := g.CanEdit(); err != nil || !canEdit {
+		if err != nil {
+			return nil, nil, toFolderError(err)
+		}
+		return nil, nil, dashboards.ErrFolderAccessDenied
+	}
+
+	ancestors, err := f.dashboardStore.GetFolderParents(ctx, orgID, parentUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parent, ancestors, nil
+}
+
+// getFolderTreeHeight returns the number of levels of the folder tree rooted at the given folder,
+// without exploring more than maxHeight+1 levels.
+func (f *FolderServiceImpl) getFolderTreeHeight(ctx context.Context, orgID int64, folderID int64, maxHeight int) (int, error) {
+	height := 1
+	level := []int64{folderID}
+	for len(level) > 0 && height <= maxHeight {
+		var next []int64
+		for _, id := range level {
+			children, err := f.dashboardStore.GetChildFolders(ctx, orgID, id)
+			if err != nil {
+				return 0, err
+			}
+			for _, child := range children {
+				next = append(next, child.Id)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		height++
+		level = next
+	}
+	return height, nil
+}
+
 func (f *FolderServiceImpl) DeleteFolder(ctx context.Context, user *user.SignedInUser, orgID int64, uid string, forceDeleteRules bool) (*models.Folder, error) {
 	dashFolder, err := f.dashboardStore.GetFolderByUID(ctx, orgID, uid)
 	if err != nil {
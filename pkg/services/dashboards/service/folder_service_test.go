@@ -32,10 +32,222 @@ func TestIntegrationProvideFolderService(t *testing.T) {
 		cfg := setting.NewCfg()
 		ac := acmock.New()
 
-		ProvideFolderService(cfg, nil, nil, nil, nil, nil, ac, busmock.New())
+		ProvideFolderService(cfg, nil, nil, nil, featuremgmt.WithFeatures(), nil, ac, busmock.New())
 
 		require.Len(t, ac.Calls.RegisterAttributeScopeResolver, 2)
 	})
+
+	t.Run("should register folder uid scope resolver when nested folders are enabled", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		ac := acmock.New()
+
+		ProvideFolderService(cfg, nil, nil, nil, featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders), nil, ac, busmock.New())
+
+		require.Len(t, ac.Calls.RegisterAttributeScopeResolver, 3)
+	})
+}
+
+func TestIntegrationNestedFolderService(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	setup := func(t *testing.T, features featuremgmt.FeatureToggles) (*FolderServiceImpl, *dashboards.FakeDashboardStore) {
+		store := dashboards.NewFakeDashboardStore(t)
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		cfg.IsFeatureToggleEnabled = features.IsEnabled
+		folderPermissions := acmock.NewMockedPermissionsService()
+		dashboardPermissions := acmock.NewMockedPermissionsService()
+		dashboardService := ProvideDashboardService(cfg, store, nil, features, folderPermissions, dashboardPermissions, acmock.New())
+
+		origNewGuardian := guardian.New
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true, CanEditValue: true, CanViewValue: true})
+		t.Cleanup(func() {
+			guardian.New = origNewGuardian
+		})
+
+		return &FolderServiceImpl{
+			cfg:              cfg,
+			log:              log.New("test-folder-service"),
+			dashboardService: dashboardService,
+			dashboardStore:   store,
+			features:         features,
+			permissions:      folderPermissions,
+			bus:              busmock.New(),
+		}, store
+	}
+
+	newFolder := func(parentUID string) *models.Folder {
+		f := models.NewFolder(util.GenerateShortUID())
+		f.Id = rand.Int63()
+		f.Uid = util.GenerateShortUID()
+		f.ParentUid = parentUID
+		return f
+	}
+
+	t.Run("When nested folders are disabled", func(t *testing.T) {
+		service, _ := setup(t, featuremgmt.WithFeatures())
+
+		t.Run("creating a nested folder should fail", func(t *testing.T) {
+			_, err := service.CreateNestedFolder(context.Background(), usr, orgID, "Folder", "", "parent")
+			require.ErrorIs(t, err, dashboards.ErrDashboardFolderCannotHaveParent)
+		})
+
+		t.Run("moving a folder should fail", func(t *testing.T) {
+			err := service.MoveFolder(context.Background(), usr, orgID, "folder", &models.MoveFolderCommand{ParentUid: "parent"})
+			require.ErrorIs(t, err, dashboards.ErrDashboardFolderCannotHaveParent)
+		})
+	})
+
+	t.Run("When nested folders are enabled", func(t *testing.T) {
+		features := featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders)
+
+		t.Run("creating a folder inside another folder should save it with the parent id", func(t *testing.T) {
+			service, store := setup(t, features)
+			root := newFolder("")
+			parent := newFolder(root.Uid)
+			store.On("GetFolderByUID", mock.Anything, orgID, parent.Uid).Return(parent, nil)
+			store.On("GetFolderParents", mock.Anything, orgID, parent.Uid).Return([]*models.Folder{root}, nil)
+			store.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+			dash := models.NewDashboardFolder("Child")
+			dash.Id = rand.Int63()
+			expected := models.DashboardToFolder(dash)
+			var savedCmd models.SaveDashboardCommand
+			store.On("SaveDashboard", mock.Anything).Run(func(args mock.Arguments) {
+				savedCmd = args.Get(0).(models.SaveDashboardCommand)
+			}).Return(dash, nil)
+			store.On("GetFolderByID", mock.Anything, orgID, dash.Id).Return(expected, nil)
+
+			actual, err := service.CreateNestedFolder(context.Background(), usr, orgID, "Child", "", parent.Uid)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+			require.Equal(t, parent.Id, savedCmd.FolderId)
+			require.True(t, savedCmd.IsFolder)
+		})
+
+		t.Run("creating a folder deeper than the maximum depth should fail", func(t *testing.T) {
+			service, store := setup(t, features)
+			ancestors := []*models.Folder{newFolder("")}
+			for len(ancestors) < dashboards.MaxNestedFolderDepth-1 {
+				ancestors = append(ancestors, newFolder(ancestors[len(ancestors)-1].Uid))
+			}
+			parent := newFolder(ancestors[len(ancestors)-1].Uid)
+			store.On("GetFolderByUID", mock.Anything, orgID, parent.Uid).Return(parent, nil)
+			store.On("GetFolderParents", mock.Anything, orgID, parent.Uid).Return(ancestors, nil)
+
+			_, err := service.CreateNestedFolder(context.Background(), usr, orgID, "Child", "", parent.Uid)
+			require.ErrorIs(t, err, dashboards.ErrFolderTooDeep)
+		})
+
+		t.Run("creating a folder with the path separator in its title should fail", func(t *testing.T) {
+			service, _ := setup(t, features)
+			_, err := service.CreateNestedFolder(context.Background(), usr, orgID, "Team/Service", "", "")
+			require.ErrorIs(t, err, dashboards.ErrFolderTitleInvalid)
+		})
+
+		t.Run("creating a folder inside a folder with the path separator in its title should fail", func(t *testing.T) {
+			service, store := setup(t, features)
+			parent := newFolder("")
+			parent.Title = "Team/Service"
+			store.On("GetFolderByUID", mock.Anything, orgID, parent.Uid).Return(parent, nil)
+
+			_, err := service.CreateNestedFolder(context.Background(), usr, orgID, "Child", "", parent.Uid)
+			require.ErrorIs(t, err, dashboards.ErrFolderTitleInvalid)
+		})
+
+		t.Run("moving a folder with the path separator in its title into another folder should fail", func(t *testing.T) {
+			service, store := setup(t, features)
+			folder := newFolder("")
+			store.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).
+				Return(&models.Dashboard{Id: folder.Id, Uid: folder.Uid, Title: "Team/Service", IsFolder: true}, nil)
+
+			err := service.MoveFolder(context.Background(), usr, orgID, folder.Uid, &models.MoveFolderCommand{ParentUid: "parent"})
+			require.ErrorIs(t, err, dashboards.ErrFolderTitleInvalid)
+		})
+
+		t.Run("moving a folder into itself should fail", func(t *testing.T) {
+			service, store := setup(t, features)
+			folder := newFolder("")
+			store.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).
+				Return(&models.Dashboard{Id: folder.Id, Uid: folder.Uid, IsFolder: true}, nil)
+
+			err := service.MoveFolder(context.Background(), usr, orgID, folder.Uid, &models.MoveFolderCommand{ParentUid: folder.Uid})
+			require.ErrorIs(t, err, dashboards.ErrFolderCircularReference)
+		})
+
+		t.Run("moving a folder into one of its descendants should fail", func(t *testing.T) {
+			service, store := setup(t, features)
+			folder := newFolder("")
+			child := newFolder(folder.Uid)
+			store.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).
+				Return(&models.Dashboard{Id: folder.Id, Uid: folder.Uid, IsFolder: true}, nil)
+			store.On("GetFolderByUID", mock.Anything, orgID, child.Uid).Return(child, nil)
+			store.On("GetFolderParents", mock.Anything, orgID, child.Uid).Return([]*models.Folder{folder}, nil)
+
+			err := service.MoveFolder(context.Background(), usr, orgID, folder.Uid, &models.MoveFolderCommand{ParentUid: child.Uid})
+			require.ErrorIs(t, err, dashboards.ErrFolderCircularReference)
+		})
+
+		t.Run("moving a folder should fail if its nested folders would exceed the maximum depth", func(t *testing.T) {
+			service, store := setup(t, features)
+			root := newFolder("")
+			parent := newFolder(root.Uid)
+			folder := newFolder("")
+			child := newFolder(folder.Uid)
+			grandchild := newFolder(child.Uid)
+			store.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).
+				Return(&models.Dashboard{Id: folder.Id, Uid: folder.Uid, IsFolder: true}, nil)
+			store.On("GetFolderByUID", mock.Anything, orgID, parent.Uid).Return(parent, nil)
+			store.On("GetFolderParents", mock.Anything, orgID, parent.Uid).Return([]*models.Folder{root}, nil)
+			store.On("GetChildFolders", mock.Anything, orgID, folder.Id).Return([]*models.Folder{child}, nil)
+			store.On("GetChildFolders", mock.Anything, orgID, child.Id).Return([]*models.Folder{grandchild}, nil)
+
+			err := service.MoveFolder(context.Background(), usr, orgID, folder.Uid, &models.MoveFolderCommand{ParentUid: parent.Uid})
+			require.ErrorIs(t, err, dashboards.ErrFolderTooDeep)
+		})
+
+		t.Run("moving a nested folder to the root level should save it without parent", func(t *testing.T) {
+			service, store := setup(t, features)
+			parent := newFolder("")
+			dash := models.NewDashboardFolder("Folder")
+			dash.Id = rand.Int63()
+			dash.Uid = util.GenerateShortUID()
+			dash.FolderId = parent.Id
+			expected := models.DashboardToFolder(dash)
+			store.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Return(dash, nil)
+			store.On("GetChildFolders", mock.Anything, orgID, dash.Id).Return([]*models.Folder{}, nil)
+			store.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+			var savedCmd models.SaveDashboardCommand
+			store.On("SaveDashboard", mock.Anything).Run(func(args mock.Arguments) {
+				savedCmd = args.Get(0).(models.SaveDashboardCommand)
+			}).Return(dash, nil)
+			store.On("GetFolderByID", mock.Anything, orgID, dash.Id).Return(expected, nil)
+
+			cmd := &models.MoveFolderCommand{}
+			err := service.MoveFolder(context.Background(), usr, orgID, dash.Uid, cmd)
+			require.NoError(t, err)
+			require.Equal(t, int64(0), savedCmd.FolderId)
+			require.Equal(t, expected, cmd.Result)
+		})
+
+		t.Run("getting the parents of a folder should only return the folders the user can view", func(t *testing.T) {
+			service, store := setup(t, features)
+			root := newFolder("")
+			folder := newFolder(root.Uid)
+			store.On("GetFolderByUID", mock.Anything, orgID, folder.Uid).Return(folder, nil)
+			store.On("GetFolderParents", mock.Anything, orgID, folder.Uid).Return([]*models.Folder{root}, nil)
+
+			parents, err := service.GetFolderParents(context.Background(), usr, orgID, folder.Uid)
+			require.NoError(t, err)
+			require.Equal(t, []*models.Folder{root}, parents)
+
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+			_, err = service.GetFolderParents(context.Background(), usr, orgID, folder.Uid)
+			require.ErrorIs(t, err, dashboards.ErrFolderAccessDenied)
+		})
+	})
 }
 
 func TestIntegrationFolderService(t *testing.T) {
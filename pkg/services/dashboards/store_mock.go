@@ -68,6 +68,29 @@ func (_m *FakeDashboardStore) FindDashboards(ctx context.Context, query *models.
 	return r0, r1
 }
 
+// GetChildFolders provides a mock function with given fields: ctx, orgID, parentID
+func (_m *FakeDashboardStore) GetChildFolders(ctx context.Context, orgID int64, parentID int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, parentID)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDashboard provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardStore) GetDashboard(ctx context.Context, query *models.GetDashboardQuery) (*models.Dashboard, error) {
 	ret := _m.Called(ctx, query)
@@ -230,6 +253,29 @@ func (_m *FakeDashboardStore) GetFolderByUID(ctx context.Context, orgID int64, u
 	return r0, r1
 }
 
+// GetFolderParents provides a mock function with given fields: ctx, orgID, uid
+func (_m *FakeDashboardStore) GetFolderParents(ctx context.Context, orgID int64, uid string) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, uid)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProvisionedDashboardData provides a mock function with given fields: name
 func (_m *FakeDashboardStore) GetProvisionedDashboardData(ctx context.Context, name string) ([]*models.DashboardProvisioning, error) {
 	ret := _m.Called(name)
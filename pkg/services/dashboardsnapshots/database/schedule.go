@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DashboardSnapshotStore implements the ScheduleStore interface
+var _ dashboardsnapshots.ScheduleStore = (*DashboardSnapshotStore)(nil)
+
+func (d *DashboardSnapshotStore) CreateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.CreateSnapshotScheduleCommand) error {
+	return d.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		schedule := &dashboardsnapshots.SnapshotSchedule{
+			Uid:            util.GenerateShortUID(),
+			OrgId:          cmd.OrgId,
+			UserId:         cmd.UserId,
+			DashboardUid:   cmd.DashboardUid,
+			Name:           cmd.Name,
+			Schedule:       cmd.Schedule,
+			TimeFrom:       cmd.TimeFrom,
+			TimeTo:         cmd.TimeTo,
+			RetentionCount: cmd.RetentionCount,
+			RetentionAge:   cmd.RetentionAge,
+			Enabled:        cmd.Enabled,
+			NextRun:        cmd.NextRun,
+			Created:        time.Now(),
+			Updated:        time.Now(),
+		}
+		if _, err := sess.Insert(schedule); err != nil {
+			return err
+		}
+
+		cmd.Result = schedule
+		return nil
+	})
+}
+
+func (d *DashboardSnapshotStore) UpdateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.UpdateSnapshotScheduleCommand) error {
+	return d.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		schedule := dashboardsnapshots.SnapshotSchedule{}
+		has, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgId, cmd.Uid).Get(&schedule)
+		if err != nil {
+			return err
+		} else if !has {
+			return dashboardsnapshots.ErrScheduleNotFound.Errorf("snapshot schedule not found")
+		}
+
+		schedule.Name = cmd.Name
+		schedule.Schedule = cmd.Schedule
+		schedule.TimeFrom = cmd.TimeFrom
+		schedule.TimeTo = cmd.TimeTo
+		schedule.RetentionCount = cmd.RetentionCount
+		schedule.RetentionAge = cmd.RetentionAge
+		schedule.Enabled = cmd.Enabled
+		schedule.NextRun = cmd.NextRun
+		schedule.Updated = time.Now()
+
+		if _, err := sess.ID(schedule.Id).AllCols().Update(&schedule); err != nil {
+			return err
+		}
+
+		cmd.Result = &schedule
+		return nil
+	})
+}
+
+// DeleteSnapshotSchedule removes a schedule. The snapshots it took are kept
+// and are no longer linked to it.
+func (d *DashboardSnapshotStore) DeleteSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.DeleteSnapshotScheduleCommand) error {
+	return d.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		schedule := dashboardsnapshots.SnapshotSchedule{}
+		has, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgId, cmd.Uid).Get(&schedule)
+		if err != nil {
+			return err
+		} else if !has {
+			return dashboardsnapshots.ErrScheduleNotFound.Errorf("snapshot schedule not found")
+		}
+
+		if _, err := sess.Exec("DELETE FROM dashboard_snapshot_schedule WHERE id = ?", schedule.Id); err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_snapshot SET schedule_id = 0 WHERE schedule_id = ?", schedule.Id)
+		return err
+	})
+}
+
+func (d *DashboardSnapshotStore) GetSnapshotSchedule(ctx context.Context, query *dashboardsnapshots.GetSnapshotScheduleQuery) error {
+	return d.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		schedule := dashboardsnapshots.SnapshotSchedule{}
+		has, err := sess.Where("org_id = ? AND uid = ?", query.OrgId, query.Uid).Get(&schedule)
+		if err != nil {
+			return err
+		} else if !has {
+			return dashboardsnapshots.ErrScheduleNotFound.Errorf("snapshot schedule not found")
+		}
+
+		query.Result = &schedule
+		return nil
+	})
+}
+
+func (d *DashboardSnapshotStore) SearchSnapshotSchedules(ctx context.Context, query *dashboardsnapshots.GetSnapshotSchedulesQuery) error {
+	return d.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		schedules := make([]*dashboardsnapshots.SnapshotSchedule, 0)
+		sess.Where("org_id = ?", query.OrgId)
+		if query.DashboardUid != "" {
+			sess.Where("dashboard_uid = ?", query.DashboardUid)
+		}
+
+		err := sess.Asc("name").Find(&schedules)
+		query.Result = schedules
+		return err
+	})
+}
+
+// GetDueSnapshotSchedules returns the enabled schedules of all organizations
+// that should have run by now.
+func (d *DashboardSnapshotStore) GetDueSnapshotSchedules(ctx context.Context, query *dashboardsnapshots.GetDueSnapshotSchedulesQuery) error {
+	return d.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		schedules := make([]*dashboardsnapshots.SnapshotSchedule, 0)
+		err := sess.Where("enabled = "+d.store.GetDialect().BooleanStr(true)+" AND next_run <= ?", query.Now).
+			Asc("next_run").Find(&schedules)
+		query.Result = schedules
+		return err
+	})
+}
+
+func (d *DashboardSnapshotStore) UpdateSnapshotScheduleRun(ctx context.Context, cmd *dashboardsnapshots.UpdateSnapshotScheduleRunCommand) error {
+	return d.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		schedule := dashboardsnapshots.SnapshotSchedule{
+			NextRun:   cmd.NextRun,
+			LastRun:   cmd.LastRun,
+			LastError: cmd.LastError,
+		}
+		_, err := sess.ID(cmd.Id).Cols("next_run", "last_run", "last_error").Update(&schedule)
+		return err
+	})
+}
+
+// GetScheduleSnapshots returns the snapshots taken by a schedule, most recent first.
+func (d *DashboardSnapshotStore) GetScheduleSnapshots(ctx context.Context, query *dashboardsnapshots.GetScheduleSnapshotsQuery) error {
+	return d.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		snapshots := make(dashboardsnapshots.DashboardSnapshotsList, 0)
+		err := sess.Table("dashboard_snapshot").
+			Where("org_id = ? AND schedule_id = ?", query.OrgId, query.ScheduleId).
+			Desc("created").Desc("id").Find(&snapshots)
+		query.Result = snapshots
+		return err
+	})
+}
+
+// DeleteScheduleSnapshots removes the snapshots of a schedule beyond the most
+// recent KeepCount ones as well as the ones created before OlderThan.
+func (d *DashboardSnapshotStore) DeleteScheduleSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteScheduleSnapshotsCommand) error {
+	return d.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var snapshots []struct {
+			Id      int64
+			Created time.Time
+		}
+		err := sess.Table("dashboard_snapshot").Cols("id", "created").
+			Where("schedule_id = ?", cmd.ScheduleId).
+			Desc("created").Desc("id").Find(&snapshots)
+		if err != nil {
+			return err
+		}
+
+		args := make([]interface{}, 0)
+		for i, snapshot := range snapshots {
+			if (cmd.KeepCount > 0 && int64(i) >= cmd.KeepCount) || (!cmd.OlderThan.IsZero() && snapshot.Created.Before(cmd.OlderThan)) {
+				args = append(args, snapshot.Id)
+			}
+		}
+		if len(args) == 0 {
+			return nil
+		}
+
+		rawSQL := "DELETE FROM dashboard_snapshot WHERE id IN (?" + strings.Repeat(",?", len(args)-1) + ")"
+		res, err := sess.Exec(append([]interface{}{rawSQL}, args...)...)
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, _ = res.RowsAffected()
+		return nil
+	})
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationSnapshotScheduleDBAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	dashStore := ProvideStore(sqlStore)
+	ctx := context.Background()
+
+	nextRun := time.Now().Add(-time.Minute).Truncate(time.Second)
+	createCmd := dashboardsnapshots.CreateSnapshotScheduleCommand{
+		DashboardUid:   "slo",
+		Name:           "SLO",
+		Schedule:       "@daily",
+		RetentionCount: 2,
+		Enabled:        true,
+		OrgId:          1,
+		UserId:         10,
+		NextRun:        nextRun,
+	}
+	require.NoError(t, dashStore.CreateSnapshotSchedule(ctx, &createCmd))
+	schedule := createCmd.Result
+	require.NotEmpty(t, schedule.Uid)
+
+	t.Run("Should get the schedule by uid in its org only", func(t *testing.T) {
+		query := dashboardsnapshots.GetSnapshotScheduleQuery{Uid: schedule.Uid, OrgId: 1}
+		require.NoError(t, dashStore.GetSnapshotSchedule(ctx, &query))
+		assert.Equal(t, "SLO", query.Result.Name)
+		assert.Equal(t, "@daily", query.Result.Schedule)
+
+		query = dashboardsnapshots.GetSnapshotScheduleQuery{Uid: schedule.Uid, OrgId: 2}
+		require.ErrorIs(t, dashStore.GetSnapshotSchedule(ctx, &query), dashboardsnapshots.ErrScheduleNotFound)
+	})
+
+	t.Run("Should search schedules by dashboard", func(t *testing.T) {
+		query := dashboardsnapshots.GetSnapshotSchedulesQuery{OrgId: 1, DashboardUid: "slo"}
+		require.NoError(t, dashStore.SearchSnapshotSchedules(ctx, &query))
+		require.Len(t, query.Result, 1)
+
+		query = dashboardsnapshots.GetSnapshotSchedulesQuery{OrgId: 1, DashboardUid: "other"}
+		require.NoError(t, dashStore.SearchSnapshotSchedules(ctx, &query))
+		require.Empty(t, query.Result)
+	})
+
+	t.Run("Should only return enabled schedules that are due", func(t *testing.T) {
+		query := dashboardsnapshots.GetDueSnapshotSchedulesQuery{Now: time.Now()}
+		require.NoError(t, dashStore.GetDueSnapshotSchedules(ctx, &query))
+		require.Len(t, query.Result, 1)
+
+		query = dashboardsnapshots.GetDueSnapshotSchedulesQuery{Now: nextRun.Add(-time.Minute)}
+		require.NoError(t, dashStore.GetDueSnapshotSchedules(ctx, &query))
+		require.Empty(t, query.Result)
+
+		updateCmd := dashboardsnapshots.UpdateSnapshotScheduleCommand{
+			Uid:      schedule.Uid,
+			OrgId:    1,
+			Name:     "SLO",
+			Schedule: "@daily",
+			Enabled:  false,
+			NextRun:  nextRun,
+		}
+		require.NoError(t, dashStore.UpdateSnapshotSchedule(ctx, &updateCmd))
+
+		query = dashboardsnapshots.GetDueSnapshotSchedulesQuery{Now: time.Now()}
+		require.NoError(t, dashStore.GetDueSnapshotSchedules(ctx, &query))
+		require.Empty(t, query.Result)
+
+		updateCmd.Enabled = true
+		require.NoError(t, dashStore.UpdateSnapshotSchedule(ctx, &updateCmd))
+	})
+
+	t.Run("Should record the outcome of a run", func(t *testing.T) {
+		lastRun := time.Now().Truncate(time.Second)
+		err := dashStore.UpdateSnapshotScheduleRun(ctx, &dashboardsnapshots.UpdateSnapshotScheduleRunCommand{
+			Id:        schedule.Id,
+			NextRun:   lastRun.Add(24 * time.Hour),
+			LastRun:   lastRun,
+			LastError: "boom",
+		})
+		require.NoError(t, err)
+
+		query := dashboardsnapshots.GetSnapshotScheduleQuery{Uid: schedule.Uid, OrgId: 1}
+		require.NoError(t, dashStore.GetSnapshotSchedule(ctx, &query))
+		assert.Equal(t, "boom", query.Result.LastError)
+		assert.True(t, lastRun.Equal(query.Result.LastRun))
+		assert.True(t, lastRun.Add(24*time.Hour).Equal(query.Result.NextRun))
+		assert.Equal(t, "SLO", query.Result.Name)
+	})
+
+	t.Run("Should list and apply the retention to the snapshots of the schedule", func(t *testing.T) {
+		for _, key := range []string{"first", "second", "third", "fourth"} {
+			cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
+				Key:        key,
+				DeleteKey:  "delete" + key,
+				Dashboard:  simplejson.New(),
+				OrgId:      1,
+				ScheduleId: schedule.Id,
+			}
+			require.NoError(t, dashStore.CreateDashboardSnapshot(ctx, &cmd))
+		}
+		createTestSnapshot(t, dashStore, "manual", 1000)
+
+		err := sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("UPDATE dashboard_snapshot SET created = ? WHERE dashboard_snapshot.key = ?", time.Now().Add(-48*time.Hour), "first")
+			return err
+		})
+		require.NoError(t, err)
+
+		history := dashboardsnapshots.GetScheduleSnapshotsQuery{ScheduleId: schedule.Id, OrgId: 1}
+		require.NoError(t, dashStore.GetScheduleSnapshots(ctx, &history))
+		require.Len(t, history.Result, 4)
+		assert.Equal(t, "first", history.Result[3].Key)
+
+		deleteCmd := dashboardsnapshots.DeleteScheduleSnapshotsCommand{ScheduleId: schedule.Id, OlderThan: time.Now().Add(-24 * time.Hour)}
+		require.NoError(t, dashStore.DeleteScheduleSnapshots(ctx, &deleteCmd))
+		assert.Equal(t, int64(1), deleteCmd.DeletedRows)
+
+		deleteCmd = dashboardsnapshots.DeleteScheduleSnapshotsCommand{ScheduleId: schedule.Id, KeepCount: 2}
+		require.NoError(t, dashStore.DeleteScheduleSnapshots(ctx, &deleteCmd))
+		assert.Equal(t, int64(1), deleteCmd.DeletedRows)
+
+		require.NoError(t, dashStore.GetScheduleSnapshots(ctx, &history))
+		require.Len(t, history.Result, 2)
+		assert.Equal(t, "fourth", history.Result[0].Key)
+		assert.Equal(t, "third", history.Result[1].Key)
+
+		manual := dashboardsnapshots.GetDashboardSnapshotQuery{Key: "manual"}
+		require.NoError(t, dashStore.GetDashboardSnapshot(ctx, &manual))
+	})
+
+	t.Run("Should keep the snapshots when deleting the schedule", func(t *testing.T) {
+		err := dashStore.DeleteSnapshotSchedule(ctx, &dashboardsnapshots.DeleteSnapshotScheduleCommand{Uid: schedule.Uid, OrgId: 1})
+		require.NoError(t, err)
+
+		query := dashboardsnapshots.GetSnapshotScheduleQuery{Uid: schedule.Uid, OrgId: 1}
+		require.ErrorIs(t, dashStore.GetSnapshotSchedule(ctx, &query), dashboardsnapshots.ErrScheduleNotFound)
+
+		snapshot := dashboardsnapshots.GetDashboardSnapshotQuery{Key: "fourth"}
+		require.NoError(t, dashStore.GetDashboardSnapshot(ctx, &snapshot))
+		assert.Zero(t, snapshot.Result.ScheduleId)
+
+		err = dashStore.DeleteSnapshotSchedule(ctx, &dashboardsnapshots.DeleteSnapshotScheduleCommand{Uid: schedule.Uid, OrgId: 1})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrScheduleNotFound)
+	})
+}
@@ -5,3 +5,11 @@ import (
 )
 
 var ErrBaseNotFound = errutil.NewBase(errutil.StatusNotFound, "dashboardsnapshots.not-found", errutil.WithPublicMessage("Snapshot not found"))
+
+var (
+	ErrScheduleNotFound         = errutil.NewBase(errutil.StatusNotFound, "dashboardsnapshots.schedule-not-found", errutil.WithPublicMessage("Snapshot schedule not found"))
+	ErrScheduleInvalidCron      = errutil.NewBase(errutil.StatusBadRequest, "dashboardsnapshots.schedule-invalid-cron", errutil.WithPublicMessage("Invalid schedule, expected a cron expression"))
+	ErrScheduleInvalidTimeRange = errutil.NewBase(errutil.StatusBadRequest, "dashboardsnapshots.schedule-invalid-time-range", errutil.WithPublicMessage("Invalid snapshot time range"))
+	ErrScheduleInvalidRetention = errutil.NewBase(errutil.StatusBadRequest, "dashboardsnapshots.schedule-invalid-retention", errutil.WithPublicMessage("Retention count and age cannot be negative"))
+	ErrScheduleOwner            = errutil.NewBase(errutil.StatusForbidden, "dashboardsnapshots.schedule-owner", errutil.WithPublicMessage("The owner of the schedule cannot query the dashboard"))
+)
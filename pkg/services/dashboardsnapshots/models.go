@@ -18,6 +18,7 @@ type DashboardSnapshot struct {
 	External          bool
 	ExternalUrl       string
 	ExternalDeleteUrl string
+	ScheduleId        int64
 
 	Expires time.Time
 	Created time.Time
@@ -73,8 +74,9 @@ type CreateDashboardSnapshotCommand struct {
 	// required:false
 	DeleteKey string `json:"deleteKey"`
 
-	OrgId  int64 `json:"-"`
-	UserId int64 `json:"-"`
+	OrgId      int64 `json:"-"`
+	UserId     int64 `json:"-"`
+	ScheduleId int64 `json:"-"`
 
 	DashboardEncrypted []byte `json:"-"`
 
@@ -106,3 +108,151 @@ type GetDashboardSnapshotsQuery struct {
 
 	Result DashboardSnapshotsList
 }
+
+// SnapshotSchedule model
+type SnapshotSchedule struct {
+	Id           int64  `json:"id"`
+	Uid          string `json:"uid"`
+	OrgId        int64  `json:"orgId"`
+	UserId       int64  `json:"userId"`
+	DashboardUid string `json:"dashboardUid"`
+	Name         string `json:"name"`
+	// Cron expression deciding when a snapshot is taken.
+	Schedule string `json:"schedule"`
+	// Time range of the snapshot, the dashboard time range is used when empty.
+	TimeFrom string `json:"timeFrom"`
+	TimeTo   string `json:"timeTo"`
+	// Number of snapshots to keep, 0 keeps all of them.
+	RetentionCount int64 `json:"retentionCount"`
+	// Age in seconds after which snapshots are removed, 0 keeps them forever.
+	RetentionAge int64 `json:"retentionAge"`
+	Enabled      bool  `json:"enabled"`
+
+	NextRun   time.Time `json:"nextRun"`
+	LastRun   time.Time `json:"lastRun"`
+	LastError string    `json:"lastError"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+func (s SnapshotSchedule) TableName() string {
+	return "dashboard_snapshot_schedule"
+}
+
+// swagger:model
+type CreateSnapshotScheduleCommand struct {
+	// UID of the dashboard to take snapshots of.
+	// required:true
+	DashboardUid string `json:"dashboardUid" binding:"Required"`
+	// Schedule name, the dashboard title is used when empty.
+	// required:false
+	Name string `json:"name"`
+	// Cron expression, for example `0 6 * * *` or `@daily`.
+	// required:true
+	Schedule string `json:"schedule" binding:"Required"`
+	// Start of the snapshot time range, for example `now-24h`.
+	// required:false
+	TimeFrom string `json:"timeFrom"`
+	// End of the snapshot time range, for example `now`.
+	// required:false
+	TimeTo string `json:"timeTo"`
+	// Number of snapshots to keep. Default is to keep all of them.
+	// required:false
+	// default:0
+	RetentionCount int64 `json:"retentionCount"`
+	// Age in seconds after which snapshots are removed. Default is to keep them forever.
+	// required:false
+	// default:0
+	RetentionAge int64 `json:"retentionAge"`
+	// required:false
+	// default:false
+	Enabled bool `json:"enabled"`
+
+	OrgId   int64     `json:"-"`
+	UserId  int64     `json:"-"`
+	NextRun time.Time `json:"-"`
+
+	Result *SnapshotSchedule
+}
+
+// swagger:model
+type UpdateSnapshotScheduleCommand struct {
+	// required:false
+	Name string `json:"name"`
+	// required:true
+	Schedule string `json:"schedule" binding:"Required"`
+	// required:false
+	TimeFrom string `json:"timeFrom"`
+	// required:false
+	TimeTo string `json:"timeTo"`
+	// required:false
+	RetentionCount int64 `json:"retentionCount"`
+	// required:false
+	RetentionAge int64 `json:"retentionAge"`
+	// required:false
+	Enabled bool `json:"enabled"`
+
+	Uid     string    `json:"-"`
+	OrgId   int64     `json:"-"`
+	NextRun time.Time `json:"-"`
+
+	Result *SnapshotSchedule
+}
+
+type DeleteSnapshotScheduleCommand struct {
+	Uid   string
+	OrgId int64
+}
+
+type RunSnapshotScheduleCommand struct {
+	Uid   string
+	OrgId int64
+
+	Result *DashboardSnapshot
+}
+
+type GetSnapshotScheduleQuery struct {
+	Uid   string
+	OrgId int64
+
+	Result *SnapshotSchedule
+}
+
+type GetSnapshotSchedulesQuery struct {
+	OrgId        int64
+	DashboardUid string
+
+	Result []*SnapshotSchedule
+}
+
+type GetDueSnapshotSchedulesQuery struct {
+	Now time.Time
+
+	Result []*SnapshotSchedule
+}
+
+type UpdateSnapshotScheduleRunCommand struct {
+	Id        int64
+	NextRun   time.Time
+	LastRun   time.Time
+	LastError string
+}
+
+type GetScheduleSnapshotsQuery struct {
+	ScheduleId int64
+	OrgId      int64
+
+	Result DashboardSnapshotsList
+}
+
+// DeleteScheduleSnapshotsCommand applies the retention policy of a schedule.
+type DeleteScheduleSnapshotsCommand struct {
+	ScheduleId int64
+	// KeepCount is the number of most recent snapshots to keep, 0 keeps all of them.
+	KeepCount int64
+	// OlderThan removes the snapshots created before, ignored when zero.
+	OlderThan time.Time
+
+	DeletedRows int64
+}
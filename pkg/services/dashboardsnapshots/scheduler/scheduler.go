@@ -0,0 +1,353 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	scheduleCheckInterval = 30 * time.Second
+	snapshotTimeout       = 5 * time.Minute
+)
+
+type queryDataService interface {
+	QueryDataMultipleSources(ctx context.Context, user *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest, handleExpressions bool) (*backend.QueryDataResponse, error)
+}
+
+type lockService interface {
+	LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+// Scheduler takes snapshots of dashboards according to the snapshot schedules
+// and removes the snapshots that fall out of their retention policy.
+type Scheduler struct {
+	store            dashboardsnapshots.ScheduleStore
+	snapshotService  dashboardsnapshots.Service
+	dashboardService dashboards.DashboardService
+	queryDataService queryDataService
+	userService      user.Service
+	accessControl    accesscontrol.AccessControl
+	acService        accesscontrol.Service
+	serverLock       lockService
+	features         featuremgmt.FeatureToggles
+	log              log.Logger
+	now              func() time.Time
+}
+
+// Scheduler implements the dashboardsnapshots ScheduleService interface
+var _ dashboardsnapshots.ScheduleService = (*Scheduler)(nil)
+
+func ProvideService(store dashboardsnapshots.ScheduleStore, snapshotService dashboardsnapshots.Service,
+	dashboardService dashboards.DashboardService, queryDataService *query.Service, userService user.Service,
+	accessControl accesscontrol.AccessControl, acService accesscontrol.Service,
+	serverLock *serverlock.ServerLockService, features featuremgmt.FeatureToggles) *Scheduler {
+	return &Scheduler{
+		store:            store,
+		snapshotService:  snapshotService,
+		dashboardService: dashboardService,
+		queryDataService: queryDataService,
+		userService:      userService,
+		accessControl:    accessControl,
+		acService:        acService,
+		serverLock:       serverLock,
+		features:         features,
+		log:              log.New("dashboardsnapshots.scheduler"),
+		now:              time.Now,
+	}
+}
+
+func (s *Scheduler) IsDisabled() bool {
+	return !s.features.IsEnabled(featuremgmt.FlagScheduledDashboardSnapshots)
+}
+
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runDueSchedules(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Scheduler) CreateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.CreateSnapshotScheduleCommand) error {
+	nextRun, err := s.validate(cmd.Schedule, cmd.TimeFrom, cmd.TimeTo, cmd.RetentionCount, cmd.RetentionAge)
+	if err != nil {
+		return err
+	}
+
+	query := &models.GetDashboardQuery{Uid: cmd.DashboardUid, OrgId: cmd.OrgId}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return err
+	}
+
+	if cmd.Name == "" {
+		cmd.Name = query.Result.Title
+	}
+	cmd.NextRun = nextRun
+
+	return s.store.CreateSnapshotSchedule(ctx, cmd)
+}
+
+func (s *Scheduler) UpdateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.UpdateSnapshotScheduleCommand) error {
+	nextRun, err := s.validate(cmd.Schedule, cmd.TimeFrom, cmd.TimeTo, cmd.RetentionCount, cmd.RetentionAge)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Name == "" {
+		query := &dashboardsnapshots.GetSnapshotScheduleQuery{Uid: cmd.Uid, OrgId: cmd.OrgId}
+		if err := s.store.GetSnapshotSchedule(ctx, query); err != nil {
+			return err
+		}
+		cmd.Name = query.Result.Name
+	}
+	cmd.NextRun = nextRun
+
+	return s.store.UpdateSnapshotSchedule(ctx, cmd)
+}
+
+func (s *Scheduler) DeleteSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.DeleteSnapshotScheduleCommand) error {
+	return s.store.DeleteSnapshotSchedule(ctx, cmd)
+}
+
+func (s *Scheduler) GetSnapshotSchedule(ctx context.Context, query *dashboardsnapshots.GetSnapshotScheduleQuery) error {
+	return s.store.GetSnapshotSchedule(ctx, query)
+}
+
+func (s *Scheduler) SearchSnapshotSchedules(ctx context.Context, query *dashboardsnapshots.GetSnapshotSchedulesQuery) error {
+	return s.store.SearchSnapshotSchedules(ctx, query)
+}
+
+func (s *Scheduler) GetScheduleSnapshots(ctx context.Context, query *dashboardsnapshots.GetScheduleSnapshotsQuery) error {
+	return s.store.GetScheduleSnapshots(ctx, query)
+}
+
+// RunSnapshotSchedule takes a snapshot right away, without changing when the schedule runs next.
+func (s *Scheduler) RunSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.RunSnapshotScheduleCommand) error {
+	query := &dashboardsnapshots.GetSnapshotScheduleQuery{Uid: cmd.Uid, OrgId: cmd.OrgId}
+	if err := s.store.GetSnapshotSchedule(ctx, query); err != nil {
+		return err
+	}
+
+	snapshot, err := s.runSchedule(ctx, query.Result, query.Result.NextRun, s.now())
+	cmd.Result = snapshot
+	return err
+}
+
+// validate checks the settings of a schedule and returns when it should run next.
+func (s *Scheduler) validate(schedule, timeFrom, timeTo string, retentionCount, retentionAge int64) (time.Time, error) {
+	if retentionCount < 0 || retentionAge < 0 {
+		return time.Time{}, dashboardsnapshots.ErrScheduleInvalidRetention.Errorf("negative retention count %d or age %d", retentionCount, retentionAge)
+	}
+
+	timeRange := legacydata.DataTimeRange{From: timeFrom, To: timeTo, Now: s.now()}
+	if timeFrom != "" {
+		if _, err := timeRange.ParseFrom(); err != nil {
+			return time.Time{}, dashboardsnapshots.ErrScheduleInvalidTimeRange.Errorf("invalid time from %q: %w", timeFrom, err)
+		}
+	}
+	if timeTo != "" {
+		if _, err := timeRange.ParseTo(); err != nil {
+			return time.Time{}, dashboardsnapshots.ErrScheduleInvalidTimeRange.Errorf("invalid time to %q: %w", timeTo, err)
+		}
+	}
+
+	return nextRunAfter(schedule, s.now())
+}
+
+func nextRunAfter(schedule string, t time.Time) (time.Time, error) {
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, dashboardsnapshots.ErrScheduleInvalidCron.Errorf("invalid cron expression %q: %w", schedule, err)
+	}
+	return parsed.Next(t), nil
+}
+
+// runDueSchedules runs the schedules that should have run by now. When running
+// in high availability, the server lock makes sure only one instance runs a schedule.
+func (s *Scheduler) runDueSchedules(ctx context.Context) {
+	now := s.now()
+	query := &dashboardsnapshots.GetDueSnapshotSchedulesQuery{Now: now}
+	if err := s.store.GetDueSnapshotSchedules(ctx, query); err != nil {
+		s.log.Error("Failed to get due snapshot schedules", "error", err)
+		return
+	}
+
+	for _, schedule := range query.Result {
+		schedule := schedule
+		actionName := "dashboard snapshot schedule " + strconv.FormatInt(schedule.Id, 10)
+		err := s.serverLock.LockAndExecute(ctx, actionName, scheduleCheckInterval, func(ctx context.Context) {
+			nextRun, err := nextRunAfter(schedule.Schedule, now)
+			if err != nil {
+				s.log.Error("Failed to compute next run of snapshot schedule", "uid", schedule.Uid, "orgId", schedule.OrgId, "error", err)
+				return
+			}
+
+			// Move the next run forward before taking the snapshot, so that
+			// a failing snapshot is not retried on every check.
+			if err := s.store.UpdateSnapshotScheduleRun(ctx, &dashboardsnapshots.UpdateSnapshotScheduleRunCommand{
+				Id:        schedule.Id,
+				NextRun:   nextRun,
+				LastRun:   schedule.LastRun,
+				LastError: schedule.LastError,
+			}); err != nil {
+				s.log.Error("Failed to update snapshot schedule", "uid", schedule.Uid, "orgId", schedule.OrgId, "error", err)
+				return
+			}
+
+			if _, err := s.runSchedule(ctx, schedule, nextRun, now); err != nil {
+				s.log.Warn("Failed to take scheduled snapshot", "uid", schedule.Uid, "orgId", schedule.OrgId, "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to run snapshot schedule", "uid", schedule.Uid, "orgId", schedule.OrgId, "error", err)
+		}
+	}
+}
+
+// runSchedule takes a snapshot, records the outcome on the schedule and applies its retention policy.
+func (s *Scheduler) runSchedule(ctx context.Context, schedule *dashboardsnapshots.SnapshotSchedule, nextRun, now time.Time) (*dashboardsnapshots.DashboardSnapshot, error) {
+	snapshot, runErr := s.takeSnapshot(ctx, schedule, now)
+
+	lastError := ""
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+	if err := s.store.UpdateSnapshotScheduleRun(ctx, &dashboardsnapshots.UpdateSnapshotScheduleRunCommand{
+		Id:        schedule.Id,
+		NextRun:   nextRun,
+		LastRun:   now,
+		LastError: lastError,
+	}); err != nil {
+		s.log.Error("Failed to update snapshot schedule", "uid", schedule.Uid, "orgId", schedule.OrgId, "error", err)
+	}
+
+	if snapshot != nil {
+		s.applyRetention(ctx, schedule, now)
+	}
+
+	return snapshot, runErr
+}
+
+func (s *Scheduler) applyRetention(ctx context.Context, schedule *dashboardsnapshots.SnapshotSchedule, now time.Time) {
+	if schedule.RetentionCount == 0 && schedule.RetentionAge == 0 {
+		return
+	}
+
+	cmd := &dashboardsnapshots.DeleteScheduleSnapshotsCommand{
+		ScheduleId: schedule.Id,
+		KeepCount:  schedule.RetentionCount,
+	}
+	if schedule.RetentionAge > 0 {
+		cmd.OlderThan = now.Add(-time.Duration(schedule.RetentionAge) * time.Second)
+	}
+
+	if err := s.store.DeleteScheduleSnapshots(ctx, cmd); err != nil {
+		s.log.Error("Failed to apply snapshot schedule retention", "uid", schedule.Uid, "orgId", schedule.OrgId, "error", err)
+		return
+	}
+	s.log.Debug("Applied snapshot schedule retention", "uid", schedule.Uid, "orgId", schedule.OrgId, "deleted", cmd.DeletedRows)
+}
+
+// takeSnapshot queries the panels of the dashboard as the owner of the schedule and stores their data in a new snapshot.
+// It fails when the owner does not exist anymore or cannot query the data sources of the dashboard. A snapshot is still created when some of the panels fail to query, in which case
+// both the snapshot and an error are returned.
+func (s *Scheduler) takeSnapshot(ctx context.Context, schedule *dashboardsnapshots.SnapshotSchedule, now time.Time) (*dashboardsnapshots.DashboardSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+
+	query := &models.GetDashboardQuery{Uid: schedule.DashboardUid, OrgId: schedule.OrgId}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return nil, err
+	}
+
+	encoded, err := query.Result.Data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	dashboard, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	timeRange := legacydata.DataTimeRange{
+		From: schedule.TimeFrom,
+		To:   schedule.TimeTo,
+		Now:  now,
+	}
+	if timeRange.From == "" {
+		timeRange.From = dashboard.GetPath("time", "from").MustString("now-6h")
+	}
+	if timeRange.To == "" {
+		timeRange.To = dashboard.GetPath("time", "to").MustString("now")
+	}
+	from, err := timeRange.ParseFrom()
+	if err != nil {
+		return nil, dashboardsnapshots.ErrScheduleInvalidTimeRange.Errorf("invalid time from %q: %w", timeRange.From, err)
+	}
+	to, err := timeRange.ParseTo()
+	if err != nil {
+		return nil, dashboardsnapshots.ErrScheduleInvalidTimeRange.Errorf("invalid time to %q: %w", timeRange.To, err)
+	}
+
+	owner, err := s.scheduleOwner(ctx, schedule, dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s %s", schedule.Name, now.UTC().Format(time.RFC3339))
+	panelErrs := s.snapshotPanels(ctx, owner, dashboard, from, to)
+	scrubDashboard(dashboard, name, from, to, now)
+
+	key, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	deleteKey, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := &dashboardsnapshots.CreateDashboardSnapshotCommand{
+		Dashboard:  dashboard,
+		Name:       name,
+		Expires:    schedule.RetentionAge,
+		Key:        key,
+		DeleteKey:  deleteKey,
+		OrgId:      schedule.OrgId,
+		UserId:     schedule.UserId,
+		ScheduleId: schedule.Id,
+	}
+	if err := s.snapshotService.CreateDashboardSnapshot(ctx, cmd); err != nil {
+		return nil, err
+	}
+
+	if len(panelErrs) > 0 {
+		return cmd.Result, fmt.Errorf("failed to query %d panel(s), first error: %w", len(panelErrs), panelErrs[0])
+	}
+	return cmd.Result, nil
+}
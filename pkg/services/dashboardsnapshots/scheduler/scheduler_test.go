@@ -0,0 +1,270 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	dashsnapstore "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+const sloDashboard = `{
+	"uid": "slo",
+	"title": "SLO",
+	"time": {"from": "now-24h", "to": "now"},
+	"links": [{"title": "docs"}],
+	"panels": [
+		{"id": 1, "type": "timeseries", "datasource": {"uid": "prom"}, "targets": [{"refId": "A", "expr": "up{env=\"$env\"}"}, {"refId": "B", "expr": "down", "hide": true}]},
+		{"id": 2, "type": "row", "collapsed": true, "panels": [
+			{"id": 3, "type": "stat", "datasource": {"uid": "loki"}, "targets": [{"refId": "A", "expr": "errors"}]}
+		]},
+		{"id": 4, "type": "text"}
+	],
+	"annotations": {"list": [{"name": "Annotations & Alerts", "enable": true, "builtIn": 1, "datasource": {"uid": "grafana"}}]},
+	"templating": {"list": [{"name": "env", "query": "label_values(env)", "current": {"text": "prod", "value": "prod"}, "refresh": 1}]}
+}`
+
+type fakeQueryDataService struct {
+	requests []dtos.MetricRequest
+	user     *user.SignedInUser
+	err      error
+}
+
+func (f *fakeQueryDataService) QueryDataMultipleSources(_ context.Context, user *user.SignedInUser, _ bool, reqDTO dtos.MetricRequest, _ bool) (*backend.QueryDataResponse, error) {
+	f.requests = append(f.requests, reqDTO)
+	f.user = user
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	res := backend.NewQueryDataResponse()
+	for _, query := range reqDTO.Queries {
+		refID := query.Get("refId").MustString()
+		res.Responses[refID] = backend.DataResponse{Frames: data.Frames{
+			data.NewFrame(query.Get("expr").MustString(),
+				data.NewField("time", nil, []time.Time{time.UnixMilli(1000), time.UnixMilli(2000)}),
+				data.NewField("value", data.Labels{"job": "api"}, []*float64{floatPtr(1), floatPtr(math.NaN())}),
+			),
+		}}
+	}
+	return res, nil
+}
+
+type fakeLockService struct{}
+
+func (f *fakeLockService) LockAndExecute(ctx context.Context, _ string, _ time.Duration, fn func(ctx context.Context)) error {
+	fn(ctx)
+	return nil
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func setupScheduler(t *testing.T) (*Scheduler, *fakeQueryDataService, *dashsnapstore.DashboardSnapshotStore) {
+	t.Helper()
+
+	sqlStore := sqlstore.InitTestDB(t)
+	store := dashsnapstore.ProvideStore(sqlStore)
+	queryDataService := &fakeQueryDataService{}
+
+	dashboardData, err := simplejson.NewJson([]byte(sloDashboard))
+	require.NoError(t, err)
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*models.GetDashboardQuery)
+		q.Result = models.NewDashboardFromJson(dashboardData)
+		q.Result.OrgId = q.OrgId
+	}).Return(func(_ context.Context, q *models.GetDashboardQuery) error {
+		if q.Uid != "slo" {
+			return dashboards.ErrDashboardNotFound
+		}
+		return nil
+	}).Maybe()
+
+	acMock := accesscontrolmock.New().WithPermissions([]accesscontrol.Permission{
+		{Action: datasources.ActionQuery, Scope: "datasources:uid:prom"},
+		{Action: datasources.ActionQuery, Scope: "datasources:uid:loki"},
+	})
+
+	return &Scheduler{
+		store:            store,
+		snapshotService:  dashsnapsvc.ProvideService(store, fakes.NewFakeSecretsService()),
+		dashboardService: dashboardService,
+		queryDataService: queryDataService,
+		userService: &usertest.FakeUserService{
+			ExpectedSignedInUser: &user.SignedInUser{UserID: 10, OrgID: 1, OrgRole: org.RoleViewer},
+		},
+		accessControl: acMock,
+		acService:     acMock,
+		serverLock:    &fakeLockService{},
+		features:      featuremgmt.WithFeatures(featuremgmt.FlagScheduledDashboardSnapshots),
+		log:           log.New("test"),
+		now:           time.Now,
+	}, queryDataService, store
+}
+
+func TestIntegrationSnapshotScheduler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	t.Run("Should validate the schedule", func(t *testing.T) {
+		s, _, _ := setupScheduler(t)
+		require.False(t, s.IsDisabled())
+
+		cmd := &dashboardsnapshots.CreateSnapshotScheduleCommand{DashboardUid: "slo", Schedule: "every day", OrgId: 1}
+		require.ErrorIs(t, s.CreateSnapshotSchedule(context.Background(), cmd), dashboardsnapshots.ErrScheduleInvalidCron)
+
+		cmd = &dashboardsnapshots.CreateSnapshotScheduleCommand{DashboardUid: "slo", Schedule: "@daily", RetentionCount: -1, OrgId: 1}
+		require.ErrorIs(t, s.CreateSnapshotSchedule(context.Background(), cmd), dashboardsnapshots.ErrScheduleInvalidRetention)
+
+		cmd = &dashboardsnapshots.CreateSnapshotScheduleCommand{DashboardUid: "slo", Schedule: "@daily", TimeFrom: "yesterday", OrgId: 1}
+		require.ErrorIs(t, s.CreateSnapshotSchedule(context.Background(), cmd), dashboardsnapshots.ErrScheduleInvalidTimeRange)
+
+		cmd = &dashboardsnapshots.CreateSnapshotScheduleCommand{DashboardUid: "unknown", Schedule: "@daily", OrgId: 1}
+		require.ErrorIs(t, s.CreateSnapshotSchedule(context.Background(), cmd), dashboards.ErrDashboardNotFound)
+	})
+
+	t.Run("Should default the name to the dashboard title and compute the next run", func(t *testing.T) {
+		s, _, _ := setupScheduler(t)
+		now := time.Date(2022, 10, 1, 10, 30, 0, 0, time.UTC)
+		s.now = func() time.Time { return now }
+
+		cmd := &dashboardsnapshots.CreateSnapshotScheduleCommand{DashboardUid: "slo", Schedule: "0 6 * * *", OrgId: 1, Enabled: true}
+		require.NoError(t, s.CreateSnapshotSchedule(context.Background(), cmd))
+		assert.Equal(t, "SLO", cmd.Result.Name)
+		assert.True(t, time.Date(2022, 10, 2, 6, 0, 0, 0, time.UTC).Equal(cmd.Result.NextRun))
+	})
+
+	t.Run("Should take snapshots of due schedules and apply the retention", func(t *testing.T) {
+		s, queryDataService, store := setupScheduler(t)
+
+		cmd := &dashboardsnapshots.CreateSnapshotScheduleCommand{
+			DashboardUid:   "slo",
+			Schedule:       "@hourly",
+			RetentionCount: 1,
+			OrgId:          1,
+			UserId:         10,
+			Enabled:        true,
+		}
+		require.NoError(t, s.CreateSnapshotSchedule(context.Background(), cmd))
+		schedule := cmd.Result
+
+		// Not due yet
+		s.runDueSchedules(context.Background())
+		require.Empty(t, queryDataService.requests)
+
+		s.now = func() time.Time { return schedule.NextRun.Add(time.Second) }
+		s.runDueSchedules(context.Background())
+
+		// The hidden query and the panels without queries are skipped
+		require.Len(t, queryDataService.requests, 2)
+		require.Len(t, queryDataService.requests[0].Queries, 1)
+		assert.Equal(t, int64(1000), queryDataService.requests[0].Queries[0].Get("maxDataPoints").MustInt64())
+		assert.Equal(t, `up{env="prod"}`, queryDataService.requests[0].Queries[0].Get("expr").MustString(), "the template variables are interpolated")
+		assert.Equal(t, int64(10), queryDataService.user.UserID)
+		assert.Equal(t, []string{"datasources:uid:prom", "datasources:uid:loki"}, queryDataService.user.Permissions[1][datasources.ActionQuery])
+
+		history := &dashboardsnapshots.GetScheduleSnapshotsQuery{ScheduleId: schedule.Id, OrgId: 1}
+		require.NoError(t, s.GetScheduleSnapshots(context.Background(), history))
+		require.Len(t, history.Result, 1)
+
+		snapshot := &dashboardsnapshots.GetDashboardSnapshotQuery{Key: history.Result[0].Key}
+		require.NoError(t, s.snapshotService.GetDashboardSnapshot(context.Background(), snapshot))
+		assert.Equal(t, int64(10), snapshot.Result.UserId)
+
+		dashboard := snapshot.Result.Dashboard
+		assert.Empty(t, dashboard.Get("links").MustArray())
+		panel := dashboard.Get("panels").GetIndex(0)
+		assert.Empty(t, panel.Get("targets").MustArray())
+		frame := panel.Get("snapshotData").GetIndex(0)
+		assert.Equal(t, `up{env="prod"}`, frame.Get("name").MustString())
+		assert.Equal(t, "time", frame.Get("fields").GetIndex(0).Get("type").MustString())
+		assert.Equal(t, int64(2000), frame.Get("fields").GetIndex(0).Get("values").GetIndex(1).MustInt64())
+		assert.Equal(t, 1.0, frame.Get("fields").GetIndex(1).Get("values").GetIndex(0).MustFloat64())
+		assert.Nil(t, frame.Get("fields").GetIndex(1).Get("values").GetIndex(1).Interface())
+		nested := dashboard.Get("panels").GetIndex(1).Get("panels").GetIndex(0)
+		assert.Equal(t, "errors", nested.Get("snapshotData").GetIndex(0).Get("name").MustString())
+		variable := dashboard.GetPath("templating", "list").GetIndex(0)
+		assert.Equal(t, "", variable.Get("query").MustString())
+		assert.Equal(t, 0, variable.Get("refresh").MustInt())
+
+		updated := &dashboardsnapshots.GetSnapshotScheduleQuery{Uid: schedule.Uid, OrgId: 1}
+		require.NoError(t, s.GetSnapshotSchedule(context.Background(), updated))
+		assert.True(t, updated.Result.NextRun.After(schedule.NextRun))
+		assert.Empty(t, updated.Result.LastError)
+
+		// Running again manually keeps a single snapshot
+		run := &dashboardsnapshots.RunSnapshotScheduleCommand{Uid: schedule.Uid, OrgId: 1}
+		require.NoError(t, s.RunSnapshotSchedule(context.Background(), run))
+		require.NoError(t, store.GetScheduleSnapshots(context.Background(), history))
+		require.Len(t, history.Result, 1)
+		assert.Equal(t, run.Result.Key, history.Result[0].Key)
+	})
+
+	t.Run("Should record query failures on the schedule", func(t *testing.T) {
+		s, queryDataService, _ := setupScheduler(t)
+		queryDataService.err = errors.New("data source unavailable")
+
+		cmd := &dashboardsnapshots.CreateSnapshotScheduleCommand{DashboardUid: "slo", Schedule: "@hourly", OrgId: 1, Enabled: true}
+		require.NoError(t, s.CreateSnapshotSchedule(context.Background(), cmd))
+
+		run := &dashboardsnapshots.RunSnapshotScheduleCommand{Uid: cmd.Result.Uid, OrgId: 1}
+		err := s.RunSnapshotSchedule(context.Background(), run)
+		require.ErrorContains(t, err, "data source unavailable")
+		require.NotNil(t, run.Result)
+
+		query := &dashboardsnapshots.GetSnapshotScheduleQuery{Uid: cmd.Result.Uid, OrgId: 1}
+		require.NoError(t, s.GetSnapshotSchedule(context.Background(), query))
+		assert.Contains(t, query.Result.LastError, "data source unavailable")
+		assert.True(t, cmd.Result.NextRun.Equal(query.Result.NextRun))
+	})
+
+	t.Run("Should fail when the owner of the schedule cannot query the dashboard", func(t *testing.T) {
+		s, queryDataService, _ := setupScheduler(t)
+
+		cmd := &dashboardsnapshots.CreateSnapshotScheduleCommand{DashboardUid: "slo", Schedule: "@hourly", OrgId: 1, UserId: 10, Enabled: true}
+		require.NoError(t, s.CreateSnapshotSchedule(context.Background(), cmd))
+		run := &dashboardsnapshots.RunSnapshotScheduleCommand{Uid: cmd.Result.Uid, OrgId: 1}
+
+		s.acService = accesscontrolmock.New().WithPermissions([]accesscontrol.Permission{
+			{Action: datasources.ActionQuery, Scope: "datasources:uid:prom"},
+		})
+		require.ErrorIs(t, s.RunSnapshotSchedule(context.Background(), run), dashboardsnapshots.ErrScheduleOwner)
+
+		s.userService = &usertest.FakeUserService{ExpectedError: user.ErrUserNotFound}
+		require.ErrorIs(t, s.RunSnapshotSchedule(context.Background(), run), dashboardsnapshots.ErrScheduleOwner)
+
+		require.Empty(t, queryDataService.requests)
+		require.Nil(t, run.Result)
+
+		query := &dashboardsnapshots.GetSnapshotScheduleQuery{Uid: cmd.Result.Uid, OrgId: 1}
+		require.NoError(t, s.GetSnapshotSchedule(context.Background(), query))
+		assert.NotEmpty(t, query.Result.LastError)
+	})
+}
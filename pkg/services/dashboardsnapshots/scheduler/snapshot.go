@@ -0,0 +1,272 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/queries"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+const defaultMaxDataPoints = 1000
+
+// snapshotPanels runs the queries of every panel, including the panels of collapsed
+// rows, and stores the resulting frames as the snapshot data of the panel.
+func (s *Scheduler) snapshotPanels(ctx context.Context, owner *user.SignedInUser, dashboard *simplejson.Json, from, to time.Time) []error {
+	panels := simplejson.NewFromAny(map[string]interface{}{"panels": flattenPanels(dashboard.Get("panels").MustArray())})
+	queriesByPanel := queries.GroupQueriesByPanelId(panels)
+	variables := variableValues(dashboard)
+
+	var errs []error
+	for _, panelObj := range panels.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		panelQueries := make([]*simplejson.Json, 0)
+		for _, query := range queriesByPanel[panel.Get("id").MustInt64()] {
+			if !query.Get("hide").MustBool() {
+				panelQueries = append(panelQueries, query)
+			}
+		}
+		if len(panelQueries) == 0 {
+			continue
+		}
+		queries.InterpolateTemplateVariables(panelQueries, variables)
+
+		maxDataPoints := panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+		intervalMs := int64(math.Max(float64(to.Sub(from).Milliseconds()/maxDataPoints), 1))
+		for _, query := range panelQueries {
+			if _, ok := query.CheckGet("maxDataPoints"); !ok {
+				query.Set("maxDataPoints", maxDataPoints)
+			}
+			if _, ok := query.CheckGet("intervalMs"); !ok {
+				query.Set("intervalMs", intervalMs)
+			}
+		}
+
+		res, err := s.queryDataService.QueryDataMultipleSources(ctx, owner, true, dtos.MetricRequest{
+			From:    strconv.FormatInt(from.UnixMilli(), 10),
+			To:      strconv.FormatInt(to.UnixMilli(), 10),
+			Queries: panelQueries,
+		}, true)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("panel %d: %w", panel.Get("id").MustInt64(), err))
+			continue
+		}
+
+		snapshotData := make([]interface{}, 0)
+		for _, query := range panelQueries {
+			refID := query.Get("refId").MustString("A")
+			response, ok := res.Responses[refID]
+			if !ok {
+				continue
+			}
+			if response.Error != nil {
+				errs = append(errs, fmt.Errorf("panel %d, query %s: %w", panel.Get("id").MustInt64(), refID, response.Error))
+				continue
+			}
+			for _, frame := range response.Frames {
+				snapshotData = append(snapshotData, frameToSnapshotData(frame, refID))
+			}
+		}
+		panel.Set("snapshotData", snapshotData)
+	}
+
+	return errs
+}
+
+// variableValues returns the current values of the template variables of the dashboard. The variables with
+// several values are left to the data sources.
+func variableValues(dashboard *simplejson.Json) map[string]string {
+	values := make(map[string]string)
+	for _, variable := range queries.GetDashboardVariables(dashboard) {
+		if variable.Current != "" && variable.Current != "$__all" {
+			values[variable.Name] = variable.Current
+		}
+	}
+	return values
+}
+
+// flattenPanels returns the panels along with the panels nested in collapsed rows.
+func flattenPanels(list []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		result = append(result, item)
+		if panel, ok := item.(map[string]interface{}); ok {
+			if nested, ok := panel["panels"].([]interface{}); ok {
+				result = append(result, flattenPanels(nested)...)
+			}
+		}
+	}
+	return result
+}
+
+// scheduleOwner loads the owner of the schedule, with their permissions, and checks that they can still
+// query every data source used in the dashboard.
+func (s *Scheduler) scheduleOwner(ctx context.Context, schedule *dashboardsnapshots.SnapshotSchedule, dashboard *simplejson.Json) (*user.SignedInUser, error) {
+	owner, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: schedule.UserId, OrgID: schedule.OrgId})
+	if err != nil {
+		return nil, dashboardsnapshots.ErrScheduleOwner.Errorf("failed to get the owner %d of the schedule: %w", schedule.UserId, err)
+	}
+	if owner.IsDisabled || owner.OrgID != schedule.OrgId || owner.OrgRole == "" {
+		return nil, dashboardsnapshots.ErrScheduleOwner.Errorf("the owner %d of the schedule is not an active member of the organization", schedule.UserId)
+	}
+
+	if s.acService.IsDisabled() {
+		return owner, nil
+	}
+
+	permissions, err := s.acService.GetUserPermissions(ctx, owner, accesscontrol.Options{})
+	if err != nil {
+		return nil, err
+	}
+	owner.Permissions = map[int64]map[string][]string{owner.OrgID: accesscontrol.GroupScopesByAction(permissions)}
+
+	panels := simplejson.NewFromAny(map[string]interface{}{"panels": flattenPanels(dashboard.Get("panels").MustArray())})
+	for _, uid := range queries.GetUniqueDashboardDatasourceUids(panels) {
+		// the default data source is resolved by the query service, expressions and the grafana data source need no permission
+		if uid == "" || expr.IsDataSource(uid) || uid == grafanads.DatasourceUID {
+			continue
+		}
+		canQuery, err := s.accessControl.Evaluate(ctx, owner, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid)))
+		if err != nil {
+			return nil, err
+		}
+		if !canQuery {
+			return nil, dashboardsnapshots.ErrScheduleOwner.Errorf("the owner %d of the schedule cannot query the data source %s", schedule.UserId, uid)
+		}
+	}
+	return owner, nil
+}
+
+// frameToSnapshotData converts a frame to the DataFrameDTO format used by the frontend in snapshots.
+func frameToSnapshotData(frame *data.Frame, refID string) map[string]interface{} {
+	if frame.RefID != "" {
+		refID = frame.RefID
+	}
+
+	fields := make([]interface{}, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		values := make([]interface{}, field.Len())
+		for i := range values {
+			values[i] = snapshotValue(field, i)
+		}
+
+		dto := map[string]interface{}{
+			"name":   field.Name,
+			"type":   snapshotFieldType(field.Type()),
+			"values": values,
+		}
+		if field.Config != nil {
+			dto["config"] = field.Config
+		}
+		if len(field.Labels) > 0 {
+			dto["labels"] = field.Labels
+		}
+		fields = append(fields, dto)
+	}
+
+	return map[string]interface{}{
+		"name":   frame.Name,
+		"refId":  refID,
+		"fields": fields,
+	}
+}
+
+func snapshotValue(field *data.Field, i int) interface{} {
+	value, ok := field.ConcreteAt(i)
+	if !ok {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v.UnixMilli()
+	case float64:
+		// NaN and infinite values cannot be encoded to JSON
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+	}
+	return value
+}
+
+func snapshotFieldType(fieldType data.FieldType) string {
+	switch {
+	case fieldType.Time():
+		return "time"
+	case fieldType.Numeric():
+		return "number"
+	case fieldType.NonNullableType() == data.FieldTypeString:
+		return "string"
+	case fieldType.NonNullableType() == data.FieldTypeBool:
+		return "boolean"
+	}
+	return "other"
+}
+
+// scrubDashboard removes the queries, links and variable queries from the dashboard
+// and makes its time range absolute, the same way snapshots shared from the UI are.
+func scrubDashboard(dashboard *simplejson.Json, name string, from, to, now time.Time) {
+	dashboard.Set("title", name)
+	dashboard.Set("time", map[string]interface{}{
+		"from": from.UTC().Format(time.RFC3339Nano),
+		"to":   to.UTC().Format(time.RFC3339Nano),
+	})
+	dashboard.Set("links", []interface{}{})
+	dashboard.Set("snapshot", map[string]interface{}{
+		"timestamp": now.UTC().Format(time.RFC3339Nano),
+	})
+
+	for _, panelObj := range flattenPanels(dashboard.Get("panels").MustArray()) {
+		panel := simplejson.NewFromAny(panelObj)
+		panel.Set("targets", []interface{}{})
+		panel.Set("links", []interface{}{})
+		panel.Set("datasource", nil)
+	}
+
+	// Annotations are not part of the snapshot, the empty snapshot data keeps
+	// the frontend from querying them.
+	annotations := make([]interface{}, 0)
+	for _, annotationObj := range dashboard.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(annotationObj)
+		if !annotation.Get("enable").MustBool() {
+			continue
+		}
+		annotations = append(annotations, map[string]interface{}{
+			"name":         annotation.Get("name").Interface(),
+			"enable":       true,
+			"iconColor":    annotation.Get("iconColor").Interface(),
+			"type":         annotation.Get("type").Interface(),
+			"builtIn":      annotation.Get("builtIn").Interface(),
+			"hide":         annotation.Get("hide").Interface(),
+			"snapshotData": []interface{}{},
+		})
+	}
+	dashboard.SetPath([]string{"annotations", "list"}, annotations)
+
+	for _, variableObj := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		variable.Set("query", "")
+		options := []interface{}{}
+		if current, ok := variable.CheckGet("current"); ok {
+			options = append(options, current.Interface())
+		}
+		variable.Set("options", options)
+		variable.Set("refresh", 0)
+	}
+}
@@ -12,3 +12,14 @@ type Service interface {
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) error
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) error
 }
+
+// ScheduleService manages the schedules taking snapshots of dashboards at regular intervals.
+type ScheduleService interface {
+	CreateSnapshotSchedule(context.Context, *CreateSnapshotScheduleCommand) error
+	UpdateSnapshotSchedule(context.Context, *UpdateSnapshotScheduleCommand) error
+	DeleteSnapshotSchedule(context.Context, *DeleteSnapshotScheduleCommand) error
+	GetSnapshotSchedule(context.Context, *GetSnapshotScheduleQuery) error
+	SearchSnapshotSchedules(context.Context, *GetSnapshotSchedulesQuery) error
+	GetScheduleSnapshots(context.Context, *GetScheduleSnapshotsQuery) error
+	RunSnapshotSchedule(context.Context, *RunSnapshotScheduleCommand) error
+}
@@ -11,3 +11,15 @@ type Store interface {
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) error
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) error
 }
+
+type ScheduleStore interface {
+	CreateSnapshotSchedule(context.Context, *CreateSnapshotScheduleCommand) error
+	UpdateSnapshotSchedule(context.Context, *UpdateSnapshotScheduleCommand) error
+	DeleteSnapshotSchedule(context.Context, *DeleteSnapshotScheduleCommand) error
+	GetSnapshotSchedule(context.Context, *GetSnapshotScheduleQuery) error
+	SearchSnapshotSchedules(context.Context, *GetSnapshotSchedulesQuery) error
+	GetDueSnapshotSchedules(context.Context, *GetDueSnapshotSchedulesQuery) error
+	UpdateSnapshotScheduleRun(context.Context, *UpdateSnapshotScheduleRunCommand) error
+	GetScheduleSnapshots(context.Context, *GetScheduleSnapshotsQuery) error
+	DeleteScheduleSnapshots(context.Context, *DeleteScheduleSnapshotsCommand) error
+}
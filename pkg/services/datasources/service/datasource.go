@@ -54,7 +54,7 @@ func ProvideService(
 	features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl, datasourcePermissionsService accesscontrol.DatasourcePermissionsService,
 ) *Service {
 	dslogger := log.New("datasources")
-	store := &SqlStore{db: db, logger: dslogger}
+	store := &SqlStore{db: db, logger: dslogger, emitEntityEvents: features != nil && features.IsEnabled(featuremgmt.FlagPanelTitleSearch)}
 	s := &Service{
 		SQLStore:       store,
 		SecretsStore:   secretsStore,
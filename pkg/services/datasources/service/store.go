@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	entitystore "github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/util"
 	"xorm.io/xorm"
 )
@@ -34,6 +35,9 @@ type Store interface {
 type SqlStore struct {
 	db     db.DB
 	logger log.Logger
+	// emitEntityEvents is whether the changes of the datasources are saved as entity events, which are
+	// used to update the search index.
+	emitEntityEvents bool
 }
 
 func CreateStore(db sqlstore.Store, logger log.Logger) *SqlStore {
@@ -158,6 +162,9 @@ func (ss *SqlStore) DeleteDataSource(ctx context.Context, cmd *datasources.Delet
 
 		// Publish data source deletion event
 		if cmd.DeletedDatasourcesCount > 0 {
+			if err := ss.insertEntityEvent(sess, ds.OrgId, ds.Uid, entitystore.EntityEventTypeDelete); err != nil {
+				return err
+			}
 			sess.PublishAfterCommit(&events.DataSourceDeleted{
 				Timestamp: time.Now(),
 				Name:      ds.Name,
@@ -230,6 +237,10 @@ func (ss *SqlStore) AddDataSource(ctx context.Context, cmd *datasources.AddDataS
 			}
 		}
 
+		if err := ss.insertEntityEvent(sess, ds.OrgId, ds.Uid, entitystore.EntityEventTypeCreate); err != nil {
+			return err
+		}
+
 		cmd.Result = ds
 
 		sess.PublishAfterCommit(&events.DataSourceCreated{
@@ -315,6 +326,12 @@ func (ss *SqlStore) UpdateDataSource(ctx context.Context, cmd *datasources.Updat
 
 		err = updateIsDefaultFlag(ds, sess)
 
+		if ds.Uid != "" {
+			if err := ss.insertEntityEvent(sess, ds.OrgId, ds.Uid, entitystore.EntityEventTypeUpdate); err != nil {
+				return err
+			}
+		}
+
 		if cmd.UpdateSecretFn != nil {
 			if err := cmd.UpdateSecretFn(); err != nil {
 				ss.logger.Error("Failed to update datasource secrets -- rolling back update", "UID", cmd.Uid, "name", cmd.Name, "type", cmd.Type, "orgId", cmd.OrgId)
@@ -327,6 +344,15 @@ func (ss *SqlStore) UpdateDataSource(ctx context.Context, cmd *datasources.Updat
 	})
 }
 
+// insertEntityEvent saves the change of a datasource as an entity event
+func (ss *SqlStore) insertEntityEvent(sess *sqlstore.DBSession, orgID int64, uid string, eventType entitystore.EntityEventType) error {
+	if !ss.emitEntityEvents {
+		return nil
+	}
+	_, err := sess.Insert(entitystore.CreateDatabaseEntityEvent(uid, orgID, entitystore.EntityTypeDatasource, eventType))
+	return err
+}
+
 func generateNewDatasourceUid(sess *sqlstore.DBSession, orgId int64) (string, error) {
 	for i := 0; i < 3; i++ {
 		uid := generateNewUid()
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// The export only keeps the queries of the rules.  The new rules evaluate the last query, the updated
+// rules keep their other settings.
+func importAlerts(job *importJob) error {
+	files, err := job.files("alerts")
+	if err != nil {
+		return err
+	}
+
+	for _, fname := range files {
+		if !strings.HasSuffix(fname, ".json") {
+			continue
+		}
+		name := path.Join("alerts", fname)
+
+		// written with the field names, the queries are the "data" column
+		row := struct {
+			Title        string
+			UID          string
+			NamespaceUID string
+			RuleGroup    string
+			Condition    []ngmodels.AlertQuery
+			DashboardUID string
+			PanelID      int64
+		}{}
+		if err := job.readJSON(job.path(name), &row); err != nil {
+			return err
+		}
+		if len(row.Condition) == 0 {
+			job.skip(name, "no queries")
+			continue
+		}
+
+		if !job.folders[row.NamespaceUID] {
+			folder, err := findDashboard(job, row.NamespaceUID)
+			if err != nil {
+				return err
+			}
+			if folder == nil || !folder.IsFolder {
+				job.skip(name, fmt.Sprintf("folder %s not found", row.NamespaceUID))
+				continue
+			}
+		}
+
+		query := &ngmodels.GetAlertRuleByUIDQuery{UID: row.UID, OrgID: job.orgID}
+		err := job.alertStore.GetAlertRuleByUID(job.ctx, query)
+		if err != nil && !errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return err
+		}
+		existing := query.Result
+		if existing != nil {
+			overwrite, err := job.conflict(name)
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				continue
+			}
+		}
+
+		if job.cfg.DryRun {
+			if err := job.imported(name, existing != nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rule := ngmodels.AlertRule{
+			OrgID:        job.orgID,
+			Title:        row.Title,
+			UID:          row.UID,
+			NamespaceUID: row.NamespaceUID,
+			RuleGroup:    row.RuleGroup,
+			Data:         row.Condition,
+			Condition:    row.Condition[len(row.Condition)-1].RefID,
+			NoDataState:  ngmodels.NoData,
+			ExecErrState: ngmodels.AlertingErrState,
+			Annotations:  map[string]string{},
+			Labels:       map[string]string{},
+		}
+		if existing != nil {
+			rule.IntervalSeconds = existing.IntervalSeconds
+			rule.NoDataState = existing.NoDataState
+			rule.ExecErrState = existing.ExecErrState
+			rule.For = existing.For
+			rule.Annotations = existing.Annotations
+			rule.Labels = existing.Labels
+			rule.RuleGroupIndex = existing.RuleGroupIndex
+			for _, q := range row.Condition {
+				if q.RefID == existing.Condition {
+					rule.Condition = existing.Condition
+				}
+			}
+		} else {
+			rule.IntervalSeconds, err = groupInterval(job, row.NamespaceUID, row.RuleGroup)
+			if err != nil {
+				return err
+			}
+		}
+		if row.DashboardUID != "" {
+			if rule.Annotations == nil {
+				rule.Annotations = map[string]string{}
+			}
+			rule.DashboardUID = &row.DashboardUID
+			rule.PanelID = &row.PanelID
+			rule.Annotations[ngmodels.DashboardUIDAnnotation] = row.DashboardUID
+			rule.Annotations[ngmodels.PanelIDAnnotation] = strconv.FormatInt(row.PanelID, 10)
+		}
+
+		if existing != nil {
+			err = job.alertStore.UpdateAlertRules(job.ctx, []store.UpdateRule{{Existing: existing, New: rule}})
+		} else {
+			_, err = job.alertStore.InsertAlertRules(job.ctx, []ngmodels.AlertRule{rule})
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := job.imported(name, existing != nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupInterval returns the interval of the rules in a group of the target org, all the rules of a group
+// are evaluated at the same interval
+func groupInterval(job *importJob, namespaceUID string, group string) (int64, error) {
+	interval := int64(job.alertStore.Cfg.DefaultRuleEvaluationInterval.Seconds())
+	err := job.sql.WithDbSession(job.ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("alert_rule").
+			Where("org_id = ? AND namespace_uid = ? AND rule_group = ?", job.orgID, namespaceUID, group).
+			Cols("interval_seconds").
+			Get(&interval)
+		return err
+	})
+	return interval, err
+}
@@ -0,0 +1,216 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func importDashboards(job *importJob) error {
+	rootDir := job.path("root")
+	entries, err := os.ReadDir(rootDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // nothing exported
+	}
+	if err != nil {
+		return err
+	}
+
+	// The files have no UID, the alias maps them to the paths
+	alias := make(map[string]string, 100)
+	err = job.readJSON(job.path("root-alias.json"), &alias)
+	if err != nil {
+		return err
+	}
+	uids := make(map[string]string, len(alias))
+	for uid, fpath := range alias {
+		uids[fpath] = uid
+	}
+
+	// Folders are only one level deep.  A folder without __folder.json is the general folder path
+	folders := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		slug := entry.Name()
+
+		folder := struct {
+			Title string `json:"title"`
+		}{}
+		err := job.readJSON(path.Join(rootDir, slug, "__folder.json"), &folder)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		id, err := importFolder(job, path.Join("root", slug, "__folder.json"), uids[slug], folder.Title)
+		if err != nil {
+			return err
+		}
+		folders[slug] = id
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			if strings.HasSuffix(entry.Name(), "-dash.json") {
+				if err := importDashboard(job, entry.Name(), uids[entry.Name()], 0); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		files, err := job.files("root", entry.Name())
+		if err != nil {
+			return err
+		}
+		for _, fname := range files {
+			if !strings.HasSuffix(fname, "-dash.json") {
+				continue
+			}
+			fpath := path.Join(entry.Name(), fname)
+			if err := importDashboard(job, fpath, uids[fpath], folders[entry.Name()]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importFolder returns the ID of the folder, zero when it is not saved
+func importFolder(job *importJob, name string, uid string, title string) (int64, error) {
+	if uid == "" {
+		job.skip(name, "missing from root-alias.json")
+		return 0, nil
+	}
+
+	existing, err := findDashboard(job, uid)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		if !existing.IsFolder {
+			job.skip(name, "a dashboard has the same UID")
+			return 0, nil
+		}
+		job.folders[uid] = true
+		overwrite, err := job.conflict(name)
+		if err != nil || !overwrite {
+			return existing.Id, err
+		}
+	}
+	job.folders[uid] = true
+
+	if job.cfg.DryRun {
+		if existing != nil {
+			return existing.Id, job.imported(name, true)
+		}
+		return 0, job.imported(name, false)
+	}
+
+	if existing != nil {
+		err = job.folderService.UpdateFolder(job.ctx, job.user, job.orgID, uid, &models.UpdateFolderCommand{
+			Uid:       uid,
+			Title:     title,
+			Overwrite: true,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		return existing.Id, job.imported(name, true)
+	}
+
+	folder, err := job.folderService.CreateFolder(job.ctx, job.user, job.orgID, title, uid)
+	if errors.Is(err, dashboards.ErrFolderSameNameExists) {
+		delete(job.folders, uid)
+		job.skip(name, err.Error())
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return folder.Id, job.imported(name, false)
+}
+
+func importDashboard(job *importJob, fpath string, uid string, folderID int64) error {
+	name := path.Join("root", fpath)
+	if uid == "" {
+		job.skip(name, "missing from root-alias.json")
+		return nil
+	}
+
+	body, err := os.ReadFile(job.path(name))
+	if err != nil {
+		return err
+	}
+	data, err := simplejson.NewJson(body)
+	if err != nil {
+		return fmt.Errorf("invalid file %s: %w", name, err)
+	}
+
+	existing, err := findDashboard(job, uid)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.IsFolder {
+			job.skip(name, "a folder has the same UID")
+			return nil
+		}
+		job.dashboards[uid] = true
+		overwrite, err := job.conflict(name)
+		if err != nil || !overwrite {
+			return err
+		}
+	}
+	job.dashboards[uid] = true
+
+	if job.cfg.DryRun {
+		return job.imported(name, existing != nil)
+	}
+
+	data.Del("id")
+	data.Set("uid", uid)
+	dash := models.NewDashboardFromJson(data)
+	dash.FolderId = folderID
+
+	_, err = job.dashboardService.SaveDashboard(job.ctx, &dashboards.SaveDashboardDTO{
+		OrgId:     job.orgID,
+		User:      job.user,
+		Message:   "Imported",
+		Overwrite: existing != nil,
+		Dashboard: dash,
+	}, true)
+	if errors.Is(err, dashboards.ErrDashboardWithSameNameInFolderExists) {
+		delete(job.dashboards, uid)
+		job.skip(name, err.Error())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return job.imported(name, existing != nil)
+}
+
+// findDashboard returns the dashboard or folder with the UID in the target org, nil when there is none
+func findDashboard(job *importJob, uid string) (*models.Dashboard, error) {
+	query := &models.GetDashboardQuery{Uid: uid, OrgId: job.orgID}
+	err := job.dashboardService.GetDashboard(job.ctx, query)
+	if errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return query.Result, nil
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// The export has no secrets, they are kept when updating a data source
+func importDataSources(job *importJob) error {
+	files, err := job.files("datasources")
+	if err != nil {
+		return err
+	}
+
+	for _, fname := range files {
+		if !strings.HasSuffix(fname, "-ds.json") {
+			continue
+		}
+		name := path.Join("datasources", fname)
+
+		ds := datasources.DataSource{}
+		if err := job.readJSON(job.path(name), &ds); err != nil {
+			return err
+		}
+		if ds.Uid == "" {
+			job.skip(name, "missing UID")
+			continue
+		}
+
+		query := &datasources.GetDataSourceQuery{Uid: ds.Uid, OrgId: job.orgID}
+		err := job.datasourceService.GetDataSource(job.ctx, query)
+		if err != nil && !errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return err
+		}
+		existing := query.Result
+		if existing != nil {
+			overwrite, err := job.conflict(name)
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				continue
+			}
+		}
+
+		if job.cfg.DryRun {
+			if err := job.imported(name, existing != nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if existing != nil {
+			err = job.datasourceService.UpdateDataSource(job.ctx, &datasources.UpdateDataSourceCommand{
+				Id:              existing.Id,
+				Uid:             ds.Uid,
+				OrgId:           job.orgID,
+				Name:            ds.Name,
+				Type:            ds.Type,
+				Access:          ds.Access,
+				Url:             ds.Url,
+				User:            ds.User,
+				Database:        ds.Database,
+				BasicAuth:       ds.BasicAuth,
+				BasicAuthUser:   ds.BasicAuthUser,
+				WithCredentials: ds.WithCredentials,
+				IsDefault:       ds.IsDefault,
+				JsonData:        ds.JsonData,
+				ReadOnly:        existing.ReadOnly,
+			})
+		} else {
+			err = job.datasourceService.AddDataSource(job.ctx, &datasources.AddDataSourceCommand{
+				Uid:             ds.Uid,
+				OrgId:           job.orgID,
+				UserId:          job.userID,
+				Name:            ds.Name,
+				Type:            ds.Type,
+				Access:          ds.Access,
+				Url:             ds.Url,
+				User:            ds.User,
+				Database:        ds.Database,
+				BasicAuth:       ds.BasicAuth,
+				BasicAuthUser:   ds.BasicAuthUser,
+				WithCredentials: ds.WithCredentials,
+				IsDefault:       ds.IsDefault,
+				JsonData:        ds.JsonData,
+			})
+		}
+		if errors.Is(err, datasources.ErrDataSourceNameExists) {
+			job.skip(name, err.Error())
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := job.imported(name, existing != nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,457 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// The importers read what the exporters with the same key wrote. Dashboards go first, the other
+// entities reference the folders and dashboards.
+var importers = []Importer{
+	{
+		Key:         "dash",
+		Name:        "Dashboards",
+		Description: "Folders and dashboards",
+		process:     importDashboards,
+	},
+	{
+		Key:         "alerts",
+		Name:        "Alerts",
+		Description: "Alert rules",
+		process:     importAlerts,
+	},
+	{
+		Key:         "ds",
+		Name:        "Data sources",
+		Description: "Data source configurations, without the secrets",
+		process:     importDataSources,
+	},
+	{
+		Key:         "system",
+		Name:        "System",
+		Description: "Service settings",
+		Importers: []Importer{
+			{
+				Key:         "system_preferences",
+				Name:        "Preferences",
+				Description: "Default, team and user preferences",
+				process:     importSystemPreferences,
+			},
+			{
+				Key:         "system_stars",
+				Name:        "Stars",
+				Description: "User stars",
+				process:     importSystemStars,
+			},
+			{
+				Key:         "system_playlists",
+				Name:        "Playlists",
+				Description: "Playlists",
+				process:     importSystemPlaylists,
+			},
+			{
+				Key:         "system_kv_store",
+				Name:        "Key Value store",
+				Description: "Internal KV store",
+				process:     importKVStore,
+			},
+			{
+				Key:         "system_short_url",
+				Name:        "Short URLs",
+				Description: "saved links",
+				process:     importSystemShortURL,
+			},
+		},
+	},
+}
+
+// The import saves the folders and dashboards as this user
+var importPermissions = []accesscontrol.Permission{
+	{Action: dashboards.ActionFoldersCreate},
+	{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionFoldersWrite, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeFoldersAll},
+}
+
+var _ Job = new(importJob)
+
+type importJob struct {
+	logger            log.Logger
+	sql               *sqlstore.SQLStore
+	alertStore        *store.DBstore
+	dashboardService  dashboards.DashboardService
+	folderService     dashboards.FolderService
+	datasourceService datasources.DataSourceService
+	playlistService   playlist.Service
+	prefService       pref.Service
+	starService       star.Service
+	kvStore           kvstore.KVStore
+
+	ctx    context.Context
+	cfg    ImportConfig
+	orgDir string // the org in the export
+	orgID  int64  // the target org
+	userID int64  // the user running the import
+	user   *user.SignedInUser
+
+	// Set while importing
+	users      map[string]int64 // target org users by login
+	folders    map[string]bool  // folders in the target org after the import, by UID
+	dashboards map[string]bool  // dashboards in the target org after the import, by UID
+
+	statusMu      sync.Mutex
+	status        ExportStatus
+	importer      string // key of the current importer
+	policy        ConflictPolicy
+	stopRequested bool
+	broadcaster   statusBroadcaster
+}
+
+func startImportJob(cfg ImportConfig, sql *sqlstore.SQLStore, alertStore *store.DBstore, orgDir string, userID int64,
+	broadcaster statusBroadcaster, dashboardService dashboards.DashboardService, folderService dashboards.FolderService,
+	datasourceService datasources.DataSourceService, playlistService playlist.Service, prefService pref.Service,
+	starService star.Service, kvStore kvstore.KVStore) (Job, error) {
+	job := &importJob{
+		logger:            log.New("import_job"),
+		sql:               sql,
+		alertStore:        alertStore,
+		dashboardService:  dashboardService,
+		folderService:     folderService,
+		datasourceService: datasourceService,
+		playlistService:   playlistService,
+		prefService:       prefService,
+		starService:       starService,
+		kvStore:           kvStore,
+		ctx:               context.Background(),
+		cfg:               cfg,
+		orgDir:            orgDir,
+		orgID:             cfg.OrgID,
+		userID:            userID,
+		user:              accesscontrol.BackgroundUser("export_import", cfg.OrgID, org.RoleAdmin, importPermissions),
+		folders:           make(map[string]bool),
+		dashboards:        make(map[string]bool),
+		broadcaster:       broadcaster,
+		status: ExportStatus{
+			Running: true,
+			Target:  "import",
+			Started: time.Now().UnixMilli(),
+			Count:   make(map[string]int, len(importers)*2),
+			Report:  make(map[string]ImportResult, len(importers)*2),
+		},
+	}
+
+	broadcaster(job.status)
+	go job.start()
+	return job, nil
+}
+
+func (e *importJob) getStatus() ExportStatus {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	return e.copyStatus()
+}
+
+// copyStatus copies the maps updated while importing, the caller holds statusMu
+func (e *importJob) copyStatus() ExportStatus {
+	s := e.status
+	s.Count = make(map[string]int, len(e.status.Count))
+	for k, v := range e.status.Count {
+		s.Count[k] = v
+	}
+	s.Report = make(map[string]ImportResult, len(e.status.Report))
+	for k, v := range e.status.Report {
+		s.Report[k] = v
+	}
+	return s
+}
+
+func (e *importJob) getConfig() ExportConfig {
+	return ExportConfig{
+		Format:  "import",
+		Exclude: e.cfg.Exclude,
+	}
+}
+
+func (e *importJob) requestStop() {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	e.stopRequested = true // will error after the next entity
+}
+
+func (e *importJob) start() {
+	defer func() {
+		e.logger.Info("Finished import job")
+		e.statusMu.Lock()
+		defer e.statusMu.Unlock()
+		s := e.copyStatus()
+		if err := recover(); err != nil {
+			e.logger.Error("import panic", "error", err)
+			s.Status = fmt.Sprintf("ERROR: %v", err)
+		}
+		if s.Finished < 10 {
+			s.Finished = time.Now().UnixMilli()
+		}
+		s.Running = false
+		if s.Status == "" {
+			s.Status = "done"
+		}
+		s.Target = e.orgDir
+		e.status = s
+		e.broadcaster(s)
+	}()
+
+	err := e.doImport()
+	if err != nil {
+		e.logger.Error("ERROR", "e", err)
+		e.statusMu.Lock()
+		e.status.Status = "ERROR"
+		e.status.Last = err.Error()
+		e.broadcaster(e.copyStatus())
+		e.statusMu.Unlock()
+	}
+}
+
+func (e *importJob) doImport() error {
+	users, err := e.loadUsers()
+	if err != nil {
+		return err
+	}
+	e.users = users
+
+	return e.process(importers, ConflictSkip)
+}
+
+func (e *importJob) process(importers []Importer, policy ConflictPolicy) error {
+	for _, imp := range importers {
+		if e.cfg.Exclude[imp.Key] {
+			continue
+		}
+
+		p, ok := e.cfg.Conflicts[imp.Key]
+		if !ok {
+			p = policy
+		}
+
+		if imp.process != nil {
+			e.statusMu.Lock()
+			e.status.Target = imp.Key
+			e.importer = imp.Key
+			e.policy = p
+			e.status.Report[imp.Key] = ImportResult{}
+			e.statusMu.Unlock()
+
+			if err := imp.process(e); err != nil {
+				return fmt.Errorf("%s: %w", imp.Key, err)
+			}
+		}
+
+		if imp.Importers != nil {
+			if err := e.process(imp.Importers, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadUsers returns the users of the target org by login, the stars and the user preferences are
+// exported by login
+func (e *importJob) loadUsers() (map[string]int64, error) {
+	users := make(map[string]int64)
+	err := e.sql.WithDbSession(e.ctx, func(sess *sqlstore.DBSession) error {
+		type userResult struct {
+			ID    int64  `xorm:"id"`
+			Login string `xorm:"login"`
+		}
+
+		rows := make([]*userResult, 0)
+
+		sess.Table("user").
+			Join("inner", "org_user", "user.id = org_user.user_id").
+			Cols("user.id", "user.login").
+			Where("org_user.org_id = ?", e.orgID)
+
+		if err := sess.Find(&rows); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			users[row.Login] = row.ID
+		}
+		return nil
+	})
+	return users, err
+}
+
+// conflict is called for an entity already in the target org. It returns true when the entity must
+// be overwritten, and fails with the fail policy unless this is a dry run.
+func (e *importJob) conflict(name string) (bool, error) {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	r := e.status.Report[e.importer]
+	defer func() { e.status.Report[e.importer] = r }()
+
+	r.Conflicts = append(r.Conflicts, name)
+	switch e.policy {
+	case ConflictOverwrite:
+		return true, nil
+	case ConflictFail:
+		if !e.cfg.DryRun {
+			return false, fmt.Errorf("%s already exists", name)
+		}
+	}
+	r.Skipped++
+	return false, nil
+}
+
+// imported records an entity created, or updated when it existed. The import stops here when
+// requested.
+func (e *importJob) imported(name string, existed bool) error {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	r := e.status.Report[e.importer]
+	if existed {
+		r.Updated++
+	} else {
+		r.Created++
+	}
+	e.status.Report[e.importer] = r
+	e.status.Count[e.importer]++
+
+	e.status.Index++
+	e.status.Last = name
+	e.status.Changed = time.Now().UnixMilli()
+	e.broadcaster(e.copyStatus())
+
+	if e.stopRequested {
+		return fmt.Errorf("stop requested")
+	}
+	return nil
+}
+
+// skip records an entity which can't be imported
+func (e *importJob) skip(name string, reason string) {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	r := e.status.Report[e.importer]
+	r.Skipped++
+	r.Warnings = append(r.Warnings, fmt.Sprintf("%s: %s", name, reason))
+	e.status.Report[e.importer] = r
+}
+
+// unchanged records an entity already in the target org as it is in the export
+func (e *importJob) unchanged() {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	r := e.status.Report[e.importer]
+	r.Skipped++
+	e.status.Report[e.importer] = r
+}
+
+// warn records an entity imported without some of its properties
+func (e *importJob) warn(name string, reason string) {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	r := e.status.Report[e.importer]
+	r.Warnings = append(r.Warnings, fmt.Sprintf("%s: %s", name, reason))
+	e.status.Report[e.importer] = r
+}
+
+// path returns the path of a file of the org in the export
+func (e *importJob) path(elem ...string) string {
+	return filepath.Join(append([]string{e.orgDir}, elem...)...)
+}
+
+// files lists the files of a folder of the org in the export, sorted by name. The folder may not exist
+// when nothing was exported.
+func (e *importJob) files(elem ...string) ([]string, error) {
+	entries, err := os.ReadDir(e.path(elem...))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (e *importJob) readJSON(fpath string, v interface{}) error {
+	body, err := os.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid file %s: %w", fpath[len(e.orgDir)+1:], err)
+	}
+	return nil
+}
+
+// importOrgDir returns the folder of an org in an export. The export writes each org in an org_<id>
+// folder when there is more than one.
+func importOrgDir(rootDir string, orgID int64) (string, error) {
+	entries, err := os.ReadDir(rootDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("export not found")
+		}
+		return "", err
+	}
+
+	orgs := false
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "org_") {
+			orgs = true
+			break
+		}
+	}
+
+	if !orgs {
+		return rootDir, nil
+	}
+	if orgID < 1 {
+		return "", fmt.Errorf("the export has multiple orgs, the source org is required")
+	}
+
+	orgDir := filepath.Join(rootDir, fmt.Sprintf("org_%d", orgID))
+	if info, err := os.Stat(orgDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("org %d not found in the export", orgID)
+	}
+	return orgDir, nil
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+)
+
+func TestImportOrgDir(t *testing.T) {
+	t.Run("reads the root of a single org export", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "root"), 0750))
+
+		orgDir, err := importOrgDir(dir, 0)
+		require.NoError(t, err)
+		require.Equal(t, dir, orgDir)
+	})
+
+	t.Run("reads an org of a multiple org export", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "org_1"), 0750))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "org_2"), 0750))
+
+		orgDir, err := importOrgDir(dir, 2)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "org_2"), orgDir)
+
+		_, err = importOrgDir(dir, 0)
+		require.Error(t, err)
+		_, err = importOrgDir(dir, 3)
+		require.Error(t, err)
+	})
+
+	t.Run("fails without export", func(t *testing.T) {
+		_, err := importOrgDir(filepath.Join(t.TempDir(), "git_1"), 0)
+		require.EqualError(t, err, "export not found")
+	})
+}
+
+func setupDataSourceImport(t *testing.T, cfg ImportConfig) (*importJob, *fakeDatasources.FakeDataSourceService) {
+	t.Helper()
+	orgDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(orgDir, "datasources"), 0750))
+	for uid, name := range map[string]string{"prom": "Prometheus", "loki": "Loki"} {
+		body := []byte(`{"name":"` + name + `","type":"` + uid + `","access":"proxy","uid":"` + uid + `","secureJsonData":{"TODO":"WFhY"}}`)
+		require.NoError(t, os.WriteFile(filepath.Join(orgDir, "datasources", uid+"-ds.json"), body, 0600))
+	}
+
+	service := &fakeDatasources.FakeDataSourceService{
+		DataSources: []*datasources.DataSource{{Id: 1, OrgId: 2, Uid: "prom", Name: "Old Prometheus", Type: "prom"}},
+	}
+	cfg.OrgID = 2
+	return &importJob{
+		ctx:               context.Background(),
+		cfg:               cfg,
+		orgDir:            orgDir,
+		orgID:             cfg.OrgID,
+		datasourceService: service,
+		status:            ExportStatus{Count: map[string]int{}, Report: map[string]ImportResult{}},
+		broadcaster:       func(s ExportStatus) {},
+	}, service
+}
+
+var dataSourceImporters = []Importer{{Key: "ds", process: importDataSources}}
+
+func TestImportDataSources(t *testing.T) {
+	t.Run("skips the existing data sources by default", func(t *testing.T) {
+		job, service := setupDataSourceImport(t, ImportConfig{})
+		require.NoError(t, job.process(dataSourceImporters, ConflictSkip))
+
+		require.Equal(t, ImportResult{Created: 1, Skipped: 1, Conflicts: []string{"datasources/prom-ds.json"}}, job.status.Report["ds"])
+		require.Len(t, service.DataSources, 2)
+		require.Equal(t, "Old Prometheus", service.DataSources[0].Name)
+		require.Equal(t, "loki", service.DataSources[1].Uid)
+		require.Equal(t, int64(2), service.DataSources[1].OrgId)
+	})
+
+	t.Run("overwrites the existing data sources", func(t *testing.T) {
+		job, service := setupDataSourceImport(t, ImportConfig{Conflicts: map[string]ConflictPolicy{"ds": ConflictOverwrite}})
+		require.NoError(t, job.process(dataSourceImporters, ConflictSkip))
+
+		require.Equal(t, ImportResult{Created: 1, Updated: 1, Conflicts: []string{"datasources/prom-ds.json"}}, job.status.Report["ds"])
+		require.Equal(t, "Prometheus", service.DataSources[0].Name)
+		require.Equal(t, 2, job.status.Count["ds"])
+	})
+
+	t.Run("fails on the existing data sources", func(t *testing.T) {
+		job, service := setupDataSourceImport(t, ImportConfig{Conflicts: map[string]ConflictPolicy{"ds": ConflictFail}})
+		err := job.process(dataSourceImporters, ConflictSkip)
+		require.EqualError(t, err, "ds: datasources/prom-ds.json already exists")
+		require.Len(t, service.DataSources, 2) // loki is imported first
+	})
+
+	t.Run("reports without saving in a dry run", func(t *testing.T) {
+		job, service := setupDataSourceImport(t, ImportConfig{DryRun: true, Conflicts: map[string]ConflictPolicy{"ds": ConflictFail}})
+		require.NoError(t, job.process(dataSourceImporters, ConflictSkip))
+
+		require.Equal(t, ImportResult{Created: 1, Skipped: 1, Conflicts: []string{"datasources/prom-ds.json"}}, job.status.Report["ds"])
+		require.Len(t, service.DataSources, 1)
+		require.Equal(t, "Old Prometheus", service.DataSources[0].Name)
+	})
+
+	t.Run("applies the parent policy", func(t *testing.T) {
+		job, service := setupDataSourceImport(t, ImportConfig{Conflicts: map[string]ConflictPolicy{"system": ConflictOverwrite}})
+		require.NoError(t, job.process([]Importer{{Key: "system", Importers: dataSourceImporters}}, ConflictSkip))
+
+		require.Equal(t, 1, job.status.Report["ds"].Updated)
+		require.Equal(t, "Prometheus", service.DataSources[0].Name)
+	})
+
+	t.Run("stops when requested", func(t *testing.T) {
+		job, service := setupDataSourceImport(t, ImportConfig{Conflicts: map[string]ConflictPolicy{"ds": ConflictOverwrite}})
+		job.requestStop()
+		err := job.process(dataSourceImporters, ConflictSkip)
+		require.EqualError(t, err, "ds: stop requested")
+		require.Len(t, service.DataSources, 2)
+		require.Equal(t, "Old Prometheus", service.DataSources[0].Name)
+	})
+}
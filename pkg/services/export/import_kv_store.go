@@ -0,0 +1,65 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The entries are written to the target org, including the ones exported from the global (org 0) namespace
+func importKVStore(job *importJob) error {
+	kvdir := job.path("system", "kv_store")
+
+	err := filepath.WalkDir(kvdir, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(kvdir, fpath)
+		if err != nil {
+			return err
+		}
+		namespace, key, ok := strings.Cut(filepath.ToSlash(rel), "/")
+		if !ok {
+			return nil // not in a namespace
+		}
+		name := filepath.ToSlash(filepath.Join("system", "kv_store", rel))
+
+		value, err := os.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+
+		current, existed, err := job.kvStore.Get(job.ctx, job.orgID, namespace, key)
+		if err != nil {
+			return err
+		}
+		if existed {
+			if current == string(value) {
+				job.unchanged()
+				return nil
+			}
+			overwrite, err := job.conflict(name)
+			if err != nil || !overwrite {
+				return err
+			}
+		}
+
+		if !job.cfg.DryRun {
+			if err := job.kvStore.Set(job.ctx, job.orgID, namespace, key, string(value)); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		return job.imported(name, existed)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // nothing exported
+	}
+	return err
+}
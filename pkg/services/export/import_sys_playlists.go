@@ -0,0 +1,99 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// The export has no playlist items, the updated playlists keep theirs
+func importSystemPlaylists(job *importJob) error {
+	files, err := job.files("system", "playlists")
+	if err != nil {
+		return err
+	}
+
+	for _, fname := range files {
+		if !strings.HasSuffix(fname, "-playlist.json") {
+			continue
+		}
+		name := path.Join("system", "playlists", fname)
+
+		p := playlist.Playlist{}
+		if err := job.readJSON(job.path(name), &p); err != nil {
+			return err
+		}
+		if p.UID == "" {
+			job.skip(name, "missing UID")
+			continue
+		}
+
+		_, err := job.playlistService.Get(job.ctx, &playlist.GetPlaylistByUidQuery{UID: p.UID, OrgId: job.orgID})
+		if err != nil && !errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return err
+		}
+		existed := err == nil
+		if existed {
+			overwrite, err := job.conflict(name)
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				continue
+			}
+		}
+
+		if !job.cfg.DryRun {
+			if existed {
+				err = updatePlaylist(job, p)
+			} else {
+				// the service generates the UIDs
+				err = job.sql.WithDbSession(job.ctx, func(sess *sqlstore.DBSession) error {
+					_, err := sess.Insert(&playlist.Playlist{
+						UID:      p.UID,
+						OrgId:    job.orgID,
+						Name:     p.Name,
+						Interval: p.Interval,
+					})
+					return err
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if err := job.imported(name, existed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updatePlaylist(job *importJob, p playlist.Playlist) error {
+	items, err := job.playlistService.GetItems(job.ctx, &playlist.GetPlaylistItemsByUidQuery{PlaylistUID: p.UID, OrgId: job.orgID})
+	if err != nil {
+		return err
+	}
+
+	cmd := &playlist.UpdatePlaylistCommand{
+		OrgId:    job.orgID,
+		UID:      p.UID,
+		Name:     p.Name,
+		Interval: p.Interval,
+		Items:    make([]playlist.PlaylistItemDTO, 0, len(items)),
+	}
+	for _, item := range items {
+		cmd.Items = append(cmd.Items, playlist.PlaylistItemDTO{
+			Type:  item.Type,
+			Title: item.Title,
+			Value: item.Value,
+			Order: item.Order,
+		})
+	}
+	_, err = job.playlistService.Update(job.ctx, cmd)
+	return err
+}
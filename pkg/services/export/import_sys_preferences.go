@@ -0,0 +1,135 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func importSystemPreferences(job *importJob) error {
+	err := importPreferences(job, path.Join("system", "preferences", "default.json"), 0, 0)
+	if err != nil {
+		return err
+	}
+
+	// Teams are exported by ID, they are only imported in the org of the export
+	files, err := job.files("system", "preferences", "team")
+	if err != nil {
+		return err
+	}
+	for _, fname := range files {
+		name := path.Join("system", "preferences", "team", fname)
+		teamID, err := strconv.ParseInt(strings.TrimSuffix(fname, ".json"), 10, 64)
+		if err != nil {
+			job.skip(name, "invalid team ID")
+			continue
+		}
+
+		exists := false
+		err = job.sql.WithDbSession(job.ctx, func(sess *sqlstore.DBSession) error {
+			exists, err = sess.Table("team").Where("id = ? AND org_id = ?", teamID, job.orgID).Exist()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			job.skip(name, "team not found")
+			continue
+		}
+
+		if err := importPreferences(job, name, 0, teamID); err != nil {
+			return err
+		}
+	}
+
+	files, err = job.files("system", "preferences", "user")
+	if err != nil {
+		return err
+	}
+	for _, fname := range files {
+		name := path.Join("system", "preferences", "user", fname)
+		userID, ok := job.users[strings.TrimSuffix(fname, ".json")]
+		if !ok {
+			job.skip(name, "user not found")
+			continue
+		}
+
+		if err := importPreferences(job, name, userID, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importPreferences(job *importJob, name string, userID int64, teamID int64) error {
+	prefs := struct {
+		Theme         string                       `json:"theme"`
+		Locale        string                       `json:"locale"`
+		Timezone      string                       `json:"timezone"`
+		WeekStart     string                       `json:"week_start"`
+		HomeDashboard string                       `json:"home"`
+		NavBar        *pref.NavbarPreference       `json:"navbar"`
+		QueryHistory  *pref.QueryHistoryPreference `json:"queryHistory"`
+	}{}
+	err := job.readJSON(job.path(name), &prefs)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	_, err = job.prefService.Get(job.ctx, &pref.GetPreferenceQuery{OrgID: job.orgID, UserID: userID, TeamID: teamID})
+	if err != nil && !errors.Is(err, pref.ErrPrefNotFound) {
+		return err
+	}
+	existed := err == nil
+	if existed {
+		overwrite, err := job.conflict(name)
+		if err != nil || !overwrite {
+			return err
+		}
+	}
+
+	var homeID int64
+	if prefs.HomeDashboard != "" {
+		dash, err := findDashboard(job, prefs.HomeDashboard)
+		if err != nil {
+			return err
+		}
+		switch {
+		case dash != nil:
+			homeID = dash.Id
+		case !job.dashboards[prefs.HomeDashboard]:
+			job.warn(name, fmt.Sprintf("home dashboard %s not found", prefs.HomeDashboard))
+		}
+	}
+
+	if job.cfg.DryRun {
+		return job.imported(name, existed)
+	}
+
+	err = job.prefService.Save(job.ctx, &pref.SavePreferenceCommand{
+		OrgID:           job.orgID,
+		UserID:          userID,
+		TeamID:          teamID,
+		HomeDashboardID: homeID,
+		Timezone:        prefs.Timezone,
+		WeekStart:       prefs.WeekStart,
+		Theme:           prefs.Theme,
+		Locale:          prefs.Locale,
+		Navbar:          prefs.NavBar,
+		QueryHistory:    prefs.QueryHistory,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return job.imported(name, existed)
+}
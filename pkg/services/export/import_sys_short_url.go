@@ -0,0 +1,91 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// The short URLs are saved with their UIDs, the service generates new ones
+func importSystemShortURL(job *importJob) error {
+	files, err := job.files("system", "short_url", "uid")
+	if err != nil || len(files) < 1 {
+		return err
+	}
+
+	lastSeen := make(map[string]int64, len(files))
+	err = job.readJSON(job.path("system", "short_url", "last_seen_at.json"), &lastSeen)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, fname := range files {
+		if !strings.HasSuffix(fname, ".json") {
+			continue
+		}
+		name := path.Join("system", "short_url", "uid", fname)
+		uid := strings.TrimSuffix(fname, ".json")
+
+		shortURL := struct {
+			Path string `json:"path"`
+		}{}
+		if err := job.readJSON(job.path(name), &shortURL); err != nil {
+			return err
+		}
+
+		existing := models.ShortUrl{OrgId: job.orgID, Uid: uid}
+		existed := false
+		err = job.sql.WithDbSession(job.ctx, func(sess *sqlstore.DBSession) error {
+			existed, err = sess.Get(&existing)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if existed {
+			if existing.Path == shortURL.Path {
+				job.unchanged()
+				continue
+			}
+			overwrite, err := job.conflict(name)
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				continue
+			}
+		}
+
+		if !job.cfg.DryRun {
+			err = job.sql.WithDbSession(job.ctx, func(sess *sqlstore.DBSession) error {
+				if existed {
+					existing.Path = shortURL.Path
+					_, err := sess.ID(existing.Id).Cols("path").Update(&existing)
+					return err
+				}
+				_, err := sess.Insert(&models.ShortUrl{
+					OrgId:      job.orgID,
+					Uid:        uid,
+					Path:       shortURL.Path,
+					CreatedBy:  job.userID,
+					CreatedAt:  time.Now().Unix(),
+					LastSeenAt: lastSeen[uid],
+				})
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if err := job.imported(name, existed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/star"
+)
+
+// Stars are added to the existing ones, a star already there is not a conflict
+func importSystemStars(job *importJob) error {
+	files, err := job.files("system", "stars")
+	if err != nil {
+		return err
+	}
+
+	for _, fname := range files {
+		name := path.Join("system", "stars", fname)
+		userID, ok := job.users[strings.TrimSuffix(fname, ".json")]
+		if !ok {
+			job.skip(name, "user not found")
+			continue
+		}
+
+		stars := make([]string, 0)
+		if err := job.readJSON(job.path(name), &stars); err != nil {
+			return err
+		}
+
+		for _, s := range stars {
+			uid := strings.TrimPrefix(s, "dashboard/")
+			starName := fmt.Sprintf("%s/%s", name, uid)
+
+			dash, err := findDashboard(job, uid)
+			if err != nil {
+				return err
+			}
+			if dash == nil {
+				if !job.dashboards[uid] {
+					job.skip(starName, "dashboard not found")
+				} else if err := job.imported(starName, false); err != nil {
+					return err // the dashboard is not saved in a dry run
+				}
+				continue
+			}
+
+			starred, err := job.starService.IsStarredByUser(job.ctx, &star.IsStarredByUserQuery{UserID: userID, DashboardID: dash.Id})
+			if err != nil {
+				return err
+			}
+			if starred {
+				job.unchanged()
+				continue
+			}
+
+			if !job.cfg.DryRun {
+				err := job.starService.Add(job.ctx, &star.StarDashboardCommand{UserID: userID, DashboardID: dash.Id})
+				if err != nil {
+					return fmt.Errorf("%s: %w", starName, err)
+				}
+			}
+			if err := job.imported(starName, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
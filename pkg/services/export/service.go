@@ -2,23 +2,30 @@ package export
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/playlist"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -34,6 +41,9 @@ type ExportService interface {
 
 	// Cancel any running export
 	HandleRequestStop(c *models.ReqContext) response.Response
+
+	// Import an export into an org, the status and stop handlers follow it like an export
+	HandleRequestImport(c *models.ReqContext) response.Response
 }
 
 var exporters = []Exporter{
@@ -156,6 +166,12 @@ type StandardExport struct {
 	playlistService           playlist.Service
 	orgService                org.Service
 	datasourceService         datasources.DataSourceService
+	dashboardService          dashboards.DashboardService
+	folderService             dashboards.FolderService
+	prefService               pref.Service
+	starService               star.Service
+	kvStore                   kvstore.KVStore
+	alertStore                *store.DBstore
 
 	// updated with mutex
 	exportJob Job
@@ -163,7 +179,9 @@ type StandardExport struct {
 
 func ProvideService(sql *sqlstore.SQLStore, features featuremgmt.FeatureToggles, gl *live.GrafanaLive, cfg *setting.Cfg,
 	dashboardsnapshotsService dashboardsnapshots.Service, playlistService playlist.Service, orgService org.Service,
-	datasourceService datasources.DataSourceService) ExportService {
+	datasourceService datasources.DataSourceService, dashboardService dashboards.DashboardService,
+	folderService dashboards.FolderService, prefService pref.Service, starService star.Service,
+	kvStore kvstore.KVStore, alertStore *store.DBstore) ExportService {
 	if !features.IsEnabled(featuremgmt.FlagExport) {
 		return &StubExport{}
 	}
@@ -176,6 +194,12 @@ func ProvideService(sql *sqlstore.SQLStore, features featuremgmt.FeatureToggles,
 		playlistService:           playlistService,
 		orgService:                orgService,
 		datasourceService:         datasourceService,
+		dashboardService:          dashboardService,
+		folderService:             folderService,
+		prefService:               prefService,
+		starService:               starService,
+		kvStore:                   kvStore,
+		alertStore:                alertStore,
 		exportJob:                 &stoppedJob{},
 		dataDir:                   cfg.DataPath,
 	}
@@ -184,6 +208,7 @@ func ProvideService(sql *sqlstore.SQLStore, features featuremgmt.FeatureToggles,
 func (ex *StandardExport) HandleGetOptions(c *models.ReqContext) response.Response {
 	info := map[string]interface{}{
 		"exporters": exporters,
+		"importers": importers,
 	}
 	return response.JSON(http.StatusOK, info)
 }
@@ -251,6 +276,68 @@ func (ex *StandardExport) HandleRequestExport(c *models.ReqContext) response.Res
 	return response.JSON(http.StatusOK, info)
 }
 
+func (ex *StandardExport) HandleRequestImport(c *models.ReqContext) response.Response {
+	var cfg ImportConfig
+	err := json.NewDecoder(c.Req.Body).Decode(&cfg)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "unable to read config", err)
+	}
+
+	// only the exports in the data folder
+	if cfg.Source == "" || cfg.Source != filepath.Base(cfg.Source) || strings.HasPrefix(cfg.Source, ".") {
+		return response.Error(http.StatusBadRequest, "invalid source", nil)
+	}
+	for key, policy := range cfg.Conflicts {
+		switch policy {
+		case ConflictSkip, ConflictOverwrite, ConflictFail:
+		default:
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("invalid conflict policy for %s: %s", key, policy), nil)
+		}
+	}
+
+	if cfg.OrgID == 0 {
+		cfg.OrgID = c.OrgID
+	}
+	if _, err := ex.orgService.GetByID(c.Req.Context(), &org.GetOrgByIdQuery{ID: cfg.OrgID}); err != nil {
+		if errors.Is(err, org.ErrOrgNotFound) {
+			return response.Error(http.StatusNotFound, "organization not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to get organization", err)
+	}
+
+	orgDir, err := importOrgDir(filepath.Join(ex.dataDir, "export_git", cfg.Source), cfg.SourceOrgID)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+
+	ex.mutex.Lock()
+	defer ex.mutex.Unlock()
+
+	status := ex.exportJob.getStatus()
+	if status.Running {
+		ex.logger.Error("export already running")
+		return response.Error(http.StatusLocked, "export already running", nil)
+	}
+
+	broadcast := func(s ExportStatus) {
+		ex.broadcastStatus(c.OrgID, s)
+	}
+	job, err := startImportJob(cfg, ex.sql, ex.alertStore, orgDir, c.UserID, broadcast, ex.dashboardService,
+		ex.folderService, ex.datasourceService, ex.playlistService, ex.prefService, ex.starService, ex.kvStore)
+	if err != nil {
+		ex.logger.Error("failed to start import job", "err", err)
+		return response.Error(http.StatusBadRequest, "failed to start import job", err)
+	}
+
+	ex.exportJob = job
+
+	info := map[string]interface{}{
+		"cfg":    cfg, // parsed job we are running
+		"status": ex.exportJob.getStatus(),
+	}
+	return response.JSON(http.StatusOK, info)
+}
+
 func (ex *StandardExport) broadcastStatus(orgID int64, s ExportStatus) {
 	msg, err := json.Marshal(s)
 	if err != nil {
@@ -26,3 +26,7 @@ func (ex *StubExport) HandleRequestExport(c *models.ReqContext) response.Respons
 func (ex *StubExport) HandleRequestStop(c *models.ReqContext) response.Response {
 	return response.Error(http.StatusForbidden, "feature not enabled", nil)
 }
+
+func (ex *StubExport) HandleRequestImport(c *models.ReqContext) response.Response {
+	return response.Error(http.StatusForbidden, "feature not enabled", nil)
+}
@@ -11,6 +11,9 @@ type ExportStatus struct {
 	Status   string         `json:"status"` // ERROR, SUCCESS, ETC
 	Index    int            `json:"index,omitempty"`
 	Count    map[string]int `json:"count,omitempty"`
+
+	// Set by the import job
+	Report map[string]ImportResult `json:"report,omitempty"`
 }
 
 // Basic export config (for now)
@@ -27,6 +30,41 @@ type ExportConfig struct {
 
 type GitExportConfig struct{}
 
+// How the import handles an entity already in the target org
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictFail      ConflictPolicy = "fail"
+)
+
+// Import config, reads a tree written by the git export
+type ImportConfig struct {
+	// The export folder in <data>/export_git, e.g. git_1661234567
+	Source string `json:"source"`
+	// The org to read from an export of multiple orgs
+	SourceOrgID int64 `json:"sourceOrgId,omitempty"`
+	// The org receiving the entities, defaults to the current org
+	OrgID int64 `json:"orgId,omitempty"`
+	// Only report what would be imported
+	DryRun bool `json:"dryRun"`
+
+	Exclude map[string]bool `json:"exclude"`
+
+	// Conflict handling by importer key, the parent key applies to the nested importers.  Skip by default
+	Conflicts map[string]ConflictPolicy `json:"conflicts"`
+}
+
+// What an importer did (or would do in a dry run)
+type ImportResult struct {
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Skipped   int      `json:"skipped"`
+	Conflicts []string `json:"conflicts,omitempty"` // already in the target org
+	Warnings  []string `json:"warnings,omitempty"`  // could not be imported
+}
+
 type Job interface {
 	getStatus() ExportStatus
 	getConfig() ExportConfig
@@ -44,3 +82,12 @@ type Exporter struct {
 
 	process func(helper *commitHelper, job *gitExportJob) error
 }
+
+type Importer struct {
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Importers   []Importer `json:"importers,omitempty"`
+
+	process func(job *importJob) error
+}
@@ -288,5 +288,15 @@ var (
 			Name:        "increaseInMemDatabaseQueryCache",
 			Description: "Enable more in memory caching for database queries",
 		},
+		{
+			Name:        "nestedFolders",
+			Description: "Enable folders nested inside other folders",
+			State:       FeatureStateAlpha,
+		},
+		{
+			Name:        "scheduledDashboardSnapshots",
+			Description: "Take snapshots of dashboards on a schedule",
+			State:       FeatureStateAlpha,
+		},
 	}
 )
@@ -214,4 +214,12 @@ const (
 	// FlagIncreaseInMemDatabaseQueryCache
 	// Enable more in memory caching for database queries
 	FlagIncreaseInMemDatabaseQueryCache = "increaseInMemDatabaseQueryCache"
+
+	// FlagNestedFolders
+	// Enable folders nested inside other folders
+	FlagNestedFolders = "nestedFolders"
+
+	// FlagScheduledDashboardSnapshots
+	// Take snapshots of dashboards on a schedule
+	FlagScheduledDashboardSnapshots = "scheduledDashboardSnapshots"
 )
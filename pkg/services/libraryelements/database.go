@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -147,7 +148,7 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
-		return nil
+		return l.insertEntityEvent(session, element.OrgID, element.UID, element.Kind, store.EntityEventTypeCreate)
 	})
 
 	dto := LibraryElementDTO{
@@ -219,11 +220,20 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 		}
 
 		elementID = element.ID
-		return nil
+		return l.insertEntityEvent(session, element.OrgID, element.UID, element.Kind, store.EntityEventTypeDelete)
 	})
 	return elementID, err
 }
 
+// insertEntityEvent saves the change of a library panel as an entity event, which is used to update the search index.
+func (l *LibraryElementService) insertEntityEvent(session *sqlstore.DBSession, orgID int64, uid string, kind int64, eventType store.EntityEventType) error {
+	if kind != int64(models.PanelElement) || !store.EntityEventsEnabled(l.Cfg) {
+		return nil
+	}
+	_, err := session.Insert(store.CreateDatabaseEntityEvent(uid, orgID, store.EntityTypeLibraryPanel, eventType))
+	return err
+}
+
 // getLibraryElements gets a Library Element where param == value
 func getLibraryElements(c context.Context, store *sqlstore.SQLStore, signedInUser *user.SignedInUser, params []Pair) ([]LibraryElementDTO, error) {
 	libraryElements := make([]LibraryElementWithMeta, 0)
@@ -523,6 +533,14 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		} else if rowsAffected != 1 {
 			return ErrLibraryElementNotFound
 		}
+		if libraryElement.UID != elementInDB.UID {
+			if err := l.insertEntityEvent(session, elementInDB.OrgID, elementInDB.UID, elementInDB.Kind, store.EntityEventTypeDelete); err != nil {
+				return err
+			}
+		}
+		if err := l.insertEntityEvent(session, libraryElement.OrgID, libraryElement.UID, libraryElement.Kind, store.EntityEventTypeUpdate); err != nil {
+			return err
+		}
 
 		dto = LibraryElementDTO{
 			ID:          libraryElement.ID,
@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 
@@ -190,6 +191,79 @@ func TimeSeriesFromFramesLabelsColumn(frames ...*data.Frame) []prompb.TimeSeries
 	return promTimeSeriesBatch
 }
 
+// TimeSeriesFromFramesAsMetric converts the numeric fields of frames to Prometheus TimeSeries named
+// metricName. Each series gets the labels of its field and extraLabels, which take precedence. The
+// samples of frames without a time field, such as the results of reduce expressions, are stamped with ts.
+func TimeSeriesFromFramesAsMetric(metricName string, extraLabels map[string]string, ts time.Time, frames ...*data.Frame) []prompb.TimeSeries {
+	var entries = make(map[metricKey]prompb.TimeSeries)
+	var keys []metricKey // sorted keys.
+
+	for _, frame := range frames {
+		timeFieldIndex, hasTime := timeFieldIndex(frame)
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+
+			fieldLabels := make(map[string]string, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				fieldLabels[k] = v
+			}
+			for k, v := range extraLabels {
+				fieldLabels[k] = v
+			}
+			labels := createLabels(fieldLabels)
+			labels = append(labels, prompb.Label{Name: "__name__", Value: metricName})
+			// Remote write receivers expect the labels to be sorted by name.
+			sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+			key := makeMetricKey(metricName, labels)
+
+			var samples []prompb.Sample
+			for i := 0; i < field.Len(); i++ {
+				val, ok := field.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				value, ok := sampleValue(val)
+				if !ok {
+					continue
+				}
+				sampleTime := ts
+				if hasTime {
+					tm, ok := frame.Fields[timeFieldIndex].ConcreteAt(i)
+					if !ok {
+						continue
+					}
+					sampleTime = tm.(time.Time)
+				}
+				samples = append(samples, prompb.Sample{
+					// Timestamp is int milliseconds for remote write.
+					Timestamp: toSampleTime(sampleTime),
+					Value:     value,
+				})
+			}
+			if len(samples) == 0 {
+				continue
+			}
+
+			entry, ok := entries[key]
+			if !ok {
+				entry = prompb.TimeSeries{Labels: labels}
+				keys = append(keys, key)
+			}
+			entry.Samples = append(entry.Samples, samples...)
+			entries[key] = entry
+		}
+	}
+
+	var promTimeSeriesBatch = make([]prompb.TimeSeries, 0, len(entries))
+	for _, key := range keys {
+		promTimeSeriesBatch = append(promTimeSeriesBatch, entries[key])
+	}
+
+	return promTimeSeriesBatch
+}
+
 func timeFieldIndex(frame *data.Frame) (int, bool) {
 	timeFieldIndex := -1
 	for i, field := range frame.Fields {
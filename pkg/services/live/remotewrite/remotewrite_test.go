@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -85,3 +86,35 @@ func TestSerialize(t *testing.T) {
 	_, err := Serialize(frame)
 	require.NoError(t, err)
 }
+
+func TestTsFromFramesAsMetric(t *testing.T) {
+	now := time.Now()
+	t1 := now.Add(-time.Minute)
+	frames := []*data.Frame{
+		// Result of a reduce expression, without time field.
+		data.NewFrame("",
+			data.NewField("B", map[string]string{"job": "api", "instance": "a"}, []*float64{float64Ptr(1), nil}),
+		),
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{t1}),
+			data.NewField("B", map[string]string{"job": "db"}, []float64{3}),
+		),
+	}
+
+	ts := TimeSeriesFromFramesAsMetric("job:errors:rate5m", map[string]string{"team": "sre", "job": "override"}, now, frames...)
+	require.Len(t, ts, 2)
+
+	require.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "job:errors:rate5m"},
+		{Name: "instance", Value: "a"},
+		{Name: "job", Value: "override"},
+		{Name: "team", Value: "sre"},
+	}, ts[0].Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(now), Value: 1}}, ts[0].Samples)
+
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(t1), Value: 3}}, ts[1].Samples)
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -485,6 +485,9 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 			Provenance:      provenance,
 		},
 	}
+	if r.IsRecordingRule() {
+		gettableExtendedRuleNode.GrafanaManagedAlert.Record = r.Record
+	}
 	forDuration := model.Duration(r.For)
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
 		For:         &forDuration,
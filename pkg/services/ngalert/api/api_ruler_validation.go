@@ -85,9 +85,27 @@ func validateRuleNode(
 		}
 	}
 
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	record := ruleNode.GrafanaManagedAlert.Record
+	if record != nil {
+		if !cfg.RecordingRules.Enabled {
+			return nil, fmt.Errorf("%w: recording rules are disabled", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if condition != "" && condition != record.From {
+			return nil, fmt.Errorf("%w: condition of a recording rule must be the query it records", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		// the query a recording rule records is its condition, so that it is validated the same way.
+		condition = record.From
+		if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
+			if err := record.Validate(ruleNode.GrafanaManagedAlert.Data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
-			Condition: ruleNode.GrafanaManagedAlert.Condition,
+			Condition: condition,
 			Data:      ruleNode.GrafanaManagedAlert.Data,
 		}
 		if err := conditionValidator(cond); err != nil {
@@ -98,7 +116,7 @@ func validateRuleNode(
 	newAlertRule := ngmodels.AlertRule{
 		OrgID:           orgId,
 		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
+		Condition:       condition,
 		Data:            ruleNode.GrafanaManagedAlert.Data,
 		UID:             ruleNode.GrafanaManagedAlert.UID,
 		IntervalSeconds: intervalSeconds,
@@ -106,6 +124,7 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          record,
 	}
 
 	var err error
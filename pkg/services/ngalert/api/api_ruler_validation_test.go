@@ -715,3 +715,79 @@ func TestValidateRuleNodeIntervalFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNode_Record(t *testing.T) {
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+	interval := cfg.BaseInterval * time.Duration(rand.Int63n(10)+1)
+	validRecordingRule := func() apimodels.PostableExtendedRuleNode {
+		r := validRule()
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &models.Record{Metric: "job:errors:rate5m", From: "A", TargetDatasourceUID: "prometheus"}
+		return r
+	}
+	f := func(condition models.Condition) error {
+		return nil
+	}
+
+	t.Run("should use the recorded query as condition", func(t *testing.T) {
+		r := validRecordingRule()
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), interval, rand.Int63(), randFolder(), f, cfg)
+		require.NoError(t, err)
+		require.Equal(t, "A", alert.Condition)
+		require.Equal(t, r.GrafanaManagedAlert.Record, alert.Record)
+		require.True(t, alert.IsRecordingRule())
+	})
+
+	testCases := []struct {
+		name   string
+		mutate func(r *apimodels.PostableExtendedRuleNode)
+		cfg    func(cfg *setting.UnifiedAlertingSettings)
+	}{
+		{
+			name: "fail if recording rules are disabled",
+			cfg: func(cfg *setting.UnifiedAlertingSettings) {
+				cfg.RecordingRules.Enabled = false
+			},
+		},
+		{
+			name: "fail if condition is not the recorded query",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Condition = "B"
+			},
+		},
+		{
+			name: "fail if metric name is invalid",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Record.Metric = "job errors"
+			},
+		},
+		{
+			name: "fail if recorded query does not exist",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Record.From = "B"
+			},
+		},
+		{
+			name: "fail if target data source is missing",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Record.TargetDatasourceUID = ""
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := validRecordingRule()
+			if testCase.mutate != nil {
+				testCase.mutate(&r)
+			}
+			c := *cfg
+			if testCase.cfg != nil {
+				testCase.cfg(&c)
+			}
+			_, err := validateRuleNode(&r, util.GenerateShortUID(), interval, rand.Int63(), randFolder(), f, &c)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		})
+	}
+}
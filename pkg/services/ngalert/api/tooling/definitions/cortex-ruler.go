@@ -374,6 +374,8 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Record turns the rule into a recording rule, which writes the result of a query to a data source.
+	Record *models.Record `json:"record,omitempty" yaml:"record,omitempty"`
 }
 
 // swagger:model
@@ -393,4 +395,5 @@ type GettableGrafanaRule struct {
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	Provenance      models.Provenance   `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	Record          *models.Record      `json:"record,omitempty" yaml:"record,omitempty"`
 }
@@ -95,6 +95,8 @@ type ProvisionedAlertRule struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// readonly: true
 	Provenance models.Provenance `json:"provenance,omitempty"`
+	// example: {"metric": "job:errors:rate5m", "from": "A", "target_datasource_uid": "prometheus"}
+	Record *models.Record `json:"record,omitempty"`
 }
 
 func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
@@ -117,10 +119,15 @@ func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
 		For:          forDur,
 		Annotations:  a.Annotations,
 		Labels:       a.Labels,
+		Record:       a.Record,
 	}, nil
 }
 
 func NewAlertRule(rule models.AlertRule, provenance models.Provenance) ProvisionedAlertRule {
+	var record *models.Record
+	if rule.IsRecordingRule() {
+		record = rule.Record
+	}
 	return ProvisionedAlertRule{
 		ID:           rule.ID,
 		UID:          rule.UID,
@@ -137,6 +144,7 @@ func NewAlertRule(rule models.AlertRule, provenance models.Provenance) Provision
 		Annotations:  rule.Annotations,
 		Labels:       rule.Labels,
 		Provenance:   provenance,
+		Record:       record,
 	}
 }
 
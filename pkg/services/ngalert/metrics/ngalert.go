@@ -54,6 +54,9 @@ type Scheduler struct {
 	SchedulePeriodicDuration            prometheus.Histogram
 	SchedulableAlertRules               prometheus.Gauge
 	SchedulableAlertRulesHash           prometheus.Gauge
+	OwnedAlertRules                     prometheus.Gauge
+	ShardMembers                        prometheus.Gauge
+	ShardRebalances                     prometheus.Counter
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *legacyMetrics.Ticker
 	EvaluationMissed                    *prometheus.CounterVec
@@ -190,6 +193,27 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 				Name:      "schedule_alert_rules_hash",
 				Help:      "A hash of the alert rules that could be considered for evaluation at the next tick.",
 			}),
+		OwnedAlertRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_owned_alert_rules",
+				Help:      "The number of alert rules evaluated by this instance. It is lower than schedule_alert_rules when rule sharding is enabled.",
+			}),
+		ShardMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_members",
+				Help:      "The number of instances the alert rules are sharded across.",
+			}),
+		ShardRebalances: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_rebalances_total",
+				Help:      "The total number of times the alert rules were reassigned because instances joined or left the cluster.",
+			}),
 		UpdateSchedulableAlertRulesDuration: promauto.With(r).NewHistogram(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
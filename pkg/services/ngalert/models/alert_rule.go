@@ -152,6 +152,8 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is set on recording rules, which write the result of a query instead of producing alerts.
+	Record *Record `xorm:"record"`
 }
 
 type LabelOption func(map[string]string)
@@ -193,7 +195,14 @@ func (alertRule *AlertRule) Diff(rule *AlertRule, ignore ...string) cmputil.Diff
 	var jsonCmp = cmp.Transformer("", func(in json.RawMessage) string {
 		return string(in)
 	})
-	ops = append(ops, cmp.Reporter(&reporter), cmpopts.IgnoreFields(AlertQuery{}, "modelProps"), jsonCmp, cmpopts.EquateEmpty())
+	// rules that are not recording rules are read from the database with an empty record
+	var recordCmp = cmp.Comparer(func(a, b *Record) bool {
+		if a.isEmpty() || b.isEmpty() {
+			return a.isEmpty() && b.isEmpty()
+		}
+		return *a == *b
+	})
+	ops = append(ops, cmp.Reporter(&reporter), cmpopts.IgnoreFields(AlertQuery{}, "modelProps"), jsonCmp, recordCmp, cmpopts.EquateEmpty())
 
 	if len(ignore) > 0 {
 		ops = append(ops, cmpopts.IgnoreFields(AlertRule{}, ignore...))
@@ -267,6 +276,11 @@ func (alertRule *AlertRule) GetGroupKey() AlertRuleGroupKey {
 	return AlertRuleGroupKey{OrgID: alertRule.OrgID, NamespaceUID: alertRule.NamespaceUID, RuleGroup: alertRule.RuleGroup}
 }
 
+// IsRecordingRule returns true if the rule writes the result of a query instead of producing alerts.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return !alertRule.Record.isEmpty()
+}
+
 // PreSave sets default values and loads the updated model for each alert query.
 func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 	for i, q := range alertRule.Data {
@@ -316,6 +330,7 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	Record      *Record `xorm:"record"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -416,6 +431,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRule) {
 	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
+		if ruleToPatch.Record == nil {
+			ruleToPatch.Record = existingRule.Record
+		}
 	}
 	if ruleToPatch.IntervalSeconds == 0 {
 		ruleToPatch.IntervalSeconds = existingRule.IntervalSeconds
@@ -597,3 +597,30 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestRecord(t *testing.T) {
+	t.Run("an empty record should not make a recording rule", func(t *testing.T) {
+		rule := AlertRuleGen()()
+		rule.Record = nil
+		require.False(t, rule.IsRecordingRule())
+
+		loaded := CopyRule(rule)
+		loaded.Record = &Record{}
+		require.False(t, loaded.IsRecordingRule())
+		require.Empty(t, rule.Diff(loaded))
+
+		loaded.Record = &Record{Metric: "job:errors:rate5m", From: rule.Data[0].RefID, TargetDatasourceUID: "prometheus"}
+		require.True(t, loaded.IsRecordingRule())
+		diff := rule.Diff(loaded)
+		require.Len(t, diff, 1)
+		require.Equal(t, "Record", diff[0].Path)
+	})
+
+	t.Run("should validate the record", func(t *testing.T) {
+		data := []AlertQuery{{RefID: "A"}}
+		require.NoError(t, (&Record{Metric: "job:errors:rate5m", From: "A", TargetDatasourceUID: "prometheus"}).Validate(data))
+		require.ErrorIs(t, (&Record{Metric: "job errors", From: "A", TargetDatasourceUID: "prometheus"}).Validate(data), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, (&Record{Metric: "job:errors:rate5m", From: "B", TargetDatasourceUID: "prometheus"}).Validate(data), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, (&Record{Metric: "job:errors:rate5m", From: "A"}).Validate(data), ErrAlertRuleFailedValidation)
+	})
+}
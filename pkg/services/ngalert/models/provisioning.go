@@ -8,6 +8,8 @@ const (
 	ProvenanceNone Provenance = ""
 	ProvenanceAPI  Provenance = "api"
 	ProvenanceFile Provenance = "file"
+	// ProvenanceGit is set on the resources synced from a git repository.
+	ProvenanceGit Provenance = "git"
 )
 
 // Provisionable represents a resource that can be created through a provisioning mechanism, such as Terraform or config file.
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	prometheusModel "github.com/prometheus/common/model"
+)
+
+// Record contains the configuration of a recording rule. Instead of producing alerts, a recording rule
+// writes the result of one of its queries or expressions to a Prometheus-compatible data source.
+type Record struct {
+	// Metric is the name of the metric the series are written to.
+	Metric string `json:"metric" yaml:"metric"`
+	// From is the refID of the query or expression whose result is written.
+	From string `json:"from" yaml:"from"`
+	// TargetDatasourceUID is the UID of the Prometheus data source the series are written to.
+	TargetDatasourceUID string `json:"target_datasource_uid" yaml:"target_datasource_uid"`
+}
+
+// Validate checks that the record refers to one of the queries of the rule and can be written.
+func (r *Record) Validate(data []AlertQuery) error {
+	if !prometheusModel.IsValidMetricName(prometheusModel.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: metric name %q of the recording rule is not a valid Prometheus metric name", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	if r.TargetDatasourceUID == "" {
+		return fmt.Errorf("%w: target data source of the recording rule is not specified", ErrAlertRuleFailedValidation)
+	}
+	for _, q := range data {
+		if q.RefID == r.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: recording rule refers to query %q that does not exist", ErrAlertRuleFailedValidation, r.From)
+}
+
+func (r *Record) isEmpty() bool {
+	return r == nil || *r == Record{}
+}
+
+// FromDB loads the record from its JSON representation in the database.
+func (r *Record) FromDB(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, r)
+}
+
+// ToDB converts the record to JSON to store it in the database.
+func (r *Record) ToDB() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
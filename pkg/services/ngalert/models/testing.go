@@ -248,6 +248,10 @@ func CopyRule(r *AlertRule) *AlertRule {
 		p := *r.PanelID
 		result.PanelID = &p
 	}
+	if r.Record != nil {
+		record := *r.Record
+		result.Record = &record
+	}
 
 	for _, d := range r.Data {
 		q := AlertQuery{
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -30,6 +31,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -42,7 +44,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, expressionService *expr.Service, dataProxy *datasourceproxy.DataSourceProxyService,
 	quotaService quota.Service, secretsService secrets.Service, notificationService notifications.Service, m *metrics.NGAlert,
 	folderService dashboards.FolderService, ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService, renderService rendering.Service,
-	bus bus.Bus, accesscontrolService accesscontrol.Service, annotationsRepo annotations.Repository) (*AlertNG, error) {
+	bus bus.Bus, accesscontrolService accesscontrol.Service, annotationsRepo annotations.Repository, httpClientProvider httpclient.Provider) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                  cfg,
 		DataSourceCache:      dataSourceCache,
@@ -64,6 +66,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		bus:                  bus,
 		accesscontrolService: accesscontrolService,
 		annotationsRepo:      annotationsRepo,
+		httpClientProvider:   httpClientProvider,
 	}
 
 	if ng.IsDisabled() {
@@ -105,6 +108,7 @@ type AlertNG struct {
 	accesscontrol        accesscontrol.AccessControl
 	accesscontrolService accesscontrol.Service
 	annotationsRepo      annotations.Repository
+	httpClientProvider   httpclient.Provider
 
 	bus bus.Bus
 }
@@ -167,6 +171,16 @@ func (ng *AlertNG) init() error {
 		Metrics:       ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:   alertsRouter,
 	}
+	if ng.Cfg.UnifiedAlerting.HARuleSharding {
+		if len(ng.Cfg.UnifiedAlerting.HAPeers) > 0 {
+			schedCfg.Cluster = ng.MultiOrgAlertmanager
+		} else {
+			ng.Log.Warn("alert rule sharding is enabled but no HA peers are configured. All alert rules are evaluated by this instance")
+		}
+	}
+	if recordingCfg := ng.Cfg.UnifiedAlerting.RecordingRules; recordingCfg.Enabled {
+		schedCfg.RecordingWriter = writer.NewPrometheusWriter(ng.DataSourceService, ng.httpClientProvider, recordingCfg.Timeout, recordingCfg.RemoteWritePath, log.New("ngalert.writer"))
+	}
 
 	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.dashboardService, ng.imageService, clk, ng.annotationsRepo)
 	scheduler := schedule.NewScheduler(schedCfg, appUrl, stateManager)
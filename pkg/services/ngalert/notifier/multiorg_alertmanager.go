@@ -328,6 +328,22 @@ func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (*Alertmanager, er
 	return orgAM, nil
 }
 
+// ClusterMembers returns the name of this instance in the gossip cluster and the names of the alive members,
+// this instance included. It returns no members when HA is not configured or while the cluster is settling.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string) {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok || !p.Ready() {
+		return "", nil
+	}
+
+	peers := p.Peers()
+	members := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		members = append(members, peer.Name())
+	}
+	return p.Name(), members
+}
+
 // NilPeer and NilChannel implements the Alertmanager clustering interface.
 type NilPeer struct{}
 
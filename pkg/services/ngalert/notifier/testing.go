@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -205,6 +206,27 @@ func (fkv *FakeKVStore) GetAll(ctx context.Context, orgId int64, namespace strin
 	return nil, nil
 }
 
+func (fkv *FakeKVStore) SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, _ time.Duration) error {
+	return fkv.Set(ctx, orgId, namespace, key, value)
+}
+
+func (fkv *FakeKVStore) GetVersioned(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error) {
+	value, ok, err := fkv.Get(ctx, orgId, namespace, key)
+	return value, 1, ok, err
+}
+
+func (fkv *FakeKVStore) CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, _ int64, value string, _ time.Duration) (int64, error) {
+	return 1, fkv.Set(ctx, orgId, namespace, key, value)
+}
+
+func (fkv *FakeKVStore) Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan kvstore.Event, error) {
+	return make(chan kvstore.Event), nil
+}
+
+func (fkv *FakeKVStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
 type fakeState struct {
 	data string
 }
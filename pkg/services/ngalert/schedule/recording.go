@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// RecordingWriter writes the series produced by recording rules to their target data source.
+type RecordingWriter interface {
+	Write(ctx context.Context, orgID int64, datasourceUID string, series []prompb.TimeSeries) error
+}
+
+// recordRule evaluates the queries and expressions of a recording rule and writes the result of the
+// query the rule records as series named after the metric of the rule, with the labels of the rule.
+func (sch *schedule) recordRule(ctx context.Context, user *user.SignedInUser, e *evaluation) error {
+	record := e.rule.Record
+	resp, err := sch.evaluator.QueriesAndExpressionsEval(ctx, user, e.rule.Data, e.scheduledAt)
+	if err != nil {
+		return err
+	}
+
+	result, ok := resp.Responses[record.From]
+	if !ok {
+		return fmt.Errorf("no result for query %s", record.From)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("failed to execute query %s: %w", record.From, result.Error)
+	}
+
+	series := remotewrite.TimeSeriesFromFramesAsMetric(record.Metric, e.rule.Labels, e.scheduledAt, result.Frames...)
+	return sch.recordingWriter.Write(ctx, e.rule.OrgID, record.TargetDatasourceUID, latestSamples(series))
+}
+
+// latestSamples keeps only the most recent sample of each series. Range queries return every sample
+// of their window at each evaluation, the older samples were written by the previous evaluations and
+// would be rejected by the target as out of order or duplicated.
+func latestSamples(series []prompb.TimeSeries) []prompb.TimeSeries {
+	result := make([]prompb.TimeSeries, 0, len(series))
+	for _, ts := range series {
+		if len(ts.Samples) == 0 {
+			continue
+		}
+		latest := ts.Samples[0]
+		for _, sample := range ts.Samples[1:] {
+			if sample.Timestamp >= latest.Timestamp {
+				latest = sample
+			}
+		}
+		ts.Samples = []prompb.Sample{latest}
+		result = append(result, ts)
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeRecordingWriter struct {
+	orgID         int64
+	datasourceUID string
+	series        []prompb.TimeSeries
+}
+
+func (f *fakeRecordingWriter) Write(_ context.Context, orgID int64, datasourceUID string, series []prompb.TimeSeries) error {
+	f.orgID = orgID
+	f.datasourceUID = datasourceUID
+	f.series = series
+	return nil
+}
+
+func TestSchedule_recordRule(t *testing.T) {
+	rule := models.AlertRuleGen(func(rule *models.AlertRule) {
+		rule.Labels = map[string]string{"team": "sre"}
+		rule.Record = &models.Record{Metric: "job:errors:rate5m", From: rule.Data[0].RefID, TargetDatasourceUID: "mimir"}
+	})()
+	scheduledAt := time.Unix(1000, 0)
+	value := 42.0
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(900, 0)}),
+		data.NewField("value", data.Labels{"job": "api"}, []*float64{&value}),
+	)
+
+	t.Run("should write the result of the recorded query", func(t *testing.T) {
+		evaluator := &eval.FakeEvaluator{}
+		evaluator.EXPECT().QueriesAndExpressionsEval(mock.Anything, mock.Anything, rule.Data, scheduledAt).Return(&backend.QueryDataResponse{
+			Responses: backend.Responses{rule.Record.From: {Frames: data.Frames{frame}}},
+		}, nil)
+		sch := setupScheduler(t, nil, nil, nil, nil, evaluator)
+		writer := &fakeRecordingWriter{}
+		sch.recordingWriter = writer
+
+		require.NoError(t, sch.recordRule(context.Background(), nil, &evaluation{scheduledAt: scheduledAt, rule: rule}))
+		require.Equal(t, rule.OrgID, writer.orgID)
+		require.Equal(t, "mimir", writer.datasourceUID)
+		require.Equal(t, []prompb.TimeSeries{{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "job:errors:rate5m"},
+				{Name: "job", Value: "api"},
+				{Name: "team", Value: "sre"},
+			},
+			Samples: []prompb.Sample{{Timestamp: 900000, Value: 42}},
+		}}, writer.series)
+	})
+
+	t.Run("should only write the latest sample of each series", func(t *testing.T) {
+		latest := 7.0
+		rangeFrame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(800, 0), time.Unix(950, 0), time.Unix(900, 0)}),
+			data.NewField("value", data.Labels{"job": "api"}, []*float64{&value, &latest, &value}),
+		)
+
+		evaluator := &eval.FakeEvaluator{}
+		evaluator.EXPECT().QueriesAndExpressionsEval(mock.Anything, mock.Anything, rule.Data, scheduledAt).Return(&backend.QueryDataResponse{
+			Responses: backend.Responses{rule.Record.From: {Frames: data.Frames{rangeFrame}}},
+		}, nil)
+		sch := setupScheduler(t, nil, nil, nil, nil, evaluator)
+		writer := &fakeRecordingWriter{}
+		sch.recordingWriter = writer
+
+		require.NoError(t, sch.recordRule(context.Background(), nil, &evaluation{scheduledAt: scheduledAt, rule: rule}))
+		require.Len(t, writer.series, 1)
+		require.Equal(t, []prompb.Sample{{Timestamp: 950000, Value: 7}}, writer.series[0].Samples)
+	})
+
+	t.Run("should fail if the recorded query failed", func(t *testing.T) {
+		evaluator := &eval.FakeEvaluator{}
+		evaluator.EXPECT().QueriesAndExpressionsEval(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&backend.QueryDataResponse{
+			Responses: backend.Responses{rule.Record.From: {Error: errors.New("query timed out")}},
+		}, nil)
+		sch := setupScheduler(t, nil, nil, nil, nil, evaluator)
+		writer := &fakeRecordingWriter{}
+		sch.recordingWriter = writer
+
+		err := sch.recordRule(context.Background(), nil, &evaluation{scheduledAt: scheduledAt, rule: rule})
+		require.ErrorContains(t, err, "query timed out")
+		require.Nil(t, writer.series)
+	})
+}
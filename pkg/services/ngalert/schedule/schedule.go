@@ -96,11 +96,21 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	// recordingWriter writes the result of recording rules. When nil, recording rules are not evaluated.
+	recordingWriter RecordingWriter
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
+
+	// cluster provides the instances the alert rules are sharded across.
+	// When nil, this instance evaluates all the alert rules.
+	cluster ClusterMembership
+	// shardRing assigns the alert rules to the members of the cluster.
+	// It is only accessed from the scheduling loop.
+	shardRing *shardRing
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -115,6 +125,10 @@ type SchedulerCfg struct {
 	InstanceStore   store.InstanceStore
 	Metrics         *metrics.Scheduler
 	AlertSender     AlertsSender
+	// Cluster enables the sharding of alert rules across the members of the cluster.
+	Cluster ClusterMembership
+	// RecordingWriter enables the evaluation of recording rules.
+	RecordingWriter RecordingWriter
 }
 
 // NewScheduler returns a new schedule.
@@ -139,6 +153,8 @@ func NewScheduler(cfg SchedulerCfg, appURL *url.URL, stateManager *state.Manager
 		minRuleInterval:       cfg.Cfg.MinInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		cluster:               cfg.Cluster,
+		recordingWriter:       cfg.RecordingWriter,
 	}
 
 	return &sch
@@ -205,17 +221,21 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			}
 			alertRules, folderTitles := sch.schedulableAlertRules.all()
 
+			// While these are the rules that we iterate over, at the moment there's no 100% guarantee that they'll be
+			// scheduled as rules could be removed before we get a chance to evaluate them.
+			sch.metrics.SchedulableAlertRules.Set(float64(len(alertRules)))
+			sch.metrics.SchedulableAlertRulesHash.Set(float64(hashUIDs(alertRules)))
+
+			// in HA mode with rule sharding, the rules assigned to other instances are stopped here
+			// and are not considered as deleted below.
+			alertRules = sch.ownedAlertRules(ctx, alertRules)
+
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
 			// each alert rule found also in this cycle is removed
 			// so, at the end, the remaining registered alert rules are the deleted ones
 			registeredDefinitions := sch.registry.keyMap()
 
-			// While these are the rules that we iterate over, at the moment there's no 100% guarantee that they'll be
-			// scheduled as rules could be removed before we get a chance to evaluate them.
-			sch.metrics.SchedulableAlertRules.Set(float64(len(alertRules)))
-			sch.metrics.SchedulableAlertRulesHash.Set(float64(hashUIDs(alertRules)))
-
 			type readyToRunItem struct {
 				ruleInfo *alertRuleInfo
 				evaluation
@@ -348,6 +368,24 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			},
 		}
 
+		if e.rule.IsRecordingRule() {
+			if sch.recordingWriter == nil {
+				logger.Debug("skip recording rule because recording rules are disabled")
+				return
+			}
+			err := sch.recordRule(ctx, schedulerUser, e)
+			dur := sch.clock.Now().Sub(start)
+			evalTotal.Inc()
+			evalDuration.Observe(dur.Seconds())
+			if err != nil {
+				evalTotalFailures.Inc()
+				logger.Error("failed to evaluate recording rule", "err", err, "duration", dur)
+			} else {
+				logger.Debug("recording rule evaluated", "duration", dur)
+			}
+			return
+		}
+
 		results := sch.evaluator.ConditionEval(ctx, schedulerUser, e.rule.GetEvalCondition(), e.scheduledAt)
 		dur := sch.clock.Now().Sub(start)
 		evalTotal.Inc()
@@ -438,6 +476,10 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			if errors.Is(grafanaCtx.Err(), errRuleDeleted) {
 				clearState()
 			}
+			// keep the state in the database for the instance that now evaluates the rule
+			if errors.Is(grafanaCtx.Err(), errRuleNotOwned) {
+				sch.stateManager.ForgetRule(key)
+			}
 			logger.Debug("stopping alert rule routine")
 			return nil
 		}
@@ -208,6 +208,29 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
 		})
+		t.Run("and drop the cached state if the rule is assigned to another instance", func(t *testing.T) {
+			stoppedChan := make(chan error)
+			sender := AlertsSenderMock{}
+			sch, _, _, _ := createSchedule(make(chan time.Time), &sender)
+
+			rule := models.AlertRuleGen()()
+			_ = sch.stateManager.ProcessEvalResults(context.Background(), sch.clock.Now(), rule, eval.GenerateResults(rand.Intn(5)+1, eval.ResultGen()), nil)
+			require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+			ctx, cancel := util.WithCancelCause(context.Background())
+			go func() {
+				err := sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), make(chan ruleVersion))
+				stoppedChan <- err
+			}()
+
+			cancel(errRuleNotOwned)
+			err := waitForErrChannel(t, stoppedChan)
+			require.NoError(t, err)
+
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			// The new owner resolves the alerts, they are not sent as stopped.
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		})
 	})
 
 	t.Run("when a message is sent to update channel", func(t *testing.T) {
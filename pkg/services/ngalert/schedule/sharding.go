@@ -0,0 +1,157 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// shardVirtualNodes is the number of points each instance gets on the hash ring.
+// More points spread the alert rules more evenly across instances.
+const shardVirtualNodes = 128
+
+// errRuleNotOwned is the reason the routine of an alert rule is stopped when the rule
+// is assigned to another instance. Unlike errRuleDeleted, the state of the rule is kept
+// in the database so that the new owner can resume from it.
+var errRuleNotOwned = errors.New("rule assigned to another instance")
+
+// ClusterMembership provides the live Grafana instances the alert rules are sharded across.
+type ClusterMembership interface {
+	// ClusterMembers returns the name of this instance and the names of all live instances,
+	// this one included.
+	ClusterMembers() (self string, members []string)
+}
+
+// shardRing is a consistent hash ring that assigns alert rules to instances. When an instance
+// joins or leaves, only the rules of the adjacent points of the ring change owner.
+type shardRing struct {
+	members []string
+	points  []uint64
+	owners  map[uint64]string
+}
+
+func newShardRing(members []string) *shardRing {
+	r := &shardRing{
+		members: make([]string, len(members)),
+		points:  make([]uint64, 0, len(members)*shardVirtualNodes),
+		owners:  make(map[uint64]string, len(members)*shardVirtualNodes),
+	}
+	copy(r.members, members)
+	sort.Strings(r.members)
+
+	for _, member := range r.members {
+		for i := 0; i < shardVirtualNodes; i++ {
+			point := shardHash(member + "#" + strconv.Itoa(i))
+			// On collision, the point belongs to the first member in alphabetical order
+			// so that every instance builds the same ring.
+			if _, ok := r.owners[point]; ok {
+				continue
+			}
+			r.owners[point] = member
+			r.points = append(r.points, point)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// owner returns the instance the alert rule is assigned to, or an empty string if the ring has no members.
+func (r *shardRing) owner(key ngmodels.AlertRuleKey) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := shardHash(strconv.FormatInt(key.OrgID, 10) + "/" + key.UID)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// equal returns true if the ring was built from the same members.
+func (r *shardRing) equal(members []string) bool {
+	if len(members) != len(r.members) {
+		return false
+	}
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",") == strings.Join(r.members, ",")
+}
+
+func shardHash(s string) uint64 {
+	h := fnv.New64a()
+	// We can ignore err as fnv64 does not return an error
+	// nolint:errcheck,gosec
+	h.Write([]byte(s))
+	return mix64(h.Sum64())
+}
+
+// mix64 is the finalizer of MurmurHash3. FNV alone clusters the hashes of strings
+// that only differ by their last characters, such as the virtual nodes of an instance.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// ownedAlertRules returns the alert rules this instance evaluates. Without cluster membership,
+// every rule is evaluated. When the members of the cluster change, the rules are reassigned and
+// the state of the rules this instance took over is loaded from the database, where the previous
+// owner saved it after each evaluation. The routines of the rules handed off to another instance
+// drop their state from the cache when they stop.
+func (sch *schedule) ownedAlertRules(ctx context.Context, alertRules []*ngmodels.AlertRule) []*ngmodels.AlertRule {
+	if sch.cluster == nil {
+		sch.metrics.OwnedAlertRules.Set(float64(len(alertRules)))
+		return alertRules
+	}
+
+	self, members := sch.cluster.ClusterMembers()
+	if self == "" || len(members) == 0 {
+		// The membership is unknown, evaluating every rule is safer than evaluating none.
+		sch.metrics.OwnedAlertRules.Set(float64(len(alertRules)))
+		return alertRules
+	}
+
+	rebalanced := false
+	if sch.shardRing == nil || !sch.shardRing.equal(members) {
+		rebalanced = sch.shardRing != nil
+		sch.shardRing = newShardRing(members)
+		sch.metrics.ShardMembers.Set(float64(len(members)))
+		sch.log.Info("alert rules are sharded across the cluster members", "self", self, "members", strings.Join(sch.shardRing.members, ","))
+	}
+
+	owned := make([]*ngmodels.AlertRule, 0, len(alertRules))
+	var acquired []*ngmodels.AlertRule
+	for _, rule := range alertRules {
+		key := rule.GetKey()
+		if sch.shardRing.owner(key) != self {
+			if info, ok := sch.registry.del(key); ok {
+				info.stop(errRuleNotOwned)
+			}
+			continue
+		}
+		if !sch.registry.exists(key) {
+			acquired = append(acquired, rule)
+		}
+		owned = append(owned, rule)
+	}
+
+	if rebalanced {
+		sch.metrics.ShardRebalances.Inc()
+		sch.log.Info("alert rules were reassigned", "owned_rules", len(owned), "acquired_rules", len(acquired))
+		if len(acquired) > 0 {
+			sch.stateManager.WarmRules(ctx, acquired)
+		}
+	}
+	sch.metrics.OwnedAlertRules.Set(float64(len(owned)))
+	return owned
+}
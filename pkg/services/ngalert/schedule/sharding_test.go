@@ -0,0 +1,145 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) ClusterMembers() (string, []string) {
+	return f.self, f.members
+}
+
+func TestShardRing(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 3000)
+	for i := 0; i < 3000; i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+
+	t.Run("should spread the rules across all members", func(t *testing.T) {
+		ring := newShardRing([]string{"grafana-0", "grafana-1", "grafana-2"})
+		counts := map[string]int{}
+		for _, key := range keys {
+			counts[ring.owner(key)]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			assert.Greater(t, count, len(keys)/5, "member %s owns too few rules", member)
+		}
+	})
+
+	t.Run("should assign the rules the same way regardless of the order of the members", func(t *testing.T) {
+		ring := newShardRing([]string{"grafana-0", "grafana-1", "grafana-2"})
+		other := newShardRing([]string{"grafana-2", "grafana-0", "grafana-1"})
+		require.True(t, ring.equal([]string{"grafana-1", "grafana-2", "grafana-0"}))
+		for _, key := range keys {
+			require.Equal(t, ring.owner(key), other.owner(key))
+		}
+	})
+
+	t.Run("should only reassign the rules of a member that left", func(t *testing.T) {
+		ring := newShardRing([]string{"grafana-0", "grafana-1", "grafana-2"})
+		shrunk := newShardRing([]string{"grafana-0", "grafana-1"})
+		require.False(t, ring.equal(shrunk.members))
+		for _, key := range keys {
+			if owner := ring.owner(key); owner != "grafana-2" {
+				require.Equal(t, owner, shrunk.owner(key))
+			}
+		}
+	})
+
+	t.Run("should not assign rules without members", func(t *testing.T) {
+		require.Empty(t, newShardRing(nil).owner(keys[0]))
+	})
+}
+
+func TestSchedule_ownedAlertRules(t *testing.T) {
+	rules := models.GenerateAlertRules(100, models.AlertRuleGen())
+
+	t.Run("should evaluate all rules without cluster", func(t *testing.T) {
+		sch := setupScheduler(t, nil, nil, nil, nil, nil)
+		require.Len(t, sch.ownedAlertRules(context.Background(), rules), len(rules))
+		assert.Equal(t, float64(len(rules)), testutil.ToFloat64(sch.metrics.OwnedAlertRules))
+	})
+
+	t.Run("should evaluate all rules while the cluster members are unknown", func(t *testing.T) {
+		sch := setupScheduler(t, nil, nil, nil, nil, nil)
+		sch.cluster = &fakeClusterMembership{}
+		require.Len(t, sch.ownedAlertRules(context.Background(), rules), len(rules))
+	})
+
+	t.Run("should stop the rules of other members and take them over when they leave", func(t *testing.T) {
+		instanceStore := &store.FakeInstanceStore{}
+		sch := setupScheduler(t, nil, instanceStore, nil, nil, nil)
+		cluster := &fakeClusterMembership{self: "grafana-0", members: []string{"grafana-0", "grafana-1"}}
+		sch.cluster = cluster
+
+		infos := make(map[models.AlertRuleKey]*alertRuleInfo, len(rules))
+		for _, rule := range rules {
+			infos[rule.GetKey()], _ = sch.registry.getOrCreateInfo(context.Background(), rule.GetKey())
+		}
+
+		owned := sch.ownedAlertRules(context.Background(), rules)
+		require.NotEmpty(t, owned)
+		require.Less(t, len(owned), len(rules))
+		assert.Equal(t, float64(len(owned)), testutil.ToFloat64(sch.metrics.OwnedAlertRules))
+		assert.Equal(t, float64(2), testutil.ToFloat64(sch.metrics.ShardMembers))
+		assert.Zero(t, testutil.ToFloat64(sch.metrics.ShardRebalances))
+
+		ownedKeys := make(map[models.AlertRuleKey]struct{}, len(owned))
+		for _, rule := range owned {
+			ownedKeys[rule.GetKey()] = struct{}{}
+		}
+		for key, info := range infos {
+			if _, ok := ownedKeys[key]; ok {
+				require.NoError(t, info.ctx.Err())
+				require.True(t, sch.registry.exists(key))
+			} else {
+				require.ErrorIs(t, info.ctx.Err(), errRuleNotOwned)
+				require.False(t, sch.registry.exists(key))
+			}
+		}
+
+		// The same members keep the same rules and the state cache is left as is.
+		sch.stateManager.Put([]*state.State{{AlertRuleUID: owned[0].UID, OrgID: owned[0].OrgID, CacheId: "cached", State: eval.Alerting}})
+		require.Len(t, sch.ownedAlertRules(context.Background(), rules), len(owned))
+		require.Len(t, sch.stateManager.GetStatesForRuleUID(owned[0].OrgID, owned[0].UID), 1)
+
+		var acquired *models.AlertRule
+		for _, rule := range rules {
+			if _, ok := ownedKeys[rule.GetKey()]; !ok {
+				acquired = rule
+				break
+			}
+		}
+		sch.stateManager.Put([]*state.State{{AlertRuleUID: acquired.UID, OrgID: acquired.OrgID, CacheId: "stale", State: eval.Alerting}})
+
+		cluster.members = []string{"grafana-0"}
+		require.Len(t, sch.ownedAlertRules(context.Background(), rules), len(rules))
+		assert.Equal(t, float64(len(rules)), testutil.ToFloat64(sch.metrics.OwnedAlertRules))
+		assert.Equal(t, float64(1), testutil.ToFloat64(sch.metrics.ShardMembers))
+		assert.Equal(t, float64(1), testutil.ToFloat64(sch.metrics.ShardRebalances))
+		// The state of the rules that were already owned is kept.
+		require.Len(t, sch.stateManager.GetStatesForRuleUID(owned[0].OrgID, owned[0].UID), 1)
+		// The state of the acquired rules was loaded from the database, where the fake instance store has no state.
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(acquired.OrgID, acquired.UID))
+		require.NotEmpty(t, instanceStore.RecordedOps)
+		for _, op := range instanceStore.RecordedOps {
+			require.IsType(t, models.ListAlertInstancesQuery{}, op)
+		}
+	})
+}
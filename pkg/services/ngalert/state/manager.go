@@ -110,26 +110,7 @@ func (st *Manager) Warm(ctx context.Context) {
 				st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
 				continue
 			}
-
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheId:              cacheId,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, st.stateFromInstance(entry, ruleForEntry))
 		}
 	}
 
@@ -138,6 +119,63 @@ func (st *Manager) Warm(ctx context.Context) {
 	}
 }
 
+// WarmRules replaces the cached state of the given rules with the state saved in the database. It is used when
+// this instance takes over the evaluation of rules from another instance, which saved their state after each evaluation.
+func (st *Manager) WarmRules(ctx context.Context, rules []*ngModels.AlertRule) {
+	rulesByOrg := make(map[int64]map[string]*ngModels.AlertRule)
+	for _, rule := range rules {
+		if rulesByOrg[rule.OrgID] == nil {
+			rulesByOrg[rule.OrgID] = make(map[string]*ngModels.AlertRule)
+		}
+		rulesByOrg[rule.OrgID][rule.UID] = rule
+	}
+
+	for orgID, ruleByUID := range rulesByOrg {
+		cmd := ngModels.ListAlertInstancesQuery{
+			RuleOrgID: orgID,
+		}
+		if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+			st.log.Error("unable to fetch previous state", "orgID", orgID, "msg", err.Error())
+			continue
+		}
+
+		for uid := range ruleByUID {
+			st.cache.removeByRuleUID(orgID, uid)
+		}
+		for _, entry := range cmd.Result {
+			if rule, ok := ruleByUID[entry.RuleUID]; ok {
+				st.set(st.stateFromInstance(entry, rule))
+			}
+		}
+	}
+}
+
+// ForgetRule removes the state of a rule from the cache but, unlike ResetStateByRuleUID, keeps it in the database.
+// It is used when the evaluation of the rule is handed off to another instance.
+func (st *Manager) ForgetRule(ruleKey ngModels.AlertRuleKey) []*State {
+	return st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheId:              cacheId,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+	}
+}
+
 func (st *Manager) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels) *State {
 	return st.cache.getOrCreate(ctx, alertRule, result, extraLabels)
 }
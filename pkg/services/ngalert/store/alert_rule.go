@@ -7,11 +7,15 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+	entitystore "github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -94,7 +98,7 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("deleted alert instances", "count", rows)
-		return nil
+		return st.insertEntityEvents(sess, orgID, entitystore.EntityEventTypeDelete, ruleUID...)
 	})
 }
 
@@ -192,6 +196,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				For:              r.For,
 				Annotations:      r.Annotations,
 				Labels:           r.Labels,
+				Record:           r.Record,
 			})
 		}
 		if len(newRules) > 0 {
@@ -205,6 +210,9 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 					return fmt.Errorf("failed to create new rules: %w", err)
 				}
 				ids[newRules[i].UID] = newRules[i].ID
+				if err := st.insertEntityEvents(sess, newRules[i].OrgID, entitystore.EntityEventTypeCreate, newRules[i].UID); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -241,6 +249,9 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []UpdateRule) erro
 				}
 				return fmt.Errorf("%w: alert rule UID %s version %d", ErrOptimisticLock, r.New.UID, r.New.Version)
 			}
+			if err := st.insertEntityEvents(sess, r.New.OrgID, entitystore.EntityEventTypeUpdate, r.New.UID); err != nil {
+				return err
+			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
 				RuleOrgID:        r.New.OrgID,
@@ -260,6 +271,7 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []UpdateRule) erro
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				Record:           r.New.Record,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -271,6 +283,19 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []UpdateRule) erro
 	})
 }
 
+// insertEntityEvents saves the changes of the alert rules as entity events, which are used to update the search index.
+func (st DBstore) insertEntityEvents(sess *sqlstore.DBSession, orgID int64, eventType entitystore.EntityEventType, ruleUIDs ...string) error {
+	if !entitystore.EntityEventsEnabled(st.SQLStore.Cfg) {
+		return nil
+	}
+	for _, uid := range ruleUIDs {
+		if _, err := sess.Insert(entitystore.CreateDatabaseEntityEvent(uid, orgID, entitystore.EntityTypeAlertRule, eventType)); err != nil {
+			return fmt.Errorf("failed to save entity event: %w", err)
+		}
+	}
+	return nil
+}
+
 // ListAlertRules is a handler for retrieving alert rules of specific organisation.
 func (st DBstore) ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
@@ -381,12 +406,112 @@ func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, use
 		}
 		page += 1
 	}
+
+	if st.SQLStore.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagNestedFolders) {
+		if err := st.addNestedNamespaces(ctx, orgID, user, namespaceMap); err != nil {
+			return nil, err
+		}
+	}
 	return namespaceMap, nil
 }
 
+// namespaceFolder is a folder of the dashboard table with the id of its parent folder.
+type namespaceFolder struct {
+	ID       int64  `xorm:"id"`
+	UID      string `xorm:"uid"`
+	Title    string `xorm:"title"`
+	FolderID int64  `xorm:"folder_id"`
+}
+
+// addNestedNamespaces adds the nested folders with alert rules that the user can read through the permissions of a
+// parent folder, which the search does not resolve, and uses the path of the nested folders as the namespace title.
+func (st DBstore) addNestedNamespaces(ctx context.Context, orgID int64, user *user.SignedInUser, namespaceMap map[string]*models.Folder) error {
+	var nested []namespaceFolder
+	folders := make(map[int64]namespaceFolder)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		isFolder := st.SQLStore.Dialect.BooleanStr(true)
+		err := sess.SQL(`SELECT d.id, d.uid, d.title, d.folder_id FROM dashboard AS d
+			WHERE d.org_id = ? AND d.is_folder = `+isFolder+` AND d.folder_id > 0
+			AND EXISTS (SELECT 1 FROM alert_rule AS r WHERE r.org_id = d.org_id AND r.namespace_uid = d.uid)`, orgID).Find(&nested)
+		if err != nil {
+			return err
+		}
+
+		// only the ancestors of the nested folders are loaded, one level at a time
+		parentIDs := make([]interface{}, 0, len(nested))
+		for _, folder := range nested {
+			parentIDs = append(parentIDs, folder.FolderID)
+		}
+		for level := 1; level < dashboards.MaxNestedFolderDepth && len(parentIDs) > 0; level++ {
+			var parents []namespaceFolder
+			err := sess.SQL("SELECT id, uid, title, folder_id FROM dashboard WHERE org_id = ? AND is_folder = "+isFolder+
+				" AND id IN (?"+strings.Repeat(",?", len(parentIDs)-1)+")", append([]interface{}{orgID}, parentIDs...)...).Find(&parents)
+			if err != nil {
+				return err
+			}
+			parentIDs = parentIDs[:0]
+			for _, parent := range parents {
+				folders[parent.ID] = parent
+				if _, ok := folders[parent.FolderID]; parent.FolderID > 0 && !ok {
+					parentIDs = append(parentIDs, parent.FolderID)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, folder := range nested {
+		namespace, ok := namespaceMap[folder.UID]
+		if !ok {
+			// the folder scope resolvers grant the permissions of the parent folders
+			if st.AccessControl == nil || st.AccessControl.IsDisabled() {
+				continue
+			}
+			canRead, err := st.AccessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(dashboards.ActionFoldersRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)))
+			if err != nil {
+				return err
+			}
+			if !canRead {
+				continue
+			}
+			namespace = &models.Folder{Id: folder.ID, Uid: folder.UID}
+			namespaceMap[folder.UID] = namespace
+		}
+
+		var parentTitles []string
+		parentID := folder.FolderID
+		for parentID > 0 && len(parentTitles) < dashboards.MaxNestedFolderDepth {
+			parent, ok := folders[parentID]
+			if !ok {
+				break
+			}
+			if namespace.ParentUid == "" {
+				namespace.ParentUid = parent.UID
+			}
+			parentTitles = append([]string{parent.Title}, parentTitles...)
+			parentID = parent.FolderID
+		}
+		namespace.Title = dashboards.FolderPath(parentTitles, folder.Title)
+	}
+	return nil
+}
+
 // GetNamespaceByTitle is a handler for retrieving a namespace by its title. Alerting rules follow a Grafana folder-like structure which we call namespaces.
+// The namespaces of nested folders can also be the paths of the folders, e.g. "Team/Service".
 func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, orgID int64, user *user.SignedInUser, withCanSave bool) (*models.Folder, error) {
 	folder, err := st.FolderService.GetFolderByTitle(ctx, user, orgID, namespace)
+	if errors.Is(err, dashboards.ErrFolderNotFound) && strings.Contains(namespace, dashboards.FolderPathSeparator) {
+		folder, err = dashboards.FindFolderByPath(namespace, func(parent *models.Folder) ([]*models.Folder, error) {
+			parentUID := ""
+			if parent != nil {
+				parentUID = parent.Uid
+			}
+			return st.FolderService.GetChildFolders(ctx, user, orgID, parentUID)
+		})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -548,5 +673,11 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
 	}
+
+	if alertRule.IsRecordingRule() {
+		if err := alertRule.Record.Validate(alertRule.Data); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	databasestore "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationAddNestedNamespaces(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	dashboardStore := databasestore.ProvideDashboardStore(sqlStore, featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders), tagimpl.ProvideService(sqlStore))
+
+	sqlStore.Cfg.RBACEnabled = true
+	ac := acimpl.ProvideAccessControl(sqlStore.Cfg)
+	ac.RegisterScopeAttributeResolver(dashboards.NewFolderUIDScopeResolver(dashboardStore))
+
+	store := DBstore{
+		SQLStore:      sqlStore,
+		AccessControl: ac,
+	}
+
+	saveFolder := func(t *testing.T, title string, parentID int64) *gfmodels.Dashboard {
+		t.Helper()
+		folder, err := dashboardStore.SaveDashboard(context.Background(), gfmodels.SaveDashboardCommand{
+			OrgId:    1,
+			FolderId: parentID,
+			IsFolder: true,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"title": title,
+			}),
+		})
+		require.NoError(t, err)
+		return folder
+	}
+	team := saveFolder(t, "Team", 0)
+	service := saveFolder(t, "Service", team.Id)
+	other := saveFolder(t, "Other", 0)
+	database := saveFolder(t, "Database", other.Id)
+	deep := saveFolder(t, "Deep", service.Id)
+
+	for _, folder := range []*gfmodels.Dashboard{service, database, deep} {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithNamespace(&gfmodels.Folder{Uid: folder.Uid}))()
+		err := sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Table(models.AlertRule{}).InsertOne(rule)
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: {dashboards.ActionFoldersRead: {dashboards.ScopeFoldersProvider.GetResourceScopeUID(team.Uid)}},
+	}}
+
+	t.Run("should add the nested folders the user can read through a parent folder", func(t *testing.T) {
+		namespaces := map[string]*gfmodels.Folder{}
+		require.NoError(t, store.addNestedNamespaces(context.Background(), 1, signedInUser, namespaces))
+
+		require.Len(t, namespaces, 2)
+		require.Equal(t, "Team/Service", namespaces[service.Uid].Title)
+		require.Equal(t, team.Uid, namespaces[service.Uid].ParentUid)
+		require.Equal(t, "Team/Service/Deep", namespaces[deep.Uid].Title)
+		require.Equal(t, service.Uid, namespaces[deep.Uid].ParentUid)
+	})
+
+	t.Run("should use the path of the nested folders that are already visible", func(t *testing.T) {
+		namespaces := map[string]*gfmodels.Folder{
+			database.Uid: {Id: database.Id, Uid: database.Uid, Title: database.Title},
+		}
+		require.NoError(t, store.addNestedNamespaces(context.Background(), 1, signedInUser, namespaces))
+
+		require.Len(t, namespaces, 3)
+		require.Equal(t, "Other/Database", namespaces[database.Uid].Title)
+	})
+
+	t.Run("should only add the nested folders when nested folders are enabled", func(t *testing.T) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("FindDashboards", mock.Anything, mock.Anything).Return([]dashboards.DashboardSearchProjection{}, nil)
+		store := store
+		store.DashboardService = dashboardService
+
+		namespaces, err := store.GetUserVisibleNamespaces(context.Background(), 1, signedInUser)
+		require.NoError(t, err)
+		require.Empty(t, namespaces)
+
+		isEnabled := sqlStore.Cfg.IsFeatureToggleEnabled
+		sqlStore.Cfg.IsFeatureToggleEnabled = featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders).IsEnabled
+		t.Cleanup(func() { sqlStore.Cfg.IsFeatureToggleEnabled = isEnabled })
+
+		namespaces, err = store.GetUserVisibleNamespaces(context.Background(), 1, signedInUser)
+		require.NoError(t, err)
+		require.Len(t, namespaces, 2)
+	})
+
+	t.Run("should not add nested folders when access control is disabled", func(t *testing.T) {
+		store := DBstore{SQLStore: sqlStore, AccessControl: acimpl.ProvideAccessControl(&setting.Cfg{})}
+		namespaces := map[string]*gfmodels.Folder{}
+		require.NoError(t, store.addNestedNamespaces(context.Background(), 1, signedInUser, namespaces))
+		require.Empty(t, namespaces)
+	})
+}
@@ -0,0 +1,100 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+var (
+	// ErrUnsupportedDatasource is returned when the target data source of a recording rule cannot receive remote writes.
+	ErrUnsupportedDatasource = errors.New("target data source of recording rule is not a Prometheus data source")
+)
+
+// PrometheusWriter writes series to Prometheus data sources using the remote write protocol.
+type PrometheusWriter struct {
+	datasourceService  datasources.DataSourceService
+	httpClientProvider httpclient.Provider
+	timeout            time.Duration
+	path               string
+	log                log.Logger
+}
+
+// NewPrometheusWriter returns a writer that sends remote write requests to the path of the target data sources.
+func NewPrometheusWriter(datasourceService datasources.DataSourceService, httpClientProvider httpclient.Provider, timeout time.Duration, path string, l log.Logger) *PrometheusWriter {
+	return &PrometheusWriter{
+		datasourceService:  datasourceService,
+		httpClientProvider: httpClientProvider,
+		timeout:            timeout,
+		path:               path,
+		log:                l,
+	}
+}
+
+// Write sends the series to the data source with the given UID, with the authentication and
+// headers configured on the data source.
+func (w *PrometheusWriter) Write(ctx context.Context, orgID int64, datasourceUID string, series []prompb.TimeSeries) error {
+	if len(series) == 0 {
+		return nil
+	}
+
+	query := &datasources.GetDataSourceQuery{Uid: datasourceUID, OrgId: orgID}
+	if err := w.datasourceService.GetDataSource(ctx, query); err != nil {
+		return fmt.Errorf("failed to get target data source %s: %w", datasourceUID, err)
+	}
+	ds := query.Result
+	if ds.Type != datasources.DS_PROMETHEUS {
+		return fmt.Errorf("%w: data source %s has type %s", ErrUnsupportedDatasource, datasourceUID, ds.Type)
+	}
+
+	transport, err := w.datasourceService.GetHTTPTransport(ctx, ds, w.httpClientProvider)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP transport for data source %s: %w", datasourceUID, err)
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(ds.Url, "/") + "/" + strings.TrimPrefix(w.path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error constructing remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	client := &http.Client{Transport: transport, Timeout: w.timeout}
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		// The body usually explains why the samples were rejected.
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote write to data source %s failed with status %d: %s", datasourceUID, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	w.log.Debug("Series written to data source", "datasource_uid", datasourceUID, "series", len(series), "elapsed", time.Since(started))
+	return nil
+}
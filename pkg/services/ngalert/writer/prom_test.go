@@ -0,0 +1,72 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+)
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	series := []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "job:errors:rate5m"}},
+		Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}},
+	}}
+
+	var received *prompb.WriteRequest
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/prometheus/api/v1/push", r.URL.Path)
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		received = &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(decoded, received))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("out of order sample"))
+	}))
+	t.Cleanup(server.Close)
+
+	datasourceService := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{Uid: "mimir", OrgId: 1, Type: datasources.DS_PROMETHEUS, Url: server.URL + "/prometheus/"},
+		{Uid: "loki", OrgId: 1, Type: datasources.DS_LOKI, Url: server.URL},
+	}}
+	writer := NewPrometheusWriter(datasourceService, httpclient.NewProvider(), time.Second, "/api/v1/push", log.New("test"))
+
+	t.Run("should send the series to the data source", func(t *testing.T) {
+		require.NoError(t, writer.Write(context.Background(), 1, "mimir", series))
+		require.NotNil(t, received)
+		require.Equal(t, series, received.Timeseries)
+	})
+
+	t.Run("should return the reason of a rejected write", func(t *testing.T) {
+		status = http.StatusBadRequest
+		t.Cleanup(func() { status = http.StatusNoContent })
+		err := writer.Write(context.Background(), 1, "mimir", series)
+		require.ErrorContains(t, err, "status 400: out of order sample")
+	})
+
+	t.Run("should not write to other types of data sources", func(t *testing.T) {
+		err := writer.Write(context.Background(), 1, "loki", series)
+		require.ErrorIs(t, err, ErrUnsupportedDatasource)
+	})
+
+	t.Run("should fail if the data source does not exist", func(t *testing.T) {
+		err := writer.Write(context.Background(), 2, "unknown", series)
+		require.ErrorIs(t, err, datasources.ErrDataSourceNotFound)
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	entitystore "github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -16,13 +17,15 @@ func ProvideService(db db.DB, cfg *setting.Cfg) playlist.Service {
 	if cfg.IsFeatureToggleEnabled("newDBLibrary") {
 		return &Service{
 			store: &sqlxStore{
-				sess: db.GetSqlxSession(),
+				sess:             db.GetSqlxSession(),
+				emitEntityEvents: entitystore.EntityEventsEnabled(cfg),
 			},
 		}
 	}
 	return &Service{
 		store: &sqlStore{
-			db: db,
+			db:               db,
+			emitEntityEvents: entitystore.EntityEventsEnabled(cfg),
 		},
 	}
 }
@@ -8,10 +8,13 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/sqlstore/session"
+	entitystore "github.com/grafana/grafana/pkg/services/store"
 )
 
 type sqlxStore struct {
 	sess *session.SessionDB
+	// emitEntityEvents saves the changes of the playlists as entity events for the search index
+	emitEntityEvents bool
 }
 
 func (s *sqlxStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
@@ -54,7 +57,7 @@ func (s *sqlxStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComm
 				return err
 			}
 		}
-		return nil
+		return s.insertEntityEvent(ctx, tx, p.OrgId, p.UID, entitystore.EntityEventTypeCreate)
 	})
 
 	return &p, err
@@ -102,7 +105,10 @@ func (s *sqlxStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComm
 		}
 		query = `INSERT INTO playlist_item (playlist_id, type, value, title, "order") VALUES (:playlist_id, :type, :value, :title, :order)`
 		_, err = tx.NamedExec(ctx, query, playlistItems)
-		return err
+		if err != nil {
+			return err
+		}
+		return s.insertEntityEvent(ctx, tx, p.OrgId, p.UID, entitystore.EntityEventTypeUpdate)
 	})
 
 	return &dto, err
@@ -145,12 +151,21 @@ func (s *sqlxStore) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComm
 		if _, err := tx.Exec(ctx, "DELETE FROM playlist_item WHERE playlist_id = ?", p.Id); err != nil {
 			return err
 		}
-		return nil
+		return s.insertEntityEvent(ctx, tx, cmd.OrgId, cmd.UID, entitystore.EntityEventTypeDelete)
 	})
 
 	return err
 }
 
+func (s *sqlxStore) insertEntityEvent(ctx context.Context, tx *session.SessionTx, orgID int64, uid string, eventType entitystore.EntityEventType) error {
+	if !s.emitEntityEvents {
+		return nil
+	}
+	event := entitystore.CreateDatabaseEntityEvent(uid, orgID, entitystore.EntityTypePlaylist, eventType)
+	_, err := tx.Exec(ctx, "INSERT INTO entity_event (event_type, entity_id, created) VALUES (?, ?, ?)", event.EventType, event.EntityId, event.Created)
+	return err
+}
+
 func (s *sqlxStore) List(ctx context.Context, query *playlist.GetPlaylistsQuery) (playlist.Playlists, error) {
 	playlists := make(playlist.Playlists, 0)
 	if query.OrgId == 0 {
@@ -11,3 +11,9 @@ func TestIntegrationSQLxPlaylistDataAccess(t *testing.T) {
 		return &sqlxStore{sess: ss.GetSqlxSession()}
 	})
 }
+
+func TestIntegrationSQLxPlaylistEntityEvents(t *testing.T) {
+	testIntegrationPlaylistEntityEvents(t, func(ss *sqlstore.SQLStore) store {
+		return &sqlxStore{sess: ss.GetSqlxSession(), emitEntityEvents: true}
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	entitystore "github.com/grafana/grafana/pkg/services/store"
 	"github.com/stretchr/testify/require"
 )
 
@@ -125,3 +126,33 @@ func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 		}
 	})
 }
+
+func testIntegrationPlaylistEntityEvents(t *testing.T, fn getStore) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ss := sqlstore.InitTestDB(t)
+	playlistStore := fn(ss)
+
+	items := []playlist.PlaylistItemDTO{{Title: "graphite", Value: "graphite", Type: "dashboard_by_tag"}}
+	p, err := playlistStore.Insert(context.Background(), &playlist.CreatePlaylistCommand{Name: "NYC office", Interval: "10m", OrgId: 1, Items: items})
+	require.NoError(t, err)
+	_, err = playlistStore.Update(context.Background(), &playlist.UpdatePlaylistCommand{Name: "NYC office", Interval: "5m", OrgId: 1, UID: p.UID, Items: items})
+	require.NoError(t, err)
+	err = playlistStore.Delete(context.Background(), &playlist.DeletePlaylistCommand{UID: p.UID, OrgId: 1})
+	require.NoError(t, err)
+
+	var events []entitystore.EntityEvent
+	err = ss.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Asc("id").Find(&events)
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	entityID := "database/1/playlist/" + p.UID
+	for i, eventType := range []entitystore.EntityEventType{entitystore.EntityEventTypeCreate, entitystore.EntityEventTypeUpdate, entitystore.EntityEventTypeDelete} {
+		require.Equal(t, eventType, events[i].EventType)
+		require.Equal(t, entityID, events[i].EntityId)
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	entitystore "github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/util"
 )
 
 type sqlStore struct {
 	db db.DB
+	// emitEntityEvents saves the changes of the playlists as entity events for the search index
+	emitEntityEvents bool
 }
 
 func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
@@ -46,8 +49,11 @@ func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComma
 		}
 
 		_, err = sess.Insert(&playlistItems)
+		if err != nil {
+			return err
+		}
 
-		return err
+		return s.insertEntityEvent(sess, p.OrgId, p.UID, entitystore.EntityEventTypeCreate)
 	})
 	return &p, err
 }
@@ -103,7 +109,11 @@ func (s *sqlStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComma
 		}
 
 		_, err = sess.Insert(&playlistItems)
-		return err
+		if err != nil {
+			return err
+		}
+
+		return s.insertEntityEvent(sess, p.OrgId, p.UID, entitystore.EntityEventTypeUpdate)
 	})
 	return &dto, err
 }
@@ -146,11 +156,22 @@ func (s *sqlStore) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComma
 
 		var rawItemSQL = "DELETE FROM playlist_item WHERE playlist_id = ?"
 		_, err = sess.Exec(rawItemSQL, playlist.Id)
+		if err != nil {
+			return err
+		}
 
-		return err
+		return s.insertEntityEvent(sess, cmd.OrgId, cmd.UID, entitystore.EntityEventTypeDelete)
 	})
 }
 
+func (s *sqlStore) insertEntityEvent(sess *sqlstore.DBSession, orgID int64, uid string, eventType entitystore.EntityEventType) error {
+	if !s.emitEntityEvents {
+		return nil
+	}
+	_, err := sess.Insert(entitystore.CreateDatabaseEntityEvent(uid, orgID, entitystore.EntityTypePlaylist, eventType))
+	return err
+}
+
 func (s *sqlStore) List(ctx context.Context, query *playlist.GetPlaylistsQuery) (playlist.Playlists, error) {
 	playlists := make(playlist.Playlists, 0)
 	if query.OrgId == 0 {
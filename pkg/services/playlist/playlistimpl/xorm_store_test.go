@@ -11,3 +11,9 @@ func TestIntegrationXormPlaylistDataAccess(t *testing.T) {
 		return &sqlStore{db: ss}
 	})
 }
+
+func TestIntegrationXormPlaylistEntityEvents(t *testing.T) {
+	testIntegrationPlaylistEntityEvents(t, func(ss *sqlstore.SQLStore) store {
+		return &sqlStore{db: ss, emitEntityEvents: true}
+	})
+}
@@ -17,13 +17,15 @@ type ContactPointProvisioner interface {
 type defaultContactPointProvisioner struct {
 	logger              log.Logger
 	contactPointService provisioning.ContactPointService
+	provenance          models.Provenance
 }
 
 func NewContactPointProvisoner(logger log.Logger,
-	contactPointService provisioning.ContactPointService) ContactPointProvisioner {
+	contactPointService provisioning.ContactPointService, provenance models.Provenance) ContactPointProvisioner {
 	return &defaultContactPointProvisioner{
 		logger:              logger,
 		contactPointService: contactPointService,
+		provenance:          provenance,
 	}
 }
 
@@ -45,10 +47,11 @@ func (c *defaultContactPointProvisioner) Provision(ctx context.Context,
 			}
 		outer:
 			for _, contactPoint := range contactPointsConfig.ContactPoints {
+				contactPoint.Provenance = string(c.provenance)
 				for _, fetchedCP := range cpsCache[contactPointsConfig.OrgID] {
 					if fetchedCP.UID == contactPoint.UID {
 						err := c.contactPointService.UpdateContactPoint(ctx,
-							contactPointsConfig.OrgID, contactPoint, models.ProvenanceFile)
+							contactPointsConfig.OrgID, contactPoint, c.provenance)
 						if err != nil {
 							return err
 						}
@@ -56,7 +59,7 @@ func (c *defaultContactPointProvisioner) Provision(ctx context.Context,
 					}
 				}
 				_, err := c.contactPointService.CreateContactPoint(ctx, contactPointsConfig.OrgID,
-					contactPoint, models.ProvenanceFile)
+					contactPoint, c.provenance)
 				if err != nil {
 					return err
 				}
@@ -0,0 +1,137 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ProvenanceStore returns the provenance of the alerting resources of an organization, by resource ID.
+type ProvenanceStore interface {
+	GetProvenances(ctx context.Context, org int64, resourceType string) (map[string]models.Provenance, error)
+}
+
+// DeleteMissing removes the alerting resources of the organizations that were provisioned with the provenance of cfg
+// but are not in the files of cfg.Path anymore, the same way as the dashboards removed from the files are deleted.
+// The notification policies of an organization are reset when no file has them anymore.
+func DeleteMissing(ctx context.Context, cfg ProvisionerConfig, store ProvenanceStore, orgIDs []int64) error {
+	logger := log.New("provisioning.alerting")
+	cfgReader := newRulesConfigReader(logger)
+	files, err := cfgReader.readConfig(ctx, cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	type resourceKey struct {
+		orgID int64
+		id    string
+	}
+	rules := map[resourceKey]bool{}
+	contactPoints := map[resourceKey]bool{}
+	muteTimes := map[resourceKey]bool{}
+	templates := map[resourceKey]bool{}
+	policies := map[int64]bool{}
+	for _, file := range files {
+		for _, group := range file.Groups {
+			for _, rule := range group.Rules {
+				rules[resourceKey{group.OrgID, rule.UID}] = true
+			}
+		}
+		for _, cp := range file.ContactPoints {
+			for _, receiver := range cp.ContactPoints {
+				contactPoints[resourceKey{cp.OrgID, receiver.UID}] = true
+			}
+		}
+		for _, mt := range file.MuteTimes {
+			muteTimes[resourceKey{mt.OrgID, mt.MuteTime.Name}] = true
+		}
+		for _, template := range file.Templates {
+			templates[resourceKey{template.OrgID, template.Data.Name}] = true
+		}
+		for _, policy := range file.Policies {
+			policies[policy.OrgID] = true
+		}
+	}
+
+	for _, orgID := range orgIDs {
+		// getMissing returns the resources of a type that were provisioned with the provenance of cfg but are not in the files.
+		getMissing := func(resourceType string, provisioned map[resourceKey]bool) ([]string, error) {
+			provenances, err := store.GetProvenances(ctx, orgID, resourceType)
+			if err != nil {
+				return nil, err
+			}
+			var missing []string
+			for id, provenance := range provenances {
+				if provenance == cfg.Provenance && !provisioned[resourceKey{orgID, id}] {
+					missing = append(missing, id)
+				}
+			}
+			return missing, nil
+		}
+
+		missingRules, err := getMissing((&models.AlertRule{}).ResourceType(), rules)
+		if err != nil {
+			return fmt.Errorf("alert rules: %w", err)
+		}
+		for _, uid := range missingRules {
+			logger.Info("deleting alert rule removed from the files", "org", orgID, "uid", uid)
+			if err := cfg.RuleService.DeleteAlertRule(ctx, orgID, uid, cfg.Provenance); err != nil {
+				return fmt.Errorf("alert rules: %w", err)
+			}
+		}
+
+		if !policies[orgID] {
+			provenances, err := store.GetProvenances(ctx, orgID, (&definitions.Route{}).ResourceType())
+			if err != nil {
+				return fmt.Errorf("notification policies: %w", err)
+			}
+			for _, provenance := range provenances {
+				if provenance != cfg.Provenance {
+					continue
+				}
+				logger.Info("resetting notification policies removed from the files", "org", orgID)
+				if _, err := cfg.NotificiationPolicyService.ResetPolicyTree(ctx, orgID); err != nil {
+					return fmt.Errorf("notification policies: %w", err)
+				}
+				break
+			}
+		}
+
+		missingContactPoints, err := getMissing((&definitions.EmbeddedContactPoint{}).ResourceType(), contactPoints)
+		if err != nil {
+			return fmt.Errorf("contact points: %w", err)
+		}
+		for _, uid := range missingContactPoints {
+			logger.Info("deleting contact point removed from the files", "org", orgID, "uid", uid)
+			if err := cfg.ContactPointService.DeleteContactPoint(ctx, orgID, uid); err != nil {
+				return fmt.Errorf("contact points: %w", err)
+			}
+		}
+
+		missingMuteTimes, err := getMissing((&definitions.MuteTimeInterval{}).ResourceType(), muteTimes)
+		if err != nil {
+			return fmt.Errorf("mute times: %w", err)
+		}
+		for _, name := range missingMuteTimes {
+			logger.Info("deleting mute time removed from the files", "org", orgID, "name", name)
+			if err := cfg.MuteTimingService.DeleteMuteTiming(ctx, name, orgID); err != nil {
+				return fmt.Errorf("mute times: %w", err)
+			}
+		}
+
+		missingTemplates, err := getMissing((&definitions.MessageTemplate{}).ResourceType(), templates)
+		if err != nil {
+			return fmt.Errorf("text templates: %w", err)
+		}
+		for _, name := range missingTemplates {
+			logger.Info("deleting text template removed from the files", "org", orgID, "name", name)
+			if err := cfg.TemplateService.DeleteTemplate(ctx, orgID, name); err != nil {
+				return fmt.Errorf("text templates: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package alerting
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationDeleteMissing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	st := store.DBstore{
+		SQLStore: sqlStore,
+		Cfg:      setting.UnifiedAlertingSettings{BaseInterval: 10 * time.Second},
+		Logger:   log.New("test"),
+	}
+	ruleService := provisioning.NewAlertRuleService(st, st, quotatest.NewQuotaServiceFake(), sqlStore, 60, 10, log.New("test"))
+
+	createRule := func(t *testing.T, uid string, provenance models.Provenance) {
+		t.Helper()
+		rule := models.AlertRuleGen(models.WithOrgID(1))()
+		rule.UID = uid
+		_, err := ruleService.CreateAlertRule(context.Background(), *rule, provenance, 0)
+		require.NoError(t, err)
+	}
+	createRule(t, "my_first_rule", models.ProvenanceGit)
+	createRule(t, "removed", models.ProvenanceGit)
+	createRule(t, "from_file", models.ProvenanceFile)
+
+	dir := t.TempDir()
+	rules, err := os.ReadFile("./testdata/alert_rules/correct-properties/rules.yml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rules.yml"), rules, 0600))
+
+	cfg := ProvisionerConfig{Path: dir, RuleService: *ruleService, Provenance: models.ProvenanceGit}
+	require.NoError(t, DeleteMissing(context.Background(), cfg, st, []int64{1}))
+
+	provenances, err := st.GetProvenances(context.Background(), 1, (&models.AlertRule{}).ResourceType())
+	require.NoError(t, err)
+	require.Equal(t, map[string]models.Provenance{
+		"my_first_rule": models.ProvenanceGit,
+		"from_file":     models.ProvenanceFile,
+	}, provenances)
+	_, _, err = ruleService.GetAlertRule(context.Background(), 1, "removed")
+	require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+}
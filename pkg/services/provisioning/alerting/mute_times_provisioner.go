@@ -17,13 +17,15 @@ type MuteTimesProvisioner interface {
 type defaultMuteTimesProvisioner struct {
 	logger            log.Logger
 	muteTimingService provisioning.MuteTimingService
+	provenance        models.Provenance
 }
 
 func NewMuteTimesProvisioner(logger log.Logger,
-	muteTimingService provisioning.MuteTimingService) MuteTimesProvisioner {
+	muteTimingService provisioning.MuteTimingService, provenance models.Provenance) MuteTimesProvisioner {
 	return &defaultMuteTimesProvisioner{
 		logger:            logger,
 		muteTimingService: muteTimingService,
+		provenance:        provenance,
 	}
 }
 
@@ -42,7 +44,7 @@ func (c *defaultMuteTimesProvisioner) Provision(ctx context.Context,
 					cache[muteTiming.OrgID][interval.Name] = interval
 				}
 			}
-			muteTiming.MuteTime.Provenance = models.ProvenanceFile
+			muteTiming.MuteTime.Provenance = c.provenance
 			if _, exists := cache[muteTiming.OrgID][muteTiming.MuteTime.Name]; exists {
 				_, err := c.muteTimingService.UpdateMuteTiming(ctx, muteTiming.MuteTime, muteTiming.OrgID)
 				if err != nil {
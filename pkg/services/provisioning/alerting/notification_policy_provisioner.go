@@ -17,13 +17,15 @@ type NotificationPolicyProvisioner interface {
 type defaultNotificationPolicyProvisioner struct {
 	logger                    log.Logger
 	notificationPolicyService provisioning.NotificationPolicyService
+	provenance                models.Provenance
 }
 
 func NewNotificationPolicyProvisoner(logger log.Logger,
-	notificationPolicyService provisioning.NotificationPolicyService) NotificationPolicyProvisioner {
+	notificationPolicyService provisioning.NotificationPolicyService, provenance models.Provenance) NotificationPolicyProvisioner {
 	return &defaultNotificationPolicyProvisioner{
 		logger:                    logger,
 		notificationPolicyService: notificationPolicyService,
+		provenance:                provenance,
 	}
 }
 
@@ -32,7 +34,7 @@ func (c *defaultNotificationPolicyProvisioner) Provision(ctx context.Context,
 	for _, file := range files {
 		for _, np := range file.Policies {
 			err := c.notificationPolicyService.UpdatePolicyTree(ctx, np.OrgID,
-				np.Policy, models.ProvenanceFile)
+				np.Policy, c.provenance)
 			if err != nil {
 				return fmt.Errorf("%s: %w", file.Filename, err)
 			}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 )
 
@@ -18,6 +19,8 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	// Provenance is stored with the provisioned resources, ProvenanceFile when empty.
+	Provenance models.Provenance
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -27,33 +30,38 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return err
 	}
-	logger.Info("starting to provision alerting")
+	provenance := cfg.Provenance
+	if provenance == models.ProvenanceNone {
+		provenance = models.ProvenanceFile
+	}
+	logger.Info("starting to provision alerting", "provenance", provenance)
 	logger.Debug("read all alerting files", "file_count", len(files))
 	ruleProvisioner := NewAlertRuleProvisioner(
 		logger,
 		cfg.DashboardService,
 		cfg.DashboardProvService,
-		cfg.RuleService)
+		cfg.RuleService,
+		provenance)
 	err = ruleProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("alert rules: %w", err)
 	}
-	cpProvisioner := NewContactPointProvisoner(logger, cfg.ContactPointService)
+	cpProvisioner := NewContactPointProvisoner(logger, cfg.ContactPointService, provenance)
 	err = cpProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
-	mtProvisioner := NewMuteTimesProvisioner(logger, cfg.MuteTimingService)
+	mtProvisioner := NewMuteTimesProvisioner(logger, cfg.MuteTimingService, provenance)
 	err = mtProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("mute times: %w", err)
 	}
-	ttProvsioner := NewTextTemplateProvisioner(logger, cfg.TemplateService)
+	ttProvsioner := NewTextTemplateProvisioner(logger, cfg.TemplateService, provenance)
 	err = ttProvsioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
-	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService)
+	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService, provenance)
 	err = npProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("notification policies: %w", err)
@@ -21,12 +21,14 @@ func NewAlertRuleProvisioner(
 	logger log.Logger,
 	dashboardService dashboards.DashboardService,
 	dashboardProvService dashboards.DashboardProvisioningService,
-	ruleService provisioning.AlertRuleService) AlertRuleProvisioner {
+	ruleService provisioning.AlertRuleService,
+	provenance alert_models.Provenance) AlertRuleProvisioner {
 	return &defaultAlertRuleProvisioner{
 		logger:               logger,
 		dashboardService:     dashboardService,
 		dashboardProvService: dashboardProvService,
 		ruleService:          ruleService,
+		provenance:           provenance,
 	}
 }
 
@@ -35,6 +37,7 @@ type defaultAlertRuleProvisioner struct {
 	dashboardService     dashboards.DashboardService
 	dashboardProvService dashboards.DashboardProvisioningService
 	ruleService          provisioning.AlertRuleService
+	provenance           alert_models.Provenance
 }
 
 func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
@@ -65,7 +68,7 @@ func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 		}
 		for _, deleteRule := range file.DeleteRules {
 			err := prov.ruleService.DeleteAlertRule(ctx, deleteRule.OrgID,
-				deleteRule.UID, alert_models.ProvenanceFile)
+				deleteRule.UID, prov.provenance)
 			if err != nil {
 				return err
 			}
@@ -88,10 +91,10 @@ func (prov *defaultAlertRuleProvisioner) provisionRule(
 		prov.logger.Debug("creating rule", "uid", rule.UID, "org", rule.OrgID)
 		// 0 is passed as userID as then the quota logic will only check for
 		// the organization quota, as we don't have any user scope here.
-		_, err = prov.ruleService.CreateAlertRule(ctx, rule, alert_models.ProvenanceFile, 0)
+		_, err = prov.ruleService.CreateAlertRule(ctx, rule, prov.provenance, 0)
 	} else {
 		prov.logger.Debug("updating rule", "uid", rule.UID, "org", rule.OrgID)
-		_, err = prov.ruleService.UpdateAlertRule(ctx, rule, alert_models.ProvenanceFile)
+		_, err = prov.ruleService.UpdateAlertRule(ctx, rule, prov.provenance)
 	}
 	return err
 }
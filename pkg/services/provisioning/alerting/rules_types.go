@@ -78,6 +78,7 @@ type AlertRuleV1 struct {
 	For          values.StringValue    `json:"for" yaml:"for"`
 	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
 	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
+	Record       *RecordV1             `json:"record" yaml:"record"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -119,6 +120,12 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.NoDataState = noDataState
 	alertRule.Condition = rule.Condition.Value()
+	if rule.Record != nil {
+		alertRule.Record = rule.Record.mapToModel()
+		if alertRule.Condition == "" {
+			alertRule.Condition = alertRule.Record.From
+		}
+	}
 	if alertRule.Condition == "" {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no condition set", alertRule.Title)
 	}
@@ -137,6 +144,20 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	return alertRule, nil
 }
 
+type RecordV1 struct {
+	Metric              values.StringValue `json:"metric" yaml:"metric"`
+	From                values.StringValue `json:"from" yaml:"from"`
+	TargetDatasourceUID values.StringValue `json:"targetDatasourceUid" yaml:"targetDatasourceUid"`
+}
+
+func (record *RecordV1) mapToModel() *models.Record {
+	return &models.Record{
+		Metric:              record.Metric.Value(),
+		From:                record.From.Value(),
+		TargetDatasourceUID: record.TargetDatasourceUID.Value(),
+	}
+}
+
 type QueryV1 struct {
 	RefID             values.StringValue       `json:"refId" yaml:"refId"`
 	QueryType         values.StringValue       `json:"queryType" yaml:"queryType"`
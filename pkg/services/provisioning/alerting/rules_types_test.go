@@ -165,6 +165,18 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ruleMapped.NoDataState, models.NoData)
 	})
+	t.Run("a recording rule should map its record and default its condition", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Condition = values.StringValue{}
+		record := RecordV1{}
+		err := yaml.Unmarshal([]byte("metric: job:errors:rate5m\nfrom: A\ntargetDatasourceUid: prometheus"), &record)
+		require.NoError(t, err)
+		rule.Record = &record
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: "job:errors:rate5m", From: "A", TargetDatasourceUID: "prometheus"}, ruleMapped.Record)
+		require.Equal(t, "A", ruleMapped.Condition)
+	})
 }
 
 func validRuleGroupV1(t *testing.T) AlertRuleGroupV1 {
@@ -16,13 +16,15 @@ type TextTemplateProvisioner interface {
 type defaultTextTemplateProvisioner struct {
 	logger          log.Logger
 	templateService provisioning.TemplateService
+	provenance      models.Provenance
 }
 
 func NewTextTemplateProvisioner(logger log.Logger,
-	templateService provisioning.TemplateService) TextTemplateProvisioner {
+	templateService provisioning.TemplateService, provenance models.Provenance) TextTemplateProvisioner {
 	return &defaultTextTemplateProvisioner{
 		logger:          logger,
 		templateService: templateService,
+		provenance:      provenance,
 	}
 }
 
@@ -30,7 +32,7 @@ func (c *defaultTextTemplateProvisioner) Provision(ctx context.Context,
 	files []*AlertingFile) error {
 	for _, file := range files {
 		for _, template := range file.Templates {
-			template.Data.Provenance = models.ProvenanceFile
+			template.Data.Provenance = c.provenance
 			_, err := c.templateService.SetTemplate(ctx, template.OrgID, template.Data)
 			if err != nil {
 				return err
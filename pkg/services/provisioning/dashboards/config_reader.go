@@ -14,9 +14,10 @@ import (
 )
 
 type configReader struct {
-	path     string
-	log      log.Logger
-	orgStore utils.OrgStore
+	path      string
+	providers []ProviderConfig
+	log       log.Logger
+	orgStore  utils.OrgStore
 }
 
 func (cr *configReader) parseConfigs(file fs.DirEntry) ([]*config, error) {
@@ -70,7 +71,6 @@ func (cr *configReader) readConfig(ctx context.Context) ([]*config, error) {
 	files, err := os.ReadDir(cr.path)
 	if err != nil {
 		cr.log.Error("can't read dashboard provisioning files from directory", "path", cr.path, "error", err)
-		return dashboards, nil
 	}
 
 	for _, file := range files {
@@ -88,6 +88,10 @@ func (cr *configReader) readConfig(ctx context.Context) ([]*config, error) {
 		}
 	}
 
+	for _, provider := range cr.providers {
+		dashboards = append(dashboards, provider.toConfig())
+	}
+
 	uidUsage := map[string]uint8{}
 	for _, dashboard := range dashboards {
 		if dashboard.OrgID == 0 {
@@ -78,6 +78,22 @@ func TestDashboardsAsConfig(t *testing.T) {
 			require.Equal(t, 0, len(cfg))
 		})
 
+		t.Run("Should add the providers to the config files", func(t *testing.T) {
+			providers := []ProviderConfig{{Name: "git", Path: "/var/lib/grafana/git_sync/dashboards", AllowUIUpdates: true}}
+			cfgProvider := configReader{path: "/invalid-directory", providers: providers, log: logger, orgStore: store}
+			cfg, err := cfgProvider.readConfig(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, cfg, 1)
+			require.Equal(t, "git", cfg[0].Name)
+			require.Equal(t, "file", cfg[0].Type)
+			require.Equal(t, int64(1), cfg[0].OrgID)
+			require.Equal(t, int64(10), cfg[0].UpdateIntervalSeconds)
+			require.True(t, cfg[0].AllowUIUpdates)
+			require.Equal(t, "/var/lib/grafana/git_sync/dashboards", cfg[0].Options["path"])
+			require.Equal(t, true, cfg[0].Options["foldersFromFilesStructure"])
+		})
+
 		t.Run("Should skip broken config files", func(t *testing.T) {
 			cfgProvider := configReader{path: brokenConfigs, log: logger, orgStore: store}
 			cfg, err := cfgProvider.readConfig(context.Background())
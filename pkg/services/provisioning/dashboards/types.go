@@ -23,6 +23,28 @@ type config struct {
 	AllowUIUpdates        bool
 }
 
+// ProviderConfig configures a dashboard provider which is not defined in the provisioning config files, like the
+// repository synced by the git sync. The dashboards are read from the path and the folders from its directories.
+type ProviderConfig struct {
+	Name           string
+	OrgID          int64
+	Path           string
+	AllowUIUpdates bool
+}
+
+func (p ProviderConfig) toConfig() *config {
+	return &config{
+		Name:  p.Name,
+		Type:  "file",
+		OrgID: p.OrgID,
+		Options: map[string]interface{}{
+			"path":                      p.Path,
+			"foldersFromFilesStructure": true,
+		},
+		AllowUIUpdates: p.AllowUIUpdates,
+	}
+}
+
 type configV0 struct {
 	Name                  string                 `json:"name" yaml:"name"`
 	Type                  string                 `json:"type" yaml:"type"`
@@ -29,6 +29,8 @@ var (
 	invalidAccess                   = "testdata/invalid-access"
 
 	oneDatasourceWithTwoCorrelations = "testdata/one-datasource-two-correlations"
+	correlationConfig                = "testdata/correlation-config"
+	invalidCorrelationConfig         = "testdata/invalid-correlation-config"
 )
 
 func TestDatasourceAsConfig(t *testing.T) {
@@ -268,6 +270,38 @@ func TestDatasourceAsConfig(t *testing.T) {
 			require.Equal(t, 0, len(correlationsStore.deletedByTargetUID))
 		})
 
+		t.Run("Creates a correlation with a config", func(t *testing.T) {
+			store := &spyStore{}
+			orgStore := &mockOrgStore{}
+			correlationsStore := &mockCorrelationsStore{}
+			dc := newDatasourceProvisioner(logger, store, correlationsStore, orgStore)
+			err := dc.applyChanges(context.Background(), correlationConfig)
+			if err != nil {
+				t.Fatalf("applyChanges return an error %v", err)
+			}
+
+			require.Equal(t, 1, len(correlationsStore.created))
+			require.Equal(t, correlations.CorrelationConfig{
+				Type:   correlations.ConfigTypeQuery,
+				Field:  "message",
+				Target: map[string]interface{}{"query": "${traceId}"},
+				Transformations: []correlations.Transformation{
+					{Type: correlations.TransformationTypeRegex, Expression: `traceId=(\w+)`, MapValue: "traceId"},
+					{Type: correlations.TransformationTypeLogfmt},
+				},
+			}, correlationsStore.created[0].Config)
+		})
+
+		t.Run("Returns an error when the config of a correlation is invalid", func(t *testing.T) {
+			store := &spyStore{}
+			orgStore := &mockOrgStore{}
+			correlationsStore := &mockCorrelationsStore{}
+			dc := newDatasourceProvisioner(logger, store, correlationsStore, orgStore)
+			err := dc.applyChanges(context.Background(), invalidCorrelationConfig)
+			require.ErrorIs(t, err, correlations.ErrInvalidCorrelationConfig)
+			require.Equal(t, 0, len(correlationsStore.created))
+		})
+
 		t.Run("Deleting datasource deletes existing correlations", func(t *testing.T) {
 			store := &spyStore{items: []*datasources.DataSource{{Name: "old-data-source", OrgId: 1, Id: 1, Uid: "some-uid"}}}
 			orgStore := &mockOrgStore{}
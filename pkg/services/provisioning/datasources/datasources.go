@@ -2,6 +2,7 @@ package datasources
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -94,6 +95,7 @@ func (dc *DatasourceProvisioner) apply(ctx context.Context, cfg *configs) error
 			if len(ds.Correlations) > 0 {
 				if err := dc.correlationsStore.DeleteCorrelationsBySourceUID(ctx, correlations.DeleteCorrelationsBySourceUIDCommand{
 					SourceUID: cmd.Result.Uid,
+					OrgId:     updateCmd.OrgId,
 				}); err != nil {
 					return err
 				}
@@ -140,11 +142,27 @@ func makeCreateCorrelationCommand(correlation map[string]interface{}, SourceUid
 		return correlations.CreateCorrelationCommand{}, fmt.Errorf("correlation missing targetUID")
 	}
 
+	var config correlations.CorrelationConfig
+	if configValue, ok := correlation["config"]; ok {
+		// the config is read from YAML, and has the same fields as the config of the API
+		configJSON, err := json.Marshal(configValue)
+		if err != nil {
+			return correlations.CreateCorrelationCommand{}, fmt.Errorf("correlation config is invalid: %w", err)
+		}
+		if err := json.Unmarshal(configJSON, &config); err != nil {
+			return correlations.CreateCorrelationCommand{}, fmt.Errorf("correlation config is invalid: %w", err)
+		}
+		if err := config.Validate(); err != nil {
+			return correlations.CreateCorrelationCommand{}, err
+		}
+	}
+
 	return correlations.CreateCorrelationCommand{
 		SourceUID:         SourceUid,
 		TargetUID:         targetUID,
 		Label:             correlation["label"].(string),
 		Description:       correlation["description"].(string),
+		Config:            config,
 		OrgId:             OrgId,
 		SkipReadOnlyCheck: true,
 	}, nil
@@ -165,12 +183,14 @@ func (dc *DatasourceProvisioner) deleteDatasources(ctx context.Context, dsToDele
 		if getDsQuery.Result != nil {
 			if err := dc.correlationsStore.DeleteCorrelationsBySourceUID(ctx, correlations.DeleteCorrelationsBySourceUIDCommand{
 				SourceUID: getDsQuery.Result.Uid,
+				OrgId:     ds.OrgID,
 			}); err != nil {
 				return err
 			}
 
 			if err := dc.correlationsStore.DeleteCorrelationsByTargetUID(ctx, correlations.DeleteCorrelationsByTargetUIDCommand{
 				TargetUID: getDsQuery.Result.Uid,
+				OrgId:     ds.OrgID,
 			}); err != nil {
 				return err
 			}
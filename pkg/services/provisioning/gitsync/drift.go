@@ -0,0 +1,144 @@
+package gitsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+const driftKindDashboard = "dashboard"
+
+// DriftItem is a synced resource which differs from its file in the repository.
+type DriftItem struct {
+	Kind      string    `json:"kind"`
+	UID       string    `json:"uid"`
+	Title     string    `json:"title"`
+	Path      string    `json:"path"` // relative to the repository root
+	Version   int       `json:"version"`
+	Updated   time.Time `json:"updated"`
+	UpdatedBy int64     `json:"updatedBy"`
+
+	body []byte // the version saved in Grafana
+}
+
+// Drift returns the synced dashboards edited in Grafana since they were provisioned. The alerting resources
+// have no drift, they can't be edited outside of the repository.
+func (s *Syncer) Drift(ctx context.Context) ([]DriftItem, error) {
+	drift := []DriftItem{}
+
+	provisioned, err := s.dashboardProvisioningService.GetProvisionedDashboardData(ctx, ProviderName)
+	if err != nil {
+		return nil, err
+	}
+	if len(provisioned) == 0 {
+		return drift, nil
+	}
+
+	root, err := resolvePath(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		// nothing checked out yet
+		return drift, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range provisioned {
+		file, err := os.ReadFile(p.ExternalId)
+		if errors.Is(err, fs.ErrNotExist) {
+			// removed from the repository, the dashboard is deleted by the next provisioning
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		query := &models.GetDashboardQuery{Id: p.DashboardId, OrgId: s.settings.OrgID}
+		if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+			if errors.Is(err, dashboardservice.ErrDashboardNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		dash := query.Result
+
+		changed, body, err := dashboardChanged(file, dash.Data)
+		if err != nil {
+			s.log.Warn("Failed to compare dashboard with the repository", "uid", dash.Uid, "path", p.ExternalId, "error", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		path, err := filepath.Rel(root, p.ExternalId)
+		if err != nil {
+			return nil, err
+		}
+		drift = append(drift, DriftItem{
+			Kind:      driftKindDashboard,
+			UID:       dash.Uid,
+			Title:     dash.Title,
+			Path:      filepath.ToSlash(path),
+			Version:   dash.Version,
+			Updated:   dash.Updated,
+			UpdatedBy: dash.UpdatedBy,
+			body:      body,
+		})
+	}
+
+	return drift, nil
+}
+
+// resolvePath resolves the path like the dashboard file reader does for the provisioned file paths.
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// dashboardChanged compares the file of a dashboard with the version saved in Grafana, ignoring the
+// properties set when saving it. The returned body is the saved version, formatted for the repository.
+func dashboardChanged(file []byte, saved *simplejson.Json) (bool, []byte, error) {
+	var fromFile map[string]interface{}
+	if err := json.Unmarshal(file, &fromFile); err != nil {
+		return false, nil, err
+	}
+
+	savedJSON, err := saved.MarshalJSON()
+	if err != nil {
+		return false, nil, err
+	}
+	var fromDB map[string]interface{}
+	if err := json.Unmarshal(savedJSON, &fromDB); err != nil {
+		return false, nil, err
+	}
+
+	for _, key := range []string{"id", "version"} {
+		delete(fromFile, key)
+		delete(fromDB, key)
+	}
+
+	body, err := json.MarshalIndent(fromDB, "", "  ")
+	if err != nil {
+		return false, nil, err
+	}
+	body = append(body, '\n')
+
+	// the uid is generated when the file has none
+	if _, ok := fromFile["uid"]; !ok {
+		delete(fromDB, "uid")
+	}
+	return !reflect.DeepEqual(fromFile, fromDB), body, nil
+}
@@ -0,0 +1,66 @@
+package gitsync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestDashboardChanged(t *testing.T) {
+	saved := func(t *testing.T, data string) *simplejson.Json {
+		json, err := simplejson.NewJson([]byte(data))
+		require.NoError(t, err)
+		return json
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		saved   string
+		changed bool
+	}{
+		{
+			name:  "ignores the saved properties",
+			file:  `{"title":"CPU","panels":[{"id":1,"gridPos":{"h":8}}]}`,
+			saved: `{"id":12,"uid":"generated","version":3,"title":"CPU","panels":[{"id":1,"gridPos":{"h":8}}]}`,
+		},
+		{
+			name:  "ignores the property order and the formatting",
+			file:  "{\n  \"uid\": \"cpu\",\n  \"title\": \"CPU\",\n  \"refresh\": \"5s\"\n}",
+			saved: `{"refresh":"5s","title":"CPU","uid":"cpu","version":1}`,
+		},
+		{
+			name:    "detects a changed property",
+			file:    `{"uid":"cpu","title":"CPU","panels":[{"id":1,"gridPos":{"h":8}}]}`,
+			saved:   `{"uid":"cpu","title":"CPU","panels":[{"id":1,"gridPos":{"h":10}}]}`,
+			changed: true,
+		},
+		{
+			name:    "detects a changed uid",
+			file:    `{"uid":"cpu","title":"CPU"}`,
+			saved:   `{"uid":"cpu-2","title":"CPU"}`,
+			changed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, _, err := dashboardChanged([]byte(tt.file), saved(t, tt.saved))
+			require.NoError(t, err)
+			require.Equal(t, tt.changed, changed)
+		})
+	}
+
+	t.Run("returns the saved version without the saved properties", func(t *testing.T) {
+		_, body, err := dashboardChanged([]byte(`{"title":"CPU"}`), saved(t, `{"id":12,"uid":"cpu","version":3,"title":"CPU v2"}`))
+		require.NoError(t, err)
+		require.Equal(t, "{\n  \"title\": \"CPU v2\",\n  \"uid\": \"cpu\"\n}\n", string(body))
+	})
+
+	t.Run("fails with an invalid file", func(t *testing.T) {
+		_, _, err := dashboardChanged([]byte(`{"title":`), saved(t, `{"title":"CPU"}`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,239 @@
+package gitsync
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ProviderName is the name of the dashboard provider of the synced dashboards.
+const ProviderName = "git"
+
+var ErrDisabled = errors.New("git sync is disabled")
+
+// syncLockTimeout is the maximum time a sync holds the server lock without renewing it.
+const syncLockTimeout = time.Minute
+
+// ServerLock runs a function while holding a lock shared by the Grafana instances.
+type ServerLock interface {
+	LockExecuteAndRelease(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+// ApplyFunc provisions the dashboards and the alerting resources of the local checkout.
+type ApplyFunc func(ctx context.Context) error
+
+// Status is the state of the last sync.
+type Status struct {
+	Remote   string    `json:"remote"`
+	Branch   string    `json:"branch"`
+	Commit   string    `json:"commit,omitempty"`
+	LastSync time.Time `json:"lastSync"`
+	Error    string    `json:"error,omitempty"`
+	// Pushed is the number of dashboards edited in Grafana and committed to the branch by the last sync.
+	Pushed int `json:"pushed"`
+	// Conflicts are the dashboards edited both in Grafana and in the repository, the repository version is kept.
+	Conflicts []DriftItem `json:"conflicts"`
+	// Drift are the dashboards which differ from the repository after the sync.
+	Drift []DriftItem `json:"drift"`
+}
+
+// Syncer keeps a local checkout of a branch up to date and provisions it. The dashboards are read from the
+// `dashboards` directory, one folder per directory, and the alerting resources from the `alerting` directory
+// using the format of the alerting provisioning files.
+type Syncer struct {
+	settings                     setting.GitSyncSettings
+	dir                          string // local checkout
+	log                          log.Logger
+	dashboardProvisioningService dashboardservice.DashboardProvisioningService
+	dashboardService             dashboardservice.DashboardService
+	apply                        ApplyFunc
+	serverLock                   ServerLock
+	trigger                      chan struct{}
+
+	mu       sync.Mutex // one sync at a time
+	statusMu sync.RWMutex
+	status   Status
+}
+
+func New(cfg *setting.Cfg, dashboardProvisioningService dashboardservice.DashboardProvisioningService,
+	dashboardService dashboardservice.DashboardService, serverLock ServerLock, apply ApplyFunc) *Syncer {
+	return &Syncer{
+		settings:                     cfg.GitSync,
+		dir:                          filepath.Join(cfg.DataPath, "git_sync"),
+		log:                          log.New("provisioning.gitsync"),
+		dashboardProvisioningService: dashboardProvisioningService,
+		dashboardService:             dashboardService,
+		apply:                        apply,
+		serverLock:                   serverLock,
+		trigger:                      make(chan struct{}, 1),
+		status: Status{
+			Remote: cfg.GitSync.Remote,
+			Branch: cfg.GitSync.Branch,
+		},
+	}
+}
+
+// DashboardProvider returns the provider of the dashboards of the checkout. The dashboards can be saved from the UI
+// when the changes are pushed.
+func (s *Syncer) DashboardProvider() dashboards.ProviderConfig {
+	return dashboards.ProviderConfig{
+		Name:           ProviderName,
+		OrgID:          s.settings.OrgID,
+		Path:           s.DashboardsPath(),
+		AllowUIUpdates: s.settings.PushChanges,
+	}
+}
+
+func (s *Syncer) DashboardsPath() string {
+	return filepath.Join(s.dir, s.settings.Path, "dashboards")
+}
+
+func (s *Syncer) AlertingPath() string {
+	return filepath.Join(s.dir, s.settings.Path, "alerting")
+}
+
+// Run syncs the branch at startup, on every pull interval and when triggered by the webhook.
+func (s *Syncer) Run(ctx context.Context) error {
+	s.syncAndLog(ctx)
+
+	var tick <-chan time.Time
+	if s.settings.PullInterval > 0 {
+		ticker := time.NewTicker(s.settings.PullInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-tick:
+			s.syncAndLog(ctx)
+		case <-s.trigger:
+			s.syncAndLog(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Syncer) syncAndLog(ctx context.Context) {
+	if err := s.Sync(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		s.log.Error("Failed to sync git repository", "remote", s.settings.Remote, "branch", s.settings.Branch, "error", err)
+	}
+}
+
+// Trigger requests a sync without waiting for it.
+func (s *Syncer) Trigger() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+		// a sync is already pending
+	}
+}
+
+// Sync pulls the branch and provisions it. The dashboards edited in Grafana are committed to the branch
+// first when the changes are pushed. Only one Grafana instance pushes and provisions the branch at a time, the
+// other instances only pull it to keep their checkout up to date.
+func (s *Syncer) Sync(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Remote:    s.settings.Remote,
+		Branch:    s.settings.Branch,
+		LastSync:  time.Now(),
+		Conflicts: []DriftItem{},
+		Drift:     []DriftItem{},
+	}
+	var err error
+	if lockErr := s.serverLock.LockExecuteAndRelease(ctx, "git sync", syncLockTimeout, func(ctx context.Context) {
+		err = s.sync(ctx, &status)
+	}); lockErr != nil {
+		s.log.Debug("Git sync is running on another instance, only pulling the branch", "error", lockErr)
+		err = s.pull(ctx, &status)
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	s.statusMu.Lock()
+	s.status = status
+	s.statusMu.Unlock()
+	return err
+}
+
+func (s *Syncer) sync(ctx context.Context, status *Status) error {
+	repo, err := s.openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The drift is computed before the pull to compare the dashboards with the files they were provisioned from.
+	var edited []DriftItem
+	if s.settings.PushChanges {
+		edited, err = s.Drift(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	pulled, err := repo.pull(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(edited) > 0 {
+		var toPush []DriftItem
+		for _, item := range edited {
+			if pulled.changed[item.Path] {
+				s.log.Warn("Dashboard edited in Grafana and in the repository, keeping the repository version", "uid", item.UID, "path", item.Path)
+				status.Conflicts = append(status.Conflicts, item)
+				continue
+			}
+			toPush = append(toPush, item)
+		}
+
+		if err := repo.push(ctx, toPush); err != nil {
+			return err
+		}
+		status.Pushed = len(toPush)
+	}
+
+	status.Commit, err = repo.head()
+	if err != nil {
+		return err
+	}
+
+	if err := s.apply(ctx); err != nil {
+		return err
+	}
+
+	status.Drift, err = s.Drift(ctx)
+	return err
+}
+
+// pull updates the checkout without pushing or provisioning it.
+func (s *Syncer) pull(ctx context.Context, status *Status) error {
+	repo, err := s.openRepository(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := repo.pull(ctx); err != nil {
+		return err
+	}
+	status.Commit, err = repo.head()
+	return err
+}
+
+// Status returns the state of the last sync.
+func (s *Syncer) Status() Status {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+	return s.status
+}
@@ -0,0 +1,244 @@
+package gitsync
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testDashboard = `{
+  "uid": "cpu",
+  "title": "CPU"
+}
+`
+
+// testRemote is a local repository used as remote of the synced repository.
+type testRemote struct {
+	t    *testing.T
+	dir  string
+	repo *git.Repository
+}
+
+func newTestRemote(t *testing.T) *testRemote {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	return &testRemote{t: t, dir: dir, repo: repo}
+}
+
+func (r *testRemote) commit(path, body string) string {
+	r.t.Helper()
+	fpath := filepath.Join(r.dir, path)
+	require.NoError(r.t, os.MkdirAll(filepath.Dir(fpath), 0750))
+	require.NoError(r.t, os.WriteFile(fpath, []byte(body), 0600))
+
+	work, err := r.repo.Worktree()
+	require.NoError(r.t, err)
+	_, err = work.Add(path)
+	require.NoError(r.t, err)
+	hash, err := work.Commit("update "+path, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@localhost", When: time.Now()},
+	})
+	require.NoError(r.t, err)
+	return hash.String()
+}
+
+// file returns the content of a file in the last commit of the branch.
+func (r *testRemote) file(path string) (string, *object.Commit) {
+	r.t.Helper()
+	ref, err := r.repo.Reference("refs/heads/master", true)
+	require.NoError(r.t, err)
+	commit, err := r.repo.CommitObject(ref.Hash())
+	require.NoError(r.t, err)
+	file, err := commit.File(path)
+	require.NoError(r.t, err)
+	reader, err := file.Reader()
+	require.NoError(r.t, err)
+	defer func() { _ = reader.Close() }()
+	body, err := io.ReadAll(reader)
+	require.NoError(r.t, err)
+	return string(body), commit
+}
+
+func setupSyncer(t *testing.T, remote *testRemote, settings setting.GitSyncSettings) (*Syncer, *dashboards.FakeDashboardProvisioning, *dashboards.FakeDashboardService, *int) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	settings.Remote = remote.dir
+	settings.Branch = "master"
+	settings.OrgID = 1
+	settings.CommitAuthorName = "Grafana"
+	settings.CommitAuthorEmail = "grafana@localhost"
+	cfg.GitSync = settings
+
+	provisioning := dashboards.NewFakeDashboardProvisioning(t)
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	applied := 0
+	s := New(cfg, provisioning, dashboardService, &fakeServerLock{}, func(ctx context.Context) error {
+		applied++
+		return nil
+	})
+	return s, provisioning, dashboardService, &applied
+}
+
+type fakeServerLock struct {
+	held bool
+}
+
+func (l *fakeServerLock) LockExecuteAndRelease(ctx context.Context, _ string, _ time.Duration, fn func(ctx context.Context)) error {
+	if l.held {
+		return errors.New("there is already a lock for this actionName")
+	}
+	fn(ctx)
+	return nil
+}
+
+func provisionedDashboard(t *testing.T, s *Syncer, provisioning *dashboards.FakeDashboardProvisioning, dashboardService *dashboards.FakeDashboardService, saved string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(s.dir, 0750))
+	root, err := resolvePath(s.dir)
+	require.NoError(t, err)
+	provisioning.On("GetProvisionedDashboardData", ProviderName).Return([]*models.DashboardProvisioning{
+		{DashboardId: 1, Name: ProviderName, ExternalId: filepath.Join(root, "dashboards", "Team", "cpu.json")},
+	}, nil)
+
+	data, err := simplejson.NewJson([]byte(saved))
+	require.NoError(t, err)
+	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+		query := args.Get(1).(*models.GetDashboardQuery)
+		require.Equal(t, int64(1), query.Id)
+		query.Result = &models.Dashboard{Id: 1, Uid: "cpu", Title: data.Get("title").MustString(), Version: 4, Updated: time.Now(), Data: data}
+	}).Return(nil)
+}
+
+func TestSync(t *testing.T) {
+	t.Run("clones and pulls the branch", func(t *testing.T) {
+		remote := newTestRemote(t)
+		first := remote.commit("dashboards/Team/cpu.json", testDashboard)
+
+		s, provisioning, _, applied := setupSyncer(t, remote, setting.GitSyncSettings{})
+		provisioning.On("GetProvisionedDashboardData", ProviderName).Return([]*models.DashboardProvisioning{}, nil)
+
+		require.NoError(t, s.Sync(context.Background()))
+		require.Equal(t, 1, *applied)
+		require.FileExists(t, filepath.Join(s.DashboardsPath(), "Team", "cpu.json"))
+		status := s.Status()
+		require.Empty(t, status.Error)
+		require.Equal(t, first, status.Commit)
+		require.Empty(t, status.Drift)
+
+		second := remote.commit("alerting/rules.yaml", "apiVersion: 1\n")
+		require.NoError(t, s.Sync(context.Background()))
+		require.Equal(t, 2, *applied)
+		require.FileExists(t, filepath.Join(s.AlertingPath(), "rules.yaml"))
+		require.Equal(t, second, s.Status().Commit)
+	})
+
+	t.Run("only pulls the branch when another instance syncs it", func(t *testing.T) {
+		remote := newTestRemote(t)
+		commit := remote.commit("dashboards/Team/cpu.json", testDashboard)
+
+		s, _, _, applied := setupSyncer(t, remote, setting.GitSyncSettings{PushChanges: true})
+		s.serverLock = &fakeServerLock{held: true}
+
+		require.NoError(t, s.Sync(context.Background()))
+		require.Equal(t, 0, *applied)
+		require.FileExists(t, filepath.Join(s.DashboardsPath(), "Team", "cpu.json"))
+		require.Equal(t, commit, s.Status().Commit)
+	})
+
+	t.Run("reports the dashboards edited in Grafana", func(t *testing.T) {
+		remote := newTestRemote(t)
+		commit := remote.commit("dashboards/Team/cpu.json", testDashboard)
+
+		s, provisioning, dashboardService, _ := setupSyncer(t, remote, setting.GitSyncSettings{})
+		provisionedDashboard(t, s, provisioning, dashboardService, `{"id":1,"uid":"cpu","title":"CPU usage","version":4}`)
+
+		require.NoError(t, s.Sync(context.Background()))
+		status := s.Status()
+		require.Equal(t, 0, status.Pushed)
+		require.Len(t, status.Drift, 1)
+		require.Equal(t, "dashboard", status.Drift[0].Kind)
+		require.Equal(t, "cpu", status.Drift[0].UID)
+		require.Equal(t, "dashboards/Team/cpu.json", status.Drift[0].Path)
+
+		body, _ := remote.file("dashboards/Team/cpu.json")
+		require.Equal(t, testDashboard, body)
+		require.Equal(t, commit, status.Commit)
+	})
+
+	t.Run("pushes the dashboards edited in Grafana", func(t *testing.T) {
+		remote := newTestRemote(t)
+		remote.commit("dashboards/Team/cpu.json", testDashboard)
+
+		s, provisioning, dashboardService, applied := setupSyncer(t, remote, setting.GitSyncSettings{PushChanges: true})
+		provisioning.On("GetProvisionedDashboardData", ProviderName).Return([]*models.DashboardProvisioning{}, nil).Twice()
+		require.NoError(t, s.Sync(context.Background()))
+		require.Equal(t, 1, *applied)
+
+		provisionedDashboard(t, s, provisioning, dashboardService, `{"id":1,"uid":"cpu","title":"CPU usage","version":4}`)
+		require.NoError(t, s.Sync(context.Background()))
+		status := s.Status()
+		require.Empty(t, status.Error)
+		require.Equal(t, 1, status.Pushed)
+		require.Empty(t, status.Conflicts)
+
+		body, commit := remote.file("dashboards/Team/cpu.json")
+		require.Equal(t, "{\n  \"title\": \"CPU usage\",\n  \"uid\": \"cpu\"\n}\n", body)
+		require.Equal(t, "Grafana", commit.Author.Name)
+		require.Equal(t, "Update dashboard CPU usage\n\nVersion 4 saved in Grafana.", commit.Message)
+		require.Equal(t, commit.Hash.String(), status.Commit)
+		require.Equal(t, 2, *applied)
+	})
+
+	t.Run("keeps the repository version on conflicts", func(t *testing.T) {
+		remote := newTestRemote(t)
+		remote.commit("dashboards/Team/cpu.json", testDashboard)
+
+		s, provisioning, dashboardService, _ := setupSyncer(t, remote, setting.GitSyncSettings{PushChanges: true})
+		provisioning.On("GetProvisionedDashboardData", ProviderName).Return([]*models.DashboardProvisioning{}, nil).Twice()
+		require.NoError(t, s.Sync(context.Background()))
+
+		updated := "{\n  \"uid\": \"cpu\",\n  \"title\": \"CPU from git\"\n}\n"
+		commit := remote.commit("dashboards/Team/cpu.json", updated)
+		provisionedDashboard(t, s, provisioning, dashboardService, `{"id":1,"uid":"cpu","title":"CPU usage","version":4}`)
+		require.NoError(t, s.Sync(context.Background()))
+
+		status := s.Status()
+		require.Equal(t, 0, status.Pushed)
+		require.Len(t, status.Conflicts, 1)
+		require.Equal(t, "cpu", status.Conflicts[0].UID)
+		require.Equal(t, commit, status.Commit)
+
+		body, _ := remote.file("dashboards/Team/cpu.json")
+		require.Equal(t, updated, body)
+		local, err := os.ReadFile(filepath.Join(s.DashboardsPath(), "Team", "cpu.json"))
+		require.NoError(t, err)
+		require.Equal(t, updated, string(local))
+	})
+
+	t.Run("reports the errors", func(t *testing.T) {
+		remote := newTestRemote(t)
+		s, _, _, applied := setupSyncer(t, remote, setting.GitSyncSettings{})
+		s.settings.Remote = filepath.Join(remote.dir, "missing")
+
+		require.Error(t, s.Sync(context.Background()))
+		require.NotEmpty(t, s.Status().Error)
+		require.Equal(t, 0, *applied)
+	})
+}
@@ -0,0 +1,201 @@
+package gitsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const remoteName = "origin"
+
+// repository is the local checkout of the synced branch.
+type repository struct {
+	repo     *git.Repository
+	dir      string
+	settings setting.GitSyncSettings
+}
+
+type pullResult struct {
+	// changed are the paths, relative to the repository root, changed by the pulled commits.
+	changed map[string]bool
+}
+
+func (s *Syncer) openRepository(ctx context.Context) (*repository, error) {
+	if s.settings.Remote == "" {
+		return nil, fmt.Errorf("missing git sync remote")
+	}
+
+	repo, err := git.PlainOpen(s.dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		if err := os.MkdirAll(s.dir, 0750); err != nil {
+			return nil, err
+		}
+		s.log.Info("Cloning git repository", "remote", s.settings.Remote, "branch", s.settings.Branch)
+		repo, err = git.PlainCloneContext(ctx, s.dir, false, &git.CloneOptions{
+			URL:           s.settings.Remote,
+			Auth:          auth(s.settings),
+			ReferenceName: plumbing.NewBranchReferenceName(s.settings.Branch),
+			SingleBranch:  true,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	return &repository{repo: repo, dir: s.dir, settings: s.settings}, nil
+}
+
+func auth(settings setting.GitSyncSettings) transport.AuthMethod {
+	if settings.AccessToken == "" {
+		return nil
+	}
+	// GitHub, GitLab and Bitbucket accept the access tokens as password of any user name.
+	return &githttp.BasicAuth{Username: "grafana", Password: settings.AccessToken}
+}
+
+func (r *repository) head() (string, error) {
+	ref, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+// pull fetches the branch and resets the checkout to it, the local changes are discarded.
+func (r *repository) pull(ctx context.Context) (*pullResult, error) {
+	branch := r.settings.Branch
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: remoteName,
+		Auth:       auth(r.settings),
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remoteName, branch))},
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to fetch %s: %w", branch, err)
+	}
+
+	remoteRef, err := r.repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), true)
+	if err != nil {
+		return nil, err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := r.changedPaths(head.Hash(), remoteRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.reset(remoteRef.Hash()); err != nil {
+		return nil, err
+	}
+	return &pullResult{changed: changed}, nil
+}
+
+func (r *repository) reset(commit plumbing.Hash) error {
+	work, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return work.Reset(&git.ResetOptions{Commit: commit, Mode: git.HardReset})
+}
+
+func (r *repository) changedPaths(from, to plumbing.Hash) (map[string]bool, error) {
+	changed := map[string]bool{}
+	if from == to {
+		return changed, nil
+	}
+
+	fromTree, err := r.tree(from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := r.tree(to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if change.From.Name != "" {
+			changed[change.From.Name] = true
+		}
+		if change.To.Name != "" {
+			changed[change.To.Name] = true
+		}
+	}
+	return changed, nil
+}
+
+func (r *repository) tree(hash plumbing.Hash) (*object.Tree, error) {
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// push commits the dashboards edited in Grafana, one commit per dashboard, and pushes them to the branch.
+// The checkout is reset to the remote branch when the push fails.
+func (r *repository) push(ctx context.Context, items []DriftItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	work, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := os.WriteFile(filepath.Join(r.dir, item.Path), item.body, 0644); err != nil {
+			return err
+		}
+		if _, err := work.Add(item.Path); err != nil {
+			return err
+		}
+		_, err = work.Commit(fmt.Sprintf("Update dashboard %s\n\nVersion %d saved in Grafana.", item.Title, item.Version), &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  r.settings.CommitAuthorName,
+				Email: r.settings.CommitAuthorEmail,
+				When:  item.Updated,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	branch := plumbing.NewBranchReferenceName(r.settings.Branch)
+	err = r.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: remoteName,
+		Auth:       auth(r.settings),
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", branch, branch))},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if resetErr := r.reset(head.Hash()); resetErr != nil {
+			return fmt.Errorf("failed to push %s: %v, failed to reset the checkout: %w", r.settings.Branch, err, resetErr)
+		}
+		return fmt.Errorf("failed to push %s: %w", r.settings.Branch, err)
+	}
+	return nil
+}
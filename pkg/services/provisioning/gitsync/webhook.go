@@ -0,0 +1,63 @@
+package gitsync
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var (
+	ErrWebhookDisabled  = errors.New("git sync webhook is disabled")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+type pushEvent struct {
+	Ref string `json:"ref"`
+}
+
+// HandleWebhook validates a push webhook and triggers a sync unless another branch was pushed. GitHub and Gitea
+// sign the payload with the secret (X-Hub-Signature-256), GitLab sends the secret itself (X-Gitlab-Token).
+func (s *Syncer) HandleWebhook(header http.Header, body []byte) (bool, error) {
+	if s.settings.WebhookSecret == "" {
+		return false, ErrWebhookDisabled
+	}
+
+	valid := false
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		valid = validSignature(s.settings.WebhookSecret, body, signature)
+	} else if token := header.Get("X-Gitlab-Token"); token != "" {
+		valid = subtle.ConstantTimeCompare([]byte(token), []byte(s.settings.WebhookSecret)) == 1
+	}
+	if !valid {
+		return false, ErrInvalidSignature
+	}
+
+	event := pushEvent{}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return false, err
+	}
+	if event.Ref != "" && event.Ref != plumbing.NewBranchReferenceName(s.settings.Branch).String() {
+		return false, nil
+	}
+
+	s.Trigger()
+	return true, nil
+}
+
+// validSignature checks the `sha256=<hex hmac>` signature of the body.
+func validSignature(secret string, body []byte, signature string) bool {
+	sum, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
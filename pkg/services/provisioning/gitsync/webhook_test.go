@@ -0,0 +1,78 @@
+package gitsync
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandleWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main","after":"0123456"}`)
+	newSyncer := func(secret string) *Syncer {
+		cfg := setting.NewCfg()
+		cfg.GitSync = setting.GitSyncSettings{Branch: "main", WebhookSecret: secret}
+		return New(cfg, nil, nil, nil, nil)
+	}
+
+	t.Run("triggers a sync with a valid signature", func(t *testing.T) {
+		s := newSyncer("secret")
+		triggered, err := s.HandleWebhook(http.Header{"X-Hub-Signature-256": []string{sign("secret", body)}}, body)
+		require.NoError(t, err)
+		require.True(t, triggered)
+		require.Len(t, s.trigger, 1)
+
+		// a pending sync is not triggered again
+		triggered, err = s.HandleWebhook(http.Header{"X-Hub-Signature-256": []string{sign("secret", body)}}, body)
+		require.NoError(t, err)
+		require.True(t, triggered)
+		require.Len(t, s.trigger, 1)
+	})
+
+	t.Run("triggers a sync with the GitLab token", func(t *testing.T) {
+		s := newSyncer("secret")
+		triggered, err := s.HandleWebhook(http.Header{"X-Gitlab-Token": []string{"secret"}}, body)
+		require.NoError(t, err)
+		require.True(t, triggered)
+	})
+
+	t.Run("fails with an invalid signature", func(t *testing.T) {
+		s := newSyncer("secret")
+		for _, header := range []http.Header{
+			{"X-Hub-Signature-256": []string{sign("other", body)}},
+			{"X-Hub-Signature-256": []string{"sha256=not-hex"}},
+			{"X-Gitlab-Token": []string{"other"}},
+			{},
+		} {
+			_, err := s.HandleWebhook(header, body)
+			require.ErrorIs(t, err, ErrInvalidSignature)
+		}
+		require.Len(t, s.trigger, 0)
+	})
+
+	t.Run("ignores the pushes to other branches", func(t *testing.T) {
+		s := newSyncer("secret")
+		other := []byte(`{"ref":"refs/heads/feature"}`)
+		triggered, err := s.HandleWebhook(http.Header{"X-Hub-Signature-256": []string{sign("secret", other)}}, other)
+		require.NoError(t, err)
+		require.False(t, triggered)
+		require.Len(t, s.trigger, 0)
+	})
+
+	t.Run("fails without secret", func(t *testing.T) {
+		s := newSyncer("")
+		_, err := s.HandleWebhook(http.Header{"X-Hub-Signature-256": []string{sign("", body)}}, body)
+		require.ErrorIs(t, err, ErrWebhookDisabled)
+	})
+}
@@ -3,10 +3,13 @@ package provisioning
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"sync"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -15,6 +18,7 @@ import (
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
@@ -22,6 +26,7 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
@@ -49,6 +54,7 @@ func ProvideService(
 	searchService searchV2.SearchService,
 	quotaService quota.Service,
 	secrectService secrets.Service,
+	serverLockService *serverlock.ServerLockService,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -73,6 +79,9 @@ func ProvideService(
 		secretService:                secrectService,
 		log:                          log.New("provisioning"),
 	}
+	if cfg.GitSync.Enabled {
+		s.gitSync = gitsync.New(cfg, dashboardProvisioningService, dashboardService, serverLockService, s.applyGitSync)
+	}
 	return s, nil
 }
 
@@ -86,6 +95,9 @@ type ProvisioningService interface {
 	ProvisionAlerting(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	SyncGitRepository(ctx context.Context) error
+	GetGitSyncStatus() (gitsync.Status, error)
+	HandleGitSyncWebhook(header http.Header, body []byte) (bool, error)
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -141,6 +153,7 @@ type ProvisioningServiceImpl struct {
 	searchService                searchV2.SearchService
 	quotaService                 quota.Service
 	secretService                secrets.Service
+	gitSync                      *gitsync.Syncer // nil when the git sync is disabled
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -177,6 +190,12 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 		ps.searchService.TriggerReIndex()
 	}
 
+	if ps.gitSync != nil {
+		go func() {
+			_ = ps.gitSync.Run(ctx)
+		}()
+	}
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -231,7 +250,11 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) e
 
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.dashboardProvisioningService, ps.SQLStore, ps.dashboardService)
+	var providers []dashboards.ProviderConfig
+	if ps.gitSync != nil {
+		providers = append(providers, ps.gitSync.DashboardProvider())
+	}
+	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, providers, ps.dashboardProvisioningService, ps.SQLStore, ps.dashboardService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -254,6 +277,10 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
 	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	return ps.provisionAlerting(ctx, ps.alertingProvisionerConfig(alertingPath))
+}
+
+func (ps *ProvisioningServiceImpl) alertingProvisionerConfig(alertingPath string) prov_alerting.ProvisionerConfig {
 	st := store.DBstore{
 		Cfg:              ps.Cfg.UnifiedAlerting,
 		SQLStore:         ps.SQLStore,
@@ -276,7 +303,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		st, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
 	mutetimingsService := provisioning.NewMuteTimingService(&st, st, &st, ps.log)
 	templateService := provisioning.NewTemplateService(&st, st, &st, ps.log)
-	cfg := prov_alerting.ProvisionerConfig{
+	return prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
 		DashboardService:           ps.dashboardService,
@@ -286,7 +313,56 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
 	}
-	return ps.provisionAlerting(ctx, cfg)
+}
+
+// applyGitSync provisions the dashboards and the alerting resources of the git sync checkout, and deletes the
+// alerting resources removed from the repository.
+func (ps *ProvisioningServiceImpl) applyGitSync(ctx context.Context) error {
+	if err := ps.ProvisionDashboards(ctx); err != nil {
+		return err
+	}
+
+	cfg := ps.alertingProvisionerConfig(ps.gitSync.AlertingPath())
+	cfg.Provenance = ngmodels.ProvenanceGit
+	if err := ps.provisionAlerting(ctx, cfg); err != nil {
+		return fmt.Errorf("%v: %w", "Alerting provisioning error", err)
+	}
+
+	orgs := &models.SearchOrgsQuery{}
+	if err := ps.SQLStore.SearchOrgs(ctx, orgs); err != nil {
+		return err
+	}
+	orgIDs := make([]int64, 0, len(orgs.Result))
+	for _, org := range orgs.Result {
+		orgIDs = append(orgIDs, org.Id)
+	}
+	if err := prov_alerting.DeleteMissing(ctx, cfg, store.DBstore{SQLStore: ps.SQLStore}, orgIDs); err != nil {
+		return fmt.Errorf("%v: %w", "Alerting provisioning error", err)
+	}
+	return nil
+}
+
+// SyncGitRepository pulls and provisions the git sync repository.
+func (ps *ProvisioningServiceImpl) SyncGitRepository(ctx context.Context) error {
+	if ps.gitSync == nil {
+		return gitsync.ErrDisabled
+	}
+	return ps.gitSync.Sync(ctx)
+}
+
+func (ps *ProvisioningServiceImpl) GetGitSyncStatus() (gitsync.Status, error) {
+	if ps.gitSync == nil {
+		return gitsync.Status{}, gitsync.ErrDisabled
+	}
+	return ps.gitSync.Status(), nil
+}
+
+// HandleGitSyncWebhook triggers a sync of the git sync repository on the push webhooks of the synced branch.
+func (ps *ProvisioningServiceImpl) HandleGitSyncWebhook(header http.Header, body []byte) (bool, error) {
+	if ps.gitSync == nil {
+		return false, gitsync.ErrDisabled
+	}
+	return ps.gitSync.HandleWebhook(header, body)
 }
 
 func (ps *ProvisioningServiceImpl) GetDashboardProvisionerResolvedPath(name string) string {
@@ -1,6 +1,11 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []interface{}
@@ -11,6 +16,9 @@ type Calls struct {
 	ProvisionAlerting                   []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
+	SyncGitRepository                   []interface{}
+	GetGitSyncStatus                    []interface{}
+	HandleGitSyncWebhook                []interface{}
 	Run                                 []interface{}
 }
 
@@ -23,6 +31,9 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	SyncGitRepositoryFunc                   func(ctx context.Context) error
+	GetGitSyncStatusFunc                    func() (gitsync.Status, error)
+	HandleGitSyncWebhookFunc                func(header http.Header, body []byte) (bool, error)
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -93,6 +104,30 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) SyncGitRepository(ctx context.Context) error {
+	mock.Calls.SyncGitRepository = append(mock.Calls.SyncGitRepository, nil)
+	if mock.SyncGitRepositoryFunc != nil {
+		return mock.SyncGitRepositoryFunc(ctx)
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) GetGitSyncStatus() (gitsync.Status, error) {
+	mock.Calls.GetGitSyncStatus = append(mock.Calls.GetGitSyncStatus, nil)
+	if mock.GetGitSyncStatusFunc != nil {
+		return mock.GetGitSyncStatusFunc()
+	}
+	return gitsync.Status{}, nil
+}
+
+func (mock *ProvisioningServiceMock) HandleGitSyncWebhook(header http.Header, body []byte) (bool, error) {
+	mock.Calls.HandleGitSyncWebhook = append(mock.Calls.HandleGitSyncWebhook, body)
+	if mock.HandleGitSyncWebhookFunc != nil {
+		return mock.HandleGitSyncWebhookFunc(header, body)
+	}
+	return false, nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
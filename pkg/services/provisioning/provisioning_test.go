@@ -3,11 +3,13 @@ package provisioning
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
 	dashboardstore "github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
@@ -65,6 +67,43 @@ func TestProvisioningServiceImpl(t *testing.T) {
 	})
 }
 
+func TestProvisioningServiceImpl_GitSync(t *testing.T) {
+	t.Run("Provisions the dashboards of the git sync", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.DataPath = t.TempDir()
+		cfg.GitSync = setting.GitSyncSettings{Enabled: true, OrgID: 2, Path: "grafana", PushChanges: true}
+
+		var providers []dashboards.ProviderConfig
+		service := newProvisioningServiceImpl(
+			func(_ context.Context, _ string, p []dashboards.ProviderConfig, _ dashboardstore.DashboardProvisioningService, _ utils.OrgStore, _ utils.DashboardStore) (dashboards.DashboardProvisioner, error) {
+				providers = p
+				return dashboards.NewDashboardProvisionerMock(), nil
+			},
+			nil,
+			nil,
+			nil,
+		)
+		service.Cfg = cfg
+		service.gitSync = gitsync.New(cfg, nil, nil, nil, service.applyGitSync)
+
+		err := service.ProvisionDashboards(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []dashboards.ProviderConfig{{
+			Name:           gitsync.ProviderName,
+			OrgID:          2,
+			Path:           filepath.Join(cfg.DataPath, "git_sync", "grafana", "dashboards"),
+			AllowUIUpdates: true,
+		}}, providers)
+	})
+
+	t.Run("Fails when the git sync is disabled", func(t *testing.T) {
+		service := NewProvisioningServiceImpl()
+		assert.ErrorIs(t, service.SyncGitRepository(context.Background()), gitsync.ErrDisabled)
+		_, err := service.GetGitSyncStatus()
+		assert.ErrorIs(t, err, gitsync.ErrDisabled)
+	})
+}
+
 type serviceTestStruct struct {
 	waitForPollChanges func()
 	waitForStop        func()
@@ -93,7 +132,7 @@ func setup() *serviceTestStruct {
 	}
 
 	serviceTest.service = newProvisioningServiceImpl(
-		func(context.Context, string, dashboardstore.DashboardProvisioningService, utils.OrgStore, utils.DashboardStore) (dashboards.DashboardProvisioner, error) {
+		func(context.Context, string, []dashboards.ProviderConfig, dashboardstore.DashboardProvisioningService, utils.OrgStore, utils.DashboardStore) (dashboards.DashboardProvisioner, error) {
 			return serviceTest.mock, nil
 		},
 		nil,
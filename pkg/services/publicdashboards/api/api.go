@@ -17,6 +17,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/queries"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -27,6 +29,7 @@ type Api struct {
 	AccessControl          accesscontrol.AccessControl
 	Features               *featuremgmt.FeatureManager
 	Log                    log.Logger
+	RateLimiter            *RateLimiter
 }
 
 func ProvideApi(
@@ -34,6 +37,7 @@ func ProvideApi(
 	rr routing.RouteRegister,
 	ac accesscontrol.AccessControl,
 	features *featuremgmt.FeatureManager,
+	cfg *setting.Cfg,
 ) *Api {
 	api := &Api{
 		PublicDashboardService: pd,
@@ -41,6 +45,7 @@ func ProvideApi(
 		AccessControl:          ac,
 		Features:               features,
 		Log:                    log.New("publicdashboards.api"),
+		RateLimiter:            NewRateLimiter(cfg.PublicDashboardsRateLimitPerAccessToken, cfg.PublicDashboardsRateLimitPerIP),
 	}
 
 	// attach api if PublicDashboards feature flag is enabled
@@ -59,9 +64,13 @@ func (api *Api) RegisterAPIEndpoints() {
 	// because it is deeply dependent on the HTTPServer.Index() method and would result in a
 	// circular dependency
 
-	// public endpoints
-	api.RouteRegister.Get("/api/public/dashboards/:accessToken", routing.Wrap(api.GetPublicDashboard))
-	api.RouteRegister.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
+	// public endpoints, the access token is validated before the rate limit so that the limiter
+	// only keeps the access tokens of enabled public dashboards
+	validToken := RequiresValidAccessToken(api.PublicDashboardService)
+	rateLimit := RateLimitPublicDashboardRequest(api.RateLimiter)
+	api.RouteRegister.Get("/api/public/dashboards/:accessToken", validToken, rateLimit, routing.Wrap(api.GetPublicDashboard))
+	api.RouteRegister.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", validToken, rateLimit, routing.Wrap(api.QueryPublicDashboard))
+	api.RouteRegister.Get("/api/public/dashboards/:accessToken/annotations", validToken, rateLimit, routing.Wrap(api.GetAnnotations))
 
 	// Create/Update Public Dashboard
 	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
@@ -104,6 +113,15 @@ func (api *Api) GetPublicDashboard(c *models.ReqContext) response.Response {
 		FolderId:                   dash.FolderId,
 		PublicDashboardAccessToken: pubdash.AccessToken,
 		PublicDashboardUID:         pubdash.Uid,
+
+		PublicDashboardTimeSelectionEnabled: pubdash.TimeSelectionEnabled,
+		PublicDashboardMaxTimeRange:         pubdash.MaxTimeRange,
+		PublicDashboardAnnotationsEnabled:   pubdash.AnnotationsEnabled,
+	}
+
+	// the viewers only see the allowed template variables, with the options they can choose
+	if dash.Data != nil {
+		dash.Data.SetPath([]string{"templating", "list"}, queries.BuildPublicTemplating(dash.Data, pubdash.TemplateVariables))
 	}
 
 	dto := dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}
@@ -174,6 +192,22 @@ func (api *Api) QueryPublicDashboard(c *models.ReqContext) response.Response {
 	return toJsonStreamingResponse(api.Features, resp)
 }
 
+// GetAnnotations returns the annotations of a public dashboard
+// GET /api/public/dashboards/:accessToken/annotations
+func (api *Api) GetAnnotations(c *models.ReqContext) response.Response {
+	reqDTO := PublicDashboardAnnotationsQueryDTO{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+
+	annotations, err := api.PublicDashboardService.FindAnnotations(c.Req.Context(), reqDTO, web.Params(c.Req)[":accessToken"])
+	if err != nil {
+		return api.handleError(http.StatusInternalServerError, "error getting public dashboard annotations", err)
+	}
+
+	return response.JSON(http.StatusOK, annotations)
+}
+
 // util to help us unpack dashboard and publicdashboard errors or use default http code and message
 // we should look to do some future refactoring of these errors as publicdashboard err is the same as a dashboarderr, just defined in a
 // different package.
@@ -184,7 +218,8 @@ func (api *Api) handleError(code int, message string, err error) response.Respon
 
 	// handle public dashboard error
 	if ok := errors.As(err, &publicDashboardErr); ok {
-		return response.Error(publicDashboardErr.StatusCode, publicDashboardErr.Error(), publicDashboardErr)
+		// the message of the wrapping error has the details of the public dashboard error
+		return response.Error(publicDashboardErr.StatusCode, err.Error(), publicDashboardErr)
 	}
 
 	// handle dashboard errors as well
@@ -51,6 +51,7 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 		cfg := setting.NewCfg()
 		cfg.RBACEnabled = false
 		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("AccessTokenExists", mock.Anything, mock.AnythingOfType("string")).Return(true, nil).Maybe()
 		service.On("GetPublicDashboard", mock.Anything, mock.AnythingOfType("string")).
 			Return(&PublicDashboard{}, &models.Dashboard{}, nil).Maybe()
 		service.On("GetPublicDashboardConfig", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("string")).
@@ -105,6 +106,7 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.Name, func(t *testing.T) {
 			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("AccessTokenExists", mock.Anything, mock.AnythingOfType("string")).Return(true, nil).Maybe()
 			service.On("GetPublicDashboard", mock.Anything, mock.AnythingOfType("string")).
 				Return(&PublicDashboard{}, test.DashboardResult, test.Err).Maybe()
 
@@ -420,6 +422,7 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 
 	setup := func(enabled bool) (*web.Mux, *publicdashboards.FakePublicDashboardService) {
 		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("AccessTokenExists", mock.Anything, mock.AnythingOfType("string")).Return(true, nil).Maybe()
 		cfg := setting.NewCfg()
 		cfg.RBACEnabled = false
 
@@ -544,7 +547,7 @@ func TestIntegrationUnauthenticatedUserCanGetPubdashPanelQueryData(t *testing.T)
 	store := publicdashboardsStore.ProvideStore(db)
 	cfg := setting.NewCfg()
 	cfg.RBACEnabled = false
-	service := publicdashboardsService.ProvideService(cfg, store, qds)
+	service := publicdashboardsService.ProvideService(cfg, store, qds, nil)
 	pubdash, err := service.SavePublicDashboardConfig(context.Background(), &user.SignedInUser{}, savePubDashboardCmd)
 	require.NoError(t, err)
 
@@ -585,3 +588,73 @@ func TestIntegrationUnauthenticatedUserCanGetPubdashPanelQueryData(t *testing.T)
 		resp.Body.String(),
 	)
 }
+
+func TestAPIGetAnnotations(t *testing.T) {
+	testCases := []struct {
+		Name                 string
+		ExpectedHttpResponse int
+		Annotations          []AnnotationEvent
+		ServiceError         error
+	}{
+		{
+			Name:                 "returns the annotations",
+			ExpectedHttpResponse: http.StatusOK,
+			Annotations:          []AnnotationEvent{{Id: 1, Text: "deployment", Time: 1}},
+		},
+		{
+			Name:                 "returns 404 when the public dashboard doesn't exist",
+			ExpectedHttpResponse: http.StatusNotFound,
+			ServiceError:         ErrPublicDashboardNotFound,
+		},
+		{
+			Name:                 "returns 400 when the time range is not allowed",
+			ExpectedHttpResponse: http.StatusBadRequest,
+			ServiceError:         ErrPublicDashboardTimeRangeNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("AccessTokenExists", mock.Anything, mock.AnythingOfType("string")).Return(true, nil).Maybe()
+			service.On("FindAnnotations", mock.Anything, PublicDashboardAnnotationsQueryDTO{From: "now-1h", To: "now"}, "abc123").
+				Return(test.Annotations, test.ServiceError)
+
+			testServer := setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, anonymousUser)
+
+			response := callAPI(testServer, http.MethodGet, "/api/public/dashboards/abc123/annotations?from=now-1h&to=now", nil, t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusOK {
+				var annotations []AnnotationEvent
+				require.NoError(t, json.Unmarshal(response.Body.Bytes(), &annotations))
+				assert.Equal(t, test.Annotations, annotations)
+			}
+		})
+	}
+}
+
+func TestAPIRateLimitsValidAccessTokens(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.RBACEnabled = false
+	cfg.PublicDashboardsRateLimitPerAccessToken = 1
+	service := publicdashboards.NewFakePublicDashboardService(t)
+	service.On("AccessTokenExists", mock.Anything, "invalid").Return(false, nil)
+	service.On("AccessTokenExists", mock.Anything, "abc123").Return(true, nil)
+	service.On("GetPublicDashboard", mock.Anything, "abc123").Return(&PublicDashboard{}, &models.Dashboard{}, nil)
+
+	testServer := setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, anonymousUser)
+
+	// the invalid access tokens are rejected before the rate limit
+	for i := 0; i < 2; i++ {
+		response := callAPI(testServer, http.MethodGet, "/api/public/dashboards/invalid", nil, t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	}
+
+	response := callAPI(testServer, http.MethodGet, "/api/public/dashboards/abc123", nil, t)
+	assert.Equal(t, http.StatusOK, response.Code)
+	response = callAPI(testServer, http.MethodGet, "/api/public/dashboards/abc123", nil, t)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+}
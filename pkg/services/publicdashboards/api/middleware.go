@@ -1,6 +1,7 @@
 package api
 
 import (
+	"net"
 	"net/http"
 	"strconv"
 
@@ -52,3 +53,23 @@ func CountPublicDashboardRequest() func(c *models.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
 	}
 }
+
+// RateLimitPublicDashboardRequest rejects the requests above the limits of the public dashboard
+// and of the client. The client is the address of the connection, since the X-Forwarded-For and
+// X-Real-IP headers can be set by the clients themselves to get around the limit.
+func RateLimitPublicDashboardRequest(limiter *RateLimiter) func(c *models.ReqContext) {
+	return func(c *models.ReqContext) {
+		clientAddr, _, err := net.SplitHostPort(c.Req.RemoteAddr)
+		if err != nil {
+			clientAddr = c.Req.RemoteAddr
+		}
+
+		if limiter.Allow(web.Params(c.Req)[":accessToken"], clientAddr) {
+			return
+		}
+
+		metrics.MPublicDashboardRateLimitedRequestCount.Inc()
+		c.Resp.Header().Set("Retry-After", "60")
+		c.JsonApiErr(http.StatusTooManyRequests, "Too many requests", nil)
+	}
+}
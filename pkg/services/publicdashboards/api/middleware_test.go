@@ -81,7 +81,7 @@ func mockAccessTokenExistsResponse(returnArguments ...interface{}) *publicdashbo
 		&fakeOAuthTokenService{},
 	)
 
-	return publicdashboardsService.ProvideService(setting.NewCfg(), fakeStore, qds)
+	return publicdashboardsService.ProvideService(setting.NewCfg(), fakeStore, qds, nil)
 }
 
 func runMiddleware(request *http.Request, pubdashService *publicdashboardsService.PublicDashboardServiceImpl) *httptest.ResponseRecorder {
@@ -103,3 +103,34 @@ func mockValidRequestHandler(c *models.ReqContext) {
 	resp["message"] = "Valid request"
 	c.JSON(http.StatusOK, resp)
 }
+
+func TestRateLimitPublicDashboardRequest(t *testing.T) {
+	limiter := NewRateLimiter(0, 1)
+
+	runRateLimitMiddleware := func(forwardedFor string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest("GET", "/api/public/ma/events/myAccessToken", nil)
+		require.NoError(t, err)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+
+		recorder := httptest.NewRecorder()
+		m := web.New()
+		m.Use(func(c *web.Context) {
+			c.Req = c.Req.WithContext(ctxkey.Set(c.Req.Context(), &models.ReqContext{Context: c}))
+		})
+		m.Get("/api/public/ma/events/:accessToken", RateLimitPublicDashboardRequest(limiter), mockValidRequestHandler)
+		m.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("Returns 200 when the request is within the limits", func(t *testing.T) {
+		resp := runRateLimitMiddleware("192.168.0.1")
+		require.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("Returns 429 when the request is above the limits, whatever the forwarded address", func(t *testing.T) {
+		resp := runRateLimitMiddleware("192.168.0.2")
+		require.Equal(t, http.StatusTooManyRequests, resp.Code)
+		require.Equal(t, "60", resp.Header().Get("Retry-After"))
+	})
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// limiterIdleTimeout is how long the limiter of an access token or a client is kept after its last request.
+const limiterIdleTimeout = 10 * time.Minute
+
+// RateLimiter limits the requests to the public dashboards per access token and per client address,
+// so that a public dashboard shared widely cannot overload the data sources.
+type RateLimiter struct {
+	perAccessToken *keyedLimiter
+	perIP          *keyedLimiter
+}
+
+// NewRateLimiter returns a rate limiter allowing the numbers of requests per minute, a limit of 0
+// disables it.
+func NewRateLimiter(perAccessTokenPerMinute, perIPPerMinute int) *RateLimiter {
+	return &RateLimiter{
+		perAccessToken: newKeyedLimiter(perAccessTokenPerMinute),
+		perIP:          newKeyedLimiter(perIPPerMinute),
+	}
+}
+
+// Allow returns whether a request to the public dashboard of the access token from the client
+// address is within the limits.
+func (rl *RateLimiter) Allow(accessToken string, ip string) bool {
+	now := time.Now()
+	return rl.perIP.allow(ip, now) && rl.perAccessToken.allow(accessToken, now)
+}
+
+// keyedLimiter is a token bucket per key, which allows a minute of requests at once.
+type keyedLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mutex       sync.Mutex
+	limiters    map[string]*limiterEntry
+	lastCleanup time.Time
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newKeyedLimiter(perMinute int) *keyedLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &keyedLimiter{
+		limit:       rate.Limit(float64(perMinute) / 60),
+		burst:       perMinute,
+		limiters:    make(map[string]*limiterEntry),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (l *keyedLimiter) allow(key string, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.lastCleanup) > limiterIdleTimeout {
+		for k, entry := range l.limiters {
+			if now.Sub(entry.lastSeen) > limiterIdleTimeout {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	entry, ok := l.limiters[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = entry
+	}
+	entry.lastSeen = now
+
+	return entry.limiter.AllowN(now, 1)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("limits the requests per access token", func(t *testing.T) {
+		limiter := NewRateLimiter(2, 0)
+
+		assert.True(t, limiter.Allow("token1", "10.0.0.1"))
+		assert.True(t, limiter.Allow("token1", "10.0.0.2"))
+		assert.False(t, limiter.Allow("token1", "10.0.0.3"))
+		assert.True(t, limiter.Allow("token2", "10.0.0.1"))
+	})
+
+	t.Run("limits the requests per client address", func(t *testing.T) {
+		limiter := NewRateLimiter(0, 2)
+
+		assert.True(t, limiter.Allow("token1", "10.0.0.1"))
+		assert.True(t, limiter.Allow("token2", "10.0.0.1"))
+		assert.False(t, limiter.Allow("token3", "10.0.0.1"))
+		assert.True(t, limiter.Allow("token1", "10.0.0.2"))
+	})
+
+	t.Run("allows all the requests when the limits are disabled", func(t *testing.T) {
+		limiter := NewRateLimiter(0, 0)
+
+		for i := 0; i < 100; i++ {
+			assert.True(t, limiter.Allow("token1", "10.0.0.1"))
+		}
+	})
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := newKeyedLimiter(60)
+
+	t.Run("refills the requests over the minute", func(t *testing.T) {
+		for i := 0; i < 60; i++ {
+			assert.True(t, limiter.allow("key", now))
+		}
+		assert.False(t, limiter.allow("key", now))
+		assert.True(t, limiter.allow("key", now.Add(time.Second)))
+	})
+
+	t.Run("removes the idle limiters", func(t *testing.T) {
+		limiter.allow("other", now.Add(time.Second))
+		assert.Len(t, limiter.limiters, 2)
+
+		limiter.allow("other", now.Add(limiterIdleTimeout+2*time.Second))
+		assert.Len(t, limiter.limiters, 1)
+	})
+}
@@ -170,7 +170,7 @@ func (d *PublicDashboardStoreImpl) SavePublicDashboardConfig(ctx context.Context
 	}
 
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		_, err := sess.UseBool("is_enabled").Insert(&cmd.PublicDashboard)
+		_, err := sess.UseBool("is_enabled", "time_selection_enabled", "annotations_enabled").Insert(&cmd.PublicDashboard)
 		if err != nil {
 			return err
 		}
@@ -189,9 +189,18 @@ func (d *PublicDashboardStoreImpl) UpdatePublicDashboardConfig(ctx context.Conte
 			return err
 		}
 
-		_, err = sess.Exec("UPDATE dashboard_public SET is_enabled = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		templateVariablesJSON, err := json.Marshal(cmd.PublicDashboard.TemplateVariables)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_public SET is_enabled = ?, time_settings = ?, template_variables = ?, time_selection_enabled = ?, max_time_range = ?, annotations_enabled = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			string(timeSettingsJSON),
+			string(templateVariablesJSON),
+			cmd.PublicDashboard.TimeSelectionEnabled,
+			cmd.PublicDashboard.MaxTimeRange,
+			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
 			cmd.PublicDashboard.Uid)
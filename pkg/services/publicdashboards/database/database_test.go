@@ -363,8 +363,15 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 			OrgId:        savedDashboard.OrgId,
 			IsEnabled:    false,
 			TimeSettings: &TimeSettings{From: "now-8", To: "now"},
-			UpdatedAt:    time.Now().UTC().Round(time.Second),
-			UpdatedBy:    8,
+			TemplateVariables: TemplateVariables{
+				{Name: "region", Options: []string{"eu", "us"}},
+				{Name: "env", Fixed: true, Value: "prod"},
+			},
+			TimeSelectionEnabled: true,
+			MaxTimeRange:         "7d",
+			AnnotationsEnabled:   true,
+			UpdatedAt:            time.Now().UTC().Round(time.Second),
+			UpdatedBy:            8,
 		}
 		// update initial record
 		err = publicdashboardStore.UpdatePublicDashboardConfig(context.Background(), SavePublicDashboardConfigCommand{
@@ -380,6 +387,10 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		// make sure we're correctly updated IsEnabled because we have to call
 		// UseBool with xorm
 		assert.Equal(t, updatedPublicDashboard.IsEnabled, pdRetrieved.IsEnabled)
+		assert.Equal(t, updatedPublicDashboard.TemplateVariables, pdRetrieved.TemplateVariables)
+		assert.Equal(t, updatedPublicDashboard.TimeSelectionEnabled, pdRetrieved.TimeSelectionEnabled)
+		assert.Equal(t, updatedPublicDashboard.MaxTimeRange, pdRetrieved.MaxTimeRange)
+		assert.Equal(t, updatedPublicDashboard.AnnotationsEnabled, pdRetrieved.AnnotationsEnabled)
 
 		// not updated dashboard shouldn't have changed
 		pdNotUpdatedRetrieved, err := publicdashboardStore.GetPublicDashboardConfig(context.Background(), anotherSavedDashboard.OrgId, anotherSavedDashboard.Uid)
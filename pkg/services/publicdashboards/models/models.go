@@ -51,6 +51,22 @@ var (
 		Reason:     "public dashboard has template variables",
 		StatusCode: 422,
 	}
+	ErrPublicDashboardInvalidTemplateVariable = PublicDashboardErr{
+		Reason:     "invalid template variable",
+		StatusCode: 422,
+	}
+	ErrPublicDashboardTemplateVariableNotAllowed = PublicDashboardErr{
+		Reason:     "template variable value is not allowed",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardTimeRangeNotAllowed = PublicDashboardErr{
+		Reason:     "time range is not allowed",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardInvalidMaxTimeRange = PublicDashboardErr{
+		Reason:     "invalid maximum time range",
+		StatusCode: 422,
+	}
 	ErrPublicDashboardBadRequest = PublicDashboardErr{
 		Reason:     "bad Request",
 		StatusCode: 400,
@@ -58,12 +74,18 @@ var (
 )
 
 type PublicDashboard struct {
-	Uid          string        `json:"uid" xorm:"pk uid"`
-	DashboardUid string        `json:"dashboardUid" xorm:"dashboard_uid"`
-	OrgId        int64         `json:"-" xorm:"org_id"` // Don't ever marshal orgId to Json
-	TimeSettings *TimeSettings `json:"timeSettings" xorm:"time_settings"`
-	IsEnabled    bool          `json:"isEnabled" xorm:"is_enabled"`
-	AccessToken  string        `json:"accessToken" xorm:"access_token"`
+	Uid               string            `json:"uid" xorm:"pk uid"`
+	DashboardUid      string            `json:"dashboardUid" xorm:"dashboard_uid"`
+	OrgId             int64             `json:"-" xorm:"org_id"` // Don't ever marshal orgId to Json
+	TimeSettings      *TimeSettings     `json:"timeSettings" xorm:"time_settings"`
+	TemplateVariables TemplateVariables `json:"templateVariables" xorm:"template_variables"`
+	IsEnabled         bool              `json:"isEnabled" xorm:"is_enabled"`
+	AccessToken       string            `json:"accessToken" xorm:"access_token"`
+
+	// Viewers can choose the time range when enabled, up to the maximum time range if set
+	TimeSelectionEnabled bool   `json:"timeSelectionEnabled" xorm:"time_selection_enabled"`
+	MaxTimeRange         string `json:"maxTimeRange" xorm:"max_time_range"`
+	AnnotationsEnabled   bool   `json:"annotationsEnabled" xorm:"annotations_enabled"`
 
 	CreatedBy int64 `json:"createdBy" xorm:"created_by"`
 	UpdatedBy int64 `json:"updatedBy" xorm:"updated_by"`
@@ -89,6 +111,38 @@ func (ts *TimeSettings) ToDB() ([]byte, error) {
 	return json.Marshal(ts)
 }
 
+// TemplateVariable allows a template variable of the dashboard on the public dashboard. The
+// viewers can choose its value from the options, unless it is fixed.
+type TemplateVariable struct {
+	Name string `json:"name"`
+	// Fixed variables always have the default value
+	Fixed bool `json:"fixed"`
+	// Value is the default value, the current value of the dashboard variable when empty
+	Value string `json:"value,omitempty"`
+	// Options restricts the options of the dashboard variable, all of them are allowed when empty
+	Options []string `json:"options,omitempty"`
+}
+
+type TemplateVariables []TemplateVariable
+
+func (tv *TemplateVariables) FromDB(data []byte) error {
+	return json.Unmarshal(data, tv)
+}
+
+func (tv *TemplateVariables) ToDB() ([]byte, error) {
+	return json.Marshal(tv)
+}
+
+// Get returns the allowed template variable with the name
+func (tv TemplateVariables) Get(name string) (TemplateVariable, bool) {
+	for _, v := range tv {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return TemplateVariable{}, false
+}
+
 // build time settings object from json on public dashboard. If empty, use
 // defaults on the dashboard
 func (pd PublicDashboard) BuildTimeSettings(dashboard *models.Dashboard) TimeSettings {
@@ -120,6 +174,29 @@ type SavePublicDashboardConfigDTO struct {
 type PublicDashboardQueryDTO struct {
 	IntervalMs    int64
 	MaxDataPoints int64
+	// Values chosen by the viewer for the template variables, by name
+	Variables map[string]string
+	// Time range chosen by the viewer, when the public dashboard allows it
+	TimeRange TimeSettings
+}
+
+type PublicDashboardAnnotationsQueryDTO struct {
+	From string
+	To   string
+}
+
+// AnnotationEvent is an annotation of a public dashboard, without the details of who created it
+type AnnotationEvent struct {
+	Id          int64    `json:"id"`
+	DashboardId int64    `json:"dashboardId"`
+	PanelId     int64    `json:"panelId"`
+	Tags        []string `json:"tags"`
+	IsRegion    bool     `json:"isRegion"`
+	Text        string   `json:"text"`
+	Color       string   `json:"color"`
+	Time        int64    `json:"time"`
+	TimeEnd     int64    `json:"timeEnd"`
+	Source      string   `json:"source"`
 }
 
 //
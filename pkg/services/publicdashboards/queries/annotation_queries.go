@@ -0,0 +1,64 @@
+package queries
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	AnnotationQueryTypeDashboard = "dashboard"
+	AnnotationQueryTypeTags      = "tags"
+)
+
+// AnnotationQuery is an enabled annotation query of a dashboard on the built-in Grafana data source
+type AnnotationQuery struct {
+	Name     string
+	Color    string
+	Type     string
+	Tags     []string
+	MatchAny bool
+	Limit    int64
+}
+
+// GetAnnotationQueries returns the enabled annotation queries of the dashboard that use the built-in
+// Grafana data source. The annotation queries of other data sources are not supported.
+func GetAnnotationQueries(dashboard *simplejson.Json) []AnnotationQuery {
+	var annotationQueries []AnnotationQuery
+
+	for _, annotationObj := range dashboard.Get("annotations").Get("list").MustArray() {
+		annotation := simplejson.NewFromAny(annotationObj)
+		if !annotation.Get("enable").MustBool(true) || !isGrafanaDataSource(annotation) {
+			continue
+		}
+
+		// the options of the query are in the target since 8.5, and at the top level before
+		target, ok := annotation.CheckGet("target")
+		if !ok {
+			target = annotation
+		}
+
+		query := AnnotationQuery{
+			Name:     annotation.Get("name").MustString(),
+			Color:    annotation.Get("iconColor").MustString(),
+			Type:     target.Get("type").MustString(AnnotationQueryTypeDashboard),
+			Tags:     target.Get("tags").MustStringArray(),
+			MatchAny: target.Get("matchAny").MustBool(),
+			Limit:    target.Get("limit").MustInt64(100),
+		}
+		if query.Type != AnnotationQueryTypeDashboard && query.Type != AnnotationQueryTypeTags {
+			continue
+		}
+
+		annotationQueries = append(annotationQueries, query)
+	}
+
+	return annotationQueries
+}
+
+func isGrafanaDataSource(annotation *simplejson.Json) bool {
+	switch GetDataSourceUidFromJson(annotation) {
+	case "grafana", "-- Grafana --":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,66 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/require"
+)
+
+const dashboardWithAnnotations = `
+{
+  "annotations": {
+    "list": [
+      {
+        "name": "Annotations & Alerts",
+        "enable": true,
+        "iconColor": "rgba(0, 211, 255, 1)",
+        "datasource": { "type": "grafana", "uid": "-- Grafana --" },
+        "target": { "type": "dashboard", "limit": 100 }
+      },
+      {
+        "name": "Deployments",
+        "enable": true,
+        "iconColor": "red",
+        "datasource": { "type": "datasource", "uid": "grafana" },
+        "target": { "type": "tags", "tags": ["deploy", "prod"], "matchAny": true, "limit": 20 }
+      },
+      {
+        "name": "Before 8.5",
+        "iconColor": "green",
+        "datasource": "-- Grafana --",
+        "type": "tags",
+        "tags": ["legacy"]
+      },
+      {
+        "name": "Disabled",
+        "enable": false,
+        "datasource": { "type": "grafana", "uid": "-- Grafana --" },
+        "target": { "type": "dashboard" }
+      },
+      {
+        "name": "Prometheus",
+        "enable": true,
+        "datasource": { "type": "prometheus", "uid": "prom" },
+        "expr": "changes(up[5m])"
+      }
+    ]
+  }
+}`
+
+func TestGetAnnotationQueries(t *testing.T) {
+	t.Run("returns the enabled annotation queries of the Grafana data source", func(t *testing.T) {
+		json, err := simplejson.NewJson([]byte(dashboardWithAnnotations))
+		require.NoError(t, err)
+
+		require.Equal(t, []AnnotationQuery{
+			{Name: "Annotations & Alerts", Color: "rgba(0, 211, 255, 1)", Type: AnnotationQueryTypeDashboard, Limit: 100},
+			{Name: "Deployments", Color: "red", Type: AnnotationQueryTypeTags, Tags: []string{"deploy", "prod"}, MatchAny: true, Limit: 20},
+			{Name: "Before 8.5", Color: "green", Type: AnnotationQueryTypeTags, Tags: []string{"legacy"}, Limit: 100},
+		}, GetAnnotationQueries(json))
+	})
+
+	t.Run("returns nothing when there are no annotations", func(t *testing.T) {
+		require.Empty(t, GetAnnotationQueries(simplejson.New()))
+	})
+}
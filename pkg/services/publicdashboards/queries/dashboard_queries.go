@@ -1,6 +1,8 @@
 package queries
 
 import (
+	"regexp"
+
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
@@ -91,6 +93,64 @@ func GroupQueriesByDataSource(queries []*simplejson.Json) (result [][]*simplejso
 	return
 }
 
+// templateVariableRegex matches the $var, ${var}, ${var:format} and [[var]] syntaxes of the variables
+var templateVariableRegex = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?::[^}]*)?\}|\[\[(\w+)(?::[^\]]*)?\]\]`)
+
+// InterpolateTemplateVariables replaces the template variables in the queries with their values, so
+// that the viewers of public dashboards never send query text. The formats of the variables are
+// not applied, and the variables without a value, like the global variables, are left to the data
+// sources. The queries are copied, as they share their fields with the dashboard.
+func InterpolateTemplateVariables(queries []*simplejson.Json, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	for _, query := range queries {
+		fields, err := query.Map()
+		if err != nil {
+			continue
+		}
+		interpolated := make(map[string]interface{}, len(fields))
+		for key, value := range fields {
+			if key == "datasource" || key == "refId" {
+				interpolated[key] = value
+				continue
+			}
+			interpolated[key] = interpolateValue(value, values)
+		}
+		*query = *simplejson.NewFromAny(interpolated)
+	}
+}
+
+func interpolateValue(value interface{}, values map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return templateVariableRegex.ReplaceAllStringFunc(v, func(match string) string {
+			groups := templateVariableRegex.FindStringSubmatch(match)
+			for _, name := range groups[1:] {
+				if value, ok := values[name]; ok {
+					return value
+				}
+			}
+			return match
+		})
+	case map[string]interface{}:
+		interpolated := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			interpolated[key] = interpolateValue(field, values)
+		}
+		return interpolated
+	case []interface{}:
+		interpolated := make([]interface{}, len(v))
+		for i, item := range v {
+			interpolated[i] = interpolateValue(item, values)
+		}
+		return interpolated
+	default:
+		return v
+	}
+}
+
 func GetDataSourceUidFromJson(query *simplejson.Json) string {
 	uid := query.Get("datasource").Get("uid").MustString()
 
@@ -565,3 +565,33 @@ func TestSanitizeMetadataFromQueryData(t *testing.T) {
 		}
 	})
 }
+
+func TestInterpolateTemplateVariables(t *testing.T) {
+	t.Run("replaces the variables in all the fields of the queries", func(t *testing.T) {
+		query := simplejson.NewFromAny(map[string]interface{}{
+			"datasource": map[string]interface{}{"uid": "$host"},
+			"refId":      "$host",
+			"expr":       `up{instance="$host", job="${job}", env="${env:regex}"} [[job]]`,
+			"nested":     map[string]interface{}{"filters": []interface{}{"$job", 42}},
+			"interval":   "$__interval",
+		})
+
+		InterpolateTemplateVariables([]*simplejson.Json{query}, map[string]string{"host": "server1", "job": "api", "env": "prod"})
+
+		require.Equal(t, simplejson.NewFromAny(map[string]interface{}{
+			"datasource": map[string]interface{}{"uid": "$host"},
+			"refId":      "$host",
+			"expr":       `up{instance="server1", job="api", env="prod"} api`,
+			"nested":     map[string]interface{}{"filters": []interface{}{"api", 42}},
+			"interval":   "$__interval",
+		}), query)
+	})
+
+	t.Run("leaves the variables without value", func(t *testing.T) {
+		query := simplejson.NewFromAny(map[string]interface{}{"expr": "up{job=\"$job\"}"})
+
+		InterpolateTemplateVariables([]*simplejson.Json{query}, map[string]string{"host": "server1"})
+
+		require.Equal(t, "up{job=\"$job\"}", query.Get("expr").MustString())
+	})
+}
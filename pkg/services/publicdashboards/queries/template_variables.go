@@ -0,0 +1,188 @@
+package queries
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// DashboardVariable is a template variable of a dashboard with the options saved in the dashboard
+type DashboardVariable struct {
+	Name    string
+	Type    string
+	Current string
+	Options []string
+	// Multi is true when the variable can have several values, or all of them
+	Multi bool
+}
+
+// GetDashboardVariables returns the template variables of the dashboard. The options of the query
+// variables are the ones saved with the dashboard, or the current value if none were saved.
+func GetDashboardVariables(dashboard *simplejson.Json) []DashboardVariable {
+	var variables []DashboardVariable
+
+	for _, variableObj := range dashboard.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+
+		v := DashboardVariable{
+			Name:  variable.Get("name").MustString(),
+			Type:  variable.Get("type").MustString(),
+			Multi: variable.Get("multi").MustBool() || variable.Get("includeAll").MustBool(),
+		}
+
+		current := variable.Get("current").Get("value")
+		if values, err := current.StringArray(); err == nil {
+			if len(values) == 1 {
+				v.Current = values[0]
+			} else if len(values) > 1 {
+				v.Multi = true
+			}
+		} else {
+			v.Current = current.MustString()
+		}
+
+		for _, optionObj := range variable.Get("options").MustArray() {
+			value, ok := simplejson.NewFromAny(optionObj).Get("value").Interface().(string)
+			if ok && value != "$__all" {
+				v.Options = append(v.Options, value)
+			}
+		}
+
+		if len(v.Options) == 0 {
+			query := variable.Get("query").MustString()
+			switch v.Type {
+			case "custom", "interval":
+				v.Options = splitCustomOptions(query)
+			case "constant", "textbox":
+				v.Options = []string{query}
+			default:
+				if v.Current != "" {
+					v.Options = []string{v.Current}
+				}
+			}
+		}
+
+		if v.Current == "" && (v.Type == "constant" || v.Type == "textbox") {
+			v.Current = variable.Get("query").MustString()
+		}
+
+		variables = append(variables, v)
+	}
+
+	return variables
+}
+
+// splitCustomOptions splits the options of a custom variable, like "a,b" or "text : value,other"
+func splitCustomOptions(query string) []string {
+	var options []string
+	for _, option := range strings.Split(query, ",") {
+		option = strings.TrimSpace(option)
+		if i := strings.Index(option, " : "); i >= 0 {
+			option = strings.TrimSpace(option[i+3:])
+		}
+		if option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// DefaultTemplateVariableValue is the value of an allowed variable that the viewers didn't choose
+func DefaultTemplateVariableValue(v DashboardVariable, tv TemplateVariable) string {
+	if tv.Value != "" {
+		return tv.Value
+	}
+	return v.Current
+}
+
+// AllowedTemplateVariableOptions are the values that the viewers can choose for an allowed variable
+func AllowedTemplateVariableOptions(v DashboardVariable, tv TemplateVariable) []string {
+	if tv.Fixed {
+		return []string{DefaultTemplateVariableValue(v, tv)}
+	}
+	if len(tv.Options) > 0 {
+		return tv.Options
+	}
+	return v.Options
+}
+
+// GetTemplateVariableValues returns the values of the template variables of a public dashboard: the
+// values chosen by the viewer, which must be allowed options, or the default values.
+func GetTemplateVariableValues(dashboard *simplejson.Json, allowed TemplateVariables, requested map[string]string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	for _, v := range GetDashboardVariables(dashboard) {
+		tv, ok := allowed.Get(v.Name)
+		if !ok {
+			continue
+		}
+
+		value, chosen := requested[v.Name]
+		if !chosen {
+			values[v.Name] = DefaultTemplateVariableValue(v, tv)
+			continue
+		}
+
+		if !containsString(AllowedTemplateVariableOptions(v, tv), value) {
+			return nil, ErrPublicDashboardTemplateVariableNotAllowed
+		}
+		values[v.Name] = value
+	}
+
+	for name := range requested {
+		if _, ok := values[name]; !ok {
+			return nil, ErrPublicDashboardTemplateVariableNotAllowed
+		}
+	}
+
+	return values, nil
+}
+
+// BuildPublicTemplating returns the template variables of the dashboard as the viewers of the public
+// dashboard see them: custom variables with the allowed options, so that the viewers only choose
+// between values the server accepts and nothing is queried to list them.
+func BuildPublicTemplating(dashboard *simplejson.Json, allowed TemplateVariables) []interface{} {
+	list := dashboard.Get("templating").Get("list").MustArray()
+	variables := GetDashboardVariables(dashboard)
+
+	result := make([]interface{}, 0, len(variables))
+	for i, v := range variables {
+		tv, ok := allowed.Get(v.Name)
+		if !ok {
+			continue
+		}
+		variable := simplejson.NewFromAny(list[i])
+
+		current := DefaultTemplateVariableValue(v, tv)
+		allowedOptions := AllowedTemplateVariableOptions(v, tv)
+		options := make([]interface{}, 0, len(allowedOptions))
+		for _, option := range allowedOptions {
+			options = append(options, map[string]interface{}{"text": option, "value": option, "selected": option == current})
+		}
+
+		result = append(result, map[string]interface{}{
+			"name":        v.Name,
+			"label":       variable.Get("label").MustString(),
+			"description": variable.Get("description").MustString(),
+			"hide":        variable.Get("hide").MustInt(),
+			"type":        "custom",
+			"query":       strings.Join(allowedOptions, ","),
+			"options":     options,
+			"current":     map[string]interface{}{"text": current, "value": current},
+			"multi":       false,
+			"includeAll":  false,
+		})
+	}
+
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
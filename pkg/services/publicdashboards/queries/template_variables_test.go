@@ -0,0 +1,130 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/stretchr/testify/require"
+)
+
+const dashboardWithTemplateVariables = `
+{
+  "templating": {
+    "list": [
+      {
+        "name": "region",
+        "type": "custom",
+        "query": "Europe : eu,us",
+        "current": { "text": "eu", "value": "eu" }
+      },
+      {
+        "name": "host",
+        "type": "query",
+        "label": "Host",
+        "current": { "text": "server1", "value": ["server1"] },
+        "options": [
+          { "text": "server1", "value": "server1" },
+          { "text": "server2", "value": "server2" }
+        ]
+      },
+      {
+        "name": "env",
+        "type": "constant",
+        "query": "prod"
+      },
+      {
+        "name": "job",
+        "type": "query",
+        "multi": true,
+        "current": { "text": "All", "value": "$__all" }
+      }
+    ]
+  }
+}`
+
+func TestGetDashboardVariables(t *testing.T) {
+	json, err := simplejson.NewJson([]byte(dashboardWithTemplateVariables))
+	require.NoError(t, err)
+
+	require.Equal(t, []DashboardVariable{
+		{Name: "region", Type: "custom", Current: "eu", Options: []string{"eu", "us"}},
+		{Name: "host", Type: "query", Current: "server1", Options: []string{"server1", "server2"}},
+		{Name: "env", Type: "constant", Current: "prod", Options: []string{"prod"}},
+		{Name: "job", Type: "query", Current: "$__all", Options: []string{"$__all"}, Multi: true},
+	}, GetDashboardVariables(json))
+}
+
+func TestGetTemplateVariableValues(t *testing.T) {
+	json, err := simplejson.NewJson([]byte(dashboardWithTemplateVariables))
+	require.NoError(t, err)
+	allowed := TemplateVariables{
+		{Name: "region"},
+		{Name: "host", Options: []string{"server2"}, Value: "server2"},
+		{Name: "env", Fixed: true},
+	}
+
+	t.Run("uses the default values", func(t *testing.T) {
+		values, err := GetTemplateVariableValues(json, allowed, nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"region": "eu", "host": "server2", "env": "prod"}, values)
+	})
+
+	t.Run("uses the values chosen among the options", func(t *testing.T) {
+		values, err := GetTemplateVariableValues(json, allowed, map[string]string{"region": "us"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"region": "us", "host": "server2", "env": "prod"}, values)
+	})
+
+	t.Run("rejects the values that are not allowed", func(t *testing.T) {
+		for _, requested := range []map[string]string{
+			{"region": "ap"},
+			{"host": "server1"},
+			{"env": "dev"},
+			{"job": "api"},
+			{"unknown": "value"},
+		} {
+			_, err := GetTemplateVariableValues(json, allowed, requested)
+			require.ErrorIs(t, err, ErrPublicDashboardTemplateVariableNotAllowed, requested)
+		}
+	})
+}
+
+func TestBuildPublicTemplating(t *testing.T) {
+	json, err := simplejson.NewJson([]byte(dashboardWithTemplateVariables))
+	require.NoError(t, err)
+
+	templating := BuildPublicTemplating(json, TemplateVariables{{Name: "host"}, {Name: "env", Fixed: true}})
+
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"name":        "host",
+			"label":       "Host",
+			"description": "",
+			"hide":        0,
+			"type":        "custom",
+			"query":       "server1,server2",
+			"options": []interface{}{
+				map[string]interface{}{"text": "server1", "value": "server1", "selected": true},
+				map[string]interface{}{"text": "server2", "value": "server2", "selected": false},
+			},
+			"current":    map[string]interface{}{"text": "server1", "value": "server1"},
+			"multi":      false,
+			"includeAll": false,
+		},
+		map[string]interface{}{
+			"name":        "env",
+			"label":       "",
+			"description": "",
+			"hide":        0,
+			"type":        "custom",
+			"query":       "prod",
+			"options": []interface{}{
+				map[string]interface{}{"text": "prod", "value": "prod", "selected": true},
+			},
+			"current":    map[string]interface{}{"text": "prod", "value": "prod"},
+			"multi":      false,
+			"includeAll": false,
+		},
+	}, templating)
+}
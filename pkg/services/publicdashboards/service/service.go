@@ -2,14 +2,21 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -20,6 +27,8 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util"
+	"golang.org/x/sync/singleflight"
 )
 
 // Define the Service Implementation. We're generating mock implementation
@@ -30,10 +39,19 @@ type PublicDashboardServiceImpl struct {
 	store              publicdashboards.Store
 	intervalCalculator intervalv2.Calculator
 	QueryDataService   *query.Service
+	AnnotationsRepo    annotations.Repository
+
+	// queryCache shares the results of identical panel queries between viewers, it is nil when disabled
+	queryCache *localcache.CacheService
+	queryGroup singleflight.Group
 }
 
 var LogPrefix = "publicdashboards.service"
 
+// sharedQueryTimeout bounds the queries shared between the viewers of a public dashboard,
+// which are not cancelled when the viewer that started them leaves.
+const sharedQueryTimeout = 5 * time.Minute
+
 // Gives us compile time error if the service does not adhere to the contract of
 // the interface
 var _ publicdashboards.Service = (*PublicDashboardServiceImpl)(nil)
@@ -44,14 +62,22 @@ func ProvideService(
 	cfg *setting.Cfg,
 	store publicdashboards.Store,
 	qds *query.Service,
+	anno annotations.Repository,
 ) *PublicDashboardServiceImpl {
-	return &PublicDashboardServiceImpl{
+	pd := &PublicDashboardServiceImpl{
 		log:                log.New(LogPrefix),
 		cfg:                cfg,
 		store:              store,
 		intervalCalculator: intervalv2.NewCalculator(),
 		QueryDataService:   qds,
+		AnnotationsRepo:    anno,
 	}
+
+	if cfg.PublicDashboardsQueryCacheTTL > 0 {
+		pd.queryCache = localcache.New(cfg.PublicDashboardsQueryCacheTTL, time.Minute)
+	}
+
+	return pd
 }
 
 func (pd *PublicDashboardServiceImpl) GetDashboard(ctx context.Context, dashboardUid string) (*models.Dashboard, error) {
@@ -154,14 +180,18 @@ func (pd *PublicDashboardServiceImpl) savePublicDashboardConfig(ctx context.Cont
 
 	cmd := SavePublicDashboardConfigCommand{
 		PublicDashboard: PublicDashboard{
-			Uid:          uid,
-			DashboardUid: dto.DashboardUid,
-			OrgId:        dto.OrgId,
-			IsEnabled:    dto.PublicDashboard.IsEnabled,
-			TimeSettings: dto.PublicDashboard.TimeSettings,
-			CreatedBy:    dto.UserId,
-			CreatedAt:    time.Now(),
-			AccessToken:  accessToken,
+			Uid:                  uid,
+			DashboardUid:         dto.DashboardUid,
+			OrgId:                dto.OrgId,
+			IsEnabled:            dto.PublicDashboard.IsEnabled,
+			TimeSettings:         dto.PublicDashboard.TimeSettings,
+			TemplateVariables:    dto.PublicDashboard.TemplateVariables,
+			TimeSelectionEnabled: dto.PublicDashboard.TimeSelectionEnabled,
+			MaxTimeRange:         dto.PublicDashboard.MaxTimeRange,
+			AnnotationsEnabled:   dto.PublicDashboard.AnnotationsEnabled,
+			CreatedBy:            dto.UserId,
+			CreatedAt:            time.Now(),
+			AccessToken:          accessToken,
 		},
 	}
 
@@ -178,11 +208,15 @@ func (pd *PublicDashboardServiceImpl) savePublicDashboardConfig(ctx context.Cont
 func (pd *PublicDashboardServiceImpl) updatePublicDashboardConfig(ctx context.Context, dto *SavePublicDashboardConfigDTO) (string, error) {
 	cmd := SavePublicDashboardConfigCommand{
 		PublicDashboard: PublicDashboard{
-			Uid:          dto.PublicDashboard.Uid,
-			IsEnabled:    dto.PublicDashboard.IsEnabled,
-			TimeSettings: dto.PublicDashboard.TimeSettings,
-			UpdatedBy:    dto.UserId,
-			UpdatedAt:    time.Now(),
+			Uid:                  dto.PublicDashboard.Uid,
+			IsEnabled:            dto.PublicDashboard.IsEnabled,
+			TimeSettings:         dto.PublicDashboard.TimeSettings,
+			TemplateVariables:    dto.PublicDashboard.TemplateVariables,
+			TimeSelectionEnabled: dto.PublicDashboard.TimeSelectionEnabled,
+			MaxTimeRange:         dto.PublicDashboard.MaxTimeRange,
+			AnnotationsEnabled:   dto.PublicDashboard.AnnotationsEnabled,
+			UpdatedBy:            dto.UserId,
+			UpdatedAt:            time.Now(),
 		},
 	}
 
@@ -195,6 +229,40 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, err
 	}
 
+	if pd.queryCache == nil {
+		return pd.queryData(ctx, skipCache, dashboard, publicDashboard, panelId, queryDto)
+	}
+
+	// the viewers of a public dashboard send the same queries, which are run once per cache TTL.
+	// The key has the versions of the dashboards, so that their changes are seen immediately.
+	key, err := queryCacheKey(dashboard, publicDashboard, panelId, queryDto)
+	if err != nil {
+		return nil, err
+	}
+	if res, ok := pd.queryCache.Get(key); ok {
+		return res.(*backend.QueryDataResponse), nil
+	}
+
+	res, err, _ := pd.queryGroup.Do(key, func() (interface{}, error) {
+		// the query is shared by all the viewers waiting for it, so it must not be cancelled when the first one leaves
+		ctx, cancel := context.WithTimeout(util.WithoutCancel(ctx), sharedQueryTimeout)
+		defer cancel()
+
+		res, err := pd.queryData(ctx, skipCache, dashboard, publicDashboard, panelId, queryDto)
+		if err != nil {
+			return nil, err
+		}
+		pd.queryCache.SetDefault(key, res)
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*backend.QueryDataResponse), nil
+}
+
+func (pd *PublicDashboardServiceImpl) queryData(ctx context.Context, skipCache bool, dashboard *models.Dashboard, publicDashboard *PublicDashboard, panelId int64, queryDto PublicDashboardQueryDTO) (*backend.QueryDataResponse, error) {
 	metricReq, err := pd.GetMetricRequest(ctx, dashboard, publicDashboard, panelId, queryDto)
 	if err != nil {
 		return nil, err
@@ -219,6 +287,14 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 	return res, nil
 }
 
+func queryCacheKey(dashboard *models.Dashboard, publicDashboard *PublicDashboard, panelId int64, queryDto PublicDashboardQueryDTO) (string, error) {
+	key, err := json.Marshal([]interface{}{publicDashboard.Uid, publicDashboard.UpdatedAt, dashboard.Version, panelId, queryDto})
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
 func (pd *PublicDashboardServiceImpl) GetMetricRequest(ctx context.Context, dashboard *models.Dashboard, publicDashboard *PublicDashboard, panelId int64, queryDto PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	if err := validation.ValidateQueryPublicDashboardRequest(queryDto); err != nil {
 		return dtos.MetricRequest{}, ErrPublicDashboardBadRequest
@@ -243,24 +319,34 @@ func (pd *PublicDashboardServiceImpl) GetMetricRequest(ctx context.Context, dash
 func (pd *PublicDashboardServiceImpl) buildMetricRequest(ctx context.Context, dashboard *models.Dashboard, publicDashboard *PublicDashboard, panelId int64, reqDTO PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	// group queries by panel
 	queriesByPanel := queries.GroupQueriesByPanelId(dashboard.Data)
-	queries, ok := queriesByPanel[panelId]
+	panelQueries, ok := queriesByPanel[panelId]
 	if !ok {
 		return dtos.MetricRequest{}, ErrPublicDashboardPanelNotFound
 	}
 
-	ts := publicDashboard.BuildTimeSettings(dashboard)
+	// interpolate the template variables server side, with the values chosen among the allowed options
+	variables, err := queries.GetTemplateVariableValues(dashboard.Data, publicDashboard.TemplateVariables, reqDTO.Variables)
+	if err != nil {
+		return dtos.MetricRequest{}, err
+	}
+	queries.InterpolateTemplateVariables(panelQueries, variables)
+
+	ts, err := buildTimeSettings(dashboard, publicDashboard, reqDTO.TimeRange)
+	if err != nil {
+		return dtos.MetricRequest{}, err
+	}
 
 	// determine safe resolution to query data at
 	safeInterval, safeResolution := pd.getSafeIntervalAndMaxDataPoints(reqDTO, ts)
-	for i := range queries {
-		queries[i].Set("intervalMs", safeInterval)
-		queries[i].Set("maxDataPoints", safeResolution)
+	for i := range panelQueries {
+		panelQueries[i].Set("intervalMs", safeInterval)
+		panelQueries[i].Set("maxDataPoints", safeResolution)
 	}
 
 	return dtos.MetricRequest{
 		From:    ts.From,
 		To:      ts.To,
-		Queries: queries,
+		Queries: panelQueries,
 	}, nil
 }
 
@@ -284,6 +370,130 @@ func (pd *PublicDashboardServiceImpl) BuildAnonymousUser(ctx context.Context, da
 	return anonymousUser, nil
 }
 
+// buildTimeSettings returns the time range of the queries of a public dashboard: the default time
+// range of the dashboard, or the time range chosen by the viewer when the public dashboard allows it
+func buildTimeSettings(dashboard *models.Dashboard, publicDashboard *PublicDashboard, timeRange TimeSettings) (TimeSettings, error) {
+	if !publicDashboard.TimeSelectionEnabled || (timeRange.From == "" && timeRange.To == "") {
+		return publicDashboard.BuildTimeSettings(dashboard), nil
+	}
+
+	dataTimeRange := legacydata.NewDataTimeRange(timeRange.From, timeRange.To)
+	from, err := dataTimeRange.ParseFrom()
+	if err != nil {
+		return TimeSettings{}, ErrPublicDashboardTimeRangeNotAllowed
+	}
+	to, err := dataTimeRange.ParseTo()
+	if err != nil || !from.Before(to) {
+		return TimeSettings{}, ErrPublicDashboardTimeRangeNotAllowed
+	}
+
+	if publicDashboard.MaxTimeRange != "" {
+		maxTimeRange, err := gtime.ParseDuration(publicDashboard.MaxTimeRange)
+		if err != nil {
+			return TimeSettings{}, ErrPublicDashboardInvalidMaxTimeRange
+		}
+		if to.Sub(from) > maxTimeRange {
+			return TimeSettings{}, ErrPublicDashboardTimeRangeNotAllowed
+		}
+	}
+
+	return TimeSettings{
+		From: strconv.FormatInt(from.UnixMilli(), 10),
+		To:   strconv.FormatInt(to.UnixMilli(), 10),
+	}, nil
+}
+
+// FindAnnotations returns the annotations of the annotation queries of a public dashboard on the
+// built-in Grafana data source, in the time range of the dashboard or the one chosen by the viewer
+func (pd *PublicDashboardServiceImpl) FindAnnotations(ctx context.Context, reqDTO PublicDashboardAnnotationsQueryDTO, accessToken string) ([]AnnotationEvent, error) {
+	publicDashboard, dashboard, err := pd.GetPublicDashboard(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]AnnotationEvent, 0)
+	if !publicDashboard.AnnotationsEnabled {
+		return events, nil
+	}
+
+	ts, err := buildTimeSettings(dashboard, publicDashboard, TimeSettings{From: reqDTO.From, To: reqDTO.To})
+	if err != nil {
+		return nil, err
+	}
+	from, _ := strconv.ParseInt(ts.From, 10, 64)
+	to, _ := strconv.ParseInt(ts.To, 10, 64)
+
+	annotationQueries := queries.GetAnnotationQueries(dashboard.Data)
+	anonymousUser := buildAnnotationsUser(dashboard, annotationQueries)
+
+	for _, annotationQuery := range annotationQueries {
+		itemQuery := &annotations.ItemQuery{
+			OrgId:        dashboard.OrgId,
+			From:         from,
+			To:           to,
+			Limit:        annotationQuery.Limit,
+			SignedInUser: anonymousUser,
+		}
+		if annotationQuery.Type == queries.AnnotationQueryTypeTags {
+			if len(annotationQuery.Tags) == 0 {
+				continue
+			}
+			itemQuery.Tags = annotationQuery.Tags
+			itemQuery.MatchAny = annotationQuery.MatchAny
+		} else {
+			itemQuery.DashboardId = dashboard.Id
+		}
+
+		items, err := pd.AnnotationsRepo.Find(ctx, itemQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			// the annotations of other dashboards are never shown, even when access control is disabled
+			if item.DashboardId != 0 && item.DashboardId != dashboard.Id {
+				continue
+			}
+			events = append(events, AnnotationEvent{
+				Id:          item.Id,
+				DashboardId: item.DashboardId,
+				PanelId:     item.PanelId,
+				Tags:        item.Tags,
+				IsRegion:    item.TimeEnd > 0 && item.TimeEnd != item.Time,
+				Text:        item.Text,
+				Color:       annotationQuery.Color,
+				Time:        item.Time,
+				TimeEnd:     item.TimeEnd,
+				Source:      annotationQuery.Name,
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// buildAnnotationsUser creates a user that can read the annotations of the dashboard, and the
+// annotations of the organization when the dashboard has annotation queries by tags
+func buildAnnotationsUser(dashboard *models.Dashboard, annotationQueries []queries.AnnotationQuery) *user.SignedInUser {
+	annotationScopes := []string{accesscontrol.ScopeAnnotationsTypeDashboard}
+	for _, annotationQuery := range annotationQueries {
+		if annotationQuery.Type == queries.AnnotationQueryTypeTags {
+			annotationScopes = append(annotationScopes, accesscontrol.ScopeAnnotationsTypeOrganization)
+			break
+		}
+	}
+
+	return &user.SignedInUser{
+		OrgID: dashboard.OrgId,
+		Permissions: map[int64]map[string][]string{
+			dashboard.OrgId: {
+				accesscontrol.ActionAnnotationsRead: annotationScopes,
+				dashboards.ActionDashboardsRead:     {dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid)},
+			},
+		},
+	}
+}
+
 func (pd *PublicDashboardServiceImpl) PublicDashboardEnabled(ctx context.Context, dashboardUid string) (bool, error) {
 	return pd.store.PublicDashboardEnabled(ctx, dashboardUid)
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 
 	"github.com/google/uuid"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -18,11 +19,14 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
 	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
 
@@ -669,3 +673,238 @@ func TestDashboardEnabledChanged(t *testing.T) {
 		assert.True(t, publicDashboardIsEnabledChanged(&PublicDashboard{IsEnabled: false}, &PublicDashboard{IsEnabled: true}))
 	})
 }
+
+func TestBuildMetricRequestWithTemplateVariables(t *testing.T) {
+	dashboard := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id": 1,
+				"targets": []interface{}{
+					map[string]interface{}{
+						"datasource": map[string]interface{}{"type": "mysql", "uid": "ds1"},
+						"rawSql":     "SELECT * FROM requests WHERE region = '$region' AND env = '${env}'",
+						"refId":      "A",
+					},
+				},
+			},
+		},
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"name": "region", "type": "custom", "query": "eu,us", "current": map[string]interface{}{"value": "eu"}},
+				map[string]interface{}{"name": "env", "type": "textbox", "query": "prod"},
+			},
+		},
+		"time": map[string]interface{}{"from": "now-1h", "to": "now"},
+	}))
+	publicDashboard := &PublicDashboard{
+		TemplateVariables: TemplateVariables{{Name: "region"}, {Name: "env", Fixed: true}},
+	}
+
+	service := &PublicDashboardServiceImpl{
+		log:                log.New("test.logger"),
+		intervalCalculator: intervalv2.NewCalculator(),
+	}
+
+	t.Run("interpolates the default values", func(t *testing.T) {
+		reqDTO, err := service.buildMetricRequest(context.Background(), dashboard, publicDashboard, 1, PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM requests WHERE region = 'eu' AND env = 'prod'", reqDTO.Queries[0].Get("rawSql").MustString())
+	})
+
+	t.Run("interpolates the values chosen by the viewer", func(t *testing.T) {
+		reqDTO, err := service.buildMetricRequest(context.Background(), dashboard, publicDashboard, 1, PublicDashboardQueryDTO{
+			Variables: map[string]string{"region": "us"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM requests WHERE region = 'us' AND env = 'prod'", reqDTO.Queries[0].Get("rawSql").MustString())
+	})
+
+	t.Run("returns an error when the value is not an option", func(t *testing.T) {
+		_, err := service.buildMetricRequest(context.Background(), dashboard, publicDashboard, 1, PublicDashboardQueryDTO{
+			Variables: map[string]string{"region": "' OR 1=1 --"},
+		})
+		require.ErrorIs(t, err, ErrPublicDashboardTemplateVariableNotAllowed)
+	})
+
+	t.Run("returns an error when a fixed variable is changed", func(t *testing.T) {
+		_, err := service.buildMetricRequest(context.Background(), dashboard, publicDashboard, 1, PublicDashboardQueryDTO{
+			Variables: map[string]string{"env": "dev"},
+		})
+		require.ErrorIs(t, err, ErrPublicDashboardTemplateVariableNotAllowed)
+	})
+}
+
+func TestBuildTimeSettings(t *testing.T) {
+	dashboard := &models.Dashboard{Data: dashboardData}
+
+	t.Run("returns the time range of the dashboard when time selection is disabled", func(t *testing.T) {
+		publicDashboard := &PublicDashboard{TimeSettings: defaultPubdashTimeSettings}
+		ts, err := buildTimeSettings(dashboard, publicDashboard, TimeSettings{From: "now-1h", To: "now"})
+		require.NoError(t, err)
+		assert.Equal(t, publicDashboard.BuildTimeSettings(dashboard), ts)
+	})
+
+	t.Run("returns the time range of the dashboard when the viewer didn't choose one", func(t *testing.T) {
+		publicDashboard := &PublicDashboard{TimeSettings: defaultPubdashTimeSettings, TimeSelectionEnabled: true}
+		ts, err := buildTimeSettings(dashboard, publicDashboard, TimeSettings{})
+		require.NoError(t, err)
+		assert.Equal(t, publicDashboard.BuildTimeSettings(dashboard), ts)
+	})
+
+	t.Run("returns the time range chosen by the viewer", func(t *testing.T) {
+		publicDashboard := &PublicDashboard{TimeSettings: defaultPubdashTimeSettings, TimeSelectionEnabled: true, MaxTimeRange: "2h"}
+		ts, err := buildTimeSettings(dashboard, publicDashboard, TimeSettings{From: "1660000000000", To: "1660003600000"})
+		require.NoError(t, err)
+		assert.Equal(t, TimeSettings{From: "1660000000000", To: "1660003600000"}, ts)
+	})
+
+	t.Run("returns an error when the time range is longer than the maximum", func(t *testing.T) {
+		publicDashboard := &PublicDashboard{TimeSettings: defaultPubdashTimeSettings, TimeSelectionEnabled: true, MaxTimeRange: "2h"}
+		_, err := buildTimeSettings(dashboard, publicDashboard, TimeSettings{From: "now-1d", To: "now"})
+		require.ErrorIs(t, err, ErrPublicDashboardTimeRangeNotAllowed)
+	})
+
+	t.Run("returns an error when the time range is invalid", func(t *testing.T) {
+		publicDashboard := &PublicDashboard{TimeSettings: defaultPubdashTimeSettings, TimeSelectionEnabled: true}
+		_, err := buildTimeSettings(dashboard, publicDashboard, TimeSettings{From: "now", To: "now-1h"})
+		require.ErrorIs(t, err, ErrPublicDashboardTimeRangeNotAllowed)
+
+		_, err = buildTimeSettings(dashboard, publicDashboard, TimeSettings{From: "yesterday", To: "now"})
+		require.ErrorIs(t, err, ErrPublicDashboardTimeRangeNotAllowed)
+	})
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	dashboard := &models.Dashboard{Version: 1}
+	publicDashboard := &PublicDashboard{Uid: "pubdash"}
+	queryDto := PublicDashboardQueryDTO{IntervalMs: 1000, MaxDataPoints: 100}
+
+	key, err := queryCacheKey(dashboard, publicDashboard, 1, queryDto)
+	require.NoError(t, err)
+
+	sameKey, err := queryCacheKey(dashboard, publicDashboard, 1, queryDto)
+	require.NoError(t, err)
+	assert.Equal(t, key, sameKey)
+
+	otherPanelKey, err := queryCacheKey(dashboard, publicDashboard, 2, queryDto)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, otherPanelKey)
+
+	otherVersionKey, err := queryCacheKey(&models.Dashboard{Version: 2}, publicDashboard, 1, queryDto)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, otherVersionKey)
+
+	queryDto.TimeRange = TimeSettings{From: "now-1h", To: "now"}
+	otherTimeRangeKey, err := queryCacheKey(dashboard, publicDashboard, 1, queryDto)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, otherTimeRangeKey)
+}
+
+func TestGetQueryDataResponseFromCache(t *testing.T) {
+	publicDashboard := &PublicDashboard{Uid: "pubdash", IsEnabled: true}
+	dashboard := &models.Dashboard{Uid: "mydashboard", Version: 1, Data: dashboardData}
+	fakeStore := FakePublicDashboardStore{}
+	fakeStore.On("GetPublicDashboard", mock.Anything, mock.Anything).Return(publicDashboard, dashboard, nil)
+
+	cfg := setting.NewCfg()
+	cfg.PublicDashboardsQueryCacheTTL = time.Minute
+	service := ProvideService(cfg, &fakeStore, nil, nil)
+
+	queryDto := PublicDashboardQueryDTO{IntervalMs: 1000, MaxDataPoints: 100}
+	key, err := queryCacheKey(dashboard, publicDashboard, 1, queryDto)
+	require.NoError(t, err)
+	cached := &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{}}}
+	service.queryCache.SetDefault(key, cached)
+
+	// the query data service is nil, the response can only come from the cache
+	res, err := service.GetQueryDataResponse(context.Background(), false, queryDto, 1, "abc123")
+	require.NoError(t, err)
+	assert.Same(t, cached, res)
+}
+
+type fakeAnnotationsRepo struct {
+	annotations.Repository
+	queries []*annotations.ItemQuery
+	items   []*annotations.ItemDTO
+}
+
+func (repo *fakeAnnotationsRepo) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	repo.queries = append(repo.queries, query)
+	return repo.items, nil
+}
+
+func TestFindAnnotations(t *testing.T) {
+	dashboard := &models.Dashboard{Id: 1, Uid: "dash1", OrgId: 1, Data: simplejson.NewFromAny(map[string]interface{}{
+		"time": map[string]interface{}{"from": "now-8h", "to": "now"},
+		"annotations": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{
+					"name": "Annotations & Alerts", "enable": true, "iconColor": "blue",
+					"datasource": map[string]interface{}{"type": "grafana", "uid": "-- Grafana --"},
+					"target":     map[string]interface{}{"type": "dashboard", "limit": 50},
+				},
+				map[string]interface{}{
+					"name": "Deployments", "enable": true, "iconColor": "red",
+					"datasource": map[string]interface{}{"type": "grafana", "uid": "grafana"},
+					"target":     map[string]interface{}{"type": "tags", "tags": []interface{}{"deploy"}},
+				},
+				map[string]interface{}{
+					"name": "Prometheus", "enable": true,
+					"datasource": map[string]interface{}{"type": "prometheus", "uid": "prom"},
+				},
+			},
+		},
+	})}
+
+	setup := func(publicDashboard *PublicDashboard, items []*annotations.ItemDTO) (*PublicDashboardServiceImpl, *fakeAnnotationsRepo) {
+		fakeStore := FakePublicDashboardStore{}
+		fakeStore.On("GetPublicDashboard", mock.Anything, mock.Anything).Return(publicDashboard, dashboard, nil)
+		repo := &fakeAnnotationsRepo{items: items}
+		return &PublicDashboardServiceImpl{
+			log:             log.New("test.logger"),
+			store:           &fakeStore,
+			AnnotationsRepo: repo,
+		}, repo
+	}
+
+	t.Run("returns no annotations when they are disabled", func(t *testing.T) {
+		service, repo := setup(&PublicDashboard{IsEnabled: true, TimeSettings: defaultPubdashTimeSettings}, nil)
+
+		events, err := service.FindAnnotations(context.Background(), PublicDashboardAnnotationsQueryDTO{}, "abc123")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		assert.Empty(t, repo.queries)
+	})
+
+	t.Run("returns the annotations of the Grafana annotation queries", func(t *testing.T) {
+		service, repo := setup(&PublicDashboard{IsEnabled: true, AnnotationsEnabled: true, TimeSettings: defaultPubdashTimeSettings}, []*annotations.ItemDTO{
+			{Id: 1, DashboardId: 1, PanelId: 2, Text: "on the dashboard", Time: 1, TimeEnd: 1},
+			{Id: 2, DashboardId: 0, Text: "on the organization", Tags: []string{"deploy"}, Time: 1, TimeEnd: 5},
+			{Id: 3, DashboardId: 2, Text: "on another dashboard", Time: 1},
+		})
+
+		events, err := service.FindAnnotations(context.Background(), PublicDashboardAnnotationsQueryDTO{}, "abc123")
+		require.NoError(t, err)
+
+		require.Len(t, repo.queries, 2)
+		assert.Equal(t, int64(1), repo.queries[0].DashboardId)
+		assert.Equal(t, int64(50), repo.queries[0].Limit)
+		assert.Equal(t, []string{"deploy"}, repo.queries[1].Tags)
+		assert.Equal(t, int64(0), repo.queries[1].DashboardId)
+		assert.Equal(t, []string{accesscontrol.ScopeAnnotationsTypeDashboard, accesscontrol.ScopeAnnotationsTypeOrganization},
+			repo.queries[0].SignedInUser.Permissions[1][accesscontrol.ActionAnnotationsRead])
+
+		// both queries return the same items from the fake repository
+		require.Len(t, events, 4)
+		assert.Equal(t, AnnotationEvent{Id: 1, DashboardId: 1, PanelId: 2, Text: "on the dashboard", Color: "blue", Time: 1, TimeEnd: 1, Source: "Annotations & Alerts"}, events[0])
+		assert.True(t, events[1].IsRegion)
+		assert.Equal(t, "Deployments", events[3].Source)
+	})
+
+	t.Run("returns an error when the time range is not allowed", func(t *testing.T) {
+		service, _ := setup(&PublicDashboard{IsEnabled: true, AnnotationsEnabled: true, TimeSelectionEnabled: true, MaxTimeRange: "1h", TimeSettings: defaultPubdashTimeSettings}, nil)
+
+		_, err := service.FindAnnotations(context.Background(), PublicDashboardAnnotationsQueryDTO{From: "now-1d", To: "now"}, "abc123")
+		require.ErrorIs(t, err, ErrPublicDashboardTimeRangeNotAllowed)
+	})
+}
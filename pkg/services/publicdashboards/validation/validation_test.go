@@ -41,4 +41,107 @@ func TestValidateSavePublicDashboard(t *testing.T) {
 		err := ValidateSavePublicDashboard(dto, dashboard)
 		require.NoError(t, err)
 	})
+	t.Run("Returns no validation error when the template variables are allowed", func(t *testing.T) {
+		dashboard := dashboardWithTemplateVariables(t)
+		dto := &publicdashboardModels.SavePublicDashboardConfigDTO{DashboardUid: "abc123", OrgId: 1, UserId: 1, PublicDashboard: &publicdashboardModels.PublicDashboard{
+			TemplateVariables: publicdashboardModels.TemplateVariables{
+				{Name: "region", Options: []string{"us"}, Value: "us"},
+				{Name: "host", Options: []string{"server1", "server3"}},
+				{Name: "job", Fixed: true, Value: "api"},
+			},
+		}}
+
+		err := ValidateSavePublicDashboard(dto, dashboard)
+		require.NoError(t, err)
+	})
+
+	t.Run("Returns validation error when a template variable is not allowed", func(t *testing.T) {
+		dashboard := dashboardWithTemplateVariables(t)
+		dto := &publicdashboardModels.SavePublicDashboardConfigDTO{DashboardUid: "abc123", OrgId: 1, UserId: 1, PublicDashboard: &publicdashboardModels.PublicDashboard{
+			TemplateVariables: publicdashboardModels.TemplateVariables{{Name: "region"}, {Name: "host"}},
+		}}
+
+		err := ValidateSavePublicDashboard(dto, dashboard)
+		require.ErrorIs(t, err, publicdashboardModels.ErrPublicDashboardHasTemplateVariables)
+	})
+
+	testCases := []struct {
+		name    string
+		allowed publicdashboardModels.TemplateVariables
+	}{
+		{
+			name:    "unknown variable",
+			allowed: publicdashboardModels.TemplateVariables{{Name: "region"}, {Name: "host"}, {Name: "job", Fixed: true, Value: "api"}, {Name: "unknown"}},
+		},
+		{
+			name:    "variable allowed twice",
+			allowed: publicdashboardModels.TemplateVariables{{Name: "region"}, {Name: "region"}, {Name: "host"}, {Name: "job", Fixed: true, Value: "api"}},
+		},
+		{
+			name:    "option that the custom variable doesn't have",
+			allowed: publicdashboardModels.TemplateVariables{{Name: "region", Options: []string{"ap"}}, {Name: "host"}, {Name: "job", Fixed: true, Value: "api"}},
+		},
+		{
+			name:    "default value that is not an option",
+			allowed: publicdashboardModels.TemplateVariables{{Name: "region", Options: []string{"us"}}, {Name: "host"}, {Name: "job", Fixed: true, Value: "api"}},
+		},
+		{
+			name:    "multi-value variable that is not fixed",
+			allowed: publicdashboardModels.TemplateVariables{{Name: "region"}, {Name: "host"}, {Name: "job"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("Returns validation error for "+tc.name, func(t *testing.T) {
+			dashboard := dashboardWithTemplateVariables(t)
+			dto := &publicdashboardModels.SavePublicDashboardConfigDTO{DashboardUid: "abc123", OrgId: 1, UserId: 1, PublicDashboard: &publicdashboardModels.PublicDashboard{
+				TemplateVariables: tc.allowed,
+			}}
+
+			err := ValidateSavePublicDashboard(dto, dashboard)
+			require.ErrorIs(t, err, publicdashboardModels.ErrPublicDashboardInvalidTemplateVariable)
+		})
+	}
+
+	t.Run("Returns validation error for data source variables", func(t *testing.T) {
+		dashboardData, _ := simplejson.NewJson([]byte(`{"templating": {"list": [{"name": "ds", "type": "datasource", "current": {"value": "prom"}}]}}`))
+		dashboard := models.NewDashboardFromJson(dashboardData)
+		dto := &publicdashboardModels.SavePublicDashboardConfigDTO{DashboardUid: "abc123", OrgId: 1, UserId: 1, PublicDashboard: &publicdashboardModels.PublicDashboard{
+			TemplateVariables: publicdashboardModels.TemplateVariables{{Name: "ds", Fixed: true}},
+		}}
+
+		err := ValidateSavePublicDashboard(dto, dashboard)
+		require.ErrorIs(t, err, publicdashboardModels.ErrPublicDashboardInvalidTemplateVariable)
+	})
+
+	t.Run("Returns validation error when the maximum time range is invalid", func(t *testing.T) {
+		dashboard := models.NewDashboardFromJson(simplejson.New())
+		dto := &publicdashboardModels.SavePublicDashboardConfigDTO{DashboardUid: "abc123", OrgId: 1, UserId: 1, PublicDashboard: &publicdashboardModels.PublicDashboard{
+			TimeSelectionEnabled: true,
+			MaxTimeRange:         "a week",
+		}}
+
+		err := ValidateSavePublicDashboard(dto, dashboard)
+		require.ErrorIs(t, err, publicdashboardModels.ErrPublicDashboardInvalidMaxTimeRange)
+
+		dto.PublicDashboard.MaxTimeRange = "7d"
+		err = ValidateSavePublicDashboard(dto, dashboard)
+		require.NoError(t, err)
+	})
+}
+
+func dashboardWithTemplateVariables(t *testing.T) *models.Dashboard {
+	t.Helper()
+
+	dashboardData, err := simplejson.NewJson([]byte(`{
+		"templating": {
+			"list": [
+				{"name": "region", "type": "custom", "query": "eu,us", "current": {"value": "eu"}},
+				{"name": "host", "type": "query", "current": {"value": "server1"}},
+				{"name": "job", "type": "query", "multi": true, "current": {"value": ["api", "web"]}}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	return models.NewDashboardFromJson(dashboardData)
 }
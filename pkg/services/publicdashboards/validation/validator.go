@@ -3,22 +3,94 @@ package validation
 import (
 	"fmt"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana/pkg/models"
 	publicDashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/queries"
 )
 
 func ValidateSavePublicDashboard(dto *publicDashboardModels.SavePublicDashboardConfigDTO, dashboard *models.Dashboard) error {
-	if hasTemplateVariables(dashboard) {
-		return publicDashboardModels.ErrPublicDashboardHasTemplateVariables
+	var allowed publicDashboardModels.TemplateVariables
+	if dto.PublicDashboard != nil {
+		allowed = dto.PublicDashboard.TemplateVariables
+
+		if dto.PublicDashboard.MaxTimeRange != "" {
+			if _, err := gtime.ParseDuration(dto.PublicDashboard.MaxTimeRange); err != nil {
+				return publicDashboardModels.ErrPublicDashboardInvalidMaxTimeRange
+			}
+		}
+	}
+
+	return validateTemplateVariables(queries.GetDashboardVariables(dashboard.Data), allowed)
+}
+
+// validateTemplateVariables checks that all the template variables of the dashboard are allowed, and
+// that the viewers can only choose between values known when the public dashboard is saved.
+func validateTemplateVariables(variables []queries.DashboardVariable, allowed publicDashboardModels.TemplateVariables) error {
+	byName := make(map[string]queries.DashboardVariable, len(variables))
+	for _, v := range variables {
+		if _, ok := allowed.Get(v.Name); !ok {
+			return publicDashboardModels.ErrPublicDashboardHasTemplateVariables
+		}
+		byName[v.Name] = v
+	}
+
+	seen := make(map[string]bool, len(allowed))
+	for _, tv := range allowed {
+		v, ok := byName[tv.Name]
+		if !ok {
+			return invalidTemplateVariable(tv.Name, "not found in the dashboard")
+		}
+		if seen[tv.Name] {
+			return invalidTemplateVariable(tv.Name, "allowed more than once")
+		}
+		seen[tv.Name] = true
+
+		switch v.Type {
+		case "datasource", "adhoc":
+			return invalidTemplateVariable(tv.Name, fmt.Sprintf("%s variables are not supported", v.Type))
+		}
+		if v.Multi && (!tv.Fixed || tv.Value == "") {
+			return invalidTemplateVariable(tv.Name, "variables with several values must be fixed to a single value")
+		}
+		if tv.Fixed {
+			continue
+		}
+
+		// the options of custom, interval and constant variables are known, while the options of
+		// the other variables are whatever the dashboard owner allows
+		switch v.Type {
+		case "custom", "interval", "constant":
+			for _, option := range tv.Options {
+				if !containsString(v.Options, option) {
+					return invalidTemplateVariable(tv.Name, fmt.Sprintf("%q is not an option of the variable", option))
+				}
+			}
+		}
+
+		options := queries.AllowedTemplateVariableOptions(v, tv)
+		if len(options) == 0 {
+			return invalidTemplateVariable(tv.Name, "no options to choose from")
+		}
+		if !containsString(options, queries.DefaultTemplateVariableValue(v, tv)) {
+			return invalidTemplateVariable(tv.Name, "the default value is not one of the options")
+		}
 	}
 
 	return nil
 }
 
-func hasTemplateVariables(dashboard *models.Dashboard) bool {
-	templateVariables := dashboard.Data.Get("templating").Get("list").MustArray()
+func invalidTemplateVariable(name string, reason string) error {
+	return fmt.Errorf("%w: %s: %s", publicDashboardModels.ErrPublicDashboardInvalidTemplateVariable, name, reason)
+}
 
-	return len(templateVariables) > 0
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func ValidateQueryPublicDashboardRequest(req publicDashboardModels.PublicDashboardQueryDTO) error {
@@ -1,6 +1,7 @@
 package queryhistory
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -20,6 +21,11 @@ func (s *QueryHistoryService) registerAPIEndpoints() {
 		entities.Post("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.starHandler))
 		entities.Delete("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.unstarHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, routing.Wrap(s.patchCommentHandler))
+		entities.Put("/tags/:uid", middleware.ReqSignedIn, routing.Wrap(s.updateTagsHandler))
+		entities.Post("/share/:uid", middleware.ReqSignedIn, routing.Wrap(s.shareHandler))
+		entities.Delete("/share/:uid", middleware.ReqSignedIn, routing.Wrap(s.unshareHandler))
+		entities.Get("/retention", middleware.ReqOrgAdmin, routing.Wrap(s.getRetentionHandler))
+		entities.Put("/retention", middleware.ReqOrgAdmin, routing.Wrap(s.setRetentionHandler))
 		// Remove migrate endpoint in Grafana v10 as breaking change
 		entities.Post("/migrate", middleware.ReqSignedIn, routing.Wrap(s.migrateHandler))
 	})
@@ -69,6 +75,8 @@ func (s *QueryHistoryService) searchHandler(c *models.ReqContext) response.Respo
 		DatasourceUIDs: c.QueryStrings("datasourceUid"),
 		SearchString:   c.Query("searchString"),
 		OnlyStarred:    c.QueryBoolWithDefault("onlyStarred", false),
+		OnlyShared:     c.QueryBoolWithDefault("onlyShared", false),
+		Tags:           c.QueryStrings("tag"),
 		Sort:           c.Query("sort"),
 		Page:           c.QueryInt("page"),
 		Limit:          c.QueryInt("limit"),
@@ -189,6 +197,155 @@ func (s *QueryHistoryService) unstarHandler(c *models.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
 }
 
+// swagger:route PUT /query-history/tags/{query_history_uid} query_history updateQueryTags
+//
+// Update tags of query in query history.
+//
+// Replaces the tags of query in query history as specified by the UID.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) updateTagsHandler(c *models.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := UpdateQueryTagsInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.UpdateQueryTagsInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		return queryHistoryErrorResponse(err, "Failed to update tags of query in query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// swagger:route POST /query-history/share/{query_history_uid} query_history shareQuery
+//
+// Share query in query history.
+//
+// Shares query in query history as specified by the UID with a team of the user, or with the whole organization when no team is set.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *QueryHistoryService) shareHandler(c *models.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := ShareQueryInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.ShareQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		return queryHistoryErrorResponse(err, "Failed to share query in query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// swagger:route DELETE /query-history/share/{query_history_uid} query_history unshareQuery
+//
+// Stop sharing query in query history.
+//
+// Stops sharing query in query history as specified by the UID with a team, or with the whole organization when no team is set.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) unshareHandler(c *models.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	query, err := s.UnshareQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, c.QueryInt64("teamId"))
+	if err != nil {
+		return queryHistoryErrorResponse(err, "Failed to stop sharing query in query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// swagger:route GET /query-history/retention query_history getQueryHistoryRetention
+//
+// Get query history retention.
+//
+// Returns the retention days and row limit of the query history of the organization. Zero values use the server defaults.
+//
+// Responses:
+// 200: getQueryHistoryRetentionResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *QueryHistoryService) getRetentionHandler(c *models.ReqContext) response.Response {
+	retention, err := s.GetQueryHistoryRetention(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get query history retention", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryRetentionResponse{Result: retention})
+}
+
+// swagger:route PUT /query-history/retention query_history setQueryHistoryRetention
+//
+// Update query history retention.
+//
+// Updates the retention days and row limit of the query history of the organization. Starred and shared queries are always kept.
+//
+// Responses:
+// 200: getQueryHistoryRetentionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *QueryHistoryService) setRetentionHandler(c *models.ReqContext) response.Response {
+	cmd := QueryHistoryRetentionDTO{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	retention, err := s.SetQueryHistoryRetention(c.Req.Context(), c.OrgID, cmd)
+	if err != nil {
+		return queryHistoryErrorResponse(err, "Failed to update query history retention")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryRetentionResponse{Result: retention})
+}
+
+func queryHistoryErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrQueryNotFound), errors.Is(err, ErrSharedQueryNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrInvalidTag), errors.Is(err, ErrInvalidRetention), errors.Is(err, ErrTeamNotFound):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrNotTeamMember):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, ErrQueryAlreadyShared):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
 // swagger:route POST /query-history/migrate query_history migrateQueries
 //
 // Migrate queries to query history.
@@ -214,7 +371,7 @@ func (s *QueryHistoryService) migrateHandler(c *models.ReqContext) response.Resp
 	return response.JSON(http.StatusOK, QueryHistoryMigrationResponse{Message: "Query history successfully migrated", TotalCount: totalCount, StarredCount: starredCount})
 }
 
-// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery
+// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery updateQueryTags shareQuery unshareQuery
 type QueryHistoryByUID struct {
 	// in:path
 	// required:true
@@ -237,6 +394,16 @@ type SearchQueriesParams struct {
 	// in:query
 	// required: false
 	OnlyStarred bool `json:"onlyStarred"`
+	// Flag indicating if the queries shared with the user should be returned instead of the queries of the user
+	// in:query
+	// required: false
+	OnlyShared bool `json:"onlyShared"`
+	// List of tags the queries must have
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: multi
+	Tag []string `json:"tag"`
 	// Sort method
 	// in:query
 	// required: false
@@ -275,6 +442,35 @@ type PatchQueryCommentParams struct {
 	Body PatchQueryCommentInQueryHistoryCommand `json:"body"`
 }
 
+// swagger:parameters updateQueryTags
+type UpdateQueryTagsParams struct {
+	// in:body
+	// required:true
+	Body UpdateQueryTagsInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:parameters shareQuery
+type ShareQueryParams struct {
+	// in:body
+	// required:true
+	Body ShareQueryInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:parameters unshareQuery
+type UnshareQueryParams struct {
+	// ID of the team the query is shared with, the share with the whole organization is removed when it is not set
+	// in:query
+	// required: false
+	TeamID int64 `json:"teamId"`
+}
+
+// swagger:parameters setQueryHistoryRetention
+type SetQueryHistoryRetentionParams struct {
+	// in:body
+	// required:true
+	Body QueryHistoryRetentionDTO `json:"body"`
+}
+
 // swagger:parameters migrateQueries
 type MigrateQueriesParams struct {
 	// in:body
@@ -305,3 +501,9 @@ type GetQueryHistoryMigrationResponse struct {
 	// in: body
 	Body QueryHistoryMigrationResponse `json:"body"`
 }
+
+// swagger:response getQueryHistoryRetentionResponse
+type GetQueryHistoryRetentionResponse struct {
+	// in: body
+	Body QueryHistoryRetentionResponse `json:"body"`
+}
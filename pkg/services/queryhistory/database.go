@@ -3,9 +3,12 @@ package queryhistory
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -32,13 +35,15 @@ func (s QueryHistoryService) createQuery(ctx context.Context, user *user.SignedI
 	}
 
 	dto := QueryHistoryDTO{
-		UID:           queryHistory.UID,
-		DatasourceUID: queryHistory.DatasourceUID,
-		CreatedBy:     queryHistory.CreatedBy,
-		CreatedAt:     queryHistory.CreatedAt,
-		Comment:       queryHistory.Comment,
-		Queries:       queryHistory.Queries,
-		Starred:       false,
+		UID:             queryHistory.UID,
+		DatasourceUID:   queryHistory.DatasourceUID,
+		CreatedBy:       queryHistory.CreatedBy,
+		CreatedAt:       queryHistory.CreatedAt,
+		Comment:         queryHistory.Comment,
+		Queries:         queryHistory.Queries,
+		Starred:         false,
+		Tags:            []string{},
+		SharedWithTeams: []int64{},
 	}
 
 	return dto, nil
@@ -75,7 +80,7 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 			query_history.comment,
 			query_history.queries,
 		`)
-		writeStarredSQL(query, s.SQLStore, &dtosBuilder)
+		writeStarredSQL(query, user, s.SQLStore, &dtosBuilder)
 		writeFiltersSQL(query, user, s.SQLStore, &dtosBuilder)
 		writeSortSQL(query, s.SQLStore, &dtosBuilder)
 		writeLimitSQL(query, s.SQLStore, &dtosBuilder)
@@ -85,11 +90,14 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 		if err != nil {
 			return err
 		}
+		if err := fillQueryDetails(session, user.OrgID, dtos); err != nil {
+			return err
+		}
 
 		countBuilder := sqlstore.SQLBuilder{}
 		countBuilder.Write(`SELECT
 		`)
-		writeStarredSQL(query, s.SQLStore, &countBuilder)
+		writeStarredSQL(query, user, s.SQLStore, &countBuilder)
 		writeFiltersSQL(query, user, s.SQLStore, &countBuilder)
 		err = session.SQL(countBuilder.GetSQLString(), countBuilder.GetParams()...).Find(&allQueries)
 		return err
@@ -127,6 +135,13 @@ func (s QueryHistoryService) deleteQuery(ctx context.Context, user *user.SignedI
 			return ErrQueryNotFound
 		}
 
+		if _, err := session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID).Delete(&QueryHistoryTag{}); err != nil {
+			return err
+		}
+		if _, err := session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID).Delete(&QueryHistoryShare{}); err != nil {
+			return err
+		}
+
 		queryID = id
 		return nil
 	})
@@ -176,7 +191,7 @@ func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.S
 		Starred:       isStarred,
 	}
 
-	return dto, nil
+	return s.withQueryDetails(ctx, user.OrgID, dto)
 }
 
 // starQuery adds query into query_history_star table together with user_id and org_id
@@ -226,7 +241,7 @@ func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInU
 		Starred:       isStarred,
 	}
 
-	return dto, nil
+	return s.withQueryDetails(ctx, user.OrgID, dto)
 }
 
 // unstarQuery deletes query with with user_id and org_id from query_history_star table
@@ -269,7 +284,7 @@ func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedI
 		Starred:       isStarred,
 	}
 
-	return dto, nil
+	return s.withQueryDetails(ctx, user.OrgID, dto)
 }
 
 // migrateQueries adds multiple queries into query history
@@ -343,6 +358,8 @@ func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan i
 					LEFT JOIN query_history_star
 					ON query_history_star.query_uid = query_history.uid
 					WHERE query_history_star.query_uid IS NULL
+					AND query_history.uid NOT IN (SELECT query_uid FROM query_history_share)
+					AND query_history.org_id NOT IN (SELECT org_id FROM query_history_retention WHERE retention_days > 0)
 					AND query_history.created_at <= ?
 					ORDER BY query_history.id ASC
 					LIMIT 10000
@@ -359,7 +376,7 @@ func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan i
 			return err
 		}
 
-		return nil
+		return deleteOrphanedTags(session, rowsCount)
 	})
 
 	if err != nil {
@@ -407,6 +424,7 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 							LEFT JOIN query_history_star
 							ON query_history_star.query_uid = query_history.uid
 							WHERE query_history_star.query_uid IS NULL
+							AND query_history.uid NOT IN (SELECT query_uid FROM query_history_share)
 							ORDER BY query_history.id ASC
 							LIMIT ?
 						) AS q
@@ -427,6 +445,9 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 			if err != nil {
 				return err
 			}
+			if !starredQueries {
+				return deleteOrphanedTags(session, deletedRowsCount)
+			}
 		}
 		return nil
 	})
@@ -436,3 +457,382 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 
 	return int(deletedRowsCount), nil
 }
+
+// enforceOrgRetention is run in scheduled cleanup and it applies the retention days and row limits of the orgs
+// that changed the defaults.  Starred and shared queries are kept like in the global cleanup
+func (s QueryHistoryService) enforceOrgRetention(ctx context.Context) (int, error) {
+	var deletedRowsCount int64
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		retentions := make([]QueryHistoryRetention, 0)
+		if err := session.Find(&retentions); err != nil {
+			return err
+		}
+
+		for _, retention := range retentions {
+			if retention.RetentionDays > 0 {
+				olderThan := time.Now().Add(-time.Hour * 24 * time.Duration(retention.RetentionDays)).Unix()
+				sql := `DELETE 
+					FROM query_history 
+					WHERE uid IN (
+						SELECT uid FROM (
+							SELECT uid FROM query_history
+							LEFT JOIN query_history_star
+							ON query_history_star.query_uid = query_history.uid
+							WHERE query_history_star.query_uid IS NULL
+							AND query_history.uid NOT IN (SELECT query_uid FROM query_history_share)
+							AND query_history.org_id = ?
+							AND query_history.created_at <= ?
+							ORDER BY query_history.id ASC
+							LIMIT 10000
+						) AS q
+					)`
+				res, err := session.Exec(sql, retention.OrgID, strconv.FormatInt(olderThan, 10))
+				if err != nil {
+					return err
+				}
+				count, err := res.RowsAffected()
+				if err != nil {
+					return err
+				}
+				deletedRowsCount += count
+			}
+
+			if retention.RowLimit > 0 {
+				rowsCount, err := session.Where("org_id = ?", retention.OrgID).Count(&QueryHistory{})
+				if err != nil {
+					return err
+				}
+
+				countRowsToDelete := rowsCount - int64(retention.RowLimit)
+				if countRowsToDelete <= 0 {
+					continue
+				}
+				if countRowsToDelete > 10000 {
+					countRowsToDelete = 10000
+				}
+
+				sql := `DELETE 
+					FROM query_history 
+					WHERE uid IN (
+						SELECT uid FROM (
+							SELECT uid FROM query_history
+							LEFT JOIN query_history_star
+							ON query_history_star.query_uid = query_history.uid
+							WHERE query_history_star.query_uid IS NULL
+							AND query_history.uid NOT IN (SELECT query_uid FROM query_history_share)
+							AND query_history.org_id = ?
+							ORDER BY query_history.id ASC
+							LIMIT ?
+						) AS q
+					)`
+				res, err := session.Exec(sql, retention.OrgID, strconv.FormatInt(countRowsToDelete, 10))
+				if err != nil {
+					return err
+				}
+				count, err := res.RowsAffected()
+				if err != nil {
+					return err
+				}
+				deletedRowsCount += count
+			}
+		}
+
+		return deleteOrphanedTags(session, deletedRowsCount)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deletedRowsCount), nil
+}
+
+// deleteOrphanedTags removes the tags of the queries deleted by the cleanup
+func deleteOrphanedTags(session *sqlstore.DBSession, deletedRowsCount int64) error {
+	if deletedRowsCount == 0 {
+		return nil
+	}
+	_, err := session.Exec("DELETE FROM query_history_tag WHERE query_uid NOT IN (SELECT uid FROM query_history)")
+	return err
+}
+
+// updateQueryTags replaces the tags of a query of the user
+func (s QueryHistoryService) updateQueryTags(ctx context.Context, user *user.SignedInUser, UID string, cmd UpdateQueryTagsInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	tags, err := normalizeTags(cmd.Tags)
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	var dto QueryHistoryDTO
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		var queryHistory QueryHistory
+		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		if _, err := session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID).Delete(&QueryHistoryTag{}); err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if _, err := session.Insert(&QueryHistoryTag{OrgID: user.OrgID, QueryUID: UID, Term: tag}); err != nil {
+				return err
+			}
+		}
+
+		dto, err = newQueryDTO(session, user, queryHistory)
+		return err
+	})
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	return dto, nil
+}
+
+// shareQuery shares a query of the user with one of their teams, or with the org when no team is set
+func (s QueryHistoryService) shareQuery(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	var dto QueryHistoryDTO
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		var queryHistory QueryHistory
+		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		if cmd.TeamID != 0 {
+			exists, err := session.Table("team").Where("org_id = ? AND id = ?", user.OrgID, cmd.TeamID).Exist()
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return ErrTeamNotFound
+			}
+
+			// org admins can share with any team
+			if !user.HasRole(org.RoleAdmin) {
+				member, err := session.Table("team_member").Where("org_id = ? AND team_id = ? AND user_id = ?", user.OrgID, cmd.TeamID, user.UserID).Exist()
+				if err != nil {
+					return err
+				}
+				if !member {
+					return ErrNotTeamMember
+				}
+			}
+		}
+
+		_, err = session.Insert(&QueryHistoryShare{
+			OrgID:    user.OrgID,
+			QueryUID: UID,
+			TeamID:   cmd.TeamID,
+			SharedBy: user.UserID,
+			Created:  time.Now().Unix(),
+		})
+		if err != nil {
+			if s.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ErrQueryAlreadyShared
+			}
+			return err
+		}
+
+		dto, err = newQueryDTO(session, user, queryHistory)
+		return err
+	})
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	return dto, nil
+}
+
+// unshareQuery stops sharing a query of the user with a team, or with the org when the team is 0
+func (s QueryHistoryService) unshareQuery(ctx context.Context, user *user.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error) {
+	var dto QueryHistoryDTO
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		var queryHistory QueryHistory
+		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		id, err := session.Where("org_id = ? AND query_uid = ? AND team_id = ?", user.OrgID, UID, teamID).Delete(&QueryHistoryShare{})
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			return ErrSharedQueryNotFound
+		}
+
+		dto, err = newQueryDTO(session, user, queryHistory)
+		return err
+	})
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	return dto, nil
+}
+
+// getRetention returns the retention of the org, zero values use the defaults
+func (s QueryHistoryService) getRetention(ctx context.Context, orgID int64) (QueryHistoryRetentionDTO, error) {
+	var retention QueryHistoryRetention
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		_, err := session.Where("org_id = ?", orgID).Get(&retention)
+		return err
+	})
+	if err != nil {
+		return QueryHistoryRetentionDTO{}, err
+	}
+
+	return QueryHistoryRetentionDTO{
+		RetentionDays: retention.RetentionDays,
+		RowLimit:      retention.RowLimit,
+	}, nil
+}
+
+// setRetention saves the retention of the org, the org goes back to the defaults when both values are 0
+func (s QueryHistoryService) setRetention(ctx context.Context, orgID int64, cmd QueryHistoryRetentionDTO) (QueryHistoryRetentionDTO, error) {
+	if cmd.RetentionDays < 0 || cmd.RowLimit < 0 {
+		return QueryHistoryRetentionDTO{}, ErrInvalidRetention
+	}
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		if cmd.RetentionDays == 0 && cmd.RowLimit == 0 {
+			_, err := session.Where("org_id = ?", orgID).Delete(&QueryHistoryRetention{})
+			return err
+		}
+
+		var retention QueryHistoryRetention
+		exists, err := session.Where("org_id = ?", orgID).Get(&retention)
+		if err != nil {
+			return err
+		}
+
+		retention.OrgID = orgID
+		retention.RetentionDays = cmd.RetentionDays
+		retention.RowLimit = cmd.RowLimit
+		retention.Updated = time.Now().Unix()
+		if exists {
+			_, err = session.ID(retention.ID).Cols("retention_days", "row_limit", "updated").Update(&retention)
+		} else {
+			_, err = session.Insert(&retention)
+		}
+		return err
+	})
+	if err != nil {
+		return QueryHistoryRetentionDTO{}, err
+	}
+
+	return cmd, nil
+}
+
+// withQueryDetails adds the tags and shares to a query
+func (s QueryHistoryService) withQueryDetails(ctx context.Context, orgID int64, dto QueryHistoryDTO) (QueryHistoryDTO, error) {
+	dtos := []QueryHistoryDTO{dto}
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		return fillQueryDetails(session, orgID, dtos)
+	})
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+	return dtos[0], nil
+}
+
+func newQueryDTO(session *sqlstore.DBSession, user *user.SignedInUser, queryHistory QueryHistory) (QueryHistoryDTO, error) {
+	starred, err := session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserID, queryHistory.UID).Exist()
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	dtos := []QueryHistoryDTO{{
+		UID:           queryHistory.UID,
+		DatasourceUID: queryHistory.DatasourceUID,
+		CreatedBy:     queryHistory.CreatedBy,
+		CreatedAt:     queryHistory.CreatedAt,
+		Comment:       queryHistory.Comment,
+		Queries:       queryHistory.Queries,
+		Starred:       starred,
+	}}
+	if err := fillQueryDetails(session, user.OrgID, dtos); err != nil {
+		return QueryHistoryDTO{}, err
+	}
+	return dtos[0], nil
+}
+
+// fillQueryDetails loads the tags and shares of the queries
+func fillQueryDetails(session *sqlstore.DBSession, orgID int64, dtos []QueryHistoryDTO) error {
+	if len(dtos) == 0 {
+		return nil
+	}
+
+	uids := make([]string, 0, len(dtos))
+	byUID := make(map[string]*QueryHistoryDTO, len(dtos))
+	for i := range dtos {
+		dtos[i].Tags = []string{}
+		dtos[i].SharedWithTeams = []int64{}
+		uids = append(uids, dtos[i].UID)
+		byUID[dtos[i].UID] = &dtos[i]
+	}
+
+	tags := make([]QueryHistoryTag, 0)
+	if err := session.Where("org_id = ?", orgID).In("query_uid", uids).OrderBy("term ASC").Find(&tags); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if dto, ok := byUID[tag.QueryUID]; ok {
+			dto.Tags = append(dto.Tags, tag.Term)
+		}
+	}
+
+	shares := make([]QueryHistoryShare, 0)
+	if err := session.Where("org_id = ?", orgID).In("query_uid", uids).OrderBy("team_id ASC").Find(&shares); err != nil {
+		return err
+	}
+	for _, share := range shares {
+		dto, ok := byUID[share.QueryUID]
+		if !ok {
+			continue
+		}
+		if share.TeamID == 0 {
+			dto.SharedWithOrg = true
+		} else {
+			dto.SharedWithTeams = append(dto.SharedWithTeams, share.TeamID)
+		}
+	}
+	return nil
+}
+
+// normalizeTags trims and sorts the tags and removes the duplicates
+func normalizeTags(tags []string) ([]string, error) {
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag == "" || len(tag) > 50 {
+			return nil, ErrInvalidTag
+		}
+	}
+	result := uniqueTags(tags)
+	sort.Strings(result)
+	return result, nil
+}
+
+func uniqueTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
@@ -10,6 +10,12 @@ var (
 	ErrQueryNotFound        = errors.New("query in query history not found")
 	ErrStarredQueryNotFound = errors.New("starred query not found")
 	ErrQueryAlreadyStarred  = errors.New("query was already starred")
+	ErrQueryAlreadyShared   = errors.New("query was already shared")
+	ErrSharedQueryNotFound  = errors.New("shared query not found")
+	ErrTeamNotFound         = errors.New("team not found")
+	ErrNotTeamMember        = errors.New("queries can only be shared with the teams of the user")
+	ErrInvalidTag           = errors.New("tags must have between 1 and 50 characters")
+	ErrInvalidRetention     = errors.New("retention days and row limit can not be negative")
 )
 
 // QueryHistory is the model for query history definitions
@@ -31,15 +37,45 @@ type QueryHistoryStar struct {
 	UserID   int64  `xorm:"user_id"`
 }
 
+// QueryHistoryTag is the model for query history tag definitions
+type QueryHistoryTag struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	OrgID    int64  `xorm:"org_id"`
+	QueryUID string `xorm:"query_uid"`
+	Term     string
+}
+
+// QueryHistoryShare is the model for sharing a query with a team, a share without team is shared with the org
+type QueryHistoryShare struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	OrgID    int64  `xorm:"org_id"`
+	QueryUID string `xorm:"query_uid"`
+	TeamID   int64  `xorm:"team_id"`
+	SharedBy int64  `xorm:"shared_by"`
+	Created  int64
+}
+
+// QueryHistoryRetention is the model for the retention of the queries of an org, zero values use the defaults
+type QueryHistoryRetention struct {
+	ID            int64 `xorm:"pk autoincr 'id'"`
+	OrgID         int64 `xorm:"org_id"`
+	RetentionDays int   `xorm:"retention_days"`
+	RowLimit      int   `xorm:"row_limit"`
+	Updated       int64
+}
+
 type SearchInQueryHistoryQuery struct {
 	DatasourceUIDs []string `json:"datasourceUids"`
 	SearchString   string   `json:"searchString"`
 	OnlyStarred    bool     `json:"onlyStarred"`
-	Sort           string   `json:"sort"`
-	Page           int      `json:"page"`
-	Limit          int      `json:"limit"`
-	From           int64    `json:"from"`
-	To             int64    `json:"to"`
+	// Search the queries shared with the user instead of the queries of the user
+	OnlyShared bool     `json:"onlyShared"`
+	Tags       []string `json:"tags"`
+	Sort       string   `json:"sort"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	From       int64    `json:"from"`
+	To         int64    `json:"to"`
 }
 
 type QueryHistoryDTO struct {
@@ -50,6 +86,10 @@ type QueryHistoryDTO struct {
 	Comment       string           `json:"comment"`
 	Queries       *simplejson.Json `json:"queries"`
 	Starred       bool             `json:"starred"`
+	Tags          []string         `json:"tags" xorm:"-"`
+	SharedWithOrg bool             `json:"sharedWithOrg" xorm:"-"`
+	// IDs of the teams the query is shared with
+	SharedWithTeams []int64 `json:"sharedWithTeams" xorm:"-"`
 }
 
 // QueryHistoryResponse is a response struct for QueryHistoryDTO
@@ -74,6 +114,11 @@ type QueryHistoryDeleteQueryResponse struct {
 	Message string `json:"message"`
 }
 
+// QueryHistoryRetentionResponse is the response struct for the retention of the queries of an organization
+type QueryHistoryRetentionResponse struct {
+	Result QueryHistoryRetentionDTO `json:"result"`
+}
+
 type QueryToMigrate struct {
 	DatasourceUID string           `json:"datasourceUid"`
 	Queries       *simplejson.Json `json:"queries"`
@@ -112,3 +157,26 @@ type MigrateQueriesToQueryHistoryCommand struct {
 	// Array of queries to store in query history.
 	Queries []QueryToMigrate `json:"queries"`
 }
+
+// UpdateQueryTagsInQueryHistoryCommand is the command for replacing the tags of a query in query history
+// swagger:model
+type UpdateQueryTagsInQueryHistoryCommand struct {
+	// Tags of the query
+	Tags []string `json:"tags"`
+}
+
+// ShareQueryInQueryHistoryCommand is the command for sharing a query in query history
+// swagger:model
+type ShareQueryInQueryHistoryCommand struct {
+	// ID of the team to share the query with, the query is shared with the whole organization when it is not set
+	TeamID int64 `json:"teamId"`
+}
+
+// QueryHistoryRetentionDTO is the retention of the queries of an organization
+// swagger:model
+type QueryHistoryRetentionDTO struct {
+	// Days the queries are kept, the default is used when it is 0
+	RetentionDays int `json:"retentionDays"`
+	// Maximum number of queries kept for the organization, there is no limit when it is 0
+	RowLimit int `json:"rowLimit"`
+}
@@ -36,6 +36,12 @@ type Service interface {
 	MigrateQueriesToQueryHistory(ctx context.Context, user *user.SignedInUser, cmd MigrateQueriesToQueryHistoryCommand) (int, int, error)
 	DeleteStaleQueriesInQueryHistory(ctx context.Context, olderThan int64) (int, error)
 	EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error)
+	UpdateQueryTagsInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd UpdateQueryTagsInQueryHistoryCommand) (QueryHistoryDTO, error)
+	ShareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error)
+	UnshareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error)
+	GetQueryHistoryRetention(ctx context.Context, orgID int64) (QueryHistoryRetentionDTO, error)
+	SetQueryHistoryRetention(ctx context.Context, orgID int64, cmd QueryHistoryRetentionDTO) (QueryHistoryRetentionDTO, error)
+	EnforceOrgRetentionInQueryHistory(ctx context.Context) (int, error)
 }
 
 type QueryHistoryService struct {
@@ -80,3 +86,27 @@ func (s QueryHistoryService) DeleteStaleQueriesInQueryHistory(ctx context.Contex
 func (s QueryHistoryService) EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error) {
 	return s.enforceQueryHistoryRowLimit(ctx, limit, starredQueries)
 }
+
+func (s QueryHistoryService) UpdateQueryTagsInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd UpdateQueryTagsInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.updateQueryTags(ctx, user, UID, cmd)
+}
+
+func (s QueryHistoryService) ShareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.shareQuery(ctx, user, UID, cmd)
+}
+
+func (s QueryHistoryService) UnshareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error) {
+	return s.unshareQuery(ctx, user, UID, teamID)
+}
+
+func (s QueryHistoryService) GetQueryHistoryRetention(ctx context.Context, orgID int64) (QueryHistoryRetentionDTO, error) {
+	return s.getRetention(ctx, orgID)
+}
+
+func (s QueryHistoryService) SetQueryHistoryRetention(ctx context.Context, orgID int64, cmd QueryHistoryRetentionDTO) (QueryHistoryRetentionDTO, error) {
+	return s.setRetention(ctx, orgID, cmd)
+}
+
+func (s QueryHistoryService) EnforceOrgRetentionInQueryHistory(ctx context.Context) (int, error) {
+	return s.enforceOrgRetention(ctx)
+}
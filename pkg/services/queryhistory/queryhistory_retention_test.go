@@ -0,0 +1,108 @@
+package queryhistory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationQueryHistoryRetention(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenario(t, "When org admin updates retention, it should be returned",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.getRetentionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			require.JSONEq(t, `{"result":{"retentionDays":0,"rowLimit":0}}`, string(resp.Body()))
+
+			sc.reqContext.Req.Body = mockRequestBody(QueryHistoryRetentionDTO{RetentionDays: 30, RowLimit: 1000})
+			resp = sc.service.setRetentionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			sc.reqContext.Req.Body = mockRequestBody(QueryHistoryRetentionDTO{RetentionDays: 7, RowLimit: 1000})
+			resp = sc.service.setRetentionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			resp = sc.service.getRetentionHandler(sc.reqContext)
+			var response QueryHistoryRetentionResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, QueryHistoryRetentionDTO{RetentionDays: 7, RowLimit: 1000}, response.Result)
+
+			// the other orgs keep the defaults
+			retention, err := sc.service.GetQueryHistoryRetention(context.Background(), 2)
+			require.NoError(t, err)
+			require.Equal(t, QueryHistoryRetentionDTO{}, retention)
+		})
+
+	testScenario(t, "When org admin resets retention, it should use the defaults",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.SetQueryHistoryRetention(context.Background(), testOrgID, QueryHistoryRetentionDTO{RetentionDays: 7})
+			require.NoError(t, err)
+			_, err = sc.service.SetQueryHistoryRetention(context.Background(), testOrgID, QueryHistoryRetentionDTO{})
+			require.NoError(t, err)
+			require.Equal(t, int64(0), countRows(t, sc, &QueryHistoryRetention{}))
+		})
+
+	testScenario(t, "When org admin sets negative retention, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.Req.Body = mockRequestBody(QueryHistoryRetentionDTO{RetentionDays: -1})
+			resp := sc.service.setRetentionHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+}
+
+func TestIntegrationEnforceOrgRetentionInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "Org retention days should delete the older queries of the org",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.UpdateQueryTagsInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, UpdateQueryTagsInQueryHistoryCommand{Tags: []string{"slo"}})
+			require.NoError(t, err)
+			_, err = sc.service.SetQueryHistoryRetention(context.Background(), testOrgID, QueryHistoryRetentionDTO{RetentionDays: 30})
+			require.NoError(t, err)
+
+			rowsDeleted, err := sc.service.EnforceOrgRetentionInQueryHistory(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+
+			err = sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+				_, err := session.Exec("UPDATE query_history SET created_at = ?", time.Now().Add(-time.Hour*24*31).Unix())
+				return err
+			})
+			require.NoError(t, err)
+
+			// the global cleanup skips the orgs with their own retention days
+			rowsDeleted, err = sc.service.DeleteStaleQueriesInQueryHistory(context.Background(), time.Now().Unix())
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+
+			rowsDeleted, err = sc.service.EnforceOrgRetentionInQueryHistory(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, rowsDeleted)
+			require.Equal(t, int64(0), countRows(t, sc, &QueryHistoryTag{}))
+		})
+
+	// In this scenario we have 2 starred queries and 1 not starred query
+	testScenarioWithMultipleQueriesInQueryHistory(t, "Org row limit should delete the not starred queries of the org",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.SetQueryHistoryRetention(context.Background(), testOrgID, QueryHistoryRetentionDTO{RowLimit: 1})
+			require.NoError(t, err)
+
+			rowsDeleted, err := sc.service.EnforceOrgRetentionInQueryHistory(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, rowsDeleted)
+
+			_, err = sc.service.SetQueryHistoryRetention(context.Background(), 2, QueryHistoryRetentionDTO{RowLimit: 1})
+			require.NoError(t, err)
+			rowsDeleted, err = sc.service.EnforceOrgRetentionInQueryHistory(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+		})
+}
@@ -0,0 +1,216 @@
+package queryhistory
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+var otherUser = &user.SignedInUser{UserID: 2, Login: "other_user", OrgID: testOrgID, OrgRole: org.RoleViewer, Permissions: map[int64]map[string][]string{
+	testOrgID: {datasources.ActionQuery: {datasources.ScopeAll}},
+}}
+
+// setupTeam creates a team with the members
+func setupTeam(t *testing.T, sc scenarioContext, name string, members ...int64) int64 {
+	t.Helper()
+
+	teamService := teamimpl.ProvideService(sc.sqlStore, sc.sqlStore.Cfg)
+	team, err := teamService.CreateTeam(name, "", testOrgID)
+	require.NoError(t, err)
+	for _, userID := range members {
+		err := teamService.AddTeamMember(userID, testOrgID, team.Id, false, models.PERMISSION_VIEW)
+		require.NoError(t, err)
+	}
+	return team.Id
+}
+
+func searchSharedQueries(t *testing.T, sc scenarioContext, usr *user.SignedInUser) QueryHistorySearchResult {
+	t.Helper()
+
+	result, err := sc.service.SearchInQueryHistory(context.Background(), usr, SearchInQueryHistoryQuery{OnlyShared: true})
+	require.NoError(t, err)
+	return result
+}
+
+func TestIntegrationShareQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user shares query with the org, it should be found by the other users",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{})
+			resp := sc.service.shareHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.True(t, result.Result.SharedWithOrg)
+			require.Equal(t, []int64{}, result.Result.SharedWithTeams)
+
+			shared := searchSharedQueries(t, sc, otherUser)
+			require.Equal(t, 1, shared.TotalCount)
+			require.Equal(t, sc.initialResult.Result.UID, shared.QueryHistory[0].UID)
+			require.False(t, shared.QueryHistory[0].Starred)
+
+			// the other user does not own the query
+			own, err := sc.service.SearchInQueryHistory(context.Background(), otherUser, SearchInQueryHistoryQuery{})
+			require.NoError(t, err)
+			require.Equal(t, 0, own.TotalCount)
+
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{})
+			resp = sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 409, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user shares query with a team, it should only be found by the team members",
+		func(t *testing.T, sc scenarioContext) {
+			teamID := setupTeam(t, sc, "team", testUserID, otherUser.UserID)
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID})
+			resp := sc.service.shareHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.False(t, result.Result.SharedWithOrg)
+			require.Equal(t, []int64{teamID}, result.Result.SharedWithTeams)
+
+			require.Equal(t, 1, searchSharedQueries(t, sc, otherUser).TotalCount)
+			require.Equal(t, 0, searchSharedQueries(t, sc, &user.SignedInUser{UserID: 3, OrgID: testOrgID}).TotalCount)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user shares query with a team they are not a member of, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			teamID := setupTeam(t, sc, "team", otherUser.UserID)
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID + 1})
+			resp = sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+
+			// org admins can share with any team
+			sc.reqContext.OrgRole = org.RoleAdmin
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID})
+			resp = sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When other user tries to share query, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.ShareQueryInQueryHistory(context.Background(), otherUser, sc.initialResult.Result.UID, ShareQueryInQueryHistoryCommand{})
+			require.ErrorIs(t, err, ErrQueryNotFound)
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When users tries to get shared queries with datasourceUid and tag, it should return correct queries",
+		func(t *testing.T, sc scenarioContext) {
+			all, err := sc.service.SearchInQueryHistory(context.Background(), sc.reqContext.SignedInUser, SearchInQueryHistoryQuery{})
+			require.NoError(t, err)
+			for _, query := range all.QueryHistory {
+				_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, query.UID, ShareQueryInQueryHistoryCommand{})
+				require.NoError(t, err)
+			}
+			_, err = sc.service.UpdateQueryTagsInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, UpdateQueryTagsInQueryHistoryCommand{Tags: []string{"slo"}})
+			require.NoError(t, err)
+
+			sc.reqContext.SignedInUser = otherUser
+			sc.reqContext.Req.Form.Add("onlyShared", "true")
+			sc.reqContext.Req.Form.Add("datasourceUid", testDsUID1)
+			resp := sc.service.searchHandler(sc.reqContext)
+			var response QueryHistorySearchResponse
+			err = json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 2, response.Result.TotalCount)
+
+			sc.reqContext.Req.Form.Add("tag", "slo")
+			resp = sc.service.searchHandler(sc.reqContext)
+			err = json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 1, response.Result.TotalCount)
+			require.Equal(t, sc.initialResult.Result.UID, response.Result.QueryHistory[0].UID)
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When users get shared queries, it should only return the queries of the datasources they can query",
+		func(t *testing.T, sc scenarioContext) {
+			all, err := sc.service.SearchInQueryHistory(context.Background(), sc.reqContext.SignedInUser, SearchInQueryHistoryQuery{})
+			require.NoError(t, err)
+			for _, query := range all.QueryHistory {
+				_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, query.UID, ShareQueryInQueryHistoryCommand{})
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, 3, searchSharedQueries(t, sc, otherUser).TotalCount)
+
+			ds2User := &user.SignedInUser{UserID: 3, OrgID: testOrgID, OrgRole: org.RoleViewer, Permissions: map[int64]map[string][]string{
+				testOrgID: {datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID(testDsUID2)}},
+			}}
+			shared := searchSharedQueries(t, sc, ds2User)
+			require.Equal(t, 1, shared.TotalCount)
+			require.Equal(t, testDsUID2, shared.QueryHistory[0].DatasourceUID)
+
+			require.Equal(t, 0, searchSharedQueries(t, sc, &user.SignedInUser{UserID: 4, OrgID: testOrgID, OrgRole: org.RoleViewer}).TotalCount)
+		})
+}
+
+func TestIntegrationUnshareQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user stops sharing query, it should not be found by the other users",
+		func(t *testing.T, sc scenarioContext) {
+			teamID := setupTeam(t, sc, "team", testUserID, otherUser.UserID)
+			for _, id := range []int64{0, teamID} {
+				_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, ShareQueryInQueryHistoryCommand{TeamID: id})
+				require.NoError(t, err)
+			}
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.unshareHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.False(t, result.Result.SharedWithOrg)
+			require.Equal(t, []int64{teamID}, result.Result.SharedWithTeams)
+			require.Equal(t, 1, searchSharedQueries(t, sc, otherUser).TotalCount)
+
+			sc.reqContext.Req.Form.Add("teamId", strconv.FormatInt(teamID, 10))
+			resp = sc.service.unshareHandler(sc.reqContext)
+			validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, 0, searchSharedQueries(t, sc, otherUser).TotalCount)
+
+			resp = sc.service.unshareHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user deletes shared query, it should not be found by the other users",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, ShareQueryInQueryHistoryCommand{})
+			require.NoError(t, err)
+
+			_, err = sc.service.DeleteQueryFromQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID)
+			require.NoError(t, err)
+			require.Equal(t, 0, searchSharedQueries(t, sc, otherUser).TotalCount)
+			require.Equal(t, int64(0), countRows(t, sc, &QueryHistoryShare{}))
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "Stale shared query history can not be deleted",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, ShareQueryInQueryHistoryCommand{})
+			require.NoError(t, err)
+
+			olderThan := time.Now().Unix() + 60
+			rowsDeleted, err := sc.service.DeleteStaleQueriesInQueryHistory(context.Background(), olderThan)
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+
+			rowsDeleted, err = sc.service.EnforceRowLimitInQueryHistory(context.Background(), 0, false)
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+		})
+}
@@ -0,0 +1,96 @@
+package queryhistory
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationUpdateTagsOfQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user tries to update tags of query in query history that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": "invalid-uid"})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryTagsInQueryHistoryCommand{Tags: []string{"errors"}})
+			resp := sc.service.updateTagsHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to update tags of query in query history, it should trim and sort them",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryTagsInQueryHistoryCommand{Tags: []string{" slo", "errors", "slo "}})
+			resp := sc.service.updateTagsHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, []string{"errors", "slo"}, result.Result.Tags)
+
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryTagsInQueryHistoryCommand{Tags: []string{}})
+			resp = sc.service.updateTagsHandler(sc.reqContext)
+			result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, []string{}, result.Result.Tags)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to update query in query history with invalid tags, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			for _, tags := range [][]string{{" "}, {strings.Repeat("a", 51)}} {
+				sc.reqContext.Req.Body = mockRequestBody(UpdateQueryTagsInQueryHistoryCommand{Tags: tags})
+				resp := sc.service.updateTagsHandler(sc.reqContext)
+				require.Equal(t, 400, resp.Status())
+			}
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When users tries to get queries with tags, it should return queries with all the tags",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryTagsInQueryHistoryCommand{Tags: []string{"errors", "slo"}})
+			validateAndUnMarshalResponse(t, sc.service.updateTagsHandler(sc.reqContext))
+
+			sc.reqContext.Req.Form.Add("tag", "slo")
+			resp := sc.service.searchHandler(sc.reqContext)
+			var response QueryHistorySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 1, response.Result.TotalCount)
+			require.Equal(t, []string{"errors", "slo"}, response.Result.QueryHistory[0].Tags)
+
+			sc.reqContext.Req.Form.Add("tag", "latency")
+			resp = sc.service.searchHandler(sc.reqContext)
+			err = json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 0, response.Result.TotalCount)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user deletes query in query history, it should delete the tags",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryTagsInQueryHistoryCommand{Tags: []string{"errors"}})
+			validateAndUnMarshalResponse(t, sc.service.updateTagsHandler(sc.reqContext))
+
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			require.Equal(t, int64(0), countRows(t, sc, &QueryHistoryTag{}))
+		})
+}
+
+func countRows(t *testing.T, sc scenarioContext, bean interface{}) int64 {
+	t.Helper()
+
+	var count int64
+	err := sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+		var err error
+		count, err = session.Count(bean)
+		return err
+	})
+	require.NoError(t, err)
+	return count
+}
@@ -4,28 +4,51 @@ import (
 	"bytes"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
-func writeStarredSQL(query SearchInQueryHistoryQuery, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
+// shared queries can be starred by other users, only the stars of the user are joined
+func writeStarredSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
 	if query.OnlyStarred {
-		builder.Write(sqlStore.Dialect.BooleanStr(true) + ` AS starred
+		builder.Write(sqlStore.Dialect.BooleanStr(true)+` AS starred
 				FROM query_history
-				INNER JOIN query_history_star ON query_history_star.query_uid = query_history.uid 
-				`)
+				INNER JOIN query_history_star ON query_history_star.query_uid = query_history.uid AND query_history_star.user_id = ? 
+				`, user.UserID)
 	} else {
-		builder.Write(` CASE WHEN query_history_star.query_uid IS NULL THEN ` + sqlStore.Dialect.BooleanStr(false) + ` ELSE ` + sqlStore.Dialect.BooleanStr(true) + ` END AS starred
+		builder.Write(` CASE WHEN query_history_star.query_uid IS NULL THEN `+sqlStore.Dialect.BooleanStr(false)+` ELSE `+sqlStore.Dialect.BooleanStr(true)+` END AS starred
 				FROM query_history
-				LEFT JOIN query_history_star ON query_history_star.query_uid = query_history.uid 
-				`)
+				LEFT JOIN query_history_star ON query_history_star.query_uid = query_history.uid AND query_history_star.user_id = ? 
+				`, user.UserID)
 	}
 }
 
 func writeFiltersSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
-	params := []interface{}{user.OrgID, user.UserID, query.From, query.To, "%" + query.SearchString + "%", "%" + query.SearchString + "%"}
+	var params []interface{}
 	var sql bytes.Buffer
-	sql.WriteString(" WHERE query_history.org_id = ? AND query_history.created_by = ? AND query_history.created_at >= ? AND query_history.created_at <= ? AND (query_history.queries " + sqlStore.Dialect.LikeStr() + " ? OR query_history.comment " + sqlStore.Dialect.LikeStr() + " ?) ")
+	if query.OnlyShared {
+		// shared with the whole org or with a team of the user
+		params = append(params, user.OrgID, user.OrgID, user.OrgID, user.UserID)
+		sql.WriteString(` WHERE query_history.org_id = ? AND query_history.uid IN (
+			SELECT query_uid FROM query_history_share WHERE org_id = ? AND (team_id = 0 OR team_id IN (
+				SELECT team_id FROM team_member WHERE org_id = ? AND user_id = ?
+			))
+		) `)
+
+		// only the shared queries of the data sources the user can query, a user without permissions gets no queries
+		if !accesscontrol.IsDisabled(sqlStore.Cfg) {
+			filter, _ := accesscontrol.Filter(user, "query_history.datasource_uid", datasources.ScopePrefix, datasources.ActionQuery)
+			params = append(params, filter.Args...)
+			sql.WriteString(" AND" + filter.Where + " ")
+		}
+	} else {
+		params = append(params, user.OrgID, user.UserID)
+		sql.WriteString(" WHERE query_history.org_id = ? AND query_history.created_by = ? ")
+	}
+	params = append(params, query.From, query.To, "%"+query.SearchString+"%", "%"+query.SearchString+"%")
+	sql.WriteString(" AND query_history.created_at >= ? AND query_history.created_at <= ? AND (query_history.queries " + sqlStore.Dialect.LikeStr() + " ? OR query_history.comment " + sqlStore.Dialect.LikeStr() + " ?) ")
 
 	if len(query.DatasourceUIDs) > 0 {
 		for _, uid := range query.DatasourceUIDs {
@@ -33,6 +56,16 @@ func writeFiltersSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, s
 		}
 		sql.WriteString(" AND query_history.datasource_uid IN (? " + strings.Repeat(",?", len(query.DatasourceUIDs)-1) + ") ")
 	}
+
+	// the queries must have all the tags
+	if tags := uniqueTags(query.Tags); len(tags) > 0 {
+		params = append(params, user.OrgID)
+		for _, tag := range tags {
+			params = append(params, tag)
+		}
+		params = append(params, len(tags))
+		sql.WriteString(" AND query_history.uid IN (SELECT query_uid FROM query_history_tag WHERE org_id = ? AND term IN (? " + strings.Repeat(",?", len(tags)-1) + ") GROUP BY query_uid HAVING COUNT(*) = ?) ")
+	}
 	builder.Write(sql.String(), params...)
 }
 
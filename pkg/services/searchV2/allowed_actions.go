@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -169,6 +170,64 @@ func (s *StandardSearchService) getAllowedActionsByUid(ctx context.Context, user
 	return out
 }
 
+// getEntityReadFilter returns the filter of the alert rules, library panels, datasources, playlists and
+// correlations the user can read. The alert rules and library panels must also be in a folder the user
+// can read, which is checked with the dashboard filter.
+func (s *StandardSearchService) getEntityReadFilter(orgID int64, user *user.SignedInUser) entityFilter {
+	if s.ac.IsDisabled() {
+		return func(kind entityKind, _ string, parentUID string, _ []string) bool {
+			switch kind {
+			case entityKindDatasource, entityKindCorrelation:
+				return user.HasRole(org.RoleAdmin)
+			case entityKindLibraryPanel:
+				return canReadLibraryPanel(user, parentUID)
+			case entityKindAlertRule, entityKindPlaylist:
+				return user.HasRole(org.RoleViewer)
+			default:
+				return false
+			}
+		}
+	}
+
+	permissions := map[string][]string{}
+	if orgPermissions, ok := user.Permissions[orgID]; ok {
+		permissions = orgPermissions
+	}
+
+	return func(kind entityKind, uid string, parentUID string, dsUIDs []string) bool {
+		var evaluator accesscontrol.Evaluator
+		switch kind {
+		case entityKindAlertRule:
+			// Like the ruler API, the rules are only readable with the datasources they query.
+			evaluators := []accesscontrol.Evaluator{
+				accesscontrol.EvalPermission(accesscontrol.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parentUID)),
+			}
+			for _, dsUID := range dsUIDs {
+				evaluators = append(evaluators, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(dsUID)))
+			}
+			evaluator = accesscontrol.EvalAll(evaluators...)
+		case entityKindDatasource:
+			evaluator = accesscontrol.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(uid))
+		case entityKindCorrelation:
+			evaluator = accesscontrol.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(parentUID))
+		case entityKindLibraryPanel:
+			return canReadLibraryPanel(user, parentUID)
+		case entityKindPlaylist:
+			// The playlists are readable by the viewers of the organization.
+			return user.HasRole(org.RoleViewer)
+		default:
+			return false
+		}
+		return evaluator.Evaluate(permissions)
+	}
+}
+
+// canReadLibraryPanel returns whether the user can read a library panel. The library panels are readable
+// with their folder, which is checked with the dashboard filter, and by the viewers in the General folder.
+func canReadLibraryPanel(user *user.SignedInUser, folderUID string) bool {
+	return folderUID != "general" || user.HasRole(org.RoleViewer)
+}
+
 type entityReferences struct {
 	entityKind entityKind
 	uid        string
@@ -220,7 +279,7 @@ func getEntityReferences(resp *backend.DataResponse) ([]entityReferences, error)
 			return nil, errors.New("invalid value in uid field")
 		}
 
-		if entityKind(kind) != entityKindDashboard {
+		if !entityKind(kind).referencesDatasources() {
 			out = append(out, entityReferences{
 				entityKind: entityKind(kind),
 				uid:        uid,
@@ -251,7 +310,7 @@ func getEntityReferences(resp *backend.DataResponse) ([]entityReferences, error)
 			}
 		}
 
-		out = append(out, entityReferences{entityKind: entityKindDashboard, uid: uid, dsUids: uids})
+		out = append(out, entityReferences{entityKind: entityKind(kind), uid: uid, dsUids: uids})
 	}
 
 	return out, nil
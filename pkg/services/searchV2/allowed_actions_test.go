@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
@@ -120,3 +121,58 @@ func TestAllowedActionsForPermissionsWithScopeAll(t *testing.T) {
 		experimental.CheckGoldenJSONFrame(t, "testdata", fmt.Sprintf("allowed_actions_%s.golden", tt.name), frame, true)
 	}
 }
+
+func TestEntityReadFilter(t *testing.T) {
+	permissions := map[string][]string{
+		datasources.ActionRead: {
+			datasources.ScopeProvider.GetResourceScopeUID("datasource-2"),
+		},
+		datasources.ActionQuery: {
+			datasources.ScopeProvider.GetResourceScopeUID("datasource-2"),
+		},
+		ac.ActionAlertingRuleRead: {
+			dashboards.ScopeFoldersProvider.GetResourceScopeUID("ujaM1h6nz"),
+		},
+	}
+	viewer := &user.SignedInUser{
+		OrgRole: org.RoleViewer,
+		Permissions: map[int64]map[string][]string{
+			orgId: permissions,
+		},
+	}
+	noRole := &user.SignedInUser{
+		Permissions: map[int64]map[string][]string{
+			orgId: permissions,
+		},
+	}
+
+	tests := []struct {
+		user      *user.SignedInUser
+		kind      entityKind
+		uid       string
+		parentUID string
+		dsUIDs    []string
+		expected  bool
+	}{
+		{user: viewer, kind: entityKindAlertRule, uid: "rule-1", parentUID: "ujaM1h6nz", dsUIDs: []string{"datasource-2"}, expected: true},
+		{user: viewer, kind: entityKindAlertRule, uid: "rule-2", parentUID: "other-folder", dsUIDs: []string{"datasource-2"}, expected: false},
+		{user: viewer, kind: entityKindAlertRule, uid: "rule-3", parentUID: "ujaM1h6nz", dsUIDs: []string{"datasource-2", "datasource-1"}, expected: false},
+		{user: viewer, kind: entityKindDatasource, uid: "datasource-2", expected: true},
+		{user: viewer, kind: entityKindDatasource, uid: "datasource-1", expected: false},
+		{user: viewer, kind: entityKindCorrelation, uid: "correlation-1", parentUID: "datasource-2", expected: true},
+		{user: viewer, kind: entityKindCorrelation, uid: "correlation-2", parentUID: "datasource-1", expected: false},
+		{user: viewer, kind: entityKindLibraryPanel, uid: "panel-1", parentUID: "general", expected: true},
+		{user: noRole, kind: entityKindLibraryPanel, uid: "panel-1", parentUID: "general", expected: false},
+		{user: noRole, kind: entityKindLibraryPanel, uid: "panel-2", parentUID: "ujaM1h6nz", expected: true},
+		{user: viewer, kind: entityKindPlaylist, uid: "playlist-1", expected: true},
+		{user: noRole, kind: entityKindPlaylist, uid: "playlist-1", expected: false},
+		{user: viewer, kind: entityKindDashboard, uid: "dashboard-1", expected: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d/%s/%s", i, tt.kind, tt.uid), func(t *testing.T) {
+			filter := service(t).getEntityReadFilter(orgId, tt.user)
+			require.Equal(t, tt.expected, filter(tt.kind, tt.uid, tt.parentUID, tt.dsUIDs))
+		})
+	}
+}
@@ -30,11 +30,12 @@ const (
 	documentFieldTransformer = "transformer"
 	documentFieldDSUID       = "ds_uid"
 	documentFieldDSType      = "ds_type"
+	documentFieldQuery       = "query" // the query expressions of the panels
 	DocumentFieldCreatedAt   = "created_at"
 	DocumentFieldUpdatedAt   = "updated_at"
 )
 
-func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
+func initOrgIndex(dashboards []dashboard, entities []entity, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
 	dashboardWriter, err := bluge.OpenWriter(bluge.InMemoryOnlyConfig())
 	if err != nil {
 		return nil, fmt.Errorf("error opening writer: %v", err)
@@ -67,12 +68,16 @@ func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDas
 	}
 
 	// First index the folders to construct folderIdLookup.
-	folderIdLookup := make(map[int64]string, 50)
+	folderIdLookup := getFolderPaths(dashboards)
 	for _, dash := range dashboards {
 		if !dash.isFolder {
 			continue
 		}
-		doc := getFolderDashboardDoc(dash)
+		var location string
+		if dash.folderID > 0 {
+			location = folderIdLookup[dash.folderID]
+		}
+		doc := getFolderDashboardDoc(dash, location)
 		if err := extendDoc(dash.uid, doc); err != nil {
 			return nil, err
 		}
@@ -80,11 +85,6 @@ func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDas
 		if err := flushIfRequired(false); err != nil {
 			return nil, err
 		}
-		uid := dash.uid
-		if uid == "" {
-			uid = "general"
-		}
-		folderIdLookup[dash.id] = uid
 	}
 
 	// Then each dashboard.
@@ -123,16 +123,88 @@ func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDas
 		return nil, err
 	}
 
+	writers := map[indexType]*bluge.Writer{
+		indexTypeDashboard: dashboardWriter,
+	}
+
+	// Then the other entities, each kind in its own index.
+	folderPaths := make(map[string]string, len(folderIdLookup))
+	for _, dash := range dashboards {
+		if dash.isFolder && dash.uid != "" {
+			folderPaths[dash.uid] = folderIdLookup[dash.id]
+		}
+	}
+	folderPath := func(folderUID string) (string, error) {
+		if path, ok := folderPaths[folderUID]; ok {
+			return path, nil
+		}
+		return folderUID, nil
+	}
+	for _, kind := range indexedEntityKinds {
+		writer, err := bluge.OpenWriter(bluge.InMemoryOnlyConfig())
+		if err != nil {
+			return nil, fmt.Errorf("error opening writer: %v", err)
+		}
+		writers[indexTypeForKind(kind)] = writer
+
+		batch := bluge.NewBatch()
+		for _, e := range entities {
+			if e.kind != kind {
+				continue
+			}
+			location, err := getEntityLocation(e, folderPath)
+			if err != nil {
+				return nil, err
+			}
+			batch.Insert(getEntityDoc(e, location))
+		}
+		if err := writer.Batch(batch); err != nil {
+			return nil, err
+		}
+	}
+
 	logger.Info("Finish inserting docs into index", "elapsed", time.Since(label))
 	logger.Info("Finish building index", "totalElapsed", time.Since(start))
 	return &orgIndex{
-		writers: map[indexType]*bluge.Writer{
-			indexTypeDashboard: dashboardWriter,
-		},
+		writers: writers,
 	}, err
 }
 
-func getFolderDashboardDoc(dash dashboard) *bluge.Document {
+// getFolderPaths returns the full path of every folder, keyed by folder id. Root level
+// folders have their uid as path, nested folders have their parent path followed by their uid.
+func getFolderPaths(dashboards []dashboard) map[int64]string {
+	folders := make(map[int64]dashboard, 50)
+	for _, dash := range dashboards {
+		if dash.isFolder {
+			folders[dash.id] = dash
+		}
+	}
+
+	paths := make(map[int64]string, len(folders))
+	var getPath func(id int64, depth int) string
+	getPath = func(id int64, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		folder := folders[id]
+		path := folder.uid
+		if path == "" {
+			path = "general"
+		}
+		// The depth check protects from cycles in the stored folder hierarchy.
+		if _, ok := folders[folder.folderID]; ok && folder.folderID > 0 && depth < len(folders) {
+			path = getPath(folder.folderID, depth+1) + "/" + path
+		}
+		paths[id] = path
+		return path
+	}
+	for id := range folders {
+		getPath(id, 0)
+	}
+	return paths
+}
+
+func getFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
 	uid := dash.uid
 	url := fmt.Sprintf("/dashboards/f/%s/%s", dash.uid, dash.slug)
 	if uid == "" {
@@ -142,10 +214,15 @@ func getFolderDashboardDoc(dash dashboard) *bluge.Document {
 		dash.info.Description = ""
 	}
 
-	return newSearchDocument(uid, dash.info.Title, dash.info.Description, url).
+	doc := newSearchDocument(uid, dash.info.Title, dash.info.Description, url).
 		AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindFolder)).Aggregatable().StoreValue()).
 		AddField(bluge.NewDateTimeField(DocumentFieldCreatedAt, dash.created).Sortable().StoreValue()).
 		AddField(bluge.NewDateTimeField(DocumentFieldUpdatedAt, dash.updated).Sortable().StoreValue())
+	if location != "" {
+		// Only nested folders have a location.
+		doc.AddField(bluge.NewKeywordField(documentFieldLocation, location).Aggregatable().StoreValue())
+	}
+	return doc
 }
 
 func getNonFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
@@ -180,6 +257,14 @@ func getNonFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
 		}
 	}
 
+	// The dashboard matches the queries of all its panels, including the ones in collapsed rows.
+	for _, panel := range dash.info.Panels {
+		addQueryFields(doc, panel.Queries)
+		for _, collapsed := range panel.Collapsed {
+			addQueryFields(doc, collapsed.Queries)
+		}
+	}
+
 	return doc
 }
 
@@ -203,6 +288,8 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 			doc.AddField(bluge.NewKeywordField(documentFieldTransformer, xform).Aggregatable())
 		}
 
+		addQueryFields(doc, panel.Queries)
+
 		for _, ds := range panel.Datasource {
 			if ds.UID != "" {
 				doc.AddField(bluge.NewKeywordField(documentFieldDSUID, ds.UID).
@@ -223,6 +310,66 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 	return docs
 }
 
+func addQueryFields(doc *bluge.Document, queries []string) {
+	for _, query := range queries {
+		doc.AddField(bluge.NewTextField(documentFieldQuery, query).WithAnalyzer(queryTextAnalyzer))
+	}
+}
+
+// getEntityLocation returns the location of an entity. The location of the alert rules and library panels
+// is the full path of their folder, which is resolved with folderPath.
+func getEntityLocation(e entity, folderPath func(folderUID string) (string, error)) (string, error) {
+	if !e.kind.isInFolder() {
+		return e.location, nil
+	}
+	if e.folderUID == "" {
+		return "general", nil
+	}
+	return folderPath(e.folderUID)
+}
+
+func getEntityDoc(e entity, location string) *bluge.Document {
+	doc := newSearchDocument(e.uid, e.name, e.description, e.url).
+		AddField(bluge.NewKeywordField(documentFieldKind, string(e.kind)).Aggregatable().StoreValue())
+
+	if location != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldLocation, location).Aggregatable().StoreValue())
+	}
+	if e.panelType != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldPanelType, e.panelType).Aggregatable().StoreValue())
+	}
+	if !e.created.IsZero() {
+		doc.AddField(bluge.NewDateTimeField(DocumentFieldCreatedAt, e.created).Sortable().StoreValue())
+	}
+	if !e.updated.IsZero() {
+		doc.AddField(bluge.NewDateTimeField(DocumentFieldUpdatedAt, e.updated).Sortable().StoreValue())
+	}
+
+	for _, tag := range e.tags {
+		doc.AddField(bluge.NewKeywordField(documentFieldTag, tag).
+			StoreValue().
+			Aggregatable().
+			SearchTermPositions())
+	}
+
+	for _, ds := range e.ds {
+		if ds.UID != "" {
+			doc.AddField(bluge.NewKeywordField(documentFieldDSUID, ds.UID).
+				StoreValue().
+				Aggregatable().
+				SearchTermPositions())
+		}
+		if ds.Type != "" {
+			doc.AddField(bluge.NewKeywordField(documentFieldDSType, ds.Type).
+				StoreValue().
+				Aggregatable().
+				SearchTermPositions())
+		}
+	}
+
+	return doc
+}
+
 // Names need to be indexed a few ways to support key features
 func newSearchDocument(uid string, name string, descr string, url string) *bluge.Document {
 	doc := bluge.NewDocument(uid)
@@ -279,9 +426,13 @@ func getDashboardPanelIDs(index *orgIndex, panelLocation string) ([]string, erro
 }
 
 func getDocsIDsByLocationPrefix(index *orgIndex, prefix string) ([]string, error) {
+	return getIndexDocsIDsByLocationPrefix(index, indexTypeDashboard, prefix)
+}
+
+func getIndexDocsIDsByLocationPrefix(index *orgIndex, idxType indexType, prefix string) ([]string, error) {
 	var ids []string
 
-	reader, cancel, err := index.readerForIndex(indexTypeDashboard)
+	reader, cancel, err := index.readerForIndex(idxType)
 	if err != nil {
 		return nil, fmt.Errorf("error getting reader: %w", err)
 	}
@@ -313,41 +464,61 @@ func getDocsIDsByLocationPrefix(index *orgIndex, prefix string) ([]string, error
 }
 
 func getDashboardLocation(index *orgIndex, dashboardUID string) (string, bool, error) {
-	var dashboardLocation string
-	var found bool
+	reader, cancel, err := index.readerForIndex(indexTypeDashboard)
+	if err != nil {
+		return "", false, err
+	}
+	defer cancel()
+
+	dashboardLocation, found, _, err := getDocumentLocation(reader, dashboardUID, entityKindDashboard)
+	return dashboardLocation, found, err
+}
 
+// getFolderLocation returns the location of an indexed folder, which is empty for root level folders.
+func getFolderLocation(index *orgIndex, folderUID string) (string, bool, error) {
 	reader, cancel, err := index.readerForIndex(indexTypeDashboard)
 	if err != nil {
 		return "", false, err
 	}
 	defer cancel()
 
+	folderLocation, _, found, err := getDocumentLocation(reader, folderUID, entityKindFolder)
+	return folderLocation, found, err
+}
+
+// getDocumentLocation returns the location of the document with the given uid and kind, whether the
+// document has a location field and whether the document exists at all.
+func getDocumentLocation(reader *bluge.Reader, uid string, kind entityKind) (string, bool, bool, error) {
+	var location string
+	var hasLocation, exists bool
+
 	fullQuery := bluge.NewBooleanQuery()
-	fullQuery.AddMust(bluge.NewTermQuery(dashboardUID).SetField(documentFieldUID))
-	fullQuery.AddMust(bluge.NewTermQuery(string(entityKindDashboard)).SetField(documentFieldKind))
+	fullQuery.AddMust(bluge.NewTermQuery(uid).SetField(documentFieldUID))
+	fullQuery.AddMust(bluge.NewTermQuery(string(kind)).SetField(documentFieldKind))
 	req := bluge.NewAllMatches(fullQuery)
 	documentMatchIterator, err := reader.Search(context.Background(), req)
 	if err != nil {
-		return "", false, err
+		return "", false, false, err
 	}
 	match, err := documentMatchIterator.Next()
 	for err == nil && match != nil {
+		exists = true
 		// load the identifier for this match
 		err = match.VisitStoredFields(func(field string, value []byte) bool {
 			if field == documentFieldLocation {
-				dashboardLocation = string(value)
-				found = true
+				location = string(value)
+				hasLocation = true
 				return false
 			}
 			return true
 		})
 		if err != nil {
-			return "", false, err
+			return "", false, false, err
 		}
 		// load the next document match
 		match, err = documentMatchIterator.Next()
 	}
-	return dashboardLocation, found, err
+	return location, hasLocation, exists, err
 }
 
 //nolint:gocyclo
@@ -356,6 +527,7 @@ func doSearchQuery(
 	logger log.Logger,
 	index *orgIndex,
 	filter ResourceFilter,
+	entityFilter entityFilter,
 	q DashboardQuery,
 	extender QueryExtender,
 	appSubUrl string,
@@ -371,9 +543,18 @@ func doSearchQuery(
 	}
 	defer cancel()
 
+	// Only search the indexes of the requested kinds.
+	readers, cancelReaders, err := index.readersForKinds(q.Kind)
+	if err != nil {
+		logger.Error("error getting readers for entity indexes: %v", err)
+		response.Error = err
+		return response
+	}
+	defer cancelReaders()
+
 	hasConstraints := false
 	fullQuery := bluge.NewBooleanQuery()
-	fullQuery.AddMust(newPermissionFilter(filter, logger))
+	fullQuery.AddMust(newPermissionFilter(filter, entityFilter, logger))
 
 	// Only show dashboard / folders / panels.
 	if len(q.Kind) > 0 {
@@ -422,10 +603,41 @@ func doSearchQuery(
 
 	// Folder
 	if q.Location != "" {
-		fullQuery.AddMust(bluge.NewTermQuery(q.Location).SetField(documentFieldLocation))
+		location := q.Location
+		if !strings.Contains(location, "/") {
+			// Items inside a nested folder are located under the full folder path.
+			folderLocation, hasLocation, _, err := getDocumentLocation(reader, location, entityKindFolder)
+			if err != nil {
+				logger.Error("error resolving folder location", "err", err)
+				response.Error = err
+				return response
+			}
+			if hasLocation {
+				location = folderLocation + "/" + location
+			}
+		}
+		fullQuery.AddMust(bluge.NewTermQuery(location).SetField(documentFieldLocation))
 		hasConstraints = true
 	}
 
+	// Filters of the query string, the rest of it searches the names
+	parsedQuery := parseQuery(q.Query)
+	for _, f := range parsedQuery.filters {
+		filterQuery, err := f.blugeQuery(reader)
+		if err != nil {
+			logger.Error("error resolving query filter", "filter", f.key, "err", err)
+			response.Error = err
+			return response
+		}
+		if f.negated {
+			fullQuery.AddMustNot(filterQuery)
+		} else {
+			fullQuery.AddMust(filterQuery)
+			hasConstraints = true
+		}
+	}
+	q.Query = parsedQuery.text
+
 	isMatchAllQuery := q.Query == "*" || q.Query == ""
 	if isMatchAllQuery {
 		if !hasConstraints {
@@ -475,8 +687,8 @@ func doSearchQuery(
 		req.AddAggregation(t.Field, aggregations.NewTermsAggregation(search.Field(t.Field), lim))
 	}
 
-	// execute this search on the reader
-	documentMatchIterator, err := reader.Search(ctx, req)
+	// execute this search on the readers
+	documentMatchIterator, err := bluge.MultiSearch(ctx, req, readers...)
 	if err != nil {
 		logger.Error("error executing search", "err", err)
 		response.Error = err
@@ -0,0 +1,369 @@
+package searchV2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/searchV2/dslookup"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type entityLoader interface {
+	// LoadEntities returns slice of entities of the kind, which must be one of indexedEntityKinds.
+	// If uid is empty – then implementation must return all the entities of the kind in the
+	// organization. If uid is not empty – then only return the entity with specified UID or
+	// empty slice if not found (this is required to apply partial update).
+	LoadEntities(ctx context.Context, orgID int64, kind entityKind, uid string) ([]entity, error)
+}
+
+// entity is an alert rule, library panel, datasource, playlist or correlation
+type entity struct {
+	kind        entityKind
+	uid         string
+	name        string
+	description string
+	url         string
+	// folderUID is the folder of the alert rules and library panels, empty for the General folder.
+	folderUID string
+	// location is the location of the entities which are not in a folder: the source datasource
+	// of the correlations.
+	location  string
+	panelType string
+	tags      []string
+	ds        []dslookup.DataSourceRef
+	created   time.Time
+	updated   time.Time
+}
+
+type sqlEntityLoader struct {
+	sql    *sqlstore.SQLStore
+	logger log.Logger
+	tracer tracing.Tracer
+}
+
+func newSQLEntityLoader(sql *sqlstore.SQLStore, tracer tracing.Tracer) *sqlEntityLoader {
+	return &sqlEntityLoader{sql: sql, logger: log.New("sqlEntityLoader"), tracer: tracer}
+}
+
+func (l sqlEntityLoader) LoadEntities(ctx context.Context, orgID int64, kind entityKind, uid string) ([]entity, error) {
+	ctx, span := l.tracer.Start(ctx, "sqlEntityLoader LoadEntities")
+	span.SetAttributes("orgID", orgID, attribute.Key("orgID").Int64(orgID))
+	span.SetAttributes("kind", kind, attribute.Key("kind").String(string(kind)))
+	defer span.End()
+
+	switch kind {
+	case entityKindAlertRule:
+		return l.loadAlertRules(ctx, orgID, uid)
+	case entityKindLibraryPanel:
+		return l.loadLibraryPanels(ctx, orgID, uid)
+	case entityKindDatasource:
+		return l.loadDatasources(ctx, orgID, uid)
+	case entityKindPlaylist:
+		return l.loadPlaylists(ctx, orgID, uid)
+	case entityKindCorrelation:
+		return l.loadCorrelations(ctx, orgID, uid)
+	default:
+		return nil, fmt.Errorf("unsupported entity kind: %s", kind)
+	}
+}
+
+type alertRuleQueryResult struct {
+	UID          string `xorm:"uid"`
+	Title        string `xorm:"title"`
+	NamespaceUID string `xorm:"namespace_uid"`
+	Labels       string `xorm:"labels"`
+	Data         string `xorm:"data"`
+	Updated      time.Time
+}
+
+func (l sqlEntityLoader) loadAlertRules(ctx context.Context, orgID int64, uid string) ([]entity, error) {
+	lookup, err := dslookup.LoadDatasourceLookup(ctx, orgID, l.sql)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]alertRuleQueryResult, 0)
+	err = l.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Table("alert_rule").Where("org_id = ?", orgID)
+		if uid != "" {
+			sess.Where("uid = ?", uid)
+		}
+		sess.Cols("uid", "title", "namespace_uid", "labels", "data", "updated")
+		return sess.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]entity, 0, len(rows))
+	for _, row := range rows {
+		var labels map[string]string
+		if row.Labels != "" {
+			if err := json.Unmarshal([]byte(row.Labels), &labels); err != nil {
+				l.logger.Warn("Error reading alert rule labels", "error", err, "uid", row.UID)
+			}
+		}
+		var queries []ngmodels.AlertQuery
+		if err := json.Unmarshal([]byte(row.Data), &queries); err != nil {
+			l.logger.Warn("Error reading alert rule queries", "error", err, "uid", row.UID)
+		}
+
+		var ds []dslookup.DataSourceRef
+		for _, query := range queries {
+			if isExpression, err := query.IsExpression(); err != nil || isExpression {
+				continue
+			}
+			ds = appendDatasource(ds, lookup, &dslookup.DataSourceRef{UID: query.DatasourceUID})
+		}
+
+		entities = append(entities, entity{
+			kind:      entityKindAlertRule,
+			uid:       row.UID,
+			name:      row.Title,
+			url:       fmt.Sprintf("/alerting/grafana/%s/view", row.UID),
+			folderUID: row.NamespaceUID,
+			tags:      labelsToTags(labels),
+			ds:        ds,
+			updated:   row.Updated,
+		})
+	}
+	return entities, nil
+}
+
+type libraryPanelQueryResult struct {
+	UID         string `xorm:"uid"`
+	Name        string `xorm:"name"`
+	Description string `xorm:"description"`
+	Type        string `xorm:"type"`
+	Model       string `xorm:"model"`
+	FolderUID   string `xorm:"folder_uid"`
+	Created     time.Time
+	Updated     time.Time
+}
+
+func (l sqlEntityLoader) loadLibraryPanels(ctx context.Context, orgID int64, uid string) ([]entity, error) {
+	lookup, err := dslookup.LoadDatasourceLookup(ctx, orgID, l.sql)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]libraryPanelQueryResult, 0)
+	err = l.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Table("library_element").
+			Join("LEFT", "dashboard", "dashboard.id = library_element.folder_id").
+			Where("library_element.org_id = ? AND library_element.kind = ?", orgID, models.PanelElement)
+		if uid != "" {
+			sess.Where("library_element.uid = ?", uid)
+		}
+		sess.Select("library_element.uid, library_element.name, library_element.description, library_element.type, " +
+			"library_element.model, library_element.created, library_element.updated, dashboard.uid AS folder_uid")
+		return sess.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]entity, 0, len(rows))
+	for _, row := range rows {
+		var model struct {
+			Datasource json.RawMessage `json:"datasource"`
+		}
+		if err := json.Unmarshal([]byte(row.Model), &model); err != nil {
+			l.logger.Warn("Error reading library panel model", "error", err, "uid", row.UID)
+		}
+
+		var ds []dslookup.DataSourceRef
+		if ref := readDatasourceRef(model.Datasource); ref != nil {
+			ds = appendDatasource(ds, lookup, ref)
+		}
+
+		entities = append(entities, entity{
+			kind:        entityKindLibraryPanel,
+			uid:         row.UID,
+			name:        row.Name,
+			description: row.Description,
+			url:         "/library-panels",
+			folderUID:   row.FolderUID,
+			panelType:   row.Type,
+			ds:          ds,
+			created:     row.Created,
+			updated:     row.Updated,
+		})
+	}
+	return entities, nil
+}
+
+type datasourceQueryResult struct {
+	UID     string `xorm:"uid"`
+	Name    string `xorm:"name"`
+	Type    string `xorm:"type"`
+	Created time.Time
+	Updated time.Time
+}
+
+func (l sqlEntityLoader) loadDatasources(ctx context.Context, orgID int64, uid string) ([]entity, error) {
+	rows := make([]datasourceQueryResult, 0)
+	err := l.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Table("data_source").Where("org_id = ?", orgID)
+		if uid != "" {
+			sess.Where("uid = ?", uid)
+		}
+		sess.Cols("uid", "name", "type", "created", "updated")
+		return sess.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]entity, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, entity{
+			kind:    entityKindDatasource,
+			uid:     row.UID,
+			name:    row.Name,
+			url:     fmt.Sprintf("/datasources/edit/%s", row.UID),
+			ds:      []dslookup.DataSourceRef{{Type: row.Type}},
+			created: row.Created,
+			updated: row.Updated,
+		})
+	}
+	return entities, nil
+}
+
+type playlistQueryResult struct {
+	UID  string `xorm:"uid"`
+	Name string `xorm:"name"`
+}
+
+func (l sqlEntityLoader) loadPlaylists(ctx context.Context, orgID int64, uid string) ([]entity, error) {
+	rows := make([]playlistQueryResult, 0)
+	err := l.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Table("playlist").Where("org_id = ?", orgID)
+		if uid != "" {
+			sess.Where("uid = ?", uid)
+		}
+		sess.Cols("uid", "name")
+		return sess.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]entity, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, entity{
+			kind: entityKindPlaylist,
+			uid:  row.UID,
+			name: row.Name,
+			url:  fmt.Sprintf("/playlists/play/%s", row.UID),
+		})
+	}
+	return entities, nil
+}
+
+type correlationQueryResult struct {
+	UID         string `xorm:"uid"`
+	SourceUID   string `xorm:"source_uid"`
+	TargetUID   string `xorm:"target_uid"`
+	Label       string `xorm:"label"`
+	Description string `xorm:"description"`
+}
+
+func (l sqlEntityLoader) loadCorrelations(ctx context.Context, orgID int64, uid string) ([]entity, error) {
+	lookup, err := dslookup.LoadDatasourceLookup(ctx, orgID, l.sql)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]correlationQueryResult, 0)
+	err = l.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		// The correlations belong to the organization of their source datasource.
+		sess.Table("correlation").
+			Join("INNER", "data_source", "data_source.uid = correlation.source_uid AND data_source.org_id = ?", orgID)
+		if uid != "" {
+			sess.Where("correlation.uid = ?", uid)
+		}
+		sess.Select("correlation.uid, correlation.source_uid, correlation.target_uid, correlation.label, correlation.description")
+		return sess.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]entity, 0, len(rows))
+	for _, row := range rows {
+		name := row.Label
+		if name == "" {
+			name = row.UID
+		}
+		var ds []dslookup.DataSourceRef
+		ds = appendDatasource(ds, lookup, &dslookup.DataSourceRef{UID: row.SourceUID})
+		if row.TargetUID != "" {
+			ds = appendDatasource(ds, lookup, &dslookup.DataSourceRef{UID: row.TargetUID})
+		}
+
+		entities = append(entities, entity{
+			kind:        entityKindCorrelation,
+			uid:         row.UID,
+			name:        name,
+			description: row.Description,
+			url:         "/datasources/correlations",
+			location:    row.SourceUID,
+			ds:          ds,
+		})
+	}
+	return entities, nil
+}
+
+// appendDatasource appends the datasource, with the type resolved with the lookup, if not already in the slice
+func appendDatasource(ds []dslookup.DataSourceRef, lookup dslookup.DatasourceLookup, ref *dslookup.DataSourceRef) []dslookup.DataSourceRef {
+	if ref.UID != "" {
+		if resolved := lookup.ByRef(ref); resolved != nil {
+			ref = resolved
+		}
+	}
+	for _, existing := range ds {
+		if existing.UID == ref.UID {
+			return ds
+		}
+	}
+	return append(ds, *ref)
+}
+
+// readDatasourceRef reads a datasource reference of a panel, which is either an object with the uid
+// and type, or the name of the datasource in older panels.
+func readDatasourceRef(raw json.RawMessage) *dslookup.DataSourceRef {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		if name == "" {
+			return nil
+		}
+		return &dslookup.DataSourceRef{UID: name}
+	}
+	ref := &dslookup.DataSourceRef{}
+	if err := json.Unmarshal(raw, ref); err != nil || (ref.UID == "" && ref.Type == "") {
+		return nil
+	}
+	return ref
+}
+
+// labelsToTags returns the labels of an alert rule as sorted "name=value" tags
+func labelsToTags(labels map[string]string) []string {
+	tags := make([]string, 0, len(labels))
+	for name, value := range labels {
+		tags = append(tags, name+"="+value)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -447,6 +447,7 @@ func readPanelInfo(iter *jsoniter.Iterator, lookup dslookup.DatasourceLookup) Pa
 	}
 
 	panel.Datasource = targets.GetDatasourceInfo()
+	panel.Queries = targets.queries
 
 	return panel
 }
@@ -72,6 +72,7 @@ func TestReadDashboard(t *testing.T) {
 		"mixed-datasource-with-variable",
 		"special-datasource-types",
 		"panels-without-datasources",
+		"panel-queries",
 	}
 
 	devdash := "../../../../devenv/dev-dashboards/"
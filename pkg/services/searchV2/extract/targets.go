@@ -6,9 +6,21 @@ import (
 	"github.com/grafana/grafana/pkg/services/searchV2/dslookup"
 )
 
+// queryTextFields are the fields of the targets holding the query expression of the common datasources:
+// expr for Prometheus and Loki, rawSql for the SQL datasources, target for Graphite...
+var queryTextFields = map[string]bool{
+	"expr":       true,
+	"expression": true,
+	"query":      true,
+	"queryText":  true,
+	"rawSql":     true,
+	"target":     true,
+}
+
 type targetInfo struct {
-	lookup dslookup.DatasourceLookup
-	uids   map[string]*dslookup.DataSourceRef
+	lookup  dslookup.DatasourceLookup
+	uids    map[string]*dslookup.DataSourceRef
+	queries []string
 }
 
 func newTargetInfo(lookup dslookup.DatasourceLookup) targetInfo {
@@ -78,6 +90,13 @@ func (s *targetInfo) addTarget(iter *jsoniter.Iterator) {
 			iter.Skip()
 
 		default:
+			if queryTextFields[l1Field] && iter.WhatIsNext() == jsoniter.StringValue {
+				if query := iter.ReadString(); query != "" {
+					s.queries = append(s.queries, query)
+				}
+				continue
+			}
+
 			v := iter.Read()
 			logf("[Panel.TARGET] %s=%v\n", l1Field, v)
 		}
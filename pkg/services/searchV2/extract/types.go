@@ -12,6 +12,7 @@ type PanelInfo struct {
 	PluginVersion string                   `json:"pluginVersion,omitempty"`
 	Datasource    []dslookup.DataSourceRef `json:"datasource,omitempty"`  // UIDs
 	Transformer   []string                 `json:"transformer,omitempty"` // ids of the transformation steps
+	Queries       []string                 `json:"queries,omitempty"`     // the query expressions of the targets
 
 	// Rows define panels as sub objects
 	Collapsed []PanelInfo `json:"collapsed,omitempty"`
@@ -2,6 +2,7 @@ package searchV2
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/blugelabs/bluge"
 	"github.com/blugelabs/bluge/search"
@@ -11,29 +12,68 @@ import (
 )
 
 type PermissionFilter struct {
-	log    log.Logger
-	filter ResourceFilter
+	log          log.Logger
+	filter       ResourceFilter
+	entityFilter entityFilter
 }
 
+// entityFilter checks if we can read an entity which is not a folder, dashboard or panel. The parent
+// is the folder of the alert rules and library panels, and the source datasource of the correlations.
+// The datasources are the ones the entity uses.
+type entityFilter func(kind entityKind, uid string, parentUID string, dsUIDs []string) bool
+
 type entityKind string
 
 const (
-	entityKindPanel      entityKind = "panel"
-	entityKindDashboard  entityKind = "dashboard"
-	entityKindFolder     entityKind = "folder"
-	entityKindDatasource entityKind = "datasource"
+	entityKindPanel        entityKind = "panel"
+	entityKindDashboard    entityKind = "dashboard"
+	entityKindFolder       entityKind = "folder"
+	entityKindDatasource   entityKind = "datasource"
+	entityKindAlertRule    entityKind = "alertrule"
+	entityKindLibraryPanel entityKind = "librarypanel"
+	entityKindPlaylist     entityKind = "playlist"
+	entityKindCorrelation  entityKind = "correlation"
 )
 
+// indexedEntityKinds are the kinds indexed in their own index, besides the folders, dashboards and panels
+var indexedEntityKinds = []entityKind{
+	entityKindAlertRule,
+	entityKindLibraryPanel,
+	entityKindDatasource,
+	entityKindPlaylist,
+	entityKindCorrelation,
+}
+
 func (r entityKind) IsValid() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r.isIndexedEntity()
 }
 
 func (r entityKind) supportsAuthzCheck() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r.isIndexedEntity()
+}
+
+func (r entityKind) isIndexedEntity() bool {
+	for _, kind := range indexedEntityKinds {
+		if r == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesDatasources returns whether the entities of the kind use datasources, which are returned
+// with their allowed actions
+func (r entityKind) referencesDatasources() bool {
+	return r == entityKindDashboard || r == entityKindAlertRule || r == entityKindLibraryPanel || r == entityKindCorrelation
+}
+
+// isInFolder returns whether the entities of the kind are located in a folder
+func (r entityKind) isInFolder() bool {
+	return r == entityKindAlertRule || r == entityKindLibraryPanel
 }
 
 var (
-	permissionFilterFields                 = []string{documentFieldUID, documentFieldKind}
+	permissionFilterFields                 = []string{documentFieldUID, documentFieldKind, documentFieldLocation, documentFieldDSUID}
 	panelIdFieldRegex                      = regexp.MustCompile(`^(.*)#([0-9]{1,4})$`)
 	panelIdFieldDashboardUidSubmatchIndex  = 1
 	panelIdFieldPanelIdSubmatchIndex       = 2
@@ -42,10 +82,11 @@ var (
 	_ bluge.Query = (*PermissionFilter)(nil)
 )
 
-func newPermissionFilter(resourceFilter ResourceFilter, log log.Logger) *PermissionFilter {
+func newPermissionFilter(resourceFilter ResourceFilter, entityFilter entityFilter, log log.Logger) *PermissionFilter {
 	return &PermissionFilter{
-		filter: resourceFilter,
-		log:    log,
+		filter:       resourceFilter,
+		entityFilter: entityFilter,
+		log:          log,
 	}
 }
 
@@ -62,7 +103,7 @@ func (q *PermissionFilter) logAccessDecision(decision bool, kind interface{}, id
 	}
 }
 
-func (q *PermissionFilter) canAccess(kind entityKind, id string) bool {
+func (q *PermissionFilter) canAccess(kind entityKind, id string, location string, dsUIDs []string) bool {
 	if !kind.supportsAuthzCheck() {
 		q.logAccessDecision(false, kind, id, "entityDoesNotSupportAuthz")
 		return false
@@ -95,6 +136,20 @@ func (q *PermissionFilter) canAccess(kind entityKind, id string) bool {
 
 		q.logAccessDecision(decision, kind, id, "resourceFilter", "dashboardUid", dashboardUid, "panelId", matches[panelIdFieldPanelIdSubmatchIndex])
 		return decision
+	case entityKindAlertRule, entityKindLibraryPanel:
+		// The entities are in the last folder of their location, and visible with the folder.
+		folderUID := location[strings.LastIndex(location, "/")+1:]
+		if folderUID != "general" && !q.filter(folderUID) {
+			q.logAccessDecision(false, kind, id, "resourceFilter", "folderUid", folderUID)
+			return false
+		}
+		decision := q.entityFilter(kind, id, folderUID, dsUIDs)
+		q.logAccessDecision(decision, kind, id, "entityFilter", "folderUid", folderUID)
+		return decision
+	case entityKindDatasource, entityKindPlaylist, entityKindCorrelation:
+		decision := q.entityFilter(kind, id, location, dsUIDs)
+		q.logAccessDecision(decision, kind, id, "entityFilter")
+		return decision
 	default:
 		q.logAccessDecision(false, kind, id, "reason", "unknownKind")
 		return false
@@ -109,12 +164,18 @@ func (q *PermissionFilter) Searcher(i search.Reader, options search.SearcherOpti
 
 	s, err := searcher.NewMatchAllSearcher(i, 1, similarity.ConstantScorer(1), options)
 	return searcher.NewFilteringSearcher(s, func(d *search.DocumentMatch) bool {
-		var kind, id string
+		var kind, id, location string
+		var dsUIDs []string
 		err := dvReader.VisitDocumentValues(d.Number, func(field string, term []byte) {
-			if field == documentFieldKind {
+			switch field {
+			case documentFieldKind:
 				kind = string(term)
-			} else if field == documentFieldUID {
+			case documentFieldUID:
 				id = string(term)
+			case documentFieldLocation:
+				location = string(term)
+			case documentFieldDSUID:
+				dsUIDs = append(dsUIDs, string(term))
 			}
 		})
 		if err != nil {
@@ -128,6 +189,6 @@ func (q *PermissionFilter) Searcher(i search.Reader, options search.SearcherOpti
 			return false
 		}
 
-		return q.canAccess(e, id)
+		return q.canAccess(e, id, location, dsUIDs)
 	}), err
 }
@@ -15,6 +15,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/searchV2/dslookup"
 	"github.com/grafana/grafana/pkg/services/searchV2/extract"
@@ -84,9 +85,60 @@ func (i *orgIndex) readerForIndex(idxType indexType) (*bluge.Reader, func(), err
 	return reader, func() { _ = reader.Close() }, nil
 }
 
+// readersForKinds returns the readers of the indexes containing the entities of the kinds, or of all
+// the indexes when no kind is given.
+func (i *orgIndex) readersForKinds(kinds []string) ([]*bluge.Reader, func(), error) {
+	idxTypes := make(map[indexType]bool, len(i.writers))
+	for idxType := range i.writers {
+		idxTypes[idxType] = len(kinds) == 0
+	}
+	for _, kind := range kinds {
+		idxTypes[indexTypeForKind(entityKind(kind))] = true
+	}
+
+	var readers []*bluge.Reader
+	cancel := func() {
+		for _, reader := range readers {
+			_ = reader.Close()
+		}
+	}
+	// The readers are always in the same order, starting with the dashboard index.
+	for _, idxType := range append([]indexType{indexTypeDashboard}, indexTypesForEntities()...) {
+		writer, ok := i.writers[idxType]
+		if !ok || !idxTypes[idxType] {
+			continue
+		}
+		reader, err := writer.Reader()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		readers = append(readers, reader)
+	}
+	return readers, cancel, nil
+}
+
+// indexTypeForKind returns the index of the entities of the kind. The folders, dashboards and panels
+// share the dashboard index, the other kinds have their own index so that their uids don't collide.
+func indexTypeForKind(kind entityKind) indexType {
+	if kind.isIndexedEntity() {
+		return indexType(kind)
+	}
+	return indexTypeDashboard
+}
+
+func indexTypesForEntities() []indexType {
+	idxTypes := make([]indexType, 0, len(indexedEntityKinds))
+	for _, kind := range indexedEntityKinds {
+		idxTypes = append(idxTypes, indexTypeForKind(kind))
+	}
+	return idxTypes
+}
+
 type searchIndex struct {
 	mu                      sync.RWMutex
 	loader                  dashboardLoader
+	entityLoader            entityLoader
 	perOrgIndex             map[int64]*orgIndex
 	initializedOrgs         map[int64]bool
 	initialIndexingComplete bool
@@ -102,9 +154,10 @@ type searchIndex struct {
 	settings                setting.SearchSettings
 }
 
-func newSearchIndex(dashLoader dashboardLoader, evStore eventStore, extender DocumentExtender, folderIDs folderUIDLookup, tracer tracing.Tracer, features featuremgmt.FeatureToggles, settings setting.SearchSettings) *searchIndex {
+func newSearchIndex(dashLoader dashboardLoader, entLoader entityLoader, evStore eventStore, extender DocumentExtender, folderIDs folderUIDLookup, tracer tracing.Tracer, features featuremgmt.FeatureToggles, settings setting.SearchSettings) *searchIndex {
 	return &searchIndex{
 		loader:          dashLoader,
+		entityLoader:    entLoader,
 		eventStore:      evStore,
 		perOrgIndex:     map[int64]*orgIndex{},
 		initializedOrgs: map[int64]bool{},
@@ -484,13 +537,24 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 	}
 	i.logger.Info("Finish loading org dashboards", "elapsed", orgSearchIndexLoadTime, "orgId", orgID)
 
+	var entities []entity
+	for _, kind := range indexedEntityKinds {
+		kindEntities, err := i.entityLoader.LoadEntities(ctx, orgID, kind, "")
+		if err != nil {
+			return 0, fmt.Errorf("error loading %s entities: %w", kind, err)
+		}
+		entities = append(entities, kindEntities...)
+	}
+	orgSearchIndexLoadTime = time.Since(started)
+	i.logger.Info("Finish loading org entities", "elapsed", orgSearchIndexLoadTime, "orgId", orgID, "numEntities", len(entities))
+
 	dashboardExtender := i.extender.GetDashboardExtender(orgID)
 
 	_, initOrgIndexSpan := i.tracer.Start(ctx, "searchV2 buildOrgIndex init org index")
 	initOrgIndexSpan.SetAttributes("org_id", orgID, attribute.Key("org_id").Int64(orgID))
 	initOrgIndexSpan.SetAttributes("dashboardCount", len(dashboards), attribute.Key("dashboardCount").Int(len(dashboards)))
 
-	index, err := initOrgIndex(dashboards, i.logger, dashboardExtender)
+	index, err := initOrgIndex(dashboards, entities, i.logger, dashboardExtender)
 
 	initOrgIndexSpan.End()
 
@@ -642,6 +706,15 @@ func (i *searchIndex) applyEventOnIndex(ctx context.Context, e *store.EntityEven
 	return i.applyEvent(ctx, orgID, kind, uid, e.EventType)
 }
 
+// entityKindsByEntityType are the kinds of the entities indexed in their own index, by entity event type
+var entityKindsByEntityType = map[store.EntityType]entityKind{
+	store.EntityTypeAlertRule:    entityKindAlertRule,
+	store.EntityTypeLibraryPanel: entityKindLibraryPanel,
+	store.EntityTypeDatasource:   entityKindDatasource,
+	store.EntityTypePlaylist:     entityKindPlaylist,
+	store.EntityTypeCorrelation:  entityKindCorrelation,
+}
+
 func (i *searchIndex) applyEvent(ctx context.Context, orgID int64, kind store.EntityType, uid string, _ store.EntityEventType) error {
 	i.mu.Lock()
 	_, ok := i.perOrgIndex[orgID]
@@ -652,6 +725,10 @@ func (i *searchIndex) applyEvent(ctx context.Context, orgID int64, kind store.En
 	}
 	i.mu.Unlock()
 
+	if entKind, ok := entityKindsByEntityType[kind]; ok {
+		return i.applyEntityEvent(ctx, orgID, entKind, uid)
+	}
+
 	// Both dashboard and folder share same DB table.
 	dbDashboards, err := i.loader.LoadDashboards(ctx, orgID, uid)
 	if err != nil {
@@ -686,6 +763,43 @@ func (i *searchIndex) applyEvent(ctx context.Context, orgID int64, kind store.En
 	return nil
 }
 
+func (i *searchIndex) applyEntityEvent(ctx context.Context, orgID int64, kind entityKind, uid string) error {
+	entities, err := i.entityLoader.LoadEntities(ctx, orgID, kind, uid)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	index, ok := i.perOrgIndex[orgID]
+	if !ok {
+		// Skip event for org not yet fully indexed.
+		return nil
+	}
+
+	if len(entities) == 0 {
+		return i.removeEntity(ctx, index, kind, uid)
+	}
+	return i.updateEntity(ctx, index, entities[0])
+}
+
+func (i *searchIndex) updateEntity(_ context.Context, index *orgIndex, e entity) error {
+	location, err := getEntityLocation(e, func(folderUID string) (string, error) {
+		folderLocation, _, err := getFolderLocation(index, folderUID)
+		return joinLocation(folderLocation, folderUID), err
+	})
+	if err != nil {
+		return err
+	}
+	doc := getEntityDoc(e, location)
+	return index.writerForIndex(indexTypeForKind(e.kind)).Update(doc.ID(), doc)
+}
+
+func (i *searchIndex) removeEntity(_ context.Context, index *orgIndex, kind entityKind, uid string) error {
+	return index.writerForIndex(indexTypeForKind(kind)).Delete(bluge.NewDocument(uid).ID())
+}
+
 func (i *searchIndex) removeDashboard(_ context.Context, index *orgIndex, dashboardUID string) error {
 	dashboardLocation, ok, err := getDashboardLocation(index, dashboardUID)
 	if err != nil {
@@ -717,8 +831,62 @@ func (i *searchIndex) removeDashboard(_ context.Context, index *orgIndex, dashbo
 	return writer.Batch(batch)
 }
 
+// updateFolderContents reindexes the folders, dashboards, alert rules and library panels located under
+// the given folder path.
+func (i *searchIndex) updateFolderContents(ctx context.Context, orgID int64, index *orgIndex, folderPath string) error {
+	ids, err := getDocsIDsByLocationPrefix(index, folderPath)
+	if err != nil {
+		return fmt.Errorf("error getting by location prefix: %w", err)
+	}
+
+	for _, kind := range indexedEntityKinds {
+		if !kind.isInFolder() {
+			continue
+		}
+		entityIDs, err := getIndexDocsIDsByLocationPrefix(index, indexTypeForKind(kind), folderPath)
+		if err != nil {
+			return fmt.Errorf("error getting by location prefix: %w", err)
+		}
+		for _, id := range entityIDs {
+			entities, err := i.entityLoader.LoadEntities(ctx, orgID, kind, id)
+			if err != nil {
+				return err
+			}
+			if len(entities) == 0 {
+				continue
+			}
+			if err := i.updateEntity(ctx, index, entities[0]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if strings.Contains(id, "#") {
+			// Panels are reindexed along with their dashboard.
+			continue
+		}
+		dbDashboards, err := i.loader.LoadDashboards(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if len(dbDashboards) == 0 {
+			continue
+		}
+		if err := i.updateDashboard(ctx, orgID, index, dbDashboards[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (i *searchIndex) removeFolder(_ context.Context, index *orgIndex, folderUID string) error {
-	ids, err := getDocsIDsByLocationPrefix(index, folderUID)
+	folderLocation, _, err := getFolderLocation(index, folderUID)
+	if err != nil {
+		return err
+	}
+
+	ids, err := getDocsIDsByLocationPrefix(index, joinLocation(folderLocation, folderUID))
 	if err != nil {
 		return fmt.Errorf("error getting by location prefix: %w", err)
 	}
@@ -729,7 +897,35 @@ func (i *searchIndex) removeFolder(_ context.Context, index *orgIndex, folderUID
 		batch.Delete(bluge.NewDocument(id).ID())
 	}
 	writer := index.writerForIndex(indexTypeDashboard)
-	return writer.Batch(batch)
+	if err := writer.Batch(batch); err != nil {
+		return err
+	}
+
+	// The alert rules and library panels are deleted with their folder.
+	for _, kind := range indexedEntityKinds {
+		if !kind.isInFolder() {
+			continue
+		}
+		entityIDs, err := getIndexDocsIDsByLocationPrefix(index, indexTypeForKind(kind), joinLocation(folderLocation, folderUID))
+		if err != nil {
+			return fmt.Errorf("error getting by location prefix: %w", err)
+		}
+		batch := bluge.NewBatch()
+		for _, id := range entityIDs {
+			batch.Delete(bluge.NewDocument(id).ID())
+		}
+		if err := index.writerForIndex(indexTypeForKind(kind)).Batch(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinLocation(location, uid string) string {
+	if location == "" {
+		return uid
+	}
+	return location + "/" + uid
 }
 
 func stringInSlice(str string, slice []string) bool {
@@ -748,11 +944,30 @@ func (i *searchIndex) updateDashboard(ctx context.Context, orgID int64, index *o
 
 	var doc *bluge.Document
 	if dash.isFolder {
-		doc = getFolderDashboardDoc(dash)
+		var location string
+		if dash.folderID > 0 {
+			var err error
+			location, err = i.folderIdLookup(ctx, dash.folderID)
+			if err != nil {
+				return err
+			}
+		}
+		prevLocation, found, err := getFolderLocation(index, dash.uid)
+		if err != nil {
+			return err
+		}
+		doc = getFolderDashboardDoc(dash, location)
 		if err := extendDoc(dash.uid, doc); err != nil {
 			return err
 		}
-		return writer.Update(doc.ID(), doc)
+		if err := writer.Update(doc.ID(), doc); err != nil {
+			return err
+		}
+		if found && prevLocation != location {
+			// The folder was moved, so everything it contains has a new location.
+			return i.updateFolderContents(ctx, orgID, index, joinLocation(prevLocation, dash.uid))
+		}
+		return nil
 	}
 
 	batch := bluge.NewBatch()
@@ -922,20 +1137,34 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 	return dashboards, err
 }
 
+// newFolderIDLookup returns a lookup resolving a folder id into the full path of the folder,
+// which for nested folders includes the uids of all the parent folders.
 func newFolderIDLookup(sql *sqlstore.SQLStore) folderUIDLookup {
 	return func(ctx context.Context, folderID int64) (string, error) {
-		uid := ""
+		path := ""
 		err := sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
-			res, err := sess.Query("SELECT uid FROM dashboard WHERE id=?", folderID)
-			if err != nil {
-				return err
-			}
-			if len(res) > 0 {
-				uid = string(res[0]["uid"])
+			id := folderID
+			for depth := 0; id > 0 && depth <= dashboards.MaxNestedFolderDepth; depth++ {
+				res, err := sess.Query("SELECT uid, folder_id FROM dashboard WHERE id=?", id)
+				if err != nil {
+					return err
+				}
+				if len(res) == 0 {
+					return nil
+				}
+				if path == "" {
+					path = string(res[0]["uid"])
+				} else {
+					path = string(res[0]["uid"]) + "/" + path
+				}
+				id, err = strconv.ParseInt(string(res[0]["folder_id"]), 10, 64)
+				if err != nil {
+					return err
+				}
 			}
 			return nil
 		})
-		return uid, err
+		return path, err
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/searchV2/dslookup"
 	"github.com/grafana/grafana/pkg/services/searchV2/extract"
 	"github.com/grafana/grafana/pkg/services/store"
 
@@ -29,6 +31,20 @@ func (t *testDashboardLoader) LoadDashboards(_ context.Context, _ int64, _ strin
 	return t.dashboards, nil
 }
 
+type testEntityLoader struct {
+	entities []entity
+}
+
+func (t *testEntityLoader) LoadEntities(_ context.Context, _ int64, kind entityKind, uid string) ([]entity, error) {
+	var entities []entity
+	for _, e := range t.entities {
+		if e.kind == kind && (uid == "" || e.uid == uid) {
+			entities = append(entities, e)
+		}
+	}
+	return entities, nil
+}
+
 var testLogger = log.New("index-test-logger")
 
 var testAllowAllFilter = func(uid string) bool {
@@ -39,6 +55,10 @@ var testDisallowAllFilter = func(uid string) bool {
 	return false
 }
 
+var testAllowAllEntityFilter = func(kind entityKind, uid string, parentUID string, dsUIDs []string) bool {
+	return true
+}
+
 var testOrgID int64 = 1
 
 func initTestOrgIndexFromDashes(t *testing.T, dashboards []dashboard) *orgIndex {
@@ -59,11 +79,16 @@ func initTestIndexFromDashes(t *testing.T, dashboards []dashboard) *searchIndex
 }
 
 func initTestIndexFromDashesExtended(t *testing.T, dashboards []dashboard, extender DocumentExtender) *searchIndex {
+	t.Helper()
+	return initTestIndexFromDashesAndEntities(t, dashboards, &testEntityLoader{}, extender)
+}
+
+func initTestIndexFromDashesAndEntities(t *testing.T, dashboards []dashboard, entityLoader *testEntityLoader, extender DocumentExtender) *searchIndex {
 	t.Helper()
 	dashboardLoader := &testDashboardLoader{
 		dashboards: dashboards,
 	}
-	index := newSearchIndex(dashboardLoader, &store.MockEntityEventsService{}, extender, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
+	index := newSearchIndex(dashboardLoader, entityLoader, &store.MockEntityEventsService{}, extender, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
 	require.NotNil(t, index)
 	numDashboards, err := index.buildOrgIndex(context.Background(), testOrgID)
 	require.NoError(t, err)
@@ -78,7 +103,7 @@ func checkSearchResponse(t *testing.T, fileName string, index *orgIndex, filter
 
 func checkSearchResponseExtended(t *testing.T, fileName string, index *orgIndex, filter ResourceFilter, query DashboardQuery, extender QueryExtender) {
 	t.Helper()
-	resp := doSearchQuery(context.Background(), testLogger, index, filter, query, extender, "/pfix")
+	resp := doSearchQuery(context.Background(), testLogger, index, filter, testAllowAllEntityFilter, query, extender, "/pfix")
 	experimental.CheckGoldenJSONResponse(t, "testdata", fileName, resp, true)
 }
 
@@ -105,7 +130,7 @@ func checkSearchResponseOrdering(t *testing.T, fileName string, index *orgIndex,
 func checkSearchResponseOrderingExtended(t *testing.T, fileName string, index *orgIndex, filter ResourceFilter, query DashboardQuery, extender QueryExtender) {
 	t.Helper()
 	query.Explain = true
-	resp := doSearchQuery(context.Background(), testLogger, index, filter, query, extender, "/pfix")
+	resp := doSearchQuery(context.Background(), testLogger, index, filter, testAllowAllEntityFilter, query, extender, "/pfix")
 	experimental.CheckGoldenJSONFrame(t, "testdata", fileName, getFrameWithNames(resp), true)
 }
 
@@ -479,7 +504,7 @@ func TestDashboardIndex_Folders(t *testing.T) {
 	t.Run("folders-dashboard-has-folder", func(t *testing.T) {
 		index := initTestOrgIndexFromDashes(t, dashboardsWithFolders)
 		// TODO: golden file compare does not work here.
-		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter,
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, testAllowAllEntityFilter,
 			DashboardQuery{Query: "Dashboard in folder", Kind: []string{string(entityKindDashboard)}},
 			&NoopQueryExtender{}, "")
 		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
@@ -504,7 +529,7 @@ func TestDashboardIndex_Folders(t *testing.T) {
 		require.True(t, ok)
 		err := index.removeFolder(context.Background(), orgIdx, "1")
 		require.NoError(t, err)
-		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter,
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter, testAllowAllEntityFilter,
 			DashboardQuery{Query: "Panel", Kind: []string{string(entityKindPanel)}},
 			&NoopQueryExtender{}, "")
 		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
@@ -513,6 +538,79 @@ func TestDashboardIndex_Folders(t *testing.T) {
 	})
 }
 
+var dashboardsWithNestedFolders = []dashboard{
+	{
+		id:       1,
+		uid:      "parent",
+		isFolder: true,
+		info: &extract.DashboardInfo{
+			Title: "Parent folder",
+		},
+	},
+	{
+		id:       2,
+		uid:      "child",
+		isFolder: true,
+		folderID: 1,
+		info: &extract.DashboardInfo{
+			Title: "Child folder",
+		},
+	},
+	{
+		id:       3,
+		uid:      "nested",
+		folderID: 2,
+		info: &extract.DashboardInfo{
+			Title: "Nested dashboard",
+			Panels: []extract.PanelInfo{
+				{
+					ID:    1,
+					Title: "Nested panel",
+				},
+			},
+		},
+	},
+}
+
+func TestDashboardIndex_NestedFolders(t *testing.T) {
+	t.Run("nested-folders-location-is-full-path", func(t *testing.T) {
+		index := initTestOrgIndexFromDashes(t, dashboardsWithNestedFolders)
+
+		location, found, err := getFolderLocation(index, "child")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, "parent", location)
+
+		location, found, err = getDashboardLocation(index, "nested")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, "parent/child", location)
+	})
+	t.Run("nested-folders-query-by-folder-uid", func(t *testing.T) {
+		index := initTestOrgIndexFromDashes(t, dashboardsWithNestedFolders)
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Location: "child", Kind: []string{string(entityKindDashboard)}},
+			&NoopQueryExtender{}, "")
+		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
+		require.True(t, ok)
+		require.Equal(t, uint64(1), custom.Count)
+		require.Equal(t, "/dashboards/f/parent/", custom.Locations["parent"].URL)
+	})
+	t.Run("nested-folders-removed-on-parent-removed", func(t *testing.T) {
+		index := initTestIndexFromDashes(t, dashboardsWithNestedFolders)
+		orgIdx, ok := index.getOrgIndex(testOrgID)
+		require.True(t, ok)
+		err := index.removeFolder(context.Background(), orgIdx, "child")
+		require.NoError(t, err)
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "Nested", Kind: []string{string(entityKindDashboard), string(entityKindPanel)}},
+			&NoopQueryExtender{}, "")
+		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
+		require.True(t, ok)
+		require.Equal(t, uint64(0), custom.Count)
+	})
+}
+
 var dashboardsWithPanels = []dashboard{
 	{
 		id:  1,
@@ -538,7 +636,7 @@ func TestDashboardIndex_Panels(t *testing.T) {
 		index := initTestOrgIndexFromDashes(t, dashboardsWithPanels)
 		// TODO: golden file compare does not work here.
 		resp := doSearchQuery(
-			context.Background(), testLogger, index, testAllowAllFilter,
+			context.Background(), testLogger, index, testAllowAllFilter, testAllowAllEntityFilter,
 			DashboardQuery{Query: "Panel", Kind: []string{string(entityKindPanel)}},
 			&NoopQueryExtender{}, "")
 		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
@@ -741,3 +839,344 @@ func TestDashboardIndex_MultiTermPrefixMatch(t *testing.T) {
 		})
 	}
 }
+
+var testEntities = []entity{
+	{
+		kind:      entityKindAlertRule,
+		uid:       "rule-1",
+		name:      "High CPU usage",
+		folderUID: "1",
+		url:       "/alerting/grafana/rule-1/view",
+		tags:      []string{"team=backend"},
+		ds:        []dslookup.DataSourceRef{{UID: "prom", Type: "prometheus"}},
+	},
+	{
+		kind:      entityKindLibraryPanel,
+		uid:       "panel-1",
+		name:      "CPU usage graph",
+		panelType: "timeseries",
+		url:       "/library-panels",
+		ds:        []dslookup.DataSourceRef{{UID: "prom", Type: "prometheus"}},
+	},
+	{
+		kind: entityKindDatasource,
+		uid:  "prom",
+		name: "Prometheus CPU",
+		url:  "/datasources/edit/prom",
+		ds:   []dslookup.DataSourceRef{{Type: "prometheus"}},
+	},
+	{
+		kind: entityKindPlaylist,
+		uid:  "playlist-1",
+		name: "CPU dashboards",
+		url:  "/playlists/play/playlist-1",
+	},
+	{
+		kind:     entityKindCorrelation,
+		uid:      "correlation-1",
+		name:     "CPU to logs",
+		location: "prom",
+		url:      "/datasources/correlations",
+		ds:       []dslookup.DataSourceRef{{UID: "prom", Type: "prometheus"}, {UID: "loki", Type: "loki"}},
+	},
+}
+
+func getResponseUIDs(t *testing.T, resp *backend.DataResponse) []string {
+	t.Helper()
+	require.NoError(t, resp.Error)
+	require.Len(t, resp.Frames, 1)
+	field, idx := resp.Frames[0].FieldByName("uid")
+	require.NotEqual(t, -1, idx)
+	uids := make([]string, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		uids = append(uids, field.At(i).(string))
+	}
+	return uids
+}
+
+func TestDashboardIndex_Entities(t *testing.T) {
+	t.Run("entities-indexed", func(t *testing.T) {
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, &testEntityLoader{entities: testEntities}, &NoopDocumentExtender{})
+		checkSearchResponse(t, filepath.Base(t.Name()), index.perOrgIndex[testOrgID], testAllowAllFilter,
+			DashboardQuery{Query: "cpu", Sort: "name_sort"},
+		)
+	})
+
+	t.Run("entities-search-by-kind", func(t *testing.T) {
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, &testEntityLoader{entities: testEntities}, &NoopDocumentExtender{})
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "cpu", Kind: []string{string(entityKindAlertRule), string(entityKindPlaylist)}},
+			&NoopQueryExtender{}, "")
+		require.ElementsMatch(t, []string{"rule-1", "playlist-1"}, getResponseUIDs(t, resp))
+	})
+
+	t.Run("entities-kind-facet", func(t *testing.T) {
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, &testEntityLoader{entities: testEntities}, &NoopDocumentExtender{})
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "cpu", Facet: []FacetField{{Field: documentFieldKind}}},
+			&NoopQueryExtender{}, "")
+		require.Len(t, resp.Frames, 2)
+		counts := map[string]uint64{}
+		for i := 0; i < resp.Frames[1].Rows(); i++ {
+			counts[resp.Frames[1].Fields[0].At(i).(string)] = resp.Frames[1].Fields[1].At(i).(uint64)
+		}
+		require.Equal(t, map[string]uint64{
+			string(entityKindAlertRule):    1,
+			string(entityKindLibraryPanel): 1,
+			string(entityKindDatasource):   1,
+			string(entityKindPlaylist):     1,
+			string(entityKindCorrelation):  1,
+		}, counts)
+	})
+
+	t.Run("entities-alert-rule-in-folder", func(t *testing.T) {
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, &testEntityLoader{entities: testEntities}, &NoopDocumentExtender{})
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Location: "1", Kind: []string{string(entityKindAlertRule)}},
+			&NoopQueryExtender{}, "")
+		require.Equal(t, []string{"rule-1"}, getResponseUIDs(t, resp))
+	})
+
+	t.Run("entities-filtered-by-folder-permissions", func(t *testing.T) {
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, &testEntityLoader{entities: testEntities}, &NoopDocumentExtender{})
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testDisallowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "cpu"},
+			&NoopQueryExtender{}, "")
+		// the alert rule is in a folder the user can't read, the library panel is in the General folder
+		require.ElementsMatch(t, []string{"panel-1", "prom", "playlist-1", "correlation-1"}, getResponseUIDs(t, resp))
+	})
+
+	t.Run("entities-filtered-by-entity-permissions", func(t *testing.T) {
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, &testEntityLoader{entities: testEntities}, &NoopDocumentExtender{})
+		var checked []string
+		entityFilter := func(kind entityKind, uid string, parentUID string, dsUIDs []string) bool {
+			checked = append(checked, fmt.Sprintf("%s/%s/%s/%s", kind, uid, parentUID, strings.Join(dsUIDs, ",")))
+			return kind != entityKindDatasource && kind != entityKindCorrelation
+		}
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testAllowAllFilter, entityFilter,
+			DashboardQuery{Query: "cpu"},
+			&NoopQueryExtender{}, "")
+		require.ElementsMatch(t, []string{"rule-1", "panel-1", "playlist-1"}, getResponseUIDs(t, resp))
+		require.ElementsMatch(t, []string{
+			"alertrule/rule-1/1/prom",
+			"librarypanel/panel-1/general/prom",
+			"datasource/prom//",
+			"playlist/playlist-1//",
+			"correlation/correlation-1/prom/loki,prom",
+		}, checked)
+	})
+}
+
+func TestDashboardIndexUpdates_Entities(t *testing.T) {
+	t.Run("entity-create", func(t *testing.T) {
+		loader := &testEntityLoader{entities: testEntities}
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, loader, &NoopDocumentExtender{})
+		loader.entities = append(loader.entities, entity{kind: entityKindPlaylist, uid: "playlist-2", name: "Memory dashboards"})
+
+		err := index.applyEvent(context.Background(), testOrgID, store.EntityTypePlaylist, "playlist-2", store.EntityEventTypeCreate)
+		require.NoError(t, err)
+
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "memory"},
+			&NoopQueryExtender{}, "")
+		require.Equal(t, []string{"playlist-2"}, getResponseUIDs(t, resp))
+	})
+
+	t.Run("entity-update", func(t *testing.T) {
+		loader := &testEntityLoader{entities: testEntities}
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, loader, &NoopDocumentExtender{})
+		updated := testEntities[0]
+		updated.name = "High memory usage"
+		updated.folderUID = ""
+		loader.entities = []entity{updated}
+
+		err := index.applyEvent(context.Background(), testOrgID, store.EntityTypeAlertRule, "rule-1", store.EntityEventTypeUpdate)
+		require.NoError(t, err)
+
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "memory", Kind: []string{string(entityKindAlertRule)}},
+			&NoopQueryExtender{}, "")
+		require.Equal(t, []string{"rule-1"}, getResponseUIDs(t, resp))
+		location, _ := resp.Frames[0].FieldByName("location")
+		require.Equal(t, "general", location.At(0))
+	})
+
+	t.Run("entity-delete", func(t *testing.T) {
+		loader := &testEntityLoader{entities: testEntities}
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, loader, &NoopDocumentExtender{})
+		loader.entities = testEntities[1:]
+
+		err := index.applyEvent(context.Background(), testOrgID, store.EntityTypeAlertRule, "rule-1", store.EntityEventTypeDelete)
+		require.NoError(t, err)
+
+		resp := doSearchQuery(context.Background(), testLogger, index.perOrgIndex[testOrgID], testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "cpu", Kind: []string{string(entityKindAlertRule)}},
+			&NoopQueryExtender{}, "")
+		require.Empty(t, getResponseUIDs(t, resp))
+	})
+
+	t.Run("entity-removed-on-folder-removed", func(t *testing.T) {
+		index := initTestIndexFromDashesAndEntities(t, dashboardsWithFolders, &testEntityLoader{entities: testEntities}, &NoopDocumentExtender{})
+		orgIdx, ok := index.getOrgIndex(testOrgID)
+		require.True(t, ok)
+		err := index.removeFolder(context.Background(), orgIdx, "1")
+		require.NoError(t, err)
+
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter, testAllowAllEntityFilter,
+			DashboardQuery{Query: "cpu", Kind: []string{string(entityKindAlertRule), string(entityKindLibraryPanel)}},
+			&NoopQueryExtender{}, "")
+		require.Equal(t, []string{"panel-1"}, getResponseUIDs(t, resp))
+	})
+}
+
+var dashboardsWithQueries = []dashboard{
+	{
+		id:       1,
+		uid:      "scratch",
+		isFolder: true,
+		info: &extract.DashboardInfo{
+			Title: "Scratch",
+		},
+	},
+	{
+		id:  2,
+		uid: "api",
+		info: &extract.DashboardInfo{
+			Title: "API",
+			Tags:  []string{"prod"},
+			Panels: []extract.PanelInfo{
+				{
+					ID:         1,
+					Title:      "Requests",
+					Type:       "timeseries",
+					Datasource: []dslookup.DataSourceRef{{UID: "prom", Type: "prometheus"}},
+					Queries:    []string{`sum(rate(http_requests_total{job="api"}[5m]))`},
+				},
+				{
+					ID:    2,
+					Title: "Logs",
+					Type:  "row",
+					Collapsed: []extract.PanelInfo{
+						{
+							ID:      3,
+							Title:   "Errors",
+							Type:    "logs",
+							Queries: []string{`{app="api"} |= "error"`},
+						},
+					},
+				},
+			},
+			Datasource: []dslookup.DataSourceRef{{UID: "prom", Type: "prometheus"}},
+		},
+	},
+	{
+		id:       3,
+		uid:      "api-copy",
+		folderID: 1,
+		info: &extract.DashboardInfo{
+			Title: "API copy",
+			Tags:  []string{"prod"},
+			Panels: []extract.PanelInfo{
+				{
+					ID:         1,
+					Title:      "Requests",
+					Type:       "stat",
+					Datasource: []dslookup.DataSourceRef{{UID: "prom", Type: "prometheus"}},
+					Queries:    []string{`sum(http_requests_total)`},
+				},
+			},
+			Datasource: []dslookup.DataSourceRef{{UID: "prom", Type: "prometheus"}},
+		},
+	},
+	{
+		id:  4,
+		uid: "db",
+		info: &extract.DashboardInfo{
+			Title: "Database",
+			Panels: []extract.PanelInfo{
+				{
+					ID:         1,
+					Title:      "Connections",
+					Type:       "timeseries",
+					Datasource: []dslookup.DataSourceRef{{UID: "mysql", Type: "mysql"}},
+					Queries:    []string{"SELECT count(*) FROM connections"},
+				},
+			},
+			Datasource: []dslookup.DataSourceRef{{UID: "mysql", Type: "mysql"}},
+		},
+	},
+}
+
+func TestDashboardIndex_QueryFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		kind     []string
+		expected []string
+	}{
+		{
+			name:     "metric",
+			query:    "metric:http_requests_total",
+			kind:     []string{string(entityKindDashboard)},
+			expected: []string{"api", "api-copy"},
+		},
+		{
+			name:     "metric-panels",
+			query:    "metric:http_requests_total",
+			kind:     []string{string(entityKindPanel)},
+			expected: []string{"api#1", "api-copy#1"},
+		},
+		{
+			name:     "metric-prefix",
+			query:    "metric:HTTP_*",
+			kind:     []string{string(entityKindDashboard)},
+			expected: []string{"api", "api-copy"},
+		},
+		{
+			name:     "metric-in-collapsed-row",
+			query:    "metric:error",
+			kind:     []string{string(entityKindDashboard)},
+			expected: []string{"api"},
+		},
+		{
+			name:     "query-text",
+			query:    `query:"from connections"`,
+			expected: []string{"db", "db#1"},
+		},
+		{
+			name:     "datasource-type",
+			query:    "ds:mysql kind:dashboard",
+			expected: []string{"db"},
+		},
+		{
+			name:     "excluded-folder-by-title",
+			query:    "metric:http_requests_total tag:prod -folder:scratch",
+			expected: []string{"api"},
+		},
+		{
+			name:     "folder-by-uid",
+			query:    "folder:scratch",
+			expected: []string{"api-copy", "api-copy#1"},
+		},
+		{
+			name:     "unknown-folder",
+			query:    "folder:unknown",
+			expected: []string{},
+		},
+		{
+			name:     "panel-type-and-text",
+			query:    "type:timeseries requests",
+			expected: []string{"api#1"},
+		},
+	}
+
+	index := initTestOrgIndexFromDashes(t, dashboardsWithQueries)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, testAllowAllEntityFilter,
+				DashboardQuery{Query: tt.query, Kind: tt.kind},
+				&NoopQueryExtender{}, "")
+			require.ElementsMatch(t, tt.expected, getResponseUIDs(t, resp))
+		})
+	}
+}
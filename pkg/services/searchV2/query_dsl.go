@@ -0,0 +1,216 @@
+package searchV2
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/blugelabs/bluge"
+	"github.com/blugelabs/bluge/analysis"
+	"github.com/blugelabs/bluge/analysis/token"
+	"github.com/blugelabs/bluge/analysis/tokenizer"
+)
+
+// The query string supports filters written as `key:value`, like `metric:http_requests_total ds:prom
+// tag:prod -folder:scratch`. The filters prefixed with `-` exclude the matching documents, the values
+// with spaces are quoted (`tag:"my tag"`) and the rest of the query string searches the names.
+const (
+	queryFilterMetric = "metric" // identifier used by the panel queries, like a metric name, `*` suffix for a prefix
+	queryFilterQuery  = "query"  // words of the panel queries
+	queryFilterDS     = "ds"     // datasource uid or type
+	queryFilterTag    = "tag"
+	queryFilterKind   = "kind"
+	queryFilterType   = "type"   // panel type
+	queryFilterFolder = "folder" // folder uid or title, the documents in its nested folders match too
+)
+
+var queryFilterKeys = map[string]bool{
+	queryFilterMetric: true,
+	queryFilterQuery:  true,
+	queryFilterDS:     true,
+	queryFilterTag:    true,
+	queryFilterKind:   true,
+	queryFilterType:   true,
+	queryFilterFolder: true,
+}
+
+// queryTextAnalyzer splits the panel queries into the identifiers they use: metric names, labels,
+// functions... `sum(rate(http_requests_total[5m]))` is indexed as sum, rate, http_requests_total and 5m.
+var queryTextAnalyzer = &analysis.Analyzer{
+	Tokenizer: tokenizer.NewCharacterTokenizer(isQueryTextTokenRune),
+	TokenFilters: []analysis.TokenFilter{
+		token.NewLowerCaseFilter(),
+	},
+}
+
+func isQueryTextTokenRune(r rune) bool {
+	// `:` is part of the name of the Prometheus recording rules
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == ':'
+}
+
+type queryFilter struct {
+	key     string
+	value   string
+	negated bool
+}
+
+type parsedQuery struct {
+	text    string
+	filters []queryFilter
+}
+
+// parseQuery extracts the filters of the query string. The text is the query string without the filters.
+func parseQuery(query string) parsedQuery {
+	var parsed parsedQuery
+	var text []string
+	for _, term := range splitQueryTerms(query) {
+		if filter, ok := parseQueryFilter(term); ok {
+			parsed.filters = append(parsed.filters, filter)
+			continue
+		}
+		text = append(text, term)
+	}
+
+	if len(parsed.filters) == 0 {
+		// Keep the query as it was written when there is no filter.
+		parsed.text = query
+	} else {
+		parsed.text = strings.Join(text, " ")
+	}
+	return parsed
+}
+
+// splitQueryTerms splits the query on the spaces which are not quoted
+func splitQueryTerms(query string) []string {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+func parseQueryFilter(term string) (queryFilter, bool) {
+	filter := queryFilter{}
+	if strings.HasPrefix(term, "-") {
+		filter.negated = true
+		term = term[1:]
+	}
+
+	idx := strings.Index(term, ":")
+	if idx < 0 {
+		return filter, false
+	}
+	filter.key = term[:idx]
+	filter.value = term[idx+1:]
+	if len(filter.value) >= 2 && strings.HasPrefix(filter.value, `"`) && strings.HasSuffix(filter.value, `"`) {
+		filter.value = filter.value[1 : len(filter.value)-1]
+	}
+
+	if !queryFilterKeys[filter.key] || filter.value == "" {
+		return filter, false
+	}
+	return filter, true
+}
+
+// blugeQuery returns the query matching the documents of the filter. The reader of the dashboard index
+// is used to resolve the folders.
+func (f queryFilter) blugeQuery(reader *bluge.Reader) (bluge.Query, error) {
+	switch f.key {
+	case queryFilterMetric:
+		value := strings.ToLower(f.value)
+		if strings.HasSuffix(value, "*") {
+			return bluge.NewPrefixQuery(strings.TrimSuffix(value, "*")).SetField(documentFieldQuery), nil
+		}
+		return bluge.NewTermQuery(value).SetField(documentFieldQuery), nil
+
+	case queryFilterQuery:
+		return bluge.NewMatchQuery(f.value).
+			SetField(documentFieldQuery).
+			SetAnalyzer(queryTextAnalyzer).
+			SetOperator(bluge.MatchQueryOperatorAnd), nil
+
+	case queryFilterDS:
+		return bluge.NewBooleanQuery().
+			AddShould(bluge.NewTermQuery(f.value).SetField(documentFieldDSUID)).
+			AddShould(bluge.NewTermQuery(f.value).SetField(documentFieldDSType)), nil
+
+	case queryFilterTag:
+		return bluge.NewTermQuery(f.value).SetField(documentFieldTag), nil
+
+	case queryFilterKind:
+		return bluge.NewTermQuery(f.value).SetField(documentFieldKind), nil
+
+	case queryFilterType:
+		return bluge.NewTermQuery(f.value).SetField(documentFieldPanelType), nil
+
+	case queryFilterFolder:
+		paths, err := getFolderPathsByUIDOrTitle(reader, f.value)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return bluge.NewMatchNoneQuery(), nil
+		}
+		bq := bluge.NewBooleanQuery()
+		for _, path := range paths {
+			bq.AddShould(bluge.NewTermQuery(path).SetField(documentFieldLocation))
+			bq.AddShould(bluge.NewPrefixQuery(path + "/").SetField(documentFieldLocation))
+		}
+		return bq, nil
+	}
+
+	return bluge.NewMatchNoneQuery(), nil
+}
+
+// getFolderPathsByUIDOrTitle returns the full path of the folders with the given uid or title,
+// which is the location of the documents in these folders.
+func getFolderPathsByUIDOrTitle(reader *bluge.Reader, folder string) ([]string, error) {
+	var paths []string
+
+	fullQuery := bluge.NewBooleanQuery()
+	fullQuery.AddMust(bluge.NewTermQuery(string(entityKindFolder)).SetField(documentFieldKind))
+	fullQuery.AddMust(bluge.NewBooleanQuery().
+		AddShould(bluge.NewTermQuery(folder).SetField(documentFieldUID)).
+		AddShould(bluge.NewTermQuery(formatForNameSortField(folder)).SetField(documentFieldName_sort)))
+	req := bluge.NewAllMatches(fullQuery)
+	documentMatchIterator, err := reader.Search(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	match, err := documentMatchIterator.Next()
+	for err == nil && match != nil {
+		var uid, location string
+		err = match.VisitStoredFields(func(field string, value []byte) bool {
+			switch field {
+			case documentFieldUID:
+				uid = string(value)
+			case documentFieldLocation:
+				location = string(value)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, joinLocation(location, uid))
+		// load the next document match
+		match, err = documentMatchIterator.Next()
+	}
+	return paths, err
+}
@@ -0,0 +1,67 @@
+package searchV2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  parsedQuery
+	}{
+		{
+			name:  "no filter",
+			query: "  my  dashboard ",
+			want:  parsedQuery{text: "  my  dashboard "},
+		},
+		{
+			name:  "filters and text",
+			query: "metric:http_requests_total ds:prom tag:prod -folder:scratch cpu usage",
+			want: parsedQuery{
+				text: "cpu usage",
+				filters: []queryFilter{
+					{key: queryFilterMetric, value: "http_requests_total"},
+					{key: queryFilterDS, value: "prom"},
+					{key: queryFilterTag, value: "prod"},
+					{key: queryFilterFolder, value: "scratch", negated: true},
+				},
+			},
+		},
+		{
+			name:  "quoted value",
+			query: `tag:"team a" -query:"rate(up"`,
+			want: parsedQuery{
+				filters: []queryFilter{
+					{key: queryFilterTag, value: "team a"},
+					{key: queryFilterQuery, value: "rate(up", negated: true},
+				},
+			},
+		},
+		{
+			name:  "unknown keys and empty values are text",
+			query: "host:server-1 tag: kind:panel",
+			want: parsedQuery{
+				text:    "host:server-1 tag:",
+				filters: []queryFilter{{key: queryFilterKind, value: "panel"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, parseQuery(tt.query))
+		})
+	}
+}
+
+func TestQueryTextAnalyzer(t *testing.T) {
+	stream := queryTextAnalyzer.Analyze([]byte(`sum by (job) (rate(HTTP_requests_total{job="api"}[5m])) / job:up:sum`))
+	terms := make([]string, 0, len(stream))
+	for _, token := range stream {
+		terms = append(terms, string(token.Term))
+	}
+	require.Equal(t, []string{"sum", "by", "job", "rate", "http_requests_total", "job", "api", "5m", "job:up:sum"}, terms)
+}
@@ -91,6 +91,7 @@ func ProvideService(cfg *setting.Cfg, sql *sqlstore.SQLStore, entityEventStore s
 		},
 		dashboardIndex: newSearchIndex(
 			newSQLDashboardLoader(sql, tracer, cfg.Search),
+			newSQLEntityLoader(sql, tracer),
 			entityEventStore,
 			extender.GetDocumentExtender(),
 			newFolderIDLookup(sql),
@@ -259,7 +260,9 @@ func (s *StandardSearchService) doDashboardQuery(ctx context.Context, signedInUs
 		return rsp
 	}
 
-	response := doSearchQuery(ctx, s.logger, index, filter, q, s.extender.GetQueryExtender(q), s.cfg.AppSubURL)
+	entityFilter := s.getEntityReadFilter(orgID, signedInUser)
+
+	response := doSearchQuery(ctx, s.logger, index, filter, entityFilter, q, s.extender.GetQueryExtender(q), s.cfg.AppSubURL)
 
 	if q.WithAllowedActions {
 		if err := s.addAllowedActionsField(ctx, orgID, signedInUser, response); err != nil {
@@ -15,7 +15,7 @@ type FacetField struct {
 }
 
 type DashboardQuery struct {
-	Query              string       `json:"query"`
+	Query              string       `json:"query"`              // name and key:value filters, see query_dsl.go
 	Location           string       `json:"location,omitempty"` // parent folder ID
 	Sort               string       `json:"sort,omitempty"`     // field ASC/DESC
 	Datasource         string       `json:"ds_uid,omitempty"`   // "datasource" collides with the JSON value at the same leel :()
@@ -186,6 +186,78 @@ func (api *ServiceAccountsAPI) CreateToken(c *models.ReqContext) response.Respon
 	return response.JSON(http.StatusOK, result)
 }
 
+// swagger:route POST /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/rotate service_accounts rotateToken
+//
+// # RotateToken replaces a service account token with a new one
+//
+// The new token gets the name of the rotated token. The rotated token is renamed and stays valid for `gracePeriodSeconds`,
+// so that clients can switch to the new token without downtime.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: createTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) RotateToken(c *models.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	cmd := serviceaccounts.RotateServiceAccountTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+
+	// Force affected service account to be the one referenced in the URL
+	cmd.OrgId = c.OrgID
+
+	// the lifetime of the new token defaults to the lifetime of the rotated token, the store checks the limit
+	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
+		cmd.MaxSecondsToLive = api.cfg.ApiKeyMaxSecondsToLive
+	}
+
+	newKeyInfo, err := apikeygenprefix.New(ServiceID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+
+	cmd.Key = newKeyInfo.HashedKey
+
+	if err := api.store.RotateServiceAccountToken(c.Req.Context(), saID, tokenID, &cmd); err != nil {
+		switch {
+		case errors.Is(err, database.ErrServiceAccountTokenNotFound):
+			return response.Error(http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, database.ErrInvalidTokenExpiration), errors.Is(err, database.ErrInvalidTokenGracePeriod),
+			errors.Is(err, database.ErrTokenRevoked), errors.Is(err, database.ErrMissingTokenExpiration),
+			errors.Is(err, database.ErrTokenExpirationTooLong):
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, database.ErrDuplicateToken):
+			return response.Error(http.StatusConflict, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to rotate service account token", err)
+	}
+
+	result := &dtos.NewApiKeyResult{
+		ID:   cmd.Result.Id,
+		Name: cmd.Result.Name,
+		Key:  newKeyInfo.ClientSecret,
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 // swagger:route DELETE /serviceaccounts/{serviceAccountId}/tokens/{tokenId} service_accounts deleteToken
 //
 // # DeleteToken deletes service account tokens
@@ -251,6 +323,16 @@ type CreateTokenParams struct {
 	Body serviceaccounts.AddServiceAccountTokenCommand
 }
 
+// swagger:parameters rotateToken
+type RotateTokenParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	Body serviceaccounts.RotateServiceAccountTokenCommand
+}
+
 // swagger:parameters deleteToken
 type DeleteTokenParams struct {
 	// in:path
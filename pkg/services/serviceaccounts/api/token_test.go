@@ -257,6 +257,116 @@ func TestServiceAccountsAPI_DeleteToken(t *testing.T) {
 	}
 }
 
+func TestServiceAccountsAPI_RotateToken(t *testing.T) {
+	store := sqlstore.InitTestDB(t)
+	apiKeyService := apikeyimpl.ProvideService(store, store.Cfg)
+	kvStore := kvstore.ProvideService(store)
+	svcMock := &tests.ServiceAccountMock{}
+	saStore := database.ProvideServiceAccountsStore(store, apiKeyService, kvStore, nil)
+	sa := tests.SetupUserServiceAccount(t, store, tests.TestUser{Login: "sa", IsServiceAccount: true})
+
+	type testRotateSAToken struct {
+		desc         string
+		keyName      string
+		body         map[string]interface{}
+		expectedCode int
+		acmock       *accesscontrolmock.Mock
+	}
+
+	testCases := []testRotateSAToken{
+		{
+			desc:    "should be ok to rotate serviceaccount token with scope id permissions",
+			keyName: "Test1",
+			body:    map[string]interface{}{"gracePeriodSeconds": 3600},
+			acmock: tests.SetupMockAccesscontrol(
+				t,
+				func(c context.Context, siu *user.SignedInUser, _ accesscontrol.Options) ([]accesscontrol.Permission, error) {
+					return []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}}, nil
+				},
+				false,
+			),
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:    "should be bad request to rotate serviceaccount token with a negative grace period",
+			keyName: "Test2",
+			body:    map[string]interface{}{"gracePeriodSeconds": -1},
+			acmock: tests.SetupMockAccesscontrol(
+				t,
+				func(c context.Context, siu *user.SignedInUser, _ accesscontrol.Options) ([]accesscontrol.Permission, error) {
+					return []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: serviceaccounts.ScopeAll}}, nil
+				},
+				false,
+			),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:    "should be forbidden to rotate serviceaccount token if wrong scoped",
+			keyName: "Test3",
+			body:    map[string]interface{}{},
+			acmock: tests.SetupMockAccesscontrol(
+				t,
+				func(c context.Context, siu *user.SignedInUser, _ accesscontrol.Options) ([]accesscontrol.Permission, error) {
+					return []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:10"}}, nil
+				},
+				false,
+			),
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	var requestResponse = func(server *web.Mux, httpMethod, requestpath string, requestBody io.Reader) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(httpMethod, requestpath, requestBody)
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			token := createTokenforSA(t, saStore, tc.keyName, sa.OrgID, sa.ID, 3600*24)
+
+			endpoint := fmt.Sprintf(serviceaccountIDTokensDetailPath+"/rotate", sa.ID, token.Id)
+			b, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			server, _ := setupTestServer(t, svcMock, routing.NewRouteRegister(), tc.acmock, store, saStore)
+			actual := requestResponse(server, http.MethodPost, endpoint, strings.NewReader(string(b)))
+
+			actualCode := actual.Code
+			actualBody := map[string]interface{}{}
+
+			_ = json.Unmarshal(actual.Body.Bytes(), &actualBody)
+			require.Equal(t, tc.expectedCode, actualCode, endpoint, actualBody)
+
+			query := apikey.GetByNameQuery{KeyName: tc.keyName, OrgId: sa.OrgID}
+			err = apiKeyService.GetApiKeyByName(context.Background(), &query)
+			require.NoError(t, err)
+
+			if actualCode != http.StatusOK {
+				require.Equal(t, token.Id, query.Result.Id)
+				return
+			}
+
+			assert.Equal(t, tc.keyName, actualBody["name"])
+			assert.NotEqual(t, token.Id, query.Result.Id)
+			require.NotNil(t, query.Result.Expires)
+
+			keyInfo, err := apikeygenprefix.Decode(actualBody["key"].(string))
+			require.NoError(t, err)
+			hash, err := keyInfo.Hash()
+			require.NoError(t, err)
+			require.Equal(t, query.Result.Key, hash)
+
+			rotated := apikey.GetByIDQuery{ApiKeyId: token.Id}
+			require.NoError(t, apiKeyService.GetApiKeyById(context.Background(), &rotated))
+			assert.True(t, strings.HasPrefix(rotated.Result.Name, tc.keyName+"-rotated-"))
+			assert.LessOrEqual(t, *rotated.Result.Expires, time.Now().Add(time.Hour).Unix())
+		})
+	}
+}
+
 type saStoreMockTokens struct {
 	serviceaccounts.Store
 	saAPIKeys []apikey.APIKey
@@ -8,6 +8,10 @@ var (
 	ErrServiceAccountAlreadyExists    = errors.New("service account already exists")
 	ErrServiceAccountTokenNotFound    = errors.New("service account token not found")
 	ErrInvalidTokenExpiration         = errors.New("invalid SecondsToLive value")
+	ErrInvalidTokenGracePeriod        = errors.New("invalid GracePeriodSeconds value")
+	ErrTokenRevoked                   = errors.New("service account token is revoked")
+	ErrMissingTokenExpiration         = errors.New("number of seconds before expiration should be set")
+	ErrTokenExpirationTooLong         = errors.New("number of seconds before expiration is greater than the global limit")
 	ErrDuplicateToken                 = errors.New("service account token with given name already exists in the organization")
 	ErrServiceAccountAndTokenMismatch = errors.New("API token does not belong to the given service account")
 )
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -29,6 +31,14 @@ func (s *ServiceAccountsStoreImpl) ListTokens(
 			sess = sess.Where("api_key.service_account_id=?", *query.ServiceAccountID)
 		}
 
+		if query.ExpiresAfter != nil {
+			sess = sess.Where("api_key.expires > ?", *query.ExpiresAfter)
+		}
+
+		if query.ExpiresBefore != nil {
+			sess = sess.Where("api_key.expires <= ?", *query.ExpiresBefore)
+		}
+
 		sess = sess.Join("inner", quotedUser, quotedUser+".id = api_key.service_account_id").
 			Asc("api_key.name")
 
@@ -68,6 +78,85 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 	})
 }
 
+// RotateServiceAccountToken replaces a token with a new one that has the same name.
+// The rotated token is renamed and stays valid for the requested grace period, or
+// until its original expiration if that comes first.
+func (s *ServiceAccountsStoreImpl) RotateServiceAccountToken(ctx context.Context, serviceAccountId, tokenId int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) error {
+	if cmd.SecondsToLive < 0 {
+		return ErrInvalidTokenExpiration
+	}
+	if cmd.GracePeriodSeconds < 0 {
+		return ErrInvalidTokenGracePeriod
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var old apikey.APIKey
+		exists, err := sess.Where("id=? AND org_id=? AND service_account_id=?", tokenId, cmd.OrgId, serviceAccountId).Get(&old)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrServiceAccountTokenNotFound
+		}
+		if old.IsRevoked != nil && *old.IsRevoked {
+			return ErrTokenRevoked
+		}
+
+		now := time.Now()
+		secondsToLive := cmd.SecondsToLive
+		if secondsToLive == 0 && old.Expires != nil {
+			secondsToLive = *old.Expires - old.Created.Unix()
+		}
+		if cmd.MaxSecondsToLive > 0 {
+			if secondsToLive == 0 {
+				return ErrMissingTokenExpiration
+			}
+			if secondsToLive > cmd.MaxSecondsToLive {
+				return ErrTokenExpirationTooLong
+			}
+		}
+
+		graceExpires := now.Add(time.Duration(cmd.GracePeriodSeconds) * time.Second).Unix()
+		if old.Expires == nil || *old.Expires > graceExpires {
+			old.Expires = &graceExpires
+		}
+		name := old.Name
+		old.Name = fmt.Sprintf("%s-rotated-%d", name, now.Unix())
+		old.Updated = now
+		if _, err := sess.ID(old.Id).Cols("name", "expires", "updated").Update(&old); err != nil {
+			return err
+		}
+
+		var expires *int64
+		if secondsToLive > 0 {
+			v := now.Add(time.Duration(secondsToLive) * time.Second).Unix()
+			expires = &v
+		}
+
+		isRevoked := false
+		newKey := apikey.APIKey{
+			OrgId:            cmd.OrgId,
+			Name:             name,
+			Role:             old.Role,
+			Key:              cmd.Key,
+			Created:          now,
+			Updated:          now,
+			Expires:          expires,
+			ServiceAccountId: &serviceAccountId,
+			IsRevoked:        &isRevoked,
+		}
+		if _, err := sess.Insert(&newKey); err != nil {
+			if s.sqlStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ErrDuplicateToken
+			}
+			return err
+		}
+
+		cmd.Result = &newKey
+		return nil
+	})
+}
+
 func (s *ServiceAccountsStoreImpl) DeleteServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error {
 	rawSQL := "DELETE FROM api_key WHERE id=? and org_id=? and service_account_id=?"
 
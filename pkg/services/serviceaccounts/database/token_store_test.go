@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/apikeygen"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -183,3 +184,123 @@ func TestStore_DeleteServiceAccountToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStore_RotateServiceAccountToken(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, userToCreate)
+
+	keyName := t.Name()
+	key, err := apikeygen.New(sa.OrgID, keyName)
+	require.NoError(t, err)
+
+	cmd := serviceaccounts.AddServiceAccountTokenCommand{
+		Name:          keyName,
+		OrgId:         sa.OrgID,
+		Key:           key.HashedKey,
+		SecondsToLive: 3600,
+		Result:        &apikey.APIKey{},
+	}
+
+	err = store.AddServiceAccountToken(context.Background(), sa.ID, &cmd)
+	require.NoError(t, err)
+	oldKey := cmd.Result
+
+	rotatedKey, err := apikeygen.New(sa.OrgID, keyName)
+	require.NoError(t, err)
+
+	// Rotate key from wrong service account
+	rotateCmd := serviceaccounts.RotateServiceAccountTokenCommand{OrgId: sa.OrgID, Key: rotatedKey.HashedKey}
+	err = store.RotateServiceAccountToken(context.Background(), sa.ID+2, oldKey.Id, &rotateCmd)
+	require.ErrorIs(t, err, ErrServiceAccountTokenNotFound)
+
+	// A grace period longer than the remaining lifetime keeps the original expiration
+	rotateCmd = serviceaccounts.RotateServiceAccountTokenCommand{OrgId: sa.OrgID, Key: rotatedKey.HashedKey, GracePeriodSeconds: 7200}
+	err = store.RotateServiceAccountToken(context.Background(), sa.ID, oldKey.Id, &rotateCmd)
+	require.NoError(t, err)
+	newKey := rotateCmd.Result
+	require.Equal(t, keyName, newKey.Name)
+	require.NotNil(t, newKey.Expires)
+	require.InDelta(t, 3600, *newKey.Expires-newKey.Created.Unix(), 1, "new key should inherit the lifetime of the rotated key")
+
+	keys, err := store.ListTokens(context.Background(), &serviceaccounts.GetSATokensQuery{
+		OrgID:            &sa.OrgID,
+		ServiceAccountID: &sa.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	for _, k := range keys {
+		if k.Id == oldKey.Id {
+			require.NotEqual(t, keyName, k.Name)
+			require.Equal(t, *oldKey.Expires, *k.Expires)
+		} else {
+			require.Equal(t, newKey.Id, k.Id)
+			require.Equal(t, rotatedKey.HashedKey, k.Key)
+		}
+	}
+}
+
+func TestStore_RotateServiceAccountToken_Checks(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, userToCreate)
+
+	addToken := func(name string, secondsToLive int64) *apikey.APIKey {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+		cmd := serviceaccounts.AddServiceAccountTokenCommand{Name: name, OrgId: sa.OrgID, Key: key.HashedKey, SecondsToLive: secondsToLive}
+		require.NoError(t, store.AddServiceAccountToken(context.Background(), sa.ID, &cmd))
+		return cmd.Result
+	}
+	rotate := func(token *apikey.APIKey, cmd serviceaccounts.RotateServiceAccountTokenCommand) error {
+		key, err := apikeygen.New(sa.OrgID, token.Name)
+		require.NoError(t, err)
+		cmd.OrgId = sa.OrgID
+		cmd.Key = key.HashedKey
+		return store.RotateServiceAccountToken(context.Background(), sa.ID, token.Id, &cmd)
+	}
+
+	t.Run("revoked tokens can not be rotated", func(t *testing.T) {
+		token := addToken("revoked", 3600)
+		require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, token.Id))
+		require.ErrorIs(t, rotate(token, serviceaccounts.RotateServiceAccountTokenCommand{}), ErrTokenRevoked)
+	})
+
+	t.Run("the lifetime of the new token is limited", func(t *testing.T) {
+		never := addToken("never", 0)
+		require.ErrorIs(t, rotate(never, serviceaccounts.RotateServiceAccountTokenCommand{MaxSecondsToLive: 3600}), ErrMissingTokenExpiration)
+		require.NoError(t, rotate(never, serviceaccounts.RotateServiceAccountTokenCommand{MaxSecondsToLive: 3600, SecondsToLive: 60}))
+
+		day := addToken("day", 3600*24)
+		require.ErrorIs(t, rotate(day, serviceaccounts.RotateServiceAccountTokenCommand{MaxSecondsToLive: 3600}), ErrTokenExpirationTooLong)
+	})
+}
+
+func TestStore_ListTokens_Expiration(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, userToCreate)
+
+	for name, secondsToLive := range map[string]int64{"soon": 60, "later": 3600 * 24, "never": 0} {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+		err = store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          name,
+			OrgId:         sa.OrgID,
+			Key:           key.HashedKey,
+			SecondsToLive: secondsToLive,
+		})
+		require.NoError(t, err)
+	}
+
+	after := time.Now().Unix()
+	before := time.Now().Add(time.Hour).Unix()
+	keys, err := store.ListTokens(context.Background(), &serviceaccounts.GetSATokensQuery{
+		ExpiresAfter:  &after,
+		ExpiresBefore: &before,
+	})
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, "soon", keys[0].Name)
+}
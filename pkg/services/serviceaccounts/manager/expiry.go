@@ -0,0 +1,223 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	tokenExpiryCheckInterval  = time.Hour
+	tokenExpiryLastCheckKey   = "tokenExpiryLastCheck"
+	tokenExpiryEmailTemplate  = "service_account_token_expiring"
+	tokenExpiryLockActionName = "service account token expiry notifications"
+)
+
+// ExpiringToken describes a service account token about to expire. It is used
+// both in the notification email and in the webhook payload.
+type ExpiringToken struct {
+	OrgID              int64  `json:"orgId"`
+	ServiceAccountID   int64  `json:"serviceAccountId"`
+	ServiceAccountName string `json:"serviceAccountName"`
+	TokenID            int64  `json:"tokenId"`
+	TokenName          string `json:"tokenName"`
+	ExpiresAt          int64  `json:"expiresAt"`
+	Expires            string `json:"-"`
+}
+
+type tokenExpiryWebhookPayload struct {
+	WarningDays int              `json:"warningDays"`
+	Tokens      []*ExpiringToken `json:"tokens"`
+}
+
+// checkExpiringTokens notifies about the tokens that entered the warning window
+// since the previous check. Only one instance in a HA setup runs it at a time.
+func (sa *ServiceAccountsService) checkExpiringTokens(ctx context.Context) {
+	if sa.cfg.ServiceAccounts.TokenExpirationWarningDays <= 0 {
+		return
+	}
+
+	err := sa.serverLock.LockAndExecute(ctx, tokenExpiryLockActionName, tokenExpiryCheckInterval/2, func(ctx context.Context) {
+		now := time.Now()
+		value, exists, err := sa.kvStore.Get(ctx, tokenExpiryLastCheckKey)
+		if err != nil {
+			sa.backgroundLog.Warn("Failed to get last token expiry check", "error", err)
+			return
+		}
+		var lastCheck *time.Time
+		if exists {
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				t := time.Unix(seconds, 0)
+				lastCheck = &t
+			}
+		}
+		warning := time.Duration(sa.cfg.ServiceAccounts.TokenExpirationWarningDays) * 24 * time.Hour
+		from := tokenExpiryCheckStart(now, lastCheck, warning)
+
+		if err := sa.notifyExpiringTokens(ctx, from, now); err != nil {
+			sa.backgroundLog.Warn("Failed to notify about expiring service account tokens", "error", err)
+			return
+		}
+
+		if err := sa.kvStore.Set(ctx, tokenExpiryLastCheckKey, strconv.FormatInt(now.Unix(), 10)); err != nil {
+			sa.backgroundLog.Warn("Failed to store last token expiry check", "error", err)
+		}
+	})
+	if err != nil {
+		sa.backgroundLog.Warn("Failed to run token expiry check", "error", err)
+	}
+}
+
+// tokenExpiryCheckStart returns the start of the window of a check. The first check, and a check long after the
+// previous one, covers all the tokens already in the warning window but not expired yet.
+func tokenExpiryCheckStart(now time.Time, lastCheck *time.Time, warning time.Duration) time.Time {
+	earliest := now.Add(-warning)
+	if lastCheck == nil || lastCheck.Before(earliest) {
+		return earliest
+	}
+	if lastCheck.After(now) {
+		return now
+	}
+	return *lastCheck
+}
+
+// notifyExpiringTokens sends notifications for the tokens that entered the
+// warning window between from and to, i.e. the tokens expiring in
+// (from + warning days, to + warning days].
+func (sa *ServiceAccountsService) notifyExpiringTokens(ctx context.Context, from, to time.Time) error {
+	warning := time.Duration(sa.cfg.ServiceAccounts.TokenExpirationWarningDays) * 24 * time.Hour
+	expiresAfter := from.Add(warning).Unix()
+	expiresBefore := to.Add(warning).Unix()
+
+	tokens, err := sa.store.ListTokens(ctx, &serviceaccounts.GetSATokensQuery{
+		ExpiresAfter:  &expiresAfter,
+		ExpiresBefore: &expiresBefore,
+	})
+	if err != nil {
+		return err
+	}
+
+	expiring := sa.toExpiringTokens(ctx, tokens)
+	if len(expiring) == 0 {
+		return nil
+	}
+
+	byOrg := make(map[int64][]*ExpiringToken)
+	for _, token := range expiring {
+		byOrg[token.OrgID] = append(byOrg[token.OrgID], token)
+	}
+
+	for orgID, orgTokens := range byOrg {
+		if err := sa.emailOrgAdmins(ctx, orgID, orgTokens); err != nil {
+			sa.backgroundLog.Warn("Failed to email org admins about expiring service account tokens", "orgId", orgID, "error", err)
+		}
+	}
+
+	if sa.cfg.ServiceAccounts.TokenExpirationWebhookURL != "" {
+		return sa.sendTokenExpiryWebhook(ctx, expiring)
+	}
+
+	return nil
+}
+
+func (sa *ServiceAccountsService) toExpiringTokens(ctx context.Context, tokens []apikey.APIKey) []*ExpiringToken {
+	names := make(map[int64]string)
+	result := make([]*ExpiringToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Expires == nil || token.ServiceAccountId == nil || (token.IsRevoked != nil && *token.IsRevoked) {
+			continue
+		}
+
+		saID := *token.ServiceAccountId
+		name, ok := names[saID]
+		if !ok {
+			name = strconv.FormatInt(saID, 10)
+			profile, err := sa.store.RetrieveServiceAccount(ctx, token.OrgId, saID)
+			if err != nil {
+				sa.backgroundLog.Warn("Failed to retrieve service account", "serviceAccountId", saID, "error", err)
+			} else if profile != nil {
+				name = profile.Name
+			}
+			names[saID] = name
+		}
+
+		result = append(result, &ExpiringToken{
+			OrgID:              token.OrgId,
+			ServiceAccountID:   saID,
+			ServiceAccountName: name,
+			TokenID:            token.Id,
+			TokenName:          token.Name,
+			ExpiresAt:          *token.Expires,
+			Expires:            time.Unix(*token.Expires, 0).UTC().Format(time.RFC1123),
+		})
+	}
+	return result
+}
+
+func (sa *ServiceAccountsService) emailOrgAdmins(ctx context.Context, orgID int64, tokens []*ExpiringToken) error {
+	o, err := sa.orgService.GetByID(ctx, &org.GetOrgByIdQuery{ID: orgID})
+	if err != nil {
+		return err
+	}
+
+	orgUsers, err := sa.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID:                    orgID,
+		DontEnforceAccessControl: true,
+		User:                     &user.SignedInUser{OrgID: orgID, OrgRole: org.RoleAdmin},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, orgUser := range orgUsers {
+		if orgUser.Role != string(org.RoleAdmin) || orgUser.IsDisabled || orgUser.Email == "" {
+			continue
+		}
+
+		name := orgUser.Name
+		if name == "" {
+			name = orgUser.Login
+		}
+
+		cmd := &models.SendEmailCommand{
+			To:       []string{orgUser.Email},
+			Template: tokenExpiryEmailTemplate,
+			Data: map[string]interface{}{
+				"Name":        name,
+				"OrgName":     o.Name,
+				"WarningDays": sa.cfg.ServiceAccounts.TokenExpirationWarningDays,
+				"Tokens":      tokens,
+			},
+		}
+		if err := sa.notifications.SendEmailCommandHandler(ctx, cmd); err != nil {
+			return fmt.Errorf("failed to send email to %s: %w", orgUser.Login, err)
+		}
+	}
+
+	return nil
+}
+
+func (sa *ServiceAccountsService) sendTokenExpiryWebhook(ctx context.Context, tokens []*ExpiringToken) error {
+	body, err := json.Marshal(tokenExpiryWebhookPayload{
+		WarningDays: sa.cfg.ServiceAccounts.TokenExpirationWarningDays,
+		Tokens:      tokens,
+	})
+	if err != nil {
+		return err
+	}
+
+	return sa.notifications.SendWebhookSync(ctx, &models.SendWebhookSync{
+		Url:         sa.cfg.ServiceAccounts.TokenExpirationWebhookURL,
+		Body:        string(body),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	})
+}
@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAccountsService_NotifyExpiringTokens(t *testing.T) {
+	saID := int64(2)
+	expires := time.Now().Add(3 * 24 * time.Hour).Unix()
+	revoked := true
+
+	setup := func(webhookURL string, tokens []apikey.APIKey) (*ServiceAccountsService, *notifications.NotificationServiceMock, *[]*models.SendEmailCommand) {
+		cfg := setting.NewCfg()
+		cfg.ServiceAccounts.TokenExpirationWarningDays = 7
+		cfg.ServiceAccounts.TokenExpirationWebhookURL = webhookURL
+
+		orgService := orgtest.NewOrgServiceFake()
+		orgService.ExpectedOrg = &org.Org{ID: 1, Name: "Main Org."}
+		orgService.ExpectedOrgUsers = []*org.OrgUserDTO{
+			{OrgID: 1, UserID: 1, Login: "admin", Email: "admin@localhost", Role: string(org.RoleAdmin)},
+			{OrgID: 1, UserID: 3, Login: "viewer", Email: "viewer@localhost", Role: string(org.RoleViewer)},
+			{OrgID: 1, UserID: 4, Login: "disabled", Email: "disabled@localhost", Role: string(org.RoleAdmin), IsDisabled: true},
+		}
+
+		emails := make([]*models.SendEmailCommand, 0)
+		notificationService := notifications.MockNotificationService()
+		notificationService.EmailHandler = func(ctx context.Context, cmd *models.SendEmailCommand) error {
+			emails = append(emails, cmd)
+			return nil
+		}
+
+		svc := &ServiceAccountsService{
+			cfg:           cfg,
+			store:         &tests.ServiceAccountsStoreMock{Tokens: tokens, Calls: tests.Calls{}},
+			orgService:    orgService,
+			notifications: notificationService,
+			log:           log.New("test"),
+			backgroundLog: log.New("test"),
+		}
+		return svc, notificationService, &emails
+	}
+
+	t.Run("should email org admins about expiring tokens", func(t *testing.T) {
+		svc, notificationService, emails := setup("", []apikey.APIKey{
+			{Id: 1, OrgId: 1, Name: "expiring", Expires: &expires, ServiceAccountId: &saID},
+			{Id: 2, OrgId: 1, Name: "revoked", Expires: &expires, ServiceAccountId: &saID, IsRevoked: &revoked},
+		})
+
+		err := svc.notifyExpiringTokens(context.Background(), time.Now().Add(-time.Hour), time.Now())
+		require.NoError(t, err)
+
+		require.Len(t, *emails, 1)
+		email := (*emails)[0]
+		assert.Equal(t, []string{"admin@localhost"}, email.To)
+		assert.Equal(t, tokenExpiryEmailTemplate, email.Template)
+		assert.Equal(t, "Main Org.", email.Data["OrgName"])
+
+		expiring, ok := email.Data["Tokens"].([]*ExpiringToken)
+		require.True(t, ok)
+		require.Len(t, expiring, 1)
+		assert.Equal(t, "expiring", expiring[0].TokenName)
+		assert.Equal(t, expires, expiring[0].ExpiresAt)
+
+		assert.Empty(t, notificationService.Webhook.Url)
+	})
+
+	t.Run("should call the webhook when configured", func(t *testing.T) {
+		svc, notificationService, _ := setup("http://localhost/hook", []apikey.APIKey{
+			{Id: 1, OrgId: 1, Name: "expiring", Expires: &expires, ServiceAccountId: &saID},
+		})
+
+		err := svc.notifyExpiringTokens(context.Background(), time.Now().Add(-time.Hour), time.Now())
+		require.NoError(t, err)
+
+		assert.Equal(t, "http://localhost/hook", notificationService.Webhook.Url)
+		var payload tokenExpiryWebhookPayload
+		require.NoError(t, json.Unmarshal([]byte(notificationService.Webhook.Body), &payload))
+		assert.Equal(t, 7, payload.WarningDays)
+		require.Len(t, payload.Tokens, 1)
+		assert.Equal(t, int64(1), payload.Tokens[0].TokenID)
+	})
+
+	t.Run("should not notify when no token is expiring", func(t *testing.T) {
+		svc, notificationService, emails := setup("http://localhost/hook", nil)
+
+		err := svc.notifyExpiringTokens(context.Background(), time.Now().Add(-time.Hour), time.Now())
+		require.NoError(t, err)
+
+		assert.Empty(t, *emails)
+		assert.Empty(t, notificationService.Webhook.Url)
+	})
+}
+
+func TestTokenExpiryCheckStart(t *testing.T) {
+	now := time.Unix(1_000_000_000, 0)
+	warning := 7 * 24 * time.Hour
+
+	hourAgo := now.Add(-time.Hour)
+	monthAgo := now.Add(-30 * 24 * time.Hour)
+	later := now.Add(time.Hour)
+
+	assert.Equal(t, now.Add(-warning), tokenExpiryCheckStart(now, nil, warning), "the first check covers the tokens already in the warning window")
+	assert.Equal(t, hourAgo, tokenExpiryCheckStart(now, &hourAgo, warning))
+	assert.Equal(t, now.Add(-warning), tokenExpiryCheckStart(now, &monthAgo, warning), "expired tokens are not notified")
+	assert.Equal(t, now, tokenExpiryCheckStart(now, &later, warning))
+}
@@ -6,9 +6,13 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/api"
 	"github.com/grafana/grafana/pkg/setting"
@@ -19,7 +23,12 @@ const (
 )
 
 type ServiceAccountsService struct {
+	cfg           *setting.Cfg
 	store         serviceaccounts.Store
+	orgService    org.Service
+	notifications notifications.Service
+	serverLock    *serverlock.ServerLockService
+	kvStore       *kvstore.NamespacedKVStore
 	log           log.Logger
 	backgroundLog log.Logger
 }
@@ -32,9 +41,18 @@ func ProvideServiceAccountsService(
 	serviceAccountsStore serviceaccounts.Store,
 	permissionService accesscontrol.ServiceAccountPermissionsService,
 	accesscontrolService accesscontrol.Service,
+	orgService org.Service,
+	notificationService notifications.Service,
+	serverLockService *serverlock.ServerLockService,
+	kvStore kvstore.KVStore,
 ) (*ServiceAccountsService, error) {
 	s := &ServiceAccountsService{
+		cfg:           cfg,
 		store:         serviceAccountsStore,
+		orgService:    orgService,
+		notifications: notificationService,
+		serverLock:    serverLockService,
+		kvStore:       kvstore.WithNamespace(kvStore, 0, "serviceaccounts"),
 		log:           log.New("serviceaccounts"),
 		backgroundLog: log.New("serviceaccounts.background"),
 	}
@@ -61,6 +79,10 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 	updateStatsTicker := time.NewTicker(metricsCollectionInterval)
 	defer updateStatsTicker.Stop()
 
+	sa.checkExpiringTokens(ctx)
+	tokenExpiryTicker := time.NewTicker(tokenExpiryCheckInterval)
+	defer tokenExpiryTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -77,6 +99,10 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if _, err := sa.getUsageMetrics(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to get usage metrics", "error", err.Error())
 			}
+		case <-tokenExpiryTicker.C:
+			sa.backgroundLog.Debug("checking for expiring tokens")
+
+			sa.checkExpiringTokens(ctx)
 		}
 	}
 }
@@ -67,6 +67,8 @@ type ServiceAccountDTO struct {
 type GetSATokensQuery struct {
 	OrgID            *int64 // optional filtering by org ID
 	ServiceAccountID *int64 // optional filtering by service account ID
+	ExpiresAfter     *int64 // optional filtering by expiration, exclusive unix timestamp
+	ExpiresBefore    *int64 // optional filtering by expiration, inclusive unix timestamp
 }
 
 type AddServiceAccountTokenCommand struct {
@@ -77,6 +79,21 @@ type AddServiceAccountTokenCommand struct {
 	Result        *apikey.APIKey `json:"-"`
 }
 
+// swagger:model
+type RotateServiceAccountTokenCommand struct {
+	// Lifetime of the new token. Defaults to the lifetime of the rotated token.
+	// example: 2592000
+	SecondsToLive int64 `json:"secondsToLive"`
+	// Time the rotated token stays valid after the rotation. Defaults to 0, the rotated token expires immediately.
+	// example: 86400
+	GracePeriodSeconds int64 `json:"gracePeriodSeconds"`
+	// MaxSecondsToLive limits the lifetime of the new token, 0 for no limit.
+	MaxSecondsToLive int64          `json:"-"`
+	OrgId            int64          `json:"-"`
+	Key              string         `json:"-"`
+	Result           *apikey.APIKey `json:"-"`
+}
+
 // swagger: model
 type SearchServiceAccountsResult struct {
 	// It can be used for pagination of the user list
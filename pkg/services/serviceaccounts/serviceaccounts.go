@@ -32,5 +32,6 @@ type Store interface {
 	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
 	RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error
 	AddServiceAccountToken(ctx context.Context, serviceAccountID int64, cmd *AddServiceAccountTokenCommand) error
+	RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *RotateServiceAccountTokenCommand) error
 	GetUsageMetrics(ctx context.Context) (*Stats, error)
 }
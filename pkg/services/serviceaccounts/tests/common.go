@@ -132,14 +132,16 @@ type Calls struct {
 	DeleteServiceAccountToken       []interface{}
 	UpdateServiceAccount            []interface{}
 	AddServiceAccountToken          []interface{}
+	RotateServiceAccountToken       []interface{}
 	SearchOrgServiceAccounts        []interface{}
 	RetrieveServiceAccountIdByName  []interface{}
 }
 
 type ServiceAccountsStoreMock struct {
 	serviceaccounts.Store
-	Stats *serviceaccounts.Stats
-	Calls Calls
+	Stats  *serviceaccounts.Stats
+	Tokens []apikey.APIKey
+	Calls  Calls
 }
 
 func (s *ServiceAccountsStoreMock) RetrieveServiceAccountIdByName(ctx context.Context, orgID int64, name string) (int64, error) {
@@ -186,7 +188,7 @@ func (s *ServiceAccountsStoreMock) RevertApiKey(ctx context.Context, saId int64,
 
 func (s *ServiceAccountsStoreMock) ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error) {
 	s.Calls.ListTokens = append(s.Calls.ListTokens, []interface{}{ctx, query.OrgID, query.ServiceAccountID})
-	return nil, nil
+	return s.Tokens, nil
 }
 
 func (s *ServiceAccountsStoreMock) RetrieveServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*serviceaccounts.ServiceAccountProfileDTO, error) {
@@ -224,6 +226,11 @@ func (s *ServiceAccountsStoreMock) AddServiceAccountToken(ctx context.Context, s
 	return nil
 }
 
+func (s *ServiceAccountsStoreMock) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) error {
+	s.Calls.RotateServiceAccountToken = append(s.Calls.RotateServiceAccountToken, []interface{}{ctx, serviceAccountID, tokenID, cmd})
+	return nil
+}
+
 func (s *ServiceAccountsStoreMock) GetUsageMetrics(ctx context.Context) (*serviceaccounts.Stats, error) {
 	if s.Stats == nil {
 		return &serviceaccounts.Stats{}, nil
@@ -43,4 +43,52 @@ func addCommentMigrations(mg *Migrator) {
 	mg.AddMigration("create comment table", NewAddTableMigration(commentTable))
 	mg.AddMigration("add index comment.group_id", NewAddIndexMigration(commentTable, commentTable.Indices[0]))
 	mg.AddMigration("add index comment.created", NewAddIndexMigration(commentTable, commentTable.Indices[1]))
+
+	// threads and anchors
+	mg.AddMigration("add column comment.parent_id", NewAddColumnMigration(commentTable, &Column{
+		Name: "parent_id", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column comment.panel_id", NewAddColumnMigration(commentTable, &Column{
+		Name: "panel_id", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column comment.time_from", NewAddColumnMigration(commentTable, &Column{
+		Name: "time_from", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column comment.time_to", NewAddColumnMigration(commentTable, &Column{
+		Name: "time_to", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add index comment.parent_id", NewAddIndexMigration(commentTable, &Index{
+		Cols: []string{"parent_id"}, Type: IndexType,
+	}))
+
+	commentHistoryTable := Table{
+		Name: "comment_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "comment_id", Type: DB_BigInt, Nullable: false},
+			{Name: "content", Type: DB_MediumText, Nullable: false},
+			{Name: "created", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"comment_id"}, Type: IndexType},
+		},
+	}
+	mg.AddMigration("create comment_history table", NewAddTableMigration(commentHistoryTable))
+	mg.AddMigration("add index comment_history.comment_id", NewAddIndexMigration(commentHistoryTable, commentHistoryTable.Indices[0]))
+
+	commentReactionTable := Table{
+		Name: "comment_reaction",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "comment_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "reaction", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "created", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"comment_id", "user_id", "reaction"}, Type: UniqueIndex},
+		},
+	}
+	mg.AddMigration("create comment_reaction table", NewAddTableMigration(commentReactionTable))
+	mg.AddMigration("add unique index comment_reaction.comment_id_user_id_reaction", NewAddIndexMigration(commentReactionTable, commentReactionTable.Indices[0]))
 }
@@ -29,4 +29,58 @@ func addQueryHistoryMigrations(mg *Migrator) {
 	mg.AddMigration("alter table query_history alter column created_by type to bigint", NewRawSQLMigration("").
 		Mysql("ALTER TABLE query_history MODIFY created_by BIGINT;").
 		Postgres("ALTER TABLE query_history ALTER COLUMN created_by TYPE BIGINT;"))
+
+	queryHistoryTagV1 := Table{
+		Name: "query_history_tag",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "query_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "term", Type: DB_NVarchar, Length: 50, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"query_uid", "term"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "term"}},
+		},
+	}
+
+	mg.AddMigration("create query_history_tag table v1", NewAddTableMigration(queryHistoryTagV1))
+	addTableIndicesMigrations(mg, "v1", queryHistoryTagV1)
+
+	// a share without team is shared with the whole org
+	queryHistoryShareV1 := Table{
+		Name: "query_history_share",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "query_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false},
+			{Name: "shared_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"query_uid", "team_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "team_id"}},
+		},
+	}
+
+	mg.AddMigration("create query_history_share table v1", NewAddTableMigration(queryHistoryShareV1))
+	addTableIndicesMigrations(mg, "v1", queryHistoryShareV1)
+
+	queryHistoryRetentionV1 := Table{
+		Name: "query_history_retention",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "retention_days", Type: DB_Int, Nullable: false},
+			{Name: "row_limit", Type: DB_Int, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create query_history_retention table v1", NewAddTableMigration(queryHistoryRetentionV1))
+	addTableIndicesMigrations(mg, "v1", queryHistoryRetentionV1)
 }
@@ -44,4 +44,15 @@ func addUserAuthTokenMigrations(mg *Migrator) {
 			},
 		),
 	)
+	mg.AddMigration("Add max_inactive_lifetime to the user auth token", NewAddColumnMigration(userAuthTokenV1, &Column{
+		Name: "max_inactive_lifetime", Type: DB_BigInt, Nullable: true, Default: "0",
+	}))
+
+	mg.AddMigration("Add max_lifetime to the user auth token", NewAddColumnMigration(userAuthTokenV1, &Column{
+		Name: "max_lifetime", Type: DB_BigInt, Nullable: true, Default: "0",
+	}))
+
+	mg.AddMigration("Add revoke_reason to the user auth token", NewAddColumnMigration(userAuthTokenV1, &Column{
+		Name: "revoke_reason", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
 }
@@ -22,6 +22,10 @@ type GlobalStorageConfig struct {
 
 	// Paths under 'root' (NOTE: this is applied to all orgs)
 	Roots []RootStorageConfig `json:"roots"`
+
+	// Keep the uploaded resources in an object store rather than SQL.  Each org is saved
+	// under its own folder
+	Resources *RootStorageConfig `json:"resources,omitempty"`
 }
 
 func LoadStorageConfig(cfg *setting.Cfg, features featuremgmt.FeatureToggles) (*GlobalStorageConfig, error) {
@@ -106,12 +110,17 @@ type RootStorageConfig struct {
 	Description      string `json:"description"`
 	Disabled         bool   `json:"disabled,omitempty"`
 
+	// Object stores can redirect reads to a signed URL valid for this duration, ie "15m"
+	SignedURLExpiry string `json:"signedUrlExpiry,omitempty"`
+
 	// Depending on type, these will be configured
-	Disk *StorageLocalDiskConfig `json:"disk,omitempty"`
-	Git  *StorageGitConfig       `json:"git,omitempty"`
-	SQL  *StorageSQLConfig       `json:"sql,omitempty"`
-	S3   *StorageS3Config        `json:"s3,omitempty"`
-	GCS  *StorageGCSConfig       `json:"gcs,omitempty"`
+	Disk  *StorageLocalDiskConfig `json:"disk,omitempty"`
+	Git   *StorageGitConfig       `json:"git,omitempty"`
+	SQL   *StorageSQLConfig       `json:"sql,omitempty"`
+	S3    *StorageS3Config        `json:"s3,omitempty"`
+	GCS   *StorageGCSConfig       `json:"gcs,omitempty"`
+	Azure *StorageAzureConfig     `json:"azure,omitempty"`
+	Blob  *StorageBlobConfig      `json:"blob,omitempty"`
 }
 
 type StorageLocalDiskConfig struct {
@@ -145,6 +154,10 @@ type StorageS3Config struct {
 	AccessKey string `json:"accessKey"`
 	SecretKey string `json:"secretKey"`
 	Region    string `json:"region"`
+
+	// S3 compatible stores, ie MinIO
+	Endpoint       string `json:"endpoint,omitempty"`
+	ForcePathStyle bool   `json:"forcePathStyle,omitempty"`
 }
 
 type StorageGCSConfig struct {
@@ -154,12 +167,29 @@ type StorageGCSConfig struct {
 	CredentialsFile string `json:"credentialsFile"`
 }
 
+type StorageAzureConfig struct {
+	Container string `json:"container"`
+	Folder    string `json:"folder"`
+
+	// SECURE!!!
+	AccountName string `json:"accountName"`
+	AccountKey  string `json:"accountKey"`
+}
+
+type StorageBlobConfig struct {
+	// Any Go CDK bucket URL, ie mem://, file:///path or s3://bucket?endpoint=localhost:9000&s3ForcePathStyle=true
+	URL    string `json:"url"`
+	Folder string `json:"folder"`
+}
+
 func newStorage(cfg RootStorageConfig, localWorkCache string) (storageRuntime, error) {
 	switch cfg.Type {
 	case rootStorageTypeDisk:
 		return newDiskStorage(RootStorageMeta{}, cfg), nil
 	case rootStorageTypeGit:
 		return newGitStorage(RootStorageMeta{}, cfg, localWorkCache), nil
+	case rootStorageTypeS3, rootStorageTypeGCS, rootStorageTypeAzure, rootStorageTypeBlob:
+		return newBlobStorage(RootStorageMeta{}, cfg, ""), nil
 	}
 
 	return nil, fmt.Errorf("unsupported store: " + cfg.Type)
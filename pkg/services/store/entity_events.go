@@ -23,10 +23,15 @@ const (
 type EntityType string
 
 const (
-	EntityTypeDashboard EntityType = "dashboard"
-	EntityTypeFolder    EntityType = "folder"
-	EntityTypeImage     EntityType = "image"
-	EntityTypeJSON      EntityType = "json"
+	EntityTypeDashboard    EntityType = "dashboard"
+	EntityTypeFolder       EntityType = "folder"
+	EntityTypeImage        EntityType = "image"
+	EntityTypeJSON         EntityType = "json"
+	EntityTypeAlertRule    EntityType = "alertrule"
+	EntityTypeLibraryPanel EntityType = "librarypanel"
+	EntityTypeDatasource   EntityType = "datasource"
+	EntityTypePlaylist     EntityType = "playlist"
+	EntityTypeCorrelation  EntityType = "correlation"
 )
 
 // CreateDatabaseEntityId creates entityId for entities stored in the existing SQL tables
@@ -42,6 +47,21 @@ func CreateDatabaseEntityId(internalId interface{}, orgId int64, entityType Enti
 	return fmt.Sprintf("database/%d/%s/%s", orgId, entityType, internalIdAsString)
 }
 
+// CreateDatabaseEntityEvent creates the event of a change of an entity stored in the existing SQL tables
+func CreateDatabaseEntityEvent(internalId interface{}, orgId int64, entityType EntityType, eventType EntityEventType) *EntityEvent {
+	return &EntityEvent{
+		EventType: eventType,
+		EntityId:  CreateDatabaseEntityId(internalId, orgId, entityType),
+		Created:   time.Now().Unix(),
+	}
+}
+
+// EntityEventsEnabled returns whether the changes of the entities are saved as entity events. The events
+// are only consumed by the search index, so they are not saved when the search is disabled.
+func EntityEventsEnabled(cfg *setting.Cfg) bool {
+	return cfg != nil && cfg.IsFeatureToggleEnabled != nil && cfg.IsFeatureToggleEnabled(featuremgmt.FlagPanelTitleSearch)
+}
+
 type EntityEvent struct {
 	Id        int64
 	EventType EntityEventType
@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
@@ -161,6 +162,19 @@ func getMultipartFormValue(req *http.Request, key string) string {
 func (s *standardStorageService) read(c *models.ReqContext) response.Response {
 	// full path is api/storage/read/upload/example.jpg, but we only want the part after read
 	scope, path := getPathAndScope(c)
+
+	// object stores can serve the file directly
+	signedURL, err := s.signedURL(c.Req.Context(), c.SignedInUser, scope+"/"+path)
+	if err != nil {
+		if errors.Is(err, ErrAccessDenied) {
+			return response.Error(403, "access denied", err)
+		}
+		return response.Error(400, "cannot call read", err)
+	}
+	if signedURL != "" {
+		return response.Redirect(signedURL)
+	}
+
 	file, err := s.Read(c.Req.Context(), c.SignedInUser, scope+"/"+path)
 	if err != nil {
 		return response.Error(400, "cannot call read", err)
@@ -260,7 +274,15 @@ func (s *standardStorageService) doCreateFolder(c *models.ReqContext) response.R
 func (s *standardStorageService) list(c *models.ReqContext) response.Response {
 	params := web.Params(c.Req)
 	path := params["*"]
-	frame, err := s.List(c.Req.Context(), c.SignedInUser, path)
+
+	var paging *filestorage.Paging
+	first := c.QueryInt("first")
+	after := c.Query("after")
+	if first > 0 || after != "" {
+		paging = &filestorage.Paging{First: first, After: after}
+	}
+
+	frame, err := s.List(c.Req.Context(), c.SignedInUser, path, paging)
 	if err != nil {
 		return response.Error(400, "error reading path", err)
 	}
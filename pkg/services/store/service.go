@@ -64,8 +64,8 @@ type StorageService interface {
 	// Register the HTTP
 	RegisterHTTPRoutes(routing.RouteRegister)
 
-	// List folder contents
-	List(ctx context.Context, user *user.SignedInUser, path string) (*StorageListFrame, error)
+	// List folder contents, the paging is optional
+	List(ctx context.Context, user *user.SignedInUser, path string, paging *filestorage.Paging) (*StorageListFrame, error)
 
 	// Read raw file contents out of the store
 	Read(ctx context.Context, user *user.SignedInUser, path string) (*filestorage.File, error)
@@ -171,10 +171,7 @@ func ProvideService(
 			}, RootContent, "Content", "Content root", &StorageSQLConfig{}, sql, orgId, false))
 
 		// Custom upload files
-		storages = append(storages,
-			newSQLStorage(RootStorageMeta{
-				Builtin: true,
-			}, RootResources, "Resources", "Upload custom resource files", &StorageSQLConfig{}, sql, orgId, false))
+		storages = append(storages, newResourcesStorage(settings.Resources, sql, orgId))
 
 		// System settings
 		storages = append(storages,
@@ -262,6 +259,29 @@ func ProvideService(
 	return s
 }
 
+// newResourcesStorage keeps the resources in SQL unless an object store is configured
+func newResourcesStorage(cfg *RootStorageConfig, sql db.DB, orgId int64) storageRuntime {
+	if cfg != nil {
+		switch cfg.Type {
+		case rootStorageTypeS3, rootStorageTypeGCS, rootStorageTypeAzure, rootStorageTypeBlob:
+			scfg := *cfg
+			scfg.Prefix = RootResources
+			scfg.Name = "Resources"
+			scfg.Description = "Upload custom resource files"
+			scfg.UnderContentRoot = false
+			return newBlobStorage(RootStorageMeta{
+				Builtin: true,
+			}, scfg, fmt.Sprintf("%d", orgId))
+		default:
+			grafanaStorageLogger.Warn("Invalid resources configuration, using SQL", "type", cfg.Type)
+		}
+	}
+
+	return newSQLStorage(RootStorageMeta{
+		Builtin: true,
+	}, RootResources, "Resources", "Upload custom resource files", &StorageSQLConfig{}, sql, orgId, false)
+}
+
 func createSystemBrandingPathFilter() filestorage.PathFilter {
 	return filestorage.NewPathFilter(
 		[]string{filestorage.Delimiter + brandingStorage + filestorage.Delimiter}, // access to all folders and files inside `/branding/`
@@ -316,9 +336,9 @@ func getOrgId(user *user.SignedInUser) int64 {
 	return user.OrgID
 }
 
-func (s *standardStorageService) List(ctx context.Context, user *user.SignedInUser, path string) (*StorageListFrame, error) {
+func (s *standardStorageService) List(ctx context.Context, user *user.SignedInUser, path string, paging *filestorage.Paging) (*StorageListFrame, error) {
 	guardian := s.authService.newGuardian(ctx, user, getFirstSegment(path))
-	return s.tree.ListFolder(ctx, getOrgId(user), path, guardian.getPathFilter(ActionFilesRead))
+	return s.tree.ListFolder(ctx, getOrgId(user), path, paging, guardian.getPathFilter(ActionFilesRead))
 }
 
 func (s *standardStorageService) Read(ctx context.Context, user *user.SignedInUser, path string) (*filestorage.File, error) {
@@ -329,6 +349,22 @@ func (s *standardStorageService) Read(ctx context.Context, user *user.SignedInUs
 	return s.tree.GetFile(ctx, getOrgId(user), path)
 }
 
+// signedURL returns a link to read the file directly from its object store, it is empty when the
+// root does not hand out links
+func (s *standardStorageService) signedURL(ctx context.Context, user *user.SignedInUser, path string) (string, error) {
+	guardian := s.authService.newGuardian(ctx, user, getFirstSegment(path))
+	if !guardian.canView(path) {
+		return "", ErrAccessDenied
+	}
+
+	root, storagePath := s.tree.getRoot(getOrgId(user), path)
+	signer, ok := root.(storageSigner)
+	if !ok {
+		return "", nil
+	}
+	return signer.signedURL(ctx, storagePath)
+}
+
 type UploadRequest struct {
 	Contents           []byte
 	Path               string
@@ -74,7 +74,7 @@ func TestListFiles(t *testing.T) {
 	store := newStandardStorageService(sqlstore.InitTestDB(t), roots, func(orgId int64) []storageRuntime {
 		return make([]storageRuntime, 0)
 	}, allowAllAuthService, cfg)
-	frame, err := store.List(context.Background(), dummyUser, "public/testdata")
+	frame, err := store.List(context.Background(), dummyUser, "public/testdata", nil)
 	require.NoError(t, err)
 
 	experimental.CheckGoldenJSONFrame(t, "testdata", "public_testdata.golden", frame.Frame, true)
@@ -94,7 +94,7 @@ func TestListFilesWithoutPermissions(t *testing.T) {
 	store := newStandardStorageService(sqlstore.InitTestDB(t), roots, func(orgId int64) []storageRuntime {
 		return make([]storageRuntime, 0)
 	}, denyAllAuthService, cfg)
-	frame, err := store.List(context.Background(), dummyUser, "public/testdata")
+	frame, err := store.List(context.Background(), dummyUser, "public/testdata", nil)
 	require.NoError(t, err)
 	rowLen, err := frame.RowLen()
 	require.NoError(t, err)
@@ -370,7 +370,7 @@ func TestContentRootWithNestedStorage(t *testing.T) {
 				Files: []*filestorage.File{},
 			}, nil)
 
-			_, err := store.List(context.Background(), test.user, RootContent+"/"+test.nestedRoot)
+			_, err := store.List(context.Background(), test.user, RootContent+"/"+test.nestedRoot, nil)
 			require.NoError(t, err)
 		})
 
@@ -386,7 +386,7 @@ func TestContentRootWithNestedStorage(t *testing.T) {
 				Files: []*filestorage.File{},
 			}, nil)
 
-			_, err := store.List(context.Background(), test.user, strings.Join([]string{RootContent, test.nestedRoot, "folder1", "folder2"}, "/"))
+			_, err := store.List(context.Background(), test.user, strings.Join([]string{RootContent, test.nestedRoot, "folder1", "folder2"}, "/"), nil)
 			require.NoError(t, err)
 		})
 
@@ -433,16 +433,16 @@ func TestContentRootWithNestedStorage(t *testing.T) {
 				Files: []*filestorage.File{},
 			}, nil)
 
-			_, err := store.List(context.Background(), test.user, strings.Join([]string{RootContent, "not-nested-content"}, "/"))
+			_, err := store.List(context.Background(), test.user, strings.Join([]string{RootContent, "not-nested-content"}, "/"), nil)
 			require.NoError(t, err)
 
-			_, err = store.List(context.Background(), test.user, strings.Join([]string{RootContent, "a", "b", "c"}, "/"))
+			_, err = store.List(context.Background(), test.user, strings.Join([]string{RootContent, "a", "b", "c"}, "/"), nil)
 			require.NoError(t, err)
 
-			_, err = store.List(context.Background(), test.user, strings.Join([]string{RootContent, test.nestedRoot + "a"}, "/"))
+			_, err = store.List(context.Background(), test.user, strings.Join([]string{RootContent, test.nestedRoot + "a"}, "/"), nil)
 			require.NoError(t, err)
 
-			_, err = store.List(context.Background(), test.user, strings.Join([]string{RootContent, test.nestedRoot + "a", "b"}, "/"))
+			_, err = store.List(context.Background(), test.user, strings.Join([]string{RootContent, test.nestedRoot + "a", "b"}, "/"), nil)
 			require.NoError(t, err)
 		})
 
@@ -535,7 +535,7 @@ func TestShadowingExistingFolderByNestedContentRoot(t *testing.T) {
 		AllowUnsanitizedSvgUpload: true,
 	}
 
-	resp, err := store.List(ctx, globalUser, "content/nested")
+	resp, err := store.List(ctx, globalUser, "content/nested", nil)
 	require.NoError(t, err)
 	require.NotNil(t, resp)
 
@@ -543,7 +543,7 @@ func TestShadowingExistingFolderByNestedContentRoot(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 0, rowLen) // nested storage is empty
 
-	resp, err = store.List(ctx, globalUser, "content")
+	resp, err = store.List(ctx, globalUser, "content", nil)
 	require.NoError(t, err)
 	require.NotNil(t, resp)
 
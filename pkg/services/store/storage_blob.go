@@ -0,0 +1,308 @@
+package store
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/azureblob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcerrors"
+	"gocloud.dev/gcp"
+	"golang.org/x/oauth2/google"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+)
+
+const (
+	rootStorageTypeS3    = "s3"
+	rootStorageTypeGCS   = "gcs"
+	rootStorageTypeAzure = "azure"
+	rootStorageTypeBlob  = "blob" // any Go CDK bucket URL
+)
+
+var _ storageRuntime = &rootStorageBlob{}
+
+// storageSigner is implemented by the roots that can hand out direct links to their files
+type storageSigner interface {
+	signedURL(ctx context.Context, path string) (string, error)
+}
+
+type rootStorageBlob struct {
+	meta   RootStorageMeta
+	bucket *blob.Bucket
+	store  filestorage.FileStorage
+	expiry time.Duration // signed URLs are disabled when zero
+}
+
+// newBlobStorage opens the bucket of an object store root.  The org folder is added to the
+// configured folder, it keeps the orgs apart when a root is created for each org
+func newBlobStorage(meta RootStorageMeta, scfg RootStorageConfig, orgFolder string) *rootStorageBlob {
+	meta.Config = scfg
+	if scfg.Prefix == "" {
+		meta.Notice = append(meta.Notice, data.Notice{
+			Severity: data.NoticeSeverityError,
+			Text:     "Missing prefix",
+		})
+	}
+
+	folder, missing := blobFolder(scfg)
+	if missing != "" {
+		meta.Notice = append(meta.Notice, data.Notice{
+			Severity: data.NoticeSeverityError,
+			Text:     missing,
+		})
+	}
+
+	s := &rootStorageBlob{}
+	if scfg.SignedURLExpiry != "" {
+		expiry, err := time.ParseDuration(scfg.SignedURLExpiry)
+		if err != nil || expiry <= 0 {
+			meta.Notice = append(meta.Notice, data.Notice{
+				Severity: data.NoticeSeverityError,
+				Text:     "Invalid signed URL expiry: " + scfg.SignedURLExpiry,
+			})
+		} else {
+			s.expiry = expiry
+		}
+	}
+
+	if meta.Notice == nil {
+		bucket, err := openBucket(context.Background(), scfg)
+		if err != nil {
+			grafanaStorageLogger.Warn("error loading storage", "prefix", scfg.Prefix, "type", scfg.Type, "err", err)
+			meta.Notice = append(meta.Notice, data.Notice{
+				Severity: data.NoticeSeverityError,
+				Text:     "Failed to initialize storage",
+			})
+		} else {
+			keyPrefix := strings.Trim(path.Join(folder, orgFolder), filestorage.Delimiter)
+			if keyPrefix != "" {
+				bucket = blob.PrefixedBucket(bucket, keyPrefix+filestorage.Delimiter)
+			}
+			s.bucket = bucket
+			s.store = filestorage.NewCdkBlobStorage(grafanaStorageLogger, bucket, "", nil)
+
+			meta.Ready = true // exists!
+		}
+	}
+
+	s.meta = meta
+	return s
+}
+
+// blobFolder returns the folder within the bucket, or the notice for a missing configuration
+func blobFolder(scfg RootStorageConfig) (string, string) {
+	switch scfg.Type {
+	case rootStorageTypeS3:
+		if scfg.S3 == nil || scfg.S3.Bucket == "" {
+			return "", "Missing bucket configuration"
+		}
+		return scfg.S3.Folder, ""
+	case rootStorageTypeGCS:
+		if scfg.GCS == nil || scfg.GCS.Bucket == "" {
+			return "", "Missing bucket configuration"
+		}
+		return scfg.GCS.Folder, ""
+	case rootStorageTypeAzure:
+		if scfg.Azure == nil || scfg.Azure.Container == "" {
+			return "", "Missing container configuration"
+		}
+		if scfg.Azure.AccountName == "" || scfg.Azure.AccountKey == "" {
+			return "", "Missing account configuration"
+		}
+		return scfg.Azure.Folder, ""
+	case rootStorageTypeBlob:
+		if scfg.Blob == nil || scfg.Blob.URL == "" {
+			return "", "Missing URL configuration"
+		}
+		return scfg.Blob.Folder, ""
+	}
+	return "", "Unsupported type: " + scfg.Type
+}
+
+func openBucket(ctx context.Context, scfg RootStorageConfig) (*blob.Bucket, error) {
+	switch scfg.Type {
+	case rootStorageTypeS3:
+		return openS3Bucket(ctx, scfg.S3)
+	case rootStorageTypeGCS:
+		return openGCSBucket(ctx, scfg.GCS)
+	case rootStorageTypeAzure:
+		return openAzureBucket(ctx, scfg.Azure)
+	case rootStorageTypeBlob:
+		return blob.OpenBucket(ctx, scfg.Blob.URL)
+	}
+	return nil, fmt.Errorf("unsupported store: " + scfg.Type)
+}
+
+// storageSecret reads the value from the environment when it starts with $
+func storageSecret(value string) string {
+	if strings.HasPrefix(value, "$") {
+		return os.Getenv(value[1:])
+	}
+	return value
+}
+
+// Without keys, the credentials are read from the environment like the other AWS clients
+func openS3Bucket(ctx context.Context, cfg *StorageS3Config) (*blob.Bucket, error) {
+	awsCfg := aws.NewConfig()
+	if cfg.Region != "" {
+		awsCfg = awsCfg.WithRegion(cfg.Region)
+	}
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint)
+	}
+	if cfg.ForcePathStyle {
+		awsCfg = awsCfg.WithS3ForcePathStyle(true)
+	}
+	if cfg.AccessKey != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(storageSecret(cfg.AccessKey), storageSecret(cfg.SecretKey), ""))
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsCfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s3blob.OpenBucket(ctx, sess, cfg.Bucket, nil)
+}
+
+// Without a credentials file, the application default credentials are used and the URLs can not be signed
+func openGCSBucket(ctx context.Context, cfg *StorageGCSConfig) (*blob.Bucket, error) {
+	opts := &gcsblob.Options{}
+	var creds *google.Credentials
+	if cfg.CredentialsFile != "" {
+		body, err := os.ReadFile(cfg.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		creds, err = google.CredentialsFromJSON(ctx, body, "https://www.googleapis.com/auth/devstorage.read_write")
+		if err != nil {
+			return nil, err
+		}
+
+		// service account keys also sign the URLs
+		if jwt, err := google.JWTConfigFromJSON(body); err == nil && len(jwt.PrivateKey) > 0 {
+			key, err := parseRSAPrivateKey(jwt.PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			opts.GoogleAccessID = jwt.Email
+			opts.MakeSignBytes = func(context.Context) gcsblob.SignBytesFunc {
+				return func(b []byte) ([]byte, error) {
+					sum := sha256.Sum256(b)
+					return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+				}
+			}
+		}
+	} else {
+		var err error
+		creds, err = gcp.DefaultCredentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := gcp.NewHTTPClient(gcp.DefaultTransport(), gcp.CredentialsTokenSource(creds))
+	if err != nil {
+		return nil, err
+	}
+	return gcsblob.OpenBucket(ctx, client, cfg.Bucket, opts)
+}
+
+func parseRSAPrivateKey(pemKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, errors.New("invalid private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not RSA")
+	}
+	return rsaKey, nil
+}
+
+func openAzureBucket(ctx context.Context, cfg *StorageAzureConfig) (*blob.Bucket, error) {
+	accountName := azureblob.AccountName(storageSecret(cfg.AccountName))
+	credential, err := azureblob.NewCredential(accountName, azureblob.AccountKey(storageSecret(cfg.AccountKey)))
+	if err != nil {
+		return nil, err
+	}
+	pipeline := azureblob.NewPipeline(credential, azblob.PipelineOptions{})
+	return azureblob.OpenBucket(ctx, pipeline, accountName, cfg.Container, &azureblob.Options{
+		Credential: credential, // signs the URLs
+	})
+}
+
+func (s *rootStorageBlob) Meta() RootStorageMeta {
+	return s.meta
+}
+
+func (s *rootStorageBlob) Store() filestorage.FileStorage {
+	return s.store
+}
+
+func (s *rootStorageBlob) Sync() error {
+	return nil // already in sync
+}
+
+// with object stores user metadata and messages are lost
+func (s *rootStorageBlob) Write(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
+	path := cmd.Path
+	if !strings.HasPrefix(path, filestorage.Delimiter) {
+		path = filestorage.Delimiter + path
+	}
+	err := s.store.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:     path,
+		Contents: cmd.Body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WriteValueResponse{Code: 200}, nil
+}
+
+// signedURL returns a link to read the file directly from the bucket.  It is empty when the links are
+// disabled, the bucket can not sign them or the file does not exist
+func (s *rootStorageBlob) signedURL(ctx context.Context, path string) (string, error) {
+	if s.expiry == 0 || s.store == nil {
+		return "", nil
+	}
+
+	file, _, err := s.store.Get(ctx, path, &filestorage.GetFileOptions{WithContents: false})
+	if err != nil || file == nil {
+		return "", err
+	}
+
+	// the keys are saved in lower case
+	key := strings.ToLower(strings.TrimPrefix(path, filestorage.Delimiter))
+	url, err := s.bucket.SignedURL(ctx, key, &blob.SignedURLOptions{Expiry: s.expiry})
+	if gcerrors.Code(err) == gcerrors.Unimplemented {
+		grafanaStorageLogger.Warn("storage can not sign URLs", "prefix", s.meta.Config.Prefix)
+		return "", nil
+	}
+	return url, err
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func setupBlobStore(t *testing.T, scfg RootStorageConfig) (*standardStorageService, *rootStorageBlob) {
+	t.Helper()
+	root := newBlobStorage(RootStorageMeta{}, scfg, "")
+	require.True(t, root.Meta().Ready, root.Meta().Notice)
+
+	for _, p := range []string{"/a.json", "/B.json", "/c.json", "/folder/d.json"} {
+		_, err := root.Write(context.Background(), &WriteValueRequest{Path: p, Body: []byte(`{}`)})
+		require.NoError(t, err)
+	}
+
+	store := newStandardStorageService(nil, []storageRuntime{root}, func(orgId int64) []storageRuntime {
+		return make([]storageRuntime, 0)
+	}, allowAllAuthService, cfg)
+	return store, root
+}
+
+func listNames(t *testing.T, frame *StorageListFrame) []string {
+	t.Helper()
+	names := make([]string, 0)
+	field, _ := frame.FieldByName(nameListFrameField)
+	for i := 0; i < field.Len(); i++ {
+		names = append(names, field.At(i).(string))
+	}
+	return names
+}
+
+func TestBlobStorageConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    RootStorageConfig
+		notice string
+	}{
+		{
+			name:   "missing bucket",
+			cfg:    RootStorageConfig{Type: rootStorageTypeS3, Prefix: "s3", S3: &StorageS3Config{}},
+			notice: "Missing bucket configuration",
+		},
+		{
+			name:   "missing container",
+			cfg:    RootStorageConfig{Type: rootStorageTypeAzure, Prefix: "azure"},
+			notice: "Missing container configuration",
+		},
+		{
+			name:   "missing account",
+			cfg:    RootStorageConfig{Type: rootStorageTypeAzure, Prefix: "azure", Azure: &StorageAzureConfig{Container: "grafana"}},
+			notice: "Missing account configuration",
+		},
+		{
+			name:   "invalid expiry",
+			cfg:    RootStorageConfig{Type: rootStorageTypeBlob, Prefix: "mem", SignedURLExpiry: "soon", Blob: &StorageBlobConfig{URL: "mem://"}},
+			notice: "Invalid signed URL expiry: soon",
+		},
+		{
+			name:   "unknown scheme",
+			cfg:    RootStorageConfig{Type: rootStorageTypeBlob, Prefix: "unknown", Blob: &StorageBlobConfig{URL: "unknown://bucket"}},
+			notice: "Failed to initialize storage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := newBlobStorage(RootStorageMeta{}, tt.cfg, "").Meta()
+			require.False(t, meta.Ready)
+			require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityError, Text: tt.notice}}, meta.Notice)
+		})
+	}
+}
+
+func TestBlobStorageListPaging(t *testing.T) {
+	store, _ := setupBlobStore(t, RootStorageConfig{
+		Type:   rootStorageTypeBlob,
+		Prefix: "mem",
+		Blob:   &StorageBlobConfig{URL: "mem://", Folder: "grafana"},
+	})
+
+	frame, err := store.List(context.Background(), dummyUser, "mem", &filestorage.Paging{First: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.json", "B.json"}, listNames(t, frame))
+	require.Equal(t, map[string]interface{}{"HasMore": true, "LastPath": "/B.json"}, frame.Meta.Custom)
+
+	frame, err = store.List(context.Background(), dummyUser, "mem", &filestorage.Paging{First: 2, After: "/B.json"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c.json", "folder"}, listNames(t, frame))
+	require.Equal(t, map[string]interface{}{"HasMore": false}, frame.Meta.Custom)
+
+	frame, err = store.List(context.Background(), dummyUser, "mem/folder", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d.json"}, listNames(t, frame))
+}
+
+func TestBlobStorageSignedURL(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(t.TempDir(), "secret.key")
+	require.NoError(t, os.WriteFile(keyPath, []byte("secret"), 0600))
+
+	store, root := setupBlobStore(t, RootStorageConfig{
+		Type:            rootStorageTypeBlob,
+		Prefix:          "files",
+		SignedURLExpiry: "5m",
+		Blob:            &StorageBlobConfig{URL: "file://" + dir + "?base_url=/signed&secret_key_path=" + keyPath},
+	})
+
+	t.Run("signs the existing files", func(t *testing.T) {
+		url, err := store.signedURL(context.Background(), dummyUser, "files/B.json")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(url, "/signed?"), url)
+		require.Contains(t, url, "obj=b.json")
+
+		url, err = store.signedURL(context.Background(), dummyUser, "files/missing.json")
+		require.NoError(t, err)
+		require.Empty(t, url)
+	})
+
+	t.Run("checks the access", func(t *testing.T) {
+		store.authService = denyAllAuthService
+		t.Cleanup(func() { store.authService = allowAllAuthService })
+
+		_, err := store.signedURL(context.Background(), dummyUser, "files/B.json")
+		require.ErrorIs(t, err, ErrAccessDenied)
+
+		req := web.SetURLParams(httptest.NewRequest(http.MethodGet, "/api/storage/read/files/B.json", nil), map[string]string{"*": "files/B.json"})
+		resp := store.read(&models.ReqContext{Context: &web.Context{Req: req}, SignedInUser: dummyUser})
+		require.Equal(t, http.StatusForbidden, resp.Status())
+	})
+
+	t.Run("disabled without expiry", func(t *testing.T) {
+		root.expiry = 0
+		url, err := store.signedURL(context.Background(), dummyUser, "files/B.json")
+		require.NoError(t, err)
+		require.Empty(t, url)
+	})
+}
+
+func TestBlobStorageResources(t *testing.T) {
+	dir := t.TempDir()
+	resources := &RootStorageConfig{Type: rootStorageTypeBlob, Blob: &StorageBlobConfig{URL: "file://" + dir, Folder: "grafana"}}
+
+	org1 := newResourcesStorage(resources, nil, 1)
+	org2 := newResourcesStorage(resources, nil, 2)
+	require.Equal(t, RootResources, org1.Meta().Config.Prefix)
+	require.True(t, org1.Meta().Ready)
+
+	_, err := org1.Write(context.Background(), &WriteValueRequest{Path: "/logo.json", Body: []byte(`{}`)})
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, "grafana", "1", "logo.json"))
+
+	file, _, err := org2.Store().Get(context.Background(), "/logo.json", nil)
+	require.NoError(t, err)
+	require.Nil(t, file)
+
+	sqlRoot := newResourcesStorage(&RootStorageConfig{Type: rootStorageTypeDisk}, nil, 1)
+	require.Equal(t, rootStorageTypeSQL, sqlRoot.Meta().Config.Type)
+}
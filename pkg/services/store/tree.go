@@ -164,7 +164,7 @@ func (t *nestedTree) getStorages(orgId int64) []storageRuntime {
 	return storages
 }
 
-func (t *nestedTree) ListFolder(ctx context.Context, orgId int64, path string, accessFilter filestorage.PathFilter) (*StorageListFrame, error) {
+func (t *nestedTree) ListFolder(ctx context.Context, orgId int64, path string, paging *filestorage.Paging, accessFilter filestorage.PathFilter) (*StorageListFrame, error) {
 	if path == "" || path == "/" {
 		t.assureOrgIsInitialized(orgId)
 
@@ -203,8 +203,9 @@ func (t *nestedTree) ListFolder(ctx context.Context, orgId int64, path string, a
 		return nil, nil // not found (or not ready)
 	}
 
+	// the mounted storages are listed on the first page
 	var storages []storageRuntime
-	if root.Meta().Config.Prefix == RootContent && (path == "" || path == "/") {
+	if root.Meta().Config.Prefix == RootContent && (path == "" || path == "/") && (paging == nil || paging.After == "") {
 		storages = filterStoragesUnderContentRoot(t.getStorages(orgId))
 	}
 	grafanaStorageLogger.Info("Listing folder", "path", path, "storageCount", len(storages), "root", root.Meta().Config.Prefix)
@@ -223,7 +224,7 @@ func (t *nestedTree) ListFolder(ctx context.Context, orgId int64, path string, a
 		)
 	}
 
-	listResponse, err := store.List(ctx, path, nil, &filestorage.ListOptions{
+	listResponse, err := store.List(ctx, path, paging, &filestorage.ListOptions{
 		Recursive:   false,
 		WithFolders: true,
 		WithFiles:   true,
@@ -260,12 +261,17 @@ func (t *nestedTree) ListFolder(ctx context.Context, orgId int64, path string, a
 		idx++
 	}
 
+	custom := map[string]interface{}{
+		"HasMore": listResponse.HasMore,
+	}
+	if listResponse.HasMore {
+		custom["LastPath"] = listResponse.LastPath // the next page starts after
+	}
+
 	frame := data.NewFrame("", names, mtype, fsize)
 	frame.SetMeta(&data.FrameMeta{
-		Type: data.FrameTypeDirectoryListing,
-		Custom: map[string]interface{}{
-			"HasMore": listResponse.HasMore,
-		},
+		Type:   data.FrameTypeDirectoryListing,
+		Custom: custom,
 	})
 	return &StorageListFrame{frame}, nil
 }
@@ -287,6 +287,9 @@ type Cfg struct {
 	LoginCookieName              string
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
+	LoginMaxConcurrentSessions   int
+	LoginSessionDeviceBinding    bool
+	LoginRoleLifetimes           map[string]LoginLifetime
 	TokenRotationIntervalMinutes int
 	SigV4AuthEnabled             bool
 	SigV4VerboseLogging          bool
@@ -352,6 +355,14 @@ type Cfg struct {
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
 
+	// ServerLockBackend is where the locks of the servers in HA mode are kept, "database" or "redis".
+	ServerLockBackend string
+
+	// KVStoreBackend is where the items of the key/value store are kept, "database" or "redis".
+	KVStoreBackend string
+	// KVStoreWatchInterval is how often the database key/value store is polled for the changes of watched keys.
+	KVStoreWatchInterval time.Duration
+
 	EditorsCanAdmin bool
 
 	ApiKeyMaxSecondsToLive int64
@@ -391,6 +402,14 @@ type Cfg struct {
 	// Snapshots
 	SnapshotPublicMode bool
 
+	// Public dashboards
+	// PublicDashboardsRateLimitPerAccessToken is the number of requests per minute to a public dashboard, 0 for no limit.
+	PublicDashboardsRateLimitPerAccessToken int
+	// PublicDashboardsRateLimitPerIP is the number of requests per minute from a client to the public dashboards, 0 for no limit.
+	PublicDashboardsRateLimitPerIP int
+	// PublicDashboardsQueryCacheTTL is how long the results of the identical panel queries are shared, 0 to disable.
+	PublicDashboardsQueryCacheTTL time.Duration
+
 	ErrTemplateName string
 
 	Env string
@@ -451,6 +470,10 @@ type Cfg struct {
 
 	// Query history
 	QueryHistoryEnabled bool
+	// Days the queries are kept, unless an org sets its own retention
+	QueryHistoryRetentionDays int
+	// Queries kept for all the orgs
+	QueryHistoryRowLimit int
 
 	DashboardPreviews DashboardPreviewsSettings
 
@@ -458,6 +481,10 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	GitSync GitSyncSettings
+
+	ServiceAccounts ServiceAccountsSettings
+
 	// Access Control
 	RBACEnabled         bool
 	RBACPermissionCache bool
@@ -946,6 +973,8 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		return err
 	}
 
+	readPublicDashboardsSettings(cfg, iniFile)
+
 	// read dashboard settings
 	dashboards := iniFile.Section("dashboards")
 	DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
@@ -1007,6 +1036,8 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	queryHistory := iniFile.Section("query_history")
 	cfg.QueryHistoryEnabled = queryHistory.Key("enabled").MustBool(true)
+	cfg.QueryHistoryRetentionDays = queryHistory.Key("retention_days").MustInt(14)
+	cfg.QueryHistoryRowLimit = queryHistory.Key("row_limit").MustInt(200000)
 
 	panelsSection := iniFile.Section("panels")
 	cfg.DisableSanitizeHtml = panelsSection.Key("disable_sanitize_html").MustBool(false)
@@ -1048,6 +1079,8 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.DashboardPreviews = readDashboardPreviewsSettings(iniFile)
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.GitSync = readGitSyncSettings(iniFile)
+	cfg.ServiceAccounts = readServiceAccountsSettings(iniFile)
 
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		cfg.Logger.Warn("require_email_validation is enabled but smtp is disabled")
@@ -1076,6 +1109,13 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		ConnStr: connStr,
 	}
 
+	serverLock := iniFile.Section("server_lock")
+	cfg.ServerLockBackend = valueAsString(serverLock, "backend", "database")
+
+	kvStore := iniFile.Section("kvstore")
+	cfg.KVStoreBackend = valueAsString(kvStore, "backend", "database")
+	cfg.KVStoreWatchInterval = kvStore.Key("watch_interval").MustDuration(5 * time.Second)
+
 	geomapSection := iniFile.Section("geomap")
 	basemapJSON := valueAsString(geomapSection, "default_baselayer_config", "")
 	if basemapJSON != "" {
@@ -1106,6 +1146,47 @@ func valueAsString(section *ini.Section, keyName string, defaultValue string) st
 	return section.Key(keyName).MustString(defaultValue)
 }
 
+// LoginLifetime holds session lifetimes that override the global
+// login_maximum_inactive_lifetime_duration and login_maximum_lifetime_duration
+// settings for users with a given role. A zero value means no override.
+type LoginLifetime struct {
+	MaxInactiveLifetime time.Duration
+	MaxLifetime         time.Duration
+}
+
+// LoginLifetimeRoles maps the role names used for LoginRoleLifetimes to the
+// suffix of their configuration keys in the [auth] section.
+var LoginLifetimeRoles = map[string]string{
+	"Viewer":       "viewer",
+	"Editor":       "editor",
+	"Admin":        "admin",
+	"GrafanaAdmin": "grafana_admin",
+}
+
+func readLoginRoleLifetimes(auth *ini.Section) (map[string]LoginLifetime, error) {
+	lifetimes := make(map[string]LoginLifetime)
+	for role, suffix := range LoginLifetimeRoles {
+		var lifetime LoginLifetime
+		var err error
+
+		if val := valueAsString(auth, "login_maximum_inactive_lifetime_duration_"+suffix, ""); val != "" {
+			if lifetime.MaxInactiveLifetime, err = gtime.ParseDuration(val); err != nil {
+				return nil, err
+			}
+		}
+		if val := valueAsString(auth, "login_maximum_lifetime_duration_"+suffix, ""); val != "" {
+			if lifetime.MaxLifetime, err = gtime.ParseDuration(val); err != nil {
+				return nil, err
+			}
+		}
+
+		if lifetime != (LoginLifetime{}) {
+			lifetimes[role] = lifetime
+		}
+	}
+	return lifetimes, nil
+}
+
 type RemoteCacheOptions struct {
 	Name    string
 	ConnStr string
@@ -1302,6 +1383,13 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		return err
 	}
 
+	cfg.LoginMaxConcurrentSessions = auth.Key("login_maximum_concurrent_sessions").MustInt(0)
+	cfg.LoginSessionDeviceBinding = auth.Key("login_session_device_binding").MustBool(false)
+	cfg.LoginRoleLifetimes, err = readLoginRoleLifetimes(auth)
+	if err != nil {
+		return err
+	}
+
 	cfg.ApiKeyMaxSecondsToLive = auth.Key("api_key_max_seconds_to_live").MustInt64(-1)
 
 	cfg.TokenRotationIntervalMinutes = auth.Key("token_rotation_interval_minutes").MustInt(10)
@@ -1511,6 +1599,14 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	return nil
 }
 
+func readPublicDashboardsSettings(cfg *Cfg, iniFile *ini.File) {
+	publicDashboards := iniFile.Section("public_dashboards")
+
+	cfg.PublicDashboardsRateLimitPerAccessToken = publicDashboards.Key("rate_limit_per_access_token").MustInt(600)
+	cfg.PublicDashboardsRateLimitPerIP = publicDashboards.Key("rate_limit_per_ip").MustInt(120)
+	cfg.PublicDashboardsQueryCacheTTL = publicDashboards.Key("query_cache_ttl").MustDuration(10 * time.Second)
+}
+
 func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 	server := iniFile.Section("server")
 	var err error
@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type GitSyncSettings struct {
+	Enabled bool
+	// Remote is the URL of the synced repository and Branch the synced branch.
+	Remote string
+	Branch string
+	// Path is the directory of the repository holding the `dashboards` and `alerting` directories.
+	Path string
+	// OrgID is the organization of the synced dashboards.
+	OrgID        int64
+	PullInterval time.Duration
+	// AccessToken authenticates the pulls and pushes over https.
+	AccessToken string
+	// WebhookSecret validates the signature of the push webhooks, the webhook is disabled when empty.
+	WebhookSecret string
+	// PushChanges commits the dashboards edited in Grafana to the branch.
+	PushChanges       bool
+	CommitAuthorName  string
+	CommitAuthorEmail string
+}
+
+func readGitSyncSettings(iniFile *ini.File) GitSyncSettings {
+	s := GitSyncSettings{}
+
+	section := iniFile.Section("git_sync")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.Remote = valueAsString(section, "remote", "")
+	s.Branch = valueAsString(section, "branch", "main")
+	s.Path = valueAsString(section, "path", "")
+	s.OrgID = section.Key("org_id").MustInt64(1)
+	s.PullInterval = section.Key("pull_interval").MustDuration(5 * time.Minute)
+	s.AccessToken = valueAsString(section, "access_token", "")
+	s.WebhookSecret = valueAsString(section, "webhook_secret", "")
+	s.PushChanges = section.Key("push_changes").MustBool(false)
+	s.CommitAuthorName = valueAsString(section, "commit_author_name", "Grafana")
+	s.CommitAuthorEmail = valueAsString(section, "commit_author_email", "grafana@localhost")
+	return s
+}
@@ -0,0 +1,22 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+)
+
+type ServiceAccountsSettings struct {
+	// TokenExpirationWarningDays is the number of days before expiration at which
+	// org admins are warned about expiring service account tokens. 0 disables the warnings.
+	TokenExpirationWarningDays int
+	// TokenExpirationWebhookURL is an optional webhook notified about expiring tokens.
+	TokenExpirationWebhookURL string
+}
+
+func readServiceAccountsSettings(iniFile *ini.File) ServiceAccountsSettings {
+	s := ServiceAccountsSettings{}
+
+	section := iniFile.Section("service_accounts")
+	s.TokenExpirationWarningDays = section.Key("token_expiration_warning_days").MustInt(7)
+	s.TokenExpirationWebhookURL = valueAsString(section, "token_expiration_webhook_url", "")
+	return s
+}
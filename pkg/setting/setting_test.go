@@ -350,6 +350,23 @@ func TestAuthDurationSettings(t *testing.T) {
 	err = readAuthSettings(f, cfg)
 	require.NoError(t, err)
 	require.Equal(t, maxLifetimeDurationTest, cfg.LoginMaxLifetime)
+
+	f = ini.Empty()
+	sec, err = f.NewSection("auth")
+	require.NoError(t, err)
+	_, err = sec.NewKey("login_maximum_concurrent_sessions", "3")
+	require.NoError(t, err)
+	_, err = sec.NewKey("login_maximum_lifetime_duration_admin", "12h")
+	require.NoError(t, err)
+	_, err = sec.NewKey("login_maximum_inactive_lifetime_duration_grafana_admin", "1h")
+	require.NoError(t, err)
+	err = readAuthSettings(f, cfg)
+	require.NoError(t, err)
+	require.Equal(t, 3, cfg.LoginMaxConcurrentSessions)
+	require.Equal(t, map[string]LoginLifetime{
+		"Admin":        {MaxLifetime: 12 * time.Hour},
+		"GrafanaAdmin": {MaxInactiveLifetime: time.Hour},
+	}, cfg.LoginRoleLifetimes)
 }
 
 func TestGetCDNPath(t *testing.T) {
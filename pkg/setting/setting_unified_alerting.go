@@ -52,6 +52,8 @@ const (
 	screenshotsDefaultCapture               = false
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
+	recordingRulesDefaultTimeout            = 10 * time.Second
+	recordingRulesDefaultRemoteWritePath    = "/api/v1/write"
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -69,6 +71,7 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HARuleSharding                 bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -83,6 +86,7 @@ type UnifiedAlertingSettings struct {
 	DefaultRuleEvaluationInterval time.Duration
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
+	RecordingRules                UnifiedAlertingRecordingRuleSettings
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -95,6 +99,14 @@ type UnifiedAlertingReservedLabelSettings struct {
 	DisabledLabels map[string]struct{}
 }
 
+type UnifiedAlertingRecordingRuleSettings struct {
+	Enabled bool
+	// Timeout of the remote write requests to the target data sources.
+	Timeout time.Duration
+	// RemoteWritePath is appended to the URL of the target data sources to send remote write requests.
+	RemoteWritePath string
+}
+
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
 // It hides the implementation details of the Enabled and simplifies its usage.
 func (u *UnifiedAlertingSettings) IsEnabled() bool {
@@ -212,6 +224,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 			uaCfg.HAPeers = append(uaCfg.HAPeers, peer)
 		}
 	}
+	uaCfg.HARuleSharding = ua.Key("ha_rule_sharding").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -294,6 +307,14 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.ReservedLabels = uaCfgReservedLabels
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfg.RecordingRules.Enabled = recordingRules.Key("enabled").MustBool(false)
+	uaCfg.RecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(recordingRules, "timeout", recordingRulesDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.RecordingRules.RemoteWritePath = recordingRules.Key("remote_write_path").MustString(recordingRulesDefaultRemoteWritePath)
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -240,4 +240,67 @@ func TestIntegrationCreateCorrelation(t *testing.T) {
 
 		require.NoError(t, res.Body.Close())
 	})
+
+	t.Run("Should correctly create a correlation with a config", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url: fmt.Sprintf("/api/datasources/uid/%s/correlations", writableDs),
+			body: fmt.Sprintf(`{
+					"targetUID": "%s",
+					"label": "Trace",
+					"config": {
+						"type": "query",
+						"field": "message",
+						"target": { "expr": "{app=\"${app}\"}" },
+						"transformations": [{ "type": "logfmt" }]
+					}
+				}`, writableDs),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var response correlations.CreateCorrelationResponseBody
+		err = json.Unmarshal(responseBody, &response)
+		require.NoError(t, err)
+
+		require.Equal(t, "Correlation created", response.Message)
+		require.Equal(t, correlations.CorrelationConfig{
+			Type:            correlations.ConfigTypeQuery,
+			Field:           "message",
+			Target:          map[string]interface{}{"expr": `{app="${app}"}`},
+			Transformations: []correlations.Transformation{{Type: correlations.TransformationTypeLogfmt}},
+		}, response.Result.Config)
+
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("creating a correlation with an invalid config should result in a 400", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url: fmt.Sprintf("/api/datasources/uid/%s/correlations", writableDs),
+			body: fmt.Sprintf(`{
+					"targetUID": "%s",
+					"config": {
+						"type": "query",
+						"field": "message",
+						"target": { "expr": "{app=\"${app}\"}" },
+						"transformations": [{ "type": "regex", "expression": "app=(" }]
+					}
+				}`, writableDs),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var response errorResponseBody
+		err = json.Unmarshal(responseBody, &response)
+		require.NoError(t, err)
+
+		require.Equal(t, "Invalid correlation config", response.Message)
+
+		require.NoError(t, res.Body.Close())
+	})
 }
@@ -184,7 +184,7 @@ func TestIntegrationUpdateCorrelation(t *testing.T) {
 		err = json.Unmarshal(responseBody, &response)
 		require.NoError(t, err)
 
-		require.Equal(t, "At least one of label, description is required", response.Message)
+		require.Equal(t, "At least one of label, description or config is required", response.Message)
 		require.Equal(t, correlations.ErrUpdateCorrelationEmptyParams.Error(), response.Error)
 		require.NoError(t, res.Body.Close())
 
@@ -202,7 +202,7 @@ func TestIntegrationUpdateCorrelation(t *testing.T) {
 		err = json.Unmarshal(responseBody, &response)
 		require.NoError(t, err)
 
-		require.Equal(t, "At least one of label, description is required", response.Message)
+		require.Equal(t, "At least one of label, description or config is required", response.Message)
 		require.Equal(t, correlations.ErrUpdateCorrelationEmptyParams.Error(), response.Error)
 		require.NoError(t, res.Body.Close())
 
@@ -223,7 +223,7 @@ func TestIntegrationUpdateCorrelation(t *testing.T) {
 		err = json.Unmarshal(responseBody, &response)
 		require.NoError(t, err)
 
-		require.Equal(t, "At least one of label, description is required", response.Message)
+		require.Equal(t, "At least one of label, description or config is required", response.Message)
 		require.Equal(t, correlations.ErrUpdateCorrelationEmptyParams.Error(), response.Error)
 		require.NoError(t, res.Body.Close())
 	})
@@ -353,4 +353,68 @@ func TestIntegrationUpdateCorrelation(t *testing.T) {
 		require.Equal(t, "", response.Result.Description)
 		require.NoError(t, res.Body.Close())
 	})
+
+	t.Run("should keep the config when it is not updated", func(t *testing.T) {
+		config := correlations.CorrelationConfig{
+			Type:   correlations.ConfigTypeQuery,
+			Field:  "traceId",
+			Target: map[string]interface{}{"query": "${traceId}"},
+		}
+		correlation := ctx.createCorrelation(correlations.CreateCorrelationCommand{
+			SourceUID: writableDs,
+			TargetUID: writableDs,
+			OrgId:     writableDsOrgId,
+			Label:     "0",
+			Config:    config,
+		})
+
+		// updating only the label
+		res := ctx.Patch(PatchParams{
+			url:  fmt.Sprintf("/api/datasources/uid/%s/correlations/%s", correlation.SourceUID, correlation.UID),
+			user: adminUser,
+			body: `{
+				"label": "1"
+			}`,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var response correlations.UpdateCorrelationResponseBody
+		err = json.Unmarshal(responseBody, &response)
+		require.NoError(t, err)
+
+		require.Equal(t, "1", response.Result.Label)
+		require.Equal(t, config, response.Result.Config)
+		require.NoError(t, res.Body.Close())
+
+		// updating the config
+		res = ctx.Patch(PatchParams{
+			url:  fmt.Sprintf("/api/datasources/uid/%s/correlations/%s", correlation.SourceUID, correlation.UID),
+			user: adminUser,
+			body: `{
+				"config": {
+					"type": "query",
+					"field": "message",
+					"target": { "query": "${traceId}" },
+					"transformations": [{ "type": "regex", "expression": "traceId=(\\w+)", "mapValue": "traceId" }]
+				}
+			}`,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		err = json.Unmarshal(responseBody, &response)
+		require.NoError(t, err)
+
+		require.Equal(t, "1", response.Result.Label)
+		require.Equal(t, "message", response.Result.Config.Field)
+		require.Equal(t, []correlations.Transformation{
+			{Type: correlations.TransformationTypeRegex, Expression: `traceId=(\w+)`, MapValue: "traceId"},
+		}, response.Result.Config.Transformations)
+		require.NoError(t, res.Body.Close())
+	})
 }
@@ -124,7 +124,7 @@ func (s *Service) doListQuery(ctx context.Context, query backend.DataQuery) back
 	}
 
 	path := store.RootPublicStatic + "/" + q.Path
-	listFrame, err := s.store.List(ctx, nil, path)
+	listFrame, err := s.store.List(ctx, nil, path, nil)
 	response.Error = err
 	if listFrame != nil {
 		response.Frames = data.Frames{listFrame.Frame}
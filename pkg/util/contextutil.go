@@ -3,6 +3,7 @@ package util
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 )
@@ -42,3 +43,18 @@ func WithCancelCause(parent context.Context) (context.Context, CancelCauseFunc)
 	}
 	return result, cancelFn
 }
+
+type contextWithoutCancel struct {
+	parent context.Context
+}
+
+func (contextWithoutCancel) Deadline() (deadline time.Time, ok bool) { return }
+func (contextWithoutCancel) Done() <-chan struct{}                   { return nil }
+func (contextWithoutCancel) Err() error                              { return nil }
+func (c contextWithoutCancel) Value(key interface{}) interface{}     { return c.parent.Value(key) }
+
+// WithoutCancel returns a context that has the values of parent but is not cancelled when parent is,
+// the same way as context.WithoutCancel of Go 1.21.
+func WithoutCancel(parent context.Context) context.Context {
+	return contextWithoutCancel{parent: parent}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -44,3 +45,17 @@ func TestWithCancelWithReason(t *testing.T) {
 		require.Equal(t, ctx.Err(), context.Canceled)
 	})
 }
+
+func TestWithoutCancel(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), time.Minute)
+	ctx := WithoutCancel(parent)
+	cancel()
+
+	require.ErrorIs(t, parent.Err(), context.Canceled)
+	require.NoError(t, ctx.Err())
+	require.Nil(t, ctx.Done())
+	_, ok := ctx.Deadline()
+	require.False(t, ok)
+	require.Equal(t, "value", ctx.Value(key{}))
+}
@@ -1,6 +1,7 @@
 export interface MessagePacket {
   event: string;
-  commentCreated: Message;
+  commentCreated?: Message;
+  commentUpdated?: Message;
 }
 
 export interface Message {
@@ -9,6 +10,18 @@ export interface Message {
   created: number;
   userId: number;
   user: User;
+  updated?: number;
+  parentId?: number;
+  panelId?: number;
+  timeFrom?: number;
+  timeTo?: number;
+  replyCount?: number;
+  reactions?: Reaction[];
+}
+
+export interface Reaction {
+  reaction: string;
+  userIds: number[];
 }
 
 // TODO: Interface may exist elsewhere
@@ -152,6 +152,11 @@ export function getIconName(type: string): IconName {
       return 'folder-open';
     case 'sql':
       return 'database';
+    case 's3':
+    case 'gcs':
+    case 'azure':
+    case 'blob':
+      return 'cloud';
     default:
       return 'folder-open';
   }
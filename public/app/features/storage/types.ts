@@ -43,6 +43,25 @@ export interface StorageConfig {
     accessToken: string;
   };
   sql?: {};
+  s3?: {
+    bucket: string;
+    folder: string;
+    region: string;
+    endpoint?: string;
+  };
+  gcs?: {
+    bucket: string;
+    folder: string;
+  };
+  azure?: {
+    container: string;
+    folder: string;
+  };
+  blob?: {
+    url: string;
+    folder: string;
+  };
+  signedUrlExpiry?: string;
 }
 
 export enum WorkflowID {